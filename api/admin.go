@@ -0,0 +1,409 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes/empty"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// endpoints is the list of all endpoints served by crypki.
+var endpoints = []string{
+	config.X509CertEndpoint,
+	config.SSHUserCertEndpoint,
+	config.SSHHostCertEndpoint,
+	config.BlobEndpoint,
+	config.TimestampEndpoint,
+	config.GitEndpoint,
+	config.DNSSECEndpoint,
+	config.ContainerImageEndpoint,
+	config.AttestationEndpoint,
+	config.EphemeralEndpoint,
+	config.IssuanceManifestEndpoint,
+}
+
+// EndpointState tracks which endpoints have their signing requests disabled at runtime.
+// Disabling an endpoint rejects its signing requests; read-only requests such as
+// fetching public keys are still served.
+type EndpointState struct {
+	mu       sync.RWMutex
+	disabled map[string]bool
+}
+
+// NewEndpointState returns an EndpointState with the specified endpoints disabled.
+func NewEndpointState(disabled ...string) *EndpointState {
+	e := &EndpointState{disabled: make(map[string]bool)}
+	for _, endpoint := range disabled {
+		e.disabled[endpoint] = true
+	}
+	return e
+}
+
+// IsEnabled returns true if the signing requests of endpoint are served.
+func (e *EndpointState) IsEnabled(endpoint string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return !e.disabled[endpoint]
+}
+
+// SetEnabled enables or disables the signing requests of endpoint.
+func (e *EndpointState) SetEnabled(endpoint string, enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.disabled[endpoint] = !enabled
+}
+
+// checkEndpointEnabled returns an error if the signing requests of endpoint are disabled.
+func (s *SigningService) checkEndpointEnabled(endpoint string) error {
+	if s.Endpoints != nil && !s.Endpoints.IsEnabled(endpoint) {
+		return fmt.Errorf("endpoint %q is disabled", endpoint)
+	}
+	return nil
+}
+
+// callerIdentity returns the common name of the verified client certificate of the caller, or for the requests
+// relayed by the REST gateway, the one of its REST client set by GatewayIdentity.
+func callerIdentity(ctx context.Context) string {
+	if caller, ok := ctx.Value(gatewayCallerContextKey{}).(string); ok {
+		return caller
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+	if len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+		return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	}
+	return ""
+}
+
+// checkAdmin returns an error if the caller is not allowed to call the Admin service.
+func (s *SigningService) checkAdmin(ctx context.Context) error {
+	caller := callerIdentity(ctx)
+	if caller == "" {
+		return errors.New("no verified client identity")
+	}
+	if !s.AdminIdentities[caller] {
+		return fmt.Errorf("%q is not an admin", caller)
+	}
+	return nil
+}
+
+// GetServerInfo returns the runtime state of the server.
+func (s *SigningService) GetServerInfo(ctx context.Context, e *empty.Empty) (*proto.ServerInfo, error) {
+	const methodName = "GetServerInfo"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,caller=%q,st=%d,et=%d,err="%v"`, methodName, callerIdentity(ctx), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+
+	info := &proto.ServerInfo{}
+	for _, endpoint := range endpoints {
+		info.Endpoints = append(info.Endpoints, &proto.EndpointStatus{
+			Endpoint: endpoint,
+			Enabled:  s.checkEndpointEnabled(endpoint) == nil,
+		})
+	}
+	if s.Breakers != nil {
+		for _, id := range s.Breakers.Identifiers() {
+			state, failures := s.Breakers.State(id)
+			info.CircuitBreakers = append(info.CircuitBreakers, &proto.CircuitBreakerStatus{
+				Identifier:          id,
+				State:               state.String(),
+				ConsecutiveFailures: uint32(failures),
+			})
+		}
+	}
+	return info, nil
+}
+
+// SetEndpointStatus enables or disables the signing requests of an endpoint at runtime.
+func (s *SigningService) SetEndpointStatus(ctx context.Context, request *proto.EndpointStatus) (*proto.EndpointStatus, error) {
+	const methodName = "SetEndpointStatus"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,caller=%q,endpoint=%q,enabled=%t,st=%d,et=%d,err="%v"`, methodName, callerIdentity(ctx), request.GetEndpoint(), request.GetEnabled(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+
+	if !isKnownEndpoint(request.GetEndpoint()) {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("unknown endpoint %q", request.GetEndpoint())
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if s.Endpoints == nil {
+		statusCode = http.StatusInternalServerError
+		err = errors.New("endpoint state is not initialized")
+		return nil, s.internalError(err)
+	}
+	s.Endpoints.SetEnabled(request.GetEndpoint(), request.GetEnabled())
+	return &proto.EndpointStatus{Endpoint: request.GetEndpoint(), Enabled: s.Endpoints.IsEnabled(request.GetEndpoint())}, nil
+}
+
+// GenerateKey generates a new key pair in the HSM and registers it as a signing key. The key is not added to
+// Keys nor KeyUsages, so the endpoints only sign with it once it is configured and crypki is restarted.
+func (s *SigningService) GenerateKey(ctx context.Context, request *proto.KeyGenerationRequest) (*proto.GeneratedKey, error) {
+	const methodName = "GenerateKey"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	// Key generation is logged as an audit event regardless of its result.
+	defer func() {
+		log.Printf(`audit: m=%s,caller=%q,id=%q,slot=%d,label=%q,type=%s,size=%d,st=%d,et=%d,err="%v"`,
+			methodName, callerIdentity(ctx), request.GetKeyMeta().GetIdentifier(), request.GetSlotNumber(),
+			request.GetKeyLabel(), request.GetKeyType(), request.GetKeySize(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+	if caller := callerIdentity(ctx); !s.KeyGenerationIdentities[caller] {
+		statusCode = http.StatusForbidden
+		err = fmt.Errorf("%q is not allowed to generate keys", caller)
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+
+	if s.KeyGenerator == nil {
+		statusCode = http.StatusNotImplemented
+		err = errors.New("key generation is not supported")
+		return nil, status.Error(codes.Unimplemented, "Key generation is not supported")
+	}
+
+	if request.KeyMeta == nil || request.KeyMeta.Identifier == "" {
+		statusCode = http.StatusBadRequest
+		err = errors.New("request.keyMeta is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	var keyType crypki.PublicKeyAlgorithm
+	switch request.KeyType {
+	case proto.KeyType_RSA:
+		keyType = crypki.RSA
+	case proto.KeyType_ECDSA:
+		keyType = crypki.ECDSA
+	default:
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("unsupported key type %s", request.KeyType)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	// Requests for the key fail with a retry hint until it is registered.
+	s.Transitions.Begin(request.KeyMeta.Identifier)
+	pub, err := s.KeyGenerator.GenerateKey(&crypki.KeyGenParams{
+		Identifier: request.KeyMeta.Identifier,
+		Module:     request.Module,
+		SlotNumber: uint(request.SlotNumber),
+		KeyLabel:   request.KeyLabel,
+		KeyType:    keyType,
+		KeySize:    int(request.KeySize),
+	})
+	s.Transitions.End(request.KeyMeta.Identifier)
+	if _, ok := err.(*crypki.RequestError); ok {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	s.indexFingerprint(request.KeyMeta.Identifier)
+	return &proto.GeneratedKey{
+		KeyMeta:   &proto.KeyMeta{Identifier: request.KeyMeta.Identifier},
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}, nil
+}
+
+// ReloadKeySessions replaces the PKCS#11 sessions of a signing key with freshly opened ones.
+func (s *SigningService) ReloadKeySessions(ctx context.Context, keyMeta *proto.KeyMeta) (*proto.ReloadedSessions, error) {
+	const methodName = "ReloadKeySessions"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	// Session reloads are logged as audit events regardless of their result.
+	defer func() {
+		log.Printf(`audit: m=%s,caller=%q,id=%q,st=%d,et=%d,err="%v"`,
+			methodName, callerIdentity(ctx), keyMeta.GetIdentifier(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+
+	reloader, ok := s.CertSign.(crypki.SessionReloader)
+	if !ok {
+		statusCode = http.StatusNotImplemented
+		err = errors.New("reloading key sessions is not supported")
+		return nil, status.Error(codes.Unimplemented, "Reloading key sessions is not supported")
+	}
+
+	if keyMeta.GetIdentifier() == "" {
+		statusCode = http.StatusBadRequest
+		err = errors.New("request.identifier is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	n, err := reloader.ReloadSessions(ctx, keyMeta.Identifier)
+	if _, ok := err.(*crypki.RequestError); ok {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err != nil && err == ctx.Err() {
+		// The sessions are reloaded, and the previous ones are closed once the requests using them are done.
+		s.indexFingerprint(keyMeta.Identifier)
+		statusCode = http.StatusGatewayTimeout
+		return nil, status.Errorf(codes.DeadlineExceeded, "Deadline exceeded: the sessions of key %q are reloaded, but the previous sessions are still in use", keyMeta.Identifier)
+	}
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	// The key may have been replaced in the HSM under the same label.
+	s.indexFingerprint(keyMeta.Identifier)
+	return &proto.ReloadedSessions{
+		KeyMeta:  &proto.KeyMeta{Identifier: keyMeta.Identifier},
+		Sessions: uint32(n),
+	}, nil
+}
+
+// ListHSMMechanisms returns the PKCS#11 mechanisms supported by each slot holding signing keys.
+func (s *SigningService) ListHSMMechanisms(ctx context.Context, e *empty.Empty) (*proto.SlotMechanismsList, error) {
+	const methodName = "ListHSMMechanisms"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,caller=%q,st=%d,et=%d,err="%v"`, methodName, callerIdentity(ctx), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+
+	ml, ok := s.CertSign.(crypki.MechanismLister)
+	if !ok {
+		statusCode = http.StatusNotImplemented
+		err = errors.New("listing HSM mechanisms is not supported")
+		return nil, status.Error(codes.Unimplemented, "Listing HSM mechanisms is not supported")
+	}
+	list := &proto.SlotMechanismsList{}
+	for _, slot := range ml.ListMechanisms() {
+		sm := &proto.SlotMechanisms{Module: slot.Module, SlotNumber: uint32(slot.SlotNumber)}
+		if slot.Err != nil {
+			sm.Error = slot.Err.Error()
+		}
+		for _, m := range slot.Mechanisms {
+			sm.Mechanisms = append(sm.Mechanisms, &proto.HSMMechanism{
+				Name:       m.Name,
+				Value:      uint32(m.Value),
+				MinKeySize: uint32(m.MinKeySize),
+				MaxKeySize: uint32(m.MaxKeySize),
+				Functions:  m.Functions,
+			})
+		}
+		list.Slots = append(list.Slots, sm)
+	}
+	return list, nil
+}
+
+// GetEffectiveConfig returns the configuration the server is running with, with its secrets redacted.
+func (s *SigningService) GetEffectiveConfig(ctx context.Context, e *empty.Empty) (*proto.EffectiveConfig, error) {
+	const methodName = "GetEffectiveConfig"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,caller=%q,st=%d,et=%d,err="%v"`, methodName, callerIdentity(ctx), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+
+	if s.Config == nil {
+		statusCode = http.StatusNotImplemented
+		err = errors.New("the effective configuration is not available")
+		return nil, status.Error(codes.Unimplemented, "The effective configuration is not available")
+	}
+	// The configuration is converted to a Struct through its JSON encoding, which has the field
+	// names of the configuration file.
+	b, err := json.Marshal(s.Config.Redacted())
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	st := &_struct.Struct{}
+	if err = jsonpb.UnmarshalString(string(b), st); err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.EffectiveConfig{Config: st}, nil
+}
+
+// isKnownEndpoint returns true if endpoint is served by crypki.
+func isKnownEndpoint(endpoint string) bool {
+	for _, e := range endpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,422 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// contextWithIdentity returns a context carrying a verified client certificate with the given common name.
+func contextWithIdentity(cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func TestSetEndpointStatus(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		ctx          context.Context
+		request      *proto.EndpointStatus
+		withoutState bool
+		expectedCode codes.Code
+	}{
+		"admin-disable": {
+			ctx:          contextWithIdentity("admin"),
+			request:      &proto.EndpointStatus{Endpoint: config.BlobEndpoint, Enabled: false},
+			expectedCode: codes.OK,
+		},
+		"admin-enable": {
+			ctx:          contextWithIdentity("admin"),
+			request:      &proto.EndpointStatus{Endpoint: config.BlobEndpoint, Enabled: true},
+			expectedCode: codes.OK,
+		},
+		"no-identity": {
+			ctx:          context.Background(),
+			request:      &proto.EndpointStatus{Endpoint: config.BlobEndpoint, Enabled: false},
+			expectedCode: codes.PermissionDenied,
+		},
+		"not-admin": {
+			ctx:          contextWithIdentity("alice"),
+			request:      &proto.EndpointStatus{Endpoint: config.BlobEndpoint, Enabled: false},
+			expectedCode: codes.PermissionDenied,
+		},
+		"unknown-endpoint": {
+			ctx:          contextWithIdentity("admin"),
+			request:      &proto.EndpointStatus{Endpoint: "/sig/unknown", Enabled: false},
+			expectedCode: codes.InvalidArgument,
+		},
+		"no-endpoint-state": {
+			ctx:          contextWithIdentity("admin"),
+			request:      &proto.EndpointStatus{Endpoint: config.BlobEndpoint, Enabled: false},
+			withoutState: true,
+			expectedCode: codes.Internal,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := initMockSigningService(mockSigningServiceParam{KeyUsages: combineKeyUsage})
+			ss.AdminIdentities = map[string]bool{"admin": true}
+			if !tt.withoutState {
+				ss.Endpoints = NewEndpointState()
+			}
+			resp, err := ss.SetEndpointStatus(tt.ctx, tt.request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Enabled != tt.request.Enabled {
+				t.Errorf("in test %v: got enabled %t, want %t", label, resp.Enabled, tt.request.Enabled)
+			}
+			if ss.Endpoints.IsEnabled(tt.request.Endpoint) != tt.request.Enabled {
+				t.Errorf("in test %v: endpoint state was not updated", label)
+			}
+		})
+	}
+}
+
+func TestDisabledEndpoint(t *testing.T) {
+	t.Parallel()
+	ctx := contextWithIdentity("admin")
+	ss := initMockSigningService(mockSigningServiceParam{KeyUsages: combineKeyUsage})
+	ss.AdminIdentities = map[string]bool{"admin": true}
+	ss.Endpoints = NewEndpointState(config.BlobEndpoint)
+
+	blobRequest := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA512Digest, HashAlgorithm: proto.HashAlgo_SHA512}
+	if _, err := ss.PostSignBlob(ctx, blobRequest); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected blob signing to be unavailable, got err: %v", err)
+	}
+	// read-only requests are still served for a disabled endpoint
+	if _, err := ss.GetBlobSigningKey(ctx, &proto.KeyMeta{Identifier: "blobid1"}); err != nil {
+		t.Fatalf("unexpected error fetching blob signing key: %v", err)
+	}
+	userRequest := &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+		PublicKey:  testGoodRsaPubKey,
+		Validity:   3600,
+		Principals: []string{"alice"},
+		KeyId:      testGoodKeyID,
+	}
+	if _, err := ss.PostUserSSHCertificate(ctx, userRequest); err != nil {
+		t.Fatalf("unexpected error for enabled endpoint: %v", err)
+	}
+
+	info, err := ss.GetServerInfo(ctx, &empty.Empty{})
+	if err != nil {
+		t.Fatalf("unexpected error getting server info: %v", err)
+	}
+	for _, e := range info.Endpoints {
+		if e.Enabled != (e.Endpoint != config.BlobEndpoint) {
+			t.Errorf("endpoint %q: got enabled %t", e.Endpoint, e.Enabled)
+		}
+	}
+
+	if _, err := ss.SetEndpointStatus(ctx, &proto.EndpointStatus{Endpoint: config.BlobEndpoint, Enabled: true}); err != nil {
+		t.Fatalf("unexpected error enabling endpoint: %v", err)
+	}
+	if _, err := ss.PostSignBlob(ctx, blobRequest); err != nil {
+		t.Fatalf("unexpected error after enabling endpoint: %v", err)
+	}
+
+	if _, err := ss.GetServerInfo(context.Background(), &empty.Empty{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected permission denied without client identity, got err: %v", err)
+	}
+}
+
+// mockKeyGenerator records the params of the last generated key.
+type mockKeyGenerator struct {
+	params *crypki.KeyGenParams
+	pub    crypto.PublicKey
+	err    error
+}
+
+func (m *mockKeyGenerator) GenerateKey(params *crypki.KeyGenParams) (crypto.PublicKey, error) {
+	m.params = params
+	return m.pub, m.err
+}
+
+func TestGenerateKey(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to marshal public key: %v", err)
+	}
+	expectedPub := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	fp, err := PublicKeyFingerprint(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+	goodRequest := &proto.KeyGenerationRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "newkey"},
+		SlotNumber: 1,
+		KeyLabel:   "newlabel",
+		KeyType:    proto.KeyType_RSA,
+		KeySize:    2048,
+	}
+	testcases := map[string]struct {
+		ctx            context.Context
+		request        *proto.KeyGenerationRequest
+		withoutKeyGen  bool
+		keyGenErr      error
+		expectedCode   codes.Code
+		expectedParams *crypki.KeyGenParams
+	}{
+		"good": {
+			ctx:            contextWithIdentity("keygen-admin"),
+			request:        goodRequest,
+			expectedCode:   codes.OK,
+			expectedParams: &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.RSA, KeySize: 2048},
+		},
+		"not-admin": {
+			ctx:          contextWithIdentity("alice"),
+			request:      goodRequest,
+			expectedCode: codes.PermissionDenied,
+		},
+		"admin-not-allowed-to-generate": {
+			ctx:          contextWithIdentity("admin"),
+			request:      goodRequest,
+			expectedCode: codes.PermissionDenied,
+		},
+		"no-key-generator": {
+			ctx:           contextWithIdentity("keygen-admin"),
+			request:       goodRequest,
+			withoutKeyGen: true,
+			expectedCode:  codes.Unimplemented,
+		},
+		"missing-key-meta": {
+			ctx:          contextWithIdentity("keygen-admin"),
+			request:      &proto.KeyGenerationRequest{SlotNumber: 1, KeyLabel: "newlabel", KeyType: proto.KeyType_RSA, KeySize: 2048},
+			expectedCode: codes.InvalidArgument,
+		},
+		"unspecified-key-type": {
+			ctx:          contextWithIdentity("keygen-admin"),
+			request:      &proto.KeyGenerationRequest{KeyMeta: &proto.KeyMeta{Identifier: "newkey"}, SlotNumber: 1, KeyLabel: "newlabel", KeySize: 2048},
+			expectedCode: codes.InvalidArgument,
+		},
+		"generation-rejected": {
+			ctx:          contextWithIdentity("keygen-admin"),
+			request:      goodRequest,
+			keyGenErr:    &crypki.RequestError{Err: errors.New("unsupported key type")},
+			expectedCode: codes.InvalidArgument,
+		},
+		"generation-failed": {
+			ctx:          contextWithIdentity("keygen-admin"),
+			request:      goodRequest,
+			keyGenErr:    errors.New("CKR_DEVICE_ERROR"),
+			expectedCode: codes.Internal,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := initMockSigningService(mockSigningServiceParam{})
+			ss.AdminIdentities = map[string]bool{"admin": true, "keygen-admin": true}
+			ss.KeyGenerationIdentities = map[string]bool{"keygen-admin": true}
+			ss.CertSign = &mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"newkey": &key.PublicKey}}
+			ss.Fingerprints, _ = NewFingerprintIndex(nil)
+			kg := &mockKeyGenerator{pub: &key.PublicKey, err: tt.keyGenErr}
+			if !tt.withoutKeyGen {
+				ss.KeyGenerator = kg
+			}
+			resp, err := ss.GenerateKey(tt.ctx, tt.request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(kg.params, tt.expectedParams) {
+				t.Errorf("in test %v: got params %+v, want %+v", label, kg.params, tt.expectedParams)
+			}
+			if resp.KeyMeta.GetIdentifier() != tt.request.KeyMeta.Identifier || resp.PublicKey != expectedPub {
+				t.Errorf("in test %v: unexpected response %+v", label, resp)
+			}
+			if id, err := ss.Fingerprints.Resolve(fp); err != nil || id != tt.request.KeyMeta.Identifier {
+				t.Errorf("in test %v: got %q, %v for fingerprint of generated key", label, id, err)
+			}
+		})
+	}
+}
+
+// mockMechanismCertSign lists a fixed set of slot mechanisms.
+type mockMechanismCertSign struct {
+	mockGoodCertSign
+	slots []crypki.SlotMechanisms
+}
+
+func (m *mockMechanismCertSign) ListMechanisms() []crypki.SlotMechanisms {
+	return m.slots
+}
+
+func TestListHSMMechanisms(t *testing.T) {
+	t.Parallel()
+	slots := []crypki.SlotMechanisms{
+		{SlotNumber: 1, Mechanisms: []crypki.Mechanism{
+			{Name: "CKM_RSA_PKCS", Value: 0x1, MinKeySize: 2048, MaxKeySize: 4096, Functions: []string{"sign", "verify"}},
+		}},
+		{Module: "softhsm", SlotNumber: 2, Err: errors.New("CKR_TOKEN_NOT_PRESENT")},
+	}
+	expected := &proto.SlotMechanismsList{Slots: []*proto.SlotMechanisms{
+		{SlotNumber: 1, Mechanisms: []*proto.HSMMechanism{
+			{Name: "CKM_RSA_PKCS", Value: 0x1, MinKeySize: 2048, MaxKeySize: 4096, Functions: []string{"sign", "verify"}},
+		}},
+		{Module: "softhsm", SlotNumber: 2, Error: "CKR_TOKEN_NOT_PRESENT"},
+	}}
+	testcases := map[string]struct {
+		ctx          context.Context
+		certSign     crypki.CertSign
+		expectedCode codes.Code
+	}{
+		"good":        {ctx: contextWithIdentity("admin"), certSign: &mockMechanismCertSign{slots: slots}, expectedCode: codes.OK},
+		"not-admin":   {ctx: contextWithIdentity("alice"), certSign: &mockMechanismCertSign{slots: slots}, expectedCode: codes.PermissionDenied},
+		"unsupported": {ctx: contextWithIdentity("admin"), certSign: &mockGoodCertSign{}, expectedCode: codes.Unimplemented},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: tt.certSign, AdminIdentities: map[string]bool{"admin": true}}
+			got, err := ss.ListHSMMechanisms(tt.ctx, &empty.Empty{})
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, code, tt.expectedCode, err)
+			}
+			if err == nil && got.String() != expected.String() {
+				t.Errorf("in test %v: got %v, want %v", label, got, expected)
+			}
+		})
+	}
+}
+
+type mockReloadCertSign struct {
+	mockGoodCertSign
+	err error
+}
+
+func (m *mockReloadCertSign) ReloadSessions(ctx context.Context, keyIdentifier string) (int, error) {
+	if m.err == context.DeadlineExceeded {
+		return 2, ctx.Err()
+	}
+	return 2, m.err
+}
+
+// expiredContextWithIdentity returns a context of the identity whose deadline is exceeded.
+func expiredContextWithIdentity(cn string) context.Context {
+	ctx, cancel := context.WithDeadline(contextWithIdentity(cn), time.Now())
+	cancel()
+	return ctx
+}
+
+func TestReloadKeySessions(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		ctx          context.Context
+		certSign     crypki.CertSign
+		keyMeta      *proto.KeyMeta
+		expectedCode codes.Code
+	}{
+		"good":           {ctx: contextWithIdentity("admin"), certSign: &mockReloadCertSign{}, keyMeta: &proto.KeyMeta{Identifier: "key1"}, expectedCode: codes.OK},
+		"not-admin":      {ctx: contextWithIdentity("alice"), certSign: &mockReloadCertSign{}, keyMeta: &proto.KeyMeta{Identifier: "key1"}, expectedCode: codes.PermissionDenied},
+		"unsupported":    {ctx: contextWithIdentity("admin"), certSign: &mockGoodCertSign{}, keyMeta: &proto.KeyMeta{Identifier: "key1"}, expectedCode: codes.Unimplemented},
+		"missing-key-id": {ctx: contextWithIdentity("admin"), certSign: &mockReloadCertSign{}, keyMeta: &proto.KeyMeta{}, expectedCode: codes.InvalidArgument},
+		"reload-failed":  {ctx: contextWithIdentity("admin"), certSign: &mockReloadCertSign{err: errors.New("CKR_DEVICE_ERROR")}, keyMeta: &proto.KeyMeta{Identifier: "key1"}, expectedCode: codes.Internal},
+		"unknown-key":    {ctx: contextWithIdentity("admin"), certSign: &mockReloadCertSign{err: &crypki.RequestError{Err: errors.New("unknown key identifier")}}, keyMeta: &proto.KeyMeta{Identifier: "key1"}, expectedCode: codes.InvalidArgument},
+		"still-in-use":   {ctx: expiredContextWithIdentity("admin"), certSign: &mockReloadCertSign{err: context.DeadlineExceeded}, keyMeta: &proto.KeyMeta{Identifier: "key1"}, expectedCode: codes.DeadlineExceeded},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: tt.certSign, AdminIdentities: map[string]bool{"admin": true}}
+			got, err := ss.ReloadKeySessions(tt.ctx, tt.keyMeta)
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, code, tt.expectedCode, err)
+			}
+			if err == nil && (got.GetKeyMeta().GetIdentifier() != "key1" || got.GetSessions() != 2) {
+				t.Errorf("in test %v: got %v, want 2 sessions of key1", label, got)
+			}
+		})
+	}
+}
+
+func TestGetEffectiveConfig(t *testing.T) {
+	t.Parallel()
+	cfg, err := config.Parse("../config/testdata/testconf-good.json")
+	if err != nil {
+		t.Fatalf("unable to parse config: %v", err)
+	}
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, AdminIdentities: map[string]bool{"admin": true}, Config: cfg}
+	if _, err := ss.GetEffectiveConfig(contextWithIdentity("alice"), &empty.Empty{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("got %v for a non-admin caller, want PermissionDenied", err)
+	}
+	resp, err := ss.GetEffectiveConfig(contextWithIdentity("admin"), &empty.Empty{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fields := resp.Config.Fields
+	// None of these fields are in the file, so they have their default values.
+	for name, want := range map[string]float64{"SignersPerPool": 2, "CircuitBreakerOpenTimeoutMs": 30000, "KeepaliveMaxConnectionIdleMs": 30000} {
+		if got := fields[name].GetNumberValue(); got != want {
+			t.Errorf("got %s %v, want %v", name, got, want)
+		}
+	}
+	if got := fields["TLSServerKeyPath"].GetStringValue(); got != "REDACTED" {
+		t.Errorf("got TLSServerKeyPath %q, want it redacted", got)
+	}
+	keys := fields["Keys"].GetListValue().GetValues()
+	if len(keys) != len(cfg.Keys) {
+		t.Fatalf("got %d keys, want %d", len(keys), len(cfg.Keys))
+	}
+	for i, key := range keys {
+		kf := key.GetStructValue().Fields
+		if got := kf["UserPinPath"].GetStringValue(); got != "REDACTED" {
+			t.Errorf("key %d: got UserPinPath %q, want it redacted", i, got)
+		}
+		if got, want := kf["KeyLabel"].GetStringValue(), cfg.Keys[i].KeyLabel; got != want {
+			t.Errorf("key %d: got KeyLabel %q, want %q", i, got, want)
+		}
+	}
+	if cfg.Keys[0].UserPinPath == "REDACTED" || cfg.TLSServerKeyPath == "REDACTED" {
+		t.Error("the configuration of the server was modified")
+	}
+
+	ss.Config = nil
+	if _, err := ss.GetEffectiveConfig(contextWithIdentity("admin"), &empty.Empty{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("got %v without a configuration, want Unimplemented", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"sort"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+)
+
+// KeyAliases maps the client-facing aliases of the keys to their identifiers, so that an alias can be
+// remapped to another key, e.g. during a migration, without the clients changing their requests.
+type KeyAliases map[string]string
+
+// aliasesOf returns the sorted aliases of the key with the identifier.
+func (a KeyAliases) aliasesOf(identifier string) []string {
+	var aliases []string
+	for alias, id := range a {
+		if id == identifier {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that replaces the alias in the KeyMeta
+// of the requests by the identifier of the key, so that the key usages and the signing use the aliased key.
+// The keys listed by the responses are listed by their aliases instead.
+func (a KeyAliases) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var keyMeta *proto.KeyMeta
+		switch r := req.(type) {
+		case *proto.KeyMeta:
+			keyMeta = r
+		case interface{ GetKeyMeta() *proto.KeyMeta }:
+			keyMeta = r.GetKeyMeta()
+		}
+		alias := keyMeta.GetIdentifier()
+		id, aliased := a[alias]
+		if aliased {
+			keyMeta.Identifier = id
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		switch r := resp.(type) {
+		case *proto.KeyMetas:
+			var keys []*proto.KeyMeta
+			for _, key := range r.Keys {
+				aliases := a.aliasesOf(key.Identifier)
+				if len(aliases) == 0 {
+					keys = append(keys, key)
+					continue
+				}
+				for _, alias := range aliases {
+					keys = append(keys, &proto.KeyMeta{Identifier: alias, Fingerprint: key.Fingerprint, Format: key.Format})
+				}
+			}
+			r.Keys = keys
+		case interface{ GetKeyMeta() *proto.KeyMeta }:
+			if km := r.GetKeyMeta(); aliased && km.GetIdentifier() == id {
+				km.Identifier = alias
+			}
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockKeyRecordingCertSign is a mockGoodCertSign that records the identifiers of the keys it signed with.
+type mockKeyRecordingCertSign struct {
+	mockGoodCertSign
+	mu  sync.Mutex
+	ids []string
+}
+
+func (m *mockKeyRecordingCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	m.mu.Lock()
+	m.ids = append(m.ids, keyIdentifier)
+	m.mu.Unlock()
+	return m.mockGoodCertSign.Sign(digest, opts, keyIdentifier)
+}
+
+func TestKeyAliasesSigning(t *testing.T) {
+	t.Parallel()
+	aliases := KeyAliases{"release-signer": "blobid1", "retired-signer": "blobid2"}
+	testcases := map[string]struct {
+		identifier   string
+		expectedKey  string
+		expectedCode codes.Code
+	}{
+		"alias":              {identifier: "release-signer", expectedKey: "blobid1"},
+		"identifier":         {identifier: "blobid1", expectedKey: "blobid1"},
+		"alias-unusable":     {identifier: "retired-signer", expectedCode: codes.InvalidArgument},
+		"unknown-identifier": {identifier: "unknown-signer", expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockKeyRecordingCertSign{}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return ss.PostSignBlob(ctx, req.(*proto.BlobSigningRequest))
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+			request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: tt.identifier}, Digest: testSHA256Digest, HashAlgorithm: proto.HashAlgo_SHA256}
+			_, err := aliases.UnaryServerInterceptor()(context.Background(), request, info, handler)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if want := []string{tt.expectedKey}; !reflect.DeepEqual(signer.ids, want) {
+				t.Errorf("in test %v: signed with keys %v, want %v", label, signer.ids, want)
+			}
+		})
+	}
+}
+
+func TestKeyAliasesListing(t *testing.T) {
+	t.Parallel()
+	aliases := KeyAliases{"release-signer": "blobid1", "legacy-signer": "blobid1"}
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return ss.GetBlobAvailableSigningKeys(ctx, req.(*proto.KeyFilter))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/GetBlobAvailableSigningKeys"}
+	resp, err := aliases.UnaryServerInterceptor()(context.Background(), &proto.KeyFilter{}, info, handler)
+	if err != nil {
+		t.Fatalf("unable to list keys: %v", err)
+	}
+	var got []string
+	for _, key := range resp.(*proto.KeyMetas).Keys {
+		got = append(got, key.Identifier)
+	}
+	sort.Strings(got)
+	if want := []string{"blobid2", "legacy-signer", "release-signer"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got keys %v, want %v", got, want)
+	}
+}
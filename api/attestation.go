@@ -0,0 +1,136 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// COSE algorithm identifiers of the attestation signatures produced by crypki, as registered by IANA.
+const (
+	coseES256 = -7
+	coseES384 = -35
+	coseES512 = -36
+	coseRS256 = -257
+)
+
+// minAuthenticatorDataSize is the size of the authenticator data without attested credential data
+// nor extensions, i.e. the RP ID hash, the flags and the signature counter.
+const minAuthenticatorDataSize = 37
+
+// attestationAlgorithm returns the COSE algorithm identifier and the hash function of the packed
+// attestation signatures of the public key.
+func attestationAlgorithm(pub crypto.PublicKey) (int64, crypto.Hash, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return coseRS256, crypto.SHA256, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return coseES256, crypto.SHA256, nil
+		case elliptic.P384():
+			return coseES384, crypto.SHA384, nil
+		case elliptic.P521():
+			return coseES512, crypto.SHA512, nil
+		}
+		return 0, 0, fmt.Errorf("curve %s is not supported for attestation signatures", pub.Curve.Params().Name)
+	}
+	return 0, 0, fmt.Errorf("unsupported public key type %T for attestation signatures", pub)
+}
+
+// PostSignAttestation returns the packed attestation statement of the WebAuthn attestation, signed by the
+// specified attestation key. The X509 CA certificate of the key is the attestation certificate.
+func (s *SigningService) PostSignAttestation(ctx context.Context, request *proto.AttestationSigningRequest) (*proto.AttestationStatement, error) {
+	const methodName = "PostSignAttestation"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.AttestationEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.AttestationEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if len(request.AuthenticatorData) < minAuthenticatorDataSize {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("authenticator data of %d bytes is shorter than %d bytes", len(request.AuthenticatorData), minAuthenticatorDataSize)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if len(request.ClientDataHash) != crypto.SHA256.Size() {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("client data hash of %d bytes is not a SHA256 hash", len(request.ClientDataHash))
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[config.AttestationEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.AttestationEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	cert, err := s.x509CACert(request.KeyMeta.Identifier)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	alg, hash, err := attestationAlgorithm(cert.PublicKey)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	if err = s.checkHashFloor(request.KeyMeta.Identifier, hash); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	h := hash.New()
+	h.Write(request.AuthenticatorData)
+	h.Write(request.ClientDataHash)
+
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	// The HSM returns DER encoded ECDSA signatures, as the packed attestation format requires.
+	signature, err := s.signBlob(ctx, h.Sum(nil), hash, request.KeyMeta.Identifier, proto.Priority_NORMAL)
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.AttestationStatement{Alg: alg, Sig: signature, X5C: [][]byte{cert.Raw}}, nil
+}
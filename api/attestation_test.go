@@ -0,0 +1,64 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostSignAttestation(t *testing.T) {
+	t.Parallel()
+	authData := bytes.Repeat([]byte{0x5a}, 37)
+	clientDataHash := sha256.Sum256([]byte(`{"type":"webauthn.create"}`))
+	keyUsages := map[string]map[string]bool{config.AttestationEndpoint: {"attid1": true}}
+	keys := map[string]config.KeyConfig{"attid1": {Identifier: "attid1"}}
+	testcases := map[string]struct {
+		request      *proto.AttestationSigningRequest
+		expectedCode codes.Code
+	}{
+		"good":                     {&proto.AttestationSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "attid1"}, AuthenticatorData: authData, ClientDataHash: clientDataHash[:]}, codes.OK},
+		"bad-key":                  {&proto.AttestationSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, AuthenticatorData: authData, ClientDataHash: clientDataHash[:]}, codes.InvalidArgument},
+		"no-key-meta":              {&proto.AttestationSigningRequest{AuthenticatorData: authData, ClientDataHash: clientDataHash[:]}, codes.InvalidArgument},
+		"short-authenticator-data": {&proto.AttestationSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "attid1"}, AuthenticatorData: authData[:36], ClientDataHash: clientDataHash[:]}, codes.InvalidArgument},
+		"bad-client-data-hash":     {&proto.AttestationSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "attid1"}, AuthenticatorData: authData, ClientDataHash: clientDataHash[:20]}, codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: newMockCACertSign(t), KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys}
+			resp, err := ss.PostSignAttestation(context.Background(), tt.request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Alg != coseES256 || len(resp.X5C) != 1 {
+				t.Fatalf("in test %v: got alg %d with %d certificates, want ES256 with the attestation certificate", label, resp.Alg, len(resp.X5C))
+			}
+			cert, err := x509.ParseCertificate(resp.X5C[0])
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse attestation certificate: %v", label, err)
+			}
+			// The packed attestation signature is over the authenticator data followed by the client data hash.
+			digest := sha256.Sum256(append(append([]byte{}, tt.request.AuthenticatorData...), tt.request.ClientDataHash...))
+			if !ecdsa.VerifyASN1(cert.PublicKey.(*ecdsa.PublicKey), digest[:], resp.Sig) {
+				t.Errorf("in test %v: attestation signature does not verify with the attestation certificate", label)
+			}
+		})
+	}
+}
@@ -4,15 +4,25 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/asn1"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"net/http"
+	"strconv"
 	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki"
 	"github.com/yahoo/crypki/config"
 	"github.com/yahoo/crypki/proto"
 	"google.golang.org/grpc/codes"
@@ -20,7 +30,8 @@ import (
 )
 
 // GetBlobAvailableSigningKeys returns all available keys that can sign
-func (s *SigningService) GetBlobAvailableSigningKeys(ctx context.Context, e *empty.Empty) (*proto.KeyMetas, error) {
+// If the filter is not empty, only the keys matching it are returned.
+func (s *SigningService) GetBlobAvailableSigningKeys(ctx context.Context, filter *proto.KeyFilter) (*proto.KeyMetas, error) {
 	const methodName = "GetBlobAvailableSigningKeys"
 	statusCode := http.StatusOK
 	start := time.Now()
@@ -31,11 +42,7 @@ func (s *SigningService) GetBlobAvailableSigningKeys(ctx context.Context, e *emp
 	}()
 	defer recoverIfPanicked(methodName)
 
-	var keys []*proto.KeyMeta
-	for id := range s.KeyUsages[config.BlobEndpoint] {
-		keys = append(keys, &proto.KeyMeta{Identifier: id})
-	}
-	return &proto.KeyMetas{Keys: keys}, nil
+	return &proto.KeyMetas{Keys: s.availableKeys(config.BlobEndpoint, filter)}, nil
 }
 
 // GetBlobSigningKey returns the public signing key of the
@@ -57,18 +64,42 @@ func (s *SigningService) GetBlobSigningKey(ctx context.Context, keyMeta *proto.K
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
+	if err = s.checkKeyTransition(keyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
 	if !s.KeyUsages[config.BlobEndpoint][keyMeta.Identifier] {
 		statusCode = http.StatusBadRequest
 		err = fmt.Errorf("cannot use key %q for %q", keyMeta.Identifier, config.BlobEndpoint)
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	key, err := s.GetBlobSigningPublicKey(keyMeta.Identifier)
+	key, err := s.PublicKeyCache.get(cachedBlobKey, keyMeta.Identifier, s.GetBlobSigningPublicKey)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	format, err := publicKeyFormat(ctx, keyMeta.Format)
+	if err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, err
+	}
+	encoded, err := encodePublicKey(key, keyMeta.Identifier, format)
 	if err != nil {
 		statusCode = http.StatusInternalServerError
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, s.internalError(err)
 	}
-	return &proto.PublicKey{Key: string(key)}, nil
+	var cert string
+	if keyMeta.IncludeCertificate {
+		var ok bool
+		if cert, ok = s.BlobSigningCerts[keyMeta.Identifier]; !ok {
+			statusCode = http.StatusNotFound
+			err = fmt.Errorf("key %q has no signing certificate", keyMeta.Identifier)
+			return nil, status.Errorf(codes.NotFound, "Not found: %v", err)
+		}
+	}
+	return &proto.PublicKey{Key: encoded, Certificate: cert}, nil
 }
 
 // PostSignBlob signs the digest using the specified key.
@@ -79,37 +110,422 @@ func (s *SigningService) PostSignBlob(ctx context.Context, request *proto.BlobSi
 	var err error
 
 	defer func() {
-		log.Printf(`m=%s,digest=%q,hash=%q,st=%d,et=%d,err="%v"`, methodName, request.GetDigest(), request.HashAlgorithm.String(), statusCode, timeElapsedSince(start), err)
+		log.Printf(`m=%s,digest=%q,hash=%q,st=%d,et=%d,err="%v"`, methodName, s.redact(request.GetDigest()), request.HashAlgorithm.String(), statusCode, timeElapsedSince(start), err)
 	}()
 	defer recoverIfPanicked(methodName)
 
+	if err = s.checkEndpointEnabled(config.BlobEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
 	if request.KeyMeta == nil {
 		statusCode = http.StatusBadRequest
 		err = fmt.Errorf("request.keyMeta is empty for %q", config.BlobEndpoint)
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	if !s.KeyUsages[config.BlobEndpoint][request.KeyMeta.Identifier] {
+	digest, err := decodeDigest(request.GetDigest(), request.GetDigestEncoding())
+	if err != nil {
 		statusCode = http.StatusBadRequest
-		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.BlobEndpoint)
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	digest, err := base64.StdEncoding.DecodeString(request.GetDigest())
-	if err != nil {
+	if err = s.checkDigest(digest); err != nil {
 		statusCode = http.StatusBadRequest
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	signerOpts := getSignerOpts(request.HashAlgorithm.String())
-	signature, err := s.Sign(digest, signerOpts, request.KeyMeta.Identifier)
+	if len(request.CoSignerKeyMetas) != 0 {
+		var signature *proto.Signature
+		signature, statusCode, err = s.coSignBlob(ctx, request, digest)
+		return signature, err
+	}
+	var signature string
+	if signature, statusCode, err = s.signBlobWithKey(ctx, request, request.KeyMeta.Identifier, digest); err != nil {
+		return nil, err
+	}
+	return &proto.Signature{Signature: signature}, nil
+}
+
+// decodeDigest decodes the digest of a blob signing request in the encoding.
+func decodeDigest(digest string, encoding proto.DigestEncoding) ([]byte, error) {
+	switch encoding {
+	case proto.DigestEncoding_Unspecified_DigestEncoding, proto.DigestEncoding_BASE64:
+		return base64.StdEncoding.DecodeString(digest)
+	case proto.DigestEncoding_HEX:
+		decoded, err := hex.DecodeString(digest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex digest: %v", err)
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("unknown digest encoding %v", encoding)
+}
+
+// signBlobWithKey checks the blob signing request against the key of the identifier and signs its digest with it.
+// It returns the base64 encoded signature, or a status error, along with the HTTP status code of the result.
+func (s *SigningService) signBlobWithKey(ctx context.Context, request *proto.BlobSigningRequest, identifier string, digest []byte) (string, int, error) {
+	if err := s.checkKeyTransition(identifier); err != nil {
+		return "", http.StatusServiceUnavailable, err
+	}
+
+	if !s.KeyUsages[config.BlobEndpoint][identifier] {
+		err := fmt.Errorf("cannot use key %q for %q", identifier, config.BlobEndpoint)
+		return "", http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err := s.checkDigestAlgorithm(identifier, request.HashAlgorithm, digest); err != nil {
+		return "", http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err := s.RequiredFields.check(config.BlobEndpoint, request); err != nil {
+		return "", http.StatusBadRequest, err
+	}
+
+	// The signature scheme and the signer options are resolved for each key of a request with co-signers.
+	keyRequest := *request
+	keyRequest.KeyMeta = &proto.KeyMeta{Identifier: identifier}
+	var err error
+	keyRequest.SignatureScheme, err = signatureScheme(ctx, request.SignatureScheme, s.Keys[identifier].KeyType == crypki.ECDSA)
 	if err != nil {
-		statusCode = http.StatusInternalServerError
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return "", http.StatusBadRequest, err
+	}
+	if err = s.checkCallerSignatureScheme(ctx, identifier, keyRequest.SignatureScheme); err != nil {
+		return "", http.StatusForbidden, err
+	}
+	signerOpts, err := s.getBlobSignerOpts(&keyRequest)
+	if err != nil {
+		return "", http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err = s.checkHashFloor(identifier, signerOpts.HashFunc()); err != nil {
+		return "", http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	s.logSignerOpts(identifier, keyRequest.SignatureScheme, signerOpts)
+	if err = s.checkSigningWindow(identifier); err != nil {
+		return "", runtime.HTTPStatusFromCode(status.Code(err)), err
+	}
+	if err = s.checkBreaker(identifier); err != nil {
+		return "", http.StatusServiceUnavailable, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	if statusCode, err := s.checkReplay(identifier, digest); err != nil {
+		return "", statusCode, err
+	}
+	signature, err := s.signBlob(ctx, digest, signerOpts, identifier, request.Priority)
+	s.recordSignResult(identifier, err)
+	if err != nil {
+		s.forgetReplay(identifier, digest)
+		return "", http.StatusInternalServerError, s.internalError(err)
+	}
+	if keyRequest.SignatureScheme == proto.SignatureScheme_ECDSA_P1363 {
+		var key []byte
+		if key, err = s.PublicKeyCache.get(cachedBlobKey, identifier, s.GetBlobSigningPublicKey); err == nil {
+			signature, err = ecdsaP1363(signature, key)
+		}
+		if err != nil {
+			return "", http.StatusInternalServerError, s.internalError(err)
+		}
+	}
+	return base64.StdEncoding.EncodeToString(signature), http.StatusCreated, nil
+}
+
+// coSignBlob signs the digest of a blob signing request with the key of its key_meta and each of its co-signers.
+// In strict mode, the request fails with the error of the first key that fails to sign. In best-effort mode,
+// the signatures of the keys that signed are returned along with the errors of the others, and the request
+// fails only if no key signed.
+func (s *SigningService) coSignBlob(ctx context.Context, request *proto.BlobSigningRequest, digest []byte) (*proto.Signature, int, error) {
+	identifiers := []string{request.KeyMeta.Identifier}
+	seen := map[string]bool{request.KeyMeta.Identifier: true}
+	for _, keyMeta := range request.CoSignerKeyMetas {
+		if keyMeta == nil || seen[keyMeta.Identifier] {
+			err := fmt.Errorf("empty or duplicate co-signer key for %q", config.BlobEndpoint)
+			return nil, http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		}
+		seen[keyMeta.Identifier] = true
+		identifiers = append(identifiers, keyMeta.Identifier)
+	}
+	bestEffort := s.partialAvailability(request) == proto.PartialAvailability_BEST_EFFORT
+
+	response := &proto.Signature{}
+	var firstCode int
+	var firstErr error
+	for _, id := range identifiers {
+		signature, code, err := s.signBlobWithKey(ctx, request, id, digest)
+		if err != nil && !bestEffort {
+			return nil, code, err
+		}
+		result := &proto.KeySignature{Identifier: id, Signature: signature}
+		if err != nil {
+			log.Printf("m=PostSignBlob,id=%q: co-signing failed: %v", id, err)
+			result.Error = status.Convert(err).Message()
+			if firstErr == nil {
+				firstCode, firstErr = code, err
+			}
+		} else if id == request.KeyMeta.Identifier {
+			response.Signature = signature
+		}
+		response.Signatures = append(response.Signatures, result)
+	}
+	for _, result := range response.Signatures {
+		if result.Error == "" {
+			return response, http.StatusCreated, nil
+		}
+	}
+	return nil, firstCode, firstErr
+}
+
+// partialAvailability returns the behavior of the blob signing request if some of its keys fail to sign:
+// the one of the request, if specified, or else the one configured for the key of its key_meta.
+func (s *SigningService) partialAvailability(request *proto.BlobSigningRequest) proto.PartialAvailability {
+	if request.PartialAvailability != proto.PartialAvailability_Unspecified_PartialAvailability {
+		return request.PartialAvailability
+	}
+	if s.Keys[request.KeyMeta.Identifier].PartialAvailability == config.PartialAvailabilityBestEffort {
+		return proto.PartialAvailability_BEST_EFFORT
+	}
+	return proto.PartialAvailability_STRICT
+}
+
+// signBlob signs the digest with the key and checks that the signature returned by the HSM is
+// plausible for the key. If RetryInvalidSignatures is set, an implausible signature is retried once.
+func (s *SigningService) signBlob(ctx context.Context, digest []byte, opts crypto.SignerOpts, identifier string, priority proto.Priority) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		signature, err := s.prioritized(ctx, priority).Sign(digest, opts, identifier)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.checkSignature(signature, identifier); err == nil {
+			return signature, nil
+		}
+		if !s.RetryInvalidSignatures || attempt > 0 {
+			return nil, err
+		}
+		log.Printf("retrying to sign with key %q: %v", identifier, err)
+	}
+}
+
+// checkSignature returns an error if the signature is implausible for the public key of the key,
+// i.e. if an RSA signature is not as long as the modulus, an ECDSA signature is not a valid DER
+// encoded (r, s) pair or an Ed25519 signature is not 64 bytes. The check is skipped if the public key cannot be fetched from the signer.
+func (s *SigningService) checkSignature(signature []byte, identifier string) error {
+	pkg, ok := s.CertSign.(crypki.PublicKeyGetter)
+	if !ok {
+		return nil
+	}
+	pub, err := pkg.PublicKey(identifier)
+	if err != nil {
+		return fmt.Errorf("unable to get public key of %q: %v", identifier, err)
+	}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if size := (pub.N.BitLen() + 7) / 8; len(signature) != size {
+			return fmt.Errorf("invalid RSA signature of key %q: got %d bytes, want %d", identifier, len(signature), size)
+		}
+	case ed25519.PublicKey:
+		if len(signature) != ed25519.SignatureSize {
+			return fmt.Errorf("invalid Ed25519 signature of key %q: got %d bytes, want %d", identifier, len(signature), ed25519.SignatureSize)
+		}
+	case *ecdsa.PublicKey:
+		var sig struct{ R, S *big.Int }
+		rest, err := asn1.Unmarshal(signature, &sig)
+		if err != nil {
+			return fmt.Errorf("invalid ECDSA signature of key %q: %v", identifier, err)
+		}
+		n := pub.Curve.Params().N
+		if len(rest) != 0 || sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Cmp(n) >= 0 {
+			return fmt.Errorf("invalid ECDSA signature of key %q", identifier)
+		}
 	}
+	return nil
+}
+
+// checkDigest returns an error if the digest is suspicious. A hash function never outputs an empty or
+// all-zero digest in practice, so such a digest is almost certainly the uninitialized buffer of a buggy
+// client, and signing it would let anyone reuse the signature for every other request with the same bug.
+// The check is skipped if AllowZeroDigests is set. If RejectLowEntropyDigests is set, digests made of
+// few distinct byte values, which is implausible for the uniformly distributed output of a hash
+// function, are rejected as well.
+func (s *SigningService) checkDigest(digest []byte) error {
+	if !s.AllowZeroDigests {
+		if len(digest) == 0 {
+			return errors.New("empty digest")
+		}
+		if bytes.Count(digest, []byte{0}) == len(digest) {
+			return errors.New("all-zero digest")
+		}
+	}
+	if s.RejectLowEntropyDigests && len(digest) != 0 {
+		seen := make(map[byte]bool)
+		for _, b := range digest {
+			seen[b] = true
+		}
+		// A uniformly random digest of 20 bytes or more has fewer distinct bytes than a quarter of
+		// its length with negligible probability.
+		if len(seen) < len(digest)/4 {
+			return fmt.Errorf("low entropy digest: only %d distinct byte values", len(seen))
+		}
+	}
+	return nil
+}
+
+// checkDigestAlgorithm returns an error unless the hash algorithm of a blob signing request is approved for
+// the type of the key and the digest has the length of that hash, so that no inconsistent request reaches
+// the HSM. Unless StrictBlobDigests is set, only the requests for Ed25519 and post-quantum keys are checked,
+// as Ed25519ph always signs SHA512 digests and the pre-hash post-quantum schemes do not sign short digests,
+// and an unspecified hash algorithm stands for SHA512.
+func (s *SigningService) checkDigestAlgorithm(identifier string, hashAlgo proto.HashAlgo, digest []byte) error {
+	key := s.Keys[identifier]
+	if !s.StrictBlobDigests && key.KeyType != crypki.Ed25519 && !key.KeyType.IsPostQuantum() {
+		return nil
+	}
+	if hashAlgo == proto.HashAlgo_Unspecified_Hash {
+		if s.StrictBlobDigests {
+			return errors.New("hash algorithm must be specified")
+		}
+		hashAlgo = proto.HashAlgo_SHA512
+	}
+	approved := false
+	for _, h := range hashAlgorithms(key) {
+		approved = approved || h == hashAlgo
+	}
+	if !approved {
+		return fmt.Errorf("hash algorithm %s is not approved for %s key %q", hashAlgo, protoKeyType(key.KeyType), identifier)
+	}
+	if size := getSignerOpts(hashAlgo.String()).HashFunc().Size(); len(digest) != size {
+		return fmt.Errorf("digest of %d bytes does not match hash algorithm %s: want %d bytes", len(digest), hashAlgo, size)
+	}
+	return nil
+}
+
+// getBlobSignerOpts returns the signer options of the blob signing request,
+// or an error if the signature scheme or the signature context is not supported by the key.
+func (s *SigningService) getBlobSignerOpts(request *proto.BlobSigningRequest) (crypto.SignerOpts, error) {
+	hash := getSignerOpts(request.HashAlgorithm.String())
+	isEd25519 := s.Keys[request.KeyMeta.Identifier].KeyType == crypki.Ed25519
+	if request.Context != "" {
+		if !isEd25519 {
+			return nil, fmt.Errorf("signature context is not supported by key %q", request.KeyMeta.Identifier)
+		}
+		if len(request.Context) > maxSignatureContextSize {
+			return nil, fmt.Errorf("signature context longer than %d bytes", maxSignatureContextSize)
+		}
+	}
+	if request.SignatureScheme == proto.SignatureScheme_Unspecified_SignatureScheme {
+		if isEd25519 {
+			return ed25519phOpts(hash, request.Context)
+		}
+		return hash, nil
+	}
+	for _, scheme := range signatureSchemes(s.Keys[request.KeyMeta.Identifier]) {
+		if scheme != request.SignatureScheme {
+			continue
+		}
+		switch scheme {
+		case proto.SignatureScheme_PSS:
+			if isSHA3(hash.HashFunc()) {
+				return nil, fmt.Errorf("signature scheme %s does not support SHA3 digests", scheme)
+			}
+			saltLength, err := s.pssSaltLength(request.KeyMeta.Identifier, hash.HashFunc())
+			if err != nil {
+				return nil, err
+			}
+			return &rsa.PSSOptions{SaltLength: saltLength, Hash: hash.HashFunc()}, nil
+		case proto.SignatureScheme_Ed25519ph:
+			return ed25519phOpts(hash, request.Context)
+		}
+		return hash, nil
+	}
+	return nil, fmt.Errorf("signature scheme %s is not supported by key %q", request.SignatureScheme, request.KeyMeta.Identifier)
+}
+
+// logSignerOpts logs the hash, signature scheme, salt length and signature encoding resolved for signing
+// with the key, if LogSignerOpts is set. The values not applicable to the scheme are logged as "-".
+func (s *SigningService) logSignerOpts(identifier string, scheme proto.SignatureScheme, opts crypto.SignerOpts) {
+	if !s.LogSignerOpts {
+		return
+	}
+	saltLength, encoding := "-", "raw"
+	switch o := opts.(type) {
+	case *rsa.PSSOptions:
+		scheme = proto.SignatureScheme_PSS
+		saltLength = strconv.Itoa(o.SaltLength)
+		if o.SaltLength == rsa.PSSSaltLengthEqualsHash {
+			saltLength = strconv.Itoa(o.Hash.Size())
+		}
+	case *ed25519.Options:
+		scheme = proto.SignatureScheme_Ed25519ph
+	}
+	if scheme == proto.SignatureScheme_Unspecified_SignatureScheme {
+		scheme = proto.SignatureScheme_PKCS1v15
+		switch s.Keys[identifier].KeyType {
+		case crypki.ECDSA:
+			scheme = proto.SignatureScheme_ECDSA_ASN1
+		case crypki.MLDSA:
+			scheme = proto.SignatureScheme_HashMLDSA
+		case crypki.SLHDSA:
+			scheme = proto.SignatureScheme_HashSLHDSA
+		}
+	}
+	switch scheme {
+	case proto.SignatureScheme_ECDSA_ASN1:
+		encoding = "asn1"
+	case proto.SignatureScheme_ECDSA_P1363:
+		encoding = "p1363"
+	}
+	log.Printf(`signer opts: id=%q,hash=%v,scheme=%s,saltlen=%s,enc=%s`, identifier, opts.HashFunc(), scheme, saltLength, encoding)
+}
+
+// pssSaltLength returns the RSA-PSS salt length configured for the key, or an error if it exceeds the maximum
+// length allowed by the size of the key and the hash. The maximum length is resolved to an explicit length,
+// as PKCS#11 mechanisms take one. An explicit length is not checked if the public key cannot be fetched from the signer.
+func (s *SigningService) pssSaltLength(identifier string, hash crypto.Hash) (int, error) {
+	saltLength, err := s.Keys[identifier].PSSSaltLengthValue()
+	if err != nil || saltLength == rsa.PSSSaltLengthEqualsHash {
+		return saltLength, err
+	}
+	pkg, ok := s.CertSign.(crypki.PublicKeyGetter)
+	if !ok {
+		if saltLength == rsa.PSSSaltLengthAuto {
+			return 0, fmt.Errorf("unable to get the key size of %q for the maximum PSS salt length", identifier)
+		}
+		return saltLength, nil
+	}
+	pub, err := pkg.PublicKey(identifier)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get public key of %q: %v", identifier, err)
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return 0, fmt.Errorf("key %q is not an RSA key", identifier)
+	}
+	// As per RFC 8017 section 9.1.1, the encoded message of emBits = modBits - 1 holds the hash,
+	// the salt and 2 more bytes.
+	maxLength := (rsaPub.N.BitLen()-1+7)/8 - hash.Size() - 2
+	switch {
+	case maxLength < 1:
+		return 0, fmt.Errorf("key %q is too small for PSS signatures with %v", identifier, hash)
+	case saltLength == rsa.PSSSaltLengthAuto:
+		return maxLength, nil
+	case saltLength > maxLength:
+		return 0, fmt.Errorf("PSS salt length %d of key %q exceeds the maximum %d with %v", saltLength, identifier, maxLength, hash)
+	}
+	return saltLength, nil
+}
+
+// maxSignatureContextSize is the maximum size in bytes of an Ed25519 context as per RFC 8032.
+const maxSignatureContextSize = 255
+
+// ed25519phOpts returns the signer options of an Ed25519ph request with the context,
+// which must sign a SHA512 digest.
+func ed25519phOpts(hash crypto.SignerOpts, context string) (crypto.SignerOpts, error) {
+	if hash.HashFunc() != crypto.SHA512 {
+		return nil, fmt.Errorf("signature scheme %s requires a SHA512 digest, got %v", proto.SignatureScheme_Ed25519ph, hash.HashFunc())
+	}
+	return &ed25519.Options{Hash: crypto.SHA512, Context: context}, nil
+}
 
-	base64Signature := base64.StdEncoding.EncodeToString(signature)
-	return &proto.Signature{Signature: base64Signature}, nil
+// isSHA3 returns true if the hash function is of the SHA3 family.
+func isSHA3(hash crypto.Hash) bool {
+	return hash == crypto.SHA3_224 || hash == crypto.SHA3_256 || hash == crypto.SHA3_384 || hash == crypto.SHA3_512
 }
 
 func getSignerOpts(hashAlgo string) crypto.SignerOpts {
@@ -122,6 +538,14 @@ func getSignerOpts(hashAlgo string) crypto.SignerOpts {
 		return crypto.SHA384
 	case "SHA512":
 		return crypto.SHA512
+	case "SHA3_224":
+		return crypto.SHA3_224
+	case "SHA3_256":
+		return crypto.SHA3_256
+	case "SHA3_384":
+		return crypto.SHA3_384
+	case "SHA3_512":
+		return crypto.SHA3_512
 	default:
 		return crypto.SHA512
 	}
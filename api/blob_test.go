@@ -3,14 +3,36 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
 	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetBlobAvailableSigningKeys(t *testing.T) {
@@ -56,8 +78,8 @@ func TestGetBlobAvailableSigningKeys(t *testing.T) {
 			mssp := mockSigningServiceParam{KeyUsages: tt.KeyUsages, sendError: false}
 			ss := initMockSigningService(mssp)
 			var ctx context.Context
-			var e *empty.Empty
-			keyMetas, err := ss.GetBlobAvailableSigningKeys(ctx, e)
+			var filter *proto.KeyFilter
+			keyMetas, err := ss.GetBlobAvailableSigningKeys(ctx, filter)
 			if err != nil {
 				t.Fatal(err)
 				return
@@ -75,6 +97,43 @@ func TestGetBlobAvailableSigningKeys(t *testing.T) {
 	}
 }
 
+func TestGetBlobAvailableSigningKeysFilter(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{
+		CertSign:       &mockGoodCertSign{},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      combineKeyUsage,
+		Keys: map[string]config.KeyConfig{
+			"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA, Tenant: "media"},
+			"blobid2": {Identifier: "blobid2", KeyType: crypki.ECDSA, Tenant: "ads"},
+		},
+	}
+	testcases := map[string]struct {
+		filter *proto.KeyFilter
+		expect []string
+	}{
+		"no-filter":      {filter: &proto.KeyFilter{}, expect: []string{"blobid1", "blobid2"}},
+		"key-type":       {filter: &proto.KeyFilter{KeyType: proto.KeyType_ECDSA}, expect: []string{"blobid2"}},
+		"no-match":       {filter: &proto.KeyFilter{KeyType: proto.KeyType_Ed25519}},
+		"tenant":         {filter: &proto.KeyFilter{Tenant: "media"}, expect: []string{"blobid1"}},
+		"key-and-tenant": {filter: &proto.KeyFilter{KeyType: proto.KeyType_RSA, Tenant: "ads"}},
+	}
+	for label, tt := range testcases {
+		keyMetas, err := ss.GetBlobAvailableSigningKeys(context.Background(), tt.filter)
+		if err != nil {
+			t.Fatalf("in test %v: unable to list keys: %v", label, err)
+		}
+		var got []string
+		for _, key := range keyMetas.Keys {
+			got = append(got, key.Identifier)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("in test %v: got keys %v, want %v", label, got, tt.expect)
+		}
+	}
+}
+
 func TestGetBlobSigningKey(t *testing.T) {
 	t.Parallel()
 	testcases := map[string]struct {
@@ -179,6 +238,7 @@ func TestPostSignBlob(t *testing.T) {
 			KeyUsages:         blobkeyUsage,
 			KeyMeta:           &proto.KeyMeta{Identifier: "blobid"},
 			expectedSignature: &proto.Signature{Signature: base64.StdEncoding.EncodeToString([]byte("good blob signature"))},
+			Digest:            testSHA512Digest,
 		},
 		"blobUsagesBadDigest": {
 			KeyUsages:         blobkeyUsage,
@@ -200,6 +260,7 @@ func TestPostSignBlob(t *testing.T) {
 			KeyUsages:         combineKeyUsage,
 			KeyMeta:           &proto.KeyMeta{Identifier: "blobid1"},
 			expectedSignature: &proto.Signature{Signature: base64.StdEncoding.EncodeToString([]byte("good blob signature"))},
+			Digest:            testSHA512Digest,
 		},
 		"combineKeyUsagesWithFalseIdSet": {
 			KeyUsages:         combineKeyUsage,
@@ -242,3 +303,781 @@ func TestPostSignBlob(t *testing.T) {
 		})
 	}
 }
+
+func TestPostSignBlobSignatureScheme(t *testing.T) {
+	t.Parallel()
+	keys := map[string]config.KeyConfig{
+		"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA, AllowPSS: true},
+		"blobid2": {Identifier: "blobid2", KeyType: crypki.RSA},
+		"mldsa":   {Identifier: "mldsa", KeyType: crypki.MLDSA},
+	}
+	keyUsages := map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true, "mldsa": true}}
+	testcases := map[string]struct {
+		identifier   string
+		scheme       proto.SignatureScheme
+		expectedCode codes.Code
+		expectedOpts crypto.SignerOpts
+	}{
+		"default-scheme": {"blobid2", proto.SignatureScheme_Unspecified_SignatureScheme, codes.OK, crypto.SHA256},
+		"pkcs1v15":       {"blobid2", proto.SignatureScheme_PKCS1v15, codes.OK, crypto.SHA256},
+		"pss-enabled":    {"blobid1", proto.SignatureScheme_PSS, codes.OK, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}},
+		"pss-disabled":   {"blobid2", proto.SignatureScheme_PSS, codes.InvalidArgument, nil},
+		"wrong-key-type": {"blobid1", proto.SignatureScheme_ECDSA_ASN1, codes.InvalidArgument, nil},
+		"mldsa-default":  {"mldsa", proto.SignatureScheme_Unspecified_SignatureScheme, codes.OK, crypto.SHA256},
+		"hash-mldsa":     {"mldsa", proto.SignatureScheme_HashMLDSA, codes.OK, crypto.SHA256},
+		"mldsa-pss":      {"mldsa", proto.SignatureScheme_PSS, codes.InvalidArgument, nil},
+		"rsa-hash-mldsa": {"blobid2", proto.SignatureScheme_HashMLDSA, codes.InvalidArgument, nil},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys}
+			request := &proto.BlobSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: tt.identifier},
+				Digest:          testSHA256Digest,
+				HashAlgorithm:   proto.HashAlgo_SHA256,
+				SignatureScheme: tt.scheme,
+			}
+			_, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if !reflect.DeepEqual(signer.opts, tt.expectedOpts) {
+				t.Errorf("in test %v: got signer opts %#v, want %#v", label, signer.opts, tt.expectedOpts)
+			}
+		})
+	}
+}
+
+func TestPostSignBlobPSSSaltLength(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	certSign := &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey}}}, rsaKey}
+	keyUsages := map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}}
+	testcases := map[string]struct {
+		saltLength   string
+		hash         proto.HashAlgo
+		expectedCode codes.Code
+		verifyOpts   *rsa.PSSOptions
+	}{
+		"default":          {hash: proto.HashAlgo_SHA256, verifyOpts: &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}},
+		"equals-hash":      {saltLength: config.PSSSaltLengthEqualsHash, hash: proto.HashAlgo_SHA512, verifyOpts: &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}},
+		"max-sha256":       {saltLength: config.PSSSaltLengthMax, hash: proto.HashAlgo_SHA256, verifyOpts: &rsa.PSSOptions{SaltLength: 256 - 32 - 2}},
+		"max-sha512":       {saltLength: config.PSSSaltLengthMax, hash: proto.HashAlgo_SHA512, verifyOpts: &rsa.PSSOptions{SaltLength: 256 - 64 - 2}},
+		"explicit":         {saltLength: "20", hash: proto.HashAlgo_SHA256, verifyOpts: &rsa.PSSOptions{SaltLength: 20}},
+		"explicit-max":     {saltLength: "222", hash: proto.HashAlgo_SHA256, verifyOpts: &rsa.PSSOptions{SaltLength: 222}},
+		"explicit-too-big": {saltLength: "223", hash: proto.HashAlgo_SHA256, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			keys := map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA, AllowPSS: true, PSSSaltLength: tt.saltLength}}
+			ss := &SigningService{CertSign: certSign, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys}
+			hash := getSignerOpts(tt.hash.String()).HashFunc()
+			h := hash.New()
+			h.Write([]byte("good"))
+			digest := h.Sum(nil)
+			request := &proto.BlobSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: "blobid1"},
+				Digest:          base64.StdEncoding.EncodeToString(digest),
+				HashAlgorithm:   tt.hash,
+				SignatureScheme: proto.SignatureScheme_PSS,
+			}
+			resp, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			signature, err := base64.StdEncoding.DecodeString(resp.Signature)
+			if err != nil {
+				t.Fatalf("in test %v: unable to decode signature: %v", label, err)
+			}
+			if err := rsa.VerifyPSS(&rsaKey.PublicKey, hash, digest, signature, tt.verifyOpts); err != nil {
+				t.Errorf("in test %v: unable to verify signature with salt length %d: %v", label, tt.verifyOpts.SaltLength, err)
+			}
+		})
+	}
+}
+
+func TestPostSignBlobDigestAlgorithm(t *testing.T) {
+	t.Parallel()
+	keys := map[string]config.KeyConfig{
+		"rsa":     {Identifier: "rsa", KeyType: crypki.RSA},
+		"ecdsa":   {Identifier: "ecdsa", KeyType: crypki.ECDSA},
+		"ed25519": {Identifier: "ed25519", KeyType: crypki.Ed25519},
+		"mldsa":   {Identifier: "mldsa", KeyType: crypki.MLDSA},
+	}
+	keyUsages := map[string]map[string]bool{config.BlobEndpoint: {"rsa": true, "ecdsa": true, "ed25519": true, "mldsa": true}}
+	sha224Digest := sha256.Sum224([]byte("good"))
+	sha256Digest := sha256.Sum256([]byte("good"))
+	sha512Digest := sha512.Sum512([]byte("good"))
+	testcases := map[string]struct {
+		strict          bool
+		identifier      string
+		hash            proto.HashAlgo
+		digest          []byte
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		"strict-rsa-sha256":        {true, "rsa", proto.HashAlgo_SHA256, sha256Digest[:], codes.OK, ""},
+		"strict-ecdsa-sha224":      {true, "ecdsa", proto.HashAlgo_SHA224, sha224Digest[:], codes.OK, ""},
+		"strict-ed25519-sha512":    {true, "ed25519", proto.HashAlgo_SHA512, sha512Digest[:], codes.OK, ""},
+		"strict-unspecified-hash":  {true, "rsa", proto.HashAlgo_Unspecified_Hash, sha512Digest[:], codes.InvalidArgument, "hash algorithm must be specified"},
+		"strict-rsa-sha224":        {true, "rsa", proto.HashAlgo_SHA224, sha224Digest[:], codes.InvalidArgument, `hash algorithm SHA224 is not approved for RSA key "rsa"`},
+		"strict-ed25519-sha256":    {true, "ed25519", proto.HashAlgo_SHA256, sha256Digest[:], codes.InvalidArgument, `hash algorithm SHA256 is not approved for Ed25519 key "ed25519"`},
+		"strict-rsa-short-digest":  {true, "rsa", proto.HashAlgo_SHA256, sha256Digest[:20], codes.InvalidArgument, "digest of 20 bytes does not match hash algorithm SHA256: want 32 bytes"},
+		"strict-ecdsa-long-digest": {true, "ecdsa", proto.HashAlgo_SHA384, sha512Digest[:], codes.InvalidArgument, "digest of 64 bytes does not match hash algorithm SHA384: want 48 bytes"},
+		"rsa-unspecified-hash":     {false, "rsa", proto.HashAlgo_Unspecified_Hash, sha256Digest[:], codes.OK, ""},
+		"rsa-short-digest":         {false, "rsa", proto.HashAlgo_SHA256, sha256Digest[:20], codes.OK, ""},
+		"ed25519-unspecified-hash": {false, "ed25519", proto.HashAlgo_Unspecified_Hash, sha512Digest[:], codes.OK, ""},
+		"ed25519-short-digest":     {false, "ed25519", proto.HashAlgo_SHA512, sha512Digest[:32], codes.InvalidArgument, "digest of 32 bytes does not match hash algorithm SHA512: want 64 bytes"},
+		"mldsa-sha256":             {false, "mldsa", proto.HashAlgo_SHA256, sha256Digest[:], codes.OK, ""},
+		"mldsa-sha224":             {false, "mldsa", proto.HashAlgo_SHA224, sha224Digest[:], codes.InvalidArgument, `hash algorithm SHA224 is not approved for MLDSA key "mldsa"`},
+		"mldsa-short-digest":       {false, "mldsa", proto.HashAlgo_SHA384, sha256Digest[:], codes.InvalidArgument, "digest of 32 bytes does not match hash algorithm SHA384: want 48 bytes"},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockKeyRecordingCertSign{}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys, StrictBlobDigests: tt.strict}
+			request := &proto.BlobSigningRequest{
+				KeyMeta:       &proto.KeyMeta{Identifier: tt.identifier},
+				Digest:        base64.StdEncoding.EncodeToString(tt.digest),
+				HashAlgorithm: tt.hash,
+			}
+			_, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if msg := status.Convert(err).Message(); !strings.HasSuffix(msg, tt.expectedMessage) {
+				t.Errorf("in test %v: got message %q, want %q", label, msg, tt.expectedMessage)
+			}
+			if err != nil && len(signer.ids) != 0 {
+				t.Errorf("in test %v: inconsistent request was signed by the HSM", label)
+			}
+		})
+	}
+}
+
+func TestPostSignBlobDigestEncoding(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	ss := &SigningService{
+		CertSign:       &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey}}}, rsaKey},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}},
+		Keys:           map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA}},
+	}
+	digest := sha256.Sum256([]byte("good"))
+	testcases := map[string]struct {
+		digest       string
+		encoding     proto.DigestEncoding
+		expectedCode codes.Code
+	}{
+		"unspecified":      {base64.StdEncoding.EncodeToString(digest[:]), proto.DigestEncoding_Unspecified_DigestEncoding, codes.OK},
+		"base64":           {base64.StdEncoding.EncodeToString(digest[:]), proto.DigestEncoding_BASE64, codes.OK},
+		"hex":              {hex.EncodeToString(digest[:]), proto.DigestEncoding_HEX, codes.OK},
+		"hex-upper":        {strings.ToUpper(hex.EncodeToString(digest[:])), proto.DigestEncoding_HEX, codes.OK},
+		"invalid-base64":   {"not base64!", proto.DigestEncoding_BASE64, codes.InvalidArgument},
+		"base64-as-hex":    {base64.StdEncoding.EncodeToString(digest[:]), proto.DigestEncoding_HEX, codes.InvalidArgument},
+		"hex-odd-length":   {hex.EncodeToString(digest[:])[1:], proto.DigestEncoding_HEX, codes.InvalidArgument},
+		"unknown-encoding": {hex.EncodeToString(digest[:]), proto.DigestEncoding(42), codes.InvalidArgument},
+	}
+	expected, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("unable to sign digest: %v", err)
+	}
+	for label, tt := range testcases {
+		resp, err := ss.PostSignBlob(context.Background(), &proto.BlobSigningRequest{
+			KeyMeta:        &proto.KeyMeta{Identifier: "blobid1"},
+			Digest:         tt.digest,
+			HashAlgorithm:  proto.HashAlgo_SHA256,
+			DigestEncoding: tt.encoding,
+		})
+		if got := status.Code(err); got != tt.expectedCode {
+			t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		// PKCS #1 v1.5 signatures are deterministic, so every encoding of the digest has the same signature.
+		if resp.Signature != base64.StdEncoding.EncodeToString(expected) {
+			t.Errorf("in test %v: got signature %q, want the signature of the decoded digest", label, resp.Signature)
+		}
+	}
+}
+
+// mockSequenceCertSign is a mockPublicKeyCertSign whose blob signing returns the signatures in turn.
+type mockSequenceCertSign struct {
+	mockPublicKeyCertSign
+	signatures [][]byte
+	calls      int
+}
+
+func (m *mockSequenceCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	signature := m.signatures[m.calls%len(m.signatures)]
+	m.calls++
+	return signature, nil
+}
+
+func TestPostSignBlobInvalidSignature(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	goodRSASig := make([]byte, 256)
+	digest := sha256.Sum256([]byte("blob"))
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatalf("unable to sign: %v", err)
+	}
+	goodECSig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatalf("unable to marshal ECDSA signature: %v", err)
+	}
+	garbage := []byte("short")
+	keys := map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey, "blobid2": &ecKey.PublicKey}
+	testcases := map[string]struct {
+		identifier    string
+		signatures    [][]byte
+		retry         bool
+		expectedCode  codes.Code
+		expectedCalls int
+	}{
+		"rsa-good":                {"blobid1", [][]byte{goodRSASig}, false, codes.OK, 1},
+		"rsa-short":               {"blobid1", [][]byte{goodRSASig[:255]}, false, codes.Internal, 1},
+		"rsa-garbage-retried":     {"blobid1", [][]byte{garbage, goodRSASig}, true, codes.OK, 2},
+		"rsa-garbage-retry-fails": {"blobid1", [][]byte{garbage}, true, codes.Internal, 2},
+		"ecdsa-good":              {"blobid2", [][]byte{goodECSig}, false, codes.OK, 1},
+		"ecdsa-garbage":           {"blobid2", [][]byte{garbage}, false, codes.Internal, 1},
+		"ecdsa-truncated":         {"blobid2", [][]byte{goodECSig[:len(goodECSig)-1]}, false, codes.Internal, 1},
+		"ecdsa-trailing-data":     {"blobid2", [][]byte{append(append([]byte{}, goodECSig...), 0)}, false, codes.Internal, 1},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockSequenceCertSign{mockPublicKeyCertSign: mockPublicKeyCertSign{keys: keys}, signatures: tt.signatures}
+			ss := &SigningService{
+				CertSign:               signer,
+				KeyIDProcessor:         &crypki.KeyID{},
+				KeyUsages:              map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+				RetryInvalidSignatures: tt.retry,
+			}
+			request := &proto.BlobSigningRequest{
+				KeyMeta:       &proto.KeyMeta{Identifier: tt.identifier},
+				Digest:        base64.StdEncoding.EncodeToString(digest[:]),
+				HashAlgorithm: proto.HashAlgo_SHA256,
+			}
+			_, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if signer.calls != tt.expectedCalls {
+				t.Errorf("in test %v: got %d sign calls, want %d", label, signer.calls, tt.expectedCalls)
+			}
+		})
+	}
+}
+
+// mockPEMCertSign is a mockPublicKeyCertSign whose blob signing public keys are PEM encoded.
+type mockPEMCertSign struct {
+	mockPublicKeyCertSign
+}
+
+func (m *mockPEMCertSign) GetBlobSigningPublicKey(keyIdentifier string) ([]byte, error) {
+	pub, err := m.PublicKey(keyIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// decodeTestJWK decodes the public key of the JWK, independently of encodeJWK.
+func decodeTestJWK(t *testing.T, s string) (string, crypto.PublicKey) {
+	t.Helper()
+	var key map[string]string
+	if err := json.Unmarshal([]byte(s), &key); err != nil {
+		t.Fatalf("unable to unmarshal JWK %q: %v", s, err)
+	}
+	param := func(name string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(key[name])
+		if err != nil || len(b) == 0 {
+			t.Fatalf("bad JWK parameter %q in %q: %v", name, s, err)
+		}
+		return new(big.Int).SetBytes(b)
+	}
+	switch key["kty"] {
+	case "RSA":
+		return key["kid"], &rsa.PublicKey{N: param("n"), E: int(param("e").Int64())}
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[key["crv"]]
+		if !ok {
+			t.Fatalf("unexpected JWK curve %q", key["crv"])
+		}
+		if size := (curve.Params().BitSize + 7) / 8; len(key["x"]) != base64.RawURLEncoding.EncodedLen(size) {
+			t.Fatalf("JWK x coordinate %q is not %d bytes", key["x"], size)
+		}
+		return key["kid"], &ecdsa.PublicKey{Curve: curve, X: param("x"), Y: param("y")}
+	}
+	t.Fatalf("unexpected JWK key type %q", key["kty"])
+	return "", nil
+}
+
+func TestGetBlobSigningKeyJWK(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	keys := map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey, "blobid2": &ecKey.PublicKey}
+	ss := &SigningService{
+		CertSign:       &mockPEMCertSign{mockPublicKeyCertSign{keys: keys}},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+	}
+	for _, id := range []string{"blobid1", "blobid2"} {
+		pemKey, err := ss.GetBlobSigningKey(context.Background(), &proto.KeyMeta{Identifier: id})
+		if err != nil {
+			t.Fatalf("%s: unable to get PEM key: %v", id, err)
+		}
+		block, _ := pem.Decode([]byte(pemKey.Key))
+		if block == nil {
+			t.Fatalf("%s: default format is not PEM: %q", id, pemKey.Key)
+		}
+		want, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			t.Fatalf("%s: unable to parse PEM key: %v", id, err)
+		}
+		jwkKey, err := ss.GetBlobSigningKey(context.Background(), &proto.KeyMeta{Identifier: id, Format: proto.PublicKeyFormat_JWK})
+		if err != nil {
+			t.Fatalf("%s: unable to get JWK key: %v", id, err)
+		}
+		kid, got := decodeTestJWK(t, jwkKey.Key)
+		if kid != id {
+			t.Errorf("%s: got kid %q, want %q", id, kid, id)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: JWK %q does not match the PEM key", id, jwkKey.Key)
+		}
+	}
+}
+
+// mockEd25519CertSign is a mockPublicKeyCertSign that signs blobs with an Ed25519 key in software.
+type mockEd25519CertSign struct {
+	mockPublicKeyCertSign
+	key ed25519.PrivateKey
+}
+
+func (m *mockEd25519CertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	return m.key.Sign(nil, digest, opts)
+}
+
+func TestPostSignBlobEd25519ph(t *testing.T) {
+	t.Parallel()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate Ed25519 key: %v", err)
+	}
+	digest := sha512.Sum512([]byte("blob"))
+	keys := map[string]config.KeyConfig{
+		"blobid1": {Identifier: "blobid1", KeyType: crypki.Ed25519},
+		"blobid2": {Identifier: "blobid2", KeyType: crypki.RSA},
+	}
+	testcases := map[string]struct {
+		identifier   string
+		scheme       proto.SignatureScheme
+		hash         proto.HashAlgo
+		digest       []byte
+		expectedCode codes.Code
+	}{
+		"ed25519ph":         {"blobid1", proto.SignatureScheme_Ed25519ph, proto.HashAlgo_SHA512, digest[:], codes.OK},
+		"default-scheme":    {"blobid1", proto.SignatureScheme_Unspecified_SignatureScheme, proto.HashAlgo_SHA512, digest[:], codes.OK},
+		"short-digest":      {"blobid1", proto.SignatureScheme_Ed25519ph, proto.HashAlgo_SHA512, digest[:32], codes.InvalidArgument},
+		"sha256-digest":     {"blobid1", proto.SignatureScheme_Ed25519ph, proto.HashAlgo_SHA256, digest[:32], codes.InvalidArgument},
+		"wrong-scheme":      {"blobid1", proto.SignatureScheme_PKCS1v15, proto.HashAlgo_SHA512, digest[:], codes.InvalidArgument},
+		"rsa-key-ed25519ph": {"blobid2", proto.SignatureScheme_Ed25519ph, proto.HashAlgo_SHA512, digest[:], codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockEd25519CertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": pub}}, priv}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+				Keys:           keys,
+			}
+			request := &proto.BlobSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: tt.identifier},
+				Digest:          base64.StdEncoding.EncodeToString(tt.digest),
+				HashAlgorithm:   tt.hash,
+				SignatureScheme: tt.scheme,
+			}
+			resp, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			signature, err := base64.StdEncoding.DecodeString(resp.Signature)
+			if err != nil {
+				t.Fatalf("in test %v: unable to decode signature: %v", label, err)
+			}
+			if err := ed25519.VerifyWithOptions(pub, tt.digest, signature, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
+				t.Errorf("in test %v: Ed25519ph signature does not verify: %v", label, err)
+			}
+		})
+	}
+}
+
+func TestPostSignBlobContext(t *testing.T) {
+	t.Parallel()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate Ed25519 key: %v", err)
+	}
+	digest := sha512.Sum512([]byte("blob"))
+	ss := &SigningService{
+		CertSign:       &mockEd25519CertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": pub}}, priv},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+		Keys: map[string]config.KeyConfig{
+			"blobid1": {Identifier: "blobid1", KeyType: crypki.Ed25519},
+			"blobid2": {Identifier: "blobid2", KeyType: crypki.RSA},
+		},
+	}
+	sign := func(identifier, ctx string) (string, error) {
+		resp, err := ss.PostSignBlob(context.Background(), &proto.BlobSigningRequest{
+			KeyMeta:       &proto.KeyMeta{Identifier: identifier},
+			Digest:        base64.StdEncoding.EncodeToString(digest[:]),
+			HashAlgorithm: proto.HashAlgo_SHA512,
+			Context:       ctx,
+		})
+		if err != nil {
+			return "", err
+		}
+		return resp.Signature, nil
+	}
+
+	sigs := make(map[string]string)
+	for _, ctx := range []string{"", "a", "b"} {
+		sig, err := sign("blobid1", ctx)
+		if err != nil {
+			t.Fatalf("unable to sign with context %q: %v", ctx, err)
+		}
+		raw, err := base64.StdEncoding.DecodeString(sig)
+		if err != nil {
+			t.Fatalf("unable to decode signature: %v", err)
+		}
+		if err := ed25519.VerifyWithOptions(pub, digest[:], raw, &ed25519.Options{Hash: crypto.SHA512, Context: ctx}); err != nil {
+			t.Errorf("signature with context %q does not verify: %v", ctx, err)
+		}
+		sigs[ctx] = sig
+	}
+	if sigs["a"] == sigs["b"] || sigs["a"] == sigs[""] {
+		t.Errorf("signatures with different contexts are identical: %v", sigs)
+	}
+
+	if _, err := sign("blobid2", "a"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got err %v for a context with an RSA key, want InvalidArgument", err)
+	}
+	if _, err := sign("blobid1", strings.Repeat("a", 256)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got err %v for a context longer than 255 bytes, want InvalidArgument", err)
+	}
+}
+
+func TestPostSignBlobSuspiciousDigest(t *testing.T) {
+	t.Parallel()
+	sum := sha256.Sum256([]byte("good blob"))
+	lowEntropy := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, sha256.Size/4)
+	testcases := map[string]struct {
+		digest                  []byte
+		allowZeroDigests        bool
+		rejectLowEntropyDigests bool
+		expectedCode            codes.Code
+	}{
+		"good":                      {digest: sum[:], expectedCode: codes.OK},
+		"all-zero":                  {digest: make([]byte, sha256.Size), expectedCode: codes.InvalidArgument},
+		"empty":                     {digest: nil, expectedCode: codes.InvalidArgument},
+		"all-zero-allowed":          {digest: make([]byte, sha256.Size), allowZeroDigests: true, expectedCode: codes.OK},
+		"low-entropy-check-off":     {digest: lowEntropy, expectedCode: codes.OK},
+		"low-entropy-check-on":      {digest: lowEntropy, rejectLowEntropyDigests: true, expectedCode: codes.InvalidArgument},
+		"good-low-entropy-check-on": {digest: sum[:], rejectLowEntropyDigests: true, expectedCode: codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:                signer,
+				KeyIDProcessor:          &crypki.KeyID{},
+				KeyUsages:               combineKeyUsage,
+				AllowZeroDigests:        tt.allowZeroDigests,
+				RejectLowEntropyDigests: tt.rejectLowEntropyDigests,
+			}
+			request := &proto.BlobSigningRequest{
+				KeyMeta:       &proto.KeyMeta{Identifier: "blobid1"},
+				Digest:        base64.StdEncoding.EncodeToString(tt.digest),
+				HashAlgorithm: proto.HashAlgo_SHA256,
+			}
+			_, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil && signer.opts != nil {
+				t.Errorf("in test %v: rejected digest was signed", label)
+			}
+		})
+	}
+}
+
+// TestPostSignBlobRedactLogs is not parallel as it captures the output of the standard logger.
+func TestPostSignBlobRedactLogs(t *testing.T) {
+	digest := sha256.Sum256([]byte("good"))
+	encoded := base64.StdEncoding.EncodeToString(digest[:])
+	hash := sha256.Sum256([]byte(encoded))
+	redacted := "sha256:" + hex.EncodeToString(hash[:8])
+	testcases := map[string]struct {
+		redact   bool
+		expected string
+		hidden   string
+	}{
+		"redacted":     {redact: true, expected: `digest="` + redacted + `"`, hidden: encoded},
+		"not-redacted": {redact: false, expected: `digest="` + encoded + `"`, hidden: redacted},
+	}
+	for label, tt := range testcases {
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		ss := initMockSigningService(mockSigningServiceParam{KeyUsages: combineKeyUsage})
+		ss.RedactLogs = tt.redact
+		request := &proto.BlobSigningRequest{
+			KeyMeta:       &proto.KeyMeta{Identifier: "blobid1"},
+			Digest:        encoded,
+			HashAlgorithm: proto.HashAlgo_SHA256,
+		}
+		_, err := ss.PostSignBlob(context.Background(), request)
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			t.Fatalf("in test %v: unexpected error: %v", label, err)
+		}
+		if line := buf.String(); !strings.Contains(line, tt.expected) || strings.Contains(line, tt.hidden) {
+			t.Errorf("in test %v: got log %q, want it to contain %q and not %q", label, line, tt.expected, tt.hidden)
+		}
+	}
+}
+
+// TestPostSignBlobLogSignerOpts is not parallel as it captures the output of the standard logger.
+func TestPostSignBlobLogSignerOpts(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	certSign := &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey}}}, rsaKey}
+	keyUsages := map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}}
+	digest := sha256.Sum256([]byte("good"))
+	testcases := map[string]struct {
+		logSignerOpts bool
+		saltLength    string
+		scheme        proto.SignatureScheme
+		expected      string
+	}{
+		"pss-explicit": {logSignerOpts: true, saltLength: "20", scheme: proto.SignatureScheme_PSS, expected: `signer opts: id="blobid1",hash=SHA-256,scheme=PSS,saltlen=20,enc=raw`},
+		"pss-max":      {logSignerOpts: true, saltLength: config.PSSSaltLengthMax, scheme: proto.SignatureScheme_PSS, expected: `signer opts: id="blobid1",hash=SHA-256,scheme=PSS,saltlen=222,enc=raw`},
+		"default":      {logSignerOpts: true, expected: `signer opts: id="blobid1",hash=SHA-256,scheme=PKCS1v15,saltlen=-,enc=raw`},
+		"disabled":     {scheme: proto.SignatureScheme_PSS},
+	}
+	for label, tt := range testcases {
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		keys := map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA, AllowPSS: true, PSSSaltLength: tt.saltLength}}
+		ss := &SigningService{CertSign: certSign, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys, LogSignerOpts: tt.logSignerOpts}
+		request := &proto.BlobSigningRequest{
+			KeyMeta:         &proto.KeyMeta{Identifier: "blobid1"},
+			Digest:          base64.StdEncoding.EncodeToString(digest[:]),
+			HashAlgorithm:   proto.HashAlgo_SHA256,
+			SignatureScheme: tt.scheme,
+		}
+		_, err := ss.PostSignBlob(context.Background(), request)
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			t.Fatalf("in test %v: unexpected error: %v", label, err)
+		}
+		logged := strings.Contains(buf.String(), "signer opts:")
+		if logged != tt.logSignerOpts || !strings.Contains(buf.String(), tt.expected) {
+			t.Errorf("in test %v: got log %q, want it to contain %q", label, buf.String(), tt.expected)
+		}
+	}
+}
+
+// mockUnavailableKeyCertSign is a mockGoodCertSign that fails to sign with the unavailable keys.
+type mockUnavailableKeyCertSign struct {
+	mockGoodCertSign
+	unavailable map[string]bool
+}
+
+func (m *mockUnavailableKeyCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	if m.unavailable[keyIdentifier] {
+		return nil, fmt.Errorf("HSM of key %q unavailable", keyIdentifier)
+	}
+	return m.mockGoodCertSign.Sign(digest, opts, keyIdentifier)
+}
+
+func TestPostSignBlobCoSigners(t *testing.T) {
+	t.Parallel()
+	keys := map[string]config.KeyConfig{
+		"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA},
+		"blobid2": {Identifier: "blobid2", KeyType: crypki.RSA, PartialAvailability: config.PartialAvailabilityBestEffort},
+		"blobid3": {Identifier: "blobid3", KeyType: crypki.RSA},
+	}
+	keyUsages := map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true, "blobid3": true}}
+	goodSignature := base64.StdEncoding.EncodeToString([]byte("good blob signature"))
+	testcases := map[string]struct {
+		identifier   string
+		coSigners    []string
+		mode         proto.PartialAvailability
+		unavailable  map[string]bool
+		expectedCode codes.Code
+		expected     *proto.Signature
+	}{
+		"all-available": {
+			identifier: "blobid1",
+			coSigners:  []string{"blobid3"},
+			expected: &proto.Signature{Signature: goodSignature, Signatures: []*proto.KeySignature{
+				{Identifier: "blobid1", Signature: goodSignature},
+				{Identifier: "blobid3", Signature: goodSignature},
+			}},
+		},
+		"strict-co-signer-unavailable": {
+			identifier:   "blobid1",
+			coSigners:    []string{"blobid3"},
+			unavailable:  map[string]bool{"blobid3": true},
+			expectedCode: codes.Internal,
+		},
+		"best-effort-co-signer-unavailable": {
+			identifier:  "blobid1",
+			coSigners:   []string{"blobid3"},
+			mode:        proto.PartialAvailability_BEST_EFFORT,
+			unavailable: map[string]bool{"blobid3": true},
+			expected: &proto.Signature{Signature: goodSignature, Signatures: []*proto.KeySignature{
+				{Identifier: "blobid1", Signature: goodSignature},
+				{Identifier: "blobid3", Error: "Internal server error"},
+			}},
+		},
+		"best-effort-primary-unavailable": {
+			identifier:  "blobid1",
+			coSigners:   []string{"blobid3"},
+			mode:        proto.PartialAvailability_BEST_EFFORT,
+			unavailable: map[string]bool{"blobid1": true},
+			expected: &proto.Signature{Signatures: []*proto.KeySignature{
+				{Identifier: "blobid1", Error: "Internal server error"},
+				{Identifier: "blobid3", Signature: goodSignature},
+			}},
+		},
+		"best-effort-none-available": {
+			identifier:   "blobid1",
+			coSigners:    []string{"blobid3"},
+			mode:         proto.PartialAvailability_BEST_EFFORT,
+			unavailable:  map[string]bool{"blobid1": true, "blobid3": true},
+			expectedCode: codes.Internal,
+		},
+		"best-effort-configured": {
+			identifier:  "blobid2",
+			coSigners:   []string{"blobid3"},
+			unavailable: map[string]bool{"blobid3": true},
+			expected: &proto.Signature{Signature: goodSignature, Signatures: []*proto.KeySignature{
+				{Identifier: "blobid2", Signature: goodSignature},
+				{Identifier: "blobid3", Error: "Internal server error"},
+			}},
+		},
+		"strict-overrides-configured": {
+			identifier:   "blobid2",
+			coSigners:    []string{"blobid3"},
+			mode:         proto.PartialAvailability_STRICT,
+			unavailable:  map[string]bool{"blobid3": true},
+			expectedCode: codes.Internal,
+		},
+		"best-effort-co-signer-not-allowed": {
+			identifier: "blobid1",
+			coSigners:  []string{"unknown"},
+			mode:       proto.PartialAvailability_BEST_EFFORT,
+			expected: &proto.Signature{Signature: goodSignature, Signatures: []*proto.KeySignature{
+				{Identifier: "blobid1", Signature: goodSignature},
+				{Identifier: "unknown", Error: `Bad request: cannot use key "unknown" for "/sig/blob"`},
+			}},
+		},
+		"duplicate-co-signer": {
+			identifier:   "blobid1",
+			coSigners:    []string{"blobid1"},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{
+				CertSign:       &mockUnavailableKeyCertSign{unavailable: tt.unavailable},
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      keyUsages,
+				Keys:           keys,
+			}
+			request := &proto.BlobSigningRequest{
+				KeyMeta:             &proto.KeyMeta{Identifier: tt.identifier},
+				Digest:              testSHA256Digest,
+				HashAlgorithm:       proto.HashAlgo_SHA256,
+				PartialAvailability: tt.mode,
+			}
+			for _, id := range tt.coSigners {
+				request.CoSignerKeyMetas = append(request.CoSignerKeyMetas, &proto.KeyMeta{Identifier: id})
+			}
+			resp, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(resp, tt.expected) {
+				t.Errorf("in test %v: got %v, want %v", label, resp, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PostSignBlobBatch signs the digests of the entries of the request with its key, each with the signer options
+// of its own hash algorithm. All the entries are checked before any is signed, so that an invalid entry fails
+// the request without using the HSM, and each entry is then signed as the digest of a PostSignBlob request.
+func (s *SigningService) PostSignBlobBatch(ctx context.Context, request *proto.BlobBatchSigningRequest) (*proto.BlobBatchSignatures, error) {
+	const methodName = "PostSignBlobBatch"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,entries=%d,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), len(request.GetEntries()), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.BlobEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.BlobEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if len(request.Entries) == 0 || (s.MaxBlobBatchSize > 0 && len(request.Entries) > s.MaxBlobBatchSize) {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("batch of %d entries, want between 1 and %d", len(request.Entries), s.MaxBlobBatchSize)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	identifier := request.KeyMeta.Identifier
+	requests := make([]*proto.BlobSigningRequest, len(request.Entries))
+	digests := make([][]byte, len(request.Entries))
+	for i, entry := range request.Entries {
+		if digests[i], err = s.checkBatchEntry(identifier, entry, request.HashAlgorithm); err != nil {
+			statusCode = http.StatusBadRequest
+			err = fmt.Errorf("entry %d: %v", i, err)
+			return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		}
+		requests[i] = &proto.BlobSigningRequest{
+			KeyMeta:         request.KeyMeta,
+			Digest:          entry.Digest,
+			HashAlgorithm:   batchEntryHashAlgorithm(entry, request.HashAlgorithm),
+			SignatureScheme: request.SignatureScheme,
+			Priority:        request.Priority,
+		}
+	}
+
+	response := &proto.BlobBatchSignatures{Signatures: make([]string, len(requests))}
+	for i := range requests {
+		if response.Signatures[i], statusCode, err = s.signBlobWithKey(ctx, requests[i], identifier, digests[i]); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// postSignBlobBatchStreamMethod is the full method name under which the streamed batch signing requests
+// are passed to the UnaryInterceptor.
+const postSignBlobBatchStreamMethod = "/v3.Signing/PostSignBlobBatchStream"
+
+// PostSignBlobBatchStream signs the digests of the entries of the request with its key as PostSignBlobBatch
+// does, but streams the result of each entry as soon as it is signed, so that the batch may be larger than
+// a single response allows. Each entry is checked and signed on its own, and an entry that cannot be signed
+// is streamed with its status instead of failing the others. The request is passed through the
+// UnaryInterceptor, so that the key aliases, the rate limits and the other policies of the unary requests apply.
+func (s *SigningService) PostSignBlobBatchStream(request *proto.BlobBatchSigningRequest, stream proto.Signing_PostSignBlobBatchStreamServer) error {
+	const methodName = "PostSignBlobBatchStream"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+	failed := 0
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,entries=%d,failed=%d,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), len(request.GetEntries()), failed, statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if s.MaxBlobBatchStreamSize <= 0 {
+		statusCode = http.StatusNotImplemented
+		err = errors.New("streamed batch signing is not enabled")
+		return status.Error(codes.Unimplemented, "Streamed batch signing is not enabled")
+	}
+	if err = s.checkEndpointEnabled(config.BlobEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.BlobEndpoint)
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if len(request.Entries) == 0 || len(request.Entries) > s.MaxBlobBatchStreamSize {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("batch of %d entries, want between 1 and %d", len(request.Entries), s.MaxBlobBatchStreamSize)
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		request := req.(*proto.BlobBatchSigningRequest)
+		identifier := request.KeyMeta.Identifier
+		for i, entry := range request.Entries {
+			if err := ctx.Err(); err != nil {
+				return nil, status.FromContextError(err).Err()
+			}
+			result := &proto.BlobBatchEntrySignature{Index: uint32(i)}
+			digest, err := s.checkBatchEntry(identifier, entry, request.HashAlgorithm)
+			if err != nil {
+				err = status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+			} else {
+				result.Signature, _, err = s.signBlobWithKey(ctx, &proto.BlobSigningRequest{
+					KeyMeta:         request.KeyMeta,
+					Digest:          entry.Digest,
+					HashAlgorithm:   batchEntryHashAlgorithm(entry, request.HashAlgorithm),
+					SignatureScheme: request.SignatureScheme,
+					Priority:        request.Priority,
+				}, identifier, digest)
+			}
+			if err != nil {
+				failed++
+				st := status.Convert(err)
+				result.Code, result.Message = int32(st.Code()), st.Message()
+			}
+			if err := stream.Send(result); err != nil {
+				return nil, err
+			}
+		}
+		return &proto.BlobBatchSignatures{}, nil
+	}
+	if s.UnaryInterceptor != nil {
+		_, err = s.UnaryInterceptor(stream.Context(), request, &grpc.UnaryServerInfo{Server: s, FullMethod: postSignBlobBatchStreamMethod}, handler)
+	} else {
+		_, err = handler(stream.Context(), request)
+	}
+	if err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return err
+	}
+	return nil
+}
+
+// checkBatchEntry returns the decoded digest of the entry of a batch signing request, or an error if the digest is
+// suspicious or its hash algorithm is not approved for the key or does not match its length.
+func (s *SigningService) checkBatchEntry(identifier string, entry *proto.BlobBatchEntry, defaultHashAlgo proto.HashAlgo) ([]byte, error) {
+	if entry == nil {
+		return nil, fmt.Errorf("empty entry for %q", config.BlobEndpoint)
+	}
+	digest, err := base64.StdEncoding.DecodeString(entry.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDigest(digest); err != nil {
+		return nil, err
+	}
+	hashAlgo := batchEntryHashAlgorithm(entry, defaultHashAlgo)
+	if hashAlgo == proto.HashAlgo_Unspecified_Hash {
+		return nil, errors.New("hash algorithm must be specified")
+	}
+	if err := s.checkHashAlgorithm(identifier, hashAlgo); err != nil {
+		return nil, err
+	}
+	if size := getSignerOpts(hashAlgo.String()).HashFunc().Size(); len(digest) != size {
+		return nil, fmt.Errorf("digest of %d bytes does not match hash algorithm %s: want %d bytes", len(digest), hashAlgo, size)
+	}
+	return digest, nil
+}
+
+// batchEntryHashAlgorithm returns the hash algorithm of the entry, or the default one if the entry does not specify it.
+func batchEntryHashAlgorithm(entry *proto.BlobBatchEntry, defaultHashAlgo proto.HashAlgo) proto.HashAlgo {
+	if entry.HashAlgorithm != proto.HashAlgo_Unspecified_Hash {
+		return entry.HashAlgorithm
+	}
+	return defaultHashAlgo
+}
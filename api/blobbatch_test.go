@@ -0,0 +1,223 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostSignBlobBatch(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	ss := &SigningService{
+		CertSign:         &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey}}}, rsaKey},
+		KeyIDProcessor:   &crypki.KeyID{},
+		KeyUsages:        map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}},
+		Keys:             map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA}},
+		MaxBlobBatchSize: 3,
+	}
+	sha256Digest := sha256.Sum256([]byte("good"))
+	sha384Digest := sha512.Sum384([]byte("good"))
+	sha256Entry := &proto.BlobBatchEntry{Digest: base64.StdEncoding.EncodeToString(sha256Digest[:]), HashAlgorithm: proto.HashAlgo_SHA256}
+	sha384Entry := &proto.BlobBatchEntry{Digest: base64.StdEncoding.EncodeToString(sha384Digest[:]), HashAlgorithm: proto.HashAlgo_SHA384}
+	keyMeta := &proto.KeyMeta{Identifier: "blobid1"}
+
+	request := &proto.BlobBatchSigningRequest{
+		KeyMeta: keyMeta,
+		Entries: []*proto.BlobBatchEntry{
+			sha256Entry,
+			sha384Entry,
+			// An entry without a hash algorithm has the one of the request.
+			{Digest: sha256Entry.Digest},
+		},
+		HashAlgorithm: proto.HashAlgo_SHA256,
+	}
+	resp, err := ss.PostSignBlobBatch(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unable to sign batch: %v", err)
+	}
+	if len(resp.Signatures) != len(request.Entries) {
+		t.Fatalf("got %d signatures, want %d", len(resp.Signatures), len(request.Entries))
+	}
+	expected := []struct {
+		hash   crypto.Hash
+		digest []byte
+	}{
+		{crypto.SHA256, sha256Digest[:]},
+		{crypto.SHA384, sha384Digest[:]},
+		{crypto.SHA256, sha256Digest[:]},
+	}
+	for i, want := range expected {
+		signature, err := base64.StdEncoding.DecodeString(resp.Signatures[i])
+		if err != nil {
+			t.Fatalf("unable to decode signature %d: %v", i, err)
+		}
+		if err := rsa.VerifyPKCS1v15(&rsaKey.PublicKey, want.hash, want.digest, signature); err != nil {
+			t.Errorf("signature %d does not verify with %v: %v", i, want.hash, err)
+		}
+	}
+
+	testcases := map[string]struct {
+		request      *proto.BlobBatchSigningRequest
+		expectedCode codes.Code
+	}{
+		"bad-no-entries": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-too-many-entries": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta, Entries: []*proto.BlobBatchEntry{sha256Entry, sha256Entry, sha256Entry, sha256Entry}},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-no-key-meta": {
+			request:      &proto.BlobBatchSigningRequest{Entries: []*proto.BlobBatchEntry{sha256Entry}},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-unspecified-hash": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta, Entries: []*proto.BlobBatchEntry{sha256Entry, {Digest: sha256Entry.Digest}}},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-hash-not-approved": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta, Entries: []*proto.BlobBatchEntry{{Digest: sha256Entry.Digest, HashAlgorithm: proto.HashAlgo_SHA224}}},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-digest-length": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta, Entries: []*proto.BlobBatchEntry{sha256Entry, {Digest: sha256Entry.Digest, HashAlgorithm: proto.HashAlgo_SHA384}}},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-key-usage": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid2"}, Entries: []*proto.BlobBatchEntry{sha256Entry}},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		if _, err := ss.PostSignBlobBatch(context.Background(), tt.request); status.Code(err) != tt.expectedCode {
+			t.Errorf("in test %v: got %v, want code %v", label, err, tt.expectedCode)
+		}
+	}
+}
+
+// mockBlobBatchStream is a proto.Signing_PostSignBlobBatchStreamServer recording the results it is sent.
+type mockBlobBatchStream struct {
+	grpc.ServerStream
+	results []*proto.BlobBatchEntrySignature
+}
+
+func (m *mockBlobBatchStream) Context() context.Context {
+	return context.Background()
+}
+
+func (m *mockBlobBatchStream) Send(result *proto.BlobBatchEntrySignature) error {
+	m.results = append(m.results, result)
+	return nil
+}
+
+func TestPostSignBlobBatchStream(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	ss := &SigningService{
+		CertSign:               &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey}}}, rsaKey},
+		KeyIDProcessor:         &crypki.KeyID{},
+		KeyUsages:              map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}},
+		Keys:                   map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA}},
+		MaxBlobBatchSize:       3,
+		MaxBlobBatchStreamSize: 500,
+	}
+	keyMeta := &proto.KeyMeta{Identifier: "blobid1"}
+
+	// A batch larger than MaxBlobBatchSize, with some entries that cannot be signed.
+	const size = 250
+	digests := make([][]byte, size)
+	request := &proto.BlobBatchSigningRequest{KeyMeta: keyMeta, HashAlgorithm: proto.HashAlgo_SHA256}
+	for i := range digests {
+		digest := sha256.Sum256([]byte{byte(i), byte(i >> 8)})
+		digests[i] = digest[:]
+		entry := &proto.BlobBatchEntry{Digest: base64.StdEncoding.EncodeToString(digest[:])}
+		if i%50 == 7 {
+			entry.HashAlgorithm = proto.HashAlgo_SHA384
+		}
+		request.Entries = append(request.Entries, entry)
+	}
+	stream := &mockBlobBatchStream{}
+	if err := ss.PostSignBlobBatchStream(request, stream); err != nil {
+		t.Fatalf("unable to sign streamed batch: %v", err)
+	}
+	if len(stream.results) != size {
+		t.Fatalf("got %d results, want %d", len(stream.results), size)
+	}
+	for i, result := range stream.results {
+		if result.Index != uint32(i) {
+			t.Fatalf("got result %d for entry %d, want the results in order", result.Index, i)
+		}
+		if i%50 == 7 {
+			if codes.Code(result.Code) != codes.InvalidArgument || result.Signature != "" || result.Message == "" {
+				t.Errorf("got result %+v for entry %d with a mismatched digest, want InvalidArgument", result, i)
+			}
+			continue
+		}
+		if codes.Code(result.Code) != codes.OK {
+			t.Fatalf("got code %v for entry %d, want OK: %s", codes.Code(result.Code), i, result.Message)
+		}
+		signature, err := base64.StdEncoding.DecodeString(result.Signature)
+		if err != nil {
+			t.Fatalf("unable to decode signature %d: %v", i, err)
+		}
+		if err := rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digests[i], signature); err != nil {
+			t.Errorf("signature %d does not verify: %v", i, err)
+		}
+	}
+
+	testcases := map[string]struct {
+		request      *proto.BlobBatchSigningRequest
+		disabled     bool
+		expectedCode codes.Code
+	}{
+		"bad-no-entries": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-too-many-entries": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta, Entries: make([]*proto.BlobBatchEntry, 501)},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-no-key-meta": {
+			request:      &proto.BlobBatchSigningRequest{Entries: request.Entries[:1]},
+			expectedCode: codes.InvalidArgument,
+		},
+		"disabled": {
+			request:      &proto.BlobBatchSigningRequest{KeyMeta: keyMeta, Entries: request.Entries[:1], HashAlgorithm: proto.HashAlgo_SHA256},
+			disabled:     true,
+			expectedCode: codes.Unimplemented,
+		},
+	}
+	for label, tt := range testcases {
+		ss := *ss
+		if tt.disabled {
+			ss.MaxBlobBatchStreamSize = 0
+		}
+		if err := ss.PostSignBlobBatchStream(tt.request, &mockBlobBatchStream{}); status.Code(err) != tt.expectedCode {
+			t.Errorf("in test %v: got %v, want code %v", label, err, tt.expectedCode)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/yahoo/crypki/x509cert"
+)
+
+// LoadBlobSigningCert reads the PEM file of the signing certificate of a blob signing key followed by its
+// chain, through the cache if not nil, and returns the PEM encoded certificates. It returns an error if
+// the certificate does not certify pub, unless pub is nil.
+func LoadBlobSigningCert(cache *x509cert.CertFileCache, path string, pub crypto.PublicKey) (string, error) {
+	chain, err := cache.Load(path)
+	if err != nil {
+		return "", err
+	}
+	if pub != nil {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return "", err
+		}
+		if !bytes.Equal(der, chain[0].RawSubjectPublicKeyInfo) {
+			return "", fmt.Errorf("%s does not certify the public key of the key", path)
+		}
+	}
+	return strings.Join(encodeX509Chain(chain), ""), nil
+}
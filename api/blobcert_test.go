@@ -0,0 +1,110 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadBlobSigningCert(t *testing.T) {
+	t.Parallel()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := newTestCert(t, "blob CA", &caKey.PublicKey, nil, caKey)
+	cert := newTestCert(t, "blob signer", &key.PublicKey, ca, caKey)
+	want := strings.Join(encodeX509Chain([]*x509.Certificate{cert, ca}), "")
+	dir, err := ioutil.TempDir("", "blobcert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cert.pem")
+	if err := ioutil.WriteFile(path, []byte(want), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadBlobSigningCert(nil, path, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to load the signing certificate: %v", err)
+	}
+	if got != want {
+		t.Errorf("got signing certificate %q, want %q", got, want)
+	}
+	if _, err := LoadBlobSigningCert(nil, path, &caKey.PublicKey); err == nil {
+		t.Error("got no error loading a signing certificate of another public key")
+	}
+	if _, err := LoadBlobSigningCert(nil, path, nil); err != nil {
+		t.Errorf("unable to load the signing certificate without a public key: %v", err)
+	}
+	if _, err := LoadBlobSigningCert(nil, filepath.Join(dir, "missing.pem"), &key.PublicKey); err == nil {
+		t.Error("got no error loading a missing signing certificate")
+	}
+}
+
+func TestGetBlobSigningKeyCertificate(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newTestCert(t, "blob signer", &key.PublicKey, nil, key).Raw}))
+	ss := &SigningService{
+		CertSign:         &mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &key.PublicKey, "blobid2": &key.PublicKey}}},
+		KeyIDProcessor:   &crypki.KeyID{},
+		KeyUsages:        map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+		BlobSigningCerts: map[string]string{"blobid1": cert},
+	}
+	testcases := map[string]struct {
+		keyMeta  *proto.KeyMeta
+		wantCert string
+		wantCode codes.Code
+	}{
+		"with-certificate":      {keyMeta: &proto.KeyMeta{Identifier: "blobid1", IncludeCertificate: true}, wantCert: cert},
+		"certificate-not-asked": {keyMeta: &proto.KeyMeta{Identifier: "blobid1"}},
+		"no-certificate":        {keyMeta: &proto.KeyMeta{Identifier: "blobid2", IncludeCertificate: true}, wantCode: codes.NotFound},
+		"no-certificate-asked":  {keyMeta: &proto.KeyMeta{Identifier: "blobid2"}},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			got, err := ss.GetBlobSigningKey(context.Background(), tt.keyMeta)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("got error %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if got.Key == "" {
+				t.Error("got no public key")
+			}
+			if got.Certificate != tt.wantCert {
+				t.Errorf("got certificate %q, want %q", got.Certificate, tt.wantCert)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	// Registers the SHA3 hash functions with crypto.
+	_ "golang.org/x/crypto/sha3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blobHashes maps the hash algorithms of the streamed blobs to the hash functions with which crypki hashes them.
+// A hash algorithm added here is served once it is in the hashAlgorithms of the key types that sign its digests.
+var blobHashes = map[proto.HashAlgo]crypto.Hash{
+	proto.HashAlgo_SHA224:   crypto.SHA224,
+	proto.HashAlgo_SHA256:   crypto.SHA256,
+	proto.HashAlgo_SHA384:   crypto.SHA384,
+	proto.HashAlgo_SHA512:   crypto.SHA512,
+	proto.HashAlgo_SHA3_224: crypto.SHA3_224,
+	proto.HashAlgo_SHA3_256: crypto.SHA3_256,
+	proto.HashAlgo_SHA3_384: crypto.SHA3_384,
+	proto.HashAlgo_SHA3_512: crypto.SHA3_512,
+}
+
+// errTooManyStreamBytes is returned when the bytes of the next chunk of a blob stream cannot be reserved.
+var errTooManyStreamBytes = errors.New("too many blob bytes in flight")
+
+// postSignBlobMethod is the full method name under which the requests composed from the streamed blobs
+// are passed to the UnaryInterceptor.
+const postSignBlobMethod = "/v3.Signing/PostSignBlob"
+
+// PostSignBlobStream hashes the streamed blob with the hash algorithm of the first message of the stream,
+// and signs the digest as a PostSignBlob request passed through the UnaryInterceptor, so that the key aliases,
+// the rate limits and the other policies of the unary requests apply. It returns the digest along with the
+// signature, for the client to check that crypki signed the blob it sent.
+func (s *SigningService) PostSignBlobStream(stream proto.Signing_PostSignBlobStreamServer) error {
+	const methodName = "PostSignBlobStream"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+	var first *proto.BlobStreamRequest
+	var size int64
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,hash=%q,size=%d,st=%d,et=%d,err="%v"`, methodName, first.GetKeyMeta().GetIdentifier(), first.GetHashAlgorithm().String(), size, statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.BlobEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	// The bytes of the next chunk are reserved before it is received, and released once it is hashed.
+	var reserved int64
+	defer func() { s.StreamBytes.release(reserved) }()
+	recv := func() (*proto.BlobStreamRequest, error) {
+		s.StreamBytes.release(reserved)
+		reserved = 0
+		n := s.StreamBytes.messageSize()
+		if !s.StreamBytes.acquire(n) {
+			return nil, errTooManyStreamBytes
+		}
+		reserved = n
+		return stream.Recv()
+	}
+
+	if first, err = recv(); err != nil {
+		if err == errTooManyStreamBytes {
+			statusCode = http.StatusTooManyRequests
+			return status.Errorf(codes.ResourceExhausted, "Resource exhausted: %v", err)
+		}
+		if err == io.EOF {
+			err = errors.New("blob stream is empty")
+		}
+		statusCode = http.StatusBadRequest
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if first.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.BlobEndpoint)
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	hash, ok := blobHashes[first.HashAlgorithm]
+	if !ok || !hash.Available() {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("hash algorithm %s is not supported for streamed blobs", first.HashAlgorithm)
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	h := hash.New()
+	for req := first; ; {
+		h.Write(req.Chunk)
+		size += int64(len(req.Chunk))
+		if req, err = recv(); err == io.EOF {
+			err = nil
+			break
+		}
+		if err == errTooManyStreamBytes {
+			statusCode = http.StatusTooManyRequests
+			err = fmt.Errorf("%v, %d bytes of this stream received", err, size)
+			return status.Errorf(codes.ResourceExhausted, "Resource exhausted: %v", err)
+		}
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			return status.Errorf(codes.InvalidArgument, "Bad request: unable to receive blob stream: %v", err)
+		}
+	}
+	digest := h.Sum(nil)
+	s.StreamBytes.release(reserved)
+	reserved = 0
+
+	request := &proto.BlobSigningRequest{
+		KeyMeta:         first.KeyMeta,
+		Digest:          base64.StdEncoding.EncodeToString(digest),
+		HashAlgorithm:   first.HashAlgorithm,
+		SignatureScheme: first.SignatureScheme,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		request := req.(*proto.BlobSigningRequest)
+		// The hash algorithm is checked once the interceptors have resolved the key.
+		if err := s.checkHashAlgorithm(request.GetKeyMeta().GetIdentifier(), request.HashAlgorithm); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		}
+		return s.PostSignBlob(ctx, request)
+	}
+	var resp interface{}
+	if s.UnaryInterceptor != nil {
+		resp, err = s.UnaryInterceptor(stream.Context(), request, &grpc.UnaryServerInfo{Server: s, FullMethod: postSignBlobMethod}, handler)
+	} else {
+		resp, err = handler(stream.Context(), request)
+	}
+	if err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return err
+	}
+	return stream.SendAndClose(&proto.BlobStreamSignature{
+		Signature:     resp.(*proto.Signature).Signature,
+		Digest:        request.Digest,
+		HashAlgorithm: request.HashAlgorithm,
+	})
+}
+
+// checkHashAlgorithm returns an error unless the digests of the hash algorithm can be signed by the key.
+func (s *SigningService) checkHashAlgorithm(identifier string, hashAlgo proto.HashAlgo) error {
+	key, ok := s.Keys[identifier]
+	if !ok {
+		// PostSignBlob rejects the keys that are not configured for the endpoint.
+		return nil
+	}
+	for _, h := range hashAlgorithms(key) {
+		if h == hashAlgo {
+			return nil
+		}
+	}
+	return fmt.Errorf("hash algorithm %s is not approved for %s key %q", hashAlgo, protoKeyType(key.KeyType), identifier)
+}
@@ -0,0 +1,203 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockBlobStream is a proto.Signing_PostSignBlobStreamServer receiving the requests in order.
+type mockBlobStream struct {
+	grpc.ServerStream
+	requests []*proto.BlobStreamRequest
+	resp     *proto.BlobStreamSignature
+}
+
+func (m *mockBlobStream) Context() context.Context {
+	return context.Background()
+}
+
+func (m *mockBlobStream) Recv() (*proto.BlobStreamRequest, error) {
+	if len(m.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := m.requests[0]
+	m.requests = m.requests[1:]
+	return req, nil
+}
+
+func (m *mockBlobStream) SendAndClose(resp *proto.BlobStreamSignature) error {
+	m.resp = resp
+	return nil
+}
+
+func TestPostSignBlobStream(t *testing.T) {
+	t.Parallel()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	pubs := map[string]crypto.PublicKey{"blobid1": &ecKey.PublicKey}
+	blob := bytes.Repeat([]byte("streamed blob "), 1000)
+	sha3Digest := sha3.Sum256(blob)
+	sha512Digest := sha512.Sum512(blob)
+	testcases := map[string]struct {
+		identifier     string
+		hashAlgo       proto.HashAlgo
+		expectedCode   codes.Code
+		expectedDigest []byte
+	}{
+		"sha3-256":           {identifier: "blobid1", hashAlgo: proto.HashAlgo_SHA3_256, expectedDigest: sha3Digest[:]},
+		"sha512":             {identifier: "blobid1", hashAlgo: proto.HashAlgo_SHA512, expectedDigest: sha512Digest[:]},
+		"alias":              {identifier: "release-signer", hashAlgo: proto.HashAlgo_SHA3_256, expectedDigest: sha3Digest[:]},
+		"unspecified-hash":   {identifier: "blobid1", expectedCode: codes.InvalidArgument},
+		"unsupported-by-key": {identifier: "blobid2", hashAlgo: proto.HashAlgo_SHA3_256, expectedCode: codes.InvalidArgument},
+		"unknown-key":        {identifier: "randomid", hashAlgo: proto.HashAlgo_SHA256, expectedCode: codes.InvalidArgument},
+		"alias-unsupported":  {identifier: "edge-signer", hashAlgo: proto.HashAlgo_SHA3_256, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{
+				CertSign: &mockECDSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: pubs}}, ecKey},
+				Keys: map[string]config.KeyConfig{
+					"blobid1": {Identifier: "blobid1", KeyType: crypki.ECDSA},
+					"blobid2": {Identifier: "blobid2", KeyType: crypki.Ed25519},
+				},
+				KeyIDProcessor:   &crypki.KeyID{},
+				KeyUsages:        map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+				UnaryInterceptor: KeyAliases{"release-signer": "blobid1", "edge-signer": "blobid2"}.UnaryServerInterceptor(),
+			}
+			// The blob is streamed in chunks of various sizes, the first one carrying the parameters.
+			stream := &mockBlobStream{requests: []*proto.BlobStreamRequest{
+				{KeyMeta: &proto.KeyMeta{Identifier: tt.identifier}, HashAlgorithm: tt.hashAlgo, Chunk: blob[:10]},
+				{Chunk: blob[10:5000]},
+				{},
+				{Chunk: blob[5000:]},
+			}}
+			err := ss.PostSignBlobStream(stream)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			digest, err := base64.StdEncoding.DecodeString(stream.resp.Digest)
+			if err != nil || !bytes.Equal(digest, tt.expectedDigest) {
+				t.Fatalf("in test %v: got digest %q, want %x", label, stream.resp.Digest, tt.expectedDigest)
+			}
+			if stream.resp.HashAlgorithm != tt.hashAlgo {
+				t.Errorf("in test %v: got hash algorithm %v, want %v", label, stream.resp.HashAlgorithm, tt.hashAlgo)
+			}
+			sig, err := base64.StdEncoding.DecodeString(stream.resp.Signature)
+			if err != nil {
+				t.Fatalf("in test %v: unable to decode signature: %v", label, err)
+			}
+			if !ecdsa.VerifyASN1(&ecKey.PublicKey, tt.expectedDigest, sig) {
+				t.Errorf("in test %v: invalid signature of the digest", label)
+			}
+		})
+	}
+}
+
+func TestPostSignBlobStreamEmpty(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, KeyIDProcessor: &crypki.KeyID{}}
+	if err := ss.PostSignBlobStream(&mockBlobStream{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v for an empty stream, want InvalidArgument", err)
+	}
+}
+
+// mockChanBlobStream is a proto.Signing_PostSignBlobStreamServer receiving the requests sent on a channel,
+// blocking in Recv until the next one is sent. The stream ends once the channel is closed.
+type mockChanBlobStream struct {
+	mockBlobStream
+	requests chan *proto.BlobStreamRequest
+}
+
+func (m *mockChanBlobStream) Recv() (*proto.BlobStreamRequest, error) {
+	req, ok := <-m.requests
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func TestPostSignBlobStreamInFlightBytes(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{
+		CertSign:       &mockGoodCertSign{},
+		Keys:           map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.ECDSA}},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}},
+		StreamBytes:    &StreamBytesLimiter{Max: 2000, MessageSize: 1000},
+	}
+	first := func(chunk []byte) *proto.BlobStreamRequest {
+		return &proto.BlobStreamRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, HashAlgorithm: proto.HashAlgo_SHA256, Chunk: chunk}
+	}
+	inFlight := func() int64 {
+		ss.StreamBytes.mu.Lock()
+		defer ss.StreamBytes.mu.Unlock()
+		return ss.StreamBytes.inFlight
+	}
+
+	// Two streams waiting for their next chunk hold the whole cap.
+	var streams [2]*mockChanBlobStream
+	var errs [2]chan error
+	for i := range streams {
+		streams[i] = &mockChanBlobStream{requests: make(chan *proto.BlobStreamRequest)}
+		errs[i] = make(chan error, 1)
+		go func(i int) { errs[i] <- ss.PostSignBlobStream(streams[i]) }(i)
+	}
+	for deadline := time.Now().Add(5 * time.Second); inFlight() != 2000; {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d bytes in flight, want the reservations of both streams", inFlight())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	err := ss.PostSignBlobStream(&mockBlobStream{requests: []*proto.BlobStreamRequest{first(make([]byte, 100))}})
+	if got := status.Code(err); got != codes.ResourceExhausted {
+		t.Errorf("got code %v for a stream beyond the cap, want %v, err: %v", got, codes.ResourceExhausted, err)
+	}
+
+	// The reservation of a stream is released once it completes.
+	streams[0].requests <- first(make([]byte, 1000))
+	close(streams[0].requests)
+	if err := <-errs[0]; err != nil {
+		t.Errorf("unable to sign the first stream: %v", err)
+	}
+	// The bytes of a chunk are released once hashed, so a blob larger than the cap can be streamed.
+	blob := []*proto.BlobStreamRequest{first(make([]byte, 1000)), {Chunk: make([]byte, 1000)}, {Chunk: make([]byte, 1000)}}
+	if err := ss.PostSignBlobStream(&mockBlobStream{requests: blob}); err != nil {
+		t.Errorf("unable to sign a stream larger than the cap: %v", err)
+	}
+	streams[1].requests <- first(make([]byte, 1000))
+	close(streams[1].requests)
+	if err := <-errs[1]; err != nil {
+		t.Errorf("unable to sign the second stream: %v", err)
+	}
+	if got := inFlight(); got != 0 {
+		t.Errorf("got %d bytes in flight once the streams completed, want 0", got)
+	}
+}
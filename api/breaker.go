@@ -0,0 +1,192 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+)
+
+// BreakerState is the state of the circuit breaker of a key.
+type BreakerState int
+
+const (
+	// BreakerClosed is the state in which signing requests with the key are served.
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen is the state in which a single probe signing request tests the recovery of the key.
+	BreakerHalfOpen
+	// BreakerOpen is the state in which signing requests with the key are rejected.
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// errBreakerOpen is returned for signing requests with a key whose circuit breaker is open.
+var errBreakerOpen = errors.New("circuit breaker is open")
+
+// breaker is the circuit breaker of a single key.
+type breaker struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// CircuitBreakers tracks the consecutive signing failures of each key. The breaker of a key opens
+// after Threshold consecutive failures and fast-fails the signing requests with the key. Once
+// OpenTimeout has elapsed, a single probe request is let through: the breaker closes if the probe
+// succeeds and opens again otherwise.
+type CircuitBreakers struct {
+	// Threshold is the number of consecutive failures that opens the breaker of a key.
+	Threshold int
+	// OpenTimeout is the time the breaker of a key stays open before allowing a probe request.
+	OpenTimeout time.Duration
+	// OnStateChange, if not nil, is called with the new state of the breaker of a key.
+	// It is called with the internal lock held and must not call the CircuitBreakers.
+	OnStateChange func(identifier string, state BreakerState)
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+	now      func() time.Time
+}
+
+// NewCircuitBreakers returns CircuitBreakers opening after threshold consecutive failures of a key.
+func NewCircuitBreakers(threshold int, openTimeout time.Duration) *CircuitBreakers {
+	return &CircuitBreakers{
+		Threshold:   threshold,
+		OpenTimeout: openTimeout,
+		breakers:    make(map[string]*breaker),
+		now:         time.Now,
+	}
+}
+
+// Allow returns an error if a signing request with the key must be rejected.
+// A nil error must be followed by a call to Record with the result of the request.
+func (c *CircuitBreakers) Allow(identifier string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.get(identifier)
+	if b.state == BreakerClosed {
+		return nil
+	}
+	// While half-open, openedAt is the start of the probe request; a probe that never
+	// recorded its result is replaced by a new one after OpenTimeout.
+	if c.now().Sub(b.openedAt) < c.OpenTimeout {
+		return errBreakerOpen
+	}
+	b.openedAt = c.now()
+	if b.state != BreakerHalfOpen {
+		c.setState(identifier, b, BreakerHalfOpen)
+	}
+	return nil
+}
+
+// Record records the result of a signing request with the key.
+func (c *CircuitBreakers) Record(identifier string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.get(identifier)
+	if err == nil {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			c.setState(identifier, b, BreakerClosed)
+		}
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= c.Threshold) {
+		b.openedAt = c.now()
+		c.setState(identifier, b, BreakerOpen)
+	}
+}
+
+// State returns the state and the number of consecutive failures of the breaker of the key.
+func (c *CircuitBreakers) State(identifier string) (BreakerState, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, ok := c.breakers[identifier]; ok {
+		return b.state, b.failures
+	}
+	return BreakerClosed, 0
+}
+
+// Identifiers returns the sorted identifiers of the keys that have a breaker.
+func (c *CircuitBreakers) Identifiers() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]string, 0, len(c.breakers))
+	for id := range c.breakers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// get returns the breaker of the key, creating it if needed. c.mu must be held.
+func (c *CircuitBreakers) get(identifier string) *breaker {
+	b, ok := c.breakers[identifier]
+	if !ok {
+		b = &breaker{}
+		c.breakers[identifier] = b
+	}
+	return b
+}
+
+// setState sets the state of the breaker of the key. c.mu must be held.
+func (c *CircuitBreakers) setState(identifier string, b *breaker, state BreakerState) {
+	b.state = state
+	if c.OnStateChange != nil {
+		c.OnStateChange(identifier, state)
+	}
+}
+
+// checkBreaker returns an error if the circuit breaker of the key rejects signing requests.
+func (s *SigningService) checkBreaker(identifier string) error {
+	if s.Breakers == nil {
+		return nil
+	}
+	return s.Breakers.Allow(identifier)
+}
+
+// keyHealth returns the health of the key: unavailable while it is being (re)loaded or its breaker is open,
+// degraded while its breaker is half-open, after failed signing requests or after a failed self-test, and
+// healthy otherwise.
+func (s *SigningService) keyHealth(identifier string) proto.KeyHealth {
+	if s.Transitions.IsTransitioning(identifier) {
+		return proto.KeyHealth_UNAVAILABLE
+	}
+	if s.Breakers != nil {
+		switch state, failures := s.Breakers.State(identifier); {
+		case state == BreakerOpen:
+			return proto.KeyHealth_UNAVAILABLE
+		case state == BreakerHalfOpen || failures != 0:
+			return proto.KeyHealth_DEGRADED
+		}
+	}
+	if s.SelfTest.Failed(identifier) != nil {
+		return proto.KeyHealth_DEGRADED
+	}
+	return proto.KeyHealth_HEALTHY
+}
+
+// recordSignResult records the result of a signing request with the key in its usage counters and circuit breaker.
+func (s *SigningService) recordSignResult(identifier string, err error) {
+	s.Usage.Record(identifier, err)
+	if s.Breakers != nil {
+		s.Breakers.Record(identifier, err)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockFlakyCertSign is a mockGoodCertSign whose blob signing fails while failing is set.
+type mockFlakyCertSign struct {
+	mockGoodCertSign
+	mu      sync.Mutex
+	failing bool
+	calls   int
+}
+
+func (m *mockFlakyCertSign) setFailing(failing bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failing = failing
+}
+
+func (m *mockFlakyCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.failing {
+		return nil, errors.New("CKR_DEVICE_ERROR")
+	}
+	return m.mockGoodCertSign.Sign(digest, opts, keyIdentifier)
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1000, 0)
+	var transitions []BreakerState
+	breakers := NewCircuitBreakers(2, time.Minute)
+	breakers.now = func() time.Time { return now }
+	breakers.OnStateChange = func(identifier string, state BreakerState) {
+		transitions = append(transitions, state)
+	}
+	backend := &mockFlakyCertSign{failing: true}
+	ss := &SigningService{
+		CertSign:        backend,
+		KeyIDProcessor:  &crypki.KeyID{},
+		KeyUsages:       combineKeyUsage,
+		Breakers:        breakers,
+		AdminIdentities: map[string]bool{"admin": true},
+	}
+	request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA512Digest, HashAlgorithm: proto.HashAlgo_SHA512}
+
+	steps := []struct {
+		name          string
+		advance       time.Duration
+		failing       bool
+		expectedCode  codes.Code
+		expectedState BreakerState
+		expectedCalls int
+	}{
+		{"first-failure", 0, true, codes.Internal, BreakerClosed, 1},
+		{"second-failure-opens", 0, true, codes.Internal, BreakerOpen, 2},
+		{"open-fast-fails", 30 * time.Second, false, codes.Unavailable, BreakerOpen, 2},
+		{"failed-probe-reopens", 30 * time.Second, true, codes.Internal, BreakerOpen, 3},
+		{"reopened-fast-fails", 59 * time.Second, false, codes.Unavailable, BreakerOpen, 3},
+		{"successful-probe-closes", time.Second, false, codes.OK, BreakerClosed, 4},
+		{"closed-serves", 0, false, codes.OK, BreakerClosed, 5},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		backend.setFailing(step.failing)
+		_, err := ss.PostSignBlob(context.Background(), request)
+		if got := status.Code(err); got != step.expectedCode {
+			t.Fatalf("%s: got code %v, want %v, err: %v", step.name, got, step.expectedCode, err)
+		}
+		if state, _ := breakers.State("blobid1"); state != step.expectedState {
+			t.Fatalf("%s: got state %v, want %v", step.name, state, step.expectedState)
+		}
+		if backend.calls != step.expectedCalls {
+			t.Fatalf("%s: got %d backend calls, want %d", step.name, backend.calls, step.expectedCalls)
+		}
+	}
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(transitions) != len(want) {
+		t.Fatalf("got transitions %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("got transitions %v, want %v", transitions, want)
+		}
+	}
+
+	// Breaker states are exposed in the server info.
+	backend.setFailing(true)
+	if _, err := ss.PostSignBlob(context.Background(), request); status.Code(err) != codes.Internal {
+		t.Fatalf("expected internal error, got: %v", err)
+	}
+	info, err := ss.GetServerInfo(contextWithIdentity("admin"), &empty.Empty{})
+	if err != nil {
+		t.Fatalf("unexpected error getting server info: %v", err)
+	}
+	if len(info.CircuitBreakers) != 1 {
+		t.Fatalf("got circuit breakers %v, want one", info.CircuitBreakers)
+	}
+	cb := info.CircuitBreakers[0]
+	if cb.Identifier != "blobid1" || cb.State != "closed" || cb.ConsecutiveFailures != 1 {
+		t.Errorf("unexpected circuit breaker status: %+v", cb)
+	}
+}
+
+func TestCircuitBreakerHalfOpenSingleProbe(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1000, 0)
+	breakers := NewCircuitBreakers(1, time.Minute)
+	breakers.now = func() time.Time { return now }
+	if err := breakers.Allow("key"); err != nil {
+		t.Fatalf("closed breaker rejected request: %v", err)
+	}
+	breakers.Record("key", errors.New("CKR_DEVICE_ERROR"))
+
+	now = now.Add(time.Minute)
+	if err := breakers.Allow("key"); err != nil {
+		t.Fatalf("probe request rejected: %v", err)
+	}
+	if err := breakers.Allow("key"); err == nil {
+		t.Fatal("second request allowed while the probe is in flight")
+	}
+	// A probe that never records its result is replaced after the open timeout.
+	now = now.Add(time.Minute)
+	if err := breakers.Allow("key"); err != nil {
+		t.Fatalf("replacement probe request rejected: %v", err)
+	}
+	breakers.Record("key", nil)
+	if state, failures := breakers.State("key"); state != BreakerClosed || failures != 0 {
+		t.Errorf("got state %v with %d failures, want closed with 0 failures", state, failures)
+	}
+}
+
+func TestKeyHealthListing(t *testing.T) {
+	t.Parallel()
+	backend := &mockFlakyCertSign{failing: true}
+	ss := &SigningService{
+		CertSign:       backend,
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+		Breakers:       NewCircuitBreakers(2, time.Minute),
+		Transitions:    NewKeyTransitions(time.Second),
+	}
+	health := func(filter *proto.KeyFilter) map[string]proto.KeyHealth {
+		resp, err := ss.GetBlobAvailableSigningKeys(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("unable to list keys: %v", err)
+		}
+		got := make(map[string]proto.KeyHealth)
+		for _, key := range resp.Keys {
+			got[key.Identifier] = key.Health
+		}
+		return got
+	}
+	signBlob := func() {
+		request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA512Digest, HashAlgorithm: proto.HashAlgo_SHA512}
+		_, _ = ss.PostSignBlob(context.Background(), request)
+	}
+	withHealth := &proto.KeyFilter{IncludeHealth: true}
+
+	steps := []struct {
+		name     string
+		before   func()
+		expected map[string]proto.KeyHealth
+	}{
+		{"healthy", func() {}, map[string]proto.KeyHealth{"blobid1": proto.KeyHealth_HEALTHY, "blobid2": proto.KeyHealth_HEALTHY}},
+		{"failed-sign-degrades", signBlob, map[string]proto.KeyHealth{"blobid1": proto.KeyHealth_DEGRADED, "blobid2": proto.KeyHealth_HEALTHY}},
+		{"open-breaker-unavailable", signBlob, map[string]proto.KeyHealth{"blobid1": proto.KeyHealth_UNAVAILABLE, "blobid2": proto.KeyHealth_HEALTHY}},
+		{"reloading-unavailable", func() { ss.Transitions.Begin("blobid2") }, map[string]proto.KeyHealth{"blobid1": proto.KeyHealth_UNAVAILABLE, "blobid2": proto.KeyHealth_UNAVAILABLE}},
+	}
+	for _, step := range steps {
+		step.before()
+		if got := health(withHealth); !reflect.DeepEqual(got, step.expected) {
+			t.Errorf("step %s: got health %v, want %v", step.name, got, step.expected)
+		}
+	}
+	// The health is only listed on request.
+	for id, h := range health(&proto.KeyFilter{}) {
+		if h != proto.KeyHealth_Unspecified_KeyHealth {
+			t.Errorf("got health %v of key %q without include_health", h, id)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yahoo/crypki/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// x509CACert returns the parsed CA certificate of the key.
+func (s *SigningService) x509CACert(identifier string) (*x509.Certificate, error) {
+	caPEM, err := s.PublicKeyCache.get(cachedX509CA, identifier, s.GetX509CACert)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(caPEM)
+	if block == nil {
+		return nil, fmt.Errorf("unable to decode CA certificate of key %q", identifier)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// checkCAExpiry applies the X509CAExpiry of the key to the certificate, once its validity is final: a notAfter
+// after the one of the CA certificate of the key is set to the latter in clamp mode and rejected in strict mode.
+// A certificate starting after the CA certificate expires is rejected in both modes. It returns a status error.
+func (s *SigningService) checkCAExpiry(identifier string, cert *x509.Certificate) error {
+	mode := s.Keys[identifier].X509CAExpiry
+	if mode == "" {
+		return nil
+	}
+	ca, err := s.x509CACert(identifier)
+	if err != nil {
+		return s.internalError(err)
+	}
+	if !cert.NotAfter.After(ca.NotAfter) {
+		return nil
+	}
+	if mode == config.X509CAExpiryStrict || !cert.NotBefore.Before(ca.NotAfter) {
+		err = fmt.Errorf("notAfter %s is after the notAfter %s of the CA certificate of key %q", cert.NotAfter.UTC().Format(time.RFC3339), ca.NotAfter.UTC().Format(time.RFC3339), identifier)
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	log.Printf("clamping notAfter %s to the notAfter %s of the CA certificate of key %q", cert.NotAfter.UTC().Format(time.RFC3339), ca.NotAfter.UTC().Format(time.RFC3339), identifier)
+	cert.NotAfter = ca.NotAfter
+	return nil
+}
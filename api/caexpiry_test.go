@@ -0,0 +1,64 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckCAExpiry(t *testing.T) {
+	t.Parallel()
+	// The CA certificate of newMockCACertSign expires in an hour.
+	testcases := map[string]struct {
+		mode         string
+		validity     uint64
+		expectedCode codes.Code
+		expectClamp  bool
+	}{
+		"unset-beyond-ca":  {validity: 7200},
+		"clamp-beyond-ca":  {mode: config.X509CAExpiryClamp, validity: 7200, expectClamp: true},
+		"clamp-within-ca":  {mode: config.X509CAExpiryClamp, validity: 600},
+		"strict-beyond-ca": {mode: config.X509CAExpiryStrict, validity: 7200, expectedCode: codes.InvalidArgument},
+		"strict-within-ca": {mode: config.X509CAExpiryStrict, validity: 600},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": {Identifier: "x509id1", X509CAExpiry: tt.mode}},
+			}
+			_, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      testGoodcsrRsa,
+				Validity: tt.validity,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				if len(signer.signed) != 0 {
+					t.Errorf("in test %v: rejected certificate was signed", label)
+				}
+				return
+			}
+			cert := signer.signed[len(signer.signed)-1]
+			if clamped := cert.NotAfter.Equal(signer.ca.NotAfter); clamped != tt.expectClamp {
+				t.Errorf("in test %v: got notAfter %v with CA notAfter %v, want clamped: %v", label, cert.NotAfter, signer.ca.NotAfter, tt.expectClamp)
+			}
+		})
+	}
+}
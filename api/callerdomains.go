@@ -0,0 +1,52 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/yahoo/crypki/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkCallerDomains returns PermissionDenied if a DNS SAN or the subject common name of the certificate is
+// outside the domains the caller is permitted, once the names of the certificate are final. The callers
+// without an entry are permitted the domains of the default entry, if any, and nothing otherwise.
+func (s *SigningService) checkCallerDomains(ctx context.Context, cert *x509.Certificate) error {
+	if len(s.CallerPermittedDomains) == 0 {
+		return nil
+	}
+	caller := callerIdentity(ctx)
+	domains, ok := s.CallerPermittedDomains[caller]
+	if !ok {
+		if domains, ok = s.CallerPermittedDomains[config.DefaultCaller]; !ok {
+			return status.Errorf(codes.PermissionDenied, "Permission denied: caller %q is not permitted any domain", caller)
+		}
+	}
+	for _, name := range cert.DNSNames {
+		if !withinDomains(name, domains) {
+			return status.Errorf(codes.PermissionDenied, "Permission denied: caller %q is not permitted to obtain DNS name %q", caller, name)
+		}
+	}
+	if cn := cert.Subject.CommonName; cn != "" && !withinDomains(cn, domains) {
+		return status.Errorf(codes.PermissionDenied, "Permission denied: caller %q is not permitted to obtain common name %q", caller, cn)
+	}
+	return nil
+}
+
+// withinDomains returns whether the DNS name, which may be a wildcard, is one of the domains or a subdomain
+// of one. The names are compared label by label, so that "badexample.com" is not within "example.com".
+func withinDomains(name string, domains []string) bool {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(domain), ".")
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
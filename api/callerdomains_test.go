@@ -0,0 +1,80 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostX509CertificateCallerDomains(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	newCSRWithCN := func(cn string, names ...string) string {
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}, DNSNames: names}, key)
+		if err != nil {
+			t.Fatalf("unable to create CSR: %v", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	}
+	newCSR := func(names ...string) string {
+		return newCSRWithCN(names[0], names...)
+	}
+	permitted := map[string][]string{"tenant1": {"example.com"}}
+	withDefault := map[string][]string{"tenant1": {"example.com"}, config.DefaultCaller: {"example.org"}}
+	testcases := map[string]struct {
+		ctx          context.Context
+		csr          string
+		permitted    map[string][]string
+		expectedCode codes.Code
+	}{
+		"domain":                    {contextWithIdentity("tenant1"), newCSR("example.com"), permitted, codes.OK},
+		"subdomain":                 {contextWithIdentity("tenant1"), newCSR("foo.example.com", "*.bar.Example.com"), permitted, codes.OK},
+		"out-of-scope":              {contextWithIdentity("tenant1"), newCSR("foo.org"), permitted, codes.PermissionDenied},
+		"one-out-of-scope":          {contextWithIdentity("tenant1"), newCSR("foo.example.com", "foo.org"), permitted, codes.PermissionDenied},
+		"suffix-not-subdomain":      {contextWithIdentity("tenant1"), newCSR("badexample.com"), permitted, codes.PermissionDenied},
+		"common-name-out-of-scope":  {contextWithIdentity("tenant1"), newCSRWithCN("foo.org", "foo.example.com"), permitted, codes.PermissionDenied},
+		"common-name-only":          {contextWithIdentity("tenant1"), newCSRWithCN("foo.example.com"), permitted, codes.OK},
+		"unlisted-caller":           {contextWithIdentity("tenant2"), newCSR("foo.org"), permitted, codes.PermissionDenied},
+		"unlisted-caller-default":   {contextWithIdentity("tenant2"), newCSR("foo.example.org"), withDefault, codes.OK},
+		"default-out-of-scope":      {contextWithIdentity("tenant2"), newCSR("foo.example.com"), withDefault, codes.PermissionDenied},
+		"listed-caller-not-default": {contextWithIdentity("tenant1"), newCSR("foo.example.org"), withDefault, codes.PermissionDenied},
+		"unrestricted":              {contextWithIdentity("tenant2"), newCSR("foo.org"), nil, codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, CallerPermittedDomains: tt.permitted}
+			_, err := ss.PostX509Certificate(tt.ctx, &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      tt.csr,
+				Validity: 3600,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil && len(signer.signed) != 0 {
+				t.Errorf("in test %v: denied certificate was signed", label)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkCallerSignatureScheme returns PermissionDenied if the caller may not use the signature scheme of
+// a blob signing request with the key. An unspecified scheme is checked as the default scheme of the key type.
+func (s *SigningService) checkCallerSignatureScheme(ctx context.Context, identifier string, scheme proto.SignatureScheme) error {
+	if len(s.CallerSignatureSchemes) == 0 {
+		return nil
+	}
+	caller := callerIdentity(ctx)
+	allowed, ok := s.CallerSignatureSchemes[caller]
+	if !ok {
+		return nil
+	}
+	if scheme == proto.SignatureScheme_Unspecified_SignatureScheme {
+		// The first scheme of the key type is its default one.
+		if schemes := signatureSchemes(s.Keys[identifier]); len(schemes) != 0 {
+			scheme = schemes[0]
+		}
+	}
+	if !allowed[scheme] {
+		return status.Errorf(codes.PermissionDenied, "Permission denied: caller %q is not permitted signature scheme %s", caller, scheme)
+	}
+	return nil
+}
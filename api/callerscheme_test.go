@@ -0,0 +1,53 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostSignBlobCallerSignatureSchemes(t *testing.T) {
+	t.Parallel()
+	keys := map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA, AllowPSS: true}}
+	keyUsages := map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}}
+	callerSchemes := map[string]map[proto.SignatureScheme]bool{"tenant1": {proto.SignatureScheme_PSS: true}}
+	testcases := map[string]struct {
+		ctx          context.Context
+		scheme       proto.SignatureScheme
+		expectedCode codes.Code
+	}{
+		"permitted-scheme":    {contextWithIdentity("tenant1"), proto.SignatureScheme_PSS, codes.OK},
+		"denied-scheme":       {contextWithIdentity("tenant1"), proto.SignatureScheme_PKCS1v15, codes.PermissionDenied},
+		"denied-default":      {contextWithIdentity("tenant1"), proto.SignatureScheme_Unspecified_SignatureScheme, codes.PermissionDenied},
+		"unrestricted-caller": {contextWithIdentity("tenant2"), proto.SignatureScheme_PKCS1v15, codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys, CallerSignatureSchemes: callerSchemes}
+			_, err := ss.PostSignBlob(tt.ctx, &proto.BlobSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: "blobid1"},
+				Digest:          testSHA256Digest,
+				HashAlgorithm:   proto.HashAlgo_SHA256,
+				SignatureScheme: tt.scheme,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil && signer.opts != nil {
+				t.Errorf("in test %v: denied blob was signed", label)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/mldsa"
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Encodings of the outputs of the signing endpoints.
+const (
+	encodingPEM     = "PEM"
+	encodingOpenSSH = "OpenSSH"
+	encodingBase64  = "base64"
+)
+
+// GetKeyCapabilities returns the capabilities of the specified key.
+func (s *SigningService) GetKeyCapabilities(ctx context.Context, keyMeta *proto.KeyMeta) (*proto.KeyCapabilities, error) {
+	const methodName = "GetKeyCapabilities"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,st=%d,et=%d,err="%v"`, methodName, keyMeta.GetIdentifier(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if keyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("keyMeta is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	key, ok := s.Keys[keyMeta.Identifier]
+	if !ok {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("unknown key %q", keyMeta.Identifier)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	caps := &proto.KeyCapabilities{
+		KeyMeta:     &proto.KeyMeta{Identifier: keyMeta.Identifier},
+		SshUserCa:   s.KeyUsages[config.SSHUserCertEndpoint][keyMeta.Identifier],
+		SshHostCa:   s.KeyUsages[config.SSHHostCertEndpoint][keyMeta.Identifier],
+		X509Ca:      s.KeyUsages[config.X509CertEndpoint][keyMeta.Identifier],
+		BlobSigning: s.KeyUsages[config.BlobEndpoint][keyMeta.Identifier],
+	}
+	caps.KeyType = protoKeyType(key.KeyType)
+	// There is no Go type of the SLH-DSA public keys, whose parameter sets are not known.
+	if pkg, ok := s.CertSign.(crypki.PublicKeyGetter); ok && key.KeyType != crypki.SLHDSA {
+		var pub crypto.PublicKey
+		if pub, err = pkg.PublicKey(keyMeta.Identifier); err != nil {
+			statusCode = http.StatusInternalServerError
+			return nil, s.internalError(err)
+		}
+		switch pub := pub.(type) {
+		case *rsa.PublicKey:
+			caps.KeySize = int32(pub.N.BitLen())
+		case *ecdsa.PublicKey:
+			caps.KeySize = int32(pub.Curve.Params().BitSize)
+		case ed25519.PublicKey:
+			caps.KeySize = 8 * ed25519.PublicKeySize
+		case *mldsa.PublicKey:
+			caps.ParameterSet = pub.Parameters().String()
+		}
+	}
+	if caps.X509Ca {
+		caps.OutputEncodings = append(caps.OutputEncodings, encodingPEM)
+	}
+	if caps.SshUserCa || caps.SshHostCa {
+		caps.OutputEncodings = append(caps.OutputEncodings, encodingOpenSSH)
+	}
+	if caps.BlobSigning {
+		caps.OutputEncodings = append(caps.OutputEncodings, encodingBase64)
+		caps.HashAlgorithms = hashAlgorithms(key)
+		caps.SignatureSchemes = signatureSchemes(key)
+	}
+	return caps, nil
+}
+
+// protoKeyType returns the proto.KeyType of the key type.
+func protoKeyType(keyType crypki.PublicKeyAlgorithm) proto.KeyType {
+	switch keyType {
+	case crypki.RSA:
+		return proto.KeyType_RSA
+	case crypki.ECDSA:
+		return proto.KeyType_ECDSA
+	case crypki.Ed25519:
+		return proto.KeyType_Ed25519
+	case crypki.MLDSA:
+		return proto.KeyType_MLDSA
+	case crypki.SLHDSA:
+		return proto.KeyType_SLHDSA
+	}
+	return proto.KeyType_Unspecified_KeyType
+}
+
+// hashAlgorithms returns the hash algorithms of the digests the key can sign.
+func hashAlgorithms(key config.KeyConfig) []proto.HashAlgo {
+	switch key.KeyType {
+	case crypki.RSA:
+		// The PKCS#11 signer does not support SHA224 and SHA3-224 digests for RSA keys.
+		return []proto.HashAlgo{proto.HashAlgo_SHA256, proto.HashAlgo_SHA384, proto.HashAlgo_SHA512,
+			proto.HashAlgo_SHA3_256, proto.HashAlgo_SHA3_384, proto.HashAlgo_SHA3_512}
+	case crypki.ECDSA:
+		return []proto.HashAlgo{proto.HashAlgo_SHA224, proto.HashAlgo_SHA256, proto.HashAlgo_SHA384, proto.HashAlgo_SHA512,
+			proto.HashAlgo_SHA3_224, proto.HashAlgo_SHA3_256, proto.HashAlgo_SHA3_384, proto.HashAlgo_SHA3_512}
+	case crypki.Ed25519:
+		return []proto.HashAlgo{proto.HashAlgo_SHA512}
+	case crypki.MLDSA, crypki.SLHDSA:
+		// The 224-bit hashes are too short for the security categories of the post-quantum keys.
+		return []proto.HashAlgo{proto.HashAlgo_SHA256, proto.HashAlgo_SHA384, proto.HashAlgo_SHA512,
+			proto.HashAlgo_SHA3_256, proto.HashAlgo_SHA3_384, proto.HashAlgo_SHA3_512}
+	}
+	return nil
+}
+
+// signatureSchemes returns the signature schemes the key can sign blobs with.
+func signatureSchemes(key config.KeyConfig) []proto.SignatureScheme {
+	switch key.KeyType {
+	case crypki.RSA:
+		if key.AllowPSS {
+			return []proto.SignatureScheme{proto.SignatureScheme_PKCS1v15, proto.SignatureScheme_PSS}
+		}
+		return []proto.SignatureScheme{proto.SignatureScheme_PKCS1v15}
+	case crypki.ECDSA:
+		return []proto.SignatureScheme{proto.SignatureScheme_ECDSA_ASN1, proto.SignatureScheme_ECDSA_P1363}
+	case crypki.Ed25519:
+		return []proto.SignatureScheme{proto.SignatureScheme_Ed25519ph}
+	case crypki.MLDSA:
+		return []proto.SignatureScheme{proto.SignatureScheme_HashMLDSA}
+	case crypki.SLHDSA:
+		return []proto.SignatureScheme{proto.SignatureScheme_HashSLHDSA}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/mldsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockPublicKeyCertSign is a mockGoodCertSign which also returns the public keys of the signing keys.
+type mockPublicKeyCertSign struct {
+	mockGoodCertSign
+	keys map[string]crypto.PublicKey
+}
+
+func (m *mockPublicKeyCertSign) PublicKey(keyIdentifier string) (crypto.PublicKey, error) {
+	pub, ok := m.keys[keyIdentifier]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+	return pub, nil
+}
+
+func TestGetKeyCapabilities(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	mldsaKey, err := mldsa.GenerateKey(mldsa.MLDSA65())
+	if err != nil {
+		t.Fatalf("unable to generate ML-DSA key: %v", err)
+	}
+	ss := &SigningService{
+		CertSign: &mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{
+			"rsa-pss":    &rsaKey.PublicKey,
+			"rsa-no-pss": &rsaKey.PublicKey,
+			"ecdsa":      &ecKey.PublicKey,
+			"mldsa":      mldsaKey.PublicKey(),
+		}},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages: map[string]map[string]bool{
+			config.X509CertEndpoint:    {"rsa-no-pss": true},
+			config.SSHUserCertEndpoint: {"ecdsa": true},
+			config.SSHHostCertEndpoint: {"ecdsa": true, "rsa-no-pss": false},
+			config.BlobEndpoint:        {"rsa-pss": true, "rsa-no-pss": true, "ecdsa": true, "mldsa": true, "slhdsa": true},
+		},
+		Keys: map[string]config.KeyConfig{
+			"rsa-pss":    {Identifier: "rsa-pss", KeyType: crypki.RSA, AllowPSS: true},
+			"rsa-no-pss": {Identifier: "rsa-no-pss", KeyType: crypki.RSA},
+			"ecdsa":      {Identifier: "ecdsa", KeyType: crypki.ECDSA},
+			"mldsa":      {Identifier: "mldsa", KeyType: crypki.MLDSA},
+			"slhdsa":     {Identifier: "slhdsa", KeyType: crypki.SLHDSA},
+		},
+	}
+	rsaHashes := []proto.HashAlgo{proto.HashAlgo_SHA256, proto.HashAlgo_SHA384, proto.HashAlgo_SHA512,
+		proto.HashAlgo_SHA3_256, proto.HashAlgo_SHA3_384, proto.HashAlgo_SHA3_512}
+	ecdsaHashes := []proto.HashAlgo{proto.HashAlgo_SHA224, proto.HashAlgo_SHA256, proto.HashAlgo_SHA384, proto.HashAlgo_SHA512,
+		proto.HashAlgo_SHA3_224, proto.HashAlgo_SHA3_256, proto.HashAlgo_SHA3_384, proto.HashAlgo_SHA3_512}
+	testcases := map[string]struct {
+		keyMeta      *proto.KeyMeta
+		expectedCode codes.Code
+		expected     *proto.KeyCapabilities
+	}{
+		"rsa-pss-enabled": {
+			keyMeta:      &proto.KeyMeta{Identifier: "rsa-pss"},
+			expectedCode: codes.OK,
+			expected: &proto.KeyCapabilities{
+				KeyMeta:          &proto.KeyMeta{Identifier: "rsa-pss"},
+				KeyType:          proto.KeyType_RSA,
+				KeySize:          2048,
+				HashAlgorithms:   rsaHashes,
+				SignatureSchemes: []proto.SignatureScheme{proto.SignatureScheme_PKCS1v15, proto.SignatureScheme_PSS},
+				OutputEncodings:  []string{"base64"},
+				BlobSigning:      true,
+			},
+		},
+		"rsa-pss-disabled": {
+			keyMeta:      &proto.KeyMeta{Identifier: "rsa-no-pss"},
+			expectedCode: codes.OK,
+			expected: &proto.KeyCapabilities{
+				KeyMeta:          &proto.KeyMeta{Identifier: "rsa-no-pss"},
+				KeyType:          proto.KeyType_RSA,
+				KeySize:          2048,
+				HashAlgorithms:   rsaHashes,
+				SignatureSchemes: []proto.SignatureScheme{proto.SignatureScheme_PKCS1v15},
+				OutputEncodings:  []string{"PEM", "base64"},
+				X509Ca:           true,
+				BlobSigning:      true,
+			},
+		},
+		"ecdsa": {
+			keyMeta:      &proto.KeyMeta{Identifier: "ecdsa"},
+			expectedCode: codes.OK,
+			expected: &proto.KeyCapabilities{
+				KeyMeta:          &proto.KeyMeta{Identifier: "ecdsa"},
+				KeyType:          proto.KeyType_ECDSA,
+				KeySize:          384,
+				HashAlgorithms:   ecdsaHashes,
+				SignatureSchemes: []proto.SignatureScheme{proto.SignatureScheme_ECDSA_ASN1, proto.SignatureScheme_ECDSA_P1363},
+				OutputEncodings:  []string{"OpenSSH", "base64"},
+				SshUserCa:        true,
+				SshHostCa:        true,
+				BlobSigning:      true,
+			},
+		},
+		"mldsa": {
+			keyMeta:      &proto.KeyMeta{Identifier: "mldsa"},
+			expectedCode: codes.OK,
+			expected: &proto.KeyCapabilities{
+				KeyMeta:          &proto.KeyMeta{Identifier: "mldsa"},
+				KeyType:          proto.KeyType_MLDSA,
+				ParameterSet:     "ML-DSA-65",
+				HashAlgorithms:   rsaHashes,
+				SignatureSchemes: []proto.SignatureScheme{proto.SignatureScheme_HashMLDSA},
+				OutputEncodings:  []string{"base64"},
+				BlobSigning:      true,
+			},
+		},
+		// The public keys of SLH-DSA keys are not fetched, hence the mock has none.
+		"slhdsa": {
+			keyMeta:      &proto.KeyMeta{Identifier: "slhdsa"},
+			expectedCode: codes.OK,
+			expected: &proto.KeyCapabilities{
+				KeyMeta:          &proto.KeyMeta{Identifier: "slhdsa"},
+				KeyType:          proto.KeyType_SLHDSA,
+				HashAlgorithms:   rsaHashes,
+				SignatureSchemes: []proto.SignatureScheme{proto.SignatureScheme_HashSLHDSA},
+				OutputEncodings:  []string{"base64"},
+				BlobSigning:      true,
+			},
+		},
+		"unknown-key": {
+			keyMeta:      &proto.KeyMeta{Identifier: "random"},
+			expectedCode: codes.InvalidArgument,
+		},
+		"empty-key-meta": {
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			caps, err := ss.GetKeyCapabilities(context.Background(), tt.keyMeta)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(caps, tt.expected) {
+				t.Errorf("in test %v: got capabilities %+v, want %+v", label, caps, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ClockDriftMonitor measures the drift of the local clock against a trusted time source, so that the
+// certificates and timestamps are not issued with bad dates when the clock of the host is off, e.g.
+// after an NTP failure. The signing requests that do not carry a time, such as the blob signing
+// requests, are not affected.
+type ClockDriftMonitor struct {
+	// TrustedTime returns the current time of the trusted source.
+	TrustedTime func(ctx context.Context) (time.Time, error)
+	// MaxDrift is the drift of the local clock beyond which the certificates are not issued.
+	MaxDrift time.Duration
+	// Timeout is the time after which a measurement is canceled.
+	Timeout time.Duration
+	// Now returns the local time. If nil, time.Now is used.
+	Now func() time.Time
+
+	mu sync.RWMutex
+	// drift is the local time minus the trusted time at the last successful measurement.
+	drift time.Duration
+}
+
+// HTTPTrustedTime returns a TrustedTime reading the Date header of the responses of the HEAD requests to
+// the URL. The header has a resolution of one second, so the drifts below a second are not detected.
+func HTTPTrustedTime(url string) func(ctx context.Context) (time.Time, error) {
+	return func(ctx context.Context) (time.Time, error) {
+		req, err := http.NewRequest(http.MethodHead, url, nil)
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp.Body.Close()
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid Date header of %s: %v", url, err)
+		}
+		// The Date header is truncated to the second: its middle is the closest estimate.
+		return date.Add(500 * time.Millisecond), nil
+	}
+}
+
+func (m *ClockDriftMonitor) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// Measure measures the drift of the local clock. The drift of the last successful measurement is
+// kept if it fails.
+func (m *ClockDriftMonitor) Measure(ctx context.Context) error {
+	if m.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.Timeout)
+		defer cancel()
+	}
+	start := m.now()
+	trusted, err := m.TrustedTime(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get trusted time: %v", err)
+	}
+	// The trusted time is taken halfway through the round trip.
+	local := start.Add(m.now().Sub(start) / 2)
+	m.mu.Lock()
+	m.drift = local.Sub(trusted)
+	m.mu.Unlock()
+	return nil
+}
+
+// Run measures the drift of the local clock every interval, forever.
+func (m *ClockDriftMonitor) Run(interval time.Duration) {
+	for {
+		if err := m.Measure(context.Background()); err != nil {
+			log.Printf("unable to measure clock drift: %v", err)
+		} else if drift := m.Drift(); drift > m.MaxDrift || drift < -m.MaxDrift {
+			log.Printf("clock drift %v exceeds %v, certificate issuance is refused", drift, m.MaxDrift)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Drift returns the drift of the local clock at the last successful measurement, or 0 if there was none.
+func (m *ClockDriftMonitor) Drift() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.drift
+}
+
+// checkClockDrift returns a FailedPrecondition error if the last measured drift of the local clock
+// exceeds the maximum drift.
+func (s *SigningService) checkClockDrift() error {
+	if s.ClockDrift == nil {
+		return nil
+	}
+	if drift := s.ClockDrift.Drift(); drift > s.ClockDrift.MaxDrift || drift < -s.ClockDrift.MaxDrift {
+		return status.Errorf(codes.FailedPrecondition, "Clock drift %v exceeds the maximum of %v", drift, s.ClockDrift.MaxDrift)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClockDrift(t *testing.T) {
+	t.Parallel()
+	trusted := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		local        time.Time
+		expectedCode codes.Code
+	}{
+		"in-sync":      {trusted.Add(time.Second), codes.OK},
+		"ahead":        {trusted.Add(time.Hour), codes.FailedPrecondition},
+		"behind":       {trusted.Add(-time.Hour), codes.FailedPrecondition},
+		"at-max-drift": {trusted.Add(-5 * time.Second), codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			monitor := &ClockDriftMonitor{
+				TrustedTime: func(ctx context.Context) (time.Time, error) { return trusted, nil },
+				MaxDrift:    5 * time.Second,
+				Now:         func() time.Time { return tt.local },
+			}
+			if err := monitor.Measure(context.Background()); err != nil {
+				t.Fatalf("in test %v: unable to measure clock drift: %v", label, err)
+			}
+			ss := &SigningService{CertSign: &mockRecordingCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, ClockDrift: monitor}
+			_, err := ss.PostUserSSHCertificate(context.Background(), &proto.SSHCertificateSigningRequest{
+				KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+				PublicKey:  testGoodRsaPubKey,
+				Validity:   3600,
+				Principals: []string{"alice"},
+				KeyId:      testGoodKeyID,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: ssh: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			_, err = ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      testGoodcsrRsa,
+				Validity: 3600,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: x509: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			// The blob signatures carry no time.
+			_, err = ss.PostSignBlob(context.Background(), &proto.BlobSigningRequest{
+				KeyMeta:       &proto.KeyMeta{Identifier: "blobid1"},
+				Digest:        testSHA256Digest,
+				HashAlgorithm: proto.HashAlgo_SHA256,
+			})
+			if err != nil {
+				t.Errorf("in test %v: blob: got err %v, want the blob signed", label, err)
+			}
+		})
+	}
+}
+
+func TestHTTPTrustedTime(t *testing.T) {
+	t.Parallel()
+	date := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", date.Format(http.TimeFormat))
+	}))
+	defer server.Close()
+	got, err := HTTPTrustedTime(server.URL)(context.Background())
+	if err != nil {
+		t.Fatalf("unable to get trusted time: %v", err)
+	}
+	if want := date.Add(500 * time.Millisecond); !got.Equal(want) {
+		t.Errorf("got trusted time %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/x509cert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cmsHashAlgorithms maps the hash algorithms of the detached CMS signatures to their hash functions.
+var cmsHashAlgorithms = map[proto.HashAlgo]crypto.Hash{
+	proto.HashAlgo_SHA256: crypto.SHA256,
+	proto.HashAlgo_SHA384: crypto.SHA384,
+	proto.HashAlgo_SHA512: crypto.SHA512,
+}
+
+// PostSignBlobCMS returns a detached CMS signature of the content of the digest using the specified blob
+// signing key. The X509 CA certificate of the key is the signer certificate.
+func (s *SigningService) PostSignBlobCMS(ctx context.Context, request *proto.CMSSigningRequest) (*proto.CMSSignature, error) {
+	const methodName = "PostSignBlobCMS"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,digest=%q,hash=%q,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), s.redact(request.GetDigest()), request.HashAlgorithm.String(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.BlobEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.BlobEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[config.BlobEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.BlobEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if keyType := s.Keys[request.KeyMeta.Identifier].KeyType; keyType == crypki.Ed25519 {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("CMS signatures are not supported by %s key %q", protoKeyType(keyType), request.KeyMeta.Identifier)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	hash, ok := cmsHashAlgorithms[request.HashAlgorithm]
+	if !ok {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("unsupported hash algorithm %s for CMS signatures", request.HashAlgorithm)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	digest, err := base64.StdEncoding.DecodeString(request.GetDigest())
+	if err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err = s.checkDigest(digest); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if len(digest) != hash.Size() {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("digest of %d bytes does not match hash algorithm %s: want %d bytes", len(digest), request.HashAlgorithm, hash.Size())
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err = s.checkHashFloor(request.KeyMeta.Identifier, hash); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	caPEM, err := s.PublicKeyCache.get(cachedX509CA, request.KeyMeta.Identifier, s.GetX509CACert)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	block, _ := pem.Decode(caPEM)
+	if block == nil {
+		statusCode = http.StatusInternalServerError
+		err = fmt.Errorf("unable to decode signer certificate of key %q", request.KeyMeta.Identifier)
+		return nil, s.internalError(err)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	signer := &keySigner{certSign: s.prioritized(ctx, proto.Priority_NORMAL), identifier: request.KeyMeta.Identifier, public: cert.PublicKey}
+	cms, err := x509cert.SignDetachedCMS(signer, cert, digest, hash, time.Now())
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.CMSSignature{Cms: base64.StdEncoding.EncodeToString(cms)}, nil
+}
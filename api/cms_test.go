@@ -0,0 +1,126 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testSignedData is the CMS SignedData with only the fields checked by the tests parsed.
+type testSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	SignerInfos      []struct {
+		Version            int
+		SID                asn1.RawValue
+		DigestAlgorithm    asn1.RawValue
+		SignedAttrs        asn1.RawValue
+		SignatureAlgorithm asn1.RawValue
+		Signature          []byte
+	} `asn1:"set"`
+}
+
+func TestPostSignBlobCMS(t *testing.T) {
+	t.Parallel()
+	sum := sha256.Sum256([]byte("document"))
+	digest := base64.StdEncoding.EncodeToString(sum[:])
+	keyUsages := map[string]map[string]bool{config.BlobEndpoint: {"cmsid1": true, "ed25519id1": true}}
+	keys := map[string]config.KeyConfig{
+		"cmsid1":     {Identifier: "cmsid1", KeyType: crypki.ECDSA},
+		"ed25519id1": {Identifier: "ed25519id1", KeyType: crypki.Ed25519},
+	}
+	testcases := map[string]struct {
+		request      *proto.CMSSigningRequest
+		expectedCode codes.Code
+	}{
+		"good":              {&proto.CMSSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "cmsid1"}, Digest: digest, HashAlgorithm: proto.HashAlgo_SHA256}, codes.OK},
+		"bad-key":           {&proto.CMSSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Digest: digest, HashAlgorithm: proto.HashAlgo_SHA256}, codes.InvalidArgument},
+		"bad-ed25519-key":   {&proto.CMSSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ed25519id1"}, Digest: digest, HashAlgorithm: proto.HashAlgo_SHA256}, codes.InvalidArgument},
+		"bad-hash":          {&proto.CMSSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "cmsid1"}, Digest: digest, HashAlgorithm: proto.HashAlgo_SHA3_256}, codes.InvalidArgument},
+		"bad-digest-length": {&proto.CMSSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "cmsid1"}, Digest: digest, HashAlgorithm: proto.HashAlgo_SHA512}, codes.InvalidArgument},
+		"no-key-meta":       {&proto.CMSSigningRequest{Digest: digest, HashAlgorithm: proto.HashAlgo_SHA256}, codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys}
+			before := time.Now().Truncate(time.Second)
+			resp, err := ss.PostSignBlobCMS(context.Background(), tt.request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			der, err := base64.StdEncoding.DecodeString(resp.Cms)
+			if err != nil {
+				t.Fatalf("in test %v: unable to decode CMS: %v", label, err)
+			}
+			var ci struct {
+				ContentType asn1.ObjectIdentifier
+				Content     asn1.RawValue
+			}
+			if _, err := asn1.Unmarshal(der, &ci); err != nil {
+				t.Fatalf("in test %v: unable to parse ContentInfo: %v", label, err)
+			}
+			var sd testSignedData
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+				t.Fatalf("in test %v: unable to parse SignedData: %v", label, err)
+			}
+			if !bytes.Equal(sd.Certificates.Bytes, signer.ca.Raw) || len(sd.SignerInfos) != 1 {
+				t.Fatalf("in test %v: SignedData does not have the signer certificate and one SignerInfo", label)
+			}
+			si := sd.SignerInfos[0]
+			signed := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+			if err := signer.ca.CheckSignature(x509.ECDSAWithSHA256, signed, si.Signature); err != nil {
+				t.Fatalf("in test %v: unable to verify the signature: %v", label, err)
+			}
+			var attrs []struct {
+				Type   asn1.ObjectIdentifier
+				Values []asn1.RawValue `asn1:"set"`
+			}
+			if _, err := asn1.UnmarshalWithParams(signed, &attrs, "set"); err != nil {
+				t.Fatalf("in test %v: unable to parse signed attributes: %v", label, err)
+			}
+			messageDigest := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+			signingTime := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+			var md []byte
+			var st time.Time
+			for _, attr := range attrs {
+				switch {
+				case attr.Type.Equal(messageDigest):
+					_, err = asn1.Unmarshal(attr.Values[0].FullBytes, &md)
+				case attr.Type.Equal(signingTime):
+					_, err = asn1.Unmarshal(attr.Values[0].FullBytes, &st)
+				}
+				if err != nil {
+					t.Fatalf("in test %v: unable to parse attribute %v: %v", label, attr.Type, err)
+				}
+			}
+			if !bytes.Equal(md, sum[:]) {
+				t.Errorf("in test %v: message digest attribute does not match the digest", label)
+			}
+			if st.Before(before) || st.After(time.Now()) {
+				t.Errorf("in test %v: got signing time %v, want the server time", label, st)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CallerConcurrencyLimiter limits the number of concurrent signing requests of each caller, identified by
+// the common name of its client certificate, so that a single caller cannot take all the sessions of the
+// HSM even within the rate limits. Requests without a verified client identity are not limited.
+type CallerConcurrencyLimiter struct {
+	// Max is the maximum number of concurrent signing requests of a caller. Zero means no limit.
+	Max int
+	// Limits maps caller identities to their maximum number of concurrent signing requests,
+	// overriding Max. Zero means no limit.
+	Limits map[string]int
+	// Rejected, if set, is called with the full method name and the request of each rejected request.
+	Rejected func(method string, req interface{})
+
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// limit returns the maximum number of concurrent signing requests of the caller.
+func (c *CallerConcurrencyLimiter) limit(caller string) int {
+	if limit, ok := c.Limits[caller]; ok {
+		return limit
+	}
+	return c.Max
+}
+
+// acquire takes a slot of the semaphore of the caller, and returns false if it has none left.
+func (c *CallerConcurrencyLimiter) acquire(caller string, limit int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inFlight[caller] >= limit {
+		return false
+	}
+	if c.inFlight == nil {
+		c.inFlight = make(map[string]int)
+	}
+	c.inFlight[caller]++
+	return true
+}
+
+// release returns a slot to the semaphore of the caller.
+func (c *CallerConcurrencyLimiter) release(caller string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inFlight[caller]--; c.inFlight[caller] == 0 {
+		delete(c.inFlight, caller)
+	}
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor returning ResourceExhausted for the
+// signing requests of a caller that already has its maximum number of signing requests in flight.
+func (c *CallerConcurrencyLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if methodEndpoints[method] == "" || !strings.HasPrefix(method, "Post") {
+			return handler(ctx, req)
+		}
+		caller := callerIdentity(ctx)
+		limit := c.limit(caller)
+		if caller == "" || limit <= 0 {
+			return handler(ctx, req)
+		}
+		if !c.acquire(caller, limit) {
+			if c.Rejected != nil {
+				c.Rejected(info.FullMethod, req)
+			}
+			return nil, status.Errorf(codes.ResourceExhausted, "Resource exhausted: %d concurrent requests of caller %q in flight", limit, caller)
+		}
+		defer c.release(caller)
+		return handler(ctx, req)
+	}
+}
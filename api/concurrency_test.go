@@ -0,0 +1,108 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallerConcurrencyLimiter(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		max    int
+		limits map[string]int
+		method string
+		// expectedCode is the code of the request of alice made while her blocked requests are in flight.
+		expectedCode     codes.Code
+		expectedRejected int
+	}{
+		"caller-at-max": {
+			max:              2,
+			method:           "PostSignBlob",
+			expectedCode:     codes.ResourceExhausted,
+			expectedRejected: 1,
+		},
+		"caller-override": {
+			max:              1,
+			limits:           map[string]int{"alice": 2},
+			method:           "PostSignBlob",
+			expectedCode:     codes.ResourceExhausted,
+			expectedRejected: 1,
+		},
+		"unlimited-caller": {
+			max:    2,
+			limits: map[string]int{"alice": 0},
+			method: "PostSignBlob",
+		},
+		"not-a-signing-method": {
+			max:    2,
+			method: "GetBlobSigningKey",
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			rejected := 0
+			limiter := &CallerConcurrencyLimiter{
+				Max:      tt.max,
+				Limits:   tt.limits,
+				Rejected: func(method string, req interface{}) { rejected++ },
+			}
+			interceptor := limiter.UnaryServerInterceptor()
+			info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/" + tt.method}
+			request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}}
+
+			started := make(chan struct{})
+			unblock := make(chan struct{})
+			blocking := func(ctx context.Context, req interface{}) (interface{}, error) {
+				started <- struct{}{}
+				<-unblock
+				return &proto.Signature{}, nil
+			}
+			done := make(chan error, 2)
+			for i := 0; i < 2; i++ {
+				go func() {
+					_, err := interceptor(contextWithIdentity("alice"), request, info, blocking)
+					done <- err
+				}()
+				<-started
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &proto.Signature{}, nil
+			}
+			_, err := interceptor(contextWithIdentity("alice"), request, info, handler)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got code %v for alice, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if _, err := interceptor(contextWithIdentity("bob"), request, info, handler); err != nil {
+				t.Errorf("in test %v: request of bob failed: %v", label, err)
+			}
+			if _, err := interceptor(context.Background(), request, info, handler); err != nil {
+				t.Errorf("in test %v: request without identity failed: %v", label, err)
+			}
+
+			close(unblock)
+			for i := 0; i < 2; i++ {
+				if err := <-done; err != nil {
+					t.Errorf("in test %v: blocked request of alice failed: %v", label, err)
+				}
+			}
+			if _, err := interceptor(contextWithIdentity("alice"), request, info, handler); err != nil {
+				t.Errorf("in test %v: request of alice after her requests completed failed: %v", label, err)
+			}
+			if rejected != tt.expectedRejected {
+				t.Errorf("in test %v: got %d rejections, want %d", label, rejected, tt.expectedRejected)
+			}
+		})
+	}
+}
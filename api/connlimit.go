@@ -0,0 +1,91 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"log"
+	"net"
+	"sync"
+)
+
+// LimitListener returns a net.Listener that accepts at most max concurrent connections in total,
+// and at most perIP concurrent connections from the same peer IP. Connections beyond the limits
+// are closed as soon as they are accepted. Zero means no limit.
+func LimitListener(l net.Listener, max, perIP int) net.Listener {
+	if max <= 0 && perIP <= 0 {
+		return l
+	}
+	return &limitListener{Listener: l, max: max, perIP: perIP, peers: make(map[string]int)}
+}
+
+type limitListener struct {
+	net.Listener
+	max, perIP int
+
+	mu    sync.Mutex
+	total int
+	peers map[string]int
+}
+
+// Accept waits for and returns the next connection within the limits.
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		ip := peerIP(conn.RemoteAddr())
+		if !l.acquire(ip) {
+			log.Printf("connection limit reached, refusing connection from %s", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		return &limitConn{Conn: conn, release: func() { l.release(ip) }}, nil
+	}
+}
+
+func (l *limitListener) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.max > 0 && l.total >= l.max {
+		return false
+	}
+	if l.perIP > 0 && l.peers[ip] >= l.perIP {
+		return false
+	}
+	l.total++
+	l.peers[ip]++
+	return true
+}
+
+func (l *limitListener) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total--
+	if l.peers[ip]--; l.peers[ip] == 0 {
+		delete(l.peers, ip)
+	}
+}
+
+// peerIP returns the IP of the remote address, or the whole address if it has no port.
+func peerIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// limitConn is a connection that releases its slot of the listener limits once closed.
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,136 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// serveEcho accepts connections from l and echoes the data they send until l is closed.
+func serveEcho(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			buf := make([]byte, 1)
+			for {
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+				if _, err := conn.Write(buf); err != nil {
+					return
+				}
+			}
+		}(conn)
+	}
+}
+
+// dialServed dials addr from localIP and reports whether the connection is served.
+func dialServed(t *testing.T, addr, localIP string) (net.Conn, bool) {
+	t.Helper()
+	d := net.Dialer{LocalAddr: &net.TCPAddr{IP: net.ParseIP(localIP)}}
+	conn, err := d.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("unable to dial %s: %v", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 1)
+	if _, err := conn.Write([]byte("x")); err != nil {
+		return conn, false
+	}
+	if _, err := conn.Read(buf); err != nil {
+		return conn, false
+	}
+	return conn, true
+}
+
+func TestLimitListener(t *testing.T) {
+	t.Parallel()
+	type dial struct {
+		localIP string
+		served  bool
+	}
+	testcases := map[string]struct {
+		max, perIP int
+		dials      []dial
+	}{
+		"per-ip-limit": {
+			perIP: 2,
+			dials: []dial{{"127.0.0.1", true}, {"127.0.0.1", true}, {"127.0.0.1", false}, {"127.0.0.2", true}},
+		},
+		"total-limit": {
+			max:   2,
+			dials: []dial{{"127.0.0.1", true}, {"127.0.0.2", true}, {"127.0.0.3", false}},
+		},
+		"both-limits": {
+			max:   3,
+			perIP: 1,
+			dials: []dial{{"127.0.0.1", true}, {"127.0.0.1", false}, {"127.0.0.2", true}, {"127.0.0.3", true}, {"127.0.0.4", false}},
+		},
+		"no-limit": {
+			dials: []dial{{"127.0.0.1", true}, {"127.0.0.1", true}, {"127.0.0.1", true}},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+			go serveEcho(LimitListener(l, tt.max, tt.perIP))
+
+			var conns []net.Conn
+			defer func() {
+				for _, conn := range conns {
+					conn.Close()
+				}
+			}()
+			for i, d := range tt.dials {
+				conn, served := dialServed(t, l.Addr().String(), d.localIP)
+				conns = append(conns, conn)
+				if served != d.served {
+					t.Fatalf("in test %v: connection %d from %s: got served %v, want %v", label, i, d.localIP, served, d.served)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitListenerRelease(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go serveEcho(LimitListener(l, 0, 1))
+
+	conn, served := dialServed(t, l.Addr().String(), "127.0.0.1")
+	if !served {
+		t.Fatal("first connection refused")
+	}
+	conn.Close()
+	// The slot is released once the server notices the closed connection.
+	deadline := time.Now().Add(time.Second)
+	for {
+		conn, served := dialServed(t, l.Addr().String(), "127.0.0.1")
+		conn.Close()
+		if served {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("connection refused after the previous connection was closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
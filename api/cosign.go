@@ -0,0 +1,181 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// cosignSignatureType is the type of the simple signing payloads of cosign.
+const cosignSignatureType = "cosign container image signature"
+
+// manifestDigestRegexp matches the digests of image manifests, such as "sha256:<hex>".
+var manifestDigestRegexp = regexp.MustCompile(`^(sha256:[0-9a-f]{64}|sha384:[0-9a-f]{96}|sha512:[0-9a-f]{128})$`)
+
+// simpleSigning is the simple signing payload of a container image signature, as signed by cosign.
+// Its fields are in the order that cosign marshals them, so that a composed payload is identical
+// to the one that "cosign generate" outputs.
+type simpleSigning struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+// cosignPayload returns the simple signing payload of the request: the payload of the request after
+// checking it, or else the payload composed from its docker reference and manifest digest.
+func cosignPayload(request *proto.ContainerImageSigningRequest) ([]byte, error) {
+	var p simpleSigning
+	if len(request.Payload) == 0 {
+		p.Critical.Identity.DockerReference = request.DockerReference
+		p.Critical.Image.DockerManifestDigest = request.ManifestDigest
+		p.Critical.Type = cosignSignatureType
+	} else if err := json.Unmarshal(request.Payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid simple signing payload: %v", err)
+	}
+	if p.Critical.Type != cosignSignatureType {
+		return nil, fmt.Errorf("unsupported simple signing type %q", p.Critical.Type)
+	}
+	if p.Critical.Identity.DockerReference == "" {
+		return nil, errors.New("docker reference is empty")
+	}
+	if !manifestDigestRegexp.MatchString(p.Critical.Image.DockerManifestDigest) {
+		return nil, fmt.Errorf("invalid manifest digest %q", p.Critical.Image.DockerManifestDigest)
+	}
+	if len(request.Payload) != 0 {
+		if request.ManifestDigest != "" && request.ManifestDigest != p.Critical.Image.DockerManifestDigest {
+			return nil, fmt.Errorf("manifest digest %q does not match the payload", request.ManifestDigest)
+		}
+		if request.DockerReference != "" && request.DockerReference != p.Critical.Identity.DockerReference {
+			return nil, fmt.Errorf("docker reference %q does not match the payload", request.DockerReference)
+		}
+		// The payload is signed as is, as cosign verifies the signature of the uploaded bytes.
+		return request.Payload, nil
+	}
+	return json.Marshal(&p)
+}
+
+// checkCosignKey returns an error unless the public key is an ECDSA key, whose signatures cosign verifies.
+func checkCosignKey(pub crypto.PublicKey) error {
+	if _, ok := pub.(*ecdsa.PublicKey); !ok {
+		return fmt.Errorf("unsupported public key type %T for cosign signatures", pub)
+	}
+	return nil
+}
+
+// PostSignContainerImage returns the cosign signature of the simple signing payload of a container image,
+// signed by the specified ECDSA key. The signature and the public key are encoded as cosign uploads and
+// reads them, so that crypki can act as the signing backend of cosign.
+func (s *SigningService) PostSignContainerImage(ctx context.Context, request *proto.ContainerImageSigningRequest) (*proto.ContainerImageSignature, error) {
+	const methodName = "PostSignContainerImage"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,ref=%q,digest=%q,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), request.GetDockerReference(), request.GetManifestDigest(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.ContainerImageEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.ContainerImageEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	payload, err := cosignPayload(request)
+	if err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[config.ContainerImageEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.ContainerImageEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	pemKey, err := s.PublicKeyCache.get(cachedBlobKey, request.KeyMeta.Identifier, s.GetBlobSigningPublicKey)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		statusCode = http.StatusInternalServerError
+		err = fmt.Errorf("unable to decode public key of %q", request.KeyMeta.Identifier)
+		return nil, s.internalError(err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	if err = checkCosignKey(pub); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	// cosign signs and verifies SHA256 digests of the payloads with ECDSA keys of every curve.
+	hash := crypto.SHA256
+	if err = s.checkHashFloor(request.KeyMeta.Identifier, hash); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	h := hash.New()
+	h.Write(payload)
+
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	signature, err := s.signBlob(ctx, h.Sum(nil), hash, request.KeyMeta.Identifier, proto.Priority_NORMAL)
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.ContainerImageSignature{
+		Payload:   payload,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		PublicKey: string(pem.EncodeToMemory(block)),
+	}, nil
+}
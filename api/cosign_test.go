@@ -0,0 +1,177 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testManifestDigest = "sha256:4a7ba1a52c1d1d73b143a0a0ab8d1ed2ee9ec51dc8b4c3c6a8e1e3f5b7fbd0e2"
+
+// cosignVerify verifies the cosign signature of the payload as "cosign verify --key" does: it loads the
+// PEM public key, verifies the base64 signature of the SHA256 digest of the payload and checks the
+// manifest digest claimed by the payload.
+func cosignVerify(publicKey string, signature string, payload []byte, manifestDigest string) error {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return errors.New("invalid PEM public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("not an ECDSA public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(payload)
+	if !ecdsa.VerifyASN1(ecPub, digest[:], sig) {
+		return errors.New("invalid signature")
+	}
+	var p simpleSigning
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+	if p.Critical.Type != cosignSignatureType || p.Critical.Image.DockerManifestDigest != manifestDigest {
+		return errors.New("payload does not match the image")
+	}
+	return nil
+}
+
+func TestPostSignContainerImage(t *testing.T) {
+	t.Parallel()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	keys := map[string]crypto.PublicKey{"cosignid1": &ecKey.PublicKey, "cosignid2": &rsaKey.PublicKey}
+	ss := &SigningService{
+		CertSign:       &mockECDSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: keys}}, ecKey},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.ContainerImageEndpoint: {"cosignid1": true, "cosignid2": true}},
+	}
+	// The payload generated by "cosign generate registry.example.com/app@<digest>".
+	generated := `{"critical":{"identity":{"docker-reference":"registry.example.com/app"},"image":{"docker-manifest-digest":"` + testManifestDigest + `"},"type":"cosign container image signature"},"optional":null}`
+	testcases := map[string]struct {
+		request         *proto.ContainerImageSigningRequest
+		expectedCode    codes.Code
+		expectedPayload string
+	}{
+		"composed-payload": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: "cosignid1"},
+				DockerReference: "registry.example.com/app",
+				ManifestDigest:  testManifestDigest,
+			},
+			expectedCode:    codes.OK,
+			expectedPayload: generated,
+		},
+		"cosign-payload": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta:        &proto.KeyMeta{Identifier: "cosignid1"},
+				Payload:        []byte(`{"critical":{"identity":{"docker-reference":"registry.example.com/app"},"image":{"docker-manifest-digest":"` + testManifestDigest + `"},"type":"cosign container image signature"},"optional":{"build":"42"}}`),
+				ManifestDigest: testManifestDigest,
+			},
+			expectedCode:    codes.OK,
+			expectedPayload: `{"critical":{"identity":{"docker-reference":"registry.example.com/app"},"image":{"docker-manifest-digest":"` + testManifestDigest + `"},"type":"cosign container image signature"},"optional":{"build":"42"}}`,
+		},
+		"mismatched-digest": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta:        &proto.KeyMeta{Identifier: "cosignid1"},
+				Payload:        []byte(generated),
+				ManifestDigest: "sha256:" + strings.Repeat("0", 64),
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		"invalid-digest": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: "cosignid1"},
+				DockerReference: "registry.example.com/app",
+				ManifestDigest:  "latest",
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		"wrong-type": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta: &proto.KeyMeta{Identifier: "cosignid1"},
+				Payload: []byte(strings.Replace(generated, cosignSignatureType, "atomic container signature", 1)),
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		"invalid-json": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta: &proto.KeyMeta{Identifier: "cosignid1"},
+				Payload: []byte("not json"),
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		"rsa-key": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: "cosignid2"},
+				DockerReference: "registry.example.com/app",
+				ManifestDigest:  testManifestDigest,
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		"unknown-key": {
+			request: &proto.ContainerImageSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: "randomid"},
+				DockerReference: "registry.example.com/app",
+				ManifestDigest:  testManifestDigest,
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		"no-key-meta": {
+			request:      &proto.ContainerImageSigningRequest{Payload: []byte(generated)},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			resp, err := ss.PostSignContainerImage(context.Background(), tt.request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if string(resp.Payload) != tt.expectedPayload {
+				t.Errorf("in test %v: got payload %s, want %s", label, resp.Payload, tt.expectedPayload)
+			}
+			if err := cosignVerify(resp.PublicKey, resp.Signature, resp.Payload, testManifestDigest); err != nil {
+				t.Errorf("in test %v: unable to verify cosign signature: %v", label, err)
+			}
+		})
+	}
+}
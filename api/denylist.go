@@ -0,0 +1,119 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bufio"
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SubjectKeyDenyList is the list of the fingerprints of the public keys that are never certified, such as
+// known-compromised keys. The fingerprints are those returned by PublicKeyFingerprint.
+type SubjectKeyDenyList struct {
+	// Path is the path of the file the list is loaded from.
+	Path string
+
+	// reloadMu serializes the reloads, so that a reload of an older version of the file never replaces
+	// the list loaded by a later one, e.g. on two SIGHUPs close together.
+	reloadMu sync.Mutex
+
+	mu     sync.RWMutex
+	denied map[string]bool
+}
+
+// LoadSubjectKeyDenyList returns the SubjectKeyDenyList loaded from the file at path.
+func LoadSubjectKeyDenyList(path string) (*SubjectKeyDenyList, error) {
+	d := &SubjectKeyDenyList{Path: path}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload replaces the list by the fingerprints in the file at Path, one per line. Empty lines and lines
+// starting with "#" are ignored, as is the text following a fingerprint after a space. The list is
+// unchanged if the file cannot be loaded. A reload waits for the one in progress, if any, and the checks
+// use the previous list until it is replaced.
+func (d *SubjectKeyDenyList) Reload() error {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+	f, err := os.Open(d.Path)
+	if err != nil {
+		return fmt.Errorf("unable to open subject key deny list: %v", err)
+	}
+	defer f.Close()
+	denied := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		fp := strings.ToLower(fields[0])
+		if b, err := hex.DecodeString(fp); err != nil || len(b) != 32 {
+			return fmt.Errorf("invalid fingerprint %q on line %d of subject key deny list %s", fields[0], n, d.Path)
+		}
+		denied[fp] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read subject key deny list: %v", err)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.denied = denied
+	return nil
+}
+
+// Len returns the number of fingerprints in the list.
+func (d *SubjectKeyDenyList) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.denied)
+}
+
+// Check returns an error if the fingerprint of the public key is in the list.
+func (d *SubjectKeyDenyList) Check(pub crypto.PublicKey) error {
+	fp, err := PublicKeyFingerprint(pub)
+	if err != nil {
+		return fmt.Errorf("unable to compute fingerprint of subject key: %v", err)
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.denied[fp] {
+		return fmt.Errorf("subject key with fingerprint %s is denied", fp)
+	}
+	return nil
+}
+
+// checkSubjectKey returns PermissionDenied if the subject key is in the SubjectKeyDenyList.
+func (s *SigningService) checkSubjectKey(pub crypto.PublicKey) error {
+	if s.SubjectKeyDenyList == nil {
+		return nil
+	}
+	if err := s.SubjectKeyDenyList.Check(pub); err != nil {
+		return status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+	return nil
+}
+
+// checkSSHSubjectKey returns PermissionDenied if the subject key of an SSH certificate is in the SubjectKeyDenyList.
+func (s *SigningService) checkSSHSubjectKey(pub ssh.PublicKey) error {
+	if s.SubjectKeyDenyList == nil {
+		return nil
+	}
+	cpk, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "Bad request: unsupported subject key type %q", pub.Type())
+	}
+	return s.checkSubjectKey(cpk.CryptoPublicKey())
+}
@@ -0,0 +1,187 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeDenyList writes the content to a temporary deny list file and returns its path.
+func writeDenyList(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "denylist")
+	if err != nil {
+		t.Fatalf("unable to create deny list: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("unable to write deny list: %v", err)
+	}
+	return f.Name()
+}
+
+func TestSubjectKeyDenyList(t *testing.T) {
+	t.Parallel()
+	deniedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	goodKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	sshPub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(testGoodRsaPubKey))
+	if err != nil {
+		t.Fatalf("unable to parse SSH public key: %v", err)
+	}
+	deniedFP, err := PublicKeyFingerprint(&deniedKey.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+	sshFP, err := PublicKeyFingerprint(sshPub.(ssh.CryptoPublicKey).CryptoPublicKey())
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+	path := writeDenyList(t, "# compromised keys\n\n"+deniedFP+" leaked in incident 42\n")
+	defer os.Remove(path)
+	denyList, err := LoadSubjectKeyDenyList(path)
+	if err != nil {
+		t.Fatalf("unable to load deny list: %v", err)
+	}
+	ss := &SigningService{
+		CertSign:           &mockGoodCertSign{},
+		KeyIDProcessor:     &crypki.KeyID{},
+		KeyUsages:          combineKeyUsage,
+		SubjectKeyDenyList: denyList,
+	}
+	x509Request := func(key *ecdsa.PrivateKey) *proto.X509CertificateSigningRequest {
+		return &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: genCSR(t, key), Validity: 3600}
+	}
+	sshRequest := &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+		PublicKey:  testGoodRsaPubKey,
+		Validity:   3600,
+		Principals: []string{"alice"},
+		KeyId:      testGoodKeyID,
+	}
+
+	if _, err := ss.PostX509Certificate(context.Background(), x509Request(deniedKey)); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got %v for a denied x509 subject key, want PermissionDenied", err)
+	}
+	if _, err := ss.PreviewX509Certificate(context.Background(), x509Request(deniedKey)); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got %v for the preview of a denied x509 subject key, want PermissionDenied", err)
+	}
+	if _, err := ss.PostX509Certificate(context.Background(), x509Request(goodKey)); err != nil {
+		t.Errorf("unable to sign x509 certificate of a key not denied: %v", err)
+	}
+	if _, err := ss.PostUserSSHCertificate(context.Background(), sshRequest); err != nil {
+		t.Errorf("unable to sign SSH certificate of a key not denied: %v", err)
+	}
+
+	// The SSH key is denied once the list is reloaded.
+	if err := ioutil.WriteFile(path, []byte(sshFP+"\n"), 0644); err != nil {
+		t.Fatalf("unable to write deny list: %v", err)
+	}
+	if err := denyList.Reload(); err != nil {
+		t.Fatalf("unable to reload deny list: %v", err)
+	}
+	if _, err := ss.PostUserSSHCertificate(context.Background(), sshRequest); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got %v for a denied SSH subject key, want PermissionDenied", err)
+	}
+	if _, err := ss.PostX509Certificate(context.Background(), x509Request(deniedKey)); err != nil {
+		t.Errorf("unable to sign x509 certificate of a key removed from the deny list: %v", err)
+	}
+
+	// A list failing to reload is left unchanged.
+	if err := ioutil.WriteFile(path, []byte("not-a-fingerprint\n"), 0644); err != nil {
+		t.Fatalf("unable to write deny list: %v", err)
+	}
+	if err := denyList.Reload(); err == nil {
+		t.Errorf("reloaded an invalid deny list")
+	}
+	if denyList.Len() != 1 {
+		t.Errorf("got %d denied keys after a failed reload, want 1", denyList.Len())
+	}
+}
+
+func TestSubjectKeyDenyListConcurrentReloads(t *testing.T) {
+	t.Parallel()
+	var fps []string
+	for i := 0; i < 3; i++ {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("unable to generate EC key: %v", err)
+		}
+		fp, err := PublicKeyFingerprint(&key.PublicKey)
+		if err != nil {
+			t.Fatalf("unable to compute fingerprint: %v", err)
+		}
+		fps = append(fps, fp)
+	}
+	path := writeDenyList(t, fps[0]+"\n")
+	defer os.Remove(path)
+	denyList, err := LoadSubjectKeyDenyList(path)
+	if err != nil {
+		t.Fatalf("unable to load deny list: %v", err)
+	}
+	// replace atomically replaces the file, so that a reload never reads a partially written list.
+	replace := func(content string) {
+		tmp := writeDenyList(t, content)
+		if err := os.Rename(tmp, path); err != nil {
+			t.Errorf("unable to replace deny list: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if i%2 == 0 {
+					replace(strings.Join(fps[:1+(i+j)%3], "\n"))
+				}
+				if err := denyList.Reload(); err != nil {
+					t.Errorf("unable to reload deny list: %v", err)
+				}
+				if n := denyList.Len(); n < 1 || n > 3 {
+					t.Errorf("got %d denied keys during reloads, want 1 to 3", n)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Of two simultaneous reloads of the final list, the last one to apply has loaded the final list.
+	replace(fps[1] + "\n" + fps[2] + "\n")
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := denyList.Reload(); err != nil {
+				t.Errorf("unable to reload deny list: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	denyList.mu.RLock()
+	defer denyList.mu.RUnlock()
+	if expected := map[string]bool{fps[1]: true, fps[2]: true}; !reflect.DeepEqual(denyList.denied, expected) {
+		t.Errorf("got denied keys %v after the reloads, want %v", denyList.denied, expected)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DNSSEC algorithm numbers of the signatures produced by crypki, as registered by IANA.
+const (
+	dnssecRSASHA256       = 8
+	dnssecRSASHA512       = 10
+	dnssecECDSAP256SHA256 = 13
+	dnssecECDSAP384SHA384 = 14
+)
+
+// dnssecAlgorithm returns the DNSSEC algorithm number and the hash function of the RRSIG signatures
+// of the public key with the requested hash algorithm, or an error if the combination is not supported.
+func dnssecAlgorithm(pub crypto.PublicKey, hashAlgo proto.HashAlgo) (uint32, crypto.Hash, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		switch hashAlgo {
+		case proto.HashAlgo_Unspecified_Hash, proto.HashAlgo_SHA256:
+			return dnssecRSASHA256, crypto.SHA256, nil
+		case proto.HashAlgo_SHA512:
+			return dnssecRSASHA512, crypto.SHA512, nil
+		}
+		return 0, 0, fmt.Errorf("hash algorithm %s is not supported for DNSSEC RSA signatures", hashAlgo)
+	case *ecdsa.PublicKey:
+		var alg uint32
+		var hash crypto.Hash
+		var want proto.HashAlgo
+		switch pub.Curve {
+		case elliptic.P256():
+			alg, hash, want = dnssecECDSAP256SHA256, crypto.SHA256, proto.HashAlgo_SHA256
+		case elliptic.P384():
+			alg, hash, want = dnssecECDSAP384SHA384, crypto.SHA384, proto.HashAlgo_SHA384
+		default:
+			return 0, 0, fmt.Errorf("curve %s is not supported for DNSSEC signatures", pub.Curve.Params().Name)
+		}
+		if hashAlgo != proto.HashAlgo_Unspecified_Hash && hashAlgo != want {
+			return 0, 0, fmt.Errorf("hash algorithm %s does not match the %s curve of the key", hashAlgo, pub.Curve.Params().Name)
+		}
+		return alg, hash, nil
+	}
+	return 0, 0, fmt.Errorf("unsupported public key type %T for DNSSEC signatures", pub)
+}
+
+// PostSignDNSSEC returns the RRSIG signature of the RRset signing data, signed by the specified
+// RSA or ECDSA zone-signing key, with the DNSSEC algorithm number of the signature.
+func (s *SigningService) PostSignDNSSEC(ctx context.Context, request *proto.DNSSECSigningRequest) (*proto.DNSSECSignature, error) {
+	const methodName = "PostSignDNSSEC"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,size=%d,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), len(request.GetSigningData()), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.DNSSECEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.DNSSECEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if len(request.SigningData) == 0 {
+		statusCode = http.StatusBadRequest
+		err = errors.New("RRSIG signing data is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[config.DNSSECEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.DNSSECEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	pemKey, err := s.PublicKeyCache.get(cachedBlobKey, request.KeyMeta.Identifier, s.GetBlobSigningPublicKey)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		statusCode = http.StatusInternalServerError
+		err = fmt.Errorf("unable to decode public key of %q", request.KeyMeta.Identifier)
+		return nil, s.internalError(err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	algorithm, hash, err := dnssecAlgorithm(pub, request.HashAlgorithm)
+	if err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err = s.checkHashFloor(request.KeyMeta.Identifier, hash); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	h := hash.New()
+	h.Write(request.SigningData)
+
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	signature, err := s.signBlob(ctx, h.Sum(nil), hash, request.KeyMeta.Identifier, proto.Priority_NORMAL)
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	// The HSM returns DER encoded ECDSA signatures, whereas RRSIG records hold r and s as per RFC 6605.
+	if _, ok := pub.(*ecdsa.PublicKey); ok {
+		if signature, err = ecdsaP1363(signature, pemKey); err != nil {
+			statusCode = http.StatusInternalServerError
+			return nil, s.internalError(err)
+		}
+	}
+	return &proto.DNSSECSignature{Signature: signature, Algorithm: algorithm}, nil
+}
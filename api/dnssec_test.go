@@ -0,0 +1,203 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockRSACertSign is a mockPEMCertSign that signs blobs with an RSA key in software.
+type mockRSACertSign struct {
+	mockPEMCertSign
+	key *rsa.PrivateKey
+}
+
+func (m *mockRSACertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		return rsa.SignPSS(rand.Reader, m.key, pssOpts.Hash, digest, pssOpts)
+	}
+	return rsa.SignPKCS1v15(rand.Reader, m.key, opts.HashFunc(), digest)
+}
+
+// dnsName returns the canonical wire format of the fully qualified domain name.
+func dnsName(name string) []byte {
+	var b bytes.Buffer
+	for _, label := range strings.Split(strings.TrimSuffix(strings.ToLower(name), "."), ".") {
+		b.WriteByte(byte(len(label)))
+		b.WriteString(label)
+	}
+	b.WriteByte(0)
+	return b.Bytes()
+}
+
+// rrsigSigningData returns the RFC 4034 signing data of the RRSIG of the www.example.com. A RRset
+// with the algorithm, i.e. the RRSIG RDATA without the signature followed by the canonical RRset.
+func rrsigSigningData(algorithm uint8) []byte {
+	var b bytes.Buffer
+	const typeA, classIN, ttl = 1, 1, 3600
+	binary.Write(&b, binary.BigEndian, uint16(typeA))
+	b.WriteByte(algorithm)
+	b.WriteByte(3) // labels of www.example.com.
+	binary.Write(&b, binary.BigEndian, uint32(ttl))
+	binary.Write(&b, binary.BigEndian, uint32(1577836800)) // expiration
+	binary.Write(&b, binary.BigEndian, uint32(1575158400)) // inception
+	binary.Write(&b, binary.BigEndian, uint16(12345))      // key tag
+	b.Write(dnsName("example.com."))
+	// The records are in canonical order of their RDATA.
+	for _, ip := range [][]byte{{192, 0, 2, 1}, {192, 0, 2, 2}} {
+		b.Write(dnsName("www.example.com."))
+		binary.Write(&b, binary.BigEndian, uint16(typeA))
+		binary.Write(&b, binary.BigEndian, uint16(classIN))
+		binary.Write(&b, binary.BigEndian, uint32(ttl))
+		binary.Write(&b, binary.BigEndian, uint16(len(ip)))
+		b.Write(ip)
+	}
+	return b.Bytes()
+}
+
+// verifyRRSIG verifies the RRSIG signature of the signing data with the algorithm as a DNS resolver does.
+func verifyRRSIG(t *testing.T, pub crypto.PublicKey, algorithm uint32, data, sig []byte) {
+	t.Helper()
+	switch algorithm {
+	case dnssecRSASHA256, dnssecRSASHA512:
+		hash := crypto.SHA256
+		if algorithm == dnssecRSASHA512 {
+			hash = crypto.SHA512
+		}
+		h := hash.New()
+		h.Write(data)
+		if err := rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), hash, h.Sum(nil), sig); err != nil {
+			t.Errorf("invalid RRSIG signature: %v", err)
+		}
+	case dnssecECDSAP256SHA256, dnssecECDSAP384SHA384:
+		hash, size := crypto.SHA256, 32
+		if algorithm == dnssecECDSAP384SHA384 {
+			hash, size = crypto.SHA384, 48
+		}
+		if len(sig) != 2*size {
+			t.Fatalf("got RRSIG signature of %d bytes, want %d", len(sig), 2*size)
+		}
+		h := hash.New()
+		h.Write(data)
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub.(*ecdsa.PublicKey), h.Sum(nil), r, s) {
+			t.Error("invalid RRSIG signature")
+		}
+	default:
+		t.Fatalf("unexpected DNSSEC algorithm %d", algorithm)
+	}
+}
+
+func TestPostSignDNSSEC(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	keyUsages := map[string]map[string]bool{config.DNSSECEndpoint: {"zsk": true}}
+	testcases := map[string]struct {
+		certSign          crypki.CertSign
+		public            crypto.PublicKey
+		hash              proto.HashAlgo
+		expectedAlgorithm uint32
+		expectedCode      codes.Code
+	}{
+		"rsa-default-hash": {
+			certSign:          &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"zsk": &rsaKey.PublicKey}}}, rsaKey},
+			public:            &rsaKey.PublicKey,
+			expectedAlgorithm: dnssecRSASHA256,
+		},
+		"rsa-sha512": {
+			certSign:          &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"zsk": &rsaKey.PublicKey}}}, rsaKey},
+			public:            &rsaKey.PublicKey,
+			hash:              proto.HashAlgo_SHA512,
+			expectedAlgorithm: dnssecRSASHA512,
+		},
+		"rsa-sha224": {
+			certSign:     &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"zsk": &rsaKey.PublicKey}}}, rsaKey},
+			hash:         proto.HashAlgo_SHA224,
+			expectedCode: codes.InvalidArgument,
+		},
+		"ecdsa-p256": {
+			certSign:          &mockECDSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"zsk": &p256Key.PublicKey}}}, p256Key},
+			public:            &p256Key.PublicKey,
+			expectedAlgorithm: dnssecECDSAP256SHA256,
+		},
+		"ecdsa-p384": {
+			certSign:          &mockECDSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"zsk": &p384Key.PublicKey}}}, p384Key},
+			public:            &p384Key.PublicKey,
+			hash:              proto.HashAlgo_SHA384,
+			expectedAlgorithm: dnssecECDSAP384SHA384,
+		},
+		"ecdsa-p256-hash-mismatch": {
+			certSign:     &mockECDSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"zsk": &p256Key.PublicKey}}}, p256Key},
+			hash:         proto.HashAlgo_SHA384,
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: tt.certSign, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages}
+			data := rrsigSigningData(uint8(tt.expectedAlgorithm))
+			request := &proto.DNSSECSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "zsk"}, SigningData: data, HashAlgorithm: tt.hash}
+			resp, err := ss.PostSignDNSSEC(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Algorithm != tt.expectedAlgorithm {
+				t.Errorf("in test %v: got algorithm %d, want %d", label, resp.Algorithm, tt.expectedAlgorithm)
+			}
+			verifyRRSIG(t, tt.public, resp.Algorithm, data, resp.Signature)
+		})
+	}
+}
+
+func TestPostSignDNSSECBadRequest(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{
+		CertSign:       &mockGoodCertSign{},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.DNSSECEndpoint: {"zsk": true}},
+	}
+	testcases := map[string]*proto.DNSSECSigningRequest{
+		"no-key-meta":  {SigningData: []byte("data")},
+		"empty-data":   {KeyMeta: &proto.KeyMeta{Identifier: "zsk"}},
+		"unusable-key": {KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, SigningData: []byte("data")},
+	}
+	for label, request := range testcases {
+		if _, err := ss.PostSignDNSSEC(context.Background(), request); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("in test %v: got err %v, want InvalidArgument", label, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PostEphemeralSignature signs the digest with a one-time key generated in the HSM and returns
+// the signature along with the public key of the key. The configurations of the one-time keys
+// are used once listed in the KeyUsages of "/sig/ephemeral".
+func (s *SigningService) PostEphemeralSignature(ctx context.Context, request *proto.EphemeralSigningRequest) (*proto.EphemeralSignature, error) {
+	const methodName = "PostEphemeralSignature"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,digest=%q,hash=%q,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(),
+			s.redact(request.GetDigest()), request.GetHashAlgorithm().String(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.EphemeralEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if s.EphemeralSigner == nil {
+		statusCode = http.StatusNotImplemented
+		err = errors.New("ephemeral signing is not supported")
+		return nil, status.Error(codes.Unimplemented, "Ephemeral signing is not supported")
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.EphemeralEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	key, ok := s.EphemeralKeys[request.KeyMeta.Identifier]
+	if !ok {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("unknown ephemeral key %q", request.KeyMeta.Identifier)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if !s.KeyUsages[config.EphemeralEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.EphemeralEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	digest, err := base64.StdEncoding.DecodeString(request.GetDigest())
+	if err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err = s.checkDigest(digest); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	opts, err := ephemeralSignerOpts(key.KeyType, request.HashAlgorithm, digest)
+	if err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	signature, pub, err := s.EphemeralSigner.SignEphemeral(&crypki.EphemeralKeyParams{
+		Module:     key.Module,
+		SlotNumber: key.SlotNumber,
+		KeyType:    key.KeyType,
+		KeySize:    key.KeySize,
+	}, digest, opts)
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.EphemeralSignature{
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}, nil
+}
+
+// ephemeralSignerOpts returns the signer options of the digest for a one-time key of the key type.
+func ephemeralSignerOpts(keyType crypki.PublicKeyAlgorithm, hashAlgo proto.HashAlgo, digest []byte) (crypto.SignerOpts, error) {
+	if keyType == crypki.Ed25519 && hashAlgo == proto.HashAlgo_Unspecified_Hash {
+		return crypto.Hash(0), nil
+	}
+	if hashAlgo == proto.HashAlgo_Unspecified_Hash {
+		return nil, errors.New("hash algorithm must be specified")
+	}
+	approved := false
+	for _, h := range hashAlgorithms(config.KeyConfig{KeyType: keyType}) {
+		approved = approved || h == hashAlgo
+	}
+	if !approved {
+		return nil, fmt.Errorf("hash algorithm %s is not supported by %s ephemeral keys", hashAlgo, protoKeyType(keyType))
+	}
+	opts := getSignerOpts(hashAlgo.String())
+	if size := opts.HashFunc().Size(); len(digest) != size {
+		return nil, fmt.Errorf("digest of %d bytes does not match hash algorithm %s: want %d bytes", len(digest), hashAlgo, size)
+	}
+	if keyType == crypki.Ed25519 {
+		return ed25519phOpts(opts, "")
+	}
+	return opts, nil
+}
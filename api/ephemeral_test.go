@@ -0,0 +1,165 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockEphemeralSigner signs with a key generated in software for each request.
+type mockEphemeralSigner struct {
+	generated int
+}
+
+func (m *mockEphemeralSigner) SignEphemeral(params *crypki.EphemeralKeyParams, digest []byte, opts crypto.SignerOpts) ([]byte, crypto.PublicKey, error) {
+	m.generated++
+	if params.KeyType == crypki.Ed25519 {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		signature, err := priv.Sign(rand.Reader, digest, opts)
+		return signature, pub, err
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, params.KeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := priv.Sign(rand.Reader, digest, opts)
+	return signature, &priv.PublicKey, err
+}
+
+func TestPostEphemeralSignature(t *testing.T) {
+	t.Parallel()
+	digest := sha256.Sum256([]byte("ephemeral"))
+	encoded := base64.StdEncoding.EncodeToString(digest[:])
+	signer := &mockEphemeralSigner{}
+	ss := &SigningService{
+		CertSign:        &mockGoodCertSign{},
+		KeyIDProcessor:  &crypki.KeyID{},
+		EphemeralSigner: signer,
+		EphemeralKeys: map[string]config.EphemeralKeyConfig{
+			"ephemeral-rsa":      {Identifier: "ephemeral-rsa", SlotNumber: 1, KeyType: crypki.RSA, KeySize: 2048},
+			"ephemeral-ed25519":  {Identifier: "ephemeral-ed25519", SlotNumber: 1, KeyType: crypki.Ed25519},
+			"ephemeral-unlisted": {Identifier: "ephemeral-unlisted", SlotNumber: 1, KeyType: crypki.Ed25519},
+			"ephemeral-window": {Identifier: "ephemeral-window", SlotNumber: 1, KeyType: crypki.Ed25519,
+				SigningWindow: &config.SigningWindow{StartHour: 9, EndHour: 17}},
+		},
+		KeyUsages: map[string]map[string]bool{
+			config.EphemeralEndpoint: {"ephemeral-rsa": true, "ephemeral-ed25519": true, "ephemeral-window": true},
+		},
+		Now: func() time.Time { return time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC) },
+	}
+	testcases := map[string]struct {
+		request    *proto.EphemeralSigningRequest
+		expectCode codes.Code
+	}{
+		"good-rsa": {
+			request: &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ephemeral-rsa"}, Digest: encoded, HashAlgorithm: proto.HashAlgo_SHA256},
+		},
+		"good-ed25519": {
+			request: &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ephemeral-ed25519"}, Digest: encoded},
+		},
+		"bad-unknown-key": {
+			request:    &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: encoded, HashAlgorithm: proto.HashAlgo_SHA256},
+			expectCode: codes.InvalidArgument,
+		},
+		"bad-unlisted-key": {
+			request:    &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ephemeral-unlisted"}, Digest: encoded},
+			expectCode: codes.InvalidArgument,
+		},
+		"bad-outside-signing-window": {
+			request:    &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ephemeral-window"}, Digest: encoded},
+			expectCode: codes.FailedPrecondition,
+		},
+		"bad-no-key-meta": {
+			request:    &proto.EphemeralSigningRequest{Digest: encoded, HashAlgorithm: proto.HashAlgo_SHA256},
+			expectCode: codes.InvalidArgument,
+		},
+		"bad-rsa-no-hash": {
+			request:    &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ephemeral-rsa"}, Digest: encoded},
+			expectCode: codes.InvalidArgument,
+		},
+		"bad-digest-length": {
+			request:    &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ephemeral-rsa"}, Digest: encoded, HashAlgorithm: proto.HashAlgo_SHA512},
+			expectCode: codes.InvalidArgument,
+		},
+		"bad-unsupported-hash": {
+			request:    &proto.EphemeralSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "ephemeral-rsa"}, Digest: base64.StdEncoding.EncodeToString(digest[:28]), HashAlgorithm: proto.HashAlgo_SHA224},
+			expectCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		resp, err := ss.PostEphemeralSignature(context.Background(), tt.request)
+		if status.Code(err) != tt.expectCode {
+			t.Fatalf("in test %v: got %v, want code %v", label, err, tt.expectCode)
+		}
+		if err != nil {
+			continue
+		}
+		block, _ := pem.Decode([]byte(resp.PublicKey))
+		if block == nil {
+			t.Fatalf("in test %v: unable to decode public key %q", label, resp.PublicKey)
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			t.Fatalf("in test %v: unable to parse public key: %v", label, err)
+		}
+		signature, err := base64.StdEncoding.DecodeString(resp.Signature)
+		if err != nil {
+			t.Fatalf("in test %v: unable to decode signature: %v", label, err)
+		}
+		valid := false
+		switch key := pub.(type) {
+		case *rsa.PublicKey:
+			valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+		case ed25519.PublicKey:
+			valid = ed25519.Verify(key, digest[:], signature)
+		}
+		if !valid {
+			t.Errorf("in test %v: signature does not match the %T public key", label, pub)
+		}
+	}
+	if signer.generated != 2 {
+		t.Errorf("got %d ephemeral keys generated, want 2", signer.generated)
+	}
+
+	request := testcases["good-rsa"].request
+	ss.Breakers = NewCircuitBreakers(1, time.Minute)
+	ss.Breakers.Record("ephemeral-rsa", errors.New("CKR_DEVICE_ERROR"))
+	if _, err := ss.PostEphemeralSignature(context.Background(), request); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v with the breaker of the key open, want Unavailable", err)
+	}
+	ss.Breakers = nil
+	ss.Endpoints = NewEndpointState(config.EphemeralEndpoint)
+	if _, err := ss.PostEphemeralSignature(context.Background(), request); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v with the endpoint disabled, want Unavailable", err)
+	}
+	ss.Endpoints = nil
+	if signer.generated != 2 {
+		t.Errorf("got %d ephemeral keys generated by the rejected requests, want none", signer.generated-2)
+	}
+
+	ss.EphemeralSigner = nil
+	if _, err := ss.PostEphemeralSignature(context.Background(), request); status.Code(err) != codes.Unimplemented {
+		t.Errorf("got %v without an ephemeral signer, want Unimplemented", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FingerprintIndex maps the public key fingerprints of the keys to their identifiers.
+// It is safe for concurrent use, so that the keys generated or reloaded at runtime can be indexed.
+type FingerprintIndex struct {
+	mu  sync.RWMutex
+	ids map[string][]string // fingerprint to key identifiers
+	fps map[string]string   // key identifier to fingerprint
+}
+
+// PublicKeyFingerprint returns the hex encoded SHA256 fingerprint of the DER encoded public key.
+func PublicKeyFingerprint(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NewFingerprintIndex returns a FingerprintIndex of the public keys mapped by their key identifiers.
+func NewFingerprintIndex(keys map[string]crypto.PublicKey) (*FingerprintIndex, error) {
+	index := &FingerprintIndex{ids: make(map[string][]string), fps: make(map[string]string)}
+	for id, pub := range keys {
+		if err := index.Set(id, pub); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// Set indexes the public key of the key with the identifier, replacing its previous one, if any.
+func (f *FingerprintIndex) Set(identifier string, pub crypto.PublicKey) error {
+	fp, err := PublicKeyFingerprint(pub)
+	if err != nil {
+		return fmt.Errorf("unable to compute fingerprint of key %q: %v", identifier, err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if old, ok := f.fps[identifier]; ok {
+		if old == fp {
+			return nil
+		}
+		var ids []string
+		for _, id := range f.ids[old] {
+			if id != identifier {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			delete(f.ids, old)
+		} else {
+			f.ids[old] = ids
+		}
+	}
+	f.fps[identifier] = fp
+	f.ids[fp] = append(f.ids[fp], identifier)
+	return nil
+}
+
+// Resolve returns the identifier of the key with the fingerprint, or an error
+// if no key or more than one key has the fingerprint.
+func (f *FingerprintIndex) Resolve(fingerprint string) (string, error) {
+	f.mu.RLock()
+	ids := f.ids[strings.ToLower(fingerprint)]
+	f.mu.RUnlock()
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no key with fingerprint %q", fingerprint)
+	case 1:
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("fingerprint %q is ambiguous", fingerprint)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that sets the identifier of the KeyMeta
+// of the requests referring to a key by fingerprint. Unknown or ambiguous fingerprints return NotFound.
+func (f *FingerprintIndex) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var keyMeta *proto.KeyMeta
+		switch r := req.(type) {
+		case *proto.KeyMeta:
+			keyMeta = r
+		case interface{ GetKeyMeta() *proto.KeyMeta }:
+			keyMeta = r.GetKeyMeta()
+		}
+		if keyMeta.GetFingerprint() == "" {
+			return handler(ctx, req)
+		}
+		id, err := f.Resolve(keyMeta.Fingerprint)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "Not found: %v", err)
+		}
+		if keyMeta.Identifier != "" && keyMeta.Identifier != id {
+			return nil, status.Errorf(codes.InvalidArgument, "Bad request: fingerprint %q does not match key %q", keyMeta.Fingerprint, keyMeta.Identifier)
+		}
+		keyMeta.Identifier = id
+		return handler(ctx, req)
+	}
+}
+
+// indexFingerprint indexes the current public key of the key with the identifier in the Fingerprints,
+// such as after the key is generated or its sessions are reloaded. A key whose public key cannot be
+// fetched keeps its previous fingerprint, if any.
+func (s *SigningService) indexFingerprint(identifier string) {
+	pkg, ok := s.CertSign.(crypki.PublicKeyGetter)
+	if s.Fingerprints == nil || !ok {
+		return
+	}
+	pub, err := pkg.PublicKey(identifier)
+	if err == nil {
+		err = s.Fingerprints.Set(identifier, pub)
+	}
+	if err != nil {
+		log.Printf("unable to index fingerprint of key %q, it cannot be referred to by fingerprint: %v", identifier, err)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFingerprintIndexUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	userKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	sharedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	index, err := NewFingerprintIndex(map[string]crypto.PublicKey{
+		"sshuserid1": &userKey.PublicKey,
+		"x509id1":    &sharedKey.PublicKey,
+		"blobid1":    &sharedKey.PublicKey,
+	})
+	if err != nil {
+		t.Fatalf("unable to build fingerprint index: %v", err)
+	}
+	userFP, err := PublicKeyFingerprint(&userKey.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+	sharedFP, err := PublicKeyFingerprint(&sharedKey.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+
+	ss := initMockSigningService(mockSigningServiceParam{KeyUsages: combineKeyUsage})
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostUserSSHCertificate"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return ss.PostUserSSHCertificate(ctx, req.(*proto.SSHCertificateSigningRequest))
+	}
+	newRequest := func(keyMeta *proto.KeyMeta) *proto.SSHCertificateSigningRequest {
+		return &proto.SSHCertificateSigningRequest{
+			KeyMeta:    keyMeta,
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   3600,
+			Principals: []string{"alice"},
+			KeyId:      testGoodKeyID,
+		}
+	}
+	byIdentifier, err := index.UnaryServerInterceptor()(context.Background(), newRequest(&proto.KeyMeta{Identifier: "sshuserid1"}), info, handler)
+	if err != nil {
+		t.Fatalf("unable to sign by identifier: %v", err)
+	}
+
+	testcases := map[string]struct {
+		keyMeta      *proto.KeyMeta
+		expectedCode codes.Code
+	}{
+		"fingerprint":                    {&proto.KeyMeta{Fingerprint: userFP}, codes.OK},
+		"uppercase-fingerprint":          {&proto.KeyMeta{Fingerprint: strings.ToUpper(userFP)}, codes.OK},
+		"fingerprint-and-identifier":     {&proto.KeyMeta{Identifier: "sshuserid1", Fingerprint: userFP}, codes.OK},
+		"fingerprint-mismatches-key":     {&proto.KeyMeta{Identifier: "sshuserid2", Fingerprint: userFP}, codes.InvalidArgument},
+		"unknown-fingerprint":            {&proto.KeyMeta{Fingerprint: strings.Repeat("0", 64)}, codes.NotFound},
+		"ambiguous-fingerprint":          {&proto.KeyMeta{Fingerprint: sharedFP}, codes.NotFound},
+		"unknown-fingerprint-and-key-id": {&proto.KeyMeta{Identifier: "sshuserid1", Fingerprint: "bad"}, codes.NotFound},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			resp, err := index.UnaryServerInterceptor()(context.Background(), newRequest(tt.keyMeta), info, handler)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(resp, byIdentifier) {
+				t.Errorf("in test %v: got %v signing by fingerprint, want %v as by identifier", label, resp, byIdentifier)
+			}
+		})
+	}
+}
+
+func TestFingerprintIndexKeyMetaRequest(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	index, err := NewFingerprintIndex(map[string]crypto.PublicKey{"sshuserid1": &key.PublicKey})
+	if err != nil {
+		t.Fatalf("unable to build fingerprint index: %v", err)
+	}
+	fp, err := PublicKeyFingerprint(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return ss.GetUserSSHCertificateSigningKey(ctx, req.(*proto.KeyMeta))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/GetUserSSHCertificateSigningKey"}
+	if _, err := index.UnaryServerInterceptor()(context.Background(), &proto.KeyMeta{Fingerprint: fp}, info, handler); err != nil {
+		t.Fatalf("unable to get signing key by fingerprint: %v", err)
+	}
+}
+
+func TestFingerprintIndexSet(t *testing.T) {
+	t.Parallel()
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	oldFP, err := PublicKeyFingerprint(&oldKey.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+	newFP, err := PublicKeyFingerprint(&newKey.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to compute fingerprint: %v", err)
+	}
+	index, err := NewFingerprintIndex(map[string]crypto.PublicKey{"x509id1": &oldKey.PublicKey, "blobid1": &oldKey.PublicKey})
+	if err != nil {
+		t.Fatalf("unable to build fingerprint index: %v", err)
+	}
+
+	// Replacing the key of one of the identifiers sharing a fingerprint makes both unambiguous.
+	if err := index.Set("blobid1", &newKey.PublicKey); err != nil {
+		t.Fatalf("unable to index key: %v", err)
+	}
+	if id, err := index.Resolve(oldFP); err != nil || id != "x509id1" {
+		t.Errorf("got %q, %v for old fingerprint, want x509id1", id, err)
+	}
+	if id, err := index.Resolve(newFP); err != nil || id != "blobid1" {
+		t.Errorf("got %q, %v for new fingerprint, want blobid1", id, err)
+	}
+
+	if err := index.Set("x509id1", &newKey.PublicKey); err != nil {
+		t.Fatalf("unable to index key: %v", err)
+	}
+	if _, err := index.Resolve(oldFP); err == nil {
+		t.Error("expected replaced fingerprint to be unknown")
+	}
+	if _, err := index.Resolve(newFP); err == nil {
+		t.Error("expected shared fingerprint to be ambiguous")
+	}
+}
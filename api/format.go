@@ -0,0 +1,133 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Keys of the request metadata carrying the output format preferences of the client, which apply
+// uniformly to all requests without a format field of their own. REST clients supply them as the
+// "Grpc-Metadata-X-Crypki-Public-Key-Format" and "Grpc-Metadata-X-Crypki-Signature-Format" headers.
+//
+// A format set in the request always takes precedence over the metadata: the metadata is only used
+// when the request field has its default value, i.e. PEM for KeyMeta.format and
+// Unspecified_SignatureScheme for BlobSigningRequest.signature_scheme. An explicit PEM request
+// therefore cannot override a metadata preference, as proto3 cannot tell it apart from an unset field.
+const (
+	// PublicKeyFormatMetadataKey selects the encoding of public keys: "PEM", "DER" or "JWK".
+	PublicKeyFormatMetadataKey = "x-crypki-public-key-format"
+	// SignatureFormatMetadataKey selects the encoding of ECDSA signatures: "DER" or "P1363".
+	// It is ignored for the other key types, whose signatures have a single encoding.
+	SignatureFormatMetadataKey = "x-crypki-signature-format"
+)
+
+// formatPreference returns the case-insensitive value of the metadata key in the incoming context,
+// or an empty string if it is not set.
+func formatPreference(ctx context.Context, key string) string {
+	if ctx == nil {
+		return ""
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) != 0 {
+		return strings.ToUpper(strings.TrimSpace(values[0]))
+	}
+	return ""
+}
+
+// publicKeyFormat returns the requested public key format, or the one preferred in the metadata of ctx
+// if the request has the default format. It returns an InvalidArgument error for an unknown preference.
+func publicKeyFormat(ctx context.Context, requested proto.PublicKeyFormat) (proto.PublicKeyFormat, error) {
+	pref := formatPreference(ctx, PublicKeyFormatMetadataKey)
+	if requested != proto.PublicKeyFormat_PEM || pref == "" {
+		return requested, nil
+	}
+	format, ok := proto.PublicKeyFormat_value[pref]
+	if !ok {
+		return requested, status.Errorf(codes.InvalidArgument, "Bad request: unknown %s %q", PublicKeyFormatMetadataKey, pref)
+	}
+	return proto.PublicKeyFormat(format), nil
+}
+
+// signatureScheme returns the requested signature scheme, or the ECDSA scheme of the signature format
+// preferred in the metadata of ctx if the request has no scheme and the key is an ECDSA key.
+// It returns an InvalidArgument error for an unknown preference.
+func signatureScheme(ctx context.Context, requested proto.SignatureScheme, ecdsaKey bool) (proto.SignatureScheme, error) {
+	pref := formatPreference(ctx, SignatureFormatMetadataKey)
+	if requested != proto.SignatureScheme_Unspecified_SignatureScheme || pref == "" {
+		return requested, nil
+	}
+	var scheme proto.SignatureScheme
+	switch pref {
+	case "DER":
+		scheme = proto.SignatureScheme_ECDSA_ASN1
+	case "P1363":
+		scheme = proto.SignatureScheme_ECDSA_P1363
+	default:
+		return requested, status.Errorf(codes.InvalidArgument, "Bad request: unknown %s %q", SignatureFormatMetadataKey, pref)
+	}
+	if !ecdsaKey {
+		return requested, nil
+	}
+	return scheme, nil
+}
+
+// encodePublicKey encodes the PEM encoded public key of the key with the identifier in the format.
+func encodePublicKey(pemKey []byte, identifier string, format proto.PublicKeyFormat) (string, error) {
+	switch format {
+	case proto.PublicKeyFormat_JWK:
+		return encodeJWK(pemKey, identifier)
+	case proto.PublicKeyFormat_DER:
+		block, _ := pem.Decode(pemKey)
+		if block == nil {
+			return "", errors.New("unable to decode PEM public key")
+		}
+		return base64.StdEncoding.EncodeToString(block.Bytes), nil
+	}
+	return string(pemKey), nil
+}
+
+// ecdsaP1363 converts the ASN.1 DER encoded ECDSA signature to its IEEE P1363 encoding for the PEM encoded
+// ECDSA public key, i.e. the concatenation of r and s each left-padded to the byte size of the curve order.
+func ecdsaP1363(signature, pemKey []byte) ([]byte, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, errors.New("unable to decode PEM public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse public key: %v", err)
+	}
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unexpected public key type %T for an ECDSA signature", pub)
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+		return nil, fmt.Errorf("unable to parse ECDSA signature: %v", err)
+	}
+	size := (ecPub.Curve.Params().N.BitLen() + 7) / 8
+	r, sb := sig.R.Bytes(), sig.S.Bytes()
+	if len(r) > size || len(sb) > size {
+		return nil, errors.New("ECDSA signature is larger than the curve order")
+	}
+	out := make([]byte, 2*size)
+	copy(out[size-len(r):size], r)
+	copy(out[2*size-len(sb):], sb)
+	return out, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetBlobSigningKeyFormatMetadata(t *testing.T) {
+	t.Parallel()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	ss := &SigningService{
+		CertSign:       &mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &ecKey.PublicKey}}},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}},
+	}
+	der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatalf("unable to marshal EC key: %v", err)
+	}
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	jwkKey, err := encodeJWK([]byte(pemKey), "blobid1")
+	if err != nil {
+		t.Fatalf("unable to encode JWK: %v", err)
+	}
+	testcases := map[string]struct {
+		format       proto.PublicKeyFormat
+		preference   string
+		expectedKey  string
+		expectedCode codes.Code
+	}{
+		"no-preference":            {expectedKey: pemKey},
+		"preference-PEM":           {preference: "PEM", expectedKey: pemKey},
+		"preference-DER":           {preference: "DER", expectedKey: base64.StdEncoding.EncodeToString(der)},
+		"preference-JWK":           {preference: "jwk", expectedKey: jwkKey},
+		"request-takes-precedence": {format: proto.PublicKeyFormat_JWK, preference: "DER", expectedKey: jwkKey},
+		"unknown-preference":       {preference: "XML", expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			if tt.preference != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(PublicKeyFormatMetadataKey, tt.preference))
+			}
+			key, err := ss.GetBlobSigningKey(ctx, &proto.KeyMeta{Identifier: "blobid1", Format: tt.format})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err == nil && key.Key != tt.expectedKey {
+				t.Errorf("in test %v: got key %q, want %q", label, key.Key, tt.expectedKey)
+			}
+		})
+	}
+}
+
+// mockECDSACertSign is a mockPEMCertSign that signs blobs with an ECDSA key in software.
+type mockECDSACertSign struct {
+	mockPEMCertSign
+	key *ecdsa.PrivateKey
+}
+
+func (m *mockECDSACertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, m.key, digest)
+}
+
+func TestPostSignBlobSignatureFormatMetadata(t *testing.T) {
+	t.Parallel()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	keys := map[string]crypto.PublicKey{"blobid1": &ecKey.PublicKey}
+	ss := &SigningService{
+		CertSign:       &mockECDSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: keys}}, ecKey},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true}},
+		Keys:           map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", KeyType: crypki.ECDSA}},
+	}
+	digest := sha256.Sum256([]byte("good"))
+	testcases := map[string]struct {
+		scheme       proto.SignatureScheme
+		preference   string
+		expectP1363  bool
+		expectedCode codes.Code
+	}{
+		"no-preference":            {},
+		"preference-DER":           {preference: "DER"},
+		"preference-P1363":         {preference: "p1363", expectP1363: true},
+		"request-P1363":            {scheme: proto.SignatureScheme_ECDSA_P1363, expectP1363: true},
+		"request-takes-precedence": {scheme: proto.SignatureScheme_ECDSA_ASN1, preference: "P1363"},
+		"unknown-preference":       {preference: "JOSE", expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			if tt.preference != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(SignatureFormatMetadataKey, tt.preference))
+			}
+			request := &proto.BlobSigningRequest{
+				KeyMeta:         &proto.KeyMeta{Identifier: "blobid1"},
+				Digest:          base64.StdEncoding.EncodeToString(digest[:]),
+				HashAlgorithm:   proto.HashAlgo_SHA256,
+				SignatureScheme: tt.scheme,
+			}
+			resp, err := ss.PostSignBlob(ctx, request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			sig, err := base64.StdEncoding.DecodeString(resp.Signature)
+			if err != nil {
+				t.Fatalf("in test %v: unable to decode signature: %v", label, err)
+			}
+			if !tt.expectP1363 {
+				if !ecdsa.VerifyASN1(&ecKey.PublicKey, digest[:], sig) {
+					t.Errorf("in test %v: signature is not a valid DER ECDSA signature", label)
+				}
+				return
+			}
+			if len(sig) != 96 {
+				t.Fatalf("in test %v: got P1363 signature of %d bytes, want 96", label, len(sig))
+			}
+			r, s := new(big.Int).SetBytes(sig[:48]), new(big.Int).SetBytes(sig[48:])
+			if !ecdsa.Verify(&ecKey.PublicKey, digest[:], r, s) {
+				t.Errorf("in test %v: signature is not a valid P1363 ECDSA signature", label)
+			}
+		})
+	}
+}
+
+func TestSignatureSchemeNonECDSAKey(t *testing.T) {
+	t.Parallel()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(SignatureFormatMetadataKey, "P1363"))
+	scheme, err := signatureScheme(ctx, proto.SignatureScheme_Unspecified_SignatureScheme, false)
+	if err != nil || scheme != proto.SignatureScheme_Unspecified_SignatureScheme {
+		t.Errorf("got scheme %v, err %v, want the preference to be ignored for non-ECDSA keys", scheme, err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// gatewayCallerKey is the metadata key of the identity of the client of a request relayed by the REST gateway.
+const gatewayCallerKey = "x-crypki-gateway-caller"
+
+// gatewayCallerContextKey is the context key of the identity of the client of a request relayed by the REST gateway.
+type gatewayCallerContextKey struct{}
+
+// GatewayMetadata returns the metadata passing the common name of the verified client certificate of the REST
+// request to the gRPC server, for runtime.WithMetadata. The metadata is empty if the client has no verified certificate.
+func GatewayMetadata(ctx context.Context, req *http.Request) metadata.MD {
+	var caller string
+	if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 && len(req.TLS.VerifiedChains[0]) > 0 {
+		caller = req.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+	return metadata.Pairs(gatewayCallerKey, caller)
+}
+
+// GatewayHeaderMatcher is runtime.DefaultHeaderMatcher, except that the REST clients cannot set the metadata of
+// GatewayMetadata themselves, for runtime.WithIncomingHeaderMatcher.
+func GatewayHeaderMatcher(key string) (string, bool) {
+	h, ok := runtime.DefaultHeaderMatcher(key)
+	if ok && strings.EqualFold(h, gatewayCallerKey) {
+		return "", false
+	}
+	return h, ok
+}
+
+// GatewayIdentity sets the caller identity of the requests relayed by the REST gateway to the identity of their
+// REST clients. The gateway relays the requests over connections authenticated by the certificate of the server
+// itself, so that the requests would otherwise all share its identity, and the metadata of GatewayMetadata is
+// only trusted on such connections.
+type GatewayIdentity struct {
+	// Certificate is the DER encoded client certificate of the gateway.
+	Certificate []byte
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor setting the caller identity of the requests
+// made with the client certificate of the gateway to the one in their metadata. Such requests without
+// exactly one identity in their metadata have no caller identity.
+func (g *GatewayIdentity) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if len(g.Certificate) == 0 || !bytes.Equal(peerCertificate(ctx), g.Certificate) {
+			return handler(ctx, req)
+		}
+		var caller string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if callers := md.Get(gatewayCallerKey); len(callers) == 1 {
+				caller = callers[0]
+			}
+		}
+		return handler(context.WithValue(ctx, gatewayCallerContextKey{}, caller), req)
+	}
+}
+
+// peerCertificate returns the DER encoded verified client certificate of the caller, or nil if none.
+func peerCertificate(ctx context.Context) []byte {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Raw
+}
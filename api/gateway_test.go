@@ -0,0 +1,82 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestGatewayIdentity(t *testing.T) {
+	t.Parallel()
+	gatewayCert := &x509.Certificate{Raw: []byte("gateway"), Subject: pkix.Name{CommonName: "crypki.example.com"}}
+	otherCert := &x509.Certificate{Raw: []byte("other"), Subject: pkix.Name{CommonName: "alice"}}
+	withCert := func(cert *x509.Certificate, callers ...string) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+		})
+		md := metadata.MD{}
+		for _, caller := range callers {
+			md.Append(gatewayCallerKey, caller)
+		}
+		return metadata.NewIncomingContext(ctx, md)
+	}
+	testcases := map[string]struct {
+		ctx            context.Context
+		expectedCaller string
+	}{
+		"gateway":                   {withCert(gatewayCert, "bob"), "bob"},
+		"gateway-without-metadata":  {withCert(gatewayCert), ""},
+		"gateway-with-two-callers":  {withCert(gatewayCert, "bob", "admin"), ""},
+		"gateway-unverified-client": {withCert(gatewayCert, ""), ""},
+		"direct-client":             {withCert(otherCert), "alice"},
+		"direct-client-spoofing":    {withCert(otherCert, "admin"), "alice"},
+	}
+	g := &GatewayIdentity{Certificate: gatewayCert.Raw}
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostUserSSHCertificate"}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			var caller string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				caller = callerIdentity(ctx)
+				return nil, nil
+			}
+			if _, err := g.UnaryServerInterceptor()(tt.ctx, nil, info, handler); err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			if caller != tt.expectedCaller {
+				t.Errorf("in test %v: got caller %q, want %q", label, caller, tt.expectedCaller)
+			}
+		})
+	}
+}
+
+func TestGatewayMetadata(t *testing.T) {
+	t.Parallel()
+	req := &http.Request{TLS: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "bob"}}}}}}
+	if got := GatewayMetadata(context.Background(), req).Get(gatewayCallerKey); len(got) != 1 || got[0] != "bob" {
+		t.Errorf("got %q, want the common name of the client", got)
+	}
+	if got := GatewayMetadata(context.Background(), &http.Request{}).Get(gatewayCallerKey); len(got) != 1 || got[0] != "" {
+		t.Errorf("got %q, want an empty identity", got)
+	}
+	if _, ok := GatewayHeaderMatcher("Grpc-Metadata-X-Crypki-Gateway-Caller"); ok {
+		t.Error("REST clients may set their own identity")
+	}
+	if h, ok := GatewayHeaderMatcher("Grpc-Metadata-Foo"); !ok || h != "Foo" {
+		t.Errorf("got %q, %v, want the other metadata passed", h, ok)
+	}
+}
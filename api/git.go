@@ -0,0 +1,110 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/sshcert"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PostSignGitObject returns the SSHSIG signature of the git commit or tag object in the "git" namespace,
+// signed by the specified SSH key. Git verifies it against the public key of the SSH key.
+func (s *SigningService) PostSignGitObject(ctx context.Context, request *proto.GitSigningRequest) (*proto.GitSignature, error) {
+	const methodName = "PostSignGitObject"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,size=%d,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), len(request.GetPayload()), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.GitEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.GitEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if len(request.Payload) == 0 {
+		statusCode = http.StatusBadRequest
+		err = errors.New("git object payload is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[config.GitEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.GitEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	key, err := s.PublicKeyCache.get(cachedSSHKey, request.KeyMeta.Identifier, s.GetSSHCertSigningKey)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(key)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		statusCode = http.StatusInternalServerError
+		err = fmt.Errorf("unsupported SSH public key type %q", pub.Type())
+		return nil, s.internalError(err)
+	}
+	signer, err := ssh.NewSignerFromSigner(&keySigner{certSign: s.prioritized(ctx, proto.Priority_NORMAL), identifier: request.KeyMeta.Identifier, public: cryptoPub.CryptoPublicKey()})
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	// RSA keys sign git objects with rsa-sha2-512, as SignSSHSIG does, if the signer supports it.
+	format := pub.Type()
+	if _, ok := signer.(ssh.AlgorithmSigner); ok && format == ssh.KeyAlgoRSA {
+		format = ssh.SigAlgoRSASHA2512
+	}
+	if err = s.checkHashFloor(request.KeyMeta.Identifier, sshSignatureHash(format)); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	sig, err := sshcert.SignSSHSIG(signer, sshcert.GitNamespace, request.Payload)
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.GitSignature{Signature: string(sig)}, nil
+}
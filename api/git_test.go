@@ -0,0 +1,76 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockSSHKeyCertSign signs with an ECDSA key returned as the SSH signing key.
+type mockSSHKeyCertSign struct {
+	mockGoodCertSign
+	key *ecdsa.PrivateKey
+}
+
+func (m *mockSSHKeyCertSign) GetSSHCertSigningKey(keyIdentifier string) ([]byte, error) {
+	pub, err := ssh.NewPublicKey(&m.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.MarshalAuthorizedKey(pub), nil
+}
+
+func (m *mockSSHKeyCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, m.key, digest)
+}
+
+func TestPostSignGitObject(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\ninitial commit\n")
+	keyUsages := map[string]map[string]bool{config.GitEndpoint: {"gitid1": true}}
+	testcases := map[string]struct {
+		request      *proto.GitSigningRequest
+		expectedCode codes.Code
+	}{
+		"good":          {&proto.GitSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "gitid1"}, Payload: payload}, codes.OK},
+		"bad-key":       {&proto.GitSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "sshuserid1"}, Payload: payload}, codes.InvalidArgument},
+		"no-key-meta":   {&proto.GitSigningRequest{Payload: payload}, codes.InvalidArgument},
+		"empty-payload": {&proto.GitSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "gitid1"}}, codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: &mockSSHKeyCertSign{key: key}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages}
+			resp, err := ss.PostSignGitObject(context.Background(), tt.request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(resp.Signature, "-----BEGIN SSH SIGNATURE-----\n") || !strings.HasSuffix(resp.Signature, "-----END SSH SIGNATURE-----\n") {
+				t.Errorf("in test %v: got signature %q, want an armored SSH signature", label, resp.Signature)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// hashStrengths ranks the hash functions of the signatures from the weakest to the strongest.
+var hashStrengths = map[crypto.Hash]int{
+	crypto.SHA1:   1,
+	crypto.SHA224: 2,
+	crypto.SHA256: 3,
+	crypto.SHA384: 4,
+	crypto.SHA512: 5,
+	// The SHA3 functions are as strong as the SHA2 functions of the same size.
+	crypto.SHA3_224: 2,
+	crypto.SHA3_256: 3,
+	crypto.SHA3_384: 4,
+	crypto.SHA3_512: 5,
+}
+
+// hashNames are the names of the hash functions in errors, as in the MinHashAlgorithm of the keys.
+var hashNames = map[crypto.Hash]string{
+	crypto.SHA1:     "SHA1",
+	crypto.SHA224:   "SHA224",
+	crypto.SHA256:   "SHA256",
+	crypto.SHA384:   "SHA384",
+	crypto.SHA512:   "SHA512",
+	crypto.SHA3_224: "SHA3-224",
+	crypto.SHA3_256: "SHA3-256",
+	crypto.SHA3_384: "SHA3-384",
+	crypto.SHA3_512: "SHA3-512",
+}
+
+// checkHashFloor returns an error if the effective hash function of a signature of the key is weaker than
+// the MinHashAlgorithm of the key. It must be called once the hash is resolved from the request and the
+// defaults, so that no combination of them can downgrade the signature.
+func (s *SigningService) checkHashFloor(identifier string, hash crypto.Hash) error {
+	min, err := s.Keys[identifier].MinHash()
+	if err != nil || min == 0 {
+		return err
+	}
+	if hashStrengths[hash] < hashStrengths[min] {
+		name, ok := hashNames[hash]
+		if !ok {
+			name = fmt.Sprintf("hash function %d", hash)
+		}
+		return fmt.Errorf("%s signatures are weaker than the minimum hash algorithm %s of key %q", name, hashNames[min], identifier)
+	}
+	return nil
+}
+
+// sshSignatureHash returns the hash function of the SSH signatures of the format, such as "ssh-rsa".
+func sshSignatureHash(format string) crypto.Hash {
+	switch format {
+	case ssh.KeyAlgoRSA:
+		return crypto.SHA1
+	case ssh.SigAlgoRSASHA2256, ssh.KeyAlgoECDSA256:
+		return crypto.SHA256
+	case ssh.KeyAlgoECDSA384:
+		return crypto.SHA384
+	case ssh.SigAlgoRSASHA2512, ssh.KeyAlgoECDSA521, ssh.KeyAlgoED25519:
+		return crypto.SHA512
+	}
+	return 0
+}
+
+// x509SignatureHash returns the hash function of the x509 signatures of the algorithm. Ed25519 signatures
+// hash the message with SHA512.
+func x509SignatureHash(algorithm x509.SignatureAlgorithm) crypto.Hash {
+	switch algorithm {
+	case x509.SHA1WithRSA, x509.ECDSAWithSHA1:
+		return crypto.SHA1
+	case x509.SHA256WithRSA, x509.SHA256WithRSAPSS, x509.ECDSAWithSHA256:
+		return crypto.SHA256
+	case x509.SHA384WithRSA, x509.SHA384WithRSAPSS, x509.ECDSAWithSHA384:
+		return crypto.SHA384
+	case x509.SHA512WithRSA, x509.SHA512WithRSAPSS, x509.ECDSAWithSHA512, x509.PureEd25519:
+		return crypto.SHA512
+	}
+	return 0
+}
+
+// checkSSHCertHashFloor returns an InvalidArgument status if the SSH certificates signed by the key are signed
+// with a hash function weaker than the MinHashAlgorithm of the key, e.g. the SHA1 of the "ssh-rsa" signatures
+// of RSA keys, or an Internal status if the public key of the key cannot be fetched.
+func (s *SigningService) checkSSHCertHashFloor(identifier string) error {
+	if s.Keys[identifier].MinHashAlgorithm == "" {
+		return nil
+	}
+	key, err := s.PublicKeyCache.get(cachedSSHKey, identifier, s.GetSSHCertSigningKey)
+	if err != nil {
+		return s.internalError(err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(key)
+	if err != nil {
+		return s.internalError(err)
+	}
+	// Certificates are signed with the signature format of the type of the key.
+	if err := s.checkHashFloor(identifier, sshSignatureHash(pub.Type())); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostHostSSHCertificateHashFloor(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testcases := map[string]struct {
+		key              crypto.Signer
+		minHashAlgorithm string
+		expectedCode     codes.Code
+	}{
+		"rsa-sha1-blocked": {
+			key:              rsaKey,
+			minHashAlgorithm: "SHA256",
+			expectedCode:     codes.InvalidArgument,
+		},
+		"rsa-sha1-without-floor": {
+			key: rsaKey,
+		},
+		"rsa-sha1-above-floor": {
+			key:              rsaKey,
+			minHashAlgorithm: "SHA1",
+		},
+		"ecdsa-sha256-allowed": {
+			key:              ecdsaKey,
+			minHashAlgorithm: "SHA256",
+		},
+		"ecdsa-sha256-blocked": {
+			key:              ecdsaKey,
+			minHashAlgorithm: "SHA384",
+			expectedCode:     codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			sshSigner, err := ssh.NewSignerFromSigner(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ss := &SigningService{
+				CertSign:       &mockSSHCACertSign{signer: sshSigner},
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"sshhostid1": {Identifier: "sshhostid1", MinHashAlgorithm: tt.minHashAlgorithm}},
+			}
+			request := &proto.SSHCertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "sshhostid1"}, PublicKey: testGoodRsaPubKey, Validity: 3600, KeyId: testGoodKeyID}
+			_, err = ss.PostHostSSHCertificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+		})
+	}
+}
+
+func TestPostSignBlobHashFloor(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		hashAlgorithm proto.HashAlgo
+		digest        string
+		expectedCode  codes.Code
+	}{
+		"sha224-blocked": {
+			hashAlgorithm: proto.HashAlgo_SHA224,
+			digest:        "CAj2TmDViXn8tnbJbsk4Jw3qQkRa7vzTpOb42w==",
+			expectedCode:  codes.InvalidArgument,
+		},
+		"sha256-allowed": {
+			hashAlgorithm: proto.HashAlgo_SHA256,
+			digest:        testSHA256Digest,
+		},
+		"unspecified-resolves-to-sha512": {
+			digest: "9/u6bgY2+JDlb7vzKD5STG+jIErimDgtYkdB0NxmODJuKCxBvl5CVNiCB3LFUYosWowMf37aGVlKfrU5RT4e1w==",
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{
+				CertSign:       &mockGoodCertSign{},
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"blobid1": {Identifier: "blobid1", MinHashAlgorithm: "SHA256"}},
+			}
+			request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: tt.digest, HashAlgorithm: tt.hashAlgorithm}
+			_, err := ss.PostSignBlob(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxHookMessageSize is the maximum size of the summary of the stderr of a denying hook returned to clients.
+const maxHookMessageSize = 200
+
+// preSignHookInput is the JSON document passed on the stdin of the pre-sign hook.
+type preSignHookInput struct {
+	// Method is the name of the RPC method, such as "PostUserSSHCertificate".
+	Method string `json:"method"`
+	// Endpoint is the endpoint of the method, such as "/sig/ssh-user-cert".
+	Endpoint string `json:"endpoint"`
+	// Identifier is the identifier of the signing key.
+	Identifier string `json:"identifier"`
+	// Caller is the common name of the client certificate, if any.
+	Caller string `json:"caller"`
+	// Request is the request message in the JSON encoding of the REST API.
+	Request json.RawMessage `json:"request"`
+}
+
+// PreSignHook runs an external program before each signing request, which lets operators enforce
+// their own policy without recompiling crypki. The request metadata is passed as a JSON document on
+// the stdin of the program, and the request is only signed if the program exits with status 0.
+type PreSignHook struct {
+	// Path is the path of the program.
+	Path string
+	// Timeout is the time after which the program is killed and the request fails.
+	Timeout time.Duration
+}
+
+// run runs the hook with the input, and returns nil if it allows the request.
+func (h *PreSignHook) run(ctx context.Context, input []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		log.Printf("pre-sign hook %s timed out after %v", h.Path, h.Timeout)
+		return status.Errorf(codes.Unavailable, "Service unavailable: pre-sign hook timed out")
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		return status.Errorf(codes.PermissionDenied, "Permission denied: pre-sign hook denied the request: %s", summarize(stderr.String()))
+	}
+	if err != nil {
+		log.Printf("unable to run pre-sign hook %s: %v", h.Path, err)
+		return status.Errorf(codes.Unavailable, "Service unavailable: unable to run pre-sign hook")
+	}
+	return nil
+}
+
+// summarize returns the first line of the message, truncated to maxHookMessageSize bytes.
+func summarize(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = strings.TrimSpace(msg[:i])
+	}
+	if len(msg) > maxHookMessageSize {
+		msg = msg[:maxHookMessageSize] + "..."
+	}
+	return msg
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor running the hook before the signing
+// requests of all endpoints. A request denied by the hook returns PermissionDenied with the first line
+// of the stderr of the hook, and a request whose hook fails to run or times out returns Unavailable.
+func (h *PreSignHook) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		endpoint := methodEndpoints[method]
+		if endpoint == "" || !strings.HasPrefix(method, "Post") {
+			return handler(ctx, req)
+		}
+		input := preSignHookInput{Method: method, Endpoint: endpoint, Caller: callerIdentity(ctx), Request: json.RawMessage("null")}
+		if r, ok := req.(interface{ GetKeyMeta() *proto.KeyMeta }); ok {
+			input.Identifier = r.GetKeyMeta().GetIdentifier()
+		}
+		if m, ok := req.(protobuf.Message); ok {
+			var buf bytes.Buffer
+			if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(&buf, m); err == nil {
+				input.Request = buf.Bytes()
+			}
+		}
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Internal server error")
+		}
+		if err := h.run(ctx, data); err != nil {
+			log.Printf(`m=%s,id=%q,caller=%q,hook="%v"`, method, input.Identifier, input.Caller, status.Convert(err).Message())
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeHook writes an executable shell script with the body into dir and returns its path.
+func writeHook(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatalf("unable to write hook: %v", err)
+	}
+	return path
+}
+
+func TestPreSignHookUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "crypki-hook")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	request := &proto.SSHCertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "sshuserkey"}, Principals: []string{"alice"}}
+	testcases := map[string]struct {
+		hook            string
+		timeout         time.Duration
+		method          string
+		expectedCode    codes.Code
+		expectedMessage string
+		expectHandler   bool
+	}{
+		"allow": {
+			hook:          "exit 0",
+			method:        "PostUserSSHCertificate",
+			expectHandler: true,
+		},
+		"allow-on-request-fields": {
+			hook:          `in=$(cat); echo "$in" | grep -q '"identifier":"sshuserkey"' && echo "$in" | grep -q '"principals":\["alice"\]'`,
+			method:        "PostUserSSHCertificate",
+			expectHandler: true,
+		},
+		"deny": {
+			hook:            "echo 'denied by policy' >&2; echo 'detail' >&2; exit 1",
+			method:          "PostUserSSHCertificate",
+			expectedCode:    codes.PermissionDenied,
+			expectedMessage: "denied by policy",
+		},
+		"timeout": {
+			hook:         "exec sleep 5",
+			timeout:      100 * time.Millisecond,
+			method:       "PostUserSSHCertificate",
+			expectedCode: codes.Unavailable,
+		},
+		"not-a-signing-method": {
+			hook:          "exit 1",
+			method:        "GetUserSSHCertificateAvailableSigningKeys",
+			expectHandler: true,
+		},
+	}
+	// The subtests run in a group so that the hooks are removed only after all of them are done.
+	t.Run("group", func(t *testing.T) {
+		for label, tt := range testcases {
+			tt := tt
+			label := label
+			t.Run(label, func(t *testing.T) {
+				t.Parallel()
+				timeout := tt.timeout
+				if timeout == 0 {
+					timeout = 5 * time.Second
+				}
+				h := &PreSignHook{Path: writeHook(t, dir, label, tt.hook), Timeout: timeout}
+				called := false
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				}
+				info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/" + tt.method}
+				_, err := h.UnaryServerInterceptor()(context.Background(), request, info, handler)
+				if got := status.Code(err); got != tt.expectedCode {
+					t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+				}
+				if called != tt.expectHandler {
+					t.Errorf("in test %v: handler called: %v, want %v", label, called, tt.expectHandler)
+				}
+				if msg := status.Convert(err).Message(); tt.expectedMessage != "" && (!strings.HasSuffix(msg, tt.expectedMessage) || strings.Contains(msg, "detail")) {
+					t.Errorf("in test %v: got message %q, want the first line %q", label, msg, tt.expectedMessage)
+				}
+			})
+		}
+	})
+}
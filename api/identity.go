@@ -0,0 +1,46 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// signingServicePrefix is the prefix of the full method names of the Signing service.
+const signingServicePrefix = "/v3.Signing/"
+
+// IdentityRequirement rejects with Unauthenticated the requests to the Signing service made on a connection
+// without a verified client certificate, which a zero-trust deployment requires even when TLSClientAuthMode
+// is VerifyClientCertIfGiven to let such clients connect, e.g. to serve the public keys to them. The requests relayed by the REST gateway
+// carry the identity of their REST clients, see GatewayIdentity.
+type IdentityRequirement struct {
+	// ExemptReadOnly specifies whether the read-only requests, i.e. the requests other than the signing
+	// requests, are served without a verified identity.
+	ExemptReadOnly bool
+	// Rejected, if set, is called with the full method name and the request of each rejected request.
+	Rejected func(method string, req interface{})
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor returning Unauthenticated for the requests
+// to the Signing service without a verified caller identity.
+func (r *IdentityRequirement) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, signingServicePrefix) || callerIdentity(ctx) != "" {
+			return handler(ctx, req)
+		}
+		method := strings.TrimPrefix(info.FullMethod, signingServicePrefix)
+		if r.ExemptReadOnly && !strings.HasPrefix(method, "Post") {
+			return handler(ctx, req)
+		}
+		if r.Rejected != nil {
+			r.Rejected(info.FullMethod, req)
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated: %s requires a verified client certificate", method)
+	}
+}
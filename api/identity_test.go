@@ -0,0 +1,88 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIdentityRequirement(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	signBlob := func(interceptor grpc.UnaryServerInterceptor, ctx context.Context) error {
+		request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA512Digest, HashAlgorithm: proto.HashAlgo_SHA512}
+		info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+		_, err := interceptor(ctx, request, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return ss.PostSignBlob(ctx, req.(*proto.BlobSigningRequest))
+		})
+		return err
+	}
+	getKey := func(interceptor grpc.UnaryServerInterceptor, ctx context.Context) error {
+		info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/GetBlobAvailableSigningKeys"}
+		_, err := interceptor(ctx, &proto.KeyFilter{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return ss.GetBlobAvailableSigningKeys(ctx, req.(*proto.KeyFilter))
+		})
+		return err
+	}
+
+	testcases := map[string]struct {
+		exemptReadOnly bool
+		ctx            context.Context
+		expectSignCode codes.Code
+		expectGetCode  codes.Code
+		expectRejected int
+	}{
+		"no-client-cert": {
+			ctx:            context.Background(),
+			expectSignCode: codes.Unauthenticated,
+			expectGetCode:  codes.Unauthenticated,
+			expectRejected: 2,
+		},
+		"no-client-cert-read-only-exempt": {
+			exemptReadOnly: true,
+			ctx:            context.Background(),
+			expectSignCode: codes.Unauthenticated,
+			expectGetCode:  codes.OK,
+			expectRejected: 1,
+		},
+		"verified-client-cert": {
+			ctx:            contextWithIdentity("client"),
+			expectSignCode: codes.OK,
+			expectGetCode:  codes.OK,
+		},
+	}
+	for label, tt := range testcases {
+		rejected := 0
+		r := &IdentityRequirement{
+			ExemptReadOnly: tt.exemptReadOnly,
+			Rejected:       func(method string, req interface{}) { rejected++ },
+		}
+		interceptor := r.UnaryServerInterceptor()
+		if err := signBlob(interceptor, tt.ctx); status.Code(err) != tt.expectSignCode {
+			t.Errorf("in test %v: got %v signing, want code %v", label, err, tt.expectSignCode)
+		}
+		if err := getKey(interceptor, tt.ctx); status.Code(err) != tt.expectGetCode {
+			t.Errorf("in test %v: got %v listing keys, want code %v", label, err, tt.expectGetCode)
+		}
+		if rejected != tt.expectRejected {
+			t.Errorf("in test %v: got %d rejected requests, want %d", label, rejected, tt.expectRejected)
+		}
+	}
+
+	// The Admin service checks the identity of its callers itself.
+	r := &IdentityRequirement{}
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Admin/GetServerInfo"}
+	if _, err := r.UnaryServerInterceptor()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}); err != nil {
+		t.Errorf("got %v for an Admin request, want it passed to the handler", err)
+	}
+}
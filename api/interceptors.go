@@ -0,0 +1,55 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// NamedInterceptor is a gRPC interceptor of the unary requests with its name in config.Interceptors.
+type NamedInterceptor struct {
+	Name        string
+	Interceptor grpc.UnaryServerInterceptor
+}
+
+// OrderInterceptors returns the interceptors, given in their default order, without the disabled ones, with
+// the ones named in order first, in that order, followed by the others in their default order.
+func OrderInterceptors(interceptors []NamedInterceptor, order, disabled []string) []grpc.UnaryServerInterceptor {
+	skip := make(map[string]bool)
+	for _, name := range disabled {
+		skip[name] = true
+	}
+	var ordered []grpc.UnaryServerInterceptor
+	for _, name := range order {
+		for _, i := range interceptors {
+			if i.Name == name && !skip[name] {
+				ordered = append(ordered, i.Interceptor)
+				skip[name] = true
+			}
+		}
+	}
+	for _, i := range interceptors {
+		if !skip[i.Name] {
+			ordered = append(ordered, i.Interceptor)
+		}
+	}
+	return ordered
+}
+
+// ChainUnaryInterceptors returns a grpc.UnaryServerInterceptor that calls the interceptors in order,
+// the first one being the outermost.
+func ChainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return chained(ctx, req)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOrderInterceptors(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	recording := func(name string) NamedInterceptor {
+		return NamedInterceptor{Name: name, Interceptor: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}}
+	}
+	interceptors := []NamedInterceptor{
+		recording(config.IdentityInterceptor),
+		recording(config.KeyAliasInterceptor),
+		recording(config.RateLimitInterceptor),
+		recording(config.PreSignHookInterceptor),
+	}
+	testcases := map[string]struct {
+		order, disabled []string
+		expectedCalls   []string
+	}{
+		"default": {
+			expectedCalls: []string{"identity", "key-alias", "rate-limit", "pre-sign-hook"},
+		},
+		"ordered": {
+			order:         []string{"pre-sign-hook", "identity"},
+			expectedCalls: []string{"pre-sign-hook", "identity", "key-alias", "rate-limit"},
+		},
+		"disabled": {
+			disabled:      []string{"rate-limit"},
+			expectedCalls: []string{"identity", "key-alias", "pre-sign-hook"},
+		},
+		"ordered-and-disabled": {
+			order:         []string{"rate-limit", "key-alias"},
+			disabled:      []string{"rate-limit"},
+			expectedCalls: []string{"key-alias", "identity", "pre-sign-hook"},
+		},
+		"not-configured": {
+			order:         []string{"sign-pacing", "rate-limit"},
+			expectedCalls: []string{"rate-limit", "identity", "key-alias", "pre-sign-hook"},
+		},
+	}
+	for label, tt := range testcases {
+		calls = nil
+		chain := ChainUnaryInterceptors(OrderInterceptors(interceptors, tt.order, tt.disabled)...)
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+			return nil, nil
+		}
+		if _, err := chain(context.Background(), nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+			t.Fatalf("in test %v: unexpected error: %v", label, err)
+		}
+		if want := append(tt.expectedCalls, "handler"); !reflect.DeepEqual(calls, want) {
+			t.Errorf("in test %v: got calls %q, want %q", label, calls, want)
+		}
+	}
+}
+
+func TestOrderInterceptorsDisabledRateLimit(t *testing.T) {
+	t.Parallel()
+	limiter := &RateLimiter{
+		Backend: &mockRateLimitBackend{taken: make(map[string]int)},
+		Limits:  map[string]RateLimit{"blobid1": {Rate: 1, Burst: 1}},
+	}
+	interceptors := []NamedInterceptor{{Name: config.RateLimitInterceptor, Interceptor: limiter.UnaryServerInterceptor()}}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &proto.Signature{}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+	request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}}
+
+	limited := ChainUnaryInterceptors(OrderInterceptors(interceptors, nil, nil)...)
+	if _, err := limited(context.Background(), request, info, handler); err != nil {
+		t.Fatalf("unable to sign the first request: %v", err)
+	}
+	if _, err := limited(context.Background(), request, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("got %v for a throttled request, want ResourceExhausted", err)
+	}
+
+	unlimited := ChainUnaryInterceptors(OrderInterceptors(interceptors, nil, []string{config.RateLimitInterceptor})...)
+	for i := 0; i < 3; i++ {
+		if _, err := unlimited(context.Background(), request, info, handler); err != nil {
+			t.Errorf("got %v with the rate limit interceptor disabled, want no error", err)
+		}
+	}
+}
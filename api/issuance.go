@@ -0,0 +1,139 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Types of the certificates in the issuance records.
+const (
+	issuanceX509    = "x509"
+	issuanceSSHUser = "ssh-user"
+	issuanceSSHHost = "ssh-host"
+)
+
+// IssuanceLog retains the metadata of the most recently issued certificates for troubleshooting.
+// It is a fixed-size ring buffer: once full, each new record replaces the oldest one.
+type IssuanceLog struct {
+	mu      sync.Mutex
+	records []*proto.IssuanceRecord
+	next    int
+	full    bool
+}
+
+// NewIssuanceLog returns an IssuanceLog retaining the last size records.
+func NewIssuanceLog(size int) *IssuanceLog {
+	if size < 0 {
+		size = 0
+	}
+	return &IssuanceLog{records: make([]*proto.IssuanceRecord, size)}
+}
+
+// Record adds the record to the log. It is a no-op for a nil or empty IssuanceLog.
+func (l *IssuanceLog) Record(record *proto.IssuanceRecord) {
+	if l == nil || len(l.records) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records[l.next] = record
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Recent returns the retained records, most recent first.
+func (l *IssuanceLog) Recent() []*proto.IssuanceRecord {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.next
+	if l.full {
+		n = len(l.records)
+	}
+	recent := make([]*proto.IssuanceRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		recent = append(recent, l.records[(l.next-i+len(l.records))%len(l.records)])
+	}
+	return recent
+}
+
+// recordX509Issuance records the issuance of the x509 certificate signed by the specified key, returned
+// with the fingerprint.
+func (s *SigningService) recordX509Issuance(identifier string, cert *x509.Certificate, fingerprint string) {
+	s.IssuanceLog.Record(x509IssuanceRecord(identifier, cert, fingerprint, time.Now()))
+}
+
+// recordSSHIssuance records the issuance of the SSH certificate of the type signed by the specified key,
+// returned with the fingerprint.
+func (s *SigningService) recordSSHIssuance(identifier, certType string, cert *ssh.Certificate, fingerprint string) {
+	s.IssuanceLog.Record(sshIssuanceRecord(identifier, certType, cert, fingerprint, time.Now()))
+}
+
+// x509IssuanceRecord returns the record of the x509 certificate signed by the specified key at issuedAt.
+func x509IssuanceRecord(identifier string, cert *x509.Certificate, fingerprint string, issuedAt time.Time) *proto.IssuanceRecord {
+	record := &proto.IssuanceRecord{
+		Type:        issuanceX509,
+		Identifier:  identifier,
+		Subject:     cert.Subject.String(),
+		IssuedAt:    issuedAt.Unix(),
+		NotBefore:   cert.NotBefore.Unix(),
+		NotAfter:    cert.NotAfter.Unix(),
+		Fingerprint: fingerprint,
+	}
+	if cert.SerialNumber != nil {
+		record.Serial = cert.SerialNumber.String()
+	}
+	return record
+}
+
+// sshIssuanceRecord returns the record of the SSH certificate of the type signed by the specified key at issuedAt.
+func sshIssuanceRecord(identifier, certType string, cert *ssh.Certificate, fingerprint string, issuedAt time.Time) *proto.IssuanceRecord {
+	return &proto.IssuanceRecord{
+		Type:        certType,
+		Identifier:  identifier,
+		Serial:      strconv.FormatUint(cert.Serial, 10),
+		KeyId:       cert.KeyId,
+		Principals:  cert.ValidPrincipals,
+		IssuedAt:    issuedAt.Unix(),
+		NotBefore:   int64(cert.ValidAfter),
+		NotAfter:    int64(cert.ValidBefore),
+		Fingerprint: fingerprint,
+	}
+}
+
+// ListRecentIssuance returns the metadata of the certificates recently issued by this server, most recent first.
+func (s *SigningService) ListRecentIssuance(ctx context.Context, e *empty.Empty) (*proto.IssuanceRecords, error) {
+	const methodName = "ListRecentIssuance"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,caller=%q,st=%d,et=%d,err="%v"`, methodName, callerIdentity(ctx), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+	return &proto.IssuanceRecords{Records: s.IssuanceLog.Recent()}, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIssuanceLog(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		size, records int
+		expected      []string
+	}{
+		"empty":    {size: 3, records: 0, expected: []string{}},
+		"not-full": {size: 3, records: 2, expected: []string{"1", "0"}},
+		"full":     {size: 3, records: 3, expected: []string{"2", "1", "0"}},
+		"wrapped":  {size: 3, records: 7, expected: []string{"6", "5", "4"}},
+		"disabled": {size: 0, records: 2, expected: []string{}},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			l := NewIssuanceLog(tt.size)
+			for i := 0; i < tt.records; i++ {
+				l.Record(&proto.IssuanceRecord{Serial: strconv.Itoa(i)})
+			}
+			got := []string{}
+			for _, r := range l.Recent() {
+				got = append(got, r.Serial)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("in test %v: got serials %q, want %q", label, got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("in test %v: got serials %q, want %q", label, got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestIssuanceLogConcurrent(t *testing.T) {
+	t.Parallel()
+	const size, issued = 5, 50
+	ss := &SigningService{
+		CertSign:        &mockGoodCertSign{},
+		KeyIDProcessor:  &crypki.KeyID{},
+		KeyUsages:       combineKeyUsage,
+		AdminIdentities: map[string]bool{"admin": true},
+		IssuanceLog:     NewIssuanceLog(size),
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < issued; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := &proto.SSHCertificateSigningRequest{
+				KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+				PublicKey:  testGoodRsaPubKey,
+				Validity:   3600,
+				Principals: []string{"user" + strconv.Itoa(i)},
+				KeyId:      testGoodKeyID,
+			}
+			if _, err := ss.PostUserSSHCertificate(context.Background(), request); err != nil {
+				t.Errorf("unable to sign certificate %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The records written last are those of sequentially issued certificates.
+	for i := 0; i < size-2; i++ {
+		request := &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: "sshhostid1"},
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   3600,
+			Principals: []string{"host" + strconv.Itoa(i)},
+			KeyId:      testGoodKeyID,
+		}
+		if _, err := ss.PostHostSSHCertificate(context.Background(), request); err != nil {
+			t.Fatalf("unable to sign host certificate %d: %v", i, err)
+		}
+	}
+
+	if _, err := ss.ListRecentIssuance(contextWithIdentity("alice"), &empty.Empty{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got err %v for a non-admin, want PermissionDenied", err)
+	}
+	resp, err := ss.ListRecentIssuance(contextWithIdentity("admin"), &empty.Empty{})
+	if err != nil {
+		t.Fatalf("unable to list recent issuance: %v", err)
+	}
+	if len(resp.Records) != size {
+		t.Fatalf("got %d records, want %d", len(resp.Records), size)
+	}
+	seen := make(map[string]bool)
+	for i, r := range resp.Records {
+		if len(r.Principals) != 1 || seen[r.Principals[0]] {
+			t.Fatalf("record %d: unexpected principals %q", i, r.Principals)
+		}
+		seen[r.Principals[0]] = true
+		if i < size-2 {
+			want := "host" + strconv.Itoa(size-3-i)
+			if r.Type != issuanceSSHHost || r.Identifier != "sshhostid1" || r.Principals[0] != want {
+				t.Errorf("record %d: got %s %s %q, want %s sshhostid1 %q", i, r.Type, r.Identifier, r.Principals, issuanceSSHHost, want)
+			}
+		} else if r.Type != issuanceSSHUser || r.Identifier != "sshuserid1" {
+			t.Errorf("record %d: got %s %s, want %s sshuserid1", i, r.Type, r.Identifier, issuanceSSHUser)
+		}
+		if r.NotAfter <= r.NotBefore || r.IssuedAt == 0 {
+			t.Errorf("record %d: unexpected timestamps %+v", i, r)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yahoo/crypki"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FileIssuanceStore is a crypki.IssuanceStore and crypki.IssuanceFinder that records each certificate in a file
+// named after its type and SHA256 fingerprint, in the directory of its key. Each certificate is indexed by hard
+// links to its file in the directories of its serial number and fingerprint, so that it is found without
+// listing the certificates of the key.
+type FileIssuanceStore struct {
+	dir string
+}
+
+// NewFileIssuanceStore returns a FileIssuanceStore that records the certificates in dir.
+func NewFileIssuanceStore(dir string) (*FileIssuanceStore, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &FileIssuanceStore{dir: dir}, nil
+}
+
+// keyDir returns the directory of the certificates of the key.
+func (f *FileIssuanceStore) keyDir(keyIdentifier string) (string, error) {
+	if keyIdentifier == "" || keyIdentifier == "." || keyIdentifier == ".." || keyIdentifier != filepath.Base(keyIdentifier) {
+		return "", fmt.Errorf("invalid key identifier %q", keyIdentifier)
+	}
+	return filepath.Join(f.dir, keyIdentifier), nil
+}
+
+// serialIndex returns the name of the index directory of the decimal serial number.
+func serialIndex(serial string) (string, error) {
+	if serial == "" || strings.Trim(serial, "0123456789") != "" {
+		return "", fmt.Errorf("invalid serial number %q", serial)
+	}
+	return "serial-" + serial, nil
+}
+
+// fingerprintIndex returns the name of the index directory of the fingerprint, which is compared
+// case-insensitively and may contain characters such as "/", hence hashed.
+func fingerprintIndex(fingerprint string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(fingerprint)))
+	return "fingerprint-" + hex.EncodeToString(sum[:])
+}
+
+// Record implements crypki.IssuanceStore.
+func (f *FileIssuanceStore) Record(keyIdentifier, certType string, cert []byte, serial, fingerprint string) error {
+	dir, err := f.keyDir(keyIdentifier)
+	if err != nil {
+		return err
+	}
+	serialDir, err := serialIndex(serial)
+	if err != nil {
+		return err
+	}
+	if fingerprint == "" {
+		return errors.New("empty fingerprint")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// Write a temporary file and rename it, so that a crash never leaves a truncated record.
+	tmp, err := ioutil.TempFile(dir, "."+certType)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(cert); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(cert)
+	name := certType + "-" + hex.EncodeToString(sum[:])
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+	// The certificate is indexed once renamed, so that the indexes never refer to a truncated record.
+	for _, index := range []string{serialDir, fingerprintIndex(fingerprint)} {
+		if err := os.MkdirAll(filepath.Join(dir, index), 0755); err != nil {
+			return err
+		}
+		if err := os.Link(filepath.Join(dir, name), filepath.Join(dir, index, name)); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find implements crypki.IssuanceFinder. The certificates are listed from the index of the fingerprint if
+// specified, else of the serial number, and only read if found in the other index as well.
+func (f *FileIssuanceStore) Find(keyIdentifier, serial, fingerprint string) ([]*crypki.IssuedCert, error) {
+	dir, err := f.keyDir(keyIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []string
+	if fingerprint != "" {
+		indexes = append(indexes, fingerprintIndex(fingerprint))
+	}
+	if serial != "" {
+		index, err := serialIndex(serial)
+		if err != nil {
+			// No certificate is recorded with an invalid serial number.
+			return nil, nil
+		}
+		indexes = append(indexes, index)
+	}
+	if len(indexes) == 0 {
+		return nil, errors.New("neither serial number nor fingerprint specified")
+	}
+	files, err := ioutil.ReadDir(filepath.Join(dir, indexes[0]))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var certs []*crypki.IssuedCert
+next:
+	for _, file := range files {
+		i := strings.LastIndex(file.Name(), "-")
+		if file.IsDir() || i < 0 {
+			continue
+		}
+		for _, index := range indexes[1:] {
+			if _, err := os.Stat(filepath.Join(dir, index, file.Name())); os.IsNotExist(err) {
+				continue next
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		cert, err := ioutil.ReadFile(filepath.Join(dir, indexes[0], file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, &crypki.IssuedCert{Type: file.Name()[:i], Cert: cert, RecordedAt: file.ModTime()})
+	}
+	return certs, nil
+}
+
+// storeIssuance records the certificate of the type signed by the specified key in the IssuanceStore if any,
+// indexed by its serial number and fingerprint.
+// It returns an Unavailable error if the certificate cannot be recorded and the key is configured with
+// RequireIssuanceStore, in which case the certificate must not be returned.
+func (s *SigningService) storeIssuance(identifier, certType string, cert []byte, serial, fingerprint string) error {
+	required := s.Keys[identifier].RequireIssuanceStore
+	if s.IssuanceStore == nil {
+		if required {
+			return status.Errorf(codes.Unavailable, "Service unavailable: no issuance store for key %q", identifier)
+		}
+		return nil
+	}
+	if err := s.IssuanceStore.Record(identifier, certType, cert, serial, fingerprint); err != nil {
+		log.Printf("unable to record %s certificate of key %q in the issuance store: %v", certType, identifier, err)
+		if required {
+			return status.Errorf(codes.Unavailable, "Service unavailable: unable to record the certificate in the issuance store")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockIssuanceStore records the types of the certificates, or fails with err.
+type mockIssuanceStore struct {
+	mu      sync.Mutex
+	err     error
+	records []string
+}
+
+func (m *mockIssuanceStore) Record(keyIdentifier, certType string, cert []byte, serial, fingerprint string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.records = append(m.records, keyIdentifier+"/"+certType)
+	return nil
+}
+
+func TestFileIssuanceStore(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "issuance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileIssuanceStore(dir)
+	if err != nil {
+		t.Fatalf("unable to open issuance store: %v", err)
+	}
+	cert := []byte("certificate")
+	if err := store.Record("key1", issuanceX509, cert, "42", "AB01"); err != nil {
+		t.Fatalf("unable to record certificate: %v", err)
+	}
+	sum := sha256.Sum256(cert)
+	got, err := ioutil.ReadFile(filepath.Join(dir, "key1", "x509-"+hex.EncodeToString(sum[:])))
+	if err != nil {
+		t.Fatalf("certificate not recorded: %v", err)
+	}
+	if !bytes.Equal(got, cert) {
+		t.Errorf("got recorded certificate %q, want %q", got, cert)
+	}
+	if files, _ := ioutil.ReadDir(filepath.Join(dir, "key1")); len(files) != 3 {
+		t.Errorf("got %d files, want the record and its indexes", len(files))
+	}
+	// Recording a certificate again is a no-op.
+	if err := store.Record("key1", issuanceX509, cert, "42", "AB01"); err != nil {
+		t.Fatalf("unable to record certificate again: %v", err)
+	}
+	other := []byte("other certificate")
+	if err := store.Record("key1", issuanceSSHUser, other, "42", "SHA256:a/b+c"); err != nil {
+		t.Fatalf("unable to record certificate: %v", err)
+	}
+	for _, serial := range []string{"", "-1", "../42"} {
+		if err := store.Record("key1", issuanceX509, cert, serial, "AB01"); err == nil {
+			t.Errorf("got no error for serial number %q", serial)
+		}
+	}
+	if err := store.Record("key1", issuanceX509, cert, "42", ""); err == nil {
+		t.Error("got no error for an empty fingerprint")
+	}
+	if err := store.Record("../key1", issuanceX509, cert, "42", "AB01"); err == nil {
+		t.Error("got no error for an invalid key identifier")
+	}
+
+	testcases := map[string]struct {
+		keyIdentifier string
+		serial        string
+		fingerprint   string
+		expected      [][]byte
+	}{
+		"serial":                   {keyIdentifier: "key1", serial: "42", expected: [][]byte{other, cert}},
+		"fingerprint":              {keyIdentifier: "key1", fingerprint: "ab01", expected: [][]byte{cert}},
+		"serial-and-fingerprint":   {keyIdentifier: "key1", serial: "42", fingerprint: "SHA256:a/b+c", expected: [][]byte{other}},
+		"unknown-serial":           {keyIdentifier: "key1", serial: "43"},
+		"unknown-fingerprint":      {keyIdentifier: "key1", fingerprint: "AB02"},
+		"fingerprint-of-another":   {keyIdentifier: "key1", serial: "43", fingerprint: "AB01"},
+		"invalid-serial":           {keyIdentifier: "key1", serial: "../42"},
+		"key-without-certificates": {keyIdentifier: "key2", serial: "42"},
+	}
+	for label, tt := range testcases {
+		recorded, err := store.Find(tt.keyIdentifier, tt.serial, tt.fingerprint)
+		if err != nil {
+			t.Errorf("in test %v: unable to look up certificates: %v", label, err)
+			continue
+		}
+		var got [][]byte
+		for _, r := range recorded {
+			if r.RecordedAt.IsZero() {
+				t.Errorf("in test %v: got certificate %+v without recording time", label, r)
+			}
+			got = append(got, r.Cert)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("in test %v: got certificates %q, want %q", label, got, tt.expected)
+		}
+	}
+	if recorded, _ := store.Find("key1", "", "AB01"); len(recorded) != 1 || recorded[0].Type != issuanceX509 {
+		t.Errorf("got recorded certificates %+v, want the x509 certificate", recorded)
+	}
+	if _, err := store.Find("key1", "", ""); err == nil {
+		t.Error("got no error looking up without serial number nor fingerprint")
+	}
+	if _, err := store.Find("../key1", "42", ""); err == nil {
+		t.Error("got no error looking up an invalid key identifier")
+	}
+	if _, err := NewFileIssuanceStore(filepath.Join(dir, "missing")); err == nil {
+		t.Error("got no error for a missing directory")
+	}
+}
+
+func TestRequireIssuanceStore(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	testcases := map[string]struct {
+		required     bool
+		noStore      bool
+		storeErr     error
+		expectedCode codes.Code
+		expectedRecs int
+	}{
+		"required-store-up":   {required: true, expectedRecs: 3},
+		"required-store-down": {required: true, storeErr: errors.New("connection refused"), expectedCode: codes.Unavailable},
+		"required-no-store":   {required: true, noStore: true, expectedCode: codes.Unavailable},
+		"optional-store-up":   {expectedRecs: 3},
+		"optional-store-down": {storeErr: errors.New("connection refused")},
+		"optional-no-store":   {noStore: true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			keys := make(map[string]config.KeyConfig)
+			for _, id := range []string{"x509id1", "sshuserid1", "sshhostid1"} {
+				keys[id] = config.KeyConfig{Identifier: id, RequireIssuanceStore: tt.required}
+			}
+			store := &mockIssuanceStore{err: tt.storeErr}
+			ss := &SigningService{CertSign: &mockRecordingCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, Keys: keys}
+			if !tt.noStore {
+				ss.IssuanceStore = store
+			}
+			sshRequest := func(id string) *proto.SSHCertificateSigningRequest {
+				return &proto.SSHCertificateSigningRequest{
+					KeyMeta:    &proto.KeyMeta{Identifier: id},
+					PublicKey:  testGoodRsaPubKey,
+					Validity:   3600,
+					Principals: []string{"alice"},
+					KeyId:      testGoodKeyID,
+				}
+			}
+			posts := map[string]func() error{
+				"x509": func() error {
+					_, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: genCSR(t, key), Validity: 3600})
+					return err
+				},
+				"ssh-user": func() error {
+					_, err := ss.PostUserSSHCertificate(context.Background(), sshRequest("sshuserid1"))
+					return err
+				},
+				"ssh-host": func() error {
+					_, err := ss.PostHostSSHCertificate(context.Background(), sshRequest("sshhostid1"))
+					return err
+				},
+			}
+			for name, post := range posts {
+				if err := post(); status.Code(err) != tt.expectedCode {
+					t.Errorf("in test %v: %s: got %v, want code %v", label, name, err, tt.expectedCode)
+				}
+			}
+			if len(store.records) != tt.expectedRecs {
+				t.Errorf("in test %v: got records %q, want %d records", label, store.records, tt.expectedRecs)
+			}
+		})
+	}
+}
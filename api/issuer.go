@@ -0,0 +1,67 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+
+	"github.com/yahoo/crypki/x509cert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LoadX509IssuerChain reads the PEM file of a cross-signed CA certificate followed by its chain, through
+// the cache if not nil.
+func LoadX509IssuerChain(cache *x509cert.CertFileCache, path string) ([]*x509.Certificate, error) {
+	return cache.Load(path)
+}
+
+// x509IssuerChain returns the chain of the CA certificate of the key selected by the issuer of an x509
+// certificate signing request, or nil if the request does not select one. It returns an InvalidArgument
+// error if the key has no such CA certificate.
+func (s *SigningService) x509IssuerChain(identifier, issuer string) ([]*x509.Certificate, error) {
+	if issuer == "" {
+		return nil, nil
+	}
+	ca, err := s.x509CACert(identifier)
+	if err != nil {
+		return nil, s.internalError(err)
+	}
+	chains := append([][]*x509.Certificate{{ca}}, s.X509CrossSignedCACerts[identifier]...)
+	var chain []*x509.Certificate
+	if i, err := strconv.ParseUint(issuer, 10, 32); err == nil {
+		if i < uint64(len(chains)) {
+			chain = chains[i]
+		}
+	} else {
+		for _, c := range chains {
+			if c[0].Issuer.String() == issuer {
+				chain = c
+				break
+			}
+		}
+	}
+	if chain == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: key %q has no CA certificate issued by %q", identifier, issuer)
+	}
+	// The certificates signed by the key chain to the cross-signed CA certificate only if it certifies the key
+	// under the name of its CA certificate.
+	if !bytes.Equal(chain[0].RawSubject, ca.RawSubject) || !bytes.Equal(chain[0].RawSubjectPublicKeyInfo, ca.RawSubjectPublicKeyInfo) {
+		return nil, s.internalError(fmt.Errorf("CA certificate issued by %q does not certify the CA of key %q", chain[0].Issuer, identifier))
+	}
+	return chain, nil
+}
+
+// encodeX509Chain returns the PEM encoded certificates of the chain.
+func encodeX509Chain(chain []*x509.Certificate) []string {
+	var certs []string
+	for _, cert := range chain {
+		certs = append(certs, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})))
+	}
+	return certs
+}
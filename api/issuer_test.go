@@ -0,0 +1,106 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestCert returns a certificate of the public key with the subject, signed by the parent (self-signed if nil).
+func newTestCert(t *testing.T, subject string, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: subject},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent = tmpl
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestPostX509CertificateIssuer(t *testing.T) {
+	t.Parallel()
+	signer := newMockCACertSign(t)
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := newTestCert(t, "cross root", &rootKey.PublicKey, nil, rootKey)
+	crossSigned := newTestCert(t, "test CA", &signer.key.PublicKey, root, rootKey)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRoot := newTestCert(t, "other root", &rootKey.PublicKey, nil, rootKey)
+	otherCA := newTestCert(t, "test CA", &otherKey.PublicKey, otherRoot, rootKey)
+	ss := &SigningService{
+		CertSign:               signer,
+		KeyIDProcessor:         &crypki.KeyID{},
+		KeyUsages:              combineKeyUsage,
+		X509CrossSignedCACerts: map[string][][]*x509.Certificate{"x509id1": {{crossSigned, root}, {otherCA, otherRoot}}},
+	}
+	testcases := map[string]struct {
+		issuer        string
+		expectedCode  codes.Code
+		expectedChain []*x509.Certificate
+	}{
+		"none":                  {issuer: ""},
+		"primary-index":         {issuer: "0", expectedChain: []*x509.Certificate{signer.ca}},
+		"primary-subject":       {issuer: "CN=test CA", expectedChain: []*x509.Certificate{signer.ca}},
+		"cross-signed-index":    {issuer: "1", expectedChain: []*x509.Certificate{crossSigned, root}},
+		"cross-signed-subject":  {issuer: "CN=cross root", expectedChain: []*x509.Certificate{crossSigned, root}},
+		"unknown-index":         {issuer: "3", expectedCode: codes.InvalidArgument},
+		"unknown-subject":       {issuer: "CN=unknown root", expectedCode: codes.InvalidArgument},
+		"not-certifying-the-ca": {issuer: "CN=other root", expectedCode: codes.Internal},
+	}
+	for label, tt := range testcases {
+		// The subtests share the signer, which records the certificates it signs.
+		t.Run(label, func(t *testing.T) {
+			resp, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      testGoodcsrRsa,
+				Validity: 3600,
+				Issuer:   tt.issuer,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if expected := encodeX509Chain(tt.expectedChain); !reflect.DeepEqual(resp.IssuerChain, expected) {
+				t.Errorf("in test %v: got issuer chain %q, want %q", label, resp.IssuerChain, expected)
+			}
+		})
+	}
+}
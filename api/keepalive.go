@@ -0,0 +1,60 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Keepalive is the keepalive policy of the client connections. crypki serves gRPC through net/http
+// alongside the REST gateway, so the policy is applied to the HTTP server and its listener rather than
+// with the grpc keepalive options, which only affect the transport of grpc.Server.Serve.
+type Keepalive struct {
+	// MaxConnectionIdle is the time after which a connection without in-flight requests is closed,
+	// with a GOAWAY for HTTP/2 connections. Client keepalive pings do not keep a connection open.
+	// Zero means no limit.
+	MaxConnectionIdle time.Duration
+	// Time is the period of the TCP keepalive probes of the connections, which detect the peers that
+	// went away without closing their connections. Zero means the default period of the Go runtime.
+	Time time.Duration
+}
+
+// ConfigureServer applies the policy to srv.
+func (k Keepalive) ConfigureServer(srv *http.Server) {
+	srv.IdleTimeout = k.MaxConnectionIdle
+}
+
+// Listener returns a net.Listener that applies the policy to the TCP connections accepted by l.
+func (k Keepalive) Listener(l net.Listener) net.Listener {
+	if k.Time <= 0 {
+		return l
+	}
+	return &keepaliveListener{Listener: l, period: k.Time}
+}
+
+type keepaliveListener struct {
+	net.Listener
+	period time.Duration
+}
+
+// Accept waits for and returns the next connection, with TCP keepalive enabled.
+func (l *keepaliveListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tc, ok := conn.(*net.TCPConn); ok {
+		if err := tc.SetKeepAlive(true); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if err := tc.SetKeepAlivePeriod(l.period); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
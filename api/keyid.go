@@ -0,0 +1,21 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// callerKeyID returns the key ID of an SSH certificate requested at now by the caller of ctx, such as
+// "alice@2019-06-01T12:00:00Z": the common name of the verified client certificate of the caller and the
+// UTC time of the request.
+func callerKeyID(ctx context.Context, now time.Time) (string, error) {
+	caller := callerIdentity(ctx)
+	if caller == "" {
+		return "", errors.New("no verified client identity to derive the key id from")
+	}
+	return caller + "@" + now.UTC().Format(time.RFC3339), nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallerKeyID(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, 6, 1, 14, 0, 0, 0, time.FixedZone("UTC+2", 2*3600))
+	kid, err := callerKeyID(contextWithIdentity("alice"), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "alice@2019-06-01T12:00:00Z"; kid != want {
+		t.Errorf("got key id %q, want %q", kid, want)
+	}
+	if _, err := callerKeyID(context.Background(), now); err == nil {
+		t.Error("expected an error without a client identity")
+	}
+}
+
+func TestDeriveKeyID(t *testing.T) {
+	t.Parallel()
+	sshSigner := &mockSSHRecordingCertSign{}
+	ss := &SigningService{
+		CertSign:       sshSigner,
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      combineKeyUsage,
+		DeriveKeyID:    map[string]bool{config.SSHUserCertEndpoint: true, config.SSHHostCertEndpoint: true},
+	}
+	request := func(identifier string) *proto.SSHCertificateSigningRequest {
+		return &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: identifier},
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   3600,
+			Principals: []string{"alice"},
+			KeyId:      "chosen-by-client",
+		}
+	}
+	signers := map[string]func(context.Context) error{
+		"user": func(ctx context.Context) error {
+			_, err := ss.PostUserSSHCertificate(ctx, request("sshuserid1"))
+			return err
+		},
+		"host": func(ctx context.Context) error {
+			_, err := ss.PostHostSSHCertificate(ctx, request("sshhostid1"))
+			return err
+		},
+	}
+	for label, sign := range signers {
+		before := time.Now().UTC().Truncate(time.Second)
+		if err := sign(contextWithIdentity("alice")); err != nil {
+			t.Fatalf("%s: unexpected error: %v", label, err)
+		}
+		after := time.Now().UTC()
+		kid := sshSigner.cert.KeyId
+		if !strings.HasPrefix(kid, "alice@") {
+			t.Fatalf("%s: got key id %q, want it derived from the caller", label, kid)
+		}
+		issued, err := time.Parse(time.RFC3339, strings.TrimPrefix(kid, "alice@"))
+		if err != nil {
+			t.Fatalf("%s: unable to parse the time of key id %q: %v", label, kid, err)
+		}
+		if issued.Before(before) || issued.After(after) {
+			t.Errorf("%s: got key id time %v, want between %v and %v", label, issued, before, after)
+		}
+		if err := sign(context.Background()); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s: got %v without a client identity, want PermissionDenied", label, err)
+		}
+	}
+
+	ss.DeriveKeyID = nil
+	if err := signers["user"](contextWithIdentity("alice")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kid := sshSigner.cert.KeyId; kid != "chosen-by-client" {
+		t.Errorf("got key id %q with the option off, want the requested one", kid)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDMetadataKey is the key of the request metadata carrying the id of a request, such as
+// a trace id. REST clients supply it as the "Grpc-Metadata-X-Request-Id" header.
+const RequestIDMetadataKey = "x-request-id"
+
+// LogSampler logs the full request and response of a sample of the requests, in addition to the
+// summary line logged by each method for every request.
+type LogSampler struct {
+	// Rate is the fraction of the requests, between 0 and 1, that are logged in full.
+	Rate float64
+}
+
+// sampled returns true if the request with the id is logged in full. The decision only depends
+// on the id, so that all the log lines of a traced request are either sampled or not.
+func (l *LogSampler) sampled(id string) bool {
+	if l.Rate <= 0 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(id))
+	// The first 8 bytes of the hash are uniformly distributed in [0, 2^64).
+	return float64(binary.BigEndian.Uint64(sum[:8])) < l.Rate*(1<<64)
+}
+
+// requestID returns the id of the request supplied in the RequestIDMetadataKey metadata,
+// or a random id if there is none.
+func requestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(RequestIDMetadataKey); len(values) != 0 && values[0] != "" {
+		return values[0]
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor logging all the fields of the request
+// and response of the sampled requests, along with the request id.
+func (l *LogSampler) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := requestID(ctx)
+		if !l.sampled(id) {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		log.Printf(`m=%s,rid=%q,req=%q,resp=%q,code=%s,et=%d`, info.FullMethod, id, fmt.Sprint(redacted(req)), fmt.Sprint(resp), status.Code(err), timeElapsedSince(start))
+		return resp, err
+	}
+}
+
+// redacted returns the request with its secrets, such as the password of a PKCS#12 export, redacted.
+func redacted(req interface{}) interface{} {
+	if r, ok := req.(*proto.X509CACertificatePKCS12Request); ok && r.Password != "" {
+		c := *r
+		c.Password = "REDACTED"
+		return &c
+	}
+	return req
+}
@@ -0,0 +1,114 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLogSamplerRate(t *testing.T) {
+	t.Parallel()
+	const requests = 100000
+	testcases := map[string]struct {
+		rate float64
+	}{
+		"none":         {rate: 0},
+		"one-percent":  {rate: 0.01},
+		"ten-percent":  {rate: 0.1},
+		"half":         {rate: 0.5},
+		"all-requests": {rate: 1},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			l := &LogSampler{Rate: tt.rate}
+			sampled := 0
+			for i := 0; i < requests; i++ {
+				if l.sampled("request-" + strconv.Itoa(i)) {
+					sampled++
+				}
+			}
+			// Allow 10% of relative error, plus a few requests for tiny rates.
+			want := tt.rate * requests
+			if diff := float64(sampled) - want; diff > want*0.1+10 || diff < -want*0.1-10 {
+				t.Errorf("in test %v: sampled %d of %d requests, want about %v", label, sampled, requests, want)
+			}
+		})
+	}
+}
+
+func TestLogSamplerDeterministic(t *testing.T) {
+	t.Parallel()
+	l := &LogSampler{Rate: 0.5}
+	for i := 0; i < 1000; i++ {
+		id := "trace-" + strconv.Itoa(i)
+		if first := l.sampled(id); l.sampled(id) != first {
+			t.Fatalf("sampling of request %q is not deterministic", id)
+		}
+	}
+}
+
+func TestLogSamplerUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &proto.Signature{Signature: "c2ln"}, nil
+	}
+	request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: "ZGlnZXN0"}
+	testcases := map[string]struct {
+		rate      float64
+		expectLog bool
+	}{
+		"sampled":     {rate: 1, expectLog: true},
+		"not-sampled": {rate: 0, expectLog: false},
+	}
+	for label, tt := range testcases {
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		l := &LogSampler{Rate: tt.rate}
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadataKey, "trace-1"))
+		_, err := l.UnaryServerInterceptor()(ctx, request, info, handler)
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			t.Fatalf("in test %v: unexpected error: %v", label, err)
+		}
+		line := buf.String()
+		logged := strings.Contains(line, `rid="trace-1"`) && strings.Contains(line, "ZGlnZXN0") && strings.Contains(line, "c2ln")
+		if logged != tt.expectLog || (!tt.expectLog && line != "") {
+			t.Errorf("in test %v: got log %q, expect full log: %v", label, line, tt.expectLog)
+		}
+	}
+}
+
+func TestLogSamplerRedactsPassword(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/ExportX509CACertificatePKCS12"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &proto.PKCS12{}, nil
+	}
+	request := &proto.X509CACertificatePKCS12Request{KeyMeta: &proto.KeyMeta{Identifier: "x509id"}, Password: "s3cr3t"}
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	_, err := (&LogSampler{Rate: 1}).UnaryServerInterceptor()(context.Background(), request, info, handler)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if line := buf.String(); strings.Contains(line, "s3cr3t") || !strings.Contains(line, "x509id") {
+		t.Errorf("got log %q, want the request logged without the password", line)
+	}
+	if request.Password != "s3cr3t" {
+		t.Error("password of the request modified")
+	}
+}
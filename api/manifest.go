@@ -0,0 +1,194 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/x509cert"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// issuanceManifest is the payload of the IssuanceReceipt of the certificates of a session.
+type issuanceManifest struct {
+	Session string `json:"session"`
+	// Certificates are the receipts of the certificates, in the order of the request, each issued at
+	// the time of the issuance of its certificate.
+	Certificates []*issuanceReceipt `json:"certificates"`
+	IssuedAt     int64              `json:"issued_at"`
+}
+
+// PostIssuanceManifest returns the receipt of the manifest of the certificates of a session, which must
+// be recorded in the IssuanceStore. Each certificate is looked up by its serial number or fingerprint.
+// A replica only serves the manifests of the certificates issued by the others if they share the store,
+// such as an IssuanceStoreDir on a shared filesystem.
+func (s *SigningService) PostIssuanceManifest(ctx context.Context, request *proto.IssuanceManifestRequest) (*proto.IssuanceReceipt, error) {
+	const methodName = "PostIssuanceManifest"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,session=%q,certs=%d,st=%d,et=%d,err="%v"`, methodName, request.GetSession(), len(request.GetEntries()),
+			statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.IssuanceManifestEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if s.ReceiptKey == "" {
+		statusCode = http.StatusServiceUnavailable
+		err = errors.New("no receipt key")
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	finder, ok := s.IssuanceStore.(crypki.IssuanceFinder)
+	if !ok {
+		statusCode = http.StatusServiceUnavailable
+		err = errors.New("no issuance store to look up the certificates in")
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	if len(request.GetEntries()) == 0 {
+		statusCode = http.StatusBadRequest
+		err = errors.New("request.entries is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	manifest := &issuanceManifest{Session: request.Session}
+	// The certificates of the records are identified by their key, type, serial number and fingerprint.
+	type certID struct{ identifier, certType, serial, fingerprint string }
+	listed := make(map[certID]bool)
+	for i, entry := range request.Entries {
+		if entry.GetIdentifier() == "" || (entry.GetSerial() == "" && entry.GetFingerprint() == "") {
+			statusCode = http.StatusBadRequest
+			err = fmt.Errorf("entry %d has no identifier, or neither serial nor fingerprint", i)
+			return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		}
+		var records []*proto.IssuanceRecord
+		if records, err = recordedIssuances(finder, entry); err != nil {
+			statusCode = http.StatusInternalServerError
+			return nil, s.internalError(err)
+		}
+		matches := matchIssuanceRecords(records, entry)
+		var id certID
+		if len(matches) == 1 {
+			id = certID{matches[0].Identifier, matches[0].Type, matches[0].Serial, strings.ToLower(matches[0].Fingerprint)}
+		}
+		switch {
+		case len(matches) == 0:
+			statusCode = http.StatusNotFound
+			err = fmt.Errorf("entry %d is not a certificate recorded for key %q", i, entry.Identifier)
+			return nil, status.Errorf(codes.NotFound, "Not found: %v", err)
+		case len(matches) > 1:
+			statusCode = http.StatusBadRequest
+			err = fmt.Errorf("entry %d matches %d certificates issued by key %q", i, len(matches), entry.Identifier)
+			return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		case listed[id]:
+			statusCode = http.StatusBadRequest
+			err = fmt.Errorf("entry %d lists a certificate twice", i)
+			return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		}
+		listed[id] = true
+		manifest.Certificates = append(manifest.Certificates, recordReceipt(matches[0]))
+	}
+
+	manifest.IssuedAt = time.Now().Unix()
+	receipt, err := s.signReceiptPayload(manifest)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return receipt, nil
+}
+
+// recordedIssuances returns the records of the certificates of the entry recorded in the store, issued at
+// the time they were recorded. The certificates that cannot be parsed are skipped.
+func recordedIssuances(finder crypki.IssuanceFinder, entry *proto.IssuanceManifestEntry) ([]*proto.IssuanceRecord, error) {
+	identifier := entry.Identifier
+	certs, err := finder.Find(identifier, entry.Serial, entry.Fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up the certificates of key %q: %v", identifier, err)
+	}
+	var records []*proto.IssuanceRecord
+	for _, c := range certs {
+		switch c.Type {
+		case issuanceX509:
+			block, _ := pem.Decode(c.Cert)
+			if block == nil {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				continue
+			}
+			fingerprint, err := x509cert.Fingerprint(c.Cert)
+			if err != nil {
+				continue
+			}
+			records = append(records, x509IssuanceRecord(identifier, cert, fingerprint, c.RecordedAt))
+		case issuanceSSHUser, issuanceSSHHost:
+			pub, _, _, _, err := ssh.ParseAuthorizedKey(c.Cert)
+			if err != nil {
+				continue
+			}
+			cert, ok := pub.(*ssh.Certificate)
+			if !ok {
+				continue
+			}
+			records = append(records, sshIssuanceRecord(identifier, c.Type, cert, ssh.FingerprintSHA256(cert.Key), c.RecordedAt))
+		}
+	}
+	return records, nil
+}
+
+// matchIssuanceRecords returns the records of the certificates issued by the key of the entry with its serial
+// number and fingerprint, if specified.
+func matchIssuanceRecords(records []*proto.IssuanceRecord, entry *proto.IssuanceManifestEntry) []*proto.IssuanceRecord {
+	var matches []*proto.IssuanceRecord
+	for _, r := range records {
+		if r.Identifier != entry.Identifier {
+			continue
+		}
+		if entry.Serial != "" && r.Serial != entry.Serial {
+			continue
+		}
+		if entry.Fingerprint != "" && !strings.EqualFold(r.Fingerprint, entry.Fingerprint) {
+			continue
+		}
+		matches = append(matches, r)
+	}
+	return matches
+}
+
+// recordReceipt returns the receipt of the certificate of the record, issued at the time of its issuance.
+func recordReceipt(r *proto.IssuanceRecord) *issuanceReceipt {
+	receipt := &issuanceReceipt{
+		Issuer:      r.Identifier,
+		Type:        r.Type,
+		Serial:      r.Serial,
+		Subject:     r.Subject,
+		Principals:  r.Principals,
+		Fingerprint: r.Fingerprint,
+		IssuedAt:    r.IssuedAt,
+	}
+	if r.Type != issuanceX509 {
+		receipt.Subject = r.KeyId
+	}
+	return receipt
+}
@@ -0,0 +1,227 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockManifestCertSign also signs SSH certificates with an SSH key, so that they can be parsed once recorded.
+type mockManifestCertSign struct {
+	*mockReceiptCertSign
+	sshSigner ssh.Signer
+}
+
+func (m *mockManifestCertSign) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byte, error) {
+	if err := cert.SignCert(rand.Reader, m.sshSigner); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(ssh.MarshalAuthorizedKey(cert)), nil
+}
+
+func TestPostIssuanceManifest(t *testing.T) {
+	t.Parallel()
+	receiptKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	sshSigner, err := ssh.NewSignerFromKey(caKey)
+	if err != nil {
+		t.Fatalf("unable to create SSH signer: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileIssuanceStore(dir)
+	if err != nil {
+		t.Fatalf("unable to open issuance store: %v", err)
+	}
+	certSign := &mockManifestCertSign{&mockReceiptCertSign{newMockCACertSign(t), receiptKey}, sshSigner}
+	keys := map[string]config.KeyConfig{"receiptid": {Identifier: "receiptid", KeyType: crypki.ECDSA}}
+	ss := &SigningService{
+		CertSign:       certSign,
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      combineKeyUsage,
+		Keys:           keys,
+		ReceiptKey:     "receiptid",
+		IssuanceStore:  store,
+	}
+	// The manifests are served by another replica sharing the store.
+	replica := &SigningService{
+		CertSign:      certSign,
+		KeyUsages:     combineKeyUsage,
+		Keys:          keys,
+		ReceiptKey:    "receiptid",
+		IssuanceStore: store,
+	}
+
+	var certs []*x509.Certificate
+	var fingerprints []string
+	for i := 0; i < 2; i++ {
+		subjectKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("unable to generate EC key: %v", err)
+		}
+		resp, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+			KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+			Csr:      genCSR(t, subjectKey),
+			Validity: 3600,
+		})
+		if err != nil {
+			t.Fatalf("unable to sign x509 certificate: %v", err)
+		}
+		block, _ := pem.Decode([]byte(resp.Cert))
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("unable to parse x509 certificate: %v", err)
+		}
+		certs = append(certs, cert)
+		fingerprints = append(fingerprints, resp.Fingerprint)
+	}
+	sshRequest := &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+		PublicKey:  testGoodRsaPubKey,
+		Validity:   3600,
+		Principals: []string{"alice"},
+		KeyId:      testGoodKeyID,
+	}
+	sshResp, err := ss.PostUserSSHCertificate(context.Background(), sshRequest)
+	if err != nil {
+		t.Fatalf("unable to sign SSH certificate: %v", err)
+	}
+
+	receipt, err := replica.PostIssuanceManifest(context.Background(), &proto.IssuanceManifestRequest{
+		Session: "batch-42",
+		Entries: []*proto.IssuanceManifestEntry{
+			{Identifier: "x509id1", Serial: certs[1].SerialNumber.String()},
+			{Identifier: "x509id1", Fingerprint: strings.ToUpper(fingerprints[0])},
+			{Identifier: "sshuserid1", Serial: "0", Fingerprint: sshResp.Fingerprint},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to get manifest: %v", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(receipt.Signature)
+	if err != nil {
+		t.Fatalf("unable to decode manifest signature: %v", err)
+	}
+	digest := sha256.Sum256(receipt.Payload)
+	if !ecdsa.VerifyASN1(&receiptKey.PublicKey, digest[:], sig) || receipt.KeyMeta.GetIdentifier() != "receiptid" {
+		t.Fatalf("invalid manifest signature of key %q", receipt.KeyMeta.GetIdentifier())
+	}
+	var manifest issuanceManifest
+	if err := json.Unmarshal(receipt.Payload, &manifest); err != nil {
+		t.Fatalf("unable to decode manifest: %v", err)
+	}
+	if manifest.Session != "batch-42" || manifest.IssuedAt == 0 || len(manifest.Certificates) != 3 {
+		t.Fatalf("got manifest %+v, want 3 certificates of session batch-42", manifest)
+	}
+	var got []issuanceReceipt
+	for _, c := range manifest.Certificates {
+		if c.IssuedAt == 0 {
+			t.Errorf("got certificate %+v without issuance time", c)
+		}
+		c.IssuedAt = 0
+		got = append(got, *c)
+	}
+	expected := []issuanceReceipt{
+		{Issuer: "x509id1", Type: issuanceX509, Serial: certs[1].SerialNumber.String(), Subject: "CN=foo.bar.com", Fingerprint: fingerprints[1]},
+		{Issuer: "x509id1", Type: issuanceX509, Serial: certs[0].SerialNumber.String(), Subject: "CN=foo.bar.com", Fingerprint: fingerprints[0]},
+		{Issuer: "sshuserid1", Type: issuanceSSHUser, Serial: "0", Subject: testGoodKeyID, Principals: []string{"alice"}, Fingerprint: sshResp.Fingerprint},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got manifest certificates %+v, want %+v", got, expected)
+	}
+
+	// A second SSH certificate of the same subject key has the same serial and fingerprint.
+	if _, err := ss.PostUserSSHCertificate(context.Background(), sshRequest); err != nil {
+		t.Fatalf("unable to sign SSH certificate: %v", err)
+	}
+	testcases := map[string]struct {
+		entries      []*proto.IssuanceManifestEntry
+		expectedCode codes.Code
+	}{
+		"no-entries": {
+			expectedCode: codes.InvalidArgument,
+		},
+		"no-serial-nor-fingerprint": {
+			entries:      []*proto.IssuanceManifestEntry{{Identifier: "x509id1"}},
+			expectedCode: codes.InvalidArgument,
+		},
+		"unknown-serial": {
+			entries:      []*proto.IssuanceManifestEntry{{Identifier: "x509id1", Serial: "42"}},
+			expectedCode: codes.NotFound,
+		},
+		"wrong-key": {
+			entries:      []*proto.IssuanceManifestEntry{{Identifier: "x509id2", Serial: certs[0].SerialNumber.String()}},
+			expectedCode: codes.NotFound,
+		},
+		"wrong-fingerprint": {
+			entries:      []*proto.IssuanceManifestEntry{{Identifier: "x509id1", Serial: certs[0].SerialNumber.String(), Fingerprint: fingerprints[1]}},
+			expectedCode: codes.NotFound,
+		},
+		"listed-twice": {
+			entries: []*proto.IssuanceManifestEntry{
+				{Identifier: "x509id1", Serial: certs[0].SerialNumber.String()},
+				{Identifier: "x509id1", Fingerprint: fingerprints[0]},
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		"ambiguous": {
+			entries:      []*proto.IssuanceManifestEntry{{Identifier: "sshuserid1", Serial: "0"}},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		_, err := replica.PostIssuanceManifest(context.Background(), &proto.IssuanceManifestRequest{Session: "batch-42", Entries: tt.entries})
+		if got := status.Code(err); got != tt.expectedCode {
+			t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+		}
+	}
+
+	request := &proto.IssuanceManifestRequest{
+		Entries: []*proto.IssuanceManifestEntry{{Identifier: "x509id1", Serial: certs[0].SerialNumber.String()}},
+	}
+	replica.Endpoints = NewEndpointState(config.IssuanceManifestEndpoint)
+	if _, err := replica.PostIssuanceManifest(context.Background(), request); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v with the endpoint disabled, want Unavailable", err)
+	}
+	replica.Endpoints = nil
+	replica.IssuanceStore = nil
+	if _, err := replica.PostIssuanceManifest(context.Background(), request); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v without an issuance store, want Unavailable", err)
+	}
+	replica.IssuanceStore = store
+	replica.ReceiptKey = ""
+	if _, err := replica.PostIssuanceManifest(context.Background(), request); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v without a receipt key, want Unavailable", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errBackdated is returned by NotBeforeWatermarks.Advance if the notBefore is earlier than the watermark.
+type errBackdated struct {
+	notBefore, watermark time.Time
+}
+
+func (e *errBackdated) Error() string {
+	return fmt.Sprintf("notBefore %s is earlier than the notBefore %s of a previously issued certificate",
+		e.notBefore.UTC().Format(time.RFC3339), e.watermark.UTC().Format(time.RFC3339))
+}
+
+// NotBeforeWatermarks tracks the latest notBefore of the certificates issued by each key, so that
+// the keys configured with MonotonicNotBefore never issue a certificate with an earlier notBefore,
+// e.g. after the system clock was set back. The watermark of a key is persisted in a file named
+// after the key in dir, which is rewritten atomically whenever the watermark advances.
+//
+// The watermarks are only synchronized within a process: replicas must not share the directory.
+type NotBeforeWatermarks struct {
+	mu         sync.Mutex
+	dir        string
+	watermarks map[string]time.Time
+}
+
+// NewNotBeforeWatermarks returns a NotBeforeWatermarks persisting the watermarks in dir,
+// and loads the watermarks previously persisted there.
+func NewNotBeforeWatermarks(dir string) (*NotBeforeWatermarks, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	w := &NotBeforeWatermarks{dir: dir, watermarks: make(map[string]time.Time)}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		sec, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notBefore watermark of key %q: %v", f.Name(), err)
+		}
+		w.watermarks[f.Name()] = time.Unix(sec, 0)
+	}
+	return w, nil
+}
+
+// Advance checks that the notBefore of a certificate to be issued by the specified key is not earlier
+// than its watermark, and advances the watermark to it. It returns an *errBackdated if notBefore is
+// earlier than the watermark, or an error if the watermark cannot be persisted.
+func (w *NotBeforeWatermarks) Advance(keyIdentifier string, notBefore time.Time) error {
+	if keyIdentifier == "" || keyIdentifier == "." || keyIdentifier == ".." || keyIdentifier != filepath.Base(keyIdentifier) || strings.HasPrefix(keyIdentifier, ".") {
+		return fmt.Errorf("invalid key identifier %q", keyIdentifier)
+	}
+	notBefore = notBefore.Truncate(time.Second)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watermark, ok := w.watermarks[keyIdentifier]
+	if ok && notBefore.Before(watermark) {
+		return &errBackdated{notBefore: notBefore, watermark: watermark}
+	}
+	if ok && notBefore.Equal(watermark) {
+		return nil
+	}
+	// Write a temporary file and rename it, so that a crash never leaves a truncated watermark.
+	tmp, err := ioutil.TempFile(w.dir, "."+keyIdentifier)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strconv.FormatInt(notBefore.Unix(), 10)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(w.dir, keyIdentifier)); err != nil {
+		return err
+	}
+	w.watermarks[keyIdentifier] = notBefore
+	return nil
+}
+
+// Check returns an *errBackdated if the notBefore of a certificate to be issued by the specified key
+// is earlier than its watermark, without advancing the watermark.
+func (w *NotBeforeWatermarks) Check(keyIdentifier string, notBefore time.Time) error {
+	notBefore = notBefore.Truncate(time.Second)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if watermark, ok := w.watermarks[keyIdentifier]; ok && notBefore.Before(watermark) {
+		return &errBackdated{notBefore: notBefore, watermark: watermark}
+	}
+	return nil
+}
+
+// checkBackdated returns an InvalidArgument error if the specified key is configured with MonotonicNotBefore
+// and notBefore is earlier than its watermark, without advancing the watermark, as for previews.
+func (s *SigningService) checkBackdated(keyIdentifier string, notBefore time.Time) error {
+	if !s.Keys[keyIdentifier].MonotonicNotBefore || s.NotBeforeWatermarks == nil {
+		return nil
+	}
+	if err := s.NotBeforeWatermarks.Check(keyIdentifier, notBefore); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	return nil
+}
+
+// checkNotBefore advances the notBefore watermark of the specified key if it is configured with
+// MonotonicNotBefore. It returns an InvalidArgument error for a backdated certificate and an
+// Unavailable error if the watermark cannot be persisted.
+func (s *SigningService) checkNotBefore(keyIdentifier string, notBefore time.Time) error {
+	if !s.Keys[keyIdentifier].MonotonicNotBefore {
+		return nil
+	}
+	if s.NotBeforeWatermarks == nil {
+		return status.Errorf(codes.Unavailable, "Service unavailable: no notBefore watermark store for key %q", keyIdentifier)
+	}
+	err := s.NotBeforeWatermarks.Advance(keyIdentifier, notBefore)
+	if _, ok := err.(*errBackdated); ok {
+		return status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err != nil {
+		log.Printf("unable to advance notBefore watermark of key %q: %v", keyIdentifier, err)
+		return status.Errorf(codes.Unavailable, "Service unavailable: unable to persist notBefore watermark")
+	}
+	return nil
+}
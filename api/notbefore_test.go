@@ -0,0 +1,111 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNotBeforeWatermarks(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "notbefore")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	w, err := NewNotBeforeWatermarks(dir)
+	if err != nil {
+		t.Fatalf("unable to create watermarks: %v", err)
+	}
+	now := time.Now()
+	if err := w.Advance("key1", now); err != nil {
+		t.Fatalf("unable to advance watermark: %v", err)
+	}
+	if err := w.Advance("key1", now); err != nil {
+		t.Errorf("got err %v for the same notBefore, want nil", err)
+	}
+	if err := w.Advance("key2", now.Add(-time.Hour)); err != nil {
+		t.Errorf("got err %v for the first notBefore of another key, want nil", err)
+	}
+	if err := w.Advance("key1", now.Add(-time.Second)); err == nil {
+		t.Error("got nil err for an earlier notBefore, want error")
+	}
+	if err := w.Check("key1", now.Add(-time.Second)); err == nil {
+		t.Error("got nil err when checking an earlier notBefore, want error")
+	}
+	// Checking a later notBefore does not advance the watermark.
+	if err := w.Check("key1", now.Add(time.Hour)); err != nil {
+		t.Errorf("got err %v when checking a later notBefore, want nil", err)
+	}
+	if err := w.Advance("key1", now); err != nil {
+		t.Errorf("got err %v for the same notBefore after a check, want nil", err)
+	}
+	if err := w.Advance("../key1", now); err == nil {
+		t.Error("got nil err for an invalid key identifier, want error")
+	}
+
+	// The watermarks survive a restart.
+	w, err = NewNotBeforeWatermarks(dir)
+	if err != nil {
+		t.Fatalf("unable to reload watermarks: %v", err)
+	}
+	if err := w.Advance("key1", now.Add(-time.Second)); err == nil {
+		t.Error("got nil err for an earlier notBefore after reload, want error")
+	}
+	if err := w.Advance("key1", now.Add(time.Second)); err != nil {
+		t.Errorf("got err %v for a later notBefore after reload, want nil", err)
+	}
+}
+
+func TestPostUserSSHCertificateMonotonicNotBefore(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "notbefore")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	w, err := NewNotBeforeWatermarks(dir)
+	if err != nil {
+		t.Fatalf("unable to create watermarks: %v", err)
+	}
+	ss := initMockSigningService(mockSigningServiceParam{KeyUsages: sshkeyUsage, MaxValidity: map[string]uint64{config.SSHUserCertEndpoint: 0}})
+	ss.Keys = map[string]config.KeyConfig{"sshuserid": {Identifier: "sshuserid", MonotonicNotBefore: true}}
+	ss.NotBeforeWatermarks = w
+	request := func() *proto.SSHCertificateSigningRequest {
+		return &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid"},
+			Principals: []string{"alice"},
+			PublicKey:  testGoodEd25519PubKey,
+			Validity:   3600,
+			KeyId:      testGoodKeyID,
+		}
+	}
+
+	if _, err := ss.PostUserSSHCertificate(context.Background(), request()); err != nil {
+		t.Fatalf("unable to sign first certificate: %v", err)
+	}
+	// A certificate issued with a clock ahead of the current one, e.g. before the clock was set back.
+	if err := w.Advance("sshuserid", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("unable to advance watermark: %v", err)
+	}
+	_, err = ss.PostUserSSHCertificate(context.Background(), request())
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("got code %v for a backdated certificate, want %v, err: %v", got, codes.InvalidArgument, err)
+	}
+
+	ss.NotBeforeWatermarks = nil
+	_, err = ss.PostUserSSHCertificate(context.Background(), request())
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("got code %v without a watermark store, want %v, err: %v", got, codes.Unavailable, err)
+	}
+}
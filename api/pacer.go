@@ -0,0 +1,91 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SignPacer paces the signing requests of the keys of each slot of the HSMs to a steady rate, as a leaky bucket
+// that queues the bursts instead of rejecting them: each request is given the next turn of its slot, one
+// interval of the rate after the previous turn, and waits for it. A request whose turn comes after its deadline
+// fails right away with DeadlineExceeded and gives up its turn, so that it does not delay the requests behind it.
+type SignPacer struct {
+	// Slots maps the key identifiers to the names of their slots. The keys of a slot share its pace.
+	Slots map[string]string
+	// Rates maps the names of the slots to their rates in signing requests per second.
+	// The keys of the slots without a rate are not paced.
+	Rates map[string]float64
+	// Rejected, if set, is called with the full method name and the request of each rejected request.
+	Rejected func(method string, req interface{})
+
+	mu sync.Mutex
+	// next maps the names of the slots to the time of their next free turn.
+	next map[string]time.Time
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor delaying the signing requests of the paced
+// slots until their turn.
+func (p *SignPacer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if methodEndpoints[method] == "" || !strings.HasPrefix(method, "Post") {
+			return handler(ctx, req)
+		}
+		var identifier string
+		if km, ok := req.(interface{ GetKeyMeta() *proto.KeyMeta }); ok {
+			identifier = km.GetKeyMeta().GetIdentifier()
+		}
+		slot, ok := p.Slots[identifier]
+		if !ok || p.Rates[slot] <= 0 {
+			return handler(ctx, req)
+		}
+		wait, ok := p.reserve(ctx, slot)
+		if !ok {
+			if p.Rejected != nil {
+				p.Rejected(info.FullMethod, req)
+			}
+			return nil, status.Errorf(codes.DeadlineExceeded, "Deadline exceeded: the signing requests of key %q are paced past the deadline", identifier)
+		}
+		if wait > 0 {
+			t := time.NewTimer(wait)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// reserve reserves the next turn of the slot, and returns the time to wait for it. It returns false without
+// reserving the turn if the turn comes after the deadline of the context.
+func (p *SignPacer) reserve(ctx context.Context, slot string) (time.Duration, bool) {
+	interval := time.Duration(float64(time.Second) / p.Rates[slot])
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next == nil {
+		p.next = make(map[string]time.Time)
+	}
+	turn := p.next[slot]
+	if turn.Before(now) {
+		turn = now
+	}
+	if deadline, ok := ctx.Deadline(); ok && turn.After(deadline) {
+		return 0, false
+	}
+	p.next[slot] = turn.Add(interval)
+	return turn.Sub(now), true
+}
@@ -0,0 +1,88 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSignPacer(t *testing.T) {
+	t.Parallel()
+	const rate, burst = 50, 5
+	interval := time.Second / rate
+	rejected := 0
+	pacer := &SignPacer{
+		Slots:    map[string]string{"blobid1": "/1"},
+		Rates:    map[string]float64{"/1": rate},
+		Rejected: func(method string, req interface{}) { rejected++ },
+	}
+	interceptor := pacer.UnaryServerInterceptor()
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	var mu sync.Mutex
+	var signed []time.Time
+	signBlob := func(ctx context.Context) error {
+		request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA512Digest, HashAlgorithm: proto.HashAlgo_SHA512}
+		info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+		_, err := interceptor(ctx, request, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			mu.Lock()
+			signed = append(signed, time.Now())
+			mu.Unlock()
+			return ss.PostSignBlob(ctx, req.(*proto.BlobSigningRequest))
+		})
+		return err
+	}
+
+	// A burst is served at the rate rather than all at once.
+	var wg sync.WaitGroup
+	for i := 0; i < burst; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := signBlob(context.Background()); err != nil {
+				t.Errorf("unable to sign a paced request: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(signed) != burst {
+		t.Fatalf("got %d signed requests, want %d", len(signed), burst)
+	}
+	sort.Slice(signed, func(i, j int) bool { return signed[i].Before(signed[j]) })
+	for i := 1; i < burst; i++ {
+		if gap := signed[i].Sub(signed[i-1]); gap < interval*8/10 {
+			t.Errorf("got request %d signed %v after the previous one, want about %v", i, gap, interval)
+		}
+	}
+	if span := signed[burst-1].Sub(signed[0]); span > (burst-1)*interval+time.Second {
+		t.Errorf("got the burst signed over %v, want about %v", span, (burst-1)*interval)
+	}
+
+	// A request whose turn comes after its deadline fails right away without taking the turn.
+	pacer.Rates["/1"] = 1
+	if err := signBlob(context.Background()); err != nil {
+		t.Fatalf("unable to sign a paced request: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := signBlob(ctx); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("got %v for a request paced past its deadline, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("got a request paced past its deadline rejected after %v, want it rejected right away", elapsed)
+	}
+	if rejected != 1 {
+		t.Errorf("got %d rejected requests, want 1", rejected)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProblemContentType is the media type of the error responses of ProblemJSONErrorHandler.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix is the prefix of the types of the problems, followed by the name of their gRPC status code.
+const problemTypePrefix = "urn:crypki:problem:"
+
+// problem is the problem details object of RFC 7807 describing an error of the REST gateway.
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Code is the gRPC status code of the error.
+	Code codes.Code `json:"code"`
+	// RetryAfter is the number of seconds after which the request may be retried, if the error carries
+	// a RetryInfo detail. It is also returned in the Retry-After header.
+	RetryAfter int64 `json:"retry_after,omitempty"`
+}
+
+// ProblemJSONErrorHandler is a runtime.ProtoErrorHandlerFunc writing the errors of the REST gateway as
+// RFC 7807 problem+json bodies, whose type identifies the gRPC status code of the error, whose title is the
+// text of the HTTP status mapped from the code and whose detail is the message of the status.
+func ProblemJSONErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.New(codes.Unknown, err.Error())
+	}
+	httpStatus := runtime.HTTPStatusFromCode(st.Code())
+	p := &problem{
+		Type:   problemTypePrefix + st.Code().String(),
+		Title:  http.StatusText(httpStatus),
+		Status: httpStatus,
+		Detail: st.Message(),
+		Code:   st.Code(),
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			if delay, err := ptypes.Duration(info.GetRetryDelay()); err == nil {
+				p.RetryAfter = int64(math.Ceil(delay.Seconds()))
+			}
+		}
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Set("Content-Type", ProblemContentType)
+	if p.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(p.RetryAfter, 10))
+	}
+	w.WriteHeader(httpStatus)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Printf("unable to write problem response: %v", err)
+	}
+}
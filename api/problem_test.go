@@ -0,0 +1,101 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// gatewaySigningClient is a proto.SigningClient calling the PostSignBlob of a SigningService directly.
+type gatewaySigningClient struct {
+	proto.SigningClient
+	ss *SigningService
+}
+
+func (c *gatewaySigningClient) PostSignBlob(ctx context.Context, in *proto.BlobSigningRequest, opts ...grpc.CallOption) (*proto.Signature, error) {
+	return c.ss.PostSignBlob(ctx, in)
+}
+
+func TestProblemJSONErrorHandler(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	mux := runtime.NewServeMux(runtime.WithProtoErrorHandler(ProblemJSONErrorHandler))
+	if err := proto.RegisterSigningHandlerClient(context.Background(), mux, &gatewaySigningClient{ss: ss}); err != nil {
+		t.Fatalf("unable to register signing handler: %v", err)
+	}
+
+	testcases := map[string]struct {
+		body            string
+		expectedProblem problem
+	}{
+		"invalid-digest": {
+			body: `{"digest": "not base64!", "hash_algorithm": "SHA256"}`,
+			expectedProblem: problem{
+				Type:   "urn:crypki:problem:InvalidArgument",
+				Title:  "Bad Request",
+				Status: http.StatusBadRequest,
+				Detail: "Bad request: illegal base64 data at input byte 3",
+				Code:   codes.InvalidArgument,
+			},
+		},
+		"malformed-body": {
+			body: `{"digest": `,
+			expectedProblem: problem{
+				Type:   "urn:crypki:problem:InvalidArgument",
+				Title:  "Bad Request",
+				Status: http.StatusBadRequest,
+				Detail: "unexpected EOF",
+				Code:   codes.InvalidArgument,
+			},
+		},
+	}
+	for label, tt := range testcases {
+		req := httptest.NewRequest(http.MethodPost, "/v3/sig/blob/keys/blobid1", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tt.expectedProblem.Status {
+			t.Errorf("in test %v: got HTTP status %d, want %d", label, w.Code, tt.expectedProblem.Status)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+			t.Errorf("in test %v: got content type %q, want %q", label, ct, ProblemContentType)
+		}
+		var got problem
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("in test %v: unable to decode problem %q: %v", label, w.Body.String(), err)
+		}
+		if !reflect.DeepEqual(got, tt.expectedProblem) {
+			t.Errorf("in test %v: got problem %+v, want %+v", label, got, tt.expectedProblem)
+		}
+	}
+}
+
+func TestProblemJSONErrorHandlerRetryInfo(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	err := unavailableWithRetry(1500*time.Millisecond, `key "blobid1" is being reloaded`)
+	ProblemJSONErrorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, w, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("got HTTP status %d and Retry-After %q, want 503 and 2", w.Code, w.Header().Get("Retry-After"))
+	}
+	var got problem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unable to decode problem %q: %v", w.Body.String(), err)
+	}
+	if got.Type != "urn:crypki:problem:Unavailable" || got.RetryAfter != 2 {
+		t.Errorf("got problem %+v, want type urn:crypki:problem:Unavailable retrying after 2 seconds", got)
+	}
+}
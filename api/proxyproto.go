@@ -0,0 +1,223 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyHeaderTimeout is the time within which a client connection must send its PROXY protocol header.
+const proxyHeaderTimeout = 5 * time.Second
+
+// proxyV2Signature is the signature starting the binary headers of PROXY protocol version 2.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ProxyProtocolListener returns a net.Listener whose connections start with a PROXY protocol (version 1 or 2)
+// header, as sent by an L4 load balancer, and whose RemoteAddr is the client address of the header instead
+// of the address of the load balancer. The connections without a valid header are closed. The headers are
+// read concurrently, so that a slow client does not delay the others, but of at most maxPending connections
+// at once: no further connection is accepted from l until one of them is done, so that the connections
+// whose headers are being read are bounded, as the ones limited by LimitListener above it.
+//
+// Any client able to connect to l can claim any address, so l must only be reachable through the load balancer.
+func ProxyProtocolListener(l net.Listener, maxPending int) net.Listener {
+	return &proxyListener{
+		Listener: l,
+		pending:  make(chan struct{}, maxPending),
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		done:     make(chan struct{}),
+	}
+}
+
+type proxyListener struct {
+	net.Listener
+	start sync.Once
+	// pending holds a token for each connection whose header is being read.
+	pending chan struct{}
+	conns   chan net.Conn
+	errs    chan error
+	// done is closed by Close to stop the pending handshakes.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Accept waits for and returns the next connection whose PROXY protocol header was read.
+func (l *proxyListener) Accept() (net.Conn, error) {
+	l.start.Do(func() { go l.serve() })
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.done:
+		return nil, errors.New("listener closed")
+	}
+}
+
+// serve accepts the connections of the underlying listener and reads their headers, until it fails
+// with a non-temporary error.
+func (l *proxyListener) serve() {
+	for {
+		select {
+		case l.pending <- struct{}{}:
+		case <-l.done:
+			return
+		}
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			<-l.pending
+			select {
+			case l.errs <- err:
+			case <-l.done:
+				return
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return
+		}
+		go l.handshake(conn)
+	}
+}
+
+// handshake reads the header of the connection and hands the connection over to Accept.
+func (l *proxyListener) handshake(conn net.Conn) {
+	defer func() { <-l.pending }()
+	conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+	r := bufio.NewReader(conn)
+	src, err := readProxyHeader(r)
+	if err != nil {
+		log.Printf("invalid PROXY protocol header from %s: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	pc := &proxyConn{Conn: conn, r: r, remote: src}
+	select {
+	case l.conns <- pc:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// Close closes the listener and the connections whose headers are still being read.
+func (l *proxyListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+// readProxyHeader reads a PROXY protocol header and returns the client address it carries, or nil if
+// the header does not carry one, e.g. for the health checks of the load balancer.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	sig, err := r.Peek(len(proxyV2Signature))
+	if err == nil && bytes.Equal(sig, proxyV2Signature) {
+		return readProxyV2Header(r)
+	}
+	if sig, err := r.Peek(6); err != nil || string(sig) != "PROXY " {
+		return nil, errors.New("missing PROXY protocol header")
+	}
+	return readProxyV1Header(r)
+}
+
+// readProxyV1Header reads a text header of PROXY protocol version 1, such as
+// "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readProxyV1Header(r *bufio.Reader) (net.Addr, error) {
+	// The header is at most 107 bytes long, including the CRLF.
+	var line []byte
+	for len(line) < 107 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if bytes.HasSuffix(line, []byte("\r\n")) {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("PROXY protocol v1 header too long")
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("malformed PROXY protocol v1 header %q", strings.TrimSpace(string(line)))
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil || (ip.To4() != nil) != (fields[1] == "TCP4") {
+		return nil, fmt.Errorf("malformed PROXY protocol v1 header %q", strings.TrimSpace(string(line)))
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2Header reads a binary header of PROXY protocol version 2.
+func readProxyV2Header(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(proxyV2Signature)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	verCmd, family := header[12], header[13]
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", verCmd>>4)
+	}
+	switch verCmd & 0xf {
+	case 0: // LOCAL
+		return nil, nil
+	case 1: // PROXY
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol v2 command %d", verCmd&0xf)
+	}
+	switch family {
+	case 0x11: // TCP over IPv4
+		if len(payload) < 12 {
+			return nil, errors.New("truncated PROXY protocol v2 IPv4 addresses")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:]))}, nil
+	case 0x21: // TCP over IPv6
+		if len(payload) < 36 {
+			return nil, errors.New("truncated PROXY protocol v2 IPv6 addresses")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:]))}, nil
+	}
+	// The addresses of the other families, such as UNIX sockets, are ignored.
+	return nil, nil
+}
+
+// proxyConn is a connection whose header was read, with the client address of the header if any.
+type proxyConn struct {
+	net.Conn
+	// r holds the data read past the header.
+	r      *bufio.Reader
+	remote net.Addr
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the client address of the header, or the address of the peer if the header has none.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
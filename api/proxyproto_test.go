@@ -0,0 +1,168 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// proxyV2Header returns a PROXY protocol v2 header of a TCP connection from src to dst.
+func proxyV2Header(src, dst *net.TCPAddr) []byte {
+	var b bytes.Buffer
+	b.Write(proxyV2Signature)
+	family, size := byte(0x11), 4
+	if src.IP.To4() == nil {
+		family, size = 0x21, 16
+	}
+	b.Write([]byte{0x21, family})
+	_ = binary.Write(&b, binary.BigEndian, uint16(2*size+4))
+	if size == 4 {
+		b.Write(src.IP.To4())
+		b.Write(dst.IP.To4())
+	} else {
+		b.Write(src.IP.To16())
+		b.Write(dst.IP.To16())
+	}
+	_ = binary.Write(&b, binary.BigEndian, uint16(src.Port))
+	_ = binary.Write(&b, binary.BigEndian, uint16(dst.Port))
+	return b.Bytes()
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	t.Parallel()
+	dst := &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 443}
+	testcases := map[string]struct {
+		header       []byte
+		expectedAddr string
+		expectError  bool
+	}{
+		"v1-tcp4":          {header: []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"), expectedAddr: "192.0.2.1:56324"},
+		"v1-tcp6":          {header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n"), expectedAddr: "[2001:db8::1]:56324"},
+		"v1-unknown":       {header: []byte("PROXY UNKNOWN\r\n")},
+		"v1-mismatched-ip": {header: []byte("PROXY TCP4 2001:db8::1 198.51.100.1 56324 443\r\n"), expectError: true},
+		"v1-unterminated":  {header: []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443"), expectError: true},
+		"v2-tcp4":          {header: proxyV2Header(&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 56324}, dst), expectedAddr: "192.0.2.1:56324"},
+		"v2-tcp6":          {header: proxyV2Header(&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 56324}, &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443}), expectedAddr: "[2001:db8::1]:56324"},
+		"v2-local":         {header: append(append([]byte{}, proxyV2Signature...), 0x20, 0x00, 0x00, 0x00)},
+		"missing":          {header: []byte("GET / HTTP/1.1\r\n"), expectError: true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			r := bufio.NewReader(bytes.NewReader(append(tt.header, "data"...)))
+			addr, err := readProxyHeader(r)
+			if err != nil != tt.expectError {
+				t.Fatalf("in test %v: got err: %v, expect err: %v", label, err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+			if got := ""; addr != nil {
+				got = addr.String()
+				if got != tt.expectedAddr {
+					t.Errorf("in test %v: got address %s, want %s", label, got, tt.expectedAddr)
+				}
+			} else if tt.expectedAddr != "" {
+				t.Errorf("in test %v: got no address, want %s", label, tt.expectedAddr)
+			}
+			if rest, _ := r.ReadString(0); rest != "data" {
+				t.Errorf("in test %v: got %q after the header, want %q", label, rest, "data")
+			}
+		})
+	}
+}
+
+func TestProxyProtocolListenerPerIPLimit(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	limited := LimitListener(ProxyProtocolListener(l, 8), 0, 1)
+	defer limited.Close()
+	go serveEcho(limited)
+
+	dst := &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 443}
+	// All connections come from the load balancer at 127.0.0.1, and are limited by the client IPs of their headers.
+	testcases := []struct {
+		label  string
+		header []byte
+		served bool
+	}{
+		{"first-client", []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"), true},
+		{"first-client-again", proxyV2Header(&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 56325}, dst), false},
+		{"second-client", proxyV2Header(&net.TCPAddr{IP: net.ParseIP("192.0.2.2"), Port: 56324}, dst), true},
+		{"no-header", nil, false},
+	}
+	for _, tt := range testcases {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("unable to dial: %v", err)
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(time.Second))
+		served := false
+		if _, err := conn.Write(append(tt.header, 'x')); err == nil {
+			_, err = conn.Read(make([]byte, 1))
+			served = err == nil
+		}
+		if served != tt.served {
+			t.Errorf("in test %v: got served: %v, want %v", tt.label, served, tt.served)
+		}
+	}
+}
+
+func TestProxyProtocolListenerMaxPending(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	pl := ProxyProtocolListener(l, 1)
+	defer pl.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := pl.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	// A client not sending its header holds the only pending handshake.
+	slow, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	defer slow.Close()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n")); err != nil {
+		t.Fatalf("unable to write header: %v", err)
+	}
+	select {
+	case <-accepted:
+		t.Fatal("connection accepted beyond the pending handshakes")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	slow.Close()
+	select {
+	case c := <-accepted:
+		if got := c.RemoteAddr().String(); got != "192.0.2.1:56324" {
+			t.Errorf("got remote address %s, want the one of the header", got)
+		}
+		c.Close()
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection not accepted once the pending handshake is done")
+	}
+}
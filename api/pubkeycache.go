@@ -0,0 +1,66 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"sync"
+)
+
+// Kinds of the public keys cached by PublicKeyCache.
+const (
+	cachedSSHKey  = "ssh"
+	cachedX509CA  = "x509"
+	cachedBlobKey = "blob"
+)
+
+// publicKeyCacheKey identifies a cached public key.
+type publicKeyCacheKey struct {
+	kind       string
+	identifier string
+}
+
+// PublicKeyCache caches the public keys and CA certificates of the signing keys, so that
+// the public key endpoints do not need a HSM session per request.
+type PublicKeyCache struct {
+	mu      sync.RWMutex
+	entries map[publicKeyCacheKey][]byte
+}
+
+// NewPublicKeyCache returns an empty PublicKeyCache.
+func NewPublicKeyCache() *PublicKeyCache {
+	return &PublicKeyCache{entries: make(map[publicKeyCacheKey][]byte)}
+}
+
+// get returns the cached public key of the kind of the specified key, calling fetch on a miss.
+// Errors are not cached. A nil PublicKeyCache always calls fetch.
+func (c *PublicKeyCache) get(kind, identifier string, fetch func(string) ([]byte, error)) ([]byte, error) {
+	if c == nil {
+		return fetch(identifier)
+	}
+	key := publicKeyCacheKey{kind, identifier}
+	c.mu.RLock()
+	value, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		return value, nil
+	}
+	value, err := fetch(identifier)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[key] = value
+	c.mu.Unlock()
+	return value, nil
+}
+
+// Invalidate removes all cached public keys. It must be called when the keys are reloaded.
+func (c *PublicKeyCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[publicKeyCacheKey][]byte)
+}
@@ -0,0 +1,138 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+)
+
+// mockCountingCertSign is a mockGoodCertSign that counts the public key fetches,
+// failing the first fetch of each kind if failFirst is set.
+type mockCountingCertSign struct {
+	mockGoodCertSign
+	failFirst bool
+	mu        sync.Mutex
+	calls     map[string]int
+}
+
+func (m *mockCountingCertSign) count(kind string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls[kind]++
+	if m.failFirst && m.calls[kind] == 1 {
+		return errors.New("CKR_SESSION_HANDLE_INVALID")
+	}
+	return nil
+}
+
+func (m *mockCountingCertSign) fetches(kind string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[kind]
+}
+
+func (m *mockCountingCertSign) GetSSHCertSigningKey(keyIdentifier string) ([]byte, error) {
+	if err := m.count(cachedSSHKey); err != nil {
+		return nil, err
+	}
+	return m.mockGoodCertSign.GetSSHCertSigningKey(keyIdentifier)
+}
+
+func (m *mockCountingCertSign) GetX509CACert(keyIdentifier string) ([]byte, error) {
+	if err := m.count(cachedX509CA); err != nil {
+		return nil, err
+	}
+	return m.mockGoodCertSign.GetX509CACert(keyIdentifier)
+}
+
+func (m *mockCountingCertSign) GetBlobSigningPublicKey(keyIdentifier string) ([]byte, error) {
+	if err := m.count(cachedBlobKey); err != nil {
+		return nil, err
+	}
+	return m.mockGoodCertSign.GetBlobSigningPublicKey(keyIdentifier)
+}
+
+// getPublicKeys calls each of the public key endpoints once.
+func getPublicKeys(ss *SigningService) error {
+	ctx := context.Background()
+	if _, err := ss.GetUserSSHCertificateSigningKey(ctx, &proto.KeyMeta{Identifier: "sshuserid1"}); err != nil {
+		return err
+	}
+	if _, err := ss.GetHostSSHCertificateSigningKey(ctx, &proto.KeyMeta{Identifier: "sshhostid1"}); err != nil {
+		return err
+	}
+	if _, err := ss.GetX509CACertificate(ctx, &proto.KeyMeta{Identifier: "x509id1"}); err != nil {
+		return err
+	}
+	_, err := ss.GetBlobSigningKey(ctx, &proto.KeyMeta{Identifier: "blobid1"})
+	return err
+}
+
+func TestPublicKeyCache(t *testing.T) {
+	t.Parallel()
+	signer := &mockCountingCertSign{calls: make(map[string]int)}
+	ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, PublicKeyCache: NewPublicKeyCache()}
+	checkFetches := func(step string, ssh, x509, blob int) {
+		t.Helper()
+		for kind, want := range map[string]int{cachedSSHKey: ssh, cachedX509CA: x509, cachedBlobKey: blob} {
+			if got := signer.fetches(kind); got != want {
+				t.Errorf("%s: got %d %s fetches, want %d", step, got, kind, want)
+			}
+		}
+	}
+
+	if err := getPublicKeys(ss); err != nil {
+		t.Fatalf("unable to get public keys: %v", err)
+	}
+	// The user and host SSH keys are different keys, so each is fetched once.
+	checkFetches("cold cache", 2, 1, 1)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- getPublicKeys(ss)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unable to get public keys concurrently: %v", err)
+		}
+	}
+	checkFetches("warm cache", 2, 1, 1)
+
+	ss.PublicKeyCache.Invalidate()
+	if err := getPublicKeys(ss); err != nil {
+		t.Fatalf("unable to get public keys after invalidation: %v", err)
+	}
+	checkFetches("invalidated cache", 4, 2, 2)
+}
+
+func TestPublicKeyCacheErrorsNotCached(t *testing.T) {
+	t.Parallel()
+	signer := &mockCountingCertSign{failFirst: true, calls: make(map[string]int)}
+	ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, PublicKeyCache: NewPublicKeyCache()}
+	ctx := context.Background()
+	if _, err := ss.GetBlobSigningKey(ctx, &proto.KeyMeta{Identifier: "blobid1"}); err == nil {
+		t.Fatal("expected error from the first fetch")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := ss.GetBlobSigningKey(ctx, &proto.KeyMeta{Identifier: "blobid1"}); err != nil {
+			t.Fatalf("unable to get blob signing key: %v", err)
+		}
+	}
+	if got := signer.fetches(cachedBlobKey); got != 2 {
+		t.Errorf("got %d fetches, want 2", got)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitBackend holds the token buckets of the rate limits. A backend shared by the crypki replicas,
+// such as RedisRateLimitBackend, enforces the limits across the replicas instead of per replica.
+type RateLimitBackend interface {
+	// Take takes a token from the bucket named by key, which holds at most burst tokens and is refilled
+	// at rate tokens per second, starting full. It returns false if the bucket is empty.
+	Take(key string, rate float64, burst int) (bool, error)
+}
+
+// RateLimit is the rate limit of the signing requests of a key.
+type RateLimit struct {
+	// Rate is the number of requests per second.
+	Rate float64
+	// Burst is the number of requests that may be signed at once above the rate.
+	Burst int
+}
+
+// RateLimiter limits the rate of the signing requests of the keys, with the state of the limits held by Backend.
+type RateLimiter struct {
+	Backend RateLimitBackend
+	// Limits maps the key identifiers to their rate limits. The keys without a limit are not limited.
+	Limits map[string]RateLimit
+	// FailClosed specifies whether requests are rejected if the backend fails; by default they are signed.
+	FailClosed bool
+	// Rejected, if set, is called with the full method name and the request of each rejected request.
+	Rejected func(method string, req interface{})
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor returning ResourceExhausted for the
+// signing requests of a key exceeding its rate limit.
+func (r *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if methodEndpoints[method] == "" || !strings.HasPrefix(method, "Post") {
+			return handler(ctx, req)
+		}
+		var identifier string
+		if km, ok := req.(interface{ GetKeyMeta() *proto.KeyMeta }); ok {
+			identifier = km.GetKeyMeta().GetIdentifier()
+		}
+		limit, ok := r.Limits[identifier]
+		if !ok {
+			return handler(ctx, req)
+		}
+		allowed, err := r.Backend.Take("crypki:ratelimit:"+identifier, limit.Rate, limit.Burst)
+		if err != nil {
+			log.Printf("m=%s,id=%q: rate limit backend failed: %v", method, identifier, err)
+			if !r.FailClosed {
+				return handler(ctx, req)
+			}
+			return nil, status.Errorf(codes.Unavailable, "Service unavailable: unable to check the rate limit of key %q", identifier)
+		}
+		if !allowed {
+			if r.Rejected != nil {
+				r.Rejected(info.FullMethod, req)
+			}
+			return nil, status.Errorf(codes.ResourceExhausted, "Resource exhausted: rate limit of key %q exceeded", identifier)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MemoryRateLimitBackend is a RateLimitBackend holding the token buckets in memory, i.e. per replica.
+type MemoryRateLimitBackend struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	// now returns the current time; it is time.Now if nil.
+	now func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Take takes a token from the bucket named by key.
+func (m *MemoryRateLimitBackend) Take(key string, rate float64, burst int) (bool, error) {
+	now := time.Now()
+	if m.now != nil {
+		now = m.now()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buckets == nil {
+		m.buckets = make(map[string]*tokenBucket)
+	}
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, nil
+	}
+	b.tokens--
+	return true, nil
+}
+
+// redisTakeScript takes a token from the bucket hash KEYS[1] refilled at ARGV[1] tokens per second up to
+// ARGV[2] tokens, using the clock of the Redis server so that the replicas agree on the time.
+const redisTakeScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(b[1]) or burst
+local last = tonumber(b[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', tostring(now))
+redis.call('EXPIRE', KEYS[1], math.ceil(burst / rate) + 1)
+return allowed
+`
+
+// Defaults of RedisRateLimitBackend.
+const (
+	defaultRedisMaxIdleConns  = 16
+	defaultRedisRetryInterval = time.Second
+)
+
+// RedisRateLimitBackend is a RateLimitBackend holding the token buckets in a Redis server shared by the replicas.
+// The requests to the server run concurrently on a pool of connections. Once the server cannot be reached,
+// it is not contacted again for RetryInterval, so that the requests fail fast during an outage.
+type RedisRateLimitBackend struct {
+	// Addr is the host:port address of the Redis server.
+	Addr string
+	// Timeout is the timeout of each request to the Redis server.
+	Timeout time.Duration
+	// MaxIdleConns is the maximum number of idle connections kept open to the Redis server. Default is 16.
+	MaxIdleConns int
+	// RetryInterval is the time the Redis server is not contacted after it could not be reached. Default is 1s.
+	RetryInterval time.Duration
+
+	mu        sync.Mutex
+	idle      []*redisConn
+	downUntil time.Time
+}
+
+// redisConn is a connection to a Redis server.
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// redisError is an error reply of a Redis server, after which the connection is still usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// Take takes a token from the bucket named by key, atomically for all the replicas.
+func (b *RedisRateLimitBackend) Take(key string, rate float64, burst int) (bool, error) {
+	conn, err := b.get()
+	if err != nil {
+		return false, err
+	}
+	reply, err := b.do(conn, "EVAL", redisTakeScript, "1", key, strconv.FormatFloat(rate, 'f', -1, 64), strconv.Itoa(burst))
+	if err != nil {
+		if _, ok := err.(redisError); ok {
+			b.put(conn)
+			return false, err
+		}
+		// The connection may be left with a partial reply, so it is not reused.
+		conn.Close()
+		b.markDown()
+		return false, err
+	}
+	b.put(conn)
+	return reply == 1, nil
+}
+
+// get returns an idle connection to the Redis server, or a new one. The server is dialed without holding
+// the lock, so that a slow server does not serialize the requests.
+func (b *RedisRateLimitBackend) get() (*redisConn, error) {
+	b.mu.Lock()
+	if now := time.Now(); now.Before(b.downUntil) {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("redis server %s is unreachable, retrying in %v", b.Addr, b.downUntil.Sub(now).Round(time.Millisecond))
+	}
+	if n := len(b.idle); n != 0 {
+		conn := b.idle[n-1]
+		b.idle = b.idle[:n-1]
+		b.mu.Unlock()
+		return conn, nil
+	}
+	b.mu.Unlock()
+	conn, err := net.DialTimeout("tcp", b.Addr, b.Timeout)
+	if err != nil {
+		b.markDown()
+		return nil, fmt.Errorf("unable to connect to redis server %s: %v", b.Addr, err)
+	}
+	return &redisConn{Conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// put returns the connection to the pool of idle connections, or closes it if the pool is full.
+func (b *RedisRateLimitBackend) put(conn *redisConn) {
+	maxIdle := b.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultRedisMaxIdleConns
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.idle) >= maxIdle {
+		conn.Close()
+		return
+	}
+	b.idle = append(b.idle, conn)
+}
+
+// markDown stops contacting the Redis server for RetryInterval, and closes the idle connections,
+// which likely failed as well.
+func (b *RedisRateLimitBackend) markDown() {
+	interval := b.RetryInterval
+	if interval <= 0 {
+		interval = defaultRedisRetryInterval
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.downUntil = time.Now().Add(interval)
+	for _, conn := range b.idle {
+		conn.Close()
+	}
+	b.idle = nil
+}
+
+// do sends the command to the Redis server on the connection and returns its integer reply.
+// It returns a redisError for an error reply of the server.
+func (b *RedisRateLimitBackend) do(conn *redisConn, args ...string) (int64, error) {
+	if b.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(b.Timeout)); err != nil {
+			return 0, err
+		}
+	}
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := conn.Write([]byte(cmd.String())); err != nil {
+		return 0, fmt.Errorf("unable to send command to redis server %s: %v", b.Addr, err)
+	}
+	line, err := conn.r.ReadString('\n')
+	if err != nil {
+		return 0, fmt.Errorf("unable to read reply of redis server %s: %v", b.Addr, err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return 0, errors.New("empty reply from redis server")
+	}
+	switch line[0] {
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '-':
+		return 0, redisError(fmt.Sprintf("redis server %s: %s", b.Addr, line[1:]))
+	default:
+		return 0, fmt.Errorf("unexpected reply from redis server %s: %q", b.Addr, line)
+	}
+}
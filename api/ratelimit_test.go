@@ -0,0 +1,231 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockRateLimitBackend is a RateLimitBackend shared by replicas, whose buckets hold burst tokens and are never refilled.
+type mockRateLimitBackend struct {
+	mu    sync.Mutex
+	taken map[string]int
+	err   error
+}
+
+func (m *mockRateLimitBackend) Take(key string, rate float64, burst int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return false, m.err
+	}
+	if m.taken[key] >= burst {
+		return false, nil
+	}
+	m.taken[key]++
+	return true, nil
+}
+
+func TestRateLimiterUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		backendErr   error
+		failClosed   bool
+		identifier   string
+		method       string
+		expectedOK   int
+		expectedCode codes.Code
+		// expectedRejected is the number of requests reported as rejected by the rate limit.
+		expectedRejected int
+	}{
+		"shared-limit": {
+			identifier:       "blobid1",
+			method:           "PostSignBlob",
+			expectedOK:       3,
+			expectedCode:     codes.ResourceExhausted,
+			expectedRejected: 3,
+		},
+		"unlimited-key": {
+			identifier: "blobid2",
+			method:     "PostSignBlob",
+			expectedOK: 6,
+		},
+		"not-a-signing-method": {
+			identifier: "blobid1",
+			method:     "GetBlobSigningKey",
+			expectedOK: 6,
+		},
+		"backend-failure-fail-open": {
+			backendErr: errors.New("connection refused"),
+			identifier: "blobid1",
+			method:     "PostSignBlob",
+			expectedOK: 6,
+		},
+		"backend-failure-fail-closed": {
+			backendErr:   errors.New("connection refused"),
+			failClosed:   true,
+			identifier:   "blobid1",
+			method:       "PostSignBlob",
+			expectedCode: codes.Unavailable,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			backend := &mockRateLimitBackend{taken: make(map[string]int), err: tt.backendErr}
+			rejected := 0
+			// Two replicas sharing the backend enforce the limit together.
+			var replicas []grpc.UnaryServerInterceptor
+			for i := 0; i < 2; i++ {
+				limiter := &RateLimiter{
+					Backend:    backend,
+					Limits:     map[string]RateLimit{"blobid1": {Rate: 1, Burst: 3}},
+					FailClosed: tt.failClosed,
+					Rejected:   func(method string, req interface{}) { rejected++ },
+				}
+				replicas = append(replicas, limiter.UnaryServerInterceptor())
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &proto.Signature{}, nil
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/" + tt.method}
+			request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: tt.identifier}}
+			ok := 0
+			for i := 0; i < 6; i++ {
+				_, err := replicas[i%2](context.Background(), request, info, handler)
+				if err == nil {
+					ok++
+					continue
+				}
+				if got := status.Code(err); got != tt.expectedCode {
+					t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+				}
+			}
+			if ok != tt.expectedOK {
+				t.Errorf("in test %v: got %d requests signed, want %d", label, ok, tt.expectedOK)
+			}
+			if rejected != tt.expectedRejected {
+				t.Errorf("in test %v: got %d rate limit rejections, want %d", label, rejected, tt.expectedRejected)
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitBackend(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	backend := &MemoryRateLimitBackend{now: func() time.Time { return now }}
+	for i := 0; i < 2; i++ {
+		if ok, _ := backend.Take("key", 2, 2); !ok {
+			t.Fatalf("request %d rejected within the burst", i)
+		}
+	}
+	if ok, _ := backend.Take("key", 2, 2); ok {
+		t.Fatal("request allowed beyond the burst")
+	}
+	if ok, _ := backend.Take("other", 2, 2); !ok {
+		t.Fatal("request of another bucket rejected")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := backend.Take("key", 2, 2); !ok {
+		t.Fatal("request rejected after the bucket was refilled")
+	}
+	if ok, _ := backend.Take("key", 2, 2); ok {
+		t.Fatal("request allowed beyond the refilled tokens")
+	}
+}
+
+// readRESPLength reads a RESP array or bulk string header, such as "*3\r\n", and returns its length.
+func readRESPLength(r *bufio.Reader) int {
+	line, _ := r.ReadString('\n')
+	if line = strings.TrimSpace(line); line == "" {
+		return 0
+	}
+	n, _ := strconv.Atoi(line[1:])
+	return n
+}
+
+func TestRedisRateLimitBackend(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer l.Close()
+	replies := []string{":1\r\n", ":0\r\n", "-ERR script failed\r\n"}
+	commands := make(chan []string, len(replies))
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for _, reply := range replies {
+			// Read an array of bulk strings.
+			var args []string
+			n := readRESPLength(r)
+			for i := 0; i < n; i++ {
+				arg := make([]byte, readRESPLength(r)+2)
+				if _, err := io.ReadFull(r, arg); err != nil {
+					return
+				}
+				args = append(args, string(arg[:len(arg)-2]))
+			}
+			commands <- args
+			conn.Write([]byte(reply))
+		}
+	}()
+
+	backend := &RedisRateLimitBackend{Addr: l.Addr().String(), Timeout: 5 * time.Second}
+	if ok, err := backend.Take("crypki:ratelimit:key", 0.5, 3); !ok || err != nil {
+		t.Errorf("got %v, %v, want the request allowed", ok, err)
+	}
+	args := <-commands
+	if len(args) != 6 || args[0] != "EVAL" || args[2] != "1" || args[3] != "crypki:ratelimit:key" || args[4] != "0.5" || args[5] != "3" {
+		t.Errorf("unexpected command %q", args)
+	}
+	if ok, err := backend.Take("crypki:ratelimit:key", 0.5, 3); ok || err != nil {
+		t.Errorf("got %v, %v, want the request rejected", ok, err)
+	}
+	<-commands
+	if _, err := backend.Take("crypki:ratelimit:key", 0.5, 3); err == nil || !strings.Contains(err.Error(), "script failed") {
+		t.Errorf("got err %v, want the error of the server", err)
+	}
+}
+
+func TestRedisRateLimitBackendUnreachable(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	backend := &RedisRateLimitBackend{Addr: addr, Timeout: time.Second, RetryInterval: time.Hour}
+	if _, err := backend.Take("crypki:ratelimit:key", 1, 1); err == nil || !strings.Contains(err.Error(), "unable to connect") {
+		t.Errorf("got err %v, want the connection error", err)
+	}
+	// The server is not dialed again until the retry interval elapses.
+	if _, err := backend.Take("crypki:ratelimit:key", 1, 1); err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("got err %v, want the server skipped", err)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ReadinessGate rejects the signing requests with Unavailable and a retry hint while the server is not ready,
+// e.g. while keys are still being loaded in the background at startup, so that clients retry them on another
+// replica during a rollout instead of being served by a partial set of keys. Other requests are served.
+type ReadinessGate struct {
+	// Ready reports whether the server is ready.
+	Ready func() bool
+	// RetryDelay is the delay after which clients are told to retry their requests.
+	RetryDelay time.Duration
+	// Rejected, if set, is called with the full method name and the request of each rejected request.
+	Rejected func(method string, req interface{})
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor returning Unavailable with a RetryInfo
+// detail for the signing requests received while the server is not ready.
+func (r *ReadinessGate) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if methodEndpoints[method] == "" || !strings.HasPrefix(method, "Post") || r.Ready() {
+			return handler(ctx, req)
+		}
+		if r.Rejected != nil {
+			r.Rejected(info.FullMethod, req)
+		}
+		return nil, unavailableWithRetry(r.RetryDelay, "crypki is starting up")
+	}
+}
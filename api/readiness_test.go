@@ -0,0 +1,67 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadinessGate(t *testing.T) {
+	t.Parallel()
+	var ready int32
+	rejected := 0
+	gate := &ReadinessGate{
+		Ready:      func() bool { return atomic.LoadInt32(&ready) == 1 },
+		RetryDelay: 2 * time.Second,
+		Rejected:   func(method string, req interface{}) { rejected++ },
+	}
+	interceptor := gate.UnaryServerInterceptor()
+	ss := &SigningService{CertSign: &mockGoodCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	signBlob := func() error {
+		request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA512Digest, HashAlgorithm: proto.HashAlgo_SHA512}
+		info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+		_, err := interceptor(context.Background(), request, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return ss.PostSignBlob(ctx, req.(*proto.BlobSigningRequest))
+		})
+		return err
+	}
+	getKey := func() error {
+		info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/GetBlobAvailableSigningKeys"}
+		_, err := interceptor(context.Background(), &proto.KeyFilter{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return ss.GetBlobAvailableSigningKeys(ctx, req.(*proto.KeyFilter))
+		})
+		return err
+	}
+
+	err := signBlob()
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("got %v signing while not ready, want Unavailable", err)
+	}
+	if delay := retryDelay(t, err); delay != gate.RetryDelay {
+		t.Errorf("got retry delay %v, want %v", delay, gate.RetryDelay)
+	}
+	if rejected != 1 {
+		t.Errorf("got %d rejected requests, want 1", rejected)
+	}
+	if err := getKey(); err != nil {
+		t.Errorf("got %v listing keys while not ready, want it served", err)
+	}
+
+	atomic.StoreInt32(&ready, 1)
+	if err := signBlob(); err != nil {
+		t.Errorf("got %v signing once ready, want success", err)
+	}
+	if rejected != 1 {
+		t.Errorf("got %d rejected requests, want 1", rejected)
+	}
+}
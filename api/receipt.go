@@ -0,0 +1,94 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
+)
+
+// issuanceReceipt is the payload of an IssuanceReceipt.
+type issuanceReceipt struct {
+	// Issuer is the identifier of the key that signed the certificate.
+	Issuer string `json:"issuer"`
+	// Type is the type of the certificate, as in the IssuanceRecords.
+	Type   string `json:"type"`
+	Serial string `json:"serial"`
+	// Subject is the subject of an x509 certificate, or the key id of an SSH certificate.
+	Subject    string   `json:"subject"`
+	Principals []string `json:"principals,omitempty"`
+	// Fingerprint is the fingerprint returned along with the certificate.
+	Fingerprint string `json:"fingerprint"`
+	IssuedAt    int64  `json:"issued_at"`
+}
+
+// x509Receipt returns the receipt of the issuance of the x509 certificate by the specified key,
+// or nil if no ReceiptKey is configured.
+func (s *SigningService) x509Receipt(identifier string, cert *x509.Certificate, fingerprint string) (*proto.IssuanceReceipt, error) {
+	receipt := &issuanceReceipt{
+		Issuer:      identifier,
+		Type:        issuanceX509,
+		Subject:     cert.Subject.String(),
+		Fingerprint: fingerprint,
+	}
+	if cert.SerialNumber != nil {
+		receipt.Serial = cert.SerialNumber.String()
+	}
+	return s.signReceipt(receipt)
+}
+
+// sshReceipt returns the receipt of the issuance of the SSH certificate of the type by the specified key,
+// or nil if no ReceiptKey is configured.
+func (s *SigningService) sshReceipt(identifier, certType string, cert *ssh.Certificate, fingerprint string) (*proto.IssuanceReceipt, error) {
+	return s.signReceipt(&issuanceReceipt{
+		Issuer:      identifier,
+		Type:        certType,
+		Serial:      strconv.FormatUint(cert.Serial, 10),
+		Subject:     cert.KeyId,
+		Principals:  cert.ValidPrincipals,
+		Fingerprint: fingerprint,
+	})
+}
+
+// signReceipt signs the receipt issued now with the ReceiptKey.
+func (s *SigningService) signReceipt(receipt *issuanceReceipt) (*proto.IssuanceReceipt, error) {
+	if s.ReceiptKey == "" {
+		return nil, nil
+	}
+	receipt.IssuedAt = time.Now().Unix()
+	return s.signReceiptPayload(receipt)
+}
+
+// signReceiptPayload signs the JSON encoding of the payload with the ReceiptKey.
+func (s *SigningService) signReceiptPayload(v interface{}) (*proto.IssuanceReceipt, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Ed25519 keys sign the payload itself; the other keys sign its SHA256 digest.
+	digest, opts := payload, crypto.SignerOpts(crypto.Hash(0))
+	if s.Keys[s.ReceiptKey].KeyType != crypki.Ed25519 {
+		sum := sha256.Sum256(payload)
+		digest, opts = sum[:], crypto.SHA256
+	}
+	signature, err := s.CertSign.Sign(digest, opts, s.ReceiptKey)
+	s.recordSignResult(s.ReceiptKey, err)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.IssuanceReceipt{
+		Payload:   payload,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		KeyMeta:   &proto.KeyMeta{Identifier: s.ReceiptKey},
+	}, nil
+}
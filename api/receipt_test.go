@@ -0,0 +1,131 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"reflect"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+)
+
+// mockReceiptCertSign signs x509 certificates with the key of a self-signed CA, and receipts with the receipt key.
+type mockReceiptCertSign struct {
+	*mockCACertSign
+	receiptKey *ecdsa.PrivateKey
+}
+
+func (m *mockReceiptCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, m.receiptKey, digest)
+}
+
+// verifyReceipt verifies the signature of the receipt with the public key and returns its payload.
+func verifyReceipt(t *testing.T, receipt *proto.IssuanceReceipt, pub *ecdsa.PublicKey) *issuanceReceipt {
+	t.Helper()
+	if receipt == nil {
+		t.Fatal("no receipt in response")
+	}
+	if receipt.KeyMeta.GetIdentifier() != "receiptid" {
+		t.Errorf("got receipt key %q, want receiptid", receipt.KeyMeta.GetIdentifier())
+	}
+	sig, err := base64.StdEncoding.DecodeString(receipt.Signature)
+	if err != nil {
+		t.Fatalf("unable to decode receipt signature: %v", err)
+	}
+	digest := sha256.Sum256(receipt.Payload)
+	if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+		t.Fatal("invalid receipt signature")
+	}
+	var payload issuanceReceipt
+	if err := json.Unmarshal(receipt.Payload, &payload); err != nil {
+		t.Fatalf("unable to decode receipt payload: %v", err)
+	}
+	return &payload
+}
+
+func TestIssuanceReceipt(t *testing.T) {
+	t.Parallel()
+	receiptKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	subjectKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	ss := &SigningService{
+		CertSign:       &mockReceiptCertSign{newMockCACertSign(t), receiptKey},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      combineKeyUsage,
+		Keys:           map[string]config.KeyConfig{"receiptid": {Identifier: "receiptid", KeyType: crypki.ECDSA}},
+		ReceiptKey:     "receiptid",
+	}
+
+	x509Resp, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+		KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+		Csr:      genCSR(t, subjectKey),
+		Validity: 3600,
+	})
+	if err != nil {
+		t.Fatalf("unable to sign x509 certificate: %v", err)
+	}
+	block, _ := pem.Decode([]byte(x509Resp.Cert))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("unable to parse x509 certificate: %v", err)
+	}
+	got := verifyReceipt(t, x509Resp.Receipt, &receiptKey.PublicKey)
+	expected := &issuanceReceipt{
+		Issuer:      "x509id1",
+		Type:        issuanceX509,
+		Serial:      cert.SerialNumber.String(),
+		Subject:     "CN=foo.bar.com",
+		Fingerprint: x509Resp.Fingerprint,
+		IssuedAt:    got.IssuedAt,
+	}
+	if !reflect.DeepEqual(got, expected) || got.IssuedAt == 0 {
+		t.Errorf("got x509 receipt %+v, want %+v", got, expected)
+	}
+
+	sshResp, err := ss.PostUserSSHCertificate(context.Background(), &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+		PublicKey:  testGoodRsaPubKey,
+		Validity:   3600,
+		Principals: []string{"alice"},
+		KeyId:      testGoodKeyID,
+	})
+	if err != nil {
+		t.Fatalf("unable to sign SSH certificate: %v", err)
+	}
+	got = verifyReceipt(t, sshResp.Receipt, &receiptKey.PublicKey)
+	if got.Issuer != "sshuserid1" || got.Type != issuanceSSHUser || got.Subject != testGoodKeyID ||
+		!reflect.DeepEqual(got.Principals, []string{"alice"}) || got.Fingerprint != sshResp.Fingerprint || got.Serial == "" {
+		t.Errorf("got SSH receipt %+v", got)
+	}
+
+	// Without a receipt key, no receipt is returned.
+	ss.ReceiptKey = ""
+	sshResp, err = ss.PostUserSSHCertificate(context.Background(), &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+		PublicKey:  testGoodRsaPubKey,
+		Validity:   3600,
+		Principals: []string{"alice"},
+		KeyId:      testGoodKeyID,
+	})
+	if err != nil || sshResp.Receipt != nil {
+		t.Errorf("got receipt %v, err %v without a receipt key, want none", sshResp.GetReceipt(), err)
+	}
+}
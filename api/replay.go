@@ -0,0 +1,114 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReplayStore records the digests signed by the keys configured with a replay window, so that a key does not
+// sign the same digest twice within its window. A store shared by the crypki replicas detects the replays
+// across them instead of per replica.
+type ReplayStore interface {
+	// Record atomically records the digest as signed by the specified key for the window. It returns false
+	// if the digest is already recorded for the key within the window.
+	Record(keyIdentifier string, digest []byte, window time.Duration) (bool, error)
+	// Forget removes the record of the digest for the specified key, whose signing failed.
+	Forget(keyIdentifier string, digest []byte) error
+}
+
+// replaySweepInterval is the interval at which MemoryReplayStore removes its expired records.
+const replaySweepInterval = time.Minute
+
+// MemoryReplayStore is a ReplayStore holding the records in memory, i.e. per replica.
+type MemoryReplayStore struct {
+	mu sync.Mutex
+	// expiries maps the records to the time they expire.
+	expiries map[string]time.Time
+	swept    time.Time
+	// now returns the current time; it is time.Now if nil.
+	now func() time.Time
+}
+
+// replayRecord returns the name of the record of the digest for the key.
+func replayRecord(keyIdentifier string, digest []byte) string {
+	return keyIdentifier + "/" + hex.EncodeToString(digest)
+}
+
+// Record records the digest as signed by the key for the window.
+func (m *MemoryReplayStore) Record(keyIdentifier string, digest []byte, window time.Duration) (bool, error) {
+	now := time.Now()
+	if m.now != nil {
+		now = m.now()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.expiries == nil {
+		m.expiries = make(map[string]time.Time)
+	}
+	if now.Sub(m.swept) >= replaySweepInterval {
+		for record, expiry := range m.expiries {
+			if !now.Before(expiry) {
+				delete(m.expiries, record)
+			}
+		}
+		m.swept = now
+	}
+	record := replayRecord(keyIdentifier, digest)
+	if expiry, ok := m.expiries[record]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	m.expiries[record] = now.Add(window)
+	return true, nil
+}
+
+// Forget removes the record of the digest for the key.
+func (m *MemoryReplayStore) Forget(keyIdentifier string, digest []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.expiries, replayRecord(keyIdentifier, digest))
+	return nil
+}
+
+// checkReplay records the digest as signed by the key if the key has a replay window, and returns an
+// AlreadyExists status error if the key already signed it within the window. If the ReplayStore fails,
+// the digest is not signed. It returns the HTTP status code of the error.
+func (s *SigningService) checkReplay(identifier string, digest []byte) (int, error) {
+	windowMs := s.Keys[identifier].ReplayWindowMs
+	if windowMs == 0 {
+		return 0, nil
+	}
+	if s.ReplayStore == nil {
+		err := fmt.Errorf("replay store is not initialized for key %q", identifier)
+		return http.StatusInternalServerError, s.internalError(err)
+	}
+	recorded, err := s.ReplayStore.Record(identifier, digest, time.Duration(windowMs)*time.Millisecond)
+	if err != nil {
+		return http.StatusServiceUnavailable, status.Errorf(codes.Unavailable, "Service unavailable: unable to check digest replay: %v", err)
+	}
+	if !recorded {
+		err = fmt.Errorf("digest already signed by key %q within its replay window", identifier)
+		return http.StatusConflict, status.Errorf(codes.AlreadyExists, "Already exists: %v", err)
+	}
+	return 0, nil
+}
+
+// forgetReplay removes the record of the digest signed by the key if the key has a replay window,
+// so that a digest whose signing failed can be signed again.
+func (s *SigningService) forgetReplay(identifier string, digest []byte) {
+	if s.Keys[identifier].ReplayWindowMs == 0 || s.ReplayStore == nil {
+		return
+	}
+	if err := s.ReplayStore.Forget(identifier, digest); err != nil {
+		log.Printf("unable to forget digest replay record of key %q: %v", identifier, err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockFailingReplayStore is a ReplayStore that always fails.
+type mockFailingReplayStore struct{}
+
+func (m *mockFailingReplayStore) Record(keyIdentifier string, digest []byte, window time.Duration) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func (m *mockFailingReplayStore) Forget(keyIdentifier string, digest []byte) error {
+	return nil
+}
+
+func TestPostSignBlobReplay(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	store := &MemoryReplayStore{now: func() time.Time { return now }}
+	ss := &SigningService{
+		CertSign:       &mockGoodCertSign{},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      map[string]map[string]bool{config.BlobEndpoint: {"blobid1": true, "blobid2": true}},
+		Keys: map[string]config.KeyConfig{
+			"blobid1": {Identifier: "blobid1", ReplayWindowMs: 60000},
+		},
+		ReplayStore: store,
+	}
+	signBlob := func(identifier string) error {
+		_, err := ss.PostSignBlob(context.Background(), &proto.BlobSigningRequest{
+			KeyMeta:       &proto.KeyMeta{Identifier: identifier},
+			Digest:        testSHA512Digest,
+			HashAlgorithm: proto.HashAlgo_SHA512,
+		})
+		return err
+	}
+
+	if err := signBlob("blobid1"); err != nil {
+		t.Fatalf("unable to sign digest: %v", err)
+	}
+	now = now.Add(59 * time.Second)
+	if err := signBlob("blobid1"); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("got %v for a digest signed again within the replay window, want AlreadyExists", err)
+	}
+	now = now.Add(2 * time.Second)
+	if err := signBlob("blobid1"); err != nil {
+		t.Errorf("unable to sign digest again after the replay window: %v", err)
+	}
+
+	// The keys without a replay window sign the same digest repeatedly.
+	for i := 0; i < 2; i++ {
+		if err := signBlob("blobid2"); err != nil {
+			t.Errorf("unable to sign digest again with a key without replay window: %v", err)
+		}
+	}
+
+	ss.ReplayStore = &mockFailingReplayStore{}
+	if err := signBlob("blobid1"); status.Code(err) != codes.Unavailable {
+		t.Errorf("got %v with a failing replay store, want Unavailable", err)
+	}
+}
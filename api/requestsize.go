@@ -0,0 +1,44 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestSizeLimits rejects the requests larger than the maximum size of their endpoint, which is tighter than
+// the message size limit of the gRPC transport for the endpoints whose requests are small, such as the blob
+// endpoint whose requests carry a digest, so that oversized requests fail before being decoded any further.
+type RequestSizeLimits struct {
+	// Endpoints maps the endpoints to the maximum size in bytes of the wire encoding of their requests.
+	// The requests of the endpoints without a maximum are not limited.
+	Endpoints map[string]int
+	// Rejected, if set, is called with the full method name and the request of each rejected request.
+	Rejected func(method string, req interface{})
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor returning InvalidArgument for the requests
+// larger than the maximum size of their endpoint.
+func (r *RequestSizeLimits) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		endpoint := endpointOf(info.FullMethod)
+		max := r.Endpoints[endpoint]
+		msg, ok := req.(protobuf.Message)
+		if max <= 0 || !ok {
+			return handler(ctx, req)
+		}
+		if size := protobuf.Size(msg); size > max {
+			if r.Rejected != nil {
+				r.Rejected(info.FullMethod, req)
+			}
+			return nil, status.Errorf(codes.InvalidArgument, "Bad request: request of %d bytes exceeds the maximum size of %d bytes of %q", size, max, endpoint)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequestSizeLimits(t *testing.T) {
+	t.Parallel()
+	const size = 20000
+	rejected := 0
+	limits := &RequestSizeLimits{
+		Endpoints: map[string]int{config.BlobEndpoint: 16384, config.X509CertEndpoint: 65536},
+		Rejected:  func(method string, req interface{}) { rejected++ },
+	}
+	interceptor := limits.UnaryServerInterceptor()
+	blob := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, HashAlgorithm: proto.HashAlgo_SHA512}
+	blob.Digest = strings.Repeat("a", size-protobuf.Size(blob)-4)
+	x509 := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}}
+	x509.Csr = strings.Repeat("a", size-protobuf.Size(x509)-4)
+	if protobuf.Size(blob) != size || protobuf.Size(x509) != size {
+		t.Fatalf("got requests of %d and %d bytes, want %d bytes", protobuf.Size(blob), protobuf.Size(x509), size)
+	}
+
+	testcases := map[string]struct {
+		method       string
+		req          interface{}
+		expectedCode codes.Code
+	}{
+		"oversized-blob": {"/v3.Signing/PostSignBlob", blob, codes.InvalidArgument},
+		"same-size-x509": {"/v3.Signing/PostX509Certificate", x509, codes.OK},
+		"unlimited":      {"/v3.Signing/PostHostSSHCertificate", &proto.SSHCertificateSigningRequest{PublicKey: blob.Digest}, codes.OK},
+	}
+	for label, tt := range testcases {
+		called := false
+		info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+		_, err := interceptor(context.Background(), tt.req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+		if got := status.Code(err); got != tt.expectedCode {
+			t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+		}
+		if called != (tt.expectedCode == codes.OK) {
+			t.Errorf("in test %v: got handler called: %v, want %v", label, called, tt.expectedCode == codes.OK)
+		}
+	}
+	if rejected != 1 {
+		t.Errorf("got %d rejected requests, want 1", rejected)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requiredFieldTypes maps the endpoints supporting RequiredFields to the type of the value whose fields are
+// required: the signing request, or the x509 certificate composed from the CSR of the request.
+var requiredFieldTypes = map[string]reflect.Type{
+	config.SSHUserCertEndpoint: reflect.TypeOf(proto.SSHCertificateSigningRequest{}),
+	config.SSHHostCertEndpoint: reflect.TypeOf(proto.SSHCertificateSigningRequest{}),
+	config.X509CertEndpoint:    reflect.TypeOf(x509.Certificate{}),
+	config.BlobEndpoint:        reflect.TypeOf(proto.BlobSigningRequest{}),
+}
+
+// RequiredFields maps the endpoints to the fields their requests must set, as dot-separated paths of
+// Go field names, such as "KeyId" for the SSH certificate requests or "Subject.OrganizationalUnit"
+// for the x509 certificates composed from the CSRs.
+type RequiredFields map[string][]string
+
+// NewRequiredFields returns the RequiredFields of the endpoints, or an error if an endpoint does not
+// support them or a path does not name an exported field.
+func NewRequiredFields(fields map[string][]string) (RequiredFields, error) {
+	for endpoint, paths := range fields {
+		t, ok := requiredFieldTypes[endpoint]
+		if !ok {
+			return nil, fmt.Errorf("endpoint %q does not support required fields", endpoint)
+		}
+		for _, path := range paths {
+			if err := checkFieldPath(t, path); err != nil {
+				return nil, fmt.Errorf("invalid required field %q of endpoint %q: %v", path, endpoint, err)
+			}
+		}
+	}
+	return RequiredFields(fields), nil
+}
+
+// checkFieldPath returns an error if the path does not name an exported field of the struct type.
+func checkFieldPath(t reflect.Type, path string) error {
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return fmt.Errorf("%s is not a struct", t)
+		}
+		f, ok := t.FieldByName(name)
+		if !ok || f.PkgPath != "" {
+			return fmt.Errorf("%s has no field %s", t, name)
+		}
+		t = f.Type
+	}
+	return nil
+}
+
+// check returns an InvalidArgument error listing the required fields of the endpoint that v does not set.
+func (r RequiredFields) check(endpoint string, v interface{}) error {
+	var missing []string
+	for _, path := range r[endpoint] {
+		if !fieldSet(reflect.ValueOf(v), path) {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) != 0 {
+		return status.Errorf(codes.InvalidArgument, "Bad request: missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// fieldSet returns true if the field at the path of v is set: a non-empty string, slice or map, a non-nil
+// pointer or a non-zero value. A field below a nil pointer is not set.
+func fieldSet(v reflect.Value, path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return false
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByName(name)
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() != 0
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil()
+	}
+	return !reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,99 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewRequiredFields(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		fields      map[string][]string
+		expectError bool
+	}{
+		"ssh":                  {fields: map[string][]string{config.SSHUserCertEndpoint: {"KeyId", "KeyMeta.Identifier"}}},
+		"x509":                 {fields: map[string][]string{config.X509CertEndpoint: {"Subject.OrganizationalUnit", "DNSNames"}}},
+		"unknown-field":        {fields: map[string][]string{config.SSHHostCertEndpoint: {"KeyID"}}, expectError: true},
+		"field-of-non-struct":  {fields: map[string][]string{config.SSHHostCertEndpoint: {"KeyId.Length"}}, expectError: true},
+		"unsupported-endpoint": {fields: map[string][]string{config.TimestampEndpoint: {"Digest"}}, expectError: true},
+	}
+	for label, tt := range testcases {
+		if _, err := NewRequiredFields(tt.fields); (err != nil) != tt.expectError {
+			t.Errorf("in test %v: got err %v, expectError %v", label, err, tt.expectError)
+		}
+	}
+}
+
+func TestPostCertificateRequiredFields(t *testing.T) {
+	t.Parallel()
+	required, err := NewRequiredFields(map[string][]string{
+		config.SSHUserCertEndpoint: {"KeyId", "Principals"},
+		config.X509CertEndpoint:    {"Subject.OrganizationalUnit"},
+	})
+	if err != nil {
+		t.Fatalf("unable to load required fields: %v", err)
+	}
+	ss := &SigningService{CertSign: &mockRecordingCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, RequiredFields: required}
+	testcases := map[string]struct {
+		keyID           string
+		principals      []string
+		expectedCode    codes.Code
+		expectedMissing string
+	}{
+		"all-set":        {keyID: testGoodKeyID, principals: []string{"alice"}, expectedCode: codes.OK},
+		"missing-key-id": {principals: []string{"alice"}, expectedCode: codes.InvalidArgument, expectedMissing: "KeyId"},
+		"missing-both":   {expectedCode: codes.InvalidArgument, expectedMissing: "KeyId, Principals"},
+	}
+	for label, tt := range testcases {
+		_, err := ss.PostUserSSHCertificate(context.Background(), &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   3600,
+			Principals: tt.principals,
+			KeyId:      tt.keyID,
+		})
+		if got := status.Code(err); got != tt.expectedCode {
+			t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "missing required fields: "+tt.expectedMissing) {
+			t.Errorf("in test %v: got err %v, want the missing fields %s", label, err, tt.expectedMissing)
+		}
+	}
+
+	// The subject of testGoodcsrRsa has an organizational unit.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "foo.example.com"}}, key)
+	if err != nil {
+		t.Fatalf("unable to create CSR: %v", err)
+	}
+	noOUCSR := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	for csr, expectedCode := range map[string]codes.Code{testGoodcsrRsa: codes.OK, noOUCSR: codes.InvalidArgument} {
+		_, err = ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+			KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+			Csr:      csr,
+			Validity: 3600,
+		})
+		if got := status.Code(err); got != expectedCode {
+			t.Errorf("x509: got code %v, want %v, err: %v", got, expectedCode, err)
+		}
+	}
+}
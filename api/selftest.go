@@ -0,0 +1,150 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yahoo/crypki"
+)
+
+// selfTestDigest is the digest signed by the self-test probes.
+var selfTestDigest = sha256.Sum256([]byte("crypki self-test"))
+
+// SelfTest periodically probes the keys, so that the broken keys are reported before the clients' requests
+// fail. The probes of a sweep run in parallel, at most Concurrency at a time, so that a sweep of many keys
+// fits within the interval.
+type SelfTest struct {
+	// Probe returns an error if the key is broken.
+	Probe func(identifier string) error
+	// Identifiers are the keys probed by each sweep.
+	Identifiers []string
+	// Concurrency is the maximum number of probes running at a time. If not positive, the keys are
+	// probed one at a time.
+	Concurrency int
+
+	mu sync.RWMutex
+	// failed maps the keys whose probe failed in the last sweep to their errors.
+	failed map[string]error
+}
+
+// Sweep probes all the keys and returns the errors of the failed probes mapped by key.
+func (t *SelfTest) Sweep() map[string]error {
+	concurrency := t.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var mu sync.Mutex
+	failed := make(map[string]error)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, id := range t.Identifiers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := t.Probe(id); err != nil {
+				mu.Lock()
+				failed[id] = err
+				mu.Unlock()
+			}
+		}(id)
+	}
+	wg.Wait()
+	t.mu.Lock()
+	t.failed = failed
+	t.mu.Unlock()
+	return failed
+}
+
+// Run sweeps the keys every interval, forever. A sweep starts an interval after the start of the previous
+// one, or right after it if it took longer.
+func (t *SelfTest) Run(interval time.Duration) {
+	for {
+		start := time.Now()
+		failed := t.Sweep()
+		elapsed := time.Since(start)
+		var ids []string
+		for id := range failed {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			log.Printf("self-test of key %q failed: %v", id, failed[id])
+		}
+		if elapsed > interval {
+			log.Printf("self-test of %d keys took %v, longer than the interval of %v", len(t.Identifiers), elapsed, interval)
+		}
+		time.Sleep(interval - elapsed)
+	}
+}
+
+// Failed returns the error of the probe of the key in the last sweep, or nil if it succeeded or was not run.
+// It is nil-safe.
+func (t *SelfTest) Failed(identifier string) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.failed[identifier]
+}
+
+// ProbeKey signs a test digest with the key, and verifies the signature if the public key of the key can
+// be fetched.
+func (s *SigningService) ProbeKey(identifier string) error {
+	var opts crypto.SignerOpts = crypto.SHA256
+	if s.Keys[identifier].KeyType == crypki.Ed25519 {
+		opts = crypto.Hash(0)
+	}
+	signature, err := s.Sign(selfTestDigest[:], opts, identifier)
+	if err != nil {
+		return fmt.Errorf("unable to sign: %v", err)
+	}
+	getter, ok := s.CertSign.(crypki.PublicKeyGetter)
+	if !ok {
+		return nil
+	}
+	pub, err := getter.PublicKey(identifier)
+	if err != nil {
+		return fmt.Errorf("unable to get public key: %v", err)
+	}
+	return verifySelfTestSignature(pub, signature)
+}
+
+// verifySelfTestSignature verifies the signature of the test digest with the public key.
+func verifySelfTestSignature(pub crypto.PublicKey, signature []byte) error {
+	digest := selfTestDigest[:]
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		// The signature is PKCS #1 v1.5 or, for the keys restricted to it, PSS.
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil || rsa.VerifyPSS(key, crypto.SHA256, digest, signature, nil) == nil {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(key, digest, signature) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(key, digest, signature) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return errors.New("signature does not match the public key")
+}
@@ -0,0 +1,154 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+)
+
+func TestSelfTestSweep(t *testing.T) {
+	t.Parallel()
+	const keys, concurrency, latency = 200, 20, 20 * time.Millisecond
+	var identifiers []string
+	for i := 0; i < keys; i++ {
+		identifiers = append(identifiers, fmt.Sprintf("key%d", i))
+	}
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	selfTest := &SelfTest{
+		Identifiers: identifiers,
+		Concurrency: concurrency,
+		Probe: func(identifier string) error {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(latency)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			if identifier == "key7" {
+				return errors.New("CKR_DEVICE_ERROR")
+			}
+			return nil
+		},
+	}
+
+	start := time.Now()
+	failed := selfTest.Sweep()
+	// A sequential sweep would take keys*latency, i.e. 4s.
+	if elapsed, bound := time.Since(start), keys/concurrency*latency+time.Second; elapsed > bound {
+		t.Errorf("got a sweep of %d keys in %v, want it within %v", keys, elapsed, bound)
+	}
+	if maxRunning > concurrency {
+		t.Errorf("got %d probes running at a time, want at most %d", maxRunning, concurrency)
+	}
+	if len(failed) != 1 || failed["key7"] == nil {
+		t.Errorf("got failed probes %v, want only key7", failed)
+	}
+	if selfTest.Failed("key7") == nil || selfTest.Failed("key8") != nil {
+		t.Errorf("got results key7: %v, key8: %v, want only key7 failed", selfTest.Failed("key7"), selfTest.Failed("key8"))
+	}
+	var nilSelfTest *SelfTest
+	if err := nilSelfTest.Failed("key7"); err != nil {
+		t.Errorf("got %v from a nil SelfTest, want nil", err)
+	}
+}
+
+func TestProbeKey(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate Ed25519 key: %v", err)
+	}
+	keys := map[string]config.KeyConfig{
+		"blobid1": {Identifier: "blobid1", KeyType: crypki.RSA},
+		"blobid2": {Identifier: "blobid2", KeyType: crypki.Ed25519},
+	}
+	testcases := map[string]struct {
+		certSign    crypki.CertSign
+		identifier  string
+		expectError bool
+	}{
+		"rsa": {
+			certSign:   &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &rsaKey.PublicKey}}}, rsaKey},
+			identifier: "blobid1",
+		},
+		"ed25519": {
+			certSign:   &mockEd25519CertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid2": edPub}}, edKey},
+			identifier: "blobid2",
+		},
+		"wrong-public-key": {
+			certSign:    &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{keys: map[string]crypto.PublicKey{"blobid1": &otherKey.PublicKey}}}, rsaKey},
+			identifier:  "blobid1",
+			expectError: true,
+		},
+		"no-public-key": {
+			certSign:    &mockRSACertSign{mockPEMCertSign{mockPublicKeyCertSign{}}, rsaKey},
+			identifier:  "blobid1",
+			expectError: true,
+		},
+		"sign-error": {
+			certSign:    &mockBadCertSign{},
+			identifier:  "blobid1",
+			expectError: true,
+		},
+		"no-public-key-getter": {
+			certSign:   &mockGoodCertSign{},
+			identifier: "blobid1",
+		},
+	}
+	for label, tt := range testcases {
+		ss := &SigningService{CertSign: tt.certSign, Keys: keys}
+		if err := ss.ProbeKey(tt.identifier); (err != nil) != tt.expectError {
+			t.Errorf("in test %v: got error %v, expect error: %v", label, err, tt.expectError)
+		}
+	}
+}
+
+func TestSelfTestKeyHealth(t *testing.T) {
+	t.Parallel()
+	selfTest := &SelfTest{
+		Identifiers: []string{"blobid1", "blobid2"},
+		Probe: func(identifier string) error {
+			if identifier == "blobid1" {
+				return errors.New("CKR_DEVICE_ERROR")
+			}
+			return nil
+		},
+	}
+	ss := &SigningService{SelfTest: selfTest}
+	if got := ss.keyHealth("blobid1"); got != proto.KeyHealth_HEALTHY {
+		t.Errorf("got health %v before the first sweep, want HEALTHY", got)
+	}
+	selfTest.Sweep()
+	if got := ss.keyHealth("blobid1"); got != proto.KeyHealth_DEGRADED {
+		t.Errorf("got health %v after a failed probe, want DEGRADED", got)
+	}
+	if got := ss.keyHealth("blobid2"); got != proto.KeyHealth_HEALTHY {
+		t.Errorf("got health %v after a successful probe, want HEALTHY", got)
+	}
+}
@@ -4,12 +4,27 @@
 package api
 
 import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/sshcert"
+	"github.com/yahoo/crypki/x509cert"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // SigningService implements proto.SigningServer interface.
@@ -18,6 +33,168 @@ type SigningService struct {
 	crypki.KeyIDProcessor
 	KeyUsages   map[string]map[string]bool
 	MaxValidity map[string]uint64
+	// MinValidity maps the endpoints to the minimum validity period in seconds of their certificates.
+	MinValidity map[string]uint64
+	// RejectDuplicateNames is the set of endpoints that reject requests with duplicate
+	// principals or SANs instead of removing the duplicates.
+	RejectDuplicateNames map[string]bool
+	// AllowEmptyPrincipals is the set of endpoints that sign SSH user certificates without principals.
+	AllowEmptyPrincipals map[string]bool
+	// MaxSSHCertOptions is the maximum number of critical options and extensions of an SSH certificate
+	// request, and MaxSSHCertOptionsSize the maximum total size in bytes of their names and values.
+	// Zero means no limit.
+	MaxSSHCertOptions, MaxSSHCertOptionsSize int
+	// MaxBlobBatchSize is the maximum number of entries of a batch signing request. Zero means no limit.
+	MaxBlobBatchSize int
+	// MaxBlobBatchStreamSize is the maximum number of entries of a streamed batch signing request.
+	// Zero means that streamed batch signing is disabled.
+	MaxBlobBatchStreamSize int
+	// DeriveKeyID is the set of endpoints that derive the key ID of the SSH certificates from the caller.
+	DeriveKeyID map[string]bool
+	// Keys maps key identifiers to their configurations.
+	Keys map[string]config.KeyConfig
+	// Endpoints tracks the endpoints disabled at runtime. If nil, all endpoints are enabled.
+	Endpoints *EndpointState
+	// AdminIdentities is the set of client identities allowed to call the Admin service.
+	AdminIdentities map[string]bool
+	// RetryInvalidSignatures specifies whether a blob signing request is retried once
+	// if the HSM returns an implausible signature.
+	RetryInvalidSignatures bool
+	// AllowZeroDigests specifies whether empty and all-zero digests are signed. By default they are
+	// rejected, as they are the sign of a client bug rather than the output of a hash function.
+	AllowZeroDigests bool
+	// RejectLowEntropyDigests specifies whether digests made of implausibly few distinct byte values are rejected.
+	RejectLowEntropyDigests bool
+	// StrictBlobDigests specifies whether the hash algorithm and the digest length of all blob signing
+	// requests are checked against the key type, instead of those of Ed25519 keys only.
+	StrictBlobDigests bool
+	// Breakers tracks the signing failures of each key. If nil, no circuit breaker is used.
+	Breakers *CircuitBreakers
+	// SelfTest periodically probes the keys, whose failed probes degrade their health. If nil, the
+	// keys are not self-tested.
+	SelfTest *SelfTest
+	// PublicKeyCache caches the responses of the public key endpoints. If nil, nothing is cached.
+	PublicKeyCache *PublicKeyCache
+	// Fingerprints indexes the public key fingerprints of the keys, including those generated or reloaded
+	// at runtime. If nil, the keys generated or reloaded at runtime are not indexed.
+	Fingerprints *FingerprintIndex
+	// Transitions tracks the keys being (re)loaded. If nil, no key is considered transitioning.
+	Transitions *KeyTransitions
+	// IssuanceLog retains the metadata of the recently issued certificates. If nil, nothing is retained.
+	IssuanceLog *IssuanceLog
+	// Usage counts the sign operations of each key. If nil, nothing is counted.
+	Usage *UsageCounters
+	// VerboseErrors specifies whether the status of an internal error includes the underlying error.
+	// By default a generic message is returned. The error is logged in both cases.
+	VerboseErrors bool
+	// RedactLogs specifies whether the blob digests and x509 serial numbers in the request log lines
+	// are replaced by their truncated SHA256 hashes, which still correlate the log lines of a value
+	// without disclosing it. The issuance log keeps the full serial numbers.
+	RedactLogs bool
+	// LogSignerOpts specifies whether the signer options resolved for each blob signing request, i.e. its
+	// hash, signature scheme, salt length and signature encoding, are logged after its policies and defaults
+	// are applied.
+	LogSignerOpts bool
+	// SerialStore reserves the serial numbers of x509 certificates before they are signed.
+	// If nil, serial numbers are not reserved.
+	SerialStore crypki.SerialStore
+	// IssuanceStore records the x509 and SSH certificates once they are signed. If nil, the certificates
+	// are not recorded.
+	IssuanceStore crypki.IssuanceStore
+	// ReplayStore records the digests signed by the keys configured with ReplayWindowMs.
+	// If nil, such keys cannot sign blobs.
+	ReplayStore ReplayStore
+	// NotBeforeWatermarks tracks the latest notBefore of the certificates issued by the keys configured
+	// with MonotonicNotBefore. If nil, such keys cannot issue certificates.
+	NotBeforeWatermarks *NotBeforeWatermarks
+	// SSHSerialCounters hands out the serial numbers of the SSH certificates signed by the keys configured
+	// with SSHSerialCounter. If nil, such keys cannot issue SSH certificates.
+	SSHSerialCounters *SSHSerialCounters
+	// CTLogs maps key identifiers to the certificate transparency logs whose SCTs are embedded
+	// in the x509 certificates signed by the keys.
+	CTLogs map[string][]*x509cert.CTLog
+	// X509CrossSignedCACerts maps the key identifiers to the chains of the cross-signed certificates of their
+	// x509 CAs, which the x509 certificate signing requests may select as their issuers.
+	X509CrossSignedCACerts map[string][][]*x509.Certificate
+	// KeyGenerator generates new keys in the HSM. If nil, key generation is not supported.
+	KeyGenerator crypki.KeyGenerator
+	// KeyGenerationIdentities is the set of admin identities allowed to generate new keys.
+	KeyGenerationIdentities map[string]bool
+	// EphemeralSigner signs with one-time keys generated in the HSM. If nil, ephemeral signing is not supported.
+	EphemeralSigner crypki.EphemeralSigner
+	// EphemeralKeys maps the identifiers of the configurations of the one-time keys to the configurations.
+	EphemeralKeys map[string]config.EphemeralKeyConfig
+	// MutatingWebhook reviews and may modify the x509 certificates before they are signed. If nil,
+	// the certificates are signed as composed from the requests.
+	MutatingWebhook *MutatingWebhook
+	// Now returns the current time against which the signing windows of the keys are checked. If nil,
+	// time.Now is used.
+	Now func() time.Time
+	// ClockDrift, if set, refuses to issue certificates and timestamps while the local clock drifts
+	// too far from a trusted time source.
+	ClockDrift *ClockDriftMonitor
+	// RequiredFields are the fields the requests of the endpoints must set.
+	RequiredFields RequiredFields
+	// BlobSigningCerts maps the identifiers of the blob signing keys to their PEM encoded signing
+	// certificates, followed by their chains.
+	BlobSigningCerts map[string]string
+	// SubjectKeyDenyList is the list of the subject keys that are never certified. If nil, no key is denied.
+	SubjectKeyDenyList *SubjectKeyDenyList
+	// CallerPermittedDomains maps caller identities to the domains of the DNS SANs and subject common names
+	// of their x509 certificates. The callers without an entry are restricted to the domains of the
+	// config.DefaultCaller entry, and denied if there is none. If empty, no caller is restricted.
+	CallerPermittedDomains map[string][]string
+	// CallerSignatureSchemes maps caller identities to the set of the blob signature schemes they may use.
+	// The callers without an entry are not restricted.
+	CallerSignatureSchemes map[string]map[proto.SignatureScheme]bool
+	// CallerSSHValidities maps caller identities to the validity of their SSH certificates.
+	CallerSSHValidities map[string]config.CallerValidity
+	// ReceiptKey is the identifier of the key signing the receipts of the issued certificates, which is
+	// not used to sign certificates. If empty, no receipt is returned.
+	ReceiptKey string
+	// StreamBytes caps the total number of bytes uploaded by the blob streams in flight. If nil, it is not capped.
+	StreamBytes *StreamBytesLimiter
+	// UnaryInterceptor is the chain of interceptors of the unary requests, through which the PostSignBlob
+	// requests composed from the streamed blobs are passed. If nil, they are signed directly.
+	UnaryInterceptor grpc.UnaryServerInterceptor
+	// Config is the configuration the server was started with, after the defaults were applied, which is
+	// returned redacted by GetEffectiveConfig. If nil, the configuration is not available.
+	Config *config.Config
+}
+
+// methodEndpoints maps the methods of the Signing service to their endpoints.
+var methodEndpoints = map[string]string{
+	"GetX509CertificateAvailableSigningKeys":    config.X509CertEndpoint,
+	"GetX509CACertificate":                      config.X509CertEndpoint,
+	"ExportX509CACertificatePKCS12":             config.X509CertEndpoint,
+	"PostX509Certificate":                       config.X509CertEndpoint,
+	"PreviewX509Certificate":                    config.X509CertEndpoint,
+	"GetUserSSHCertificateAvailableSigningKeys": config.SSHUserCertEndpoint,
+	"GetUserSSHCertificateSigningKey":           config.SSHUserCertEndpoint,
+	"PostUserSSHCertificate":                    config.SSHUserCertEndpoint,
+	"GetHostSSHCertificateAvailableSigningKeys": config.SSHHostCertEndpoint,
+	"GetHostSSHCertificateSigningKey":           config.SSHHostCertEndpoint,
+	"PostHostSSHCertificate":                    config.SSHHostCertEndpoint,
+	"GetBlobAvailableSigningKeys":               config.BlobEndpoint,
+	"GetBlobSigningKey":                         config.BlobEndpoint,
+	"PostSignBlob":                              config.BlobEndpoint,
+	"PostSignBlobStream":                        config.BlobEndpoint,
+	"PostSignBlobBatchStream":                   config.BlobEndpoint,
+	"PostSignBlobCMS":                           config.BlobEndpoint,
+	"PostSignBlobBatch":                         config.BlobEndpoint,
+	"PostTimestamp":                             config.TimestampEndpoint,
+	"PostSignGitObject":                         config.GitEndpoint,
+	"PostSignDNSSEC":                            config.DNSSECEndpoint,
+	"PostSignContainerImage":                    config.ContainerImageEndpoint,
+	"PostSignAttestation":                       config.AttestationEndpoint,
+	"PostEphemeralSignature":                    config.EphemeralEndpoint,
+	"PostIssuanceManifest":                      config.IssuanceManifestEndpoint,
+}
+
+// endpointOf returns the endpoint of a full gRPC method name such as "/v3.Signing/PostSignBlob",
+// or an empty string if the method does not belong to an endpoint.
+func endpointOf(fullMethod string) string {
+	return methodEndpoints[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]
 }
 
 // recoverIfPanicked recovers from panic and logs the error.
@@ -41,8 +218,87 @@ func timeElapsedSince(start time.Time) int64 {
 	return time.Since(start).Nanoseconds() / time.Microsecond.Nanoseconds()
 }
 
+// availableKeys returns the keys of the endpoint that match the filter.
+func (s *SigningService) availableKeys(endpoint string, filter *proto.KeyFilter) []*proto.KeyMeta {
+	var keys []*proto.KeyMeta
+	for id := range s.KeyUsages[endpoint] {
+		key := s.Keys[id]
+		if filter.GetKeyType() != proto.KeyType_Unspecified_KeyType && protoKeyType(key.KeyType) != filter.GetKeyType() {
+			continue
+		}
+		if filter.GetTenant() != "" && key.Tenant != filter.GetTenant() {
+			continue
+		}
+		keyMeta := &proto.KeyMeta{Identifier: id}
+		if filter.GetIncludeHealth() {
+			keyMeta.Health = s.keyHealth(id)
+		}
+		keys = append(keys, keyMeta)
+	}
+	return keys
+}
+
+// maxNotAfter is the latest expiry time of a certificate, as the ASN.1
+// GeneralizedTime of X.509 certificates cannot encode years beyond 9999.
+var maxNotAfter = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+// validity returns the requested validity, or the default validity of the key clamped by maxValidity
+// if the request does not specify one.
+func (s *SigningService) validity(identifier string, requested uint64, maxValidity uint64) uint64 {
+	if requested != 0 {
+		return requested
+	}
+	validity := s.Keys[identifier].DefaultValidity
+	if maxValidity != 0 && validity > maxValidity {
+		validity = maxValidity
+	}
+	return validity
+}
+
+// sshValidity returns the validity of an SSH certificate request of the caller to the endpoint, like validity,
+// and the maximum validity allowed. The CallerSSHValidities of the caller, if any, override the default
+// validity of the key and further limit the maximum validity of the endpoint.
+func (s *SigningService) sshValidity(ctx context.Context, endpoint, identifier string, requested uint64) (uint64, uint64) {
+	maxValidity := s.MaxValidity[endpoint]
+	if len(s.CallerSSHValidities) == 0 {
+		return s.validity(identifier, requested, maxValidity), maxValidity
+	}
+	cv, ok := s.CallerSSHValidities[callerIdentity(ctx)]
+	if !ok {
+		return s.validity(identifier, requested, maxValidity), maxValidity
+	}
+	if cv.MaxValidity != 0 && (maxValidity == 0 || cv.MaxValidity < maxValidity) {
+		maxValidity = cv.MaxValidity
+	}
+	if requested != 0 {
+		return requested, maxValidity
+	}
+	validity := cv.DefaultValidity
+	if validity == 0 {
+		validity = s.Keys[identifier].DefaultValidity
+	}
+	if maxValidity != 0 && validity > maxValidity {
+		validity = maxValidity
+	}
+	return validity, maxValidity
+}
+
+// alignValidity rounds notBefore down and notAfter up, both in seconds since the Unix epoch, to the
+// ValidityGranularity of the key. The times are returned unchanged if the key has no granularity.
+func (s *SigningService) alignValidity(identifier string, notBefore, notAfter uint64) (uint64, uint64) {
+	g := s.Keys[identifier].ValidityGranularity
+	if g <= 1 {
+		return notBefore, notAfter
+	}
+	notBefore -= notBefore % g
+	if r := notAfter % g; r != 0 && notAfter <= uint64(maxNotAfter.Unix()) && g-r <= uint64(maxNotAfter.Unix())-notAfter {
+		notAfter += g - r
+	}
+	return notBefore, notAfter
+}
+
 // checkValidity checks whether the requested `validity` is less than
-// maximum allowed validity.
+// maximum allowed validity, and that the certificate would expire no later than maxNotAfter.
 // Note that validity and maxValidity values are in seconds.
 func checkValidity(validity uint64, maxValidity uint64) error {
 	if validity <= 0 {
@@ -51,5 +307,134 @@ func checkValidity(validity uint64, maxValidity uint64) error {
 	if maxValidity != 0 && maxValidity < validity {
 		return fmt.Errorf("requested validity %v is greater than maximum allowed validity %v", validity, maxValidity)
 	}
+	if limit := uint64(maxNotAfter.Unix() - time.Now().Unix()); validity > limit {
+		return fmt.Errorf("requested validity %v would expire after %v", validity, maxNotAfter.Format(time.RFC3339))
+	}
 	return nil
 }
+
+// checkSSHValidity checks the requested validity of an SSH certificate like checkValidity. The validity
+// ssh.CertTimeInfinity requests a never-expiring certificate, which the key must allow, without maxValidity.
+func (s *SigningService) checkSSHValidity(identifier string, validity uint64, maxValidity uint64) error {
+	if validity != ssh.CertTimeInfinity {
+		return checkValidity(validity, maxValidity)
+	}
+	if !s.Keys[identifier].SSHAllowNeverExpiring {
+		return fmt.Errorf("key %q does not sign never-expiring SSH certificates", identifier)
+	}
+	if maxValidity != 0 {
+		return fmt.Errorf("never-expiring SSH certificate exceeds maximum allowed validity %v", maxValidity)
+	}
+	return nil
+}
+
+// sshTime returns the time of the ValidAfter or ValidBefore of an SSH certificate, where the SSH infinity
+// is the latest expiry time of a certificate.
+func sshTime(t uint64) time.Time {
+	if t > uint64(maxNotAfter.Unix()) {
+		return maxNotAfter
+	}
+	return time.Unix(int64(t), 0)
+}
+
+// checkValidityWindow checks that the final validity period of a certificate is not empty, and that
+// the certificate is valid for at least minValidity seconds from the start of the request, in seconds like
+// the validity of the request. Note that the backdating of notBefore does not count towards the validity.
+func checkValidityWindow(start, notBefore, notAfter time.Time, minValidity uint64) error {
+	if !notAfter.After(notBefore) {
+		return fmt.Errorf("certificate would be valid from %v to %v", notBefore.UTC().Format(time.RFC3339), notAfter.UTC().Format(time.RFC3339))
+	}
+	if validity := notAfter.Sub(start.Truncate(time.Second)); validity < time.Duration(minValidity)*time.Second {
+		return fmt.Errorf("certificate would be valid for %v, less than the minimum validity of %ds", validity.Truncate(time.Second), minValidity)
+	}
+	return nil
+}
+
+// checkSSHCertOptions checks that the critical options and extensions of an SSH certificate request are within
+// MaxSSHCertOptions in number and within MaxSSHCertOptionsSize in total size of their names and values.
+func (s *SigningService) checkSSHCertOptions(request *proto.SSHCertificateSigningRequest) error {
+	count, size := 0, 0
+	for _, options := range []map[string]string{request.GetCriticalOptions(), request.GetExtensions()} {
+		for name, value := range options {
+			count++
+			size += len(name) + len(value)
+		}
+	}
+	if s.MaxSSHCertOptions > 0 && count > s.MaxSSHCertOptions {
+		return fmt.Errorf("%d critical options and extensions exceed the maximum of %d", count, s.MaxSSHCertOptions)
+	}
+	if s.MaxSSHCertOptionsSize > 0 && size > s.MaxSSHCertOptionsSize {
+		return fmt.Errorf("critical options and extensions of %d bytes exceed the maximum of %d bytes", size, s.MaxSSHCertOptionsSize)
+	}
+	return nil
+}
+
+// checkSSHCertExtensions applies the SSHStrictExtensions of the key to the extensions of the SSH certificate:
+// the non-standard extensions are stripped, or make it fail.
+func checkSSHCertExtensions(key config.KeyConfig, cert *ssh.Certificate) error {
+	if key.SSHStrictExtensions == "" {
+		return nil
+	}
+	stripped := sshcert.StripNonStandardExtensions(cert)
+	if len(stripped) == 0 {
+		return nil
+	}
+	if key.SSHStrictExtensions == config.SSHExtensionsReject {
+		return fmt.Errorf("non-standard extensions %q are not allowed", stripped)
+	}
+	log.Printf("stripped non-standard extensions %q of SSH certificate %q", stripped, cert.KeyId)
+	return nil
+}
+
+// redactedHashSize is the number of bytes of the SHA256 hash logged in place of a redacted value.
+const redactedHashSize = 8
+
+// redact returns the value to log in place of v, which is v itself unless RedactLogs is set.
+func (s *SigningService) redact(v string) string {
+	if !s.RedactLogs || v == "" {
+		return v
+	}
+	sum := sha256.Sum256([]byte(v))
+	return "sha256:" + hex.EncodeToString(sum[:redactedHashSize])
+}
+
+// internalError returns the Internal status of err, with err in its message if VerboseErrors is set.
+func (s *SigningService) internalError(err error) error {
+	if s.VerboseErrors && err != nil {
+		return status.Errorf(codes.Internal, "Internal server error: %v", err)
+	}
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+// prioritized returns the CertSign whose signing requests wait for a session of the signing key with the
+// priority until ctx is done, as far as the CertSign supports priorities and contexts.
+func (s *SigningService) prioritized(ctx context.Context, priority proto.Priority) crypki.CertSign {
+	certSign := s.CertSign
+	if p, ok := certSign.(crypki.PrioritizedCertSign); ok {
+		switch priority {
+		case proto.Priority_HIGH:
+			certSign = p.WithPriority(crypki.PriorityHigh)
+		case proto.Priority_LOW:
+			certSign = p.WithPriority(crypki.PriorityLow)
+		}
+	}
+	if c, ok := certSign.(crypki.ContextCertSign); ok {
+		certSign = c.WithContext(ctx)
+	}
+	return certSign
+}
+
+// keySigner is a crypto.Signer signing with the specified key of a crypki.CertSign.
+type keySigner struct {
+	certSign   crypki.CertSign
+	identifier string
+	public     crypto.PublicKey
+}
+
+func (k *keySigner) Public() crypto.PublicKey {
+	return k.public
+}
+
+func (k *keySigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return k.certSign.Sign(digest, opts, k.identifier)
+}
@@ -3,14 +3,27 @@
 package api
 
 import (
+	"context"
 	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/yahoo/crypki"
 	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
 	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -43,10 +56,12 @@ iAJCAf+ezR8UoGvQ0X+OX1rONp0WFtHC6pbE03jVvcOHHKnOKRW1ivU2IpKvXbz8
 UDqinTa6CHKDSjI7RweJHczdBus1AkIB376Ba4qvP5IAp+3JYHA4PDLtSJoTLxrJ
 jfOwXe2T9BIwzSu8EH9adQhFt1tH/yy5KTK3H5556OFOTSzxh2zfLa4=
 -----END CERTIFICATE REQUEST-----`
-	testGoodRsaPubKey   = `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC3qVQkPbXzYzykUomIP9q/ZYyIyKFyYZt/7qZ5fIsnfFBmJAbRMiQCXxKUcM8EkY4MO4d7ujePdLZRwPz+IakOhpvldIXJGgURMNiVbGpXFNS9HHOFnvFdiss6piu7oG9J1cMaR3XqnV5waiYSEQ+w1e/ZGcGFmq4Bc/ubeFU/kPG110pXCT+Ka6nSUI2p7zg7tEH9hOx8oWB1RoVFlPzGr1pO+pwNT6SyPK/pSCTlR3iiv84C70DSh/uKe0Hl3R0i/ebJrHNy5HaIL4hcB7bUXgyko6tU+zOaL0kVjHVNninq/wu9YIG5Q3CL6Or6+RbWkI5b3Rfxh1cuCxHv//RV XX@XX`
-	testGoodDsaPubKey   = `ssh-dss AAAAB3NzaC1kc3MAAACBAJO2OS5J02GNCTRdHkkCKnrAM6ZJkyHsvlixWN+16ahzqZD7ijdQwiIofchTpqAsKgXPLH3OhCMvItDrvsJ56SNbP1RlW9qcPix94Ar4xaiW5kqngf0AallzVO1yjyVA0vtjzGBiM0ShzMGYogj1+jOsjgu2/B/FvGb2gIAc/l1lAAAAFQCZdAPNrWBZ92WeSmgL42iQZqKiwwAAAIAyMcUFJYzB+CDZ5aifPYzWPyrHfi/DhHmiY4pDAjFnZUWB6N+Heo1ovITVPLL7coFwLcv1PCvAJ7H+2BPtx7OMzicfAB2OustgzMfznOeUXVtFvA4jaaBP1x/BTrH4THz3gTg/lr6kBpsb/nHzBCLRXjGxsXV/GLQfVvBqVGQruAAAAIAhD56FQ9iNOMHiK+Lin1tF5f/kHFdUMIO1DRodv2ueBTTgXjcZ28i5KVCEuifQ8e9QFy7Za1NePAc1R0MwDoytyirK4IWFZCn0X1nHd1DRuw+0yxUOwwk/HyjC5myo7wf3ZNcjzBu5Hd56POc6XtIHY88PX8dsGyzKGv5J3ops1A== XXX@X2VD2JLHTDD`
-	testGoodEcdsaPubKey = `ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBGbEX631frylkElDpZzmc2use3n/kCO7WXI07D1DYGutOd2F1ZTAcqCd2jzWzjNurS2Y2rROJP1roeDTAm6p8jI= XXX@XXVD2JLHTDD`
-	testGoodKeyID       = `prins=Bob, crTime=20190329T010015, host=host.XXX.com, reqU=Bob, reqIP=C02VD2JLHTDD, transID=6431f24e, isHWKey=true, touchPolicy=3, isFirefighter=false, isHeadless=false. YahooSSHCA`
+	testGoodRsaPubKey     = `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC3qVQkPbXzYzykUomIP9q/ZYyIyKFyYZt/7qZ5fIsnfFBmJAbRMiQCXxKUcM8EkY4MO4d7ujePdLZRwPz+IakOhpvldIXJGgURMNiVbGpXFNS9HHOFnvFdiss6piu7oG9J1cMaR3XqnV5waiYSEQ+w1e/ZGcGFmq4Bc/ubeFU/kPG110pXCT+Ka6nSUI2p7zg7tEH9hOx8oWB1RoVFlPzGr1pO+pwNT6SyPK/pSCTlR3iiv84C70DSh/uKe0Hl3R0i/ebJrHNy5HaIL4hcB7bUXgyko6tU+zOaL0kVjHVNninq/wu9YIG5Q3CL6Or6+RbWkI5b3Rfxh1cuCxHv//RV XX@XX`
+	testGoodDsaPubKey     = `ssh-dss AAAAB3NzaC1kc3MAAACBAJO2OS5J02GNCTRdHkkCKnrAM6ZJkyHsvlixWN+16ahzqZD7ijdQwiIofchTpqAsKgXPLH3OhCMvItDrvsJ56SNbP1RlW9qcPix94Ar4xaiW5kqngf0AallzVO1yjyVA0vtjzGBiM0ShzMGYogj1+jOsjgu2/B/FvGb2gIAc/l1lAAAAFQCZdAPNrWBZ92WeSmgL42iQZqKiwwAAAIAyMcUFJYzB+CDZ5aifPYzWPyrHfi/DhHmiY4pDAjFnZUWB6N+Heo1ovITVPLL7coFwLcv1PCvAJ7H+2BPtx7OMzicfAB2OustgzMfznOeUXVtFvA4jaaBP1x/BTrH4THz3gTg/lr6kBpsb/nHzBCLRXjGxsXV/GLQfVvBqVGQruAAAAIAhD56FQ9iNOMHiK+Lin1tF5f/kHFdUMIO1DRodv2ueBTTgXjcZ28i5KVCEuifQ8e9QFy7Za1NePAc1R0MwDoytyirK4IWFZCn0X1nHd1DRuw+0yxUOwwk/HyjC5myo7wf3ZNcjzBu5Hd56POc6XtIHY88PX8dsGyzKGv5J3ops1A== XXX@X2VD2JLHTDD`
+	testGoodEcdsaPubKey   = `ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBGbEX631frylkElDpZzmc2use3n/kCO7WXI07D1DYGutOd2F1ZTAcqCd2jzWzjNurS2Y2rROJP1roeDTAm6p8jI= XXX@XXVD2JLHTDD`
+	testRsa1024PubKey     = `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQDQfn93l8Rgy+1VmTpdjazsqXJ5he3sRefAv2yai9oYzgFnK8T40wv0LAEhgkaIqEpN8WQ7YMJyM4IRX7gtzrdFx8Iq+SPBY9fEqIN6KXM83ghTZuQzVWVzPgUg2bCEpwZQyH27K3sCwLZCtygyDAJMVWQ9ibSl7qYZkGgONMYj3Q== XXX@X2VD2JLHTDD`
+	testGoodEd25519PubKey = `ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC5Y39UI8oVlikAXaun9x72E3zFkkstnS43OwfCN1FTK XXX@X2VD2JLHTDD`
+	testGoodKeyID         = `prins=Bob, crTime=20190329T010015, host=host.XXX.com, reqU=Bob, reqIP=C02VD2JLHTDD, transID=6431f24e, isHWKey=true, touchPolicy=3, isFirefighter=false, isHeadless=false. YahooSSHCA`
 )
 
 var (
@@ -71,6 +86,35 @@ var (
 	}
 )
 
+var (
+	// testGoodX509Cert is the PEM encoded certificate returned by mockGoodCertSign.
+	testGoodX509Cert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("good x509 cert")}))
+	// testSHA256Digest and testSHA512Digest are the base64 encoded digests signed in blob signing tests.
+	testSHA256Digest = func() string {
+		sum := sha256.Sum256([]byte("good blob"))
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}()
+	testSHA512Digest = func() string {
+		sum := sha512.Sum512([]byte("good blob"))
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}()
+	// testGoodX509CertFingerprint is the SHA256 fingerprint of the DER bytes of testGoodX509Cert.
+	testGoodX509CertFingerprint = func() string {
+		sum := sha256.Sum256([]byte("good x509 cert"))
+		return hex.EncodeToString(sum[:])
+	}()
+)
+
+// sshFingerprint returns the SHA256 fingerprint of an authorized_keys encoded public key.
+func sshFingerprint(t *testing.T, pubKey string) string {
+	pk, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pubKey))
+	if err != nil {
+		t.Fatalf("unable to parse public key: %v", err)
+	}
+	sum := sha256.Sum256(pk.Marshal())
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
 type mockSigningServiceParam struct {
 	KeyUsages   map[string]map[string]bool
 	MaxValidity map[string]uint64
@@ -112,7 +156,7 @@ func (mgcs *mockGoodCertSign) GetX509CACert(keyIdentifier string) ([]byte, error
 	return []byte("good x509 ca cert"), nil
 }
 func (mgcs *mockGoodCertSign) SignX509Cert(cert *x509.Certificate, keyIdentifier string) ([]byte, error) {
-	return []byte("good x509 cert"), nil
+	return []byte(testGoodX509Cert), nil
 }
 func (mgcs *mockGoodCertSign) GetBlobSigningPublicKey(keyIdentifier string) ([]byte, error) {
 	return []byte("good blob signing key"), nil
@@ -121,6 +165,29 @@ func (mgcs *mockGoodCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIde
 	return []byte("good blob signature"), nil
 }
 
+// mockRecordingCertSign is a mockGoodCertSign that records the last certificates it signed.
+type mockRecordingCertSign struct {
+	mockGoodCertSign
+	sshCert  *ssh.Certificate
+	x509Cert *x509.Certificate
+	opts     crypto.SignerOpts
+}
+
+func (m *mockRecordingCertSign) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byte, error) {
+	m.sshCert = cert
+	return m.mockGoodCertSign.SignSSHCert(cert, keyIdentifier)
+}
+
+func (m *mockRecordingCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	m.opts = opts
+	return m.mockGoodCertSign.Sign(digest, opts, keyIdentifier)
+}
+
+func (m *mockRecordingCertSign) SignX509Cert(cert *x509.Certificate, keyIdentifier string) ([]byte, error) {
+	m.x509Cert = cert
+	return m.mockGoodCertSign.SignX509Cert(cert, keyIdentifier)
+}
+
 // InitMockSigningService initializes a mock signing service which implements mock functions
 func initMockSigningService(mssp mockSigningServiceParam) *SigningService {
 	ss := &SigningService{KeyIDProcessor: &crypki.KeyID{}}
@@ -166,6 +233,21 @@ func TestCheckValidity(t *testing.T) {
 			maxValidity: 3600,
 			expectErr:   true,
 		},
+		"validity beyond year 9999": {
+			validity:    uint64(maxNotAfter.Unix()),
+			maxValidity: 0,
+			expectErr:   true,
+		},
+		"validity of max int64": {
+			validity:    math.MaxInt64,
+			maxValidity: 0,
+			expectErr:   true,
+		},
+		"validity overflowing int64": {
+			validity:    math.MaxUint64,
+			maxValidity: 0,
+			expectErr:   true,
+		},
 	}
 	for name, tt := range table {
 		err := checkValidity(tt.validity, tt.maxValidity)
@@ -181,3 +263,572 @@ func TestCheckValidity(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckValidityWindow(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(1600000000, 0)
+	table := map[string]struct {
+		notBefore, notAfter time.Time
+		minValidity         uint64
+		expectErr           bool
+	}{
+		"valid":           {notBefore: start.Add(-time.Hour), notAfter: start.Add(time.Hour)},
+		"at-minimum":      {notBefore: start.Add(-time.Hour), notAfter: start.Add(time.Hour), minValidity: 3600},
+		"below-minimum":   {notBefore: start.Add(-time.Hour), notAfter: start.Add(time.Hour), minValidity: 3601, expectErr: true},
+		"zero-duration":   {notBefore: start, notAfter: start, expectErr: true},
+		"negative":        {notBefore: start, notAfter: start.Add(-time.Second), expectErr: true},
+		"expired-minimum": {notBefore: start.Add(-time.Hour), notAfter: start.Add(-time.Minute), minValidity: 1, expectErr: true},
+	}
+	for name, tt := range table {
+		err := checkValidityWindow(start, tt.notBefore, tt.notAfter, tt.minValidity)
+		if (err != nil) != tt.expectErr {
+			t.Errorf("in test %v: got err %v, want error: %v", name, err, tt.expectErr)
+		}
+	}
+}
+
+func TestPostCertificateMinValidity(t *testing.T) {
+	t.Parallel()
+	const minValidity = 600
+	minValidities := map[string]uint64{config.X509CertEndpoint: minValidity, config.SSHUserCertEndpoint: minValidity, config.SSHHostCertEndpoint: minValidity}
+	sshRequest := func(id string, validity uint64) *proto.SSHCertificateSigningRequest {
+		return &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: id},
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   validity,
+			Principals: []string{"alice"},
+			KeyId:      testGoodKeyID,
+		}
+	}
+	testcases := map[string]struct {
+		post         func(ss *SigningService) error
+		expectedCode codes.Code
+	}{
+		"x509-at-minimum": {post: func(ss *SigningService) error {
+			_, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: minValidity})
+			return err
+		}},
+		"x509-below-minimum": {post: func(ss *SigningService) error {
+			_, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: minValidity - 1})
+			return err
+		}, expectedCode: codes.InvalidArgument},
+		// The CA certificate of newMockCACertSign expires in an hour.
+		"x509-clamped-below-minimum": {post: func(ss *SigningService) error {
+			ss.MinValidity = map[string]uint64{config.X509CertEndpoint: 7200}
+			ss.Keys = map[string]config.KeyConfig{"x509id1": {Identifier: "x509id1", X509CAExpiry: config.X509CAExpiryClamp}}
+			_, err := ss.PostX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 7200})
+			return err
+		}, expectedCode: codes.InvalidArgument},
+		"ssh-user-at-minimum": {post: func(ss *SigningService) error {
+			_, err := ss.PostUserSSHCertificate(context.Background(), sshRequest("sshuserid1", minValidity))
+			return err
+		}},
+		"ssh-user-below-minimum": {post: func(ss *SigningService) error {
+			_, err := ss.PostUserSSHCertificate(context.Background(), sshRequest("sshuserid1", 60))
+			return err
+		}, expectedCode: codes.InvalidArgument},
+		"ssh-user-zero-duration": {post: func(ss *SigningService) error {
+			ss.MinValidity = nil
+			_, err := ss.PostUserSSHCertificate(context.Background(), sshRequest("sshuserid1", 0))
+			return err
+		}, expectedCode: codes.InvalidArgument},
+		"ssh-host-below-minimum": {post: func(ss *SigningService) error {
+			_, err := ss.PostHostSSHCertificate(context.Background(), sshRequest("sshhostid1", 60))
+			return err
+		}, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, MinValidity: minValidities}
+			if got := status.Code(tt.post(ss)); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v", label, got, tt.expectedCode)
+			}
+			if tt.expectedCode != codes.OK && len(signer.signed) != 0 {
+				t.Errorf("in test %v: certificate below the minimum validity was signed", label)
+			}
+		})
+	}
+}
+
+func TestPostCertificateValidityOverflow(t *testing.T) {
+	t.Parallel()
+	const validity = math.MaxInt64
+	signer := &mockRecordingCertSign{}
+	ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	ctx := context.Background()
+	sshRequest := func(id string) *proto.SSHCertificateSigningRequest {
+		return &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: id},
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   validity,
+			Principals: []string{"alice"},
+			KeyId:      testGoodKeyID,
+		}
+	}
+	testcases := map[string]func() error{
+		"x509": func() error {
+			_, err := ss.PostX509Certificate(ctx, &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      testGoodcsrRsa,
+				Validity: validity,
+			})
+			return err
+		},
+		"ssh-user": func() error {
+			_, err := ss.PostUserSSHCertificate(ctx, sshRequest("sshuserid1"))
+			return err
+		},
+		"ssh-host": func() error {
+			_, err := ss.PostHostSSHCertificate(ctx, sshRequest("sshhostid1"))
+			return err
+		},
+	}
+	for label, post := range testcases {
+		if got := status.Code(post()); got != codes.InvalidArgument {
+			t.Errorf("in test %v: got code %v, want %v", label, got, codes.InvalidArgument)
+		}
+	}
+	if signer.sshCert != nil || signer.x509Cert != nil {
+		t.Errorf("certificate with overflowing validity was signed: ssh %v, x509 %v", signer.sshCert, signer.x509Cert)
+	}
+}
+
+func TestPostCertificateDefaultValidity(t *testing.T) {
+	t.Parallel()
+	// The certificates are backdated by one hour.
+	const backdate = 3600
+	testcases := map[string]struct {
+		requested        uint64
+		defaultValidity  uint64
+		maxValidity      uint64
+		expectedValidity uint64
+		expectedCode     codes.Code
+	}{
+		"default":         {defaultValidity: 3600, expectedValidity: 3600, expectedCode: codes.OK},
+		"clamped-default": {defaultValidity: 90 * 86400, maxValidity: 7200, expectedValidity: 7200, expectedCode: codes.OK},
+		"requested":       {requested: 600, defaultValidity: 3600, expectedValidity: 600, expectedCode: codes.OK},
+		"no-default":      {expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			keys := make(map[string]config.KeyConfig)
+			maxValidity := make(map[string]uint64)
+			for _, id := range []string{"x509id1", "sshuserid1", "sshhostid1"} {
+				keys[id] = config.KeyConfig{Identifier: id, DefaultValidity: tt.defaultValidity}
+			}
+			for _, endpoint := range []string{config.X509CertEndpoint, config.SSHUserCertEndpoint, config.SSHHostCertEndpoint} {
+				maxValidity[endpoint] = tt.maxValidity
+			}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, Keys: keys, MaxValidity: maxValidity}
+			ctx := context.Background()
+			sshRequest := func(id string) *proto.SSHCertificateSigningRequest {
+				return &proto.SSHCertificateSigningRequest{
+					KeyMeta:    &proto.KeyMeta{Identifier: id},
+					PublicKey:  testGoodRsaPubKey,
+					Validity:   tt.requested,
+					Principals: []string{"alice"},
+					KeyId:      testGoodKeyID,
+				}
+			}
+
+			_, err := ss.PostX509Certificate(ctx, &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      testGoodcsrRsa,
+				Validity: tt.requested,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: x509: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err == nil {
+				if got := uint64(signer.x509Cert.NotAfter.Sub(signer.x509Cert.NotBefore).Seconds()) - backdate; got != tt.expectedValidity {
+					t.Errorf("in test %v: x509: got validity %d, want %d", label, got, tt.expectedValidity)
+				}
+			}
+			sshPosts := map[string]func() (*proto.SSHKey, error){
+				"ssh-user": func() (*proto.SSHKey, error) { return ss.PostUserSSHCertificate(ctx, sshRequest("sshuserid1")) },
+				"ssh-host": func() (*proto.SSHKey, error) { return ss.PostHostSSHCertificate(ctx, sshRequest("sshhostid1")) },
+			}
+			for name, post := range sshPosts {
+				signer.sshCert = nil
+				_, err := post()
+				if got := status.Code(err); got != tt.expectedCode {
+					t.Fatalf("in test %v: %s: got code %v, want %v, err: %v", label, name, got, tt.expectedCode, err)
+				}
+				if err == nil {
+					if got := signer.sshCert.ValidBefore - signer.sshCert.ValidAfter - backdate; got != tt.expectedValidity {
+						t.Errorf("in test %v: %s: got validity %d, want %d", label, name, got, tt.expectedValidity)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPostSSHCertificateCallerValidity(t *testing.T) {
+	t.Parallel()
+	// The certificates are backdated by one hour.
+	const backdate = 3600
+	keys := map[string]config.KeyConfig{
+		"sshuserid1": {Identifier: "sshuserid1", DefaultValidity: 3600},
+		"sshhostid1": {Identifier: "sshhostid1", DefaultValidity: 3600},
+	}
+	callerValidities := map[string]config.CallerValidity{
+		"breakglass": {DefaultValidity: 900, MaxValidity: 900},
+		"service":    {DefaultValidity: 12 * 3600},
+		"clamped":    {DefaultValidity: 48 * 3600},
+		"keydefault": {MaxValidity: 7200},
+	}
+	maxValidity := map[string]uint64{config.SSHUserCertEndpoint: 86400, config.SSHHostCertEndpoint: 86400}
+	testcases := map[string]struct {
+		caller           string
+		requested        uint64
+		expectedValidity uint64
+		expectedCode     codes.Code
+	}{
+		"breakglass-default":  {caller: "breakglass", expectedValidity: 900, expectedCode: codes.OK},
+		"service-default":     {caller: "service", expectedValidity: 12 * 3600, expectedCode: codes.OK},
+		"unlisted-caller":     {caller: "other", expectedValidity: 3600, expectedCode: codes.OK},
+		"key-default":         {caller: "keydefault", expectedValidity: 3600, expectedCode: codes.OK},
+		"clamped-by-endpoint": {caller: "clamped", expectedValidity: 86400, expectedCode: codes.OK},
+		"requested":           {caller: "breakglass", requested: 600, expectedValidity: 600, expectedCode: codes.OK},
+		"beyond-caller-max":   {caller: "breakglass", requested: 3600, expectedCode: codes.InvalidArgument},
+		"beyond-endpoint-max": {caller: "service", requested: 2 * 86400, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, Keys: keys, MaxValidity: maxValidity, CallerSSHValidities: callerValidities}
+			ctx := contextWithIdentity(tt.caller)
+			sshRequest := func(id string) *proto.SSHCertificateSigningRequest {
+				return &proto.SSHCertificateSigningRequest{
+					KeyMeta:    &proto.KeyMeta{Identifier: id},
+					PublicKey:  testGoodRsaPubKey,
+					Validity:   tt.requested,
+					Principals: []string{"alice"},
+					KeyId:      testGoodKeyID,
+				}
+			}
+			sshPosts := map[string]func() (*proto.SSHKey, error){
+				"ssh-user": func() (*proto.SSHKey, error) { return ss.PostUserSSHCertificate(ctx, sshRequest("sshuserid1")) },
+				"ssh-host": func() (*proto.SSHKey, error) { return ss.PostHostSSHCertificate(ctx, sshRequest("sshhostid1")) },
+			}
+			for name, post := range sshPosts {
+				signer.sshCert = nil
+				_, err := post()
+				if got := status.Code(err); got != tt.expectedCode {
+					t.Fatalf("in test %v: %s: got code %v, want %v, err: %v", label, name, got, tt.expectedCode, err)
+				}
+				if err == nil {
+					if got := signer.sshCert.ValidBefore - signer.sshCert.ValidAfter - backdate; got != tt.expectedValidity {
+						t.Errorf("in test %v: %s: got validity %d, want %d", label, name, got, tt.expectedValidity)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPostSSHCertificateStrictExtensions(t *testing.T) {
+	t.Parallel()
+	extensions := map[string]string{"permit-pty": "", "permit-user-rc": "", "login@example.com": "alice"}
+	testcases := map[string]struct {
+		mode               string
+		expectedExtensions map[string]string
+		expectedCode       codes.Code
+	}{
+		"off":    {expectedExtensions: extensions, expectedCode: codes.OK},
+		"strip":  {mode: config.SSHExtensionsStrip, expectedExtensions: map[string]string{"permit-pty": "", "permit-user-rc": ""}, expectedCode: codes.OK},
+		"reject": {mode: config.SSHExtensionsReject, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			keys := map[string]config.KeyConfig{
+				"sshuserid1": {Identifier: "sshuserid1", SSHStrictExtensions: tt.mode},
+				"sshhostid1": {Identifier: "sshhostid1", SSHStrictExtensions: tt.mode},
+			}
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, Keys: keys}
+			sshRequest := func(id string, extensions map[string]string) *proto.SSHCertificateSigningRequest {
+				return &proto.SSHCertificateSigningRequest{
+					KeyMeta:    &proto.KeyMeta{Identifier: id},
+					PublicKey:  testGoodRsaPubKey,
+					Validity:   3600,
+					Principals: []string{"alice"},
+					KeyId:      testGoodKeyID,
+					Extensions: extensions,
+				}
+			}
+			sshPosts := map[string]func(map[string]string) (*proto.SSHKey, error){
+				"ssh-user": func(e map[string]string) (*proto.SSHKey, error) {
+					return ss.PostUserSSHCertificate(context.Background(), sshRequest("sshuserid1", e))
+				},
+				"ssh-host": func(e map[string]string) (*proto.SSHKey, error) {
+					return ss.PostHostSSHCertificate(context.Background(), sshRequest("sshhostid1", e))
+				},
+			}
+			for name, post := range sshPosts {
+				signer.sshCert = nil
+				_, err := post(extensions)
+				if got := status.Code(err); got != tt.expectedCode {
+					t.Fatalf("in test %v: %s: got code %v, want %v, err: %v", label, name, got, tt.expectedCode, err)
+				}
+				if err == nil && !reflect.DeepEqual(signer.sshCert.Extensions, tt.expectedExtensions) {
+					t.Errorf("in test %v: %s: got extensions %q, want %q", label, name, signer.sshCert.Extensions, tt.expectedExtensions)
+				}
+				// The standard extensions pass through in all modes.
+				signer.sshCert = nil
+				if _, err := post(map[string]string{"permit-pty": ""}); err != nil {
+					t.Fatalf("in test %v: %s: unable to sign with standard extensions: %v", label, name, err)
+				}
+				if got := signer.sshCert.Extensions; !reflect.DeepEqual(got, map[string]string{"permit-pty": ""}) {
+					t.Errorf("in test %v: %s: got extensions %q, want only permit-pty", label, name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPostSSHCertificateNeverExpiring(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		allow        bool
+		granularity  uint64
+		maxValidity  uint64
+		expectedCode codes.Code
+	}{
+		"default":              {expectedCode: codes.InvalidArgument},
+		"allowed":              {allow: true, expectedCode: codes.OK},
+		"allowed-granularity":  {allow: true, granularity: 60, expectedCode: codes.OK},
+		"allowed-max-validity": {allow: true, maxValidity: 86400, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			keys := map[string]config.KeyConfig{
+				"sshuserid1": {Identifier: "sshuserid1", SSHAllowNeverExpiring: tt.allow, ValidityGranularity: tt.granularity},
+				"sshhostid1": {Identifier: "sshhostid1", SSHAllowNeverExpiring: tt.allow, ValidityGranularity: tt.granularity},
+			}
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           keys,
+				MaxValidity:    map[string]uint64{config.SSHUserCertEndpoint: tt.maxValidity, config.SSHHostCertEndpoint: tt.maxValidity},
+			}
+			sshRequest := func(id string, validity uint64) *proto.SSHCertificateSigningRequest {
+				return &proto.SSHCertificateSigningRequest{
+					KeyMeta:    &proto.KeyMeta{Identifier: id},
+					PublicKey:  testGoodRsaPubKey,
+					Validity:   validity,
+					Principals: []string{"alice"},
+					KeyId:      testGoodKeyID,
+				}
+			}
+			sshPosts := map[string]func(uint64) (*proto.SSHKey, error){
+				"ssh-user": func(v uint64) (*proto.SSHKey, error) {
+					return ss.PostUserSSHCertificate(context.Background(), sshRequest("sshuserid1", v))
+				},
+				"ssh-host": func(v uint64) (*proto.SSHKey, error) {
+					return ss.PostHostSSHCertificate(context.Background(), sshRequest("sshhostid1", v))
+				},
+			}
+			for name, post := range sshPosts {
+				signer.sshCert = nil
+				_, err := post(ssh.CertTimeInfinity)
+				if got := status.Code(err); got != tt.expectedCode {
+					t.Fatalf("in test %v: %s: got code %v, want %v, err: %v", label, name, got, tt.expectedCode, err)
+				}
+				if err == nil && signer.sshCert.ValidBefore != ssh.CertTimeInfinity {
+					t.Errorf("in test %v: %s: got ValidBefore %d, want the SSH infinity", label, name, signer.sshCert.ValidBefore)
+				}
+				if err != nil && signer.sshCert != nil {
+					t.Errorf("in test %v: %s: never-expiring certificate was signed", label, name)
+				}
+				// The certificates with a finite validity are signed regardless.
+				signer.sshCert = nil
+				if _, err := post(3600); err != nil {
+					t.Fatalf("in test %v: %s: unable to sign with a finite validity: %v", label, name, err)
+				}
+				if signer.sshCert.ValidBefore == ssh.CertTimeInfinity {
+					t.Errorf("in test %v: %s: got a never-expiring certificate for a finite validity", label, name)
+				}
+			}
+		})
+	}
+}
+
+func TestInternalErrorVerbosity(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		verbose         bool
+		expectedMessage string
+	}{
+		"strict":  {false, "Internal server error"},
+		"verbose": {true, "Internal server error: bad message"},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: &mockBadCertSign{}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, VerboseErrors: tt.verbose}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600}
+			_, err := ss.PostX509Certificate(context.Background(), request)
+			st, _ := status.FromError(err)
+			if st.Code() != codes.Internal || st.Message() != tt.expectedMessage {
+				t.Errorf("in test %v: got status %v %q, want %v %q", label, st.Code(), st.Message(), codes.Internal, tt.expectedMessage)
+			}
+		})
+	}
+}
+
+// mockSSHRecordingCertSign records the last SSH certificate passed to it for signing.
+type mockSSHRecordingCertSign struct {
+	mockGoodCertSign
+	cert *ssh.Certificate
+}
+
+func (m *mockSSHRecordingCertSign) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byte, error) {
+	m.cert = cert
+	return m.mockGoodCertSign.SignSSHCert(cert, keyIdentifier)
+}
+
+func TestValidityGranularity(t *testing.T) {
+	t.Parallel()
+	const granularity = 60
+	sshSigner := &mockSSHRecordingCertSign{}
+	ss := &SigningService{
+		CertSign:       sshSigner,
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages:      combineKeyUsage,
+		Keys: map[string]config.KeyConfig{
+			"sshuserid1": {Identifier: "sshuserid1", ValidityGranularity: granularity},
+			"sshhostid1": {Identifier: "sshhostid1", ValidityGranularity: granularity},
+		},
+	}
+	sshRequest := func(identifier string) *proto.SSHCertificateSigningRequest {
+		return &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: identifier},
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   3601,
+			Principals: []string{"alice"},
+			KeyId:      testGoodKeyID,
+		}
+	}
+	now := uint64(time.Now().Unix())
+	if _, err := ss.PostUserSSHCertificate(context.Background(), sshRequest("sshuserid1")); err != nil {
+		t.Fatalf("unable to sign SSH user certificate: %v", err)
+	}
+	userCert := sshSigner.cert
+	if _, err := ss.PostHostSSHCertificate(context.Background(), sshRequest("sshhostid1")); err != nil {
+		t.Fatalf("unable to sign SSH host certificate: %v", err)
+	}
+	for _, cert := range []*ssh.Certificate{userCert, sshSigner.cert} {
+		if cert.ValidAfter%granularity != 0 || cert.ValidBefore%granularity != 0 {
+			t.Errorf("got SSH validity [%d, %d], want multiples of %d", cert.ValidAfter, cert.ValidBefore, granularity)
+		}
+		if cert.ValidAfter > now-3600 || cert.ValidBefore < now+3601 {
+			t.Errorf("got SSH validity [%d, %d] narrower than the requested one", cert.ValidAfter, cert.ValidBefore)
+		}
+	}
+
+	ss.CertSign = newMockCACertSign(t)
+	ss.Keys = map[string]config.KeyConfig{"x509id1": {Identifier: "x509id1", ValidityGranularity: granularity}}
+	now = uint64(time.Now().Unix())
+	preview, err := ss.PreviewX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+		KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+		Csr:      testGoodcsrRsa,
+		Validity: 3601,
+	})
+	if err != nil {
+		t.Fatalf("unable to preview x509 certificate: %v", err)
+	}
+	if preview.NotBefore%granularity != 0 || preview.NotAfter%granularity != 0 {
+		t.Errorf("got x509 validity [%d, %d], want multiples of %d", preview.NotBefore, preview.NotAfter, granularity)
+	}
+	if uint64(preview.NotBefore) > now-3600 || uint64(preview.NotAfter) < now+3601 {
+		t.Errorf("got x509 validity [%d, %d] narrower than the requested one", preview.NotBefore, preview.NotAfter)
+	}
+
+	// Without a granularity, the times are not rounded.
+	ss.Keys = nil
+	preview, err = ss.PreviewX509Certificate(context.Background(), &proto.X509CertificateSigningRequest{
+		KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+		Csr:      testGoodcsrRsa,
+		Validity: 3601,
+	})
+	if err != nil {
+		t.Fatalf("unable to preview x509 certificate: %v", err)
+	}
+	if got := preview.NotAfter - preview.NotBefore; got != 3601+3600 {
+		t.Errorf("got validity period %d without a granularity, want %d", got, 3601+3600)
+	}
+}
+
+func TestMaxSSHCertOptions(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{
+		CertSign:              &mockGoodCertSign{},
+		KeyIDProcessor:        &crypki.KeyID{},
+		KeyUsages:             combineKeyUsage,
+		MaxSSHCertOptions:     3,
+		MaxSSHCertOptionsSize: 64,
+	}
+	testcases := map[string]struct {
+		criticalOptions map[string]string
+		extensions      map[string]string
+		expectCode      codes.Code
+	}{
+		"good-at-count-cap": {
+			criticalOptions: map[string]string{"force-command": "ls"},
+			extensions:      map[string]string{"permit-pty": "", "permit-X11-forwarding": ""},
+		},
+		"good-at-size-cap": {
+			// 13 + 51 bytes.
+			criticalOptions: map[string]string{"force-command": strings.Repeat("a", 51)},
+		},
+		"bad-over-count-cap": {
+			criticalOptions: map[string]string{"force-command": "ls"},
+			extensions:      map[string]string{"permit-pty": "", "permit-X11-forwarding": "", "permit-user-rc": ""},
+			expectCode:      codes.InvalidArgument,
+		},
+		"bad-over-size-cap": {
+			criticalOptions: map[string]string{"force-command": strings.Repeat("a", 52)},
+			expectCode:      codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		request := &proto.SSHCertificateSigningRequest{
+			KeyMeta:         &proto.KeyMeta{Identifier: "sshuserid1"},
+			PublicKey:       testGoodRsaPubKey,
+			Validity:        3600,
+			Principals:      []string{"alice"},
+			CriticalOptions: tt.criticalOptions,
+			Extensions:      tt.extensions,
+		}
+		if _, err := ss.PostUserSSHCertificate(context.Background(), request); status.Code(err) != tt.expectCode {
+			t.Errorf("%s: user certificate: got %v, want code %v", label, err, tt.expectCode)
+		}
+		request.KeyMeta.Identifier = "sshhostid1"
+		if _, err := ss.PostHostSSHCertificate(context.Background(), request); status.Code(err) != tt.expectCode {
+			t.Errorf("%s: host certificate: got %v, want code %v", label, err, tt.expectCode)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yahoo/crypki/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// signingWindowLocations caches the locations of the time zones of the signing windows by name, so that
+// the time zone database is not read on every request.
+var signingWindowLocations sync.Map
+
+// signingWindowOpen returns whether the signing window is open at t.
+func signingWindowOpen(w *config.SigningWindow, t time.Time) (bool, error) {
+	loc, ok := signingWindowLocations.Load(w.TimeZone)
+	if !ok {
+		l, err := w.Location()
+		if err != nil {
+			return false, err
+		}
+		loc, _ = signingWindowLocations.LoadOrStore(w.TimeZone, l)
+	}
+	days, err := w.Weekdays()
+	if err != nil {
+		return false, err
+	}
+	t = t.In(loc.(*time.Location))
+	if days != nil && !days[t.Weekday()] {
+		return false, nil
+	}
+	return t.Hour() >= w.StartHour && t.Hour() < w.EndHour, nil
+}
+
+// checkSigningWindow returns a FailedPrecondition error if the key, or the ephemeral key, has a signing window
+// which is closed.
+func (s *SigningService) checkSigningWindow(identifier string) error {
+	w := s.Keys[identifier].SigningWindow
+	if e, ok := s.EphemeralKeys[identifier]; ok {
+		w = e.SigningWindow
+	}
+	if w == nil {
+		return nil
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	open, err := signingWindowOpen(w, now())
+	if err != nil {
+		return s.internalError(fmt.Errorf("signing window of key %q: %v", identifier, err))
+	}
+	if !open {
+		return status.Errorf(codes.FailedPrecondition, "Key %q does not sign outside its signing window", identifier)
+	}
+	return nil
+}
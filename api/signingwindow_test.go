@@ -0,0 +1,79 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSigningWindow(t *testing.T) {
+	t.Parallel()
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("unable to load time zone: %v", err)
+	}
+	window := &config.SigningWindow{Days: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "friday"}, StartHour: 9, EndHour: 17, TimeZone: "America/New_York"}
+	testcases := map[string]struct {
+		window       *config.SigningWindow
+		now          time.Time
+		expectedCode codes.Code
+	}{
+		"inside":            {window: window, now: time.Date(2026, 10, 14, 10, 30, 0, 0, newYork), expectedCode: codes.OK},
+		"opening":           {window: window, now: time.Date(2026, 10, 16, 9, 0, 0, 0, newYork), expectedCode: codes.OK},
+		"closing":           {window: window, now: time.Date(2026, 10, 14, 17, 0, 0, 0, newYork), expectedCode: codes.FailedPrecondition},
+		"early":             {window: window, now: time.Date(2026, 10, 14, 8, 59, 0, 0, newYork), expectedCode: codes.FailedPrecondition},
+		"weekend":           {window: window, now: time.Date(2026, 10, 17, 10, 30, 0, 0, newYork), expectedCode: codes.FailedPrecondition},
+		"inside-in-utc":     {window: window, now: time.Date(2026, 10, 14, 14, 30, 0, 0, time.UTC), expectedCode: codes.OK},
+		"outside-in-utc":    {window: window, now: time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC), expectedCode: codes.FailedPrecondition},
+		"every-day-utc":     {window: &config.SigningWindow{StartHour: 0, EndHour: 24}, now: time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC), expectedCode: codes.OK},
+		"no-signing-window": {now: time.Date(2026, 10, 18, 3, 0, 0, 0, newYork), expectedCode: codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockKeyRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys: map[string]config.KeyConfig{
+					"blobid1":    {Identifier: "blobid1", SigningWindow: tt.window},
+					"sshuserid1": {Identifier: "sshuserid1", SigningWindow: tt.window},
+				},
+				Now: func() time.Time { return tt.now },
+			}
+			_, err := ss.PostSignBlob(context.Background(), &proto.BlobSigningRequest{
+				KeyMeta:       &proto.KeyMeta{Identifier: "blobid1"},
+				Digest:        testSHA512Digest,
+				HashAlgorithm: proto.HashAlgo_SHA512,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got blob code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil && len(signer.ids) != 0 {
+				t.Errorf("in test %v: blob was signed outside the signing window", label)
+			}
+			_, err = ss.PostUserSSHCertificate(context.Background(), &proto.SSHCertificateSigningRequest{
+				KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+				PublicKey:  testGoodRsaPubKey,
+				Validity:   3600,
+				Principals: []string{"alice"},
+				KeyId:      testGoodKeyID,
+			})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got SSH code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// SlowRequestLogger logs the requests that take longer than the threshold of their endpoint, with the
+// time spent in the interceptors, such as waiting for the rate limiter or the pre-sign hook, and in the
+// handler. Faster requests are only logged by the summary line of each method.
+type SlowRequestLogger struct {
+	// Default is the threshold of requests to methods without an endpoint threshold. Zero means no
+	// request is logged as slow.
+	Default time.Duration
+	// Endpoints maps endpoints to their thresholds.
+	Endpoints map[string]time.Duration
+}
+
+// slowLogKey is the context key of the requestTiming of a request.
+type slowLogKey struct{}
+
+// requestTiming records when the handler of a request is called, in Unix nanoseconds. It is accessed
+// atomically, as the handler may still be called after a request timed out.
+type requestTiming struct {
+	handlerStart int64
+}
+
+// threshold returns the threshold of requests to fullMethod.
+func (l *SlowRequestLogger) threshold(fullMethod string) time.Duration {
+	if d, ok := l.Endpoints[endpointOf(fullMethod)]; ok && d > 0 {
+		return d
+	}
+	return l.Default
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor logging the requests slower than their
+// threshold. The time of the requests is measured from this interceptor, so it should be chained before
+// the interceptors whose time is to be accounted for, and HandlerInterceptor should be chained last.
+func (l *SlowRequestLogger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		threshold := l.threshold(info.FullMethod)
+		if threshold <= 0 {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		timing := &requestTiming{}
+		resp, err := handler(context.WithValue(ctx, slowLogKey{}, timing), req)
+		if elapsed := time.Since(start); elapsed > threshold {
+			// Without HandlerInterceptor all the time is accounted to the handler.
+			it := time.Duration(0)
+			if handlerStart := atomic.LoadInt64(&timing.handlerStart); handlerStart != 0 {
+				it = time.Unix(0, handlerStart).Sub(start)
+			}
+			log.Printf(`slow: m=%s,caller=%q,code=%s,et=%d,it=%d,ht=%d,threshold=%d`, info.FullMethod, callerIdentity(ctx), status.Code(err),
+				elapsed/time.Microsecond, it/time.Microsecond, (elapsed-it)/time.Microsecond, threshold/time.Microsecond)
+		}
+		return resp, err
+	}
+}
+
+// HandlerInterceptor returns a grpc.UnaryServerInterceptor recording when the handler of a request is
+// called, for UnaryServerInterceptor to tell the time spent in the interceptors from that in the handler.
+func (l *SlowRequestLogger) HandlerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timing, ok := ctx.Value(slowLogKey{}).(*requestTiming); ok {
+			atomic.StoreInt64(&timing.handlerStart, time.Now().UnixNano())
+		}
+		return handler(ctx, req)
+	}
+}
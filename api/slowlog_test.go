@@ -0,0 +1,60 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+)
+
+func TestSlowRequestLogger(t *testing.T) {
+	l := &SlowRequestLogger{
+		Default:   time.Hour,
+		Endpoints: map[string]time.Duration{config.BlobEndpoint: 50 * time.Millisecond},
+	}
+	testcases := map[string]struct {
+		method    string
+		delay     time.Duration
+		expectLog bool
+	}{
+		"under-threshold":         {method: "PostSignBlob", expectLog: false},
+		"over-threshold":          {method: "PostSignBlob", delay: 100 * time.Millisecond, expectLog: true},
+		"under-default-threshold": {method: "PostX509Certificate", delay: 100 * time.Millisecond, expectLog: false},
+	}
+	for label, tt := range testcases {
+		info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/" + tt.method}
+		// The interceptors wait for half of the delay, and the handler for the other half.
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			time.Sleep(tt.delay / 2)
+			return &proto.Signature{}, nil
+		}
+		waiting := func(ctx context.Context, req interface{}) (interface{}, error) {
+			time.Sleep(tt.delay / 2)
+			return l.HandlerInterceptor()(ctx, req, info, handler)
+		}
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		_, err := l.UnaryServerInterceptor()(contextWithIdentity("alice"), &proto.BlobSigningRequest{}, info, waiting)
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			t.Fatalf("in test %v: unexpected error: %v", label, err)
+		}
+		line := buf.String()
+		if logged := strings.Contains(line, "slow: m="+info.FullMethod); logged != tt.expectLog {
+			t.Fatalf("in test %v: got log %q, expect slow log: %v", label, line, tt.expectLog)
+		}
+		if tt.expectLog && (!strings.Contains(line, `caller="alice"`) || strings.Contains(line, ",it=0,") || !strings.Contains(line, ",threshold=50000")) {
+			t.Errorf("in test %v: got log %q, want the caller, the threshold and the time of the interceptors", label, line)
+		}
+	}
+}
@@ -3,14 +3,19 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"reflect"
 	"sort"
 	"testing"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
 	"github.com/yahoo/crypki/config"
 	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestGetHostSSHCertificateAvailableSigningKeys(t *testing.T) {
@@ -52,8 +57,8 @@ func TestGetHostSSHCertificateAvailableSigningKeys(t *testing.T) {
 			mssp := mockSigningServiceParam{KeyUsages: tt.KeyUsages, sendError: false}
 			ss := initMockSigningService(mssp)
 			var ctx context.Context
-			var e *empty.Empty
-			keyMetas, err := ss.GetHostSSHCertificateAvailableSigningKeys(ctx, e)
+			var filter *proto.KeyFilter
+			keyMetas, err := ss.GetHostSSHCertificateAvailableSigningKeys(ctx, filter)
 			if err != nil {
 				t.Fatal(err)
 				return
@@ -216,15 +221,33 @@ func TestPostHostSSHCertificate(t *testing.T) {
 			PubKey:         testGoodRsaPubKey,
 			KeyID:          testGoodKeyID,
 		},
-		"combineKeyUsagesWithTrueIDAndDsaPubKey": {
+		"combineKeyUsagesWithTrueIDAndDsaPubKeyRejected": {
 			KeyUsages:      combineKeyUsage,
 			maxValidity:    defaultMaxValidity,
 			validity:       3600,
 			KeyMeta:        &proto.KeyMeta{Identifier: "sshhostid1"},
-			expectedSSHKey: &proto.SSHKey{Key: "good ssh cert"},
+			expectedSSHKey: nil,
 			PubKey:         testGoodDsaPubKey,
 			KeyID:          testGoodKeyID,
 		},
+		"combineKeyUsagesWithTrueIDAndRsa1024PubKeyRejected": {
+			KeyUsages:      combineKeyUsage,
+			maxValidity:    defaultMaxValidity,
+			validity:       3600,
+			KeyMeta:        &proto.KeyMeta{Identifier: "sshhostid1"},
+			expectedSSHKey: nil,
+			PubKey:         testRsa1024PubKey,
+			KeyID:          testGoodKeyID,
+		},
+		"combineKeyUsagesWithTrueIDAndEd25519PubKey": {
+			KeyUsages:      combineKeyUsage,
+			maxValidity:    defaultMaxValidity,
+			validity:       3600,
+			KeyMeta:        &proto.KeyMeta{Identifier: "sshhostid1"},
+			expectedSSHKey: &proto.SSHKey{Key: "good ssh cert"},
+			PubKey:         testGoodEd25519PubKey,
+			KeyID:          testGoodKeyID,
+		},
 		"combineKeyUsagesWithTrueIDAndEcPubKey": {
 			KeyUsages:      combineKeyUsage,
 			maxValidity:    defaultMaxValidity,
@@ -317,9 +340,72 @@ func TestPostHostSSHCertificate(t *testing.T) {
 				if err != nil {
 					t.Fatalf("unexpected error for %v, err: %v", label, err)
 				}
-				if !reflect.DeepEqual(cert, tt.expectedSSHKey) {
-					t.Errorf("output doesn't match for %v, got %+v\nwant %+v", label, cert, tt.expectedSSHKey)
+				want := &proto.SSHKey{Key: tt.expectedSSHKey.Key, Fingerprint: sshFingerprint(t, tt.PubKey)}
+				if !reflect.DeepEqual(cert, want) {
+					t.Errorf("output doesn't match for %v, got %+v\nwant %+v", label, cert, want)
+				}
+			}
+		})
+	}
+}
+
+// mockSSHCACertSign is a mockGoodCertSign that signs SSH certificates with a software key.
+type mockSSHCACertSign struct {
+	mockGoodCertSign
+	signer ssh.Signer
+}
+
+func (m *mockSSHCACertSign) GetSSHCertSigningKey(keyIdentifier string) ([]byte, error) {
+	return ssh.MarshalAuthorizedKey(m.signer.PublicKey()), nil
+}
+
+func (m *mockSSHCACertSign) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byte, error) {
+	if err := cert.SignCert(rand.Reader, m.signer); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(ssh.MarshalAuthorizedKey(cert)), nil
+}
+
+func TestPostHostSSHCertificateReturnTBS(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshSigner, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testcases := map[string]struct {
+		returnTBS bool
+	}{
+		"tbs-returned":     {returnTBS: true},
+		"tbs-not-returned": {returnTBS: false},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: &mockSSHCACertSign{signer: sshSigner}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+			request := &proto.SSHCertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "sshhostid1"}, PublicKey: testGoodRsaPubKey, Validity: 3600, KeyId: testGoodKeyID, ReturnTbs: tt.returnTBS}
+			resp, err := ss.PostHostSSHCertificate(context.Background(), request)
+			if err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			if !tt.returnTBS {
+				if resp.Tbs != nil {
+					t.Errorf("in test %v: TBS bytes returned without being requested", label)
 				}
+				return
+			}
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(resp.Key))
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			cert := pub.(*ssh.Certificate)
+			if err := cert.SignatureKey.Verify(resp.Tbs, cert.Signature); err != nil {
+				t.Errorf("in test %v: signature doesn't verify over the returned TBS bytes: %v", label, err)
 			}
 		})
 	}
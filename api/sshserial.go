@@ -0,0 +1,179 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SSHSerialCounters hands out monotonically increasing serial numbers for the SSH certificates signed by each
+// key, so that the certificates can be told apart and revoked by serial. The counter of a key is checkpointed
+// in a file named after the key in dir, which is rewritten atomically, and no serial number beyond the last
+// checkpoint plus the checkpoint interval is handed out before the next checkpoint is persisted. The next
+// checkpoint is written in the background once half of the interval is used, so that requests do not wait for
+// the disk. After a restart, including after a crash between checkpoints, the counter resumes past the last
+// checkpoint plus the interval, which is greater than any serial number previously handed out.
+//
+// The counters are only synchronized within a process: replicas must not share the directory.
+type SSHSerialCounters struct {
+	dir      string
+	interval uint64
+
+	mu       sync.Mutex
+	counters map[string]*sshSerialCounter
+
+	// writeMu serializes the checkpoint writes, so that a stale checkpoint never replaces a newer one.
+	writeMu   sync.Mutex
+	persisted map[string]uint64
+}
+
+type sshSerialCounter struct {
+	// next is the next serial number to hand out, and limit the greatest one that may be handed out.
+	next, limit uint64
+	// checkpointing is set while a background checkpoint is being written.
+	checkpointing bool
+}
+
+// NewSSHSerialCounters returns an SSHSerialCounters checkpointing the counters every interval serial numbers
+// in dir, and resumes the counters previously checkpointed there.
+func NewSSHSerialCounters(dir string, interval uint64) (*SSHSerialCounters, error) {
+	if interval == 0 {
+		return nil, fmt.Errorf("invalid checkpoint interval %d", interval)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &SSHSerialCounters{dir: dir, interval: interval, counters: make(map[string]*sshSerialCounter), persisted: make(map[string]uint64)}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		checkpoint, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH serial checkpoint of key %q: %v", f.Name(), err)
+		}
+		if checkpoint > ^uint64(0)-interval-1 {
+			return nil, fmt.Errorf("SSH serial numbers of key %q are exhausted", f.Name())
+		}
+		// The limit is below next, so that the resumed counter is checkpointed before its first serial number.
+		c.counters[f.Name()] = &sshSerialCounter{next: checkpoint + interval + 1, limit: checkpoint + interval}
+		c.persisted[f.Name()] = checkpoint
+	}
+	return c, nil
+}
+
+// Next returns the next serial number of the specified key. It returns an error if the counter cannot be
+// checkpointed.
+func (c *SSHSerialCounters) Next(keyIdentifier string) (uint64, error) {
+	if keyIdentifier == "" || keyIdentifier == "." || keyIdentifier == ".." || keyIdentifier != filepath.Base(keyIdentifier) || strings.HasPrefix(keyIdentifier, ".") {
+		return 0, fmt.Errorf("invalid key identifier %q", keyIdentifier)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counter, ok := c.counters[keyIdentifier]
+	if !ok {
+		// Serial number 0 is left for the certificates signed without a counter.
+		counter = &sshSerialCounter{next: 1}
+		c.counters[keyIdentifier] = counter
+	}
+	if counter.next > counter.limit {
+		// The background checkpoint fell behind or failed: checkpoint synchronously.
+		if counter.next > ^uint64(0)-c.interval {
+			return 0, fmt.Errorf("SSH serial numbers of key %q are exhausted", keyIdentifier)
+		}
+		if err := c.checkpoint(keyIdentifier, counter.next); err != nil {
+			return 0, err
+		}
+		counter.limit = counter.next + c.interval
+	}
+	serial := counter.next
+	counter.next++
+	if !counter.checkpointing && counter.limit-serial < c.interval/2 && counter.next <= ^uint64(0)-c.interval {
+		counter.checkpointing = true
+		go c.checkpointInBackground(keyIdentifier, counter, counter.next)
+	}
+	return serial, nil
+}
+
+// checkpointInBackground checkpoints the counter of the key at the serial number, and raises the limit
+// of the counter once the checkpoint is persisted.
+func (c *SSHSerialCounters) checkpointInBackground(keyIdentifier string, counter *sshSerialCounter, checkpoint uint64) {
+	err := c.checkpoint(keyIdentifier, checkpoint)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counter.checkpointing = false
+	if err != nil {
+		log.Printf("unable to checkpoint SSH serial number of key %q: %v", keyIdentifier, err)
+		return
+	}
+	if limit := checkpoint + c.interval; limit > counter.limit {
+		counter.limit = limit
+	}
+}
+
+// checkpoint persists the checkpoint of the key, unless a greater one is already persisted.
+func (c *SSHSerialCounters) checkpoint(keyIdentifier string, checkpoint uint64) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if persisted, ok := c.persisted[keyIdentifier]; ok && persisted >= checkpoint {
+		return nil
+	}
+	// Write a temporary file and rename it, so that a crash never leaves a truncated checkpoint.
+	tmp, err := ioutil.TempFile(c.dir, "."+keyIdentifier)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strconv.FormatUint(checkpoint, 10)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, keyIdentifier)); err != nil {
+		return err
+	}
+	c.persisted[keyIdentifier] = checkpoint
+	return nil
+}
+
+// setSSHSerial sets the serial number of the SSH certificate signed by the specified key to the next one
+// of its counter if it is configured with SSHSerialCounter. It returns an Unavailable error if the counter
+// cannot be checkpointed.
+func (s *SigningService) setSSHSerial(keyIdentifier string, cert *ssh.Certificate) error {
+	if !s.Keys[keyIdentifier].SSHSerialCounter {
+		return nil
+	}
+	if s.SSHSerialCounters == nil {
+		return status.Errorf(codes.Unavailable, "Service unavailable: no SSH serial counter for key %q", keyIdentifier)
+	}
+	serial, err := s.SSHSerialCounters.Next(keyIdentifier)
+	if err != nil {
+		log.Printf("unable to get SSH serial number of key %q: %v", keyIdentifier, err)
+		return status.Errorf(codes.Unavailable, "Service unavailable: unable to checkpoint SSH serial number")
+	}
+	cert.Serial = serial
+	return nil
+}
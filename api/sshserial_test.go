@@ -0,0 +1,117 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSSHSerialCounters(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "sshserial")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	const interval = 10
+	c, err := NewSSHSerialCounters(dir, interval)
+	if err != nil {
+		t.Fatalf("unable to create counters: %v", err)
+	}
+	var last uint64
+	for i := 0; i < 5*interval; i++ {
+		serial, err := c.Next("key1")
+		if err != nil {
+			t.Fatalf("unable to get serial number: %v", err)
+		}
+		if serial <= last {
+			t.Fatalf("got serial number %d after %d, want it greater", serial, last)
+		}
+		last = serial
+	}
+	if _, err := c.Next("../key1"); err == nil {
+		t.Error("got nil err for an invalid key identifier")
+	}
+
+	// A crash leaves the checkpoint written before the last serial numbers were handed out, and the counter
+	// abandoned: the recovered counter must not hand out any of them again.
+	data, err := ioutil.ReadFile(filepath.Join(dir, "key1"))
+	if err != nil {
+		t.Fatalf("unable to read checkpoint: %v", err)
+	}
+	if checkpoint, _ := strconv.ParseUint(string(data), 10, 64); checkpoint > last {
+		t.Fatalf("got checkpoint %d beyond the last serial number %d", checkpoint, last)
+	}
+	recovered, err := NewSSHSerialCounters(dir, interval)
+	if err != nil {
+		t.Fatalf("unable to recover counters: %v", err)
+	}
+	serial, err := recovered.Next("key1")
+	if err != nil {
+		t.Fatalf("unable to get recovered serial number: %v", err)
+	}
+	if serial <= last {
+		t.Errorf("got recovered serial number %d, want it greater than %d", serial, last)
+	}
+	if serial, err := recovered.Next("key2"); err != nil || serial != 1 {
+		t.Errorf("got serial number %d, err %v for a new key, want 1", serial, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "key3"), []byte("bad"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSSHSerialCounters(dir, interval); err == nil {
+		t.Error("got nil err for an invalid checkpoint")
+	}
+}
+
+func TestPostUserSSHCertificateSerialCounter(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "sshserial")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	c, err := NewSSHSerialCounters(dir, 10)
+	if err != nil {
+		t.Fatalf("unable to create counters: %v", err)
+	}
+	signer := &mockRecordingCertSign{}
+	ss := initMockSigningService(mockSigningServiceParam{KeyUsages: sshkeyUsage, MaxValidity: map[string]uint64{config.SSHUserCertEndpoint: 0}})
+	ss.CertSign = signer
+	ss.Keys = map[string]config.KeyConfig{"sshuserid": {Identifier: "sshuserid", SSHSerialCounter: true}}
+	ss.SSHSerialCounters = c
+	request := &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid"},
+		Principals: []string{"alice"},
+		PublicKey:  testGoodEd25519PubKey,
+		Validity:   3600,
+		KeyId:      testGoodKeyID,
+	}
+
+	for want := uint64(1); want <= 2; want++ {
+		if _, err := ss.PostUserSSHCertificate(context.Background(), request); err != nil {
+			t.Fatalf("unable to sign certificate: %v", err)
+		}
+		if signer.sshCert.Serial != want {
+			t.Errorf("got serial number %d, want %d", signer.sshCert.Serial, want)
+		}
+	}
+
+	ss.SSHSerialCounters = nil
+	_, err = ss.PostUserSSHCertificate(context.Background(), request)
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("got code %v without SSH serial counters, want %v, err: %v", got, codes.Unavailable, err)
+	}
+}
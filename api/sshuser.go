@@ -5,12 +5,14 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/yahoo/crypki/config"
 	"github.com/yahoo/crypki/proto"
 	"github.com/yahoo/crypki/sshcert"
@@ -20,7 +22,8 @@ import (
 )
 
 // GetUserSSHCertificateAvailableSigningKeys returns all available keys that can sign user SSH certificates.
-func (s *SigningService) GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, e *empty.Empty) (*proto.KeyMetas, error) {
+// If the filter is not empty, only the keys matching it are returned.
+func (s *SigningService) GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, filter *proto.KeyFilter) (*proto.KeyMetas, error) {
 	const methodName = "GetUserSSHCertificateAvailableSigningKeys"
 	statusCode := http.StatusOK
 	start := time.Now()
@@ -31,11 +34,7 @@ func (s *SigningService) GetUserSSHCertificateAvailableSigningKeys(ctx context.C
 	}()
 	defer recoverIfPanicked(methodName)
 
-	var keys []*proto.KeyMeta
-	for id := range s.KeyUsages[config.SSHUserCertEndpoint] {
-		keys = append(keys, &proto.KeyMeta{Identifier: id})
-	}
-	return &proto.KeyMetas{Keys: keys}, nil
+	return &proto.KeyMetas{Keys: s.availableKeys(config.SSHUserCertEndpoint, filter)}, nil
 }
 
 // GetUserSSHCertificateSigningKey returns the public signing key of the
@@ -57,16 +56,21 @@ func (s *SigningService) GetUserSSHCertificateSigningKey(ctx context.Context, ke
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
+	if err = s.checkKeyTransition(keyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
 	if !s.KeyUsages[config.SSHUserCertEndpoint][keyMeta.Identifier] {
 		statusCode = http.StatusBadRequest
 		err = fmt.Errorf("cannot use key %q for %q", keyMeta.Identifier, config.SSHUserCertEndpoint)
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	key, err := s.GetSSHCertSigningKey(keyMeta.Identifier)
+	key, err := s.PublicKeyCache.get(cachedSSHKey, keyMeta.Identifier, s.GetSSHCertSigningKey)
 	if err != nil {
 		statusCode = http.StatusInternalServerError
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, s.internalError(err)
 	}
 	return &proto.SSHKey{Key: string(key)}, nil
 }
@@ -80,31 +84,75 @@ func (s *SigningService) PostUserSSHCertificate(ctx context.Context, request *pr
 	var cert *ssh.Certificate
 
 	defer func() {
-		kid := ""
+		kid, fp := "", ""
 		if cert != nil {
 			kid = cert.KeyId
+			fp = ssh.FingerprintSHA256(cert.Key)
 		}
-		log.Printf(`m=%s,id=%q,principals=%q,st=%d,et=%d,err="%v"`, methodName, kid, request.Principals, statusCode, timeElapsedSince(start), err)
+		log.Printf(`m=%s,id=%q,principals=%q,fp=%q,st=%d,et=%d,err="%v"`, methodName, kid, request.Principals, fp, statusCode, timeElapsedSince(start), err)
 	}()
 	defer recoverIfPanicked(methodName)
 
+	if err = s.checkEndpointEnabled(config.SSHUserCertEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
 	if request.KeyMeta == nil {
 		statusCode = http.StatusBadRequest
 		err = fmt.Errorf("request.keyMeta is empty for %q", config.SSHUserCertEndpoint)
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	maxValidity := s.MaxValidity[config.SSHUserCertEndpoint]
-	if err := checkValidity(request.GetValidity(), maxValidity); err != nil {
+	if err = s.RequiredFields.check(config.SSHUserCertEndpoint, request); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	var maxValidity uint64
+	request.Validity, maxValidity = s.sshValidity(ctx, config.SSHUserCertEndpoint, request.KeyMeta.Identifier, request.GetValidity())
+	if err := s.checkSSHValidity(request.KeyMeta.Identifier, request.GetValidity(), maxValidity); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkSSHCertOptions(request); err != nil {
 		statusCode = http.StatusBadRequest
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
+	if s.DeriveKeyID[config.SSHUserCertEndpoint] {
+		if request.KeyId, err = callerKeyID(ctx, time.Now()); err != nil {
+			statusCode = http.StatusForbidden
+			return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+		}
+	}
+
 	cert, err = sshcert.DecodeRequest(request, ssh.UserCert, s.KeyIDProcessor)
 	if err != nil {
 		statusCode = http.StatusBadRequest
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
+	cert.ValidAfter, cert.ValidBefore = s.alignValidity(request.KeyMeta.Identifier, cert.ValidAfter, cert.ValidBefore)
+	if err = checkValidityWindow(start, sshTime(cert.ValidAfter), sshTime(cert.ValidBefore), s.MinValidity[config.SSHUserCertEndpoint]); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if dups := sshcert.DedupPrincipals(cert); len(dups) != 0 && s.RejectDuplicateNames[config.SSHUserCertEndpoint] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("duplicate principals: %q", dups)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if len(cert.ValidPrincipals) == 0 && !s.AllowEmptyPrincipals[config.SSHUserCertEndpoint] {
+		statusCode = http.StatusBadRequest
+		err = errors.New("principals cannot be empty for user certificates")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
 
 	if !s.KeyUsages[config.SSHUserCertEndpoint][request.KeyMeta.Identifier] {
 		statusCode = http.StatusBadRequest
@@ -112,10 +160,80 @@ func (s *SigningService) PostUserSSHCertificate(ctx context.Context, request *pr
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	data, err := s.SignSSHCert(cert, request.KeyMeta.Identifier)
+	key := s.Keys[request.KeyMeta.Identifier]
+	policy := &sshcert.SubjectKeyPolicy{
+		KeyTypes:      key.SSHSubjectKeyTypes,
+		MinRSAKeySize: key.MinSSHSubjectRSAKeySize,
+		MaxRSAKeySize: key.MaxSSHSubjectRSAKeySize,
+	}
+	if err = policy.Check(cert.Key); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err = checkSSHCertExtensions(key, cert); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err = s.checkSSHSubjectKey(cert.Key); err != nil {
+		statusCode = http.StatusForbidden
+		if status.Code(err) == codes.InvalidArgument {
+			statusCode = http.StatusBadRequest
+		}
+		return nil, err
+	}
+
+	if err = s.checkSSHCertHashFloor(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusBadRequest
+		if status.Code(err) == codes.Internal {
+			statusCode = http.StatusInternalServerError
+		}
+		return nil, err
+	}
+
+	if err = s.checkClockDrift(); err != nil {
+		statusCode = http.StatusPreconditionFailed
+		return nil, err
+	}
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	if err = s.checkNotBefore(request.KeyMeta.Identifier, time.Unix(int64(cert.ValidAfter), 0)); err != nil {
+		statusCode = http.StatusBadRequest
+		if status.Code(err) == codes.Unavailable {
+			statusCode = http.StatusServiceUnavailable
+		}
+		return nil, err
+	}
+	if err = s.setSSHSerial(request.KeyMeta.Identifier, cert); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+	data, err := s.prioritized(ctx, request.Priority).SignSSHCert(cert, request.KeyMeta.Identifier)
+	s.recordSignResult(request.KeyMeta.Identifier, err)
 	if err != nil {
 		statusCode = http.StatusInternalServerError
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, s.internalError(err)
+	}
+	resp := &proto.SSHKey{Key: string(data), Fingerprint: ssh.FingerprintSHA256(cert.Key)}
+	if request.ReturnTbs {
+		if resp.Tbs, err = sshcert.TBS(data); err != nil {
+			statusCode = http.StatusInternalServerError
+			return nil, s.internalError(err)
+		}
+	}
+	if resp.Receipt, err = s.sshReceipt(request.KeyMeta.Identifier, issuanceSSHUser, cert, resp.Fingerprint); err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	if err = s.storeIssuance(request.KeyMeta.Identifier, issuanceSSHUser, data, strconv.FormatUint(cert.Serial, 10), resp.Fingerprint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
 	}
-	return &proto.SSHKey{Key: string(data)}, nil
+	s.recordSSHIssuance(request.KeyMeta.Identifier, issuanceSSHUser, cert, resp.Fingerprint)
+	return resp, nil
 }
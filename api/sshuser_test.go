@@ -8,9 +8,11 @@ import (
 	"sort"
 	"testing"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
 	"github.com/yahoo/crypki/config"
 	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetUserSSHCertificateAvailableSigningKeys(t *testing.T) {
@@ -52,8 +54,8 @@ func TestGetUserSSHCertificateAvailableSigningKeys(t *testing.T) {
 			mssp := mockSigningServiceParam{KeyUsages: tt.KeyUsages, sendError: false}
 			ss := initMockSigningService(mssp)
 			var ctx context.Context
-			var e *empty.Empty
-			keyMetas, err := ss.GetUserSSHCertificateAvailableSigningKeys(ctx, e)
+			var filter *proto.KeyFilter
+			keyMetas, err := ss.GetUserSSHCertificateAvailableSigningKeys(ctx, filter)
 			if err != nil {
 				t.Fatal(err)
 				return
@@ -216,15 +218,33 @@ func TestPostUserSSHCertificate(t *testing.T) {
 			PubKey:         testGoodRsaPubKey,
 			KeyID:          testGoodKeyID,
 		},
-		"combineKeyUsagesWithTrueIdAndDsaPubKey": {
+		"combineKeyUsagesWithTrueIdAndDsaPubKeyRejected": {
 			KeyUsages:      combineKeyUsage,
 			maxValidity:    defaultMaxValidity,
 			validity:       3600,
 			KeyMeta:        &proto.KeyMeta{Identifier: "sshuserid1"},
-			expectedSSHKey: &proto.SSHKey{Key: "good ssh cert"},
+			expectedSSHKey: nil,
 			PubKey:         testGoodDsaPubKey,
 			KeyID:          testGoodKeyID,
 		},
+		"combineKeyUsagesWithTrueIdAndRsa1024PubKeyRejected": {
+			KeyUsages:      combineKeyUsage,
+			maxValidity:    defaultMaxValidity,
+			validity:       3600,
+			KeyMeta:        &proto.KeyMeta{Identifier: "sshuserid1"},
+			expectedSSHKey: nil,
+			PubKey:         testRsa1024PubKey,
+			KeyID:          testGoodKeyID,
+		},
+		"combineKeyUsagesWithTrueIdAndEd25519PubKey": {
+			KeyUsages:      combineKeyUsage,
+			maxValidity:    defaultMaxValidity,
+			validity:       3600,
+			KeyMeta:        &proto.KeyMeta{Identifier: "sshuserid1"},
+			expectedSSHKey: &proto.SSHKey{Key: "good ssh cert"},
+			PubKey:         testGoodEd25519PubKey,
+			KeyID:          testGoodKeyID,
+		},
 		"combineKeyUsagesWithTrueIdAndECdsaPubKey": {
 			KeyUsages:      combineKeyUsage,
 			maxValidity:    defaultMaxValidity,
@@ -298,7 +318,7 @@ func TestPostUserSSHCertificate(t *testing.T) {
 			// bad certsign should return error anyways
 			msspBad := mockSigningServiceParam{KeyUsages: tt.KeyUsages, MaxValidity: tt.maxValidity, sendError: true}
 			ssBad := initMockSigningService(msspBad)
-			requestBad := &proto.SSHCertificateSigningRequest{KeyMeta: tt.KeyMeta, PublicKey: tt.PubKey, Validity: tt.validity, KeyId: tt.KeyID}
+			requestBad := &proto.SSHCertificateSigningRequest{KeyMeta: tt.KeyMeta, Principals: []string{"alice"}, PublicKey: tt.PubKey, Validity: tt.validity, KeyId: tt.KeyID}
 			_, err := ssBad.PostUserSSHCertificate(ctx, requestBad)
 			if err == nil {
 				t.Fatalf("in test %v: bad signing service should return error but got nil", label)
@@ -307,7 +327,7 @@ func TestPostUserSSHCertificate(t *testing.T) {
 			// good certsign
 			msspGood := mockSigningServiceParam{KeyUsages: tt.KeyUsages, MaxValidity: tt.maxValidity, sendError: false}
 			ssGood := initMockSigningService(msspGood)
-			requestGood := &proto.SSHCertificateSigningRequest{KeyMeta: tt.KeyMeta, PublicKey: tt.PubKey, Validity: tt.validity, KeyId: tt.KeyID}
+			requestGood := &proto.SSHCertificateSigningRequest{KeyMeta: tt.KeyMeta, Principals: []string{"alice"}, PublicKey: tt.PubKey, Validity: tt.validity, KeyId: tt.KeyID}
 			cert, err := ssGood.PostUserSSHCertificate(ctx, requestGood)
 			if tt.expectedSSHKey == nil {
 				if err == nil {
@@ -317,10 +337,125 @@ func TestPostUserSSHCertificate(t *testing.T) {
 				if err != nil {
 					t.Fatalf("unexpected error for %v, err: %v", label, err)
 				}
-				if !reflect.DeepEqual(cert, tt.expectedSSHKey) {
-					t.Errorf("output doesn't match for %v, got %+v\nwant %+v", label, cert, tt.expectedSSHKey)
+				want := &proto.SSHKey{Key: tt.expectedSSHKey.Key, Fingerprint: sshFingerprint(t, tt.PubKey)}
+				if !reflect.DeepEqual(cert, want) {
+					t.Errorf("output doesn't match for %v, got %+v\nwant %+v", label, cert, want)
 				}
 			}
 		})
 	}
 }
+
+func TestPostUserSSHCertificateDuplicatePrincipals(t *testing.T) {
+	t.Parallel()
+	request := &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+		PublicKey:  testGoodRsaPubKey,
+		Validity:   3600,
+		Principals: []string{"alice", "bob", "alice"},
+		KeyId:      testGoodKeyID,
+	}
+	testcases := map[string]struct {
+		reject       bool
+		expectedCode codes.Code
+	}{
+		"dedup":  {reject: false, expectedCode: codes.OK},
+		"reject": {reject: true, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:             signer,
+				KeyIDProcessor:       &crypki.KeyID{},
+				KeyUsages:            combineKeyUsage,
+				RejectDuplicateNames: map[string]bool{config.SSHUserCertEndpoint: tt.reject},
+			}
+			_, err := ss.PostUserSSHCertificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				if signer.sshCert != nil {
+					t.Errorf("in test %v: certificate with duplicate principals was signed", label)
+				}
+				return
+			}
+			want := []string{"alice", "bob"}
+			if !reflect.DeepEqual(signer.sshCert.ValidPrincipals, want) {
+				t.Errorf("in test %v: got principals %q, want %q", label, signer.sshCert.ValidPrincipals, want)
+			}
+		})
+	}
+}
+
+func TestPostUserSSHCertificateEmptyPrincipals(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		principals   []string
+		allowEmpty   bool
+		expectedCode codes.Code
+	}{
+		"empty-principals-rejected": {principals: nil, allowEmpty: false, expectedCode: codes.InvalidArgument},
+		"empty-principals-allowed":  {principals: nil, allowEmpty: true, expectedCode: codes.OK},
+		"principals":                {principals: []string{"alice"}, allowEmpty: false, expectedCode: codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := initMockSigningService(mockSigningServiceParam{KeyUsages: combineKeyUsage})
+			ss.AllowEmptyPrincipals = map[string]bool{config.SSHUserCertEndpoint: tt.allowEmpty}
+			request := &proto.SSHCertificateSigningRequest{
+				KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+				PublicKey:  testGoodRsaPubKey,
+				Validity:   3600,
+				Principals: tt.principals,
+				KeyId:      testGoodKeyID,
+			}
+			_, err := ss.PostUserSSHCertificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+		})
+	}
+}
+
+func TestPostUserSSHCertificateSubjectKeyPolicy(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		key          config.KeyConfig
+		pubKey       string
+		expectedCode codes.Code
+	}{
+		"default-rsa-1024":    {pubKey: testRsa1024PubKey, expectedCode: codes.InvalidArgument},
+		"default-ed25519":     {pubKey: testGoodEd25519PubKey, expectedCode: codes.OK},
+		"default-dsa":         {pubKey: testGoodDsaPubKey, expectedCode: codes.InvalidArgument},
+		"min-rsa-1024":        {key: config.KeyConfig{MinSSHSubjectRSAKeySize: 1024}, pubKey: testRsa1024PubKey, expectedCode: codes.OK},
+		"ed25519-only-reject": {key: config.KeyConfig{SSHSubjectKeyTypes: []string{"ssh-ed25519"}}, pubKey: testGoodRsaPubKey, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := initMockSigningService(mockSigningServiceParam{KeyUsages: sshkeyUsage, MaxValidity: map[string]uint64{config.SSHUserCertEndpoint: 0}})
+			ss.Keys = map[string]config.KeyConfig{"sshuserid": tt.key}
+			request := &proto.SSHCertificateSigningRequest{
+				KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid"},
+				Principals: []string{"alice"},
+				PublicKey:  tt.pubKey,
+				Validity:   3600,
+				KeyId:      testGoodKeyID,
+			}
+			_, err := ss.PostUserSSHCertificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/sshcert"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifySSHCertificate checks that an SSH certificate is signed by the specified CA key and is currently
+// within its validity period. A certificate that fails the checks is reported in the response, not as an error.
+func (s *SigningService) VerifySSHCertificate(ctx context.Context, request *proto.SSHCertificateVerificationRequest) (*proto.SSHCertificateVerification, error) {
+	const methodName = "VerifySSHCertificate"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+	var cert *ssh.Certificate
+
+	defer func() {
+		kid := ""
+		if cert != nil {
+			kid = cert.KeyId
+		}
+		log.Printf(`m=%s,id=%q,kid=%q,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), kid, statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = errors.New("request.keyMeta is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(request.GetCertificate()))
+	if err != nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("unable to parse certificate: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	var ok bool
+	if cert, ok = pub.(*ssh.Certificate); !ok {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("unexpected key type %s, want a certificate", pub.Type())
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	endpoint := config.SSHUserCertEndpoint
+	if cert.CertType == ssh.HostCert {
+		endpoint = config.SSHHostCertEndpoint
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[endpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, endpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	key, err := s.PublicKeyCache.get(cachedSSHKey, request.KeyMeta.Identifier, s.GetSSHCertSigningKey)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	ca, _, _, _, err := ssh.ParseAuthorizedKey(key)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+
+	result := &proto.SSHCertificateVerification{ValidAfter: cert.ValidAfter, ValidBefore: cert.ValidBefore}
+	var reasons []string
+	if verr := sshcert.VerifySignature(cert, ca); verr != nil {
+		reasons = append(reasons, verr.Error())
+	} else {
+		result.SignatureValid = true
+	}
+	// The validity period is checked as sshd does, with ValidBefore exclusive.
+	now := uint64(time.Now().Unix())
+	if cert.ValidAfter <= now && (now < cert.ValidBefore || cert.ValidBefore == ssh.CertTimeInfinity) {
+		result.WithinValidity = true
+	} else {
+		reasons = append(reasons, "certificate is not within its validity period")
+	}
+	if len(reasons) != 0 {
+		result.Reason = strings.Join(reasons, "; ")
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestSSHSigner returns an ssh.Signer of a new ECDSA key.
+func newTestSSHSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestVerifySSHCertificate(t *testing.T) {
+	t.Parallel()
+	caSigner := newTestSSHSigner(t)
+	ss := &SigningService{CertSign: &mockSSHCACertSign{signer: caSigner}, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage}
+	issued, err := ss.PostUserSSHCertificate(context.Background(), &proto.SSHCertificateSigningRequest{
+		KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+		PublicKey:  testGoodRsaPubKey,
+		Validity:   3600,
+		Principals: []string{"alice"},
+		KeyId:      testGoodKeyID,
+	})
+	if err != nil {
+		t.Fatalf("unable to issue certificate: %v", err)
+	}
+	subject, _, _, _, err := ssh.ParseAuthorizedKey([]byte(testGoodRsaPubKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// certificate returns a user certificate valid from validAfter to validBefore signed by signer.
+	certificate := func(signer ssh.Signer, validAfter, validBefore time.Time) string {
+		cert := &ssh.Certificate{
+			Key:             subject,
+			CertType:        ssh.UserCert,
+			ValidPrincipals: []string{"alice"},
+			ValidAfter:      uint64(validAfter.Unix()),
+			ValidBefore:     uint64(validBefore.Unix()),
+		}
+		if err := cert.SignCert(rand.Reader, signer); err != nil {
+			t.Fatal(err)
+		}
+		return string(bytes.TrimSpace(ssh.MarshalAuthorizedKey(cert)))
+	}
+	now := time.Now()
+
+	testcases := map[string]struct {
+		keyMeta        *proto.KeyMeta
+		certificate    string
+		expectCode     codes.Code
+		signatureValid bool
+		withinValidity bool
+	}{
+		"good-crypki-issued": {
+			keyMeta:        &proto.KeyMeta{Identifier: "sshuserid1"},
+			certificate:    issued.Key,
+			signatureValid: true,
+			withinValidity: true,
+		},
+		"bad-other-ca-key": {
+			keyMeta:        &proto.KeyMeta{Identifier: "sshuserid1"},
+			certificate:    certificate(newTestSSHSigner(t), now.Add(-time.Hour), now.Add(time.Hour)),
+			withinValidity: true,
+		},
+		"bad-expired": {
+			keyMeta:        &proto.KeyMeta{Identifier: "sshuserid1"},
+			certificate:    certificate(caSigner, now.Add(-2*time.Hour), now.Add(-time.Hour)),
+			signatureValid: true,
+		},
+		"bad-key-not-for-user-certs": {
+			keyMeta:     &proto.KeyMeta{Identifier: "sshhostid1"},
+			certificate: issued.Key,
+			expectCode:  codes.InvalidArgument,
+		},
+		"bad-not-a-certificate": {
+			keyMeta:     &proto.KeyMeta{Identifier: "sshuserid1"},
+			certificate: testGoodRsaPubKey,
+			expectCode:  codes.InvalidArgument,
+		},
+		"bad-no-key-meta": {
+			certificate: issued.Key,
+			expectCode:  codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		resp, err := ss.VerifySSHCertificate(context.Background(), &proto.SSHCertificateVerificationRequest{KeyMeta: tt.keyMeta, Certificate: tt.certificate})
+		if status.Code(err) != tt.expectCode {
+			t.Fatalf("in test %v: got %v, want code %v", label, err, tt.expectCode)
+		}
+		if err != nil {
+			continue
+		}
+		if resp.SignatureValid != tt.signatureValid || resp.WithinValidity != tt.withinValidity {
+			t.Errorf("in test %v: got signature valid %t and within validity %t, want %t and %t",
+				label, resp.SignatureValid, resp.WithinValidity, tt.signatureValid, tt.withinValidity)
+		}
+		if valid := tt.signatureValid && tt.withinValidity; valid != (resp.Reason == "") {
+			t.Errorf("in test %v: got reason %q for a certificate valid: %t", label, resp.Reason, valid)
+		}
+	}
+}
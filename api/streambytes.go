@@ -0,0 +1,52 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import "sync"
+
+// StreamBytesLimiter caps the total number of bytes of the chunks being received by the blob streams and not
+// yet hashed, so that many concurrent uploads cannot exhaust the memory of the process. gRPC allocates a message
+// as it receives it, so a stream reserves MessageSize bytes before receiving its next chunk. The reservation is
+// released once the chunk is hashed, so a blob larger than the cap can be streamed in smaller chunks.
+type StreamBytesLimiter struct {
+	// Max is the maximum number of bytes of the chunks being received or hashed.
+	Max int64
+	// MessageSize is the maximum size of the messages received by the gRPC server, reserved for each chunk.
+	MessageSize int64
+
+	mu       sync.Mutex
+	inFlight int64
+}
+
+// acquire adds n bytes to the bytes in flight, and returns false without adding them if they would exceed Max.
+func (l *StreamBytesLimiter) acquire(n int64) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight+n > l.Max {
+		return false
+	}
+	l.inFlight += n
+	return true
+}
+
+// messageSize returns the number of bytes to reserve before receiving a chunk, or 0 if l is nil.
+func (l *StreamBytesLimiter) messageSize() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.MessageSize
+}
+
+// release removes n bytes from the bytes in flight.
+func (l *StreamBytesLimiter) release(n int64) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight -= n
+}
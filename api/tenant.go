@@ -0,0 +1,32 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PostTenantSignature is not supported. The subkeys of the tenants would have to be derived from a root key
+// and held in memory to sign, as PKCS#11 cannot derive asymmetric keys in the HSM, and the private keys of
+// crypki never leave the HSM.
+func (s *SigningService) PostTenantSignature(ctx context.Context, request *proto.TenantSigningRequest) (*proto.TenantSignature, error) {
+	const methodName = "PostTenantSignature"
+	statusCode := http.StatusNotImplemented
+	start := time.Now()
+	err := errors.New("signing with the subkeys of the tenants is not supported")
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,tenant=%q,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(),
+			request.GetTenant(), statusCode, timeElapsedSince(start), err)
+	}()
+	return nil, status.Error(codes.Unimplemented, "Signing with the subkeys of the tenants is not supported")
+}
@@ -0,0 +1,22 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostTenantSignature(t *testing.T) {
+	t.Parallel()
+	ss := &SigningService{}
+	request := &proto.TenantSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "tenants"}, Tenant: "media", Digest: "Zm9v"}
+	if _, err := ss.PostTenantSignature(context.Background(), request); status.Code(err) != codes.Unimplemented {
+		t.Errorf("got %v, want Unimplemented", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Timeouts configures the timeouts of requests.
+//
+// The deadline of a request is determined in the following order of precedence:
+//  1. the deadline supplied by the client,
+//  2. the timeout of the endpoint of the request,
+//  3. the default timeout.
+type Timeouts struct {
+	// Default is the timeout of requests to methods without an endpoint timeout. Zero means no timeout.
+	Default time.Duration
+	// Endpoints maps endpoints to their timeouts.
+	Endpoints map[string]time.Duration
+	// Rejected, if set, is called with the full method name and the request of each request that timed out.
+	Rejected func(method string, req interface{})
+}
+
+// timeout returns the timeout of requests to fullMethod.
+func (t *Timeouts) timeout(fullMethod string) time.Duration {
+	if d, ok := t.Endpoints[endpointOf(fullMethod)]; ok && d > 0 {
+		return d
+	}
+	return t.Default
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor applying the configured timeouts
+// to requests without a client deadline. The handler runs with the deadline in its context, so that
+// a request still waiting for an HSM session when it expires is not signed, and fails with
+// DeadlineExceeded. A request already sent to the HSM completes, and returns what was issued.
+func (t *Timeouts) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			d := t.timeout(info.FullMethod)
+			if d <= 0 {
+				return handler(ctx, req)
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			if t.Rejected != nil {
+				t.Rejected(info.FullMethod, req)
+			}
+			return nil, status.Error(codes.DeadlineExceeded, "Request timed out")
+		case context.Canceled:
+			return nil, status.Error(codes.Canceled, "Request canceled")
+		}
+		return nil, err
+	}
+}
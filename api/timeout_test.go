@@ -0,0 +1,183 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockSlowCertSign is a mockGoodCertSign whose signing operations wait delay for a session, unless its
+// context is done first, and counts the operations that were signed.
+type mockSlowCertSign struct {
+	mockGoodCertSign
+	delay  time.Duration
+	ctx    context.Context
+	signed *int32
+}
+
+func (m *mockSlowCertSign) WithContext(ctx context.Context) crypki.CertSign {
+	return &mockSlowCertSign{delay: m.delay, ctx: ctx, signed: m.signed}
+}
+
+// wait waits for a session for delay, or returns the error of the context if it is done first.
+func (m *mockSlowCertSign) wait() error {
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case <-time.After(m.delay):
+		atomic.AddInt32(m.signed, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *mockSlowCertSign) SignX509Cert(cert *x509.Certificate, keyIdentifier string) ([]byte, error) {
+	if err := m.wait(); err != nil {
+		return nil, err
+	}
+	return m.mockGoodCertSign.SignX509Cert(cert, keyIdentifier)
+}
+
+func (m *mockSlowCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	if err := m.wait(); err != nil {
+		return nil, err
+	}
+	return m.mockGoodCertSign.Sign(digest, opts, keyIdentifier)
+}
+
+func TestTimeoutsUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	timeouts := &Timeouts{
+		Default: time.Second,
+		Endpoints: map[string]time.Duration{
+			config.X509CertEndpoint: time.Second,
+			config.BlobEndpoint:     10 * time.Millisecond,
+		},
+	}
+	x509Request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600}
+	blobRequest := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA512Digest, HashAlgorithm: proto.HashAlgo_SHA512}
+	x509Handler := func(ss *SigningService) grpc.UnaryHandler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return ss.PostX509Certificate(ctx, req.(*proto.X509CertificateSigningRequest))
+		}
+	}
+	blobHandler := func(ss *SigningService) grpc.UnaryHandler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return ss.PostSignBlob(ctx, req.(*proto.BlobSigningRequest))
+		}
+	}
+
+	testcases := map[string]struct {
+		method         string
+		req            interface{}
+		handler        func(ss *SigningService) grpc.UnaryHandler
+		clientDeadline time.Duration
+		expectedCode   codes.Code
+	}{
+		"x509-endpoint-timeout": {
+			method:       "/v3.Signing/PostX509Certificate",
+			req:          x509Request,
+			handler:      x509Handler,
+			expectedCode: codes.OK,
+		},
+		"blob-endpoint-timeout": {
+			method:       "/v3.Signing/PostSignBlob",
+			req:          blobRequest,
+			handler:      blobHandler,
+			expectedCode: codes.DeadlineExceeded,
+		},
+		"blob-client-deadline-takes-precedence": {
+			method:         "/v3.Signing/PostSignBlob",
+			req:            blobRequest,
+			handler:        blobHandler,
+			clientDeadline: time.Second,
+			expectedCode:   codes.OK,
+		},
+		"x509-client-deadline-takes-precedence": {
+			method:         "/v3.Signing/PostX509Certificate",
+			req:            x509Request,
+			handler:        x509Handler,
+			clientDeadline: 10 * time.Millisecond,
+			expectedCode:   codes.DeadlineExceeded,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			if tt.clientDeadline != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.clientDeadline)
+				defer cancel()
+			}
+			rejected := 0
+			timeouts := &Timeouts{
+				Default:   timeouts.Default,
+				Endpoints: timeouts.Endpoints,
+				Rejected:  func(method string, req interface{}) { rejected++ },
+			}
+			signed := new(int32)
+			ss := &SigningService{
+				CertSign:       &mockSlowCertSign{delay: 100 * time.Millisecond, signed: signed},
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+			}
+			_, err := timeouts.UnaryServerInterceptor()(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, tt.handler(ss))
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Errorf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			timedOut := tt.expectedCode == codes.DeadlineExceeded
+			if (rejected == 1) != timedOut {
+				t.Errorf("in test %v: got %d rejected requests, want timed out: %v", label, rejected, timedOut)
+			}
+			// The requests that timed out are never signed, and those that did not are signed once returned.
+			if got := atomic.LoadInt32(signed); (got == 1) == timedOut {
+				t.Errorf("in test %v: got %d signed requests, want timed out: %v", label, got, timedOut)
+			}
+		})
+	}
+}
+
+func TestTimeoutsTimeout(t *testing.T) {
+	t.Parallel()
+	timeouts := &Timeouts{
+		Default:   time.Second,
+		Endpoints: map[string]time.Duration{config.BlobEndpoint: time.Millisecond},
+	}
+	testcases := map[string]struct {
+		method   string
+		expected time.Duration
+	}{
+		"endpoint":         {"/v3.Signing/GetBlobSigningKey", time.Millisecond},
+		"default":          {"/v3.Signing/PostX509Certificate", time.Second},
+		"no-endpoint":      {"/v3.Admin/GetServerInfo", time.Second},
+		"malformed-method": {"PostSignBlob", time.Millisecond},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			if got := timeouts.timeout(tt.method); got != tt.expected {
+				t.Errorf("got timeout %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
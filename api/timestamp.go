@@ -0,0 +1,112 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/x509cert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PostTimestamp returns the RFC 3161 time-stamp response to the time-stamp request, with the
+// time-stamp token signed by the specified TSA key. The X509 CA certificate of the key is the TSA certificate.
+func (s *SigningService) PostTimestamp(ctx context.Context, request *proto.TimestampRequest) (*proto.TimestampResponse, error) {
+	const methodName = "PostTimestamp"
+	statusCode := http.StatusCreated
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,id=%q,st=%d,et=%d,err="%v"`, methodName, request.GetKeyMeta().GetIdentifier(), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.TimestampEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("request.keyMeta is empty for %q", config.TimestampEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[config.TimestampEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.TimestampEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	key := s.Keys[request.KeyMeta.Identifier]
+	policy, err := key.TSAPolicy()
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	caPEM, err := s.PublicKeyCache.get(cachedX509CA, request.KeyMeta.Identifier, s.GetX509CACert)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	block, _ := pem.Decode(caPEM)
+	if block == nil {
+		statusCode = http.StatusInternalServerError
+		err = fmt.Errorf("unable to decode TSA certificate of key %q", request.KeyMeta.Identifier)
+		return nil, s.internalError(err)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	// The time-stamp tokens are signed over SHA256, whatever the hash of the message imprint.
+	if err = s.checkHashFloor(request.KeyMeta.Identifier, crypto.SHA256); err != nil {
+		statusCode = http.StatusBadRequest
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkClockDrift(); err != nil {
+		statusCode = http.StatusPreconditionFailed
+		return nil, err
+	}
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	tsa := &x509cert.TSA{
+		Cert:     cert,
+		Signer:   &keySigner{certSign: s.prioritized(ctx, proto.Priority_NORMAL), identifier: request.KeyMeta.Identifier, public: cert.PublicKey},
+		Policy:   policy,
+		Accuracy: time.Duration(key.TSAAccuracyMs) * time.Millisecond,
+	}
+	resp, err := tsa.Respond(request.GetRequest())
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.TimestampResponse{Response: resp}, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testTimeStampReq is the RFC 3161 TimeStampReq without the optional fields.
+type testTimeStampReq struct {
+	Version        int
+	MessageImprint struct {
+		HashAlgorithm pkix.AlgorithmIdentifier
+		HashedMessage []byte
+	}
+}
+
+// testTimeStampResp is the RFC 3161 TimeStampResp with only the status parsed.
+type testTimeStampResp struct {
+	Status struct {
+		Status int
+		Rest   []asn1.RawValue `asn1:"optional"`
+	}
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+func TestPostTimestamp(t *testing.T) {
+	t.Parallel()
+	var req testTimeStampReq
+	req.Version = 1
+	req.MessageImprint.HashAlgorithm.Algorithm = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	sum := sha256.Sum256([]byte("artifact"))
+	req.MessageImprint.HashedMessage = sum[:]
+	goodReq, err := asn1.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyUsages := map[string]map[string]bool{config.TimestampEndpoint: {"tsaid1": true}}
+	keys := map[string]config.KeyConfig{"tsaid1": {Identifier: "tsaid1", TSAPolicyOID: "1.3.6.1.4.1.99999.1"}}
+	testcases := map[string]struct {
+		request      *proto.TimestampRequest
+		expectedCode codes.Code
+	}{
+		"good":        {&proto.TimestampRequest{KeyMeta: &proto.KeyMeta{Identifier: "tsaid1"}, Request: goodReq}, codes.OK},
+		"bad-key":     {&proto.TimestampRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Request: goodReq}, codes.InvalidArgument},
+		"no-key-meta": {&proto.TimestampRequest{Request: goodReq}, codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{CertSign: newMockCACertSign(t), KeyIDProcessor: &crypki.KeyID{}, KeyUsages: keyUsages, Keys: keys}
+			resp, err := ss.PostTimestamp(context.Background(), tt.request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			var r testTimeStampResp
+			if _, err := asn1.Unmarshal(resp.Response, &r); err != nil {
+				t.Fatalf("in test %v: unable to parse TimeStampResp: %v", label, err)
+			}
+			if r.Status.Status != 0 || len(r.TimeStampToken.FullBytes) == 0 {
+				t.Errorf("in test %v: got status %d, want granted with a token", label, r.Status.Status)
+			}
+		})
+	}
+}
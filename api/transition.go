@@ -0,0 +1,88 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultTransitionRetryDelay is the retry delay hinted to clients if KeyTransitions.RetryDelay or
+// ReadinessGate.RetryDelay is not set.
+const defaultTransitionRetryDelay = time.Second
+
+// KeyTransitions tracks the identifiers of the keys that are being (re)loaded, such as keys being
+// generated. Requests for these keys are rejected with Unavailable and a retry hint, so that clients
+// retry them instead of treating the key as absent.
+type KeyTransitions struct {
+	// RetryDelay is the delay after which clients are told to retry their requests.
+	RetryDelay time.Duration
+
+	mu  sync.RWMutex
+	ids map[string]int
+}
+
+// NewKeyTransitions returns KeyTransitions hinting clients to retry after retryDelay.
+func NewKeyTransitions(retryDelay time.Duration) *KeyTransitions {
+	return &KeyTransitions{RetryDelay: retryDelay, ids: make(map[string]int)}
+}
+
+// Begin marks the key as transitioning until the matching call to End. It is a no-op for a nil KeyTransitions.
+func (k *KeyTransitions) Begin(identifier string) {
+	if k == nil {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.ids[identifier]++
+}
+
+// End ends a transition of the key started by Begin.
+func (k *KeyTransitions) End(identifier string) {
+	if k == nil {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.ids[identifier]--; k.ids[identifier] <= 0 {
+		delete(k.ids, identifier)
+	}
+}
+
+// IsTransitioning returns true if the key is being (re)loaded. It returns false for a nil KeyTransitions.
+func (k *KeyTransitions) IsTransitioning(identifier string) bool {
+	if k == nil {
+		return false
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.ids[identifier] > 0
+}
+
+// checkKeyTransition returns an Unavailable error carrying a RetryInfo detail if the key is being (re)loaded.
+func (s *SigningService) checkKeyTransition(identifier string) error {
+	if !s.Transitions.IsTransitioning(identifier) {
+		return nil
+	}
+	return unavailableWithRetry(s.Transitions.RetryDelay, fmt.Sprintf("key %q is being reloaded", identifier))
+}
+
+// unavailableWithRetry returns an Unavailable error for the reason carrying a RetryInfo detail hinting clients
+// to retry after delay, or after defaultTransitionRetryDelay if delay is not positive.
+func unavailableWithRetry(delay time.Duration, reason string) error {
+	if delay <= 0 {
+		delay = defaultTransitionRetryDelay
+	}
+	st := status.Newf(codes.Unavailable, "Service unavailable: %s, retry after %v", reason, delay)
+	if withRetry, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(delay)}); err == nil {
+		st = withRetry
+	}
+	return st.Err()
+}
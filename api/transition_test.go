@@ -0,0 +1,141 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryDelay returns the delay of the RetryInfo detail of the error, or zero if it has none.
+func retryDelay(t *testing.T, err error) time.Duration {
+	t.Helper()
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			delay, err := ptypes.Duration(info.RetryDelay)
+			if err != nil {
+				t.Fatalf("invalid retry delay: %v", err)
+			}
+			return delay
+		}
+	}
+	return 0
+}
+
+func TestKeyTransition(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		identifier    string
+		transitioning []string
+		ended         bool
+		expectedCode  codes.Code
+	}{
+		"transitioning":       {identifier: "sshuserid1", transitioning: []string{"sshuserid1"}, expectedCode: codes.Unavailable},
+		"transition-ended":    {identifier: "sshuserid1", transitioning: []string{"sshuserid1"}, ended: true, expectedCode: codes.OK},
+		"other-transitioning": {identifier: "sshuserid1", transitioning: []string{"x509id1"}, expectedCode: codes.OK},
+		"nested-transitions":  {identifier: "sshuserid1", transitioning: []string{"sshuserid1", "sshuserid1"}, expectedCode: codes.Unavailable},
+		"absent":              {identifier: "randomid", expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{
+				CertSign:       &mockGoodCertSign{},
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Transitions:    NewKeyTransitions(5 * time.Second),
+			}
+			for _, id := range tt.transitioning {
+				ss.Transitions.Begin(id)
+			}
+			if tt.ended {
+				ss.Transitions.End(tt.identifier)
+			}
+			request := &proto.SSHCertificateSigningRequest{
+				KeyMeta:    &proto.KeyMeta{Identifier: tt.identifier},
+				PublicKey:  testGoodRsaPubKey,
+				Validity:   3600,
+				Principals: []string{"alice"},
+				KeyId:      testGoodKeyID,
+			}
+			_, err := ss.PostUserSSHCertificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if tt.expectedCode == codes.Unavailable {
+				if got := retryDelay(t, err); got != 5*time.Second {
+					t.Errorf("in test %v: got retry delay %v, want 5s", label, got)
+				}
+			}
+		})
+	}
+}
+
+// mockBlockingKeyGenerator blocks the generation of a key until released is closed.
+type mockBlockingKeyGenerator struct {
+	pub      crypto.PublicKey
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (m *mockBlockingKeyGenerator) GenerateKey(params *crypki.KeyGenParams) (crypto.PublicKey, error) {
+	close(m.started)
+	<-m.released
+	return m.pub, nil
+}
+
+func TestSignDuringKeyGeneration(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kg := &mockBlockingKeyGenerator{pub: &key.PublicKey, started: make(chan struct{}), released: make(chan struct{})}
+	ss := &SigningService{
+		CertSign:                &mockGoodCertSign{},
+		KeyIDProcessor:          &crypki.KeyID{},
+		KeyUsages:               combineKeyUsage,
+		AdminIdentities:         map[string]bool{"keygen-admin": true},
+		KeyGenerationIdentities: map[string]bool{"keygen-admin": true},
+		KeyGenerator:            kg,
+		Transitions:             NewKeyTransitions(time.Second),
+	}
+	done := make(chan error)
+	go func() {
+		_, err := ss.GenerateKey(contextWithIdentity("keygen-admin"), &proto.KeyGenerationRequest{
+			KeyMeta:  &proto.KeyMeta{Identifier: "blobid1"},
+			KeyLabel: "newlabel",
+			KeyType:  proto.KeyType_RSA,
+			KeySize:  2048,
+		})
+		done <- err
+	}()
+	<-kg.started
+
+	request := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "blobid1"}, Digest: testSHA256Digest, HashAlgorithm: proto.HashAlgo_SHA256}
+	if _, err := ss.PostSignBlob(context.Background(), request); status.Code(err) != codes.Unavailable {
+		t.Errorf("got err %v during key generation, want Unavailable", err)
+	}
+	close(kg.released)
+	if err := <-done; err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	if _, err := ss.PostSignBlob(context.Background(), request); err != nil {
+		t.Errorf("got err %v after key generation, want nil", err)
+	}
+}
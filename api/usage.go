@@ -0,0 +1,120 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// keyUsage is the usage counters of a single key.
+type keyUsage struct {
+	signs    uint64
+	errors   uint64
+	lastUsed time.Time
+}
+
+// UsageCounters counts the sign operations of each key since the server started,
+// to tell the hot keys from the unused ones.
+type UsageCounters struct {
+	mu   sync.Mutex
+	keys map[string]*keyUsage
+	now  func() time.Time
+}
+
+// NewUsageCounters returns empty UsageCounters.
+func NewUsageCounters() *UsageCounters {
+	return &UsageCounters{keys: make(map[string]*keyUsage), now: time.Now}
+}
+
+// Record counts a sign operation of the key with its result. It is a no-op for nil UsageCounters.
+func (u *UsageCounters) Record(identifier string, err error) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	k, ok := u.keys[identifier]
+	if !ok {
+		k = &keyUsage{}
+		u.keys[identifier] = k
+	}
+	k.signs++
+	if err != nil {
+		k.errors++
+	}
+	k.lastUsed = u.now()
+}
+
+// Stats returns the counters of the keys with the identifiers and of all the keys that were used,
+// sorted by identifier. The keys that were not used have zero counters.
+func (u *UsageCounters) Stats(identifiers []string) []*proto.KeyUsageStats {
+	ids := make(map[string]bool)
+	for _, id := range identifiers {
+		ids[id] = true
+	}
+	stats := make(map[string]*proto.KeyUsageStats)
+	if u != nil {
+		u.mu.Lock()
+		for id, k := range u.keys {
+			ids[id] = true
+			stats[id] = &proto.KeyUsageStats{Identifier: id, Signs: k.signs, Errors: k.errors, LastUsed: k.lastUsed.Unix()}
+		}
+		u.mu.Unlock()
+	}
+	var list []*proto.KeyUsageStats
+	for id := range ids {
+		if _, ok := stats[id]; !ok {
+			stats[id] = &proto.KeyUsageStats{Identifier: id}
+		}
+		list = append(list, stats[id])
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Identifier < list[j].Identifier })
+	return list
+}
+
+// GetKeyUsageStats returns the usage counters of the signing keys, including the unused ones.
+func (s *SigningService) GetKeyUsageStats(ctx context.Context, e *empty.Empty) (*proto.KeyUsageStatsList, error) {
+	const methodName = "GetKeyUsageStats"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,caller=%q,st=%d,et=%d,err="%v"`, methodName, callerIdentity(ctx), statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkAdmin(ctx); err != nil {
+		statusCode = http.StatusForbidden
+		return nil, status.Errorf(codes.PermissionDenied, "Permission denied: %v", err)
+	}
+
+	var identifiers []string
+	for id := range s.Keys {
+		identifiers = append(identifiers, id)
+	}
+	stats := s.Usage.Stats(identifiers)
+	if pug, ok := s.CertSign.(crypki.PoolUsageGetter); ok {
+		for _, k := range stats {
+			inUse, size, err := pug.PoolUsage(k.Identifier)
+			if err != nil {
+				// A key that was used but is unknown to the signer has no pool.
+				continue
+			}
+			k.PoolInUse, k.PoolSize = int32(inUse), int32(size)
+		}
+	}
+	return &proto.KeyUsageStatsList{Keys: stats}, nil
+}
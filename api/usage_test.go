@@ -0,0 +1,111 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockUsageCertSign is a mockGoodCertSign whose key "blobid2" fails to sign, and whose pools have 4 sessions.
+type mockUsageCertSign struct {
+	mockGoodCertSign
+}
+
+func (m *mockUsageCertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	if keyIdentifier == "blobid2" {
+		return nil, errors.New("HSM error")
+	}
+	return m.mockGoodCertSign.Sign(digest, opts, keyIdentifier)
+}
+
+func (m *mockUsageCertSign) PoolUsage(keyIdentifier string) (int, int, error) {
+	if keyIdentifier == "unknown" {
+		return 0, 0, errors.New("unknown key")
+	}
+	return 1, 4, nil
+}
+
+func TestGetKeyUsageStats(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1500000000, 0)
+	usage := NewUsageCounters()
+	usage.now = func() time.Time { return now }
+	ss := &SigningService{
+		CertSign:       &mockUsageCertSign{},
+		KeyIDProcessor: &crypki.KeyID{},
+		KeyUsages: map[string]map[string]bool{
+			config.BlobEndpoint:        {"blobid1": true, "blobid2": true},
+			config.SSHUserCertEndpoint: {"sshuserid1": true},
+		},
+		Keys: map[string]config.KeyConfig{
+			"blobid1": {}, "blobid2": {}, "sshuserid1": {}, "unused": {},
+		},
+		AdminIdentities: map[string]bool{"admin": true},
+		Usage:           usage,
+	}
+	digest := sha256.Sum256([]byte("good"))
+	signBlob := func(id string) {
+		request := &proto.BlobSigningRequest{
+			KeyMeta:       &proto.KeyMeta{Identifier: id},
+			Digest:        base64.StdEncoding.EncodeToString(digest[:]),
+			HashAlgorithm: proto.HashAlgo_SHA256,
+		}
+		ss.PostSignBlob(context.Background(), request)
+	}
+	signSSH := func() {
+		request := &proto.SSHCertificateSigningRequest{
+			KeyMeta:    &proto.KeyMeta{Identifier: "sshuserid1"},
+			PublicKey:  testGoodRsaPubKey,
+			Validity:   3600,
+			Principals: []string{"alice"},
+			KeyId:      testGoodKeyID,
+		}
+		if _, err := ss.PostUserSSHCertificate(context.Background(), request); err != nil {
+			t.Fatalf("unable to sign SSH certificate: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		signBlob("blobid1")
+	}
+	signBlob("blobid2")
+	signBlob("blobid2")
+	signSSH()
+	// Rejected before signing, so not counted.
+	signBlob("unknown")
+
+	if _, err := ss.GetKeyUsageStats(contextWithIdentity("alice"), &empty.Empty{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("got code %v for a non-admin, want %v", status.Code(err), codes.PermissionDenied)
+	}
+	got, err := ss.GetKeyUsageStats(contextWithIdentity("admin"), &empty.Empty{})
+	if err != nil {
+		t.Fatalf("unable to get key usage stats: %v", err)
+	}
+	expected := []*proto.KeyUsageStats{
+		{Identifier: "blobid1", Signs: 3, LastUsed: now.Unix(), PoolInUse: 1, PoolSize: 4},
+		{Identifier: "blobid2", Signs: 2, Errors: 2, LastUsed: now.Unix(), PoolInUse: 1, PoolSize: 4},
+		{Identifier: "sshuserid1", Signs: 1, LastUsed: now.Unix(), PoolInUse: 1, PoolSize: 4},
+		{Identifier: "unused", PoolInUse: 1, PoolSize: 4},
+	}
+	if len(got.Keys) != len(expected) {
+		t.Fatalf("got stats %v, want %v", got.Keys, expected)
+	}
+	for i := range expected {
+		if got.Keys[i].String() != expected[i].String() {
+			t.Errorf("got stats %v, want %v", got.Keys[i], expected[i])
+		}
+	}
+}
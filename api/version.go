@@ -0,0 +1,72 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIVersionMetadataKey is the key of the request metadata carrying the API version of the client.
+// REST clients supply it as the "Grpc-Metadata-X-Crypki-Api-Version" header.
+const APIVersionMetadataKey = "x-crypki-api-version"
+
+// APIVersions configures the range of client API versions that are served.
+// Requests without an API version are always served.
+type APIVersions struct {
+	// Min and Max are the oldest and newest supported API versions. Zero means no bound.
+	Min, Max uint64
+	// WarnOnly specifies whether requests with unsupported API versions are logged and served
+	// instead of being rejected.
+	WarnOnly bool
+}
+
+// check returns an error if version is not a supported API version.
+func (v *APIVersions) check(version string) error {
+	n, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "Bad request: invalid API version %q", version)
+	}
+	if (v.Min != 0 && n < v.Min) || (v.Max != 0 && n > v.Max) {
+		return status.Errorf(codes.FailedPrecondition, "Unsupported API version %d, supported versions are %s", n, v.supported())
+	}
+	return nil
+}
+
+// supported returns the description of the range of supported API versions.
+func (v *APIVersions) supported() string {
+	switch {
+	case v.Max == 0:
+		return strconv.FormatUint(v.Min, 10) + " and later"
+	case v.Min == 0:
+		return strconv.FormatUint(v.Max, 10) + " and earlier"
+	}
+	return strconv.FormatUint(v.Min, 10) + " to " + strconv.FormatUint(v.Max, 10)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor rejecting the requests whose API version,
+// supplied in the APIVersionMetadataKey metadata, is not supported. Unsupported versions return
+// FailedPrecondition with the supported range, or are only logged if WarnOnly is set.
+func (v *APIVersions) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(APIVersionMetadataKey)
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+		if err := v.check(values[0]); err != nil {
+			if !v.WarnOnly {
+				return nil, err
+			}
+			log.Printf(`m=%s,api-version=%q,warn="%v"`, info.FullMethod, values[0], status.Convert(err).Message())
+		}
+		return handler(ctx, req)
+	}
+}
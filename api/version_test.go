@@ -0,0 +1,61 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAPIVersionsUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		versions        APIVersions
+		version         string
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		"supported":             {versions: APIVersions{Min: 2, Max: 3}, version: "2", expectedCode: codes.OK},
+		"no-version":            {versions: APIVersions{Min: 2, Max: 3}, expectedCode: codes.OK},
+		"too-old":               {versions: APIVersions{Min: 2, Max: 3}, version: "1", expectedCode: codes.FailedPrecondition, expectedMessage: "supported versions are 2 to 3"},
+		"too-new":               {versions: APIVersions{Min: 2, Max: 3}, version: "4", expectedCode: codes.FailedPrecondition, expectedMessage: "supported versions are 2 to 3"},
+		"too-old-no-max":        {versions: APIVersions{Min: 2}, version: "1", expectedCode: codes.FailedPrecondition, expectedMessage: "supported versions are 2 and later"},
+		"new-no-max":            {versions: APIVersions{Min: 2}, version: "100", expectedCode: codes.OK},
+		"invalid":               {versions: APIVersions{Min: 2, Max: 3}, version: "v2", expectedCode: codes.InvalidArgument},
+		"unsupported-warn-only": {versions: APIVersions{Min: 2, Max: 3, WarnOnly: true}, version: "1", expectedCode: codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			if tt.version != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(APIVersionMetadataKey, tt.version))
+			}
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return req, nil
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+			_, err := tt.versions.UnaryServerInterceptor()(ctx, "request", info, handler)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if called != (err == nil) {
+				t.Errorf("in test %v: got handler called %v, want %v", label, called, err == nil)
+			}
+			if !strings.Contains(status.Convert(err).Message(), tt.expectedMessage) {
+				t.Errorf("in test %v: got message %q, want it to contain %q", label, status.Convert(err).Message(), tt.expectedMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxWebhookResponseSize is the maximum size of the responses of the mutating webhook.
+const maxWebhookResponseSize = 1 << 20
+
+// webhookName is the JSON encoding of a distinguished name sent to and returned by the mutating webhook.
+type webhookName struct {
+	CommonName         string   `json:"common_name"`
+	Country            []string `json:"country"`
+	Organization       []string `json:"organization"`
+	OrganizationalUnit []string `json:"organizational_unit"`
+	Locality           []string `json:"locality"`
+	Province           []string `json:"province"`
+	StreetAddress      []string `json:"street_address"`
+	PostalCode         []string `json:"postal_code"`
+}
+
+// webhookX509Certificate is the JSON encoding of the fields of a composed x509 certificate that the
+// mutating webhook may modify.
+type webhookX509Certificate struct {
+	Subject        webhookName `json:"subject"`
+	DNSNames       []string    `json:"dns_names"`
+	IPAddresses    []string    `json:"ip_addresses"`
+	EmailAddresses []string    `json:"email_addresses"`
+	URIs           []string    `json:"uris"`
+	// NotBefore and NotAfter are in seconds since the Unix epoch.
+	NotBefore int64 `json:"not_before"`
+	NotAfter  int64 `json:"not_after"`
+}
+
+// webhookInput is the JSON document posted to the mutating webhook.
+type webhookInput struct {
+	// Method is the name of the RPC method, such as "PostX509Certificate".
+	Method string `json:"method"`
+	// Identifier is the identifier of the signing key.
+	Identifier string `json:"identifier"`
+	// Caller is the common name of the client certificate, if any.
+	Caller      string                  `json:"caller"`
+	Certificate *webhookX509Certificate `json:"certificate"`
+}
+
+// webhookOutput is the JSON document returned by the mutating webhook.
+type webhookOutput struct {
+	// Allowed specifies whether the request may be signed.
+	Allowed bool `json:"allowed"`
+	// Message is the reason of a denial returned to the client.
+	Message string `json:"message"`
+	// Certificate is the modified certificate. If nil, the certificate is signed unchanged.
+	Certificate *webhookX509Certificate `json:"certificate"`
+}
+
+// MutatingWebhook posts the x509 certificate composed for each signing request to an external HTTP
+// service, which may deny the request or return a modified certificate, e.g. with an organizational
+// unit added or a shorter validity. The modified certificate is checked again against the profile and
+// the maximum validity of the key, which the webhook cannot override.
+type MutatingWebhook struct {
+	// URL is the URL to which the certificates are posted.
+	URL string
+	// Timeout is the time after which the request to the webhook is canceled and the request fails.
+	Timeout time.Duration
+}
+
+// review posts the input to the webhook and returns its output.
+func (w *MutatingWebhook) review(ctx context.Context, input *webhookInput) (*webhookOutput, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("unable to post to mutating webhook %s: %v", w.URL, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response of mutating webhook %s: %v", w.URL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("mutating webhook %s failed: %s", w.URL, resp.Status)
+	}
+	var output webhookOutput
+	if err := json.Unmarshal(respBody, &output); err != nil {
+		return nil, fmt.Errorf("unable to parse response of mutating webhook %s: %v", w.URL, err)
+	}
+	return &output, nil
+}
+
+// encodeWebhookX509Certificate returns the webhook encoding of the certificate.
+func encodeWebhookX509Certificate(cert *x509.Certificate) *webhookX509Certificate {
+	c := &webhookX509Certificate{
+		Subject: webhookName{
+			CommonName:         cert.Subject.CommonName,
+			Country:            cert.Subject.Country,
+			Organization:       cert.Subject.Organization,
+			OrganizationalUnit: cert.Subject.OrganizationalUnit,
+			Locality:           cert.Subject.Locality,
+			Province:           cert.Subject.Province,
+			StreetAddress:      cert.Subject.StreetAddress,
+			PostalCode:         cert.Subject.PostalCode,
+		},
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		NotBefore:      cert.NotBefore.Unix(),
+		NotAfter:       cert.NotAfter.Unix(),
+	}
+	for _, ip := range cert.IPAddresses {
+		c.IPAddresses = append(c.IPAddresses, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		c.URIs = append(c.URIs, uri.String())
+	}
+	return c
+}
+
+// applyWebhookX509Certificate sets the fields of the certificate to the ones returned by the webhook.
+func applyWebhookX509Certificate(cert *x509.Certificate, c *webhookX509Certificate) error {
+	var ips []net.IP
+	for _, s := range c.IPAddresses {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", s)
+		}
+		ips = append(ips, ip)
+	}
+	var uris []*url.URL
+	for _, s := range c.URIs {
+		uri, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid URI %q: %v", s, err)
+		}
+		uris = append(uris, uri)
+	}
+	// The subject serial number, which the webhook cannot modify, is kept.
+	cert.Subject = pkix.Name{
+		CommonName:         c.Subject.CommonName,
+		Country:            c.Subject.Country,
+		Organization:       c.Subject.Organization,
+		OrganizationalUnit: c.Subject.OrganizationalUnit,
+		Locality:           c.Subject.Locality,
+		Province:           c.Subject.Province,
+		StreetAddress:      c.Subject.StreetAddress,
+		PostalCode:         c.Subject.PostalCode,
+		SerialNumber:       cert.Subject.SerialNumber,
+		ExtraNames:         cert.Subject.ExtraNames,
+	}
+	cert.DNSNames = c.DNSNames
+	cert.IPAddresses = ips
+	cert.EmailAddresses = c.EmailAddresses
+	cert.URIs = uris
+	cert.NotBefore = time.Unix(c.NotBefore, 0)
+	cert.NotAfter = time.Unix(c.NotAfter, 0)
+	return nil
+}
+
+// mutateX509Certificate sends the certificate composed for a request of the method to the mutating webhook,
+// if any, and applies the modifications it returns. It returns a PermissionDenied status if the webhook
+// denies the request, an Unavailable status if it fails, and an InvalidArgument status if the modified
+// certificate violates the policy of the key or is valid longer than maxValidity.
+func (s *SigningService) mutateX509Certificate(ctx context.Context, method, identifier string, cert *x509.Certificate, maxValidity uint64) error {
+	if s.MutatingWebhook == nil {
+		return nil
+	}
+	output, err := s.MutatingWebhook.review(ctx, &webhookInput{
+		Method:      method,
+		Identifier:  identifier,
+		Caller:      callerIdentity(ctx),
+		Certificate: encodeWebhookX509Certificate(cert),
+	})
+	if err != nil {
+		log.Printf("m=%s,id=%q: %v", method, identifier, err)
+		return status.Errorf(codes.Unavailable, "Service unavailable: mutating webhook failed")
+	}
+	if !output.Allowed {
+		return status.Errorf(codes.PermissionDenied, "Permission denied: mutating webhook denied the request: %s", summarize(output.Message))
+	}
+	if output.Certificate == nil {
+		return nil
+	}
+	notBefore := cert.NotBefore
+	if err := applyWebhookX509Certificate(cert, output.Certificate); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Bad request: mutating webhook returned an invalid certificate: %v", err)
+	}
+	if err := s.checkMutatedX509Certificate(identifier, cert, notBefore, maxValidity); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Bad request: certificate modified by the mutating webhook violates policy: %v", err)
+	}
+	return nil
+}
+
+// checkMutatedX509Certificate checks the certificate modified by the mutating webhook against the policy
+// of the key, as the certificates composed from the requests. The webhook may also not backdate the
+// certificate before the notBefore of the composed certificate.
+func (s *SigningService) checkMutatedX509Certificate(identifier string, cert *x509.Certificate, notBefore time.Time, maxValidity uint64) error {
+	if cert.NotBefore.Before(notBefore) {
+		return fmt.Errorf("notBefore %v is earlier than %v", cert.NotBefore.UTC(), notBefore.UTC())
+	}
+	if !cert.NotAfter.After(cert.NotBefore) {
+		return fmt.Errorf("notAfter %v is not after notBefore %v", cert.NotAfter.UTC(), cert.NotBefore.UTC())
+	}
+	// The validity is counted from now as for the requests, without the backdating of notBefore.
+	validity := cert.NotAfter.Unix() - time.Now().Unix()
+	if validity <= 0 {
+		return fmt.Errorf("notAfter %v is in the past", cert.NotAfter.UTC())
+	}
+	if err := checkValidity(uint64(validity), maxValidity); err != nil {
+		return err
+	}
+	if err := s.checkX509Names(cert); err != nil {
+		return err
+	}
+	return checkX509Profile(s.Keys[identifier], cert)
+}
+
+// webhookStatusCode returns the HTTP status code of the status returned by mutateX509Certificate.
+func webhookStatusCode(err error) int {
+	switch status.Code(err) {
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,180 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostX509CertificateMutatingWebhook(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	csr := genCSR(t, key)
+	testcases := map[string]struct {
+		// mutate modifies the certificate received by the webhook and returns its output.
+		mutate     func(c *webhookX509Certificate) *webhookOutput
+		statusCode int
+		// expectedCode is the status code of the request, codes.OK if the certificate is signed.
+		expectedCode codes.Code
+		expectedOUs  []string
+		// expectedValidity is the maximum validity of the certificate from now, if not zero.
+		expectedValidity time.Duration
+	}{
+		"append-ou": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				c.Subject.OrganizationalUnit = append(c.Subject.OrganizationalUnit, "Platform")
+				return &webhookOutput{Allowed: true, Certificate: c}
+			},
+			statusCode:   http.StatusOK,
+			expectedCode: codes.OK,
+			expectedOUs:  []string{"Platform"},
+		},
+		"unchanged": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				return &webhookOutput{Allowed: true}
+			},
+			statusCode:   http.StatusOK,
+			expectedCode: codes.OK,
+		},
+		"clamp-validity": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				c.NotAfter = time.Now().Add(10 * time.Minute).Unix()
+				return &webhookOutput{Allowed: true, Certificate: c}
+			},
+			statusCode:       http.StatusOK,
+			expectedCode:     codes.OK,
+			expectedValidity: 10 * time.Minute,
+		},
+		"extend-validity-beyond-max": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				c.NotAfter = time.Now().Add(48 * time.Hour).Unix()
+				return &webhookOutput{Allowed: true, Certificate: c}
+			},
+			statusCode:   http.StatusOK,
+			expectedCode: codes.InvalidArgument,
+		},
+		"backdate": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				c.NotBefore -= 86400
+				return &webhookOutput{Allowed: true, Certificate: c}
+			},
+			statusCode:   http.StatusOK,
+			expectedCode: codes.InvalidArgument,
+		},
+		"san-outside-profile": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				c.DNSNames = append(c.DNSNames, "www.evil.org")
+				return &webhookOutput{Allowed: true, Certificate: c}
+			},
+			statusCode:   http.StatusOK,
+			expectedCode: codes.InvalidArgument,
+		},
+		"invalid-ip": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				c.IPAddresses = []string{"not an ip"}
+				return &webhookOutput{Allowed: true, Certificate: c}
+			},
+			statusCode:   http.StatusOK,
+			expectedCode: codes.InvalidArgument,
+		},
+		"denied": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				return &webhookOutput{Message: "not today"}
+			},
+			statusCode:   http.StatusOK,
+			expectedCode: codes.PermissionDenied,
+		},
+		"webhook-failure": {
+			mutate: func(c *webhookX509Certificate) *webhookOutput {
+				return &webhookOutput{Allowed: true}
+			},
+			statusCode:   http.StatusInternalServerError,
+			expectedCode: codes.Unavailable,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var input webhookInput
+				if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Certificate == nil {
+					http.Error(w, "bad input", http.StatusBadRequest)
+					return
+				}
+				if input.Method != "PostX509Certificate" || input.Identifier != "x509id1" || input.Certificate.Subject.CommonName != "foo.bar.com" {
+					http.Error(w, "unexpected input", http.StatusBadRequest)
+					return
+				}
+				output := tt.mutate(input.Certificate)
+				w.WriteHeader(tt.statusCode)
+				json.NewEncoder(w).Encode(output)
+			}))
+			defer srv.Close()
+
+			signer := newMockCACertSign(t)
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				MaxValidity:    map[string]uint64{config.X509CertEndpoint: 86400},
+				Keys: map[string]config.KeyConfig{"x509id1": {
+					Identifier:      "x509id1",
+					X509SANTypes:    []string{config.X509SANTypeDNS},
+					X509DNSSuffixes: []string{".bar.com"},
+				}},
+				MutatingWebhook: &MutatingWebhook{URL: srv.URL, Timeout: 5 * time.Second},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: csr, Validity: 3600}
+			resp, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				if len(signer.signed) != 0 {
+					t.Errorf("in test %v: certificate signed despite the error", label)
+				}
+				return
+			}
+			block, _ := pem.Decode([]byte(resp.Cert))
+			if block == nil {
+				t.Fatalf("in test %v: unable to decode certificate PEM", label)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			if !reflect.DeepEqual(cert.Subject.OrganizationalUnit, tt.expectedOUs) {
+				t.Errorf("in test %v: got OUs %q, want %q", label, cert.Subject.OrganizationalUnit, tt.expectedOUs)
+			}
+			if tt.expectedValidity != 0 && cert.NotAfter.After(time.Now().Add(tt.expectedValidity)) {
+				t.Errorf("in test %v: got notAfter %v, want the validity clamped to %v", label, cert.NotAfter, tt.expectedValidity)
+			}
+			if cert.Subject.CommonName != "foo.bar.com" {
+				t.Errorf("in test %v: got common name %q, want foo.bar.com", label, cert.Subject.CommonName)
+			}
+		})
+	}
+}
@@ -5,13 +5,17 @@ package api
 
 import (
 	"context"
+	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/yahoo/crypki/config"
 	"github.com/yahoo/crypki/proto"
 	"github.com/yahoo/crypki/x509cert"
@@ -20,7 +24,8 @@ import (
 )
 
 // GetX509CertificateAvailableSigningKeys returns all available keys that can sign X509 certificates.
-func (s *SigningService) GetX509CertificateAvailableSigningKeys(ctx context.Context, e *empty.Empty) (*proto.KeyMetas, error) {
+// If the filter is not empty, only the keys matching it are returned.
+func (s *SigningService) GetX509CertificateAvailableSigningKeys(ctx context.Context, filter *proto.KeyFilter) (*proto.KeyMetas, error) {
 	const methodName = "GetX509CertificateAvailableSigningKeys"
 	statusCode := http.StatusOK
 	start := time.Now()
@@ -31,11 +36,7 @@ func (s *SigningService) GetX509CertificateAvailableSigningKeys(ctx context.Cont
 	}()
 	defer recoverIfPanicked(methodName)
 
-	var keys []*proto.KeyMeta
-	for id := range s.KeyUsages[config.X509CertEndpoint] {
-		keys = append(keys, &proto.KeyMeta{Identifier: id})
-	}
-	return &proto.KeyMetas{Keys: keys}, nil
+	return &proto.KeyMetas{Keys: s.availableKeys(config.X509CertEndpoint, filter)}, nil
 }
 
 // GetX509CACertificate returns the CA X509 certificate self-signed by the specified key.
@@ -56,26 +57,244 @@ func (s *SigningService) GetX509CACertificate(ctx context.Context, keyMeta *prot
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
+	if err = s.checkKeyTransition(keyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
 	if !s.KeyUsages[config.X509CertEndpoint][keyMeta.Identifier] {
 		statusCode = http.StatusBadRequest
 		err = fmt.Errorf("cannot use key %q for %q", keyMeta.Identifier, config.X509CertEndpoint)
 		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
 	}
 
-	cert, err := s.GetX509CACert(keyMeta.Identifier)
+	cert, err := s.PublicKeyCache.get(cachedX509CA, keyMeta.Identifier, s.GetX509CACert)
 	if err != nil {
 		statusCode = http.StatusInternalServerError
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, s.internalError(err)
 	}
 	return &proto.X509Certificate{Cert: string(cert)}, nil
 }
 
+// ExportX509CACertificatePKCS12 returns the CA X509 certificate chain of the specified key as a PKCS#12 file
+// protected by the password of the request and holding no private key.
+func (s *SigningService) ExportX509CACertificatePKCS12(ctx context.Context, request *proto.X509CACertificatePKCS12Request) (*proto.PKCS12, error) {
+	const methodName = "ExportX509CACertificatePKCS12"
+	statusCode := http.StatusOK
+	start := time.Now()
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,st=%d,et=%d,err="%v"`, methodName, statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if request.KeyMeta == nil {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("keyMeta is empty for %q", config.X509CertEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	if !s.KeyUsages[config.X509CertEndpoint][request.KeyMeta.Identifier] {
+		statusCode = http.StatusBadRequest
+		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.X509CertEndpoint)
+		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+
+	caPEM, err := s.PublicKeyCache.get(cachedX509CA, request.KeyMeta.Identifier, s.GetX509CACert)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(caPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, perr := x509.ParseCertificate(block.Bytes)
+		if perr != nil {
+			statusCode = http.StatusInternalServerError
+			err = fmt.Errorf("unable to parse CA certificate of key %q: %v", request.KeyMeta.Identifier, perr)
+			return nil, s.internalError(err)
+		}
+		certs = append(certs, cert)
+	}
+	p12, err := x509cert.EncodeCertsPKCS12(certs, request.Password)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return &proto.PKCS12{Pkcs12: p12}, nil
+}
+
 // PostX509Certificate signs the given CSR using the specified key and returns a PEM encoded X509 certificate.
 func (s *SigningService) PostX509Certificate(ctx context.Context, request *proto.X509CertificateSigningRequest) (*proto.X509Certificate, error) {
 	const methodName = "PostX509Certificate"
 	statusCode := http.StatusCreated
 	start := time.Now()
 	subject := pkix.Name{}
+	fingerprint := ""
+	serial := ""
+	var err error
+
+	defer func() {
+		log.Printf(`m=%s,sub=%q,sn=%q,fp=%q,st=%d,et=%d,err="%v"`, methodName, subject, s.redact(serial), fingerprint, statusCode, timeElapsedSince(start), err)
+	}()
+	defer recoverIfPanicked(methodName)
+
+	if err = s.checkEndpointEnabled(config.X509CertEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.GetKeyMeta().GetIdentifier()); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	req, issuerChain, code, err := s.checkX509Request(ctx, methodName, request, start)
+	if req != nil {
+		subject = req.Subject
+	}
+	if err != nil {
+		statusCode = code
+		return nil, err
+	}
+
+	if err = s.checkClockDrift(); err != nil {
+		statusCode = http.StatusPreconditionFailed
+		return nil, err
+	}
+	if err = s.checkSigningWindow(request.KeyMeta.Identifier); err != nil {
+		statusCode = runtime.HTTPStatusFromCode(status.Code(err))
+		return nil, err
+	}
+	if err = s.checkBreaker(request.KeyMeta.Identifier); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+	if s.SerialStore != nil {
+		// The serial number is the random one assigned by x509Template, so it is replaced if already reserved.
+		// X509 signing requests cannot carry a serial number of their own, which must never be replaced.
+		if err = x509cert.ReserveSerial(s.SerialStore, request.KeyMeta.Identifier, req, true); err != nil {
+			statusCode = http.StatusServiceUnavailable
+			return nil, status.Errorf(codes.Unavailable, "Service unavailable: unable to reserve serial number")
+		}
+	}
+	if err = s.checkNotBefore(request.KeyMeta.Identifier, req.NotBefore); err != nil {
+		statusCode = http.StatusBadRequest
+		if status.Code(err) == codes.Unavailable {
+			statusCode = http.StatusServiceUnavailable
+		}
+		return nil, err
+	}
+	if logs := s.CTLogs[request.KeyMeta.Identifier]; len(logs) != 0 {
+		var precert, ca []byte
+		precert, ca, err = s.signPrecertificate(ctx, request.KeyMeta.Identifier, req, request.Priority)
+		if err != nil {
+			statusCode = http.StatusInternalServerError
+			return nil, s.internalError(err)
+		}
+		var scts []*x509cert.SCT
+		scts, err = s.submitPrecertificate(ctx, request.KeyMeta.Identifier, precert, ca, logs)
+		if err != nil {
+			statusCode = http.StatusServiceUnavailable
+			return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+		}
+		if len(scts) != 0 {
+			if err = x509cert.EmbedSCTs(req, scts); err != nil {
+				statusCode = http.StatusInternalServerError
+				return nil, s.internalError(err)
+			}
+		}
+	}
+	if req.SerialNumber != nil {
+		serial = req.SerialNumber.String()
+	}
+	data, err := s.prioritized(ctx, request.Priority).SignX509Cert(req, request.KeyMeta.Identifier)
+	s.recordSignResult(request.KeyMeta.Identifier, err)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	fingerprint, err = x509cert.Fingerprint(data)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	resp := &proto.X509Certificate{Cert: string(data), Fingerprint: fingerprint, IssuerChain: encodeX509Chain(issuerChain)}
+	if request.ReturnTbs {
+		if resp.TbsCertificate, err = x509cert.TBSCertificate(data); err != nil {
+			statusCode = http.StatusInternalServerError
+			return nil, s.internalError(err)
+		}
+	}
+	if resp.Receipt, err = s.x509Receipt(request.KeyMeta.Identifier, req, fingerprint); err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	if err = s.storeIssuance(request.KeyMeta.Identifier, issuanceX509, data, serial, fingerprint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+	s.recordX509Issuance(request.KeyMeta.Identifier, req, fingerprint)
+	return resp, nil
+}
+
+// signPrecertificate signs the precertificate of the certificate template with the specified key,
+// and returns the DER encoded precertificate and CA certificate.
+func (s *SigningService) signPrecertificate(ctx context.Context, keyIdentifier string, cert *x509.Certificate, priority proto.Priority) (precert, ca []byte, err error) {
+	caPEM, err := s.PublicKeyCache.get(cachedX509CA, keyIdentifier, s.GetX509CACert)
+	if err != nil {
+		return nil, nil, err
+	}
+	caBlock, _ := pem.Decode(caPEM)
+	if caBlock == nil {
+		return nil, nil, fmt.Errorf("unable to decode CA certificate of key %q", keyIdentifier)
+	}
+	data, err := s.prioritized(ctx, priority).SignX509Cert(x509cert.Precertificate(cert), keyIdentifier)
+	s.recordSignResult(keyIdentifier, err)
+	if err != nil {
+		return nil, nil, err
+	}
+	precertBlock, _ := pem.Decode(data)
+	if precertBlock == nil {
+		return nil, nil, errors.New("unable to decode precertificate")
+	}
+	return precertBlock.Bytes, caBlock.Bytes, nil
+}
+
+// submitPrecertificate submits the precertificate issued by the CA certificate to the CT logs and
+// returns their SCTs. If a log fails to return an SCT, an error is returned, unless the key is
+// configured to issue certificates without the SCTs of failing logs.
+func (s *SigningService) submitPrecertificate(ctx context.Context, keyIdentifier string, precert, ca []byte, logs []*x509cert.CTLog) ([]*x509cert.SCT, error) {
+	var scts []*x509cert.SCT
+	for _, ctLog := range logs {
+		sct, err := ctLog.AddPreChain(ctx, precert, [][]byte{ca})
+		if err != nil {
+			if !s.Keys[keyIdentifier].X509IssueWithoutSCTs {
+				return nil, err
+			}
+			log.Printf("issuing certificate without SCT: %v", err)
+			continue
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
+
+// PreviewX509Certificate returns the certificate that PostX509Certificate would issue for the given CSR,
+// with all the profiles, policies and defaults of the specified key applied, without signing it.
+func (s *SigningService) PreviewX509Certificate(ctx context.Context, request *proto.X509CertificateSigningRequest) (*proto.X509CertificatePreview, error) {
+	const methodName = "PreviewX509Certificate"
+	statusCode := http.StatusOK
+	start := time.Now()
+	subject := pkix.Name{}
 	var err error
 
 	defer func() {
@@ -83,35 +302,269 @@ func (s *SigningService) PostX509Certificate(ctx context.Context, request *proto
 	}()
 	defer recoverIfPanicked(methodName)
 
+	if err = s.checkEndpointEnabled(config.X509CertEndpoint); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, status.Errorf(codes.Unavailable, "Service unavailable: %v", err)
+	}
+
+	if err = s.checkKeyTransition(request.GetKeyMeta().GetIdentifier()); err != nil {
+		statusCode = http.StatusServiceUnavailable
+		return nil, err
+	}
+
+	req, _, code, err := s.checkX509Request(ctx, methodName, request, start)
+	if req != nil {
+		subject = req.Subject
+	}
+	if err != nil {
+		statusCode = code
+		return nil, err
+	}
+
+	ca, err := s.x509CACert(request.KeyMeta.Identifier)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		return nil, s.internalError(err)
+	}
+	return x509cert.Preview(req, ca.Subject), nil
+}
+
+// checkX509Request composes the x509 certificate to be issued for the request by the method, modified by
+// the mutating webhook, if any, and runs all the checks of the request and of the certificate, along with the
+// HTTP status code of the error, if any. It has no side effects, such as reserving the serial number or
+// advancing the notBefore watermark, so that PreviewX509Certificate rejects the requests PostX509Certificate
+// rejects. The certificate is returned along with the error once the CSR is decoded, so that its subject can
+// be logged, and the chain of the issuer selected by the request, if any, once it is checked.
+func (s *SigningService) checkX509Request(ctx context.Context, methodName string, request *proto.X509CertificateSigningRequest, start time.Time) (*x509.Certificate, []*x509.Certificate, int, error) {
+	req, err := s.x509Template(request)
+	if err != nil {
+		return req, nil, http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err := s.RequiredFields.check(config.X509CertEndpoint, req); err != nil {
+		return req, nil, http.StatusBadRequest, err
+	}
+	if err := s.checkSubjectKey(req.PublicKey); err != nil {
+		return req, nil, http.StatusForbidden, err
+	}
+	if err := s.checkHashFloor(request.KeyMeta.Identifier, x509SignatureHash(req.SignatureAlgorithm)); err != nil {
+		return req, nil, http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err := s.mutateX509Certificate(ctx, methodName, request.KeyMeta.Identifier, req, s.x509MaxValidity(request.KeyMeta.Identifier, request.GetProfile())); err != nil {
+		return req, nil, webhookStatusCode(err), err
+	}
+	if err := s.checkCallerDomains(ctx, req); err != nil {
+		return req, nil, http.StatusForbidden, err
+	}
+	if err := s.checkX509Wildcards(request.KeyMeta.Identifier, req); err != nil {
+		return req, nil, http.StatusForbidden, err
+	}
+	if err := s.checkX509OUs(request.KeyMeta.Identifier, req); err != nil {
+		return req, nil, http.StatusForbidden, err
+	}
+	if err := s.checkCAExpiry(request.KeyMeta.Identifier, req); err != nil {
+		return req, nil, runtime.HTTPStatusFromCode(status.Code(err)), err
+	}
+	if err := checkValidityWindow(start, req.NotBefore, req.NotAfter, s.MinValidity[config.X509CertEndpoint]); err != nil {
+		return req, nil, http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	issuerChain, err := s.x509IssuerChain(request.KeyMeta.Identifier, request.Issuer)
+	if err != nil {
+		return req, nil, runtime.HTTPStatusFromCode(status.Code(err)), err
+	}
+	if err := x509cert.CheckSerial(req.SerialNumber); err != nil {
+		return req, nil, http.StatusBadRequest, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+	}
+	if err := s.checkBackdated(request.KeyMeta.Identifier, req.NotBefore); err != nil {
+		return req, nil, http.StatusBadRequest, err
+	}
+	return req, issuerChain, 0, nil
+}
+
+// x509Template decodes the request into the (unsigned) x509 certificate to be signed, and applies the
+// policies, profile and extensions of the specified key. All errors are caused by a bad request.
+// The certificate is returned along with the error once the CSR is decoded, so that its subject can be logged.
+func (s *SigningService) x509Template(request *proto.X509CertificateSigningRequest) (*x509.Certificate, error) {
 	if request.KeyMeta == nil {
-		statusCode = http.StatusBadRequest
-		err = fmt.Errorf("request.keyMeta is empty for %q", config.X509CertEndpoint)
-		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		return nil, fmt.Errorf("request.keyMeta is empty for %q", config.X509CertEndpoint)
 	}
 
-	maxValidity := s.MaxValidity[config.X509CertEndpoint]
+	profile, ok := s.Keys[request.KeyMeta.Identifier].X509Profiles[request.GetProfile()]
+	if !ok && request.GetProfile() != "" {
+		return nil, fmt.Errorf("key %q has no x509 profile %q", request.KeyMeta.Identifier, request.GetProfile())
+	}
+	maxValidity := s.x509MaxValidity(request.KeyMeta.Identifier, request.GetProfile())
+	request.Validity = s.validity(request.KeyMeta.Identifier, request.GetValidity(), maxValidity)
+	if profile.ExcessValidity == config.X509ProfileValidityClamp && profile.MaxValidity != 0 && request.Validity > profile.MaxValidity {
+		request.Validity = profile.MaxValidity
+	}
 	if err := checkValidity(request.GetValidity(), maxValidity); err != nil {
-		statusCode = http.StatusBadRequest
-		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		return nil, err
 	}
 
 	req, err := x509cert.DecodeRequest(request)
 	if err != nil {
-		statusCode = http.StatusBadRequest
-		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		return nil, err
+	}
+	notBefore, notAfter := s.alignValidity(request.KeyMeta.Identifier, uint64(req.NotBefore.Unix()), uint64(req.NotAfter.Unix()))
+	req.NotBefore, req.NotAfter = time.Unix(int64(notBefore), 0), time.Unix(int64(notAfter), 0)
+	if err := s.checkX509Names(req); err != nil {
+		return req, err
 	}
-	subject = req.Subject
 
 	if !s.KeyUsages[config.X509CertEndpoint][request.KeyMeta.Identifier] {
-		statusCode = http.StatusBadRequest
-		err = fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.X509CertEndpoint)
-		return nil, status.Errorf(codes.InvalidArgument, "Bad request: %v", err)
+		return req, fmt.Errorf("cannot use key %q for %q", request.KeyMeta.Identifier, config.X509CertEndpoint)
 	}
 
-	data, err := s.SignX509Cert(req, request.KeyMeta.Identifier)
+	key := s.Keys[request.KeyMeta.Identifier]
+	policy := &x509cert.SubjectKeyPolicy{
+		KeyTypes:        key.X509SubjectKeyTypes,
+		MinRSAKeySize:   key.MinX509SubjectRSAKeySize,
+		MinECDSAKeySize: key.MinX509SubjectECDSAKeySize,
+	}
+	if err := policy.Check(req.PublicKey); err != nil {
+		return req, err
+	}
+	allowedExtensions, err := key.X509AllowedExtensionOIDs()
 	if err != nil {
-		statusCode = http.StatusInternalServerError
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return req, err
+	}
+	extensions := &x509cert.ExtensionPolicy{
+		AllowedOIDs:  allowedExtensions,
+		MaxSize:      key.X509MaxExtensionSize,
+		MaxTotalSize: key.X509MaxExtensionsSize,
+	}
+	if err := extensions.Apply(req); err != nil {
+		return req, err
+	}
+	if err := addX509CertificatePolicies(key, request.GetProfile(), req); err != nil {
+		return req, err
+	}
+	if err := checkX509Profile(key, req); err != nil {
+		return req, err
+	}
+	requiredEKUs, forbiddenEKUs, err := key.X509ExtKeyUsagePolicy()
+	if err != nil {
+		return req, err
+	}
+	if len(request.GetExtKeyUsage()) == 0 && len(requiredEKUs) != 0 {
+		// The required extended key usages replace the default ones.
+		req.ExtKeyUsage = nil
+	}
+	ekus := &x509cert.ExtKeyUsagePolicy{Required: requiredEKUs, Forbidden: forbiddenEKUs}
+	if err := ekus.Apply(req); err != nil {
+		return req, err
+	}
+	req.CRLDistributionPoints = key.X509CRLDistributionPoints
+	req.OCSPServer = key.X509OCSPServers
+	req.IssuingCertificateURL = key.X509IssuingCertificateURLs
+	maxPathLen := key.X509MaxPathLen
+	if v := request.GetMaxPathLen(); v != nil {
+		if !key.X509IssueCACerts {
+			return req, fmt.Errorf("key %q does not issue CA certificates with a path length", request.KeyMeta.Identifier)
+		}
+		if uint64(v.Value) > uint64(key.X509MaxPathLen) {
+			return req, fmt.Errorf("path length %d exceeds the maximum %d of key %q", v.Value, key.X509MaxPathLen, request.KeyMeta.Identifier)
+		}
+		maxPathLen = int(v.Value)
+	}
+	if key.X509IssueCACerts {
+		permittedIPs, excludedIPs, err := key.X509NameConstraintIPRanges()
+		if err != nil {
+			return req, err
+		}
+		x509cert.MakeIntermediate(req, &x509cert.NameConstraints{
+			PermittedDNSDomains: key.X509PermittedDNSDomains,
+			ExcludedDNSDomains:  key.X509ExcludedDNSDomains,
+			PermittedIPRanges:   permittedIPs,
+			ExcludedIPRanges:    excludedIPs,
+		}, maxPathLen)
+	}
+	return req, nil
+}
+
+// x509MaxValidity returns the maximum validity of the x509 certificates of the profile of the key, which
+// overrides the one of the endpoint if specified.
+func (s *SigningService) x509MaxValidity(identifier, profile string) uint64 {
+	if v := s.Keys[identifier].X509Profiles[profile].MaxValidity; v != 0 {
+		return v
+	}
+	return s.MaxValidity[config.X509CertEndpoint]
+}
+
+// checkX509Names deduplicates the subject alternative names of the certificate, and returns an error
+// if there were duplicates and the x509 endpoint rejects them.
+func (s *SigningService) checkX509Names(cert *x509.Certificate) error {
+	if dups := x509cert.DedupSANs(cert); len(dups) != 0 && s.RejectDuplicateNames[config.X509CertEndpoint] {
+		return fmt.Errorf("duplicate subject alternative names: %q", dups)
+	}
+	return nil
+}
+
+// addX509CertificatePolicies adds the certificate policies of the profile of the key, or of the key if the
+// profile has none, to the certificate.
+func addX509CertificatePolicies(key config.KeyConfig, profile string, cert *x509.Certificate) error {
+	configured := key.X509CertificatePolicies
+	if p := key.X509Profiles[profile].CertificatePolicies; len(p) != 0 {
+		configured = p
+	}
+	var policies []x509cert.CertificatePolicy
+	for _, p := range configured {
+		oid, err := p.PolicyOID()
+		if err != nil {
+			return err
+		}
+		policies = append(policies, x509cert.CertificatePolicy{OID: oid, CPSURIs: p.CPSURIs})
+	}
+	return x509cert.AddCertificatePolicies(cert, policies)
+}
+
+// checkX509Wildcards returns PermissionDenied if the key forbids wildcards and a DNS SAN or the subject
+// common name of the certificate is a wildcard, once the names of the certificate are final.
+func (s *SigningService) checkX509Wildcards(identifier string, cert *x509.Certificate) error {
+	if !s.Keys[identifier].X509ForbidWildcards {
+		return nil
+	}
+	for _, name := range append([]string{cert.Subject.CommonName}, cert.DNSNames...) {
+		if strings.Contains(name, "*") {
+			return status.Errorf(codes.PermissionDenied, "Permission denied: key %q does not issue wildcard name %q", identifier, name)
+		}
+	}
+	return nil
+}
+
+// checkX509OUs returns PermissionDenied if the key restricts the subject organizational units and an OU
+// of the certificate is not allowed, once the subject of the certificate is final.
+func (s *SigningService) checkX509OUs(identifier string, cert *x509.Certificate) error {
+	allowedOUs := s.Keys[identifier].X509AllowedOUs
+	if len(allowedOUs) == 0 {
+		return nil
+	}
+	allowed := make(map[string]bool, len(allowedOUs))
+	for _, ou := range allowedOUs {
+		allowed[ou] = true
+	}
+	for _, ou := range cert.Subject.OrganizationalUnit {
+		if !allowed[ou] {
+			return status.Errorf(codes.PermissionDenied, "Permission denied: key %q does not issue subject OU %q", identifier, ou)
+		}
+	}
+	return nil
+}
+
+// checkX509Profile applies the common name SAN rules of the profile of the key to the certificate,
+// and returns an error if the names of the certificate are not allowed by the profile.
+func checkX509Profile(key config.KeyConfig, cert *x509.Certificate) error {
+	profile := &x509cert.Profile{
+		SANTypes:             key.X509SANTypes,
+		DNSSuffixes:          key.X509DNSSuffixes,
+		ValidateDNSNames:     key.X509ValidateDNSNames,
+		AllowWildcards:       key.X509AllowWildcardDNSNames,
+		AllowUnderscores:     key.X509AllowDNSUnderscores,
+		RequireCommonNameSAN: key.X509RequireCommonNameSAN,
+		AddCommonNameSAN:     key.X509AddCommonNameSAN,
+	}
+	if err := profile.ApplyCommonNameSAN(cert); err != nil {
+		return err
 	}
-	return &proto.X509Certificate{Cert: string(data)}, nil
+	return profile.Check(cert)
 }
@@ -3,14 +3,35 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/yahoo/crypki"
 	"github.com/yahoo/crypki/config"
 	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/x509cert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetX509CertificateAvailableSigningKeys(t *testing.T) {
@@ -52,8 +73,8 @@ func TestGetX509CertificateAvailableSigningKeys(t *testing.T) {
 			mssp := mockSigningServiceParam{KeyUsages: tt.KeyUsages, sendError: false}
 			ss := initMockSigningService(mssp)
 			var ctx context.Context
-			var e *empty.Empty
-			keyMetas, err := ss.GetX509CertificateAvailableSigningKeys(ctx, e)
+			var filter *proto.KeyFilter
+			keyMetas, err := ss.GetX509CertificateAvailableSigningKeys(ctx, filter)
 			if err != nil {
 				t.Fatal(err)
 				return
@@ -145,9 +166,26 @@ func TestGetX509CACertificate(t *testing.T) {
 	}
 }
 
+// genCSR returns a PEM encoded CSR for the public key of the signer.
+func genCSR(t *testing.T, key crypto.Signer) string {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "foo.bar.com"}}, key)
+	if err != nil {
+		t.Fatalf("unable to create CSR: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
 func TestPostX509Certificate(t *testing.T) {
 	t.Parallel()
 	defaultMaxValidity := map[string]uint64{config.X509CertEndpoint: 0}
+	weakRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
 	testcases := map[string]struct {
 		KeyUsages   map[string]map[string]bool
 		maxValidity map[string]uint64
@@ -182,7 +220,7 @@ func TestPostX509Certificate(t *testing.T) {
 			maxValidity:  defaultMaxValidity,
 			validity:     3600,
 			KeyMeta:      &proto.KeyMeta{Identifier: "x509id"},
-			expectedCert: &proto.X509Certificate{Cert: "good x509 cert"},
+			expectedCert: &proto.X509Certificate{Cert: testGoodX509Cert, Fingerprint: testGoodX509CertFingerprint},
 			CSR:          testGoodcsrRsa,
 		},
 		"x509KeyUsagesWithRightIdAndEcdsaCsr": {
@@ -190,9 +228,25 @@ func TestPostX509Certificate(t *testing.T) {
 			maxValidity:  defaultMaxValidity,
 			validity:     3600,
 			KeyMeta:      &proto.KeyMeta{Identifier: "x509id"},
-			expectedCert: &proto.X509Certificate{Cert: "good x509 cert"},
+			expectedCert: &proto.X509Certificate{Cert: testGoodX509Cert, Fingerprint: testGoodX509CertFingerprint},
 			CSR:          testGoodcsrEc,
 		},
+		"x509KeyUsagesWithRightIdAndWeakRsaCsr": {
+			KeyUsages:    x509keyUsage,
+			maxValidity:  defaultMaxValidity,
+			validity:     3600,
+			KeyMeta:      &proto.KeyMeta{Identifier: "x509id"},
+			expectedCert: nil,
+			CSR:          genCSR(t, weakRSAKey),
+		},
+		"x509KeyUsagesWithRightIdAndP256Csr": {
+			KeyUsages:    x509keyUsage,
+			maxValidity:  defaultMaxValidity,
+			validity:     3600,
+			KeyMeta:      &proto.KeyMeta{Identifier: "x509id"},
+			expectedCert: &proto.X509Certificate{Cert: testGoodX509Cert, Fingerprint: testGoodX509CertFingerprint},
+			CSR:          genCSR(t, p256Key),
+		},
 		"sshKeyUsages": {
 			KeyUsages:    sshkeyUsage,
 			maxValidity:  defaultMaxValidity,
@@ -214,7 +268,7 @@ func TestPostX509Certificate(t *testing.T) {
 			maxValidity:  defaultMaxValidity,
 			validity:     3600,
 			KeyMeta:      &proto.KeyMeta{Identifier: "x509id1"},
-			expectedCert: &proto.X509Certificate{Cert: "good x509 cert"},
+			expectedCert: &proto.X509Certificate{Cert: testGoodX509Cert, Fingerprint: testGoodX509CertFingerprint},
 			CSR:          testGoodcsrRsa,
 		},
 		"combineKeyUsagesWithFalseIdSet": {
@@ -230,7 +284,7 @@ func TestPostX509Certificate(t *testing.T) {
 			maxValidity:  map[string]uint64{config.X509CertEndpoint: 3600},
 			validity:     3600,
 			KeyMeta:      &proto.KeyMeta{Identifier: "x509id1"},
-			expectedCert: &proto.X509Certificate{Cert: "good x509 cert"},
+			expectedCert: &proto.X509Certificate{Cert: testGoodX509Cert, Fingerprint: testGoodX509CertFingerprint},
 			CSR:          testGoodcsrRsa,
 		},
 		"missing validity": {
@@ -284,3 +338,1156 @@ func TestPostX509Certificate(t *testing.T) {
 		})
 	}
 }
+
+func TestPostX509CertificateDuplicateSANs(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	template := &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "foo.bar.com"},
+		DNSNames: []string{"foo.bar.com", "Foo.Bar.COM", "bar.foo.com"},
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatalf("unable to create CSR: %v", err)
+	}
+	request := &proto.X509CertificateSigningRequest{
+		KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+		Csr:      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+		Validity: 3600,
+	}
+	testcases := map[string]struct {
+		reject       bool
+		expectedCode codes.Code
+	}{
+		"dedup":  {reject: false, expectedCode: codes.OK},
+		"reject": {reject: true, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:             signer,
+				KeyIDProcessor:       &crypki.KeyID{},
+				KeyUsages:            combineKeyUsage,
+				RejectDuplicateNames: map[string]bool{config.X509CertEndpoint: tt.reject},
+			}
+			_, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				if signer.x509Cert != nil {
+					t.Errorf("in test %v: certificate with duplicate SANs was signed", label)
+				}
+				return
+			}
+			want := []string{"foo.bar.com", "bar.foo.com"}
+			if !reflect.DeepEqual(signer.x509Cert.DNSNames, want) {
+				t.Errorf("in test %v: got DNS names %q, want %q", label, signer.x509Cert.DNSNames, want)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateProfile(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	newCSR := func(template *x509.CertificateRequest) string {
+		der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+		if err != nil {
+			t.Fatalf("unable to create CSR: %v", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	}
+	subject := pkix.Name{CommonName: "foo.example.com", Organization: []string{"Example"}}
+	testcases := map[string]struct {
+		csr              string
+		expectedCode     codes.Code
+		expectedDNSNames []string
+	}{
+		"good": {
+			csr:              newCSR(&x509.CertificateRequest{Subject: subject, DNSNames: []string{"foo.example.com", "Bar.Example.com"}}),
+			expectedCode:     codes.OK,
+			expectedDNSNames: []string{"foo.example.com", "bar.example.com"},
+		},
+		"forbidden-dns-name": {
+			csr:          newCSR(&x509.CertificateRequest{Subject: subject, DNSNames: []string{"foo.example.com", "foo.bar.com"}}),
+			expectedCode: codes.InvalidArgument,
+		},
+		"malformed-dns-name": {
+			csr:          newCSR(&x509.CertificateRequest{Subject: subject, DNSNames: []string{"foo_bar.example.com"}}),
+			expectedCode: codes.InvalidArgument,
+		},
+		"forbidden-ip-san": {
+			csr:          newCSR(&x509.CertificateRequest{Subject: subject, IPAddresses: []net.IP{net.ParseIP("10.1.2.3")}}),
+			expectedCode: codes.InvalidArgument,
+		},
+		"forbidden-common-name": {
+			csr:          newCSR(&x509.CertificateRequest{Subject: pkix.Name{CommonName: "foo.bar.com"}}),
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys: map[string]config.KeyConfig{
+					"x509id1": {
+						Identifier:           "x509id1",
+						X509SANTypes:         []string{config.X509SANTypeDNS},
+						X509DNSSuffixes:      []string{".example.com"},
+						X509ValidateDNSNames: true,
+					},
+				},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: tt.csr, Validity: 3600}
+			_, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				if signer.x509Cert != nil {
+					t.Errorf("in test %v: certificate forbidden by the profile was signed", label)
+				}
+				return
+			}
+			leaf := signer.x509Cert
+			if leaf.Subject.CommonName != subject.CommonName || !reflect.DeepEqual(leaf.Subject.Organization, subject.Organization) {
+				t.Errorf("in test %v: got subject %v, want %v", label, leaf.Subject, subject)
+			}
+			if !reflect.DeepEqual(leaf.DNSNames, tt.expectedDNSNames) {
+				t.Errorf("in test %v: got DNS names %q, want %q", label, leaf.DNSNames, tt.expectedDNSNames)
+			}
+			if leaf.IsCA {
+				t.Errorf("in test %v: leaf certificate must not be a CA", label)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateCommonNameSAN(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "foo.example.com"},
+		DNSNames: []string{"bar.example.com"},
+	}, key)
+	if err != nil {
+		t.Fatalf("unable to create CSR: %v", err)
+	}
+	csr := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	testcases := map[string]struct {
+		key              config.KeyConfig
+		expectedCode     codes.Code
+		expectedDNSNames []string
+	}{
+		"disabled": {config.KeyConfig{Identifier: "x509id1"}, codes.OK, []string{"bar.example.com"}},
+		"reject":   {config.KeyConfig{Identifier: "x509id1", X509RequireCommonNameSAN: true}, codes.InvalidArgument, nil},
+		"add":      {config.KeyConfig{Identifier: "x509id1", X509AddCommonNameSAN: true}, codes.OK, []string{"bar.example.com", "foo.example.com"}},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": tt.key},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: csr, Validity: 3600}
+			_, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(signer.x509Cert.DNSNames, tt.expectedDNSNames) {
+				t.Errorf("in test %v: got DNS names %q, want %q", label, signer.x509Cert.DNSNames, tt.expectedDNSNames)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateRevocationInfo(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	testcases := map[string]struct {
+		key          config.KeyConfig
+		expectedCRLs []string
+		expectedOCSP []string
+		expectedCAs  []string
+	}{
+		"configured": {
+			key: config.KeyConfig{
+				Identifier:                 "x509id1",
+				X509CRLDistributionPoints:  []string{"http://crl.example.com/ca.crl"},
+				X509OCSPServers:            []string{"http://ocsp.example.com"},
+				X509IssuingCertificateURLs: []string{"http://ca.example.com/ca.crt"},
+			},
+			expectedCRLs: []string{"http://crl.example.com/ca.crl"},
+			expectedOCSP: []string{"http://ocsp.example.com"},
+			expectedCAs:  []string{"http://ca.example.com/ca.crt"},
+		},
+		"unconfigured": {
+			key: config.KeyConfig{Identifier: "x509id1"},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": tt.key},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: genCSR(t, key), Validity: 3600}
+			if _, err := ss.PostX509Certificate(context.Background(), request); err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			// Sign the certificate passed to the signer and check the extensions of the parsed certificate.
+			der, err := x509.CreateCertificate(rand.Reader, signer.x509Cert, signer.x509Cert, &key.PublicKey, key)
+			if err != nil {
+				t.Fatalf("in test %v: unable to create certificate: %v", label, err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			if !reflect.DeepEqual(cert.CRLDistributionPoints, tt.expectedCRLs) {
+				t.Errorf("in test %v: got CRL distribution points %q, want %q", label, cert.CRLDistributionPoints, tt.expectedCRLs)
+			}
+			if !reflect.DeepEqual(cert.OCSPServer, tt.expectedOCSP) {
+				t.Errorf("in test %v: got OCSP servers %q, want %q", label, cert.OCSPServer, tt.expectedOCSP)
+			}
+			if !reflect.DeepEqual(cert.IssuingCertificateURL, tt.expectedCAs) {
+				t.Errorf("in test %v: got issuing certificate URLs %q, want %q", label, cert.IssuingCertificateURL, tt.expectedCAs)
+			}
+		})
+	}
+}
+
+// mockSerialStore is a crypki.SerialStore that records the reserved serial numbers, or fails with err.
+type mockSerialStore struct {
+	reserved []*big.Int
+	err      error
+}
+
+func (m *mockSerialStore) Reserve(keyIdentifier string, serial *big.Int) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.reserved = append(m.reserved, serial)
+	return nil
+}
+
+func TestPostX509CertificateSerialStore(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		store        *mockSerialStore
+		expectedCode codes.Code
+	}{
+		"reserved":      {&mockSerialStore{}, codes.OK},
+		"store-failure": {&mockSerialStore{err: errors.New("connection refused")}, codes.Unavailable},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{CertSign: signer, KeyIDProcessor: &crypki.KeyID{}, KeyUsages: combineKeyUsage, SerialStore: tt.store}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600}
+			_, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				if signer.x509Cert != nil {
+					t.Errorf("in test %v: certificate signed without a reserved serial number", label)
+				}
+				return
+			}
+			if len(tt.store.reserved) != 1 || tt.store.reserved[0] != signer.x509Cert.SerialNumber {
+				t.Errorf("in test %v: signed serial number %v, reserved %v", label, signer.x509Cert.SerialNumber, tt.store.reserved)
+			}
+		})
+	}
+}
+
+// mockCACertSign signs x509 certificates with the key of a self-signed CA, and records them.
+type mockCACertSign struct {
+	mockGoodCertSign
+	ca     *x509.Certificate
+	key    *ecdsa.PrivateKey
+	signed []*x509.Certificate
+}
+
+func newMockCACertSign(t *testing.T) *mockCACertSign {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &mockCACertSign{ca: ca, key: key}
+}
+
+func (m *mockCACertSign) GetX509CACert(keyIdentifier string) ([]byte, error) {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: m.ca.Raw}), nil
+}
+
+func (m *mockCACertSign) SignX509Cert(cert *x509.Certificate, keyIdentifier string) ([]byte, error) {
+	m.signed = append(m.signed, cert)
+	// Sign with the algorithm of the CA key rather than the one picked for the HSM key.
+	tmpl := *cert
+	tmpl.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, m.ca, cert.PublicKey, m.key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+func (m *mockCACertSign) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, m.key, digest)
+}
+
+func TestPostX509CertificateCTLogFailure(t *testing.T) {
+	t.Parallel()
+	logKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logKeyDER, _ := x509.MarshalPKIXPublicKey(&logKey.PublicKey)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	ctLog, err := x509cert.NewCTLog(srv.URL, logKeyDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := map[string]struct {
+		issueWithoutSCTs bool
+		expectedCode     codes.Code
+	}{
+		"fail":              {false, codes.Unavailable},
+		"issue-without-sct": {true, codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": {Identifier: "x509id1", X509IssueWithoutSCTs: tt.issueWithoutSCTs}},
+				CTLogs:         map[string][]*x509cert.CTLog{"x509id1": {ctLog}},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600}
+			_, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if len(signer.signed) == 0 || !hasExtension(signer.signed[0], "1.3.6.1.4.1.11129.2.4.3") {
+				t.Fatalf("in test %v: precertificate not signed", label)
+			}
+			if err != nil {
+				if len(signer.signed) != 1 {
+					t.Errorf("in test %v: certificate signed without SCT", label)
+				}
+				return
+			}
+			if len(signer.signed) != 2 {
+				t.Fatalf("in test %v: got %d signed certificates, want 2", label, len(signer.signed))
+			}
+			final := signer.signed[1]
+			if hasExtension(final, "1.3.6.1.4.1.11129.2.4.3") || hasExtension(final, "1.3.6.1.4.1.11129.2.4.2") {
+				t.Errorf("in test %v: certificate has unexpected CT extensions: %v", label, final.ExtraExtensions)
+			}
+			if final.SerialNumber.Cmp(signer.signed[0].SerialNumber) != 0 {
+				t.Errorf("in test %v: certificate serial %v differs from precertificate serial %v", label, final.SerialNumber, signer.signed[0].SerialNumber)
+			}
+		})
+	}
+}
+
+// hasExtension reports whether the certificate template has the extra extension with the OID.
+func hasExtension(cert *x509.Certificate, oid string) bool {
+	for _, ext := range cert.ExtraExtensions {
+		if ext.Id.String() == oid {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPreviewX509Certificate(t *testing.T) {
+	t.Parallel()
+	key := config.KeyConfig{
+		Identifier:                 "x509id1",
+		X509CRLDistributionPoints:  []string{"http://crl.example.com/ca.crl"},
+		X509OCSPServers:            []string{"http://ocsp.example.com"},
+		X509IssuingCertificateURLs: []string{"http://ca.example.com/ca.crt"},
+	}
+	testcases := map[string]struct {
+		keyMeta      *proto.KeyMeta
+		extKeyUsage  []int32
+		issuer       string
+		expectedEKUs []int32
+		expectedCode codes.Code
+	}{
+		"default-ext-key-usage": {
+			keyMeta:      &proto.KeyMeta{Identifier: "x509id1"},
+			expectedEKUs: []int32{int32(x509.ExtKeyUsageClientAuth), int32(x509.ExtKeyUsageServerAuth)},
+			expectedCode: codes.OK,
+		},
+		"requested-ext-key-usage": {
+			keyMeta:      &proto.KeyMeta{Identifier: "x509id1"},
+			extKeyUsage:  []int32{int32(x509.ExtKeyUsageCodeSigning)},
+			expectedEKUs: []int32{int32(x509.ExtKeyUsageCodeSigning)},
+			expectedCode: codes.OK,
+		},
+		"bad-ext-key-usage": {
+			keyMeta:      &proto.KeyMeta{Identifier: "x509id1"},
+			extKeyUsage:  []int32{42},
+			expectedCode: codes.InvalidArgument,
+		},
+		"bad-identifier": {
+			keyMeta:      &proto.KeyMeta{Identifier: "randomid"},
+			expectedCode: codes.InvalidArgument,
+		},
+		"missing-key-meta": {
+			expectedCode: codes.InvalidArgument,
+		},
+		// As PostX509Certificate, the preview checks the issuer selected by the request.
+		"unknown-issuer": {
+			keyMeta:      &proto.KeyMeta{Identifier: "x509id1"},
+			issuer:       "CN=Unknown Root",
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": key},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: tt.keyMeta, Csr: testGoodcsrRsa, Validity: 3600, ExtKeyUsage: tt.extKeyUsage, Issuer: tt.issuer}
+			preview, err := ss.PreviewX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if len(signer.signed) != 0 {
+				t.Errorf("in test %v: preview signed a certificate", label)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(preview.ExtKeyUsage, tt.expectedEKUs) {
+				t.Errorf("in test %v: got ExtKeyUsage %v, want %v", label, preview.ExtKeyUsage, tt.expectedEKUs)
+			}
+			if want := []string{"DigitalSignature", "KeyEncipherment"}; !reflect.DeepEqual(preview.KeyUsage, want) {
+				t.Errorf("in test %v: got KeyUsage %v, want %v", label, preview.KeyUsage, want)
+			}
+			if preview.Issuer != signer.ca.Subject.String() {
+				t.Errorf("in test %v: got issuer %q, want %q", label, preview.Issuer, signer.ca.Subject)
+			}
+			if got := preview.NotAfter - preview.NotBefore; got != 3600+3600 {
+				t.Errorf("in test %v: got validity period %d, want the requested validity plus the one hour backdate", label, got)
+			}
+			if !reflect.DeepEqual(preview.CrlDistributionPoints, key.X509CRLDistributionPoints) ||
+				!reflect.DeepEqual(preview.OcspServers, key.X509OCSPServers) ||
+				!reflect.DeepEqual(preview.IssuingCertificateUrls, key.X509IssuingCertificateURLs) {
+				t.Errorf("in test %v: key extensions not applied: %+v", label, preview)
+			}
+			if preview.Subject == "" || preview.PublicKeyAlgorithm != "RSA" {
+				t.Errorf("in test %v: bad subject or public key algorithm: %+v", label, preview)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateReturnTBS(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		returnTBS bool
+	}{
+		"tbs-returned":     {returnTBS: true},
+		"tbs-not-returned": {returnTBS: false},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": {Identifier: "x509id1"}},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600, ReturnTbs: tt.returnTBS}
+			resp, err := ss.PostX509Certificate(context.Background(), request)
+			if err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			if !tt.returnTBS {
+				if resp.TbsCertificate != nil {
+					t.Errorf("in test %v: TBSCertificate returned without being requested", label)
+				}
+				return
+			}
+			block, _ := pem.Decode([]byte(resp.Cert))
+			if block == nil {
+				t.Fatalf("in test %v: unable to decode certificate PEM", label)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			if !reflect.DeepEqual(resp.TbsCertificate, cert.RawTBSCertificate) {
+				t.Errorf("in test %v: returned TBSCertificate doesn't match the certificate", label)
+			}
+			digest := sha256.Sum256(resp.TbsCertificate)
+			if !ecdsa.VerifyASN1(&signer.key.PublicKey, digest[:], cert.Signature) {
+				t.Errorf("in test %v: signature doesn't verify over the returned TBSCertificate", label)
+			}
+		})
+	}
+}
+
+func TestExportX509CACertificatePKCS12(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		keyMeta *proto.KeyMeta
+		// expectedCode is the status code of the request, codes.OK if the CA certificate is exported.
+		expectedCode codes.Code
+	}{
+		"exported": {
+			keyMeta:      &proto.KeyMeta{Identifier: "x509id1"},
+			expectedCode: codes.OK,
+		},
+		"emptyKeyMeta": {
+			expectedCode: codes.InvalidArgument,
+		},
+		"keyNotAllowed": {
+			keyMeta:      &proto.KeyMeta{Identifier: "x509id2"},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := newMockCACertSign(t)
+			ss := &SigningService{CertSign: signer, KeyUsages: combineKeyUsage}
+			resp, err := ss.ExportX509CACertificatePKCS12(context.Background(), &proto.X509CACertificatePKCS12Request{KeyMeta: tt.keyMeta, Password: "changeit"})
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			// The file decodes with the password of the request and holds the CA certificate only.
+			certs, err := x509cert.DecodeCertsPKCS12(resp.Pkcs12, "changeit")
+			if err != nil {
+				t.Fatalf("in test %v: unable to decode PKCS#12 file: %v", label, err)
+			}
+			if len(certs) != 1 || !certs[0].Equal(signer.ca) {
+				t.Errorf("in test %v: got %d certificates, want the CA certificate", label, len(certs))
+			}
+			if _, err := x509cert.DecodeCertsPKCS12(resp.Pkcs12, "wrong"); err == nil {
+				t.Errorf("in test %v: PKCS#12 file decoded with the wrong password", label)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateCustomExtensions(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	allowed := pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}, Value: []byte{0x0c, 0x03, 'f', 'o', 'o'}}
+	other := pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 2}, Value: []byte{0x05, 0x00}}
+	testcases := map[string]struct {
+		extensions   []pkix.Extension
+		expectedCode codes.Code
+	}{
+		"allowed-extension": {
+			extensions:   []pkix.Extension{allowed},
+			expectedCode: codes.OK,
+		},
+		"extension-not-allowed": {
+			extensions:   []pkix.Extension{allowed, other},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+				Subject:         pkix.Name{CommonName: "foo.bar.com"},
+				DNSNames:        []string{"foo.bar.com"},
+				ExtraExtensions: tt.extensions,
+			}, key)
+			if err != nil {
+				t.Fatalf("in test %v: unable to create CSR: %v", label, err)
+			}
+			csr := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+			signer := newMockCACertSign(t)
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys: map[string]config.KeyConfig{"x509id1": {
+					Identifier:            "x509id1",
+					X509AllowedExtensions: []string{"1.3.6.1.4.1.99999.1"},
+				}},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: csr, Validity: 3600}
+			resp, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			block, _ := pem.Decode([]byte(resp.Cert))
+			if block == nil {
+				t.Fatalf("in test %v: unable to decode certificate PEM", label)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			found := false
+			for _, ext := range cert.Extensions {
+				if ext.Id.Equal(allowed.Id) && bytes.Equal(ext.Value, allowed.Value) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("in test %v: allowed extension missing from the certificate", label)
+			}
+			if !reflect.DeepEqual(cert.DNSNames, []string{"foo.bar.com"}) {
+				t.Errorf("in test %v: got DNS names %q, want the ones of the CSR", label, cert.DNSNames)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateIntermediate(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		key config.KeyConfig
+	}{
+		"leaf": {
+			key: config.KeyConfig{Identifier: "x509id1"},
+		},
+		"intermediate": {
+			key: config.KeyConfig{Identifier: "x509id1", X509IssueCACerts: true},
+		},
+		"intermediate-name-constraints": {
+			key: config.KeyConfig{
+				Identifier:              "x509id1",
+				X509IssueCACerts:        true,
+				X509PermittedDNSDomains: []string{".example.com"},
+				X509ExcludedDNSDomains:  []string{".internal.example.com"},
+				X509PermittedIPRanges:   []string{"10.0.0.0/8"},
+				X509ExcludedIPRanges:    []string{"10.1.0.0/16"},
+			},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{
+				CertSign:       newMockCACertSign(t),
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": tt.key},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600}
+			resp, err := ss.PostX509Certificate(context.Background(), request)
+			if err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			block, _ := pem.Decode([]byte(resp.Cert))
+			if block == nil {
+				t.Fatalf("in test %v: unable to decode certificate PEM", label)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			if cert.IsCA != tt.key.X509IssueCACerts {
+				t.Fatalf("in test %v: got IsCA %v, want %v", label, cert.IsCA, tt.key.X509IssueCACerts)
+			}
+			if !cert.IsCA {
+				if cert.KeyUsage&x509.KeyUsageCertSign != 0 || len(cert.PermittedDNSDomains) != 0 {
+					t.Errorf("in test %v: leaf certificate may sign certificates", label)
+				}
+				return
+			}
+			if cert.KeyUsage&x509.KeyUsageCertSign == 0 || cert.MaxPathLen != 0 || !cert.MaxPathLenZero {
+				t.Errorf("in test %v: got key usage %v and max path length %d, want an intermediate issuing leaf certificates", label, cert.KeyUsage, cert.MaxPathLen)
+			}
+			var permittedIPs, excludedIPs []string
+			for _, r := range cert.PermittedIPRanges {
+				permittedIPs = append(permittedIPs, r.String())
+			}
+			for _, r := range cert.ExcludedIPRanges {
+				excludedIPs = append(excludedIPs, r.String())
+			}
+			got := [][]string{cert.PermittedDNSDomains, cert.ExcludedDNSDomains, permittedIPs, excludedIPs}
+			want := [][]string{tt.key.X509PermittedDNSDomains, tt.key.X509ExcludedDNSDomains, tt.key.X509PermittedIPRanges, tt.key.X509ExcludedIPRanges}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("in test %v: got name constraints %q, want %q", label, got, want)
+			}
+			if hasConstraints := len(tt.key.X509PermittedDNSDomains) != 0; cert.PermittedDNSDomainsCritical != hasConstraints {
+				t.Errorf("in test %v: got critical name constraints %v, want %v", label, cert.PermittedDNSDomainsCritical, hasConstraints)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificatePathLen(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		key             config.KeyConfig
+		maxPathLen      *wrappers.UInt32Value
+		expectedCode    codes.Code
+		expectedPathLen int
+	}{
+		"default-zero":        {key: config.KeyConfig{X509IssueCACerts: true}, expectedPathLen: 0},
+		"configured":          {key: config.KeyConfig{X509IssueCACerts: true, X509MaxPathLen: 2}, expectedPathLen: 2},
+		"requested-lower":     {key: config.KeyConfig{X509IssueCACerts: true, X509MaxPathLen: 2}, maxPathLen: &wrappers.UInt32Value{Value: 1}, expectedPathLen: 1},
+		"requested-zero":      {key: config.KeyConfig{X509IssueCACerts: true, X509MaxPathLen: 2}, maxPathLen: &wrappers.UInt32Value{}, expectedPathLen: 0},
+		"requested-maximum":   {key: config.KeyConfig{X509IssueCACerts: true, X509MaxPathLen: 2}, maxPathLen: &wrappers.UInt32Value{Value: 2}, expectedPathLen: 2},
+		"requested-too-high":  {key: config.KeyConfig{X509IssueCACerts: true, X509MaxPathLen: 2}, maxPathLen: &wrappers.UInt32Value{Value: 3}, expectedCode: codes.InvalidArgument},
+		"requested-of-leaves": {key: config.KeyConfig{}, maxPathLen: &wrappers.UInt32Value{}, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			tt.key.Identifier = "x509id1"
+			ss := &SigningService{
+				CertSign:       newMockCACertSign(t),
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": tt.key},
+			}
+			request := &proto.X509CertificateSigningRequest{
+				KeyMeta:    &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:        testGoodcsrRsa,
+				Validity:   3600,
+				MaxPathLen: tt.maxPathLen,
+			}
+			resp, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			block, _ := pem.Decode([]byte(resp.Cert))
+			if block == nil {
+				t.Fatalf("in test %v: unable to decode certificate PEM", label)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			if !cert.IsCA || cert.MaxPathLen != tt.expectedPathLen || cert.MaxPathLenZero != (tt.expectedPathLen == 0) {
+				t.Errorf("in test %v: got IsCA %v and max path length %d, want a CA with max path length %d", label, cert.IsCA, cert.MaxPathLen, tt.expectedPathLen)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateProfileValidity(t *testing.T) {
+	t.Parallel()
+	key := config.KeyConfig{
+		Identifier:      "x509id1",
+		DefaultValidity: 86400,
+		X509Profiles: map[string]config.X509Profile{
+			"client":     {MaxValidity: 3600, ExcessValidity: config.X509ProfileValidityClamp},
+			"strict":     {MaxValidity: 3600},
+			"server":     {MaxValidity: 31536000},
+			"no-maximum": {ExcessValidity: config.X509ProfileValidityClamp},
+		},
+	}
+	testcases := map[string]struct {
+		profile          string
+		validity         uint64
+		expectedCode     codes.Code
+		expectedValidity uint64
+	}{
+		"no-profile":             {validity: 7200, expectedCode: codes.OK, expectedValidity: 7200},
+		"no-profile-too-long":    {validity: 31536000, expectedCode: codes.InvalidArgument},
+		"clamp":                  {profile: "client", validity: 86400, expectedCode: codes.OK, expectedValidity: 3600},
+		"clamp-within-maximum":   {profile: "client", validity: 600, expectedCode: codes.OK, expectedValidity: 600},
+		"clamp-default-validity": {profile: "client", expectedCode: codes.OK, expectedValidity: 3600},
+		"reject":                 {profile: "strict", validity: 86400, expectedCode: codes.InvalidArgument},
+		"reject-within-maximum":  {profile: "strict", validity: 3600, expectedCode: codes.OK, expectedValidity: 3600},
+		"longer-than-endpoint":   {profile: "server", validity: 31536000, expectedCode: codes.OK, expectedValidity: 31536000},
+		"clamp-endpoint-maximum": {profile: "no-maximum", validity: 31536000, expectedCode: codes.InvalidArgument},
+		"unknown-profile":        {profile: "foo", validity: 3600, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": key},
+				MaxValidity:    map[string]uint64{config.X509CertEndpoint: 86400},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: tt.validity, Profile: tt.profile}
+			_, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			// The notBefore of the certificates is backdated by one hour.
+			if got := uint64(signer.x509Cert.NotAfter.Sub(signer.x509Cert.NotBefore)/time.Second) - 3600; got != tt.expectedValidity {
+				t.Errorf("in test %v: got validity %d, want %d", label, got, tt.expectedValidity)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateForbidWildcards(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	testcases := map[string]struct {
+		forbid       bool
+		commonName   string
+		dnsNames     []string
+		expectedCode codes.Code
+	}{
+		"specific-host":    {forbid: true, commonName: "foo.example.com", dnsNames: []string{"foo.example.com"}, expectedCode: codes.OK},
+		"wildcard-san":     {forbid: true, commonName: "foo.example.com", dnsNames: []string{"foo.example.com", "*.example.com"}, expectedCode: codes.PermissionDenied},
+		"wildcard-cn":      {forbid: true, commonName: "*.example.com", expectedCode: codes.PermissionDenied},
+		"wildcard-allowed": {commonName: "*.example.com", dnsNames: []string{"*.example.com"}, expectedCode: codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			template := &x509.CertificateRequest{Subject: pkix.Name{CommonName: tt.commonName}, DNSNames: tt.dnsNames}
+			der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+			if err != nil {
+				t.Fatalf("unable to create CSR: %v", err)
+			}
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": {Identifier: "x509id1", X509ForbidWildcards: tt.forbid}},
+			}
+			request := &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+				Validity: 3600,
+			}
+			_, err = ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil && signer.x509Cert != nil {
+				t.Errorf("in test %v: wildcard certificate was signed", label)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateAllowedOUs(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	testcases := map[string]struct {
+		allowedOUs   []string
+		ous          []string
+		expectedCode codes.Code
+	}{
+		"allowed-ou":     {allowedOUs: []string{"Platform", "Payments"}, ous: []string{"Payments"}, expectedCode: codes.OK},
+		"allowed-ous":    {allowedOUs: []string{"Platform", "Payments"}, ous: []string{"Platform", "Payments"}, expectedCode: codes.OK},
+		"no-ou":          {allowedOUs: []string{"Platform"}, expectedCode: codes.OK},
+		"disallowed-ou":  {allowedOUs: []string{"Platform"}, ous: []string{"Payments"}, expectedCode: codes.PermissionDenied},
+		"one-disallowed": {allowedOUs: []string{"Platform"}, ous: []string{"Platform", "Payments"}, expectedCode: codes.PermissionDenied},
+		"case-sensitive": {allowedOUs: []string{"Platform"}, ous: []string{"platform"}, expectedCode: codes.PermissionDenied},
+		"no-restriction": {ous: []string{"Payments"}, expectedCode: codes.OK},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			template := &x509.CertificateRequest{Subject: pkix.Name{CommonName: "foo.example.com", OrganizationalUnit: tt.ous}}
+			der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+			if err != nil {
+				t.Fatalf("unable to create CSR: %v", err)
+			}
+			signer := &mockRecordingCertSign{}
+			ss := &SigningService{
+				CertSign:       signer,
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": {Identifier: "x509id1", X509AllowedOUs: tt.allowedOUs}},
+			}
+			request := &proto.X509CertificateSigningRequest{
+				KeyMeta:  &proto.KeyMeta{Identifier: "x509id1"},
+				Csr:      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+				Validity: 3600,
+			}
+			_, err = ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil && signer.x509Cert != nil {
+				t.Errorf("in test %v: certificate with a disallowed OU was signed", label)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificatePolicies(t *testing.T) {
+	t.Parallel()
+	key := config.KeyConfig{
+		Identifier: "x509id1",
+		X509CertificatePolicies: []config.X509CertificatePolicy{
+			{OID: "1.3.6.1.4.1.99999.1", CPSURIs: []string{"https://pki.example.com/cps", "http://pki.example.com/cps"}},
+			{OID: "2.23.140.1.2.1"},
+		},
+		X509Profiles: map[string]config.X509Profile{
+			"client": {CertificatePolicies: []config.X509CertificatePolicy{{OID: "1.3.6.1.4.1.99999.2", CPSURIs: []string{"https://pki.example.com/client-cps"}}}},
+			"server": {MaxValidity: 86400},
+		},
+	}
+	type policy struct {
+		OID     string
+		CPSURIs []string
+	}
+	testcases := map[string]struct {
+		key      config.KeyConfig
+		profile  string
+		expected []policy
+	}{
+		"no-policies": {
+			key: config.KeyConfig{Identifier: "x509id1"},
+		},
+		"key-policies": {
+			key:      key,
+			expected: []policy{{"1.3.6.1.4.1.99999.1", []string{"https://pki.example.com/cps", "http://pki.example.com/cps"}}, {"2.23.140.1.2.1", nil}},
+		},
+		"profile-policies": {
+			key:      key,
+			profile:  "client",
+			expected: []policy{{"1.3.6.1.4.1.99999.2", []string{"https://pki.example.com/client-cps"}}},
+		},
+		"profile-without-policies": {
+			key:      key,
+			profile:  "server",
+			expected: []policy{{"1.3.6.1.4.1.99999.1", []string{"https://pki.example.com/cps", "http://pki.example.com/cps"}}, {"2.23.140.1.2.1", nil}},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{
+				CertSign:       newMockCACertSign(t),
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": tt.key},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600, Profile: tt.profile}
+			resp, err := ss.PostX509Certificate(context.Background(), request)
+			if err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			block, _ := pem.Decode([]byte(resp.Cert))
+			if block == nil {
+				t.Fatalf("in test %v: unable to decode certificate PEM", label)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			var got []policy
+			for _, ext := range cert.Extensions {
+				if !ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 32}) {
+					continue
+				}
+				if ext.Critical {
+					t.Errorf("in test %v: got a critical CertificatePolicies extension", label)
+				}
+				var infos []struct {
+					Policy     asn1.ObjectIdentifier
+					Qualifiers []struct {
+						ID        asn1.ObjectIdentifier
+						Qualifier string `asn1:"ia5"`
+					} `asn1:"optional"`
+				}
+				if _, err := asn1.Unmarshal(ext.Value, &infos); err != nil {
+					t.Fatalf("in test %v: unable to decode CertificatePolicies extension: %v", label, err)
+				}
+				for _, info := range infos {
+					p := policy{OID: info.Policy.String()}
+					for _, q := range info.Qualifiers {
+						if !q.ID.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 1}) {
+							t.Errorf("in test %v: got policy qualifier %v, want id-qt-cps", label, q.ID)
+						}
+						p.CPSURIs = append(p.CPSURIs, q.Qualifier)
+					}
+					got = append(got, p)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("in test %v: got certificate policies %+v, want %+v", label, got, tt.expected)
+			}
+			var oids []string
+			for _, oid := range cert.PolicyIdentifiers {
+				oids = append(oids, oid.String())
+			}
+			var expectedOIDs []string
+			for _, p := range tt.expected {
+				expectedOIDs = append(expectedOIDs, p.OID)
+			}
+			if !reflect.DeepEqual(oids, expectedOIDs) {
+				t.Errorf("in test %v: got policy identifiers %q, want %q", label, oids, expectedOIDs)
+			}
+		})
+	}
+}
+
+func TestPostX509CertificateExtKeyUsages(t *testing.T) {
+	t.Parallel()
+	codeSigning := config.KeyConfig{
+		Identifier:                "x509id1",
+		X509RequiredExtKeyUsages:  []string{"codeSigning"},
+		X509ForbiddenExtKeyUsages: []string{"serverAuth"},
+	}
+	testcases := map[string]struct {
+		ekus         []int32
+		expectedCode codes.Code
+		expectedEKUs []x509.ExtKeyUsage
+	}{
+		"default":            {expectedEKUs: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}},
+		"code-signing-added": {ekus: []int32{int32(x509.ExtKeyUsageTimeStamping)}, expectedEKUs: []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping, x509.ExtKeyUsageCodeSigning}},
+		"server-auth":        {ekus: []int32{int32(x509.ExtKeyUsageCodeSigning), int32(x509.ExtKeyUsageServerAuth)}, expectedCode: codes.InvalidArgument},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			ss := &SigningService{
+				CertSign:       newMockCACertSign(t),
+				KeyIDProcessor: &crypki.KeyID{},
+				KeyUsages:      combineKeyUsage,
+				Keys:           map[string]config.KeyConfig{"x509id1": codeSigning},
+			}
+			request := &proto.X509CertificateSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "x509id1"}, Csr: testGoodcsrRsa, Validity: 3600, ExtKeyUsage: tt.ekus}
+			resp, err := ss.PostX509Certificate(context.Background(), request)
+			if got := status.Code(err); got != tt.expectedCode {
+				t.Fatalf("in test %v: got code %v, want %v, err: %v", label, got, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			block, _ := pem.Decode([]byte(resp.Cert))
+			if block == nil {
+				t.Fatalf("in test %v: unable to decode certificate PEM", label)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+			}
+			if !reflect.DeepEqual(cert.ExtKeyUsage, tt.expectedEKUs) {
+				t.Errorf("in test %v: got extended key usages %v, want %v", label, cert.ExtKeyUsage, tt.expectedEKUs)
+			}
+		})
+	}
+}
@@ -74,7 +74,7 @@ func main() {
 		cc.Identifier: true,
 	}
 
-	signer, err := pkcs11.NewCertSign(cc.PKCS11ModulePath, []config.KeyConfig{{
+	signer, err := pkcs11.NewCertSign(map[string]string{"": cc.PKCS11ModulePath}, []config.KeyConfig{{
 		Identifier:             cc.Identifier,
 		SlotNumber:             uint(cc.SlotNumber),
 		UserPinPath:            cc.UserPinPath,
@@ -88,7 +88,7 @@ func main() {
 		Organization:           cc.Organization,
 		OrganizationalUnit:     cc.OrganizationalUnit,
 		CommonName:             cc.CommonName,
-	}}, requireX509CACert, hostname, ips)
+	}}, requireX509CACert, hostname, ips, nil, nil)
 	if err != nil {
 		log.Fatalf("unable to initialize cert signer: %v", err)
 	}
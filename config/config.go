@@ -4,13 +4,26 @@
 package config
 
 import (
+	"crypto"
+	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/yahoo/crypki"
+	"golang.org/x/crypto/ssh"
 )
 
 const (
@@ -21,6 +34,21 @@ const (
 	defaultTLSPort           = "4443"
 	defaultPoolSize          = 2
 	defaultKeyType           = crypki.RSA
+	defaultBreakerTimeoutMs  = 30000
+	defaultKeyRetryDelayMs   = 1000
+	defaultHookTimeoutMs     = 5000
+	defaultRedisTimeoutMs    = 100
+	defaultIdleTimeoutMs     = 30000
+	defaultEphemeralKeySize  = 2048
+	defaultMaxSSHOptions     = 64
+	defaultMaxSSHOptionsSize = 16384
+	defaultMaxBlobBatchSize  = 100
+	defaultSSHSerialInterval = 1000
+	defaultDriftIntervalMs   = 60000
+	defaultSelfTestParallel  = 8
+	defaultTLSKeySessions    = 2
+	defaultProxyHandshakes   = 128
+	defaultMaxRecvMsgSize    = 4 << 20
 
 	// X509CertEndpoint specifies the endpoint for signing X509 certificate.
 	X509CertEndpoint = "/sig/x509-cert"
@@ -30,8 +58,207 @@ const (
 	SSHHostCertEndpoint = "/sig/ssh-host-cert"
 	// BlobEndpoint specifies the endpoint for raw signing.
 	BlobEndpoint = "/sig/blob"
+	// TimestampEndpoint specifies the endpoint for signing RFC 3161 time-stamp tokens.
+	TimestampEndpoint = "/sig/timestamp"
+	// GitEndpoint specifies the endpoint for signing git objects with SSH keys.
+	GitEndpoint = "/sig/git"
+	// DNSSECEndpoint specifies the endpoint for signing DNSSEC RRSIG records.
+	DNSSECEndpoint = "/sig/dnssec"
+	// ContainerImageEndpoint specifies the endpoint for signing container images in the cosign format.
+	ContainerImageEndpoint = "/sig/container-image"
+	// AttestationEndpoint specifies the endpoint for signing WebAuthn packed attestation statements.
+	AttestationEndpoint = "/sig/attestation"
+	// EphemeralEndpoint specifies the endpoint for signing with one-time keys generated in HSM.
+	EphemeralEndpoint = "/sig/ephemeral"
+	// IssuanceManifestEndpoint specifies the endpoint for signing the manifests of the issued certificates
+	// with the receipt key.
+	IssuanceManifestEndpoint = "/issuance/manifest"
+
+	// X509SANTypeDNS specifies the DNS name subject alternative names.
+	X509SANTypeDNS = "DNS"
+	// X509SANTypeIP specifies the IP address subject alternative names.
+	X509SANTypeIP = "IP"
+	// X509SANTypeEmail specifies the email address subject alternative names.
+	X509SANTypeEmail = "Email"
+	// X509SANTypeURI specifies the URI subject alternative names.
+	X509SANTypeURI = "URI"
+
+	// PSSSaltLengthEqualsHash specifies RSA-PSS salts as long as the hash.
+	PSSSaltLengthEqualsHash = "EqualsHash"
+	// PSSSaltLengthMax specifies RSA-PSS salts of the maximum length allowed by the key size and the hash.
+	PSSSaltLengthMax = "Max"
+
+	// RateLimitBackendMemory specifies rate limits held in memory, i.e. enforced per replica.
+	RateLimitBackendMemory = "memory"
+	// RateLimitBackendRedis specifies rate limits held in a Redis server, i.e. enforced across the replicas sharing it.
+	RateLimitBackendRedis = "redis"
+
+	// PartialAvailabilityStrict specifies that a signature fails if any of the keys or threshold shares it
+	// requires is unavailable.
+	PartialAvailabilityStrict = "strict"
+	// PartialAvailabilityBestEffort specifies that a signature proceeds with the keys or threshold shares
+	// that are available.
+	PartialAvailabilityBestEffort = "best-effort"
+
+	// X509CAExpiryClamp specifies that the notAfter of an x509 certificate beyond the notAfter of the CA
+	// certificate of its key is set to the latter.
+	X509CAExpiryClamp = "clamp"
+	// X509CAExpiryStrict specifies that the requests for an x509 certificate valid beyond the CA certificate
+	// of its key are rejected.
+	X509CAExpiryStrict = "strict"
+
+	// X509ProfileValidityClamp specifies that an x509 certificate request for a longer validity than the
+	// MaxValidity of its profile is issued with the latter.
+	X509ProfileValidityClamp = "clamp"
+	// X509ProfileValidityReject specifies that the x509 certificate requests for a longer validity than the
+	// MaxValidity of their profile are rejected.
+	X509ProfileValidityReject = "reject"
+
+	// SSHExtensionsStrip specifies that the non-standard extensions of an SSH certificate request are stripped
+	// from the certificate.
+	SSHExtensionsStrip = "strip"
+	// SSHExtensionsReject specifies that the SSH certificate requests with non-standard extensions are rejected.
+	SSHExtensionsReject = "reject"
+
+	// DefaultCaller is the key of the entry of CallerPermittedDomains applied to the callers without an entry.
+	DefaultCaller = "*"
+)
+
+// Names of the configurable gRPC interceptors of the unary requests.
+const (
+	LogSamplingInterceptor = "log-sampling"
+	IdentityInterceptor    = "identity"
+	RequestSizeInterceptor = "request-size"
+	ReadinessInterceptor   = "readiness"
+	APIVersionInterceptor  = "api-version"
+	KeyAliasInterceptor    = "key-alias"
+	FingerprintInterceptor = "fingerprint"
+	TimeoutInterceptor     = "timeout"
+	RateLimitInterceptor   = "rate-limit"
+	ConcurrencyInterceptor = "concurrency"
+	PreSignHookInterceptor = "pre-sign-hook"
+	SignPacingInterceptor  = "sign-pacing"
 )
 
+// Interceptors are the names of the configurable gRPC interceptors of the unary requests, in their default order,
+// the first one being the outermost.
+var Interceptors = []string{
+	LogSamplingInterceptor,
+	IdentityInterceptor,
+	RequestSizeInterceptor,
+	ReadinessInterceptor,
+	APIVersionInterceptor,
+	KeyAliasInterceptor,
+	FingerprintInterceptor,
+	TimeoutInterceptor,
+	RateLimitInterceptor,
+	ConcurrencyInterceptor,
+	PreSignHookInterceptor,
+	SignPacingInterceptor,
+}
+
+// defaultMaxRequestSizes are the default KeyUsage.MaxRequestSize of the endpoints.
+var defaultMaxRequestSizes = map[string]int{
+	BlobEndpoint:     16384,
+	X509CertEndpoint: 65536,
+}
+
+// CallerValidity is the validity of the certificates of a caller.
+type CallerValidity struct {
+	// DefaultValidity is the validity period in seconds of the certificates of the caller whose requests do
+	// not specify one, overriding the DefaultValidity of the key. If not specified, the one of the key is used.
+	DefaultValidity uint64
+	// MaxValidity is the maximum validity period in seconds of the certificates of the caller. It further
+	// limits the MaxValidity of the endpoint. If not specified, only the one of the endpoint applies.
+	MaxValidity uint64
+}
+
+// X509Profile is a profile of the x509 certificates signed by a key, such as short-lived client certificates
+// or long-lived server certificates, selected by the requests.
+type X509Profile struct {
+	// MaxValidity is the maximum validity period in seconds of the certificates of the profile. It overrides
+	// the MaxValidity of the endpoint. If not specified, the one of the endpoint applies.
+	MaxValidity uint64
+	// ExcessValidity is the behavior of the requests of the profile for a longer validity than its MaxValidity:
+	// X509ProfileValidityClamp or X509ProfileValidityReject. Default is reject.
+	ExcessValidity string
+	// CertificatePolicies are the policies of the certificates of the profile. If specified, they replace
+	// the X509CertificatePolicies of the key.
+	CertificatePolicies []X509CertificatePolicy
+}
+
+// X509CertificatePolicy is a policy included in the CertificatePolicies extension of x509 certificates.
+type X509CertificatePolicy struct {
+	// OID is the dotted OID of the policy, such as "2.23.140.1.2.1".
+	OID string
+	// CPSURIs are the URIs of the certification practice statements of the policy, included as its
+	// qualifiers. If empty, the policy is not qualified.
+	CPSURIs []string
+}
+
+// PolicyOID returns the parsed OID of the policy.
+func (p X509CertificatePolicy) PolicyOID() (asn1.ObjectIdentifier, error) {
+	oid, ok := parseOID(p.OID)
+	if !ok {
+		return nil, fmt.Errorf("invalid certificate policy OID %q", p.OID)
+	}
+	return oid, nil
+}
+
+// SigningWindow is the window of the week during which a key signs, such as the business hours of its
+// administrators.
+type SigningWindow struct {
+	// Days are the days of the week the window is open, such as "Monday". If empty, it is open every day.
+	Days []string
+	// StartHour and EndHour are the hours of the day, from 0 to 24, from which and until which the window is
+	// open. EndHour must be greater than StartHour: the windows spanning midnight are not supported.
+	StartHour, EndHour int
+	// TimeZone is the IANA name of the time zone of the window, such as "America/Los_Angeles". Default is UTC.
+	TimeZone string
+}
+
+// Location returns the location of the time zone of the window.
+func (w *SigningWindow) Location() (*time.Location, error) {
+	loc, err := time.LoadLocation(w.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing window time zone %q: %v", w.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// validate returns an error if the hours, the time zone or the days of the window are invalid.
+func (w *SigningWindow) validate() error {
+	if w.StartHour < 0 || w.EndHour > 24 || w.StartHour >= w.EndHour {
+		return fmt.Errorf("invalid SigningWindow hours from %d to %d", w.StartHour, w.EndHour)
+	}
+	if _, err := w.Location(); err != nil {
+		return err
+	}
+	_, err := w.Weekdays()
+	return err
+}
+
+// Weekdays returns the set of the days of the week the window is open, or nil if it is open every day.
+func (w *SigningWindow) Weekdays() (map[time.Weekday]bool, error) {
+	if len(w.Days) == 0 {
+		return nil, nil
+	}
+	days := make(map[time.Weekday]bool, len(w.Days))
+	for _, name := range w.Days {
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(name, d.String()) {
+				days[d] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid signing window day %q", name)
+		}
+	}
+	return days, nil
+}
+
 // KeyUsage configures which key(s) can be used for the API call.
 type KeyUsage struct {
 	// Endpoint represents the API call that is made.
@@ -43,22 +270,145 @@ type KeyUsage struct {
 	// Maximum allowed validity period in seconds for a certificate signed by
 	// this endpoint. If not specified default is infinity.
 	MaxValidity uint64
+	// Minimum validity period in seconds of a certificate signed by this endpoint from the time of the request,
+	// checked once its validity period is final, e.g. after it was clamped to the expiry of the CA certificate.
+	// Certificates valid for no time at all are always rejected.
+	MinValidity uint64
+	// Disabled specifies whether the signing requests of this endpoint are rejected at startup.
+	// The endpoint can be enabled at runtime via the Admin service.
+	Disabled bool
+	// RequestTimeoutMs is the timeout in milliseconds of requests to this endpoint that
+	// carry no client deadline. If not specified, Config.RequestTimeoutMs is used.
+	RequestTimeoutMs uint64
+	// SlowRequestThresholdMs is the time in milliseconds above which requests to this endpoint are logged
+	// as slow. If not specified, Config.SlowRequestThresholdMs is used.
+	SlowRequestThresholdMs uint64
+	// RejectDuplicateNames specifies whether requests with duplicate principals or SANs are
+	// rejected. By default duplicates are silently removed.
+	RejectDuplicateNames bool
+	// AllowEmptyPrincipals specifies whether SSH user certificates without principals, which are
+	// valid for any user, can be signed by this endpoint. By default such requests are rejected.
+	AllowEmptyPrincipals bool
+	// DeriveKeyIDFromCaller specifies whether the key ID of the SSH certificates signed by this endpoint is
+	// derived from the identity of the authenticated caller and the time of the request, ignoring the key ID
+	// of the request, so that every certificate can be traced to its requester.
+	DeriveKeyIDFromCaller bool
+	// MaxRequestSize is the maximum size in bytes of the requests to this endpoint, which fail with InvalidArgument
+	// beyond it. Default is 16384 for the blob endpoint and 65536 for the x509 certificate endpoint, the requests
+	// of the other endpoints being only limited by the gRPC transport.
+	MaxRequestSize int
+}
+
+// ThresholdShareConfig contains information about a share of a threshold key inside HSM.
+type ThresholdShareConfig struct {
+	// Index is the index of the share, from 1 to the number of shares.
+	Index int
+	// Module is the name of the PKCS#11 module in Config.Modules whose HSM holds this share.
+	// If empty, the share is held by the module at Config.ModulePath.
+	Module string
+	// SlotNumber is the slot number in HSM.
+	SlotNumber uint
+	// UserPinPath is the path to the file that contains the pin to login to the specified slot.
+	UserPinPath string
+	// KeyLabel is the label of the share on the slot.
+	KeyLabel string
 }
 
 // KeyConfig contains information about a particular signing key inside HSM.
 type KeyConfig struct {
 	// Identifier is a unique name that can be used to refer to this key.
 	Identifier string
+	// Module is the name of the PKCS#11 module in Config.Modules whose HSM holds this key.
+	// If empty, the key is held by the module at Config.ModulePath.
+	Module string
 	// SlotNumber is the slot number in HSM.
 	SlotNumber uint
 	// UserPinPath is the path to the file that contains the pin to login to the specified slot.
 	UserPinPath string
 	// KeyLabel is the label of the key on the slot.
 	KeyLabel string
+	// KeyObjectID is the hex encoded CKA_ID of the key objects on the slot, which selects them among the
+	// objects sharing KeyLabel. The key fails to load if it matches no or several objects. If not
+	// specified, the key objects are selected by label only.
+	KeyObjectID string
 	// SessionPoolSize specifies the number of sessions that are opened for this key.
 	SessionPoolSize int
-	// KeyType specifies the type of key, such as RSA or ECDSA.
+	// KeyType specifies the type of key, such as RSA or ECDSA. The post-quantum keys, ML-DSA and SLH-DSA, are
+	// signed with the mechanisms of PKCS#11 v3.2 and may only be used to sign blobs.
 	KeyType crypki.PublicKeyAlgorithm
+	// Tenant is the tenant the key is configured for, by which the listings of the available keys
+	// may be filtered. It is informational only and does not restrict who may use the key.
+	Tenant string
+	// Mechanism is the name of the PKCS#11 mechanism, such as "CKM_RSA_X_509", used to sign with this
+	// key instead of the one selected by crypki, for HSMs that do not support the latter. It must be
+	// compatible with KeyType. The ECDSA keys may also use a vendor defined mechanism signing digests
+	// like CKM_ECDSA, given by its hex value such as "0x80000101".
+	Mechanism string
+	// DeterministicSignatures specifies whether the signatures of this ECDSA key are deterministic as per
+	// RFC 6979, so that a digest always has the same signature. PKCS#11 defines no deterministic ECDSA
+	// mechanism, so Mechanism must be the vendor defined one of the HSM. The key fails to load unless
+	// two signatures of the same digest are identical.
+	DeterministicSignatures bool
+	// ThresholdShares are the shares of a threshold RSA key, each held as an RSA key by an HSM, that
+	// are used to sign with this key instead of SlotNumber, UserPinPath and KeyLabel. The signature is
+	// combined from the partial signatures of Threshold of the shares, so that no single HSM holds
+	// the private key. See pkcs11/threshold.go for how the shares are dealt.
+	ThresholdShares []ThresholdShareConfig
+	// Threshold is the number of ThresholdShares required to sign.
+	Threshold int
+	// PartialAvailability is the behavior of the signing requests of this key when some of the keys or
+	// shares they require are unavailable: "strict" or "best-effort". A blob signing request with
+	// co-signers fails if any of the keys fails to sign in strict mode, and returns the signatures of the
+	// keys that signed in best-effort mode; requests may override it. A threshold key requires all of its
+	// ThresholdShares to sign in strict mode, and Threshold of them in best-effort mode. Default is strict
+	// for co-signers and best-effort for threshold shares.
+	PartialAvailability string
+
+	// DefaultValidity is the validity period in seconds of the certificates signed by this key whose
+	// requests do not specify one. It is clamped by the MaxValidity of the endpoint. If not specified,
+	// requests must specify the validity.
+	DefaultValidity uint64
+	// ValidityGranularity is the granularity in seconds of the validity periods of the x509 and SSH
+	// certificates signed by this key, such as 60 for minute boundaries: the notBefore is rounded down
+	// and the notAfter rounded up to multiples of it since the Unix epoch, so the validity period may
+	// exceed the requested one by less than twice the granularity. If not specified, the times are not rounded.
+	ValidityGranularity uint64
+
+	// AllowPSS specifies whether blob signing requests may use RSA-PSS with this key.
+	AllowPSS bool
+
+	// PSSSaltLength is the salt length of the RSA-PSS signatures of this key: "EqualsHash" for the length of
+	// the hash, "Max" for the maximum length allowed by the key size and the hash, or an explicit number
+	// of bytes. Default is "EqualsHash".
+	PSSSaltLength string
+
+	// MinHashAlgorithm is the weakest hash algorithm, such as "SHA256", of the signatures of this key: "SHA1",
+	// "SHA224", "SHA256", "SHA384" or "SHA512". Signing requests whose effective hash algorithm, once resolved
+	// from the request and the defaults, is weaker are rejected. If not specified, there is no minimum.
+	MinHashAlgorithm string
+
+	// MonotonicNotBefore specifies whether the x509 and SSH certificates signed by this key are rejected
+	// if their notBefore is earlier than that of a certificate it previously signed, so that no
+	// certificate is backdated. It requires NotBeforeWatermarkDir.
+	MonotonicNotBefore bool
+	// SSHSerialCounter specifies whether the serial numbers of the SSH certificates signed by this key are
+	// handed out by a monotonic counter, instead of being left to zero. It requires SSHSerialCounterDir.
+	SSHSerialCounter bool
+	// RequireIssuanceStore specifies whether the x509 and SSH certificates signed by this key are only returned
+	// once they are recorded in the issuance store, so that no certificate escapes tracking and revocation:
+	// the requests fail with Unavailable if the store is unreachable. It requires IssuanceStoreDir.
+	RequireIssuanceStore bool
+	// ReplayWindowMs is the window in milliseconds within which this key does not sign the same blob digest
+	// twice, for audit-critical keys: a repeated digest fails with AlreadyExists. The signed digests are held
+	// in memory, i.e. replays are detected per replica. If not specified, digests may be signed repeatedly.
+	ReplayWindowMs uint64
+
+	// RateLimit is the maximum number of signing requests per second of this key, enforced by the
+	// Config.RateLimitBackend. Requests beyond it fail with ResourceExhausted. If not specified, there is no limit.
+	RateLimit float64
+	// RateLimitBurst is the number of requests that may be signed at once by this key above its RateLimit.
+	// Default is the RateLimit rounded up.
+	RateLimitBurst int
 
 	// Below are configs of the x509 CA cert for this key. Useful when this key will be used
 	// for signing x509 certificates.
@@ -68,22 +418,673 @@ type KeyConfig struct {
 	CreateCACertIfNotExist bool
 	// X509CACertLocation is the path to the x509 CA certificate.
 	X509CACertLocation string
+	// BlobSigningCertLocation is the path of the PEM encoded certificate of the public key of this key,
+	// followed by its chain, returned by GetBlobSigningKey to the clients verifying its blob signatures.
+	BlobSigningCertLocation string
+	// SkipX509CACertKeyCheck disables the check, when the key is loaded, that the public key of the
+	// x509 CA certificate at X509CACertLocation is the public key of this key.
+	SkipX509CACertKeyCheck bool
+	// X509CrossSignedCACertLocations are the paths to the PEM files of the cross-signed certificates of the
+	// x509 CA of this key, each followed by its chain. Their subject and public key must be those of the x509
+	// CA certificate, so that the certificates signed by this key chain to any of them. Clients select the
+	// chain returned with the certificates by the issuer of the CA certificate.
+	X509CrossSignedCACertLocations []string
 	// Fields of the CA cert in subject line.
 	Country, State, Locality, Organization, OrganizationalUnit, CommonName string
+	// X509SubjectKeyTypes is the list of public key algorithms allowed for the subject key of
+	// x509 certificates signed by this key. If empty, RSA and ECDSA keys are allowed.
+	X509SubjectKeyTypes []crypki.PublicKeyAlgorithm
+	// MinX509SubjectRSAKeySize is the minimum size in bits of an RSA subject key. Default is 2048.
+	MinX509SubjectRSAKeySize int
+	// MinX509SubjectECDSAKeySize is the minimum curve size in bits of an ECDSA subject key. Default is 256.
+	MinX509SubjectECDSAKeySize int
+	// SSHSubjectKeyTypes is the list of SSH public key types, such as "ssh-ed25519", allowed for the subject
+	// key of SSH certificates signed by this key. If empty, all types but "ssh-dss" are allowed.
+	SSHSubjectKeyTypes []string
+	// MinSSHSubjectRSAKeySize is the minimum size in bits of an RSA subject key of SSH certificates. Default is 2048.
+	MinSSHSubjectRSAKeySize int
+	// MaxSSHSubjectRSAKeySize is the maximum size in bits of an RSA subject key of SSH certificates.
+	// If not specified, there is no maximum.
+	MaxSSHSubjectRSAKeySize int
+	// SSHStrictExtensions limits the extensions of the SSH certificates signed by this key to the standard
+	// ones of OpenSSH, for the older SSH servers rejecting the certificates with unknown extensions:
+	// SSHExtensionsStrip or SSHExtensionsReject. If not specified, all requested extensions are issued.
+	SSHStrictExtensions string
+	// SSHAllowNeverExpiring specifies whether this key signs the SSH certificates that never expire, i.e. whose
+	// ValidBefore is the SSH infinity, when requested with the maximum validity. They are only signed by the
+	// endpoints and for the callers without MaxValidity.
+	SSHAllowNeverExpiring bool
+	// SigningWindow restricts the signing requests of this key to a window of the week, e.g. for the
+	// administrative keys only used during business hours. The requests outside the window fail with
+	// FailedPrecondition. If nil, this key signs at any time.
+	SigningWindow *SigningWindow
+	// X509SANTypes is the list of subject alternative name types, such as "DNS" or "IP", allowed in
+	// the CSRs of x509 certificates signed by this key. If empty, all types are allowed.
+	X509SANTypes []string
+	// X509DNSSuffixes is the list of suffixes, such as ".example.com", allowed for the DNS names and
+	// the subject common name in the CSRs of x509 certificates signed by this key. If empty, all names are allowed.
+	X509DNSSuffixes []string
+	// X509ValidateDNSNames specifies whether the DNS names in the CSRs of x509 certificates signed by
+	// this key must be fully qualified domain names without a trailing dot.
+	X509ValidateDNSNames bool
+	// X509AllowWildcardDNSNames specifies whether a validated DNS name may have a wildcard as its leftmost label.
+	X509AllowWildcardDNSNames bool
+	// X509ForbidWildcards specifies whether the requests for an x509 certificate with a wildcard DNS name or
+	// subject common name are denied by this key, e.g. for an internal-only CA. It cannot be combined with
+	// X509AllowWildcardDNSNames.
+	X509ForbidWildcards bool
+	// X509AllowedOUs is the list of subject organizational units allowed in the x509 certificates signed by
+	// this key. The requests for a certificate with an OU outside the list are denied. If empty, all OUs
+	// are allowed.
+	X509AllowedOUs []string
+	// X509AllowDNSUnderscores specifies whether the labels of a validated DNS name may contain underscores.
+	X509AllowDNSUnderscores bool
+	// X509RequireCommonNameSAN specifies whether the CSRs of x509 certificates signed by this key are rejected
+	// if their subject common name is not among their DNS names, as per the CA/Browser Forum Baseline Requirements.
+	X509RequireCommonNameSAN bool
+	// X509AddCommonNameSAN specifies whether the subject common name of the x509 certificates signed by this key
+	// is added to their DNS names if it is missing. It takes precedence over X509RequireCommonNameSAN.
+	X509AddCommonNameSAN bool
+	// X509CRLDistributionPoints is the list of CRL distribution point URLs included in the
+	// x509 certificates signed by this key. If empty, the extension is omitted.
+	X509CRLDistributionPoints []string
+	// X509OCSPServers is the list of OCSP responder URLs included in the authority information
+	// access extension of the x509 certificates signed by this key.
+	X509OCSPServers []string
+	// X509IssuingCertificateURLs is the list of CA issuers URLs included in the authority information
+	// access extension of the x509 certificates signed by this key.
+	X509IssuingCertificateURLs []string
+	// X509CTLogs is the list of certificate transparency logs that the precertificates of the x509
+	// certificates signed by this key are submitted to. The SCTs returned by the logs are embedded
+	// in the certificates. If empty, certificates are signed without SCTs.
+	X509CTLogs []CTLogConfig
+	// X509IssueWithoutSCTs specifies whether a certificate is issued without the SCT of a CT log
+	// that fails to return one. By default the signing request fails.
+	X509IssueWithoutSCTs bool
+	// X509CAExpiry is the behavior of the requests for an x509 certificate whose notAfter is after the notAfter
+	// of the CA certificate of this key, which would outlive its issuer: X509CAExpiryClamp or X509CAExpiryStrict.
+	// If not specified, the certificate is issued with the requested validity.
+	X509CAExpiry string
+	// X509Profiles are the profiles of the x509 certificates signed by this key by name, which the requests
+	// may select. The requests selecting an unknown profile are rejected.
+	X509Profiles map[string]X509Profile
+	// X509IssueCACerts specifies whether the x509 certificates signed by this key are intermediate CA
+	// certificates, with the CertSign and CRLSign key usages and a path length constraint of X509MaxPathLen,
+	// rather than leaf certificates.
+	X509IssueCACerts bool
+	// X509MaxPathLen is the maximum path length constraint of the intermediate CA certificates signed by this
+	// key, which the requests may lower. Default is zero: the intermediate CAs only issue leaf certificates.
+	// It requires X509IssueCACerts.
+	X509MaxPathLen int
+	// X509PermittedDNSDomains and X509ExcludedDNSDomains are the DNS name constraints of the intermediate CA
+	// certificates signed by this key, and X509PermittedIPRanges and X509ExcludedIPRanges their IP address
+	// constraints in CIDR notation. They are included in a critical NameConstraints extension, and require
+	// X509IssueCACerts.
+	X509PermittedDNSDomains []string
+	X509ExcludedDNSDomains  []string
+	X509PermittedIPRanges   []string
+	X509ExcludedIPRanges    []string
+	// X509RequiredExtKeyUsages is the list of extended key usages, such as "codeSigning", of every x509
+	// certificate signed by this key. They are added to the certificates whose CSRs do not request them, and
+	// replace the default "serverAuth" and "clientAuth" of the CSRs requesting none.
+	X509RequiredExtKeyUsages []string
+	// X509ForbiddenExtKeyUsages is the list of extended key usages, such as "serverAuth", of the x509
+	// certificates rejected by this key.
+	X509ForbiddenExtKeyUsages []string
+	// X509CertificatePolicies are the policies included in the CertificatePolicies extension of the x509
+	// certificates signed by this key, for compliance with the certificate policy of the PKI. If empty,
+	// the extension is omitted.
+	X509CertificatePolicies []X509CertificatePolicy
+	// X509AllowedExtensions is the list of dotted OIDs of the custom extensions of the CSRs copied to the
+	// certificates signed by this key. The CSRs with any other extension than the subject alternative
+	// names, key usages, basic constraints and subject key identifier are rejected. The extensions set
+	// by crypki itself, such as the ones under 2.5.29, cannot be allowed.
+	X509AllowedExtensions []string
+	// X509MaxExtensionSize is the maximum size in bytes of the value of a custom extension. Default is 1024.
+	X509MaxExtensionSize int
+	// X509MaxExtensionsSize is the maximum total size in bytes of the values of the custom extensions
+	// of a certificate. Default is 4096.
+	X509MaxExtensionsSize int
+	// TSAPolicyOID is the dotted OID of the TSA policy under which the RFC 3161 time-stamp tokens signed
+	// by this key are issued, such as "1.3.6.1.4.1.4146.2.3". It is required for the keys of the
+	// "/sig/timestamp" endpoint, whose X509 CA certificate is used as the TSA certificate.
+	TSAPolicyOID string
+	// TSAAccuracyMs is the accuracy in milliseconds of the time of the time-stamp tokens signed by this key.
+	// If not specified, the accuracy is omitted from the tokens.
+	TSAAccuracyMs uint64
+}
+
+// ObjectID returns the decoded KeyObjectID of the key, or nil if it is not specified.
+func (k KeyConfig) ObjectID() ([]byte, error) {
+	if k.KeyObjectID == "" {
+		return nil, nil
+	}
+	id, err := hex.DecodeString(k.KeyObjectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KeyObjectID %q: %v", k.KeyObjectID, err)
+	}
+	return id, nil
+}
+
+// TSAPolicy returns the parsed TSAPolicyOID of the key.
+func (k KeyConfig) TSAPolicy() (asn1.ObjectIdentifier, error) {
+	oid, ok := parseOID(k.TSAPolicyOID)
+	if !ok {
+		return nil, fmt.Errorf("invalid TSA policy OID %q", k.TSAPolicyOID)
+	}
+	return oid, nil
+}
+
+// reservedExtensionArcs are the arcs of the OIDs of the x509 extensions set by crypki itself, i.e. the
+// standard certificate extensions, the PKIX private extensions and the certificate transparency ones.
+var reservedExtensionArcs = []asn1.ObjectIdentifier{
+	{2, 5, 29},
+	{1, 3, 6, 1, 5, 5, 7, 1},
+	{1, 3, 6, 1, 4, 1, 11129, 2, 4},
+}
+
+// X509AllowedExtensionOIDs returns the parsed X509AllowedExtensions of the key.
+func (k KeyConfig) X509AllowedExtensionOIDs() ([]asn1.ObjectIdentifier, error) {
+	var oids []asn1.ObjectIdentifier
+	for _, s := range k.X509AllowedExtensions {
+		oid, ok := parseOID(s)
+		if !ok {
+			return nil, fmt.Errorf("invalid x509 extension OID %q", s)
+		}
+		for _, arc := range reservedExtensionArcs {
+			if len(oid) > len(arc) && oid[:len(arc)].Equal(arc) {
+				return nil, fmt.Errorf("x509 extension %q is set by crypki and cannot be allowed", s)
+			}
+		}
+		oids = append(oids, oid)
+	}
+	return oids, nil
+}
+
+// extKeyUsages maps the names of the x509 extended key usages, as in RFC 5280, to their values.
+var extKeyUsages = map[string]x509.ExtKeyUsage{
+	"any":             x509.ExtKeyUsageAny,
+	"serverAuth":      x509.ExtKeyUsageServerAuth,
+	"clientAuth":      x509.ExtKeyUsageClientAuth,
+	"codeSigning":     x509.ExtKeyUsageCodeSigning,
+	"emailProtection": x509.ExtKeyUsageEmailProtection,
+	"ipsecEndSystem":  x509.ExtKeyUsageIPSECEndSystem,
+	"ipsecTunnel":     x509.ExtKeyUsageIPSECTunnel,
+	"ipsecUser":       x509.ExtKeyUsageIPSECUser,
+	"timeStamping":    x509.ExtKeyUsageTimeStamping,
+	"OCSPSigning":     x509.ExtKeyUsageOCSPSigning,
+}
+
+// X509ExtKeyUsagePolicy returns the parsed X509RequiredExtKeyUsages and X509ForbiddenExtKeyUsages of the key.
+func (k KeyConfig) X509ExtKeyUsagePolicy() (required, forbidden []x509.ExtKeyUsage, err error) {
+	parse := func(names []string) ([]x509.ExtKeyUsage, error) {
+		var ekus []x509.ExtKeyUsage
+		for _, name := range names {
+			eku, ok := extKeyUsages[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown x509 extended key usage %q", name)
+			}
+			ekus = append(ekus, eku)
+		}
+		return ekus, nil
+	}
+	if required, err = parse(k.X509RequiredExtKeyUsages); err != nil {
+		return nil, nil, err
+	}
+	if forbidden, err = parse(k.X509ForbiddenExtKeyUsages); err != nil {
+		return nil, nil, err
+	}
+	for _, name := range k.X509RequiredExtKeyUsages {
+		for _, f := range k.X509ForbiddenExtKeyUsages {
+			if name == f {
+				return nil, nil, fmt.Errorf("x509 extended key usage %q cannot be both required and forbidden", name)
+			}
+		}
+	}
+	return required, forbidden, nil
+}
+
+// X509NameConstraintIPRanges returns the parsed X509PermittedIPRanges and X509ExcludedIPRanges of the key.
+func (k KeyConfig) X509NameConstraintIPRanges() (permitted, excluded []*net.IPNet, err error) {
+	if permitted, err = parseCIDRs(k.X509PermittedIPRanges); err != nil {
+		return nil, nil, err
+	}
+	if excluded, err = parseCIDRs(k.X509ExcludedIPRanges); err != nil {
+		return nil, nil, err
+	}
+	return permitted, excluded, nil
+}
+
+// validateCertificatePolicies returns an error if a policy has an invalid OID or CPS URI, or is listed twice.
+func validateCertificatePolicies(policies []X509CertificatePolicy) error {
+	seen := make(map[string]bool)
+	for _, p := range policies {
+		oid, err := p.PolicyOID()
+		if err != nil {
+			return err
+		}
+		if seen[oid.String()] {
+			return fmt.Errorf("duplicate certificate policy %q", p.OID)
+		}
+		seen[oid.String()] = true
+		for _, uri := range p.CPSURIs {
+			u, err := url.Parse(uri)
+			// The URIs are encoded as IA5Strings.
+			nonASCII := strings.IndexFunc(uri, func(r rune) bool { return r >= 0x80 }) != -1
+			if err != nil || !u.IsAbs() || u.Host == "" || nonASCII {
+				return fmt.Errorf("invalid CPS URI %q of certificate policy %q", uri, p.OID)
+			}
+		}
+	}
+	return nil
+}
+
+// parseCIDRs parses IP ranges in CIDR notation such as "10.0.0.0/8".
+func parseCIDRs(ranges []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, r := range ranges {
+		_, ipNet, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q", r)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// parseOID parses a dotted OID such as "1.3.6.1.4.1.4146.2.3".
+func parseOID(s string) (asn1.ObjectIdentifier, bool) {
+	var oid asn1.ObjectIdentifier
+	for _, arc := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(arc)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		oid = append(oid, n)
+	}
+	return oid, len(oid) >= 2
+}
+
+// PSSSaltLengthValue returns the parsed PSSSaltLength of the key, i.e. rsa.PSSSaltLengthEqualsHash,
+// rsa.PSSSaltLengthAuto for the maximum length, or the explicit length in bytes.
+func (k KeyConfig) PSSSaltLengthValue() (int, error) {
+	switch k.PSSSaltLength {
+	case "", PSSSaltLengthEqualsHash:
+		return rsa.PSSSaltLengthEqualsHash, nil
+	case PSSSaltLengthMax:
+		return rsa.PSSSaltLengthAuto, nil
+	}
+	n, err := strconv.Atoi(k.PSSSaltLength)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid PSS salt length %q", k.PSSSaltLength)
+	}
+	return n, nil
+}
+
+// signatureSchemes is the set of the names of the signature schemes of CallerSignatureSchemes.
+var signatureSchemes = map[string]bool{
+	"PKCS1v15":    true,
+	"PSS":         true,
+	"ECDSA_ASN1":  true,
+	"ECDSA_P1363": true,
+	"Ed25519ph":   true,
+}
+
+// hashAlgorithms maps the names of the hash algorithms of MinHashAlgorithm to the hash functions.
+var hashAlgorithms = map[string]crypto.Hash{
+	"SHA1":   crypto.SHA1,
+	"SHA224": crypto.SHA224,
+	"SHA256": crypto.SHA256,
+	"SHA384": crypto.SHA384,
+	"SHA512": crypto.SHA512,
+}
+
+// MinHash returns the parsed MinHashAlgorithm of the key, or 0 if there is no minimum.
+func (k KeyConfig) MinHash() (crypto.Hash, error) {
+	return minHash(k.MinHashAlgorithm)
+}
+
+// minHash returns the hash function of the MinHashAlgorithm name, or 0 if name is empty.
+func minHash(name string) (crypto.Hash, error) {
+	if name == "" {
+		return 0, nil
+	}
+	hash, ok := hashAlgorithms[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown MinHashAlgorithm %q", name)
+	}
+	return hash, nil
+}
+
+// CTLogConfig contains information about a certificate transparency log.
+type CTLogConfig struct {
+	// URL is the base URL of the RFC 6962 API of the log, such as "https://ct.example.com/2019".
+	URL string
+	// PublicKey is the base64 encoded DER public key of the log, as published in CT log lists.
+	PublicKey string
+}
+
+// EphemeralKeyConfig contains information about the one-time keys generated in HSM for each
+// PostEphemeralSignature request, whose private keys never leave the HSM.
+type EphemeralKeyConfig struct {
+	// Identifier is a unique name that requests use to refer to this configuration. It cannot be the
+	// identifier of a key in Keys.
+	Identifier string
+	// Module is the name of the PKCS#11 module in Config.Modules whose HSM generates the keys.
+	// If empty, the module at Config.ModulePath is used.
+	Module string
+	// SlotNumber is the slot number in HSM. The slot must hold a key in Keys, whose pin is used.
+	SlotNumber uint
+	// KeyType specifies the type of the keys, RSA or Ed25519.
+	KeyType crypki.PublicKeyAlgorithm
+	// KeySize is the modulus size in bits of RSA keys. Default is 2048.
+	KeySize int
+	// SigningWindow restricts the signing requests of this configuration to a window of the week. The requests
+	// outside the window fail with FailedPrecondition. If nil, it signs at any time.
+	SigningWindow *SigningWindow
+}
+
+// SlotSignRate is the steady rate at which the signing requests of the keys of a slot of an HSM are served.
+type SlotSignRate struct {
+	// Module is the name of the PKCS#11 module in Config.Modules of the HSM of the slot.
+	// If empty, the module at Config.ModulePath is used.
+	Module string
+	// SlotNumber is the slot number in HSM.
+	SlotNumber uint
+	// Rate is the number of signing requests per second.
+	Rate float64
 }
 
 // Config defines struct to store configuration fields for crypki.
 type Config struct {
-	ModulePath        string
+	ModulePath string
+	// Modules maps names to the paths of additional PKCS#11 modules, such as those of HSMs
+	// from different vendors. Keys refer to these modules via KeyConfig.Module.
+	Modules map[string]string
+	// TLSClientAuthMode is the tls.ClientAuthType of the server: 4 (RequireAndVerifyClientCert), the default,
+	// or 3 (VerifyClientCertIfGiven) to also serve the clients without a certificate, which RequireCallerIdentity
+	// may then restrict to the read-only requests.
 	TLSClientAuthMode tls.ClientAuthType
 	TLSServerName     string
 	TLSServerCertPath string
 	TLSServerKeyPath  string
-	TLSCACertPath     string
-	TLSPort           string
-	SignersPerPool    int
-	Keys              []KeyConfig
-	KeyUsages         []KeyUsage
+	// TLSServerKeyIdentifier is the identifier of a key in Keys whose private key is used
+	// as the TLS server key. If specified, TLSServerKeyPath is ignored.
+	TLSServerKeyIdentifier string
+	TLSCACertPath          string
+	TLSPort                string
+	SignersPerPool         int
+	Keys                   []KeyConfig
+	KeyUsages              []KeyUsage
+	// WarnTLSKeyReuse specifies whether crypki starts, logging a warning, when the TLS server key is also a
+	// signing key, to migrate a deployment that reuses it. The TLS server key is compared to the keys used
+	// by KeyUsages, by slot and label and by public key at startup. By default, crypki refuses to start.
+	WarnTLSKeyReuse bool
+	// TLSServerKeySessions is the number of the sessions of the TLS server key opened for the TLS handshakes
+	// alone, so that handshakes and signing requests do not wait for each other's sessions. Default is 2.
+	TLSServerKeySessions int
+	// TLSMinVersion is the minimum TLS version accepted by the server, "1.2" or "1.3". Default is "1.2".
+	TLSMinVersion string
+	// TLSCipherSuites is the list of IANA names of the TLS 1.2 cipher suites accepted by the server, such as
+	// "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256". If empty, crypki's default suites are used. The TLS 1.3
+	// cipher suites are not configurable.
+	TLSCipherSuites []string
+	// AdminIdentities is the list of client certificate common names allowed to call the Admin service.
+	// Requests made through the REST gateway are authenticated as their REST clients.
+	AdminIdentities []string
+	// ProblemJSONErrors specifies whether the REST gateway returns its errors as RFC 7807 problem+json bodies,
+	// with the type, title, status and detail of the problem, rather than as the JSON of the gRPC status.
+	ProblemJSONErrors bool
+	// RequestTimeoutMs is the default timeout in milliseconds of requests that carry no client
+	// deadline. A client deadline takes precedence over KeyUsage.RequestTimeoutMs, which takes
+	// precedence over this value. If not specified, requests do not time out.
+	RequestTimeoutMs uint64
+	// SlowRequestThresholdMs is the default time in milliseconds above which requests are logged as slow,
+	// with the time spent in the interceptors and the handler. All requests are still counted in the metrics.
+	// If not specified, no request is logged as slow.
+	SlowRequestThresholdMs uint64
+	// MaxConnections is the maximum number of concurrent client connections. Connections beyond
+	// the limit are refused. If not specified, the number of connections is not limited.
+	MaxConnections int
+	// MaxConnectionsPerIP is the maximum number of concurrent connections from the same client IP.
+	// Connections beyond the limit are refused. If not specified, the number of connections is not limited.
+	MaxConnectionsPerIP int
+	// ProxyProtocol specifies whether the client connections start with a PROXY protocol (version 1 or 2)
+	// header, as sent by an L4 load balancer, whose client address is then used as the address of the
+	// connection, e.g. by MaxConnectionsPerIP. Connections without a header are refused, so the port must
+	// only be reachable through the load balancer.
+	ProxyProtocol bool
+	// MaxPendingProxyHandshakes is the maximum number of connections whose PROXY protocol headers are read
+	// at once, after which no connection is accepted until one of them is done, e.g. sends its header or
+	// times out. It bounds the connections not counted yet by MaxConnections. Default is 128.
+	MaxPendingProxyHandshakes int
+	// KeepaliveMaxConnectionIdleMs is the time in milliseconds after which a client connection without
+	// in-flight requests is closed, with a GOAWAY for HTTP/2 connections. Client keepalive pings do not
+	// keep a connection open, so clients keeping idle connections should reconnect. Default is 30000.
+	KeepaliveMaxConnectionIdleMs uint64
+	// KeepaliveTimeMs is the period in milliseconds of the TCP keepalive probes of the client connections,
+	// which close the half-open connections of clients that went away. Default is 15000, the period of the
+	// Go runtime. Unlike the gRPC keepalive of grpc.Server, client pings are not limited, as crypki serves
+	// gRPC through its HTTP server, which answers them without sending a GOAWAY.
+	KeepaliveTimeMs uint64
+	// MaxSSHCertOptions is the maximum number of critical options and extensions, together, of an SSH
+	// certificate request. Requests beyond the limit fail with InvalidArgument. Default is 64.
+	MaxSSHCertOptions int
+	// MaxSSHCertOptionsSize is the maximum total size in bytes of the names and values of the critical
+	// options and extensions of an SSH certificate request. Default is 16384.
+	MaxSSHCertOptionsSize int
+	// MaxBlobBatchSize is the maximum number of digests of a PostSignBlobBatch request. Requests beyond
+	// the limit fail with InvalidArgument. Default is 100.
+	MaxBlobBatchSize int
+	// MaxBlobBatchStreamSize is the maximum number of digests of a PostSignBlobBatchStream request, which streams
+	// the signature of each digest and so allows larger batches than MaxBlobBatchSize. Such requests are also
+	// limited by the MaxRequestSize of the blob endpoint. If not specified, the streamed batches are disabled.
+	MaxBlobBatchStreamSize int
+	// MaxInFlightStreamBytes is the maximum total number of bytes of the chunks being received by the PostSignBlobStream
+	// requests and not yet hashed. As gRPC allocates a message as it receives it, each stream reserves MaxRecvMsgSize
+	// bytes before receiving its next chunk and holds them until the chunk is hashed. A stream whose reservation would
+	// exceed the maximum fails with ResourceExhausted. It must be at least MaxRecvMsgSize. If not specified, the bytes
+	// in flight are not limited.
+	MaxInFlightStreamBytes int64
+	// MaxRecvMsgSize is the maximum size in bytes of the messages received by the gRPC server, which bounds the
+	// chunks of the PostSignBlobStream requests. Default is 4 MiB, the default of gRPC.
+	MaxRecvMsgSize int
+	// MaxConcurrentRequestsPerCaller is the maximum number of concurrent signing requests of a caller, identified
+	// by the common name of its client certificate. Requests beyond the limit fail with ResourceExhausted.
+	// If not specified, the number of concurrent requests is not limited.
+	MaxConcurrentRequestsPerCaller int
+	// CallerConcurrencyLimits maps caller identities to their maximum number of concurrent signing requests,
+	// overriding MaxConcurrentRequestsPerCaller, e.g. to give a batch signer more slots. Zero means no limit.
+	CallerConcurrencyLimits map[string]int
+	// CallerPermittedDomains maps caller identities, the common names of their client certificates, to the
+	// domains they may obtain x509 certificates for: every DNS SAN and the subject common name requested by
+	// such a caller must be one of the domains or a subdomain of one, or the request fails with PermissionDenied.
+	// The callers without an entry are restricted to the domains of the "*" entry, and denied if there is none.
+	// If empty, no caller is restricted.
+	CallerPermittedDomains map[string][]string
+	// CallerSignatureSchemes maps caller identities to the blob signature schemes they may use: "PKCS1v15",
+	// "PSS", "ECDSA_ASN1", "ECDSA_P1363" or "Ed25519ph", e.g. to restrict a caller to PSS for compliance.
+	// The scheme of a request that does not specify one is the default scheme of the key type. The blob
+	// signing requests of such a caller with another scheme fail with PermissionDenied, on top of the
+	// schemes supported by the key. The callers without an entry are not restricted.
+	CallerSignatureSchemes map[string][]string
+	// CallerSSHValidities maps caller identities, such as the break-glass admins or the service accounts, to
+	// the validity of their SSH user and host certificates.
+	CallerSSHValidities map[string]CallerValidity
+	// SlotSignRates paces the signing requests of the keys of the slots to steady rates, for HSMs that degrade
+	// under bursty load. Unlike the RateLimit of the keys, requests beyond the rate are not rejected: they
+	// wait for their turn, and fail with DeadlineExceeded only if their turn comes after their deadline.
+	// The keys of the slots without a rate are not paced.
+	SlotSignRates []SlotSignRate
+	// MinAPIVersion and MaxAPIVersion are the oldest and newest API versions supplied by clients in the
+	// "x-crypki-api-version" request metadata that are served. Requests with other versions fail with
+	// FailedPrecondition, and requests without a version are always served. Zero means no bound.
+	MinAPIVersion, MaxAPIVersion uint64
+	// WarnUnsupportedAPIVersions specifies whether requests with unsupported API versions are logged and
+	// served instead of being rejected, which is useful to find outdated clients before a rollout.
+	WarnUnsupportedAPIVersions bool
+	// KeyGenerationIdentities is the list of client certificate common names allowed to generate new keys
+	// in the HSM. They must also be listed in AdminIdentities. If empty, key generation is disabled.
+	// A generated key is used by the endpoints once added to Keys and KeyUsages and crypki is restarted.
+	KeyGenerationIdentities []string
+	// RetryInvalidSignatures specifies whether a blob signing request is retried once if the HSM
+	// returns a signature whose length or encoding is invalid for the key.
+	RetryInvalidSignatures bool
+	// AllowZeroDigests specifies whether empty and all-zero blob digests are signed. By default they are
+	// rejected: a hash function does not output them in practice, so they come from client bugs such as
+	// signing an uninitialized buffer, and one leaked signature would be valid for every such request.
+	AllowZeroDigests bool
+	// RejectLowEntropyDigests specifies whether blob digests made of implausibly few distinct byte values,
+	// another sign of a client bug, are rejected. It is a heuristic and disabled by default.
+	RejectLowEntropyDigests bool
+	// StrictBlobDigests specifies whether blob signing requests must specify a hash algorithm approved for
+	// the type of the key, as listed by GetKeyCapabilities, and a digest of the length of that hash.
+	// By default only the digests signed by Ed25519 keys are checked, and the hash algorithm defaults to SHA512.
+	StrictBlobDigests bool
+	// RequiredFields maps the SSH user and host certificate, x509 certificate and blob endpoints to the fields
+	// their requests must set, as dot-separated paths of the Go field names of the requests, such as "KeyId"
+	// for the SSH certificate requests. The x509 fields are the ones of the x509.Certificate composed from
+	// the CSR, such as "Subject.OrganizationalUnit". The requests lacking required fields fail with
+	// InvalidArgument listing them.
+	RequiredFields map[string][]string
+	// CircuitBreakerThreshold is the number of consecutive signing failures of a key after which
+	// its signing requests are rejected. If not specified, no circuit breaker is used.
+	CircuitBreakerThreshold int
+	// CircuitBreakerOpenTimeoutMs is the time in milliseconds the signing requests of a failing key
+	// are rejected before a single probe request is allowed to test its recovery. Default is 30000.
+	CircuitBreakerOpenTimeoutMs uint64
+	// IssuanceLogSize is the number of recently issued certificates whose metadata is retained in memory
+	// and listed by the ListRecentIssuance Admin RPC, for troubleshooting. If not specified, nothing is retained.
+	IssuanceLogSize int
+	// KeyReloadRetryDelayMs is the delay in milliseconds after which clients are told to retry the requests
+	// for a key that is being (re)loaded, such as a key being generated, and the signing requests rejected
+	// by RejectSigningUntilReady. Default is 1000.
+	KeyReloadRetryDelayMs uint64
+	// HSMLoginRetryIntervalMs is the interval in milliseconds at which crypki retries to load the keys
+	// whose login to the HSM fails at startup, e.g. because of a wrong user pin. These keys are
+	// unavailable and /ruok reports crypki as not ready until they are loaded, and a login failing
+	// with an incorrect pin is only retried once the pin file changes, so as not to lock the token.
+	// If not specified, crypki exits at startup if a login fails.
+	HSMLoginRetryIntervalMs uint64
+	// CacheCertFiles specifies whether the x509 CA certificate files of the keys, including the
+	// cross-signed ones, are parsed once per content, which speeds up the startup with many keys sharing
+	// CA certificates and the loading of the keys whose login is retried. A file is parsed again when
+	// its content changes.
+	CacheCertFiles bool
+	// RejectSigningUntilReady specifies whether all the signing requests, rather than only those of the keys
+	// not loaded yet, fail with Unavailable and a retry hint while /ruok reports crypki as not ready, so
+	// that clients retry them on the ready replicas during a rollout.
+	RejectSigningUntilReady bool
+	// RequireCallerIdentity specifies whether the requests to the Signing service fail with Unauthenticated
+	// when made on a connection without a verified client certificate, whatever TLSClientAuthMode is.
+	RequireCallerIdentity bool
+	// ExemptReadOnlyFromCallerIdentity specifies whether the requests other than the signing requests,
+	// such as those for the public keys, are served without a verified client certificate when
+	// RequireCallerIdentity is set.
+	ExemptReadOnlyFromCallerIdentity bool
+	// VerboseErrors specifies whether the messages of internal errors returned to clients include the
+	// underlying error, which is useful for debugging in staging. It must not be set in production.
+	VerboseErrors bool
+	// RedactLogs specifies whether the blob digests and x509 serial numbers in the request log lines are
+	// replaced by their SHA256 hashes truncated to 8 bytes, for log systems that must not hold the full values.
+	RedactLogs bool
+	// LogSignerOpts specifies whether the hash, signature scheme, salt length and signature encoding finally
+	// used for each blob signing request are logged, to debug the selection of the signing algorithms.
+	LogSignerOpts bool
+	// VerboseLogSampleRate is the fraction of the requests, between 0 and 1, whose full request and response
+	// are logged in addition to the summary log line, such as 0.01 for 1% of the requests. The sample is
+	// deterministic per "x-request-id" request metadata. It cannot be combined with RedactLogs.
+	VerboseLogSampleRate float64
+	// X509SerialStoreDir is the directory in which the serial numbers of x509 certificates are reserved
+	// before they are signed. It must be shared by all crypki replicas to guarantee unique serial numbers
+	// across them. If not specified, serial numbers are not reserved.
+	X509SerialStoreDir string
+	// IssuanceStoreDir is the directory in which the x509 and SSH certificates are recorded once signed, to be
+	// tracked and revoked, and looked up by PostIssuanceManifest by serial number or fingerprint. The store is only
+	// shared by the crypki replicas if the directory is on a shared filesystem, such as NFS, supporting hard links;
+	// otherwise each replica only finds the certificates it issued. If not specified, the certificates are not
+	// recorded. The certificates that fail to be recorded are still returned, unless their key is configured
+	// with RequireIssuanceStore.
+	IssuanceStoreDir string
+	// NotBeforeWatermarkDir is the directory in which the latest notBefore of the certificates signed by
+	// the keys configured with MonotonicNotBefore is persisted. It must be local to each crypki replica.
+	NotBeforeWatermarkDir string
+	// SSHSerialCounterDir is the directory in which the SSH serial counters of the keys configured with
+	// SSHSerialCounter are checkpointed. It must be local to each crypki replica.
+	SSHSerialCounterDir string
+	// SSHSerialCheckpointInterval is the number of SSH serial numbers handed out between two checkpoints of
+	// a counter. After a crash, the counter skips up to this many serial numbers. Default is 1000.
+	SSHSerialCheckpointInterval uint64
+	// PreSignHookPath is the path of a program run before each signing request with the request metadata
+	// as a JSON document on its stdin. The request is signed only if the program exits with status 0;
+	// otherwise it fails with PermissionDenied and the first line of the stderr of the program.
+	// If not specified, no hook is run.
+	PreSignHookPath string
+	// PreSignHookTimeoutMs is the time in milliseconds after which the pre-sign hook is killed and
+	// the request fails with Unavailable. Default is 5000.
+	PreSignHookTimeoutMs uint64
+	// MutatingWebhookURL is the http or https URL of a service receiving the x509 certificates composed for
+	// the signing requests, as a JSON document, before they are signed. The service may deny a request or
+	// return a modified certificate, which is checked again against the policy of the key; a certificate
+	// violating the policy is rejected. If not specified, the certificates are signed as composed.
+	MutatingWebhookURL string
+	// MutatingWebhookTimeoutMs is the time in milliseconds after which the request to the mutating webhook
+	// is canceled and the signing request fails with Unavailable. Default is 5000.
+	MutatingWebhookTimeoutMs uint64
+	// TrustedTimeURL is the http or https URL of a trusted time source, whose Date header is compared to the
+	// local clock every ClockDriftCheckIntervalMs. While the local clock drifts by more than MaxClockDriftMs,
+	// the x509 and SSH certificate and timestamp requests fail with FailedPrecondition; the blob signing
+	// requests are served. A failed measurement keeps the previous one. If not specified, the clock is trusted.
+	TrustedTimeURL string
+	// MaxClockDriftMs is the maximum drift in milliseconds of the local clock. It is required with
+	// TrustedTimeURL, and should be several seconds as the Date header has a resolution of one second.
+	MaxClockDriftMs uint64
+	// ClockDriftCheckIntervalMs is the interval in milliseconds between the measurements of the drift of
+	// the local clock. Default is 60000.
+	ClockDriftCheckIntervalMs uint64
+	// SelfTestIntervalMs is the interval in milliseconds between the sweeps of the self-test, which signs a test
+	// digest with each key and verifies the signature, so that the broken keys are logged and listed as degraded
+	// before the clients' requests fail. If not specified, the keys are not self-tested.
+	SelfTestIntervalMs uint64
+	// SelfTestConcurrency is the maximum number of keys self-tested in parallel, so that a sweep of many keys
+	// fits within SelfTestIntervalMs. Default is 8.
+	SelfTestConcurrency int
+	// SubjectKeyDenyListPath is the path of a file listing the subject public keys that are never certified
+	// in x509 and SSH certificates, such as known-compromised keys, one hex encoded SHA256 fingerprint of the
+	// DER encoded SubjectPublicKeyInfo per line. Requests for the listed keys fail with PermissionDenied.
+	// The file is reloaded on SIGHUP. If not specified, no subject key is denied.
+	SubjectKeyDenyListPath string
+	// ReceiptKeyIdentifier is the identifier of a key in Keys signing the receipts attesting the issuance
+	// of the x509 and SSH certificates, returned along with the certificates. The key cannot be used by
+	// the certificate endpoints. It also signs the manifests of the sessions of issued certificates returned by
+	// PostIssuanceManifest, which require IssuanceStoreDir. If not specified, no receipt is returned.
+	ReceiptKeyIdentifier string
+	// EphemeralKeys are the configurations of the one-time keys that sign the digests of the
+	// PostEphemeralSignature requests, which are used once listed in the KeyUsages of "/sig/ephemeral".
+	// If empty, the requests fail with InvalidArgument.
+	EphemeralKeys []EphemeralKeyConfig
+	// KeyAliases maps client-facing aliases to the identifiers of the keys in Keys. Requests may refer to a key
+	// by its alias, which can be remapped to another key without the clients changing their requests, and
+	// the keys are listed by their aliases.
+	KeyAliases map[string]string
+	// RateLimitBackend is the backend holding the state of the rate limits of the keys: "memory" to enforce
+	// the limits per replica, or "redis" to enforce them across the replicas sharing RateLimitRedisAddress.
+	// Default is "memory".
+	RateLimitBackend string
+	// RateLimitRedisAddress is the host:port address of the Redis server of the "redis" backend.
+	RateLimitRedisAddress string
+	// RateLimitRedisTimeoutMs is the timeout in milliseconds of the requests to the Redis server. Default is 100.
+	RateLimitRedisTimeoutMs uint64
+	// RateLimitFailClosed specifies whether the signing requests of rate limited keys are rejected with
+	// Unavailable if the backend fails. By default they are signed without checking the rate limit.
+	RateLimitFailClosed bool
+	// InterceptorOrder is the order of the gRPC interceptors of the unary requests, named as in Interceptors,
+	// the first one being the outermost. The interceptors not listed run after the listed ones, in their
+	// default order. The metrics and slow request logging always run first.
+	InterceptorOrder []string
+	// DisabledInterceptors are the names of the gRPC interceptors not run, e.g. "rate-limit" when the rate
+	// limits are enforced by a gateway in front of crypki.
+	DisabledInterceptors []string
 }
 
 // Parse loads configuration values from input file and returns config object and CA cert.
@@ -110,23 +1111,330 @@ func (c *Config) validate() error {
 		return fmt.Errorf("TLSServerName cannot be empty. Please specify it in the config")
 	}
 	c.TLSServerName = strings.TrimSpace(c.TLSServerName)
+	if err := c.validateTLS(); err != nil {
+		return err
+	}
+	if c.VerboseLogSampleRate < 0 || c.VerboseLogSampleRate > 1 {
+		return fmt.Errorf("VerboseLogSampleRate %v must be between 0 and 1", c.VerboseLogSampleRate)
+	}
+	if c.VerboseLogSampleRate > 0 && c.RedactLogs {
+		return errors.New("VerboseLogSampleRate cannot be combined with RedactLogs, as verbose log lines contain the full requests")
+	}
+	if c.MaxAPIVersion != 0 && c.MinAPIVersion > c.MaxAPIVersion {
+		return fmt.Errorf("MinAPIVersion %d is greater than MaxAPIVersion %d", c.MinAPIVersion, c.MaxAPIVersion)
+	}
+	if c.TLSServerKeyIdentifier != "" && !c.hasKey(c.TLSServerKeyIdentifier) {
+		return fmt.Errorf("TLS server key identifier %q not found in Keys", c.TLSServerKeyIdentifier)
+	}
+	if c.TLSServerKeySessions < 0 {
+		return errors.New("TLSServerKeySessions cannot be negative")
+	}
+	if c.MaxPendingProxyHandshakes < 0 {
+		return errors.New("MaxPendingProxyHandshakes cannot be negative")
+	}
+	if id := c.tlsKeyReuse(); id != "" && !c.WarnTLSKeyReuse {
+		return fmt.Errorf("TLS server key %q is also the signing key %q", c.TLSServerKeyIdentifier, id)
+	}
+	if c.ReceiptKeyIdentifier != "" {
+		if !c.hasKey(c.ReceiptKeyIdentifier) {
+			return fmt.Errorf("receipt key identifier %q not found in Keys", c.ReceiptKeyIdentifier)
+		}
+		for _, usage := range c.KeyUsages {
+			if usage.Endpoint != X509CertEndpoint && usage.Endpoint != SSHUserCertEndpoint && usage.Endpoint != SSHHostCertEndpoint {
+				continue
+			}
+			for _, id := range usage.Identifiers {
+				if id == c.ReceiptKeyIdentifier {
+					return fmt.Errorf("receipt key %q cannot be used for %q", id, usage.Endpoint)
+				}
+			}
+		}
+	}
+	if err := c.validateEphemeralKeys(); err != nil {
+		return err
+	}
+	for alias, id := range c.KeyAliases {
+		if strings.TrimSpace(alias) == "" {
+			return errors.New("key alias cannot be empty")
+		}
+		if c.hasKey(alias) {
+			return fmt.Errorf("key alias %q is also the identifier of a key", alias)
+		}
+		if !c.hasKey(id) {
+			return fmt.Errorf("key identifier %q of alias %q not found in Keys", id, alias)
+		}
+	}
+	if c.TrustedTimeURL != "" {
+		if u, err := url.Parse(c.TrustedTimeURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid TrustedTimeURL %q", c.TrustedTimeURL)
+		}
+		if c.MaxClockDriftMs == 0 {
+			return errors.New("MaxClockDriftMs is required with TrustedTimeURL")
+		}
+	}
+	if c.SelfTestConcurrency < 0 {
+		return errors.New("SelfTestConcurrency cannot be negative")
+	}
+	if c.MutatingWebhookURL != "" {
+		if u, err := url.Parse(c.MutatingWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid MutatingWebhookURL %q", c.MutatingWebhookURL)
+		}
+	}
+	switch c.RateLimitBackend {
+	case "", RateLimitBackendMemory:
+	case RateLimitBackendRedis:
+		if strings.TrimSpace(c.RateLimitRedisAddress) == "" {
+			return errors.New("RateLimitRedisAddress is required by the redis RateLimitBackend")
+		}
+	default:
+		return fmt.Errorf("unknown RateLimitBackend %q", c.RateLimitBackend)
+	}
+	for _, names := range []struct {
+		field string
+		names []string
+	}{{"InterceptorOrder", c.InterceptorOrder}, {"DisabledInterceptors", c.DisabledInterceptors}} {
+		seen := make(map[string]bool)
+		for _, name := range names.names {
+			if !isInterceptor(name) {
+				return fmt.Errorf("unknown interceptor %q in %s", name, names.field)
+			}
+			if seen[name] {
+				return fmt.Errorf("duplicate interceptor %q in %s", name, names.field)
+			}
+			seen[name] = true
+		}
+	}
+	if c.MaxBlobBatchStreamSize < 0 {
+		return errors.New("MaxBlobBatchStreamSize cannot be negative")
+	}
+	if c.MaxInFlightStreamBytes < 0 {
+		return errors.New("MaxInFlightStreamBytes cannot be negative")
+	}
+	if c.MaxRecvMsgSize < 0 {
+		return errors.New("MaxRecvMsgSize cannot be negative")
+	}
+	if c.MaxInFlightStreamBytes != 0 && c.MaxInFlightStreamBytes < int64(c.MaxRecvMsgSize) {
+		return fmt.Errorf("MaxInFlightStreamBytes %d is less than MaxRecvMsgSize %d", c.MaxInFlightStreamBytes, c.MaxRecvMsgSize)
+	}
+	if c.MaxConcurrentRequestsPerCaller < 0 {
+		return errors.New("MaxConcurrentRequestsPerCaller cannot be negative")
+	}
+	for caller, limit := range c.CallerConcurrencyLimits {
+		if limit < 0 {
+			return fmt.Errorf("CallerConcurrencyLimits of %q cannot be negative", caller)
+		}
+	}
+	for caller, v := range c.CallerSSHValidities {
+		if v.MaxValidity != 0 && v.DefaultValidity > v.MaxValidity {
+			return fmt.Errorf("CallerSSHValidities of %q has a DefaultValidity greater than its MaxValidity", caller)
+		}
+	}
+	for caller, schemes := range c.CallerSignatureSchemes {
+		for _, scheme := range schemes {
+			if !signatureSchemes[scheme] {
+				return fmt.Errorf("CallerSignatureSchemes of %q has unknown signature scheme %q", caller, scheme)
+			}
+		}
+	}
+	for caller, domains := range c.CallerPermittedDomains {
+		for _, domain := range domains {
+			if strings.Trim(domain, ".") == "" || strings.Contains(domain, "*") {
+				return fmt.Errorf("CallerPermittedDomains of %q has invalid domain %q", caller, domain)
+			}
+		}
+	}
+	for _, rate := range c.SlotSignRates {
+		if rate.Rate <= 0 {
+			return fmt.Errorf("SlotSignRates of slot %d of module %q must be positive", rate.SlotNumber, rate.Module)
+		}
+		if rate.Module != "" && strings.TrimSpace(c.Modules[rate.Module]) == "" {
+			return fmt.Errorf("SlotSignRates: PKCS#11 module %q not found in Modules", rate.Module)
+		}
+	}
+	for _, key := range c.Keys {
+		if key.Module != "" && strings.TrimSpace(c.Modules[key.Module]) == "" {
+			return fmt.Errorf("key %q: PKCS#11 module %q not found in Modules", key.Identifier, key.Module)
+		}
+		if _, err := key.PSSSaltLengthValue(); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		if _, err := key.MinHash(); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		if key.RateLimit < 0 || key.RateLimitBurst < 0 {
+			return fmt.Errorf("key %q: RateLimit and RateLimitBurst cannot be negative", key.Identifier)
+		}
+		if _, err := key.X509AllowedExtensionOIDs(); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		if _, _, err := key.X509ExtKeyUsagePolicy(); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		if _, _, err := key.X509NameConstraintIPRanges(); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		hasNameConstraints := len(key.X509PermittedDNSDomains)+len(key.X509ExcludedDNSDomains)+len(key.X509PermittedIPRanges)+len(key.X509ExcludedIPRanges) != 0
+		if hasNameConstraints && !key.X509IssueCACerts {
+			return fmt.Errorf("key %q: x509 name constraints require X509IssueCACerts", key.Identifier)
+		}
+		if key.X509MaxPathLen < 0 {
+			return fmt.Errorf("key %q: invalid X509MaxPathLen %d", key.Identifier, key.X509MaxPathLen)
+		}
+		if key.X509MaxPathLen != 0 && !key.X509IssueCACerts {
+			return fmt.Errorf("key %q: X509MaxPathLen requires X509IssueCACerts", key.Identifier)
+		}
+		if key.X509MaxExtensionSize < 0 || key.X509MaxExtensionsSize < 0 {
+			return fmt.Errorf("key %q: X509MaxExtensionSize and X509MaxExtensionsSize cannot be negative", key.Identifier)
+		}
+		if p := key.PartialAvailability; p != "" && p != PartialAvailabilityStrict && p != PartialAvailabilityBestEffort {
+			return fmt.Errorf("key %q: unknown PartialAvailability %q", key.Identifier, p)
+		}
+		if e := key.X509CAExpiry; e != "" && e != X509CAExpiryClamp && e != X509CAExpiryStrict {
+			return fmt.Errorf("key %q: unknown X509CAExpiry %q", key.Identifier, e)
+		}
+		if key.X509ForbidWildcards && key.X509AllowWildcardDNSNames {
+			return fmt.Errorf("key %q: X509ForbidWildcards cannot be combined with X509AllowWildcardDNSNames", key.Identifier)
+		}
+		if w := key.SigningWindow; w != nil {
+			if err := w.validate(); err != nil {
+				return fmt.Errorf("key %q: %v", key.Identifier, err)
+			}
+		}
+		for _, ou := range key.X509AllowedOUs {
+			if ou == "" {
+				return fmt.Errorf("key %q: empty X509AllowedOUs entry", key.Identifier)
+			}
+		}
+		for name, profile := range key.X509Profiles {
+			if name == "" {
+				return fmt.Errorf("key %q: empty X509Profiles name", key.Identifier)
+			}
+			if e := profile.ExcessValidity; e != "" && e != X509ProfileValidityClamp && e != X509ProfileValidityReject {
+				return fmt.Errorf("key %q: unknown ExcessValidity %q of x509 profile %q", key.Identifier, e, name)
+			}
+			if err := validateCertificatePolicies(profile.CertificatePolicies); err != nil {
+				return fmt.Errorf("key %q: x509 profile %q: %v", key.Identifier, name, err)
+			}
+		}
+		if err := validateCertificatePolicies(key.X509CertificatePolicies); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		if key.MonotonicNotBefore && c.NotBeforeWatermarkDir == "" {
+			return fmt.Errorf("key %q: MonotonicNotBefore requires NotBeforeWatermarkDir", key.Identifier)
+		}
+		for _, location := range key.X509CrossSignedCACertLocations {
+			if strings.TrimSpace(location) == "" {
+				return fmt.Errorf("key %q: empty X509CrossSignedCACertLocations", key.Identifier)
+			}
+		}
+		if _, err := key.ObjectID(); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		if key.BlobSigningCertLocation != "" && strings.TrimSpace(key.BlobSigningCertLocation) == "" {
+			return fmt.Errorf("key %q: empty BlobSigningCertLocation", key.Identifier)
+		}
+		if key.RequireIssuanceStore && c.IssuanceStoreDir == "" {
+			return fmt.Errorf("key %q: RequireIssuanceStore requires IssuanceStoreDir", key.Identifier)
+		}
+		if key.DeterministicSignatures && (key.KeyType != crypki.ECDSA || key.Mechanism == "") {
+			return fmt.Errorf("key %q: DeterministicSignatures requires an ECDSA key with a Mechanism", key.Identifier)
+		}
+		if key.SSHSerialCounter && c.SSHSerialCounterDir == "" {
+			return fmt.Errorf("key %q: SSHSerialCounter requires SSHSerialCounterDir", key.Identifier)
+		}
+		if err := c.validateThreshold(key); err != nil {
+			return fmt.Errorf("key %q: %v", key.Identifier, err)
+		}
+		for _, t := range key.SSHSubjectKeyTypes {
+			switch t {
+			case ssh.KeyAlgoRSA, ssh.KeyAlgoDSA, ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521, ssh.KeyAlgoED25519:
+			default:
+				return fmt.Errorf("key %q: unknown SSHSubjectKeyTypes value %q", key.Identifier, t)
+			}
+		}
+		if e := key.SSHStrictExtensions; e != "" && e != SSHExtensionsStrip && e != SSHExtensionsReject {
+			return fmt.Errorf("key %q: unknown SSHStrictExtensions %q", key.Identifier, e)
+		}
+		if key.MaxSSHSubjectRSAKeySize != 0 && key.MinSSHSubjectRSAKeySize > key.MaxSSHSubjectRSAKeySize {
+			return fmt.Errorf("key %q: MinSSHSubjectRSAKeySize %d is greater than MaxSSHSubjectRSAKeySize %d", key.Identifier, key.MinSSHSubjectRSAKeySize, key.MaxSSHSubjectRSAKeySize)
+		}
+		for _, t := range key.X509SANTypes {
+			if t != X509SANTypeDNS && t != X509SANTypeIP && t != X509SANTypeEmail && t != X509SANTypeURI {
+				return fmt.Errorf("key %q: unknown X509SANTypes value %q", key.Identifier, t)
+			}
+		}
+		for _, ctLog := range key.X509CTLogs {
+			if ctLog.URL == "" {
+				return fmt.Errorf("key %q: X509CTLogs URL cannot be empty", key.Identifier)
+			}
+			if _, err := base64.StdEncoding.DecodeString(ctLog.PublicKey); err != nil {
+				return fmt.Errorf("key %q: invalid public key of CT log %q: %v", key.Identifier, ctLog.URL, err)
+			}
+		}
+	}
+	for endpoint, paths := range c.RequiredFields {
+		if endpoint != SSHUserCertEndpoint && endpoint != SSHHostCertEndpoint && endpoint != X509CertEndpoint && endpoint != BlobEndpoint {
+			return fmt.Errorf("RequiredFields of unsupported endpoint %q", endpoint)
+		}
+		for _, path := range paths {
+			if strings.TrimSpace(path) == "" {
+				return fmt.Errorf("RequiredFields of endpoint %q has an empty field", endpoint)
+			}
+		}
+	}
 	// Do a basic validation on Keys and KeyUsages.
 	for _, ku := range c.KeyUsages {
-		if ku.Endpoint != X509CertEndpoint && ku.Endpoint != SSHHostCertEndpoint && ku.Endpoint != SSHUserCertEndpoint {
+		if ku.Endpoint != X509CertEndpoint && ku.Endpoint != BlobEndpoint && ku.Endpoint != SSHHostCertEndpoint && ku.Endpoint != SSHUserCertEndpoint && ku.Endpoint != TimestampEndpoint && ku.Endpoint != GitEndpoint && ku.Endpoint != DNSSECEndpoint && ku.Endpoint != ContainerImageEndpoint && ku.Endpoint != AttestationEndpoint && ku.Endpoint != EphemeralEndpoint && ku.Endpoint != IssuanceManifestEndpoint {
 			return fmt.Errorf("unknown endpoint %q", ku.Endpoint)
 		}
-		// Check that all key identifiers are defined in Keys,
+		if ku.Endpoint == IssuanceManifestEndpoint && c.IssuanceStoreDir == "" {
+			return fmt.Errorf("endpoint %q requires IssuanceStoreDir", ku.Endpoint)
+		}
+		if ku.MaxValidity != 0 && ku.MinValidity > ku.MaxValidity {
+			return fmt.Errorf("MinValidity of endpoint %q is greater than its MaxValidity", ku.Endpoint)
+		}
+		if ku.MaxRequestSize < 0 {
+			return fmt.Errorf("MaxRequestSize %d of endpoint %q cannot be negative", ku.MaxRequestSize, ku.Endpoint)
+		}
+		// Check that all key identifiers are defined in Keys, or in EphemeralKeys for "/sig/ephemeral",
 		// and all keys used for "/sig/x509-cert" have x509 CA cert configured.
+		// The only key of "/issuance/manifest" is the receipt key.
 	next:
 		for _, id := range ku.Identifiers {
+			if ku.Endpoint == IssuanceManifestEndpoint {
+				if id != c.ReceiptKeyIdentifier {
+					return fmt.Errorf("key %q is not the receipt key, and cannot be used for %q", id, ku.Endpoint)
+				}
+				continue
+			}
+			if ku.Endpoint == EphemeralEndpoint {
+				if !c.hasEphemeralKey(id) {
+					return fmt.Errorf("ephemeral key identifier %q not found for endpoint %q", id, ku.Endpoint)
+				}
+				continue
+			}
 			for _, key := range c.Keys {
-				if key.KeyType < crypki.RSA || key.KeyType > crypki.ECDSA {
+				if key.KeyType < crypki.RSA || key.KeyType > crypki.SLHDSA {
 					return fmt.Errorf("key %q: invalid KeyType specified", key.Identifier)
 				}
 				if key.Identifier == id {
+					if key.KeyType.IsPostQuantum() && ku.Endpoint != BlobEndpoint {
+						return fmt.Errorf("post-quantum key %q can only be used for %q, not %q", id, BlobEndpoint, ku.Endpoint)
+					}
 					if ku.Endpoint == X509CertEndpoint && key.X509CACertLocation == "" {
 						return fmt.Errorf("key %q is used for signing x509 certs, but X509CACertLocation is not specified", id)
 					}
+					if ku.Endpoint == AttestationEndpoint && key.X509CACertLocation == "" {
+						return fmt.Errorf("key %q is used for signing attestations, but X509CACertLocation is not specified", id)
+					}
+					if ku.Endpoint == TimestampEndpoint {
+						if key.X509CACertLocation == "" {
+							return fmt.Errorf("key %q is used for signing time-stamp tokens, but X509CACertLocation is not specified", id)
+						}
+						if _, err := key.TSAPolicy(); err != nil {
+							return fmt.Errorf("key %q: %v", id, err)
+						}
+					}
 					continue next
 				}
 			}
@@ -136,8 +1444,132 @@ func (c *Config) validate() error {
 	return nil
 }
 
+// isInterceptor returns true if name is one of Interceptors.
+func isInterceptor(name string) bool {
+	for _, i := range Interceptors {
+		if i == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateThreshold returns an error if the threshold shares of the key are invalid.
+func (c *Config) validateThreshold(key KeyConfig) error {
+	if len(key.ThresholdShares) == 0 {
+		if key.Threshold != 0 {
+			return errors.New("Threshold is specified without ThresholdShares")
+		}
+		return nil
+	}
+	if key.KeyType != crypki.RSA {
+		return errors.New("ThresholdShares are only supported for RSA keys")
+	}
+	if key.Threshold < 1 || key.Threshold > len(key.ThresholdShares) {
+		return fmt.Errorf("Threshold %d must be between 1 and the number of ThresholdShares %d", key.Threshold, len(key.ThresholdShares))
+	}
+	seen := make(map[int]bool)
+	for _, share := range key.ThresholdShares {
+		if share.Index < 1 || share.Index > len(key.ThresholdShares) || seen[share.Index] {
+			return fmt.Errorf("invalid or duplicate threshold share index %d", share.Index)
+		}
+		seen[share.Index] = true
+		if share.Module != "" && strings.TrimSpace(c.Modules[share.Module]) == "" {
+			return fmt.Errorf("PKCS#11 module %q of threshold share %d not found in Modules", share.Module, share.Index)
+		}
+	}
+	return nil
+}
+
+// validateEphemeralKeys checks that the EphemeralKeys have unique identifiers and supported key types,
+// and that their slots hold keys in Keys.
+func (c *Config) validateEphemeralKeys() error {
+	seen := make(map[string]bool)
+	for _, e := range c.EphemeralKeys {
+		if strings.TrimSpace(e.Identifier) == "" {
+			return errors.New("ephemeral key identifier cannot be empty")
+		}
+		if seen[e.Identifier] || c.hasKey(e.Identifier) {
+			return fmt.Errorf("ephemeral key identifier %q is not unique", e.Identifier)
+		}
+		seen[e.Identifier] = true
+		switch e.KeyType {
+		case crypki.RSA:
+			if e.KeySize < defaultEphemeralKeySize {
+				return fmt.Errorf("ephemeral key %q: RSA key size %d is less than %d", e.Identifier, e.KeySize, defaultEphemeralKeySize)
+			}
+		case crypki.Ed25519:
+		default:
+			return fmt.Errorf("ephemeral key %q: KeyType must be RSA or Ed25519", e.Identifier)
+		}
+		slotHasKey := false
+		for _, key := range c.Keys {
+			slotHasKey = slotHasKey || (key.Module == e.Module && key.SlotNumber == e.SlotNumber && len(key.ThresholdShares) == 0)
+		}
+		if !slotHasKey {
+			return fmt.Errorf("ephemeral key %q: slot %d of module %q holds no key in Keys", e.Identifier, e.SlotNumber, e.Module)
+		}
+		if w := e.SigningWindow; w != nil {
+			if err := w.validate(); err != nil {
+				return fmt.Errorf("ephemeral key %q: %v", e.Identifier, err)
+			}
+		}
+	}
+	return nil
+}
+
+// hasKey returns true if a key with the given identifier is defined in Keys.
+func (c *Config) hasKey(identifier string) bool {
+	for _, key := range c.Keys {
+		if key.Identifier == identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEphemeralKey returns true if an ephemeral key with the given identifier is defined in EphemeralKeys.
+func (c *Config) hasEphemeralKey(identifier string) bool {
+	for _, e := range c.EphemeralKeys {
+		if e.Identifier == identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsKeyReuse returns the identifier of a key used by KeyUsages that is the same HSM key as the
+// TLS server key, or "" if there is none.
+func (c *Config) tlsKeyReuse() string {
+	if c.TLSServerKeyIdentifier == "" {
+		return ""
+	}
+	var tlsKey *KeyConfig
+	for i := range c.Keys {
+		if c.Keys[i].Identifier == c.TLSServerKeyIdentifier {
+			tlsKey = &c.Keys[i]
+		}
+	}
+	if tlsKey == nil {
+		return ""
+	}
+	for _, usage := range c.KeyUsages {
+		for _, id := range usage.Identifiers {
+			for _, key := range c.Keys {
+				if key.Identifier == id && key.SlotNumber == tlsKey.SlotNumber && key.KeyLabel == tlsKey.KeyLabel {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
 // loadDefaults assigns default values to missing configuration fields.
 func (c *Config) loadDefaults() {
+	if c.TLSClientAuthMode == tls.NoClientCert {
+		c.TLSClientAuthMode = tls.RequireAndVerifyClientCert
+	}
 	if strings.TrimSpace(c.ModulePath) == "" {
 		c.ModulePath = defaultModulePath
 	}
@@ -156,6 +1588,64 @@ func (c *Config) loadDefaults() {
 	if strings.TrimSpace(c.TLSPort) == "" {
 		c.TLSPort = defaultTLSPort
 	}
+	if c.CircuitBreakerOpenTimeoutMs == 0 {
+		c.CircuitBreakerOpenTimeoutMs = defaultBreakerTimeoutMs
+	}
+	if c.KeyReloadRetryDelayMs == 0 {
+		c.KeyReloadRetryDelayMs = defaultKeyRetryDelayMs
+	}
+	if c.PreSignHookTimeoutMs == 0 {
+		c.PreSignHookTimeoutMs = defaultHookTimeoutMs
+	}
+	if c.MutatingWebhookTimeoutMs == 0 {
+		c.MutatingWebhookTimeoutMs = defaultHookTimeoutMs
+	}
+	if c.RateLimitRedisTimeoutMs == 0 {
+		c.RateLimitRedisTimeoutMs = defaultRedisTimeoutMs
+	}
+	if c.ClockDriftCheckIntervalMs == 0 {
+		c.ClockDriftCheckIntervalMs = defaultDriftIntervalMs
+	}
+	if c.SelfTestConcurrency == 0 {
+		c.SelfTestConcurrency = defaultSelfTestParallel
+	}
+	if c.TLSServerKeyIdentifier != "" && c.TLSServerKeySessions == 0 {
+		c.TLSServerKeySessions = defaultTLSKeySessions
+	}
+	if c.KeepaliveMaxConnectionIdleMs == 0 {
+		c.KeepaliveMaxConnectionIdleMs = defaultIdleTimeoutMs
+	}
+	if c.MaxPendingProxyHandshakes == 0 {
+		c.MaxPendingProxyHandshakes = defaultProxyHandshakes
+	}
+	if c.MaxSSHCertOptions == 0 {
+		c.MaxSSHCertOptions = defaultMaxSSHOptions
+	}
+	if c.MaxSSHCertOptionsSize == 0 {
+		c.MaxSSHCertOptionsSize = defaultMaxSSHOptionsSize
+	}
+	if c.MaxBlobBatchSize == 0 {
+		c.MaxBlobBatchSize = defaultMaxBlobBatchSize
+	}
+	if c.MaxRecvMsgSize == 0 {
+		c.MaxRecvMsgSize = defaultMaxRecvMsgSize
+	}
+	if c.SSHSerialCheckpointInterval == 0 {
+		c.SSHSerialCheckpointInterval = defaultSSHSerialInterval
+	}
+	for i := range c.KeyUsages {
+		if c.KeyUsages[i].MaxRequestSize == 0 {
+			c.KeyUsages[i].MaxRequestSize = defaultMaxRequestSizes[c.KeyUsages[i].Endpoint]
+		}
+	}
+	for i := range c.EphemeralKeys {
+		if c.EphemeralKeys[i].KeyType == 0 {
+			c.EphemeralKeys[i].KeyType = defaultKeyType
+		}
+		if c.EphemeralKeys[i].KeyType == crypki.RSA && c.EphemeralKeys[i].KeySize == 0 {
+			c.EphemeralKeys[i].KeySize = defaultEphemeralKeySize
+		}
+	}
 	for i := range c.Keys {
 		if c.Keys[i].KeyType == 0 {
 			c.Keys[i].KeyType = defaultKeyType
@@ -163,5 +1653,46 @@ func (c *Config) loadDefaults() {
 		if c.Keys[i].SessionPoolSize == 0 {
 			c.Keys[i].SessionPoolSize = defaultPoolSize
 		}
+		if c.Keys[i].RateLimit > 0 && c.Keys[i].RateLimitBurst == 0 {
+			c.Keys[i].RateLimitBurst = int(math.Ceil(c.Keys[i].RateLimit))
+		}
+	}
+}
+
+// redactedValue replaces the redacted configuration fields.
+const redactedValue = "REDACTED"
+
+// Redacted returns a copy of the configuration whose secrets and paths to secrets, namely the pin
+// paths, the TLS server key path and the password of the mutating webhook URL, are redacted.
+// The configuration itself is not modified.
+func (c *Config) Redacted() *Config {
+	r := *c
+	if r.TLSServerKeyPath != "" {
+		r.TLSServerKeyPath = redactedValue
+	}
+	if u, err := url.Parse(r.MutatingWebhookURL); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+			r.MutatingWebhookURL = u.String()
+		}
+	}
+	if c.Keys != nil {
+		r.Keys = make([]KeyConfig, len(c.Keys))
+	}
+	for i, key := range c.Keys {
+		if key.UserPinPath != "" {
+			key.UserPinPath = redactedValue
+		}
+		if key.ThresholdShares != nil {
+			key.ThresholdShares = make([]ThresholdShareConfig, len(c.Keys[i].ThresholdShares))
+			for j, share := range c.Keys[i].ThresholdShares {
+				if share.UserPinPath != "" {
+					share.UserPinPath = redactedValue
+				}
+				key.ThresholdShares[j] = share
+			}
+		}
+		r.Keys[i] = key
 	}
+	return &r
 }
@@ -0,0 +1,69 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// tlsVersions maps the values of Config.TLSMinVersion to the TLS versions. Versions older than
+// TLS 1.2 are deliberately not supported.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsCipherSuites maps the IANA names of the TLS 1.2 cipher suites that may be listed in
+// Config.TLSCipherSuites to their identifiers.
+var tlsCipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_AES_128_CBC_SHA":                  tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":                  tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_CBC_SHA256":               tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+	"TLS_RSA_WITH_AES_128_GCM_SHA256":               tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_RSA_WITH_AES_256_GCM_SHA384":               tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":          tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":          tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256":       tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256":         tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256":       tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384":       tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256":   tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256": tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+}
+
+// validateTLS returns an error if the TLS version or a cipher suite of the configuration is unknown,
+// or if the client authentication mode lets clients connect with unverified certificates.
+func (c *Config) validateTLS() error {
+	if c.TLSClientAuthMode != tls.VerifyClientCertIfGiven && c.TLSClientAuthMode != tls.RequireAndVerifyClientCert {
+		return fmt.Errorf("TLSClientAuthMode %d must be %d (VerifyClientCertIfGiven) or %d (RequireAndVerifyClientCert)",
+			c.TLSClientAuthMode, tls.VerifyClientCertIfGiven, tls.RequireAndVerifyClientCert)
+	}
+	if _, ok := tlsVersions[c.TLSMinVersion]; c.TLSMinVersion != "" && !ok {
+		return fmt.Errorf("unknown TLSMinVersion %q, valid values are \"1.2\" and \"1.3\"", c.TLSMinVersion)
+	}
+	for _, name := range c.TLSCipherSuites {
+		if _, ok := tlsCipherSuites[name]; !ok {
+			return fmt.Errorf("unknown TLSCipherSuites value %q", name)
+		}
+	}
+	return nil
+}
+
+// ApplyTLS sets the minimum TLS version and the cipher suites of the configuration, if specified, in cfg.
+func (c *Config) ApplyTLS(cfg *tls.Config) {
+	if v, ok := tlsVersions[c.TLSMinVersion]; ok {
+		cfg.MinVersion = v
+	}
+	if len(c.TLSCipherSuites) != 0 {
+		cfg.CipherSuites = make([]uint16, 0, len(c.TLSCipherSuites))
+		for _, name := range c.TLSCipherSuites {
+			cfg.CipherSuites = append(cfg.CipherSuites, tlsCipherSuites[name])
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testTLSCertificate returns a self-signed TLS certificate for "localhost".
+func testTLSCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestApplyTLS(t *testing.T) {
+	t.Parallel()
+	cert := testTLSCertificate(t)
+	testcases := map[string]struct {
+		config           Config
+		clientMaxVersion uint16
+		clientSuites     []uint16
+		expectError      bool
+	}{
+		"default-tls12-client":   {clientMaxVersion: tls.VersionTLS12},
+		"min-1.3-tls12-client":   {config: Config{TLSMinVersion: "1.3"}, clientMaxVersion: tls.VersionTLS12, expectError: true},
+		"min-1.3-tls13-client":   {config: Config{TLSMinVersion: "1.3"}, clientMaxVersion: tls.VersionTLS13},
+		"suites-matching-client": {config: Config{TLSCipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}}, clientMaxVersion: tls.VersionTLS12, clientSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}},
+		"suites-other-client":    {config: Config{TLSCipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}}, clientMaxVersion: tls.VersionTLS12, clientSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, expectError: true},
+	}
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			serverConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+			tt.config.ApplyTLS(serverConfig)
+			pool := x509.NewCertPool()
+			leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+			pool.AddCert(leaf)
+			clientConfig := &tls.Config{ServerName: "localhost", RootCAs: pool, MaxVersion: tt.clientMaxVersion, CipherSuites: tt.clientSuites}
+
+			serverConn, clientConn := net.Pipe()
+			defer serverConn.Close()
+			defer clientConn.Close()
+			serverErr := make(chan error, 1)
+			go func() {
+				err := tls.Server(serverConn, serverConfig).Handshake()
+				serverConn.Close()
+				serverErr <- err
+			}()
+			err := tls.Client(clientConn, clientConfig).Handshake()
+			clientConn.Close()
+			if err == nil {
+				err = <-serverErr
+			}
+			if err != nil != tt.expectError {
+				t.Errorf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+		})
+	}
+}
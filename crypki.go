@@ -4,8 +4,12 @@
 package crypki
 
 import (
+	"context"
 	"crypto"
 	"crypto/x509"
+	"errors"
+	"math/big"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 )
@@ -30,6 +34,26 @@ const (
 	UnknownPublicKeyAlgorithm PublicKeyAlgorithm = iota
 	RSA
 	ECDSA
+	Ed25519
+	// MLDSA is the post-quantum ML-DSA of FIPS 204, for the HSMs providing the mechanisms of PKCS#11 v3.2.
+	MLDSA
+	// SLHDSA is the post-quantum SLH-DSA of FIPS 205, for the HSMs providing the mechanisms of PKCS#11 v3.2.
+	SLHDSA
+)
+
+// IsPostQuantum returns whether the public key algorithm is a post-quantum one.
+func (a PublicKeyAlgorithm) IsPostQuantum() bool {
+	return a == MLDSA || a == SLHDSA
+}
+
+// Priority is the priority of a signing request for a session of the signing key.
+type Priority int
+
+// List of supported priorities. The zero value is the default priority of the requests.
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+	PriorityLow
 )
 
 // CertSign interface contains methods related to signing certificates.
@@ -48,6 +72,165 @@ type CertSign interface {
 	Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error)
 }
 
+// PrioritizedCertSign interface contains methods related to prioritizing signing requests.
+type PrioritizedCertSign interface {
+	// WithPriority returns a CertSign whose signing requests wait for a session of the signing key
+	// with the specified priority. Waiting requests of higher priority are served first.
+	WithPriority(priority Priority) CertSign
+}
+
+// ContextCertSign interface contains methods related to bounding signing requests by a context.
+type ContextCertSign interface {
+	// WithContext returns a CertSign whose signing requests give up waiting for a session of the signing
+	// key, and are not sent to the HSM, once ctx is done. They then return the error of ctx.
+	WithContext(ctx context.Context) CertSign
+}
+
+// KeyGenerator interface contains methods related to provisioning new signing keys.
+type KeyGenerator interface {
+	// GenerateKey generates a new key pair as specified by params, registers it as a signing key
+	// and returns its public key. It returns a *RequestError if params are invalid. The endpoints
+	// only sign with the key once it is configured in Keys and KeyUsages.
+	GenerateKey(params *KeyGenParams) (crypto.PublicKey, error)
+}
+
+// EphemeralSigner interface contains methods related to signing with one-time keys.
+type EphemeralSigner interface {
+	// SignEphemeral generates a one-time key pair in the HSM as specified by params, signs the digest
+	// with it and destroys it. It returns the signature and the public key of the key pair, whose
+	// private key never leaves the HSM.
+	SignEphemeral(params *EphemeralKeyParams, digest []byte, opts crypto.SignerOpts) (signature []byte, public crypto.PublicKey, err error)
+}
+
+// RequestError is returned by the KeyGenerator and the SessionReloader if the request is invalid, such as one
+// for an unknown key or slot, as opposed to an error of the HSM.
+type RequestError struct {
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+// ErrSerialReserved is returned by SerialStore.Reserve if the serial number is already reserved.
+var ErrSerialReserved = errors.New("serial number already reserved")
+
+// SerialStore interface contains methods related to reserving the serial numbers of x509 certificates
+// in a store shared by all crypki replicas, so that no two certificates of an issuer have the same serial.
+type SerialStore interface {
+	// Reserve atomically reserves the serial number for the certificates signed by the specified key.
+	// It returns ErrSerialReserved if the serial number is already reserved.
+	Reserve(keyIdentifier string, serial *big.Int) error
+}
+
+// IssuanceStore interface contains methods related to recording the issued certificates in a store, from
+// which they are tracked and revoked.
+type IssuanceStore interface {
+	// Record persists the encoded certificate of the type, such as "x509" or "ssh-user", signed by the
+	// specified key, indexed by its decimal serial number and the fingerprint returned along with it.
+	// It returns once the certificate is persisted.
+	Record(keyIdentifier, certType string, cert []byte, serial, fingerprint string) error
+}
+
+// IssuanceFinder interface contains methods related to looking up the certificates recorded in an IssuanceStore,
+// which are found by all the replicas sharing the store.
+type IssuanceFinder interface {
+	// Find returns the certificates signed by the specified key recorded in the store with the serial number
+	// and the fingerprint, compared case-insensitively. An empty serial or fingerprint matches any certificate,
+	// but not both.
+	Find(keyIdentifier, serial, fingerprint string) ([]*IssuedCert, error)
+}
+
+// IssuedCert represents a certificate recorded in an IssuanceStore.
+type IssuedCert struct {
+	// Type is the type of the certificate, such as "x509" or "ssh-user".
+	Type string
+	// Cert is the encoded certificate.
+	Cert []byte
+	// RecordedAt is the time the certificate was recorded.
+	RecordedAt time.Time
+}
+
+// PublicKeyGetter interface contains methods related to fetching the public keys of signing keys.
+type PublicKeyGetter interface {
+	// PublicKey returns the public key of the specified key.
+	PublicKey(keyIdentifier string) (crypto.PublicKey, error)
+}
+
+// PoolUsageGetter interface contains methods related to the utilization of the session pools of signing keys.
+type PoolUsageGetter interface {
+	// PoolUsage returns the number of sessions of the specified key in use and the size of its session pool.
+	PoolUsage(keyIdentifier string) (inUse, size int, err error)
+}
+
+// SessionReloader interface contains methods related to the recovery of the sessions of signing keys.
+type SessionReloader interface {
+	// ReloadSessions replaces the sessions of the specified key with freshly opened ones, and closes the
+	// previous ones once the signing requests using them are done. It returns the number of sessions opened,
+	// along with the error of ctx if it is done before the previous sessions are closed. It returns a
+	// *RequestError if the sessions of the key cannot be reloaded, such as those of an unknown key.
+	ReloadSessions(ctx context.Context, keyIdentifier string) (int, error)
+}
+
+// MechanismLister interface contains methods related to the capabilities of the HSMs.
+type MechanismLister interface {
+	// ListMechanisms returns the mechanisms supported by the slots of the HSMs holding the signing keys.
+	ListMechanisms() []SlotMechanisms
+}
+
+// SlotMechanisms represents the mechanisms supported by a slot of an HSM.
+type SlotMechanisms struct {
+	// Module is the name of the PKCS#11 module of the HSM, empty for the default module.
+	Module string
+	// SlotNumber is the slot number in HSM.
+	SlotNumber uint
+	// Mechanisms are the mechanisms supported by the slot.
+	Mechanisms []Mechanism
+	// Err is the error listing the mechanisms of the slot, if any.
+	Err error
+}
+
+// Mechanism represents a PKCS#11 mechanism supported by a slot.
+type Mechanism struct {
+	// Name is the name of the mechanism, such as CKM_RSA_PKCS, or its value in hexadecimal if unknown.
+	Name string
+	// Value is the value of the mechanism.
+	Value uint
+	// MinKeySize and MaxKeySize are the range of key sizes supported by the mechanism,
+	// in bits or bytes depending on the mechanism.
+	MinKeySize, MaxKeySize uint
+	// Functions are the functions the mechanism supports, such as sign or generate_key_pair.
+	Functions []string
+}
+
+// KeyGenParams represents the params for generating a new key pair.
+type KeyGenParams struct {
+	// Identifier is the unique name used to refer to the new key.
+	Identifier string
+	// Module is the name of the PKCS#11 module of the HSM. If empty, the default module is used.
+	Module string
+	// SlotNumber is the slot number in HSM.
+	SlotNumber uint
+	// KeyLabel is the label of the key pair on the slot.
+	KeyLabel string
+	// KeyType specifies the type of key, such as RSA or ECDSA.
+	KeyType PublicKeyAlgorithm
+	// KeySize is the modulus size in bits of an RSA key, or the size in bits of the curve of an ECDSA key.
+	KeySize int
+}
+
+// EphemeralKeyParams represents the params for generating a one-time key pair.
+type EphemeralKeyParams struct {
+	// Module is the name of the PKCS#11 module of the HSM. If empty, the default module is used.
+	Module string
+	// SlotNumber is the slot number in HSM.
+	SlotNumber uint
+	// KeyType specifies the type of key, RSA or Ed25519.
+	KeyType PublicKeyAlgorithm
+	// KeySize is the modulus size in bits of an RSA key.
+	KeySize int
+}
+
 // CAConfig represents the configuration params for generating the CA certificate.
 type CAConfig struct {
 	// Subject fields.
@@ -0,0 +1,226 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+// Package metrics instruments the requests of crypki and emits the metrics to a Sink.
+// By default the metrics are exposed in the OpenMetrics format by a PrometheusSink.
+//
+// The following metrics are emitted, all labeled by the short gRPC method name and the key identifier
+// in the request ("none" if the request has no key identifier, "unknown" if the identifier is not configured):
+//
+//	crypki_requests_total{method,identifier,code}        counter of handled requests by gRPC status code
+//	crypki_request_errors_total{method,identifier,code}  counter of requests that returned an error
+//	crypki_request_duration_seconds{method,identifier}   histogram of request latencies
+//	crypki_request_rejections_total{method,identifier,reason}
+//	                                                     counter of rejected requests by reason
+//
+// The rejections of the interceptors are observed by ObserveRejection, and those of the handlers by the
+// HandlerInterceptor from their status codes: bad requests, policy denials, unavailable keys or services,
+// quotas and HSM errors.
+//
+// The state of the circuit breaker of each key is emitted as a gauge labeled by the key identifier,
+// with the value 0 if closed, 1 if half-open and 2 if open:
+//
+//	crypki_circuit_breaker_state{identifier}
+//
+// The names passed to a Sink do not carry the "crypki_" prefix.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Names of the metrics emitted to a Sink.
+const (
+	MetricRequests            = "requests_total"
+	MetricRequestErrors       = "request_errors_total"
+	MetricRequestDuration     = "request_duration_seconds"
+	MetricRequestRejections   = "request_rejections_total"
+	MetricCircuitBreakerState = "circuit_breaker_state"
+)
+
+const (
+	// ReasonRateLimited is the rejection reason of requests exceeding a rate limit.
+	ReasonRateLimited = "rate_limited"
+	// ReasonQuotaExceeded is the rejection reason of requests exceeding a quota.
+	ReasonQuotaExceeded = "quota_exceeded"
+	// ReasonConcurrencyLimited is the rejection reason of requests exceeding the concurrency limit of their caller.
+	ReasonConcurrencyLimited = "concurrency_limited"
+	// ReasonNotReady is the rejection reason of signing requests received while the server is not ready.
+	ReasonNotReady = "not_ready"
+	// ReasonUnauthenticated is the rejection reason of requests without the caller identity they require.
+	ReasonUnauthenticated = "unauthenticated"
+	// ReasonPacingTimeout is the rejection reason of signing requests whose turn at the pace of their slot
+	// comes after their deadline.
+	ReasonPacingTimeout = "pacing_timeout"
+	// ReasonTooLarge is the rejection reason of requests larger than the maximum request size of their endpoint.
+	ReasonTooLarge = "too_large"
+	// ReasonSaturated is the rejection reason of requests that timed out, e.g. waiting for an HSM session.
+	ReasonSaturated = "saturated"
+	// ReasonBadRequest is the rejection reason of requests with an invalid or unknown argument.
+	ReasonBadRequest = "bad_request"
+	// ReasonPolicyDenied is the rejection reason of requests denied by the policy of their key or caller.
+	ReasonPolicyDenied = "policy_denied"
+	// ReasonUnavailable is the rejection reason of requests to a disabled endpoint, a key whose circuit breaker
+	// is open or a failed dependency, such as a webhook or a store.
+	ReasonUnavailable = "unavailable"
+	// ReasonHSMError is the rejection reason of requests failing with an internal error, mostly of the HSM.
+	ReasonHSMError = "hsm_error"
+
+	noIdentifier      = "none"
+	unknownIdentifier = "unknown"
+)
+
+// Sink receives the metrics emitted by crypki, e.g. to export them to Prometheus or StatsD.
+// Implementations must be safe for concurrent use and must not modify the labels.
+type Sink interface {
+	// IncrCounter increments the counter with the given name and labels.
+	IncrCounter(name string, labels map[string]string)
+	// ObserveDuration records a duration in the distribution with the given name and labels.
+	ObserveDuration(name string, labels map[string]string, d time.Duration)
+	// SetGauge sets the gauge with the given name and labels.
+	SetGauge(name string, labels map[string]string, value float64)
+}
+
+// NopSink is a Sink that drops all metrics.
+type NopSink struct{}
+
+// IncrCounter does nothing.
+func (NopSink) IncrCounter(name string, labels map[string]string) {}
+
+// ObserveDuration does nothing.
+func (NopSink) ObserveDuration(name string, labels map[string]string, d time.Duration) {}
+
+// SetGauge does nothing.
+func (NopSink) SetGauge(name string, labels map[string]string, value float64) {}
+
+// Metrics instruments the requests of crypki.
+type Metrics struct {
+	sink        Sink
+	identifiers map[string]bool
+}
+
+// New returns a Metrics emitting to a new PrometheusSink.
+// identifiers is the list of configured key identifiers; any other identifier in a
+// request is reported as "unknown" to bound the cardinality of the metrics.
+func New(identifiers []string) *Metrics {
+	return NewWithSink(identifiers, NewPrometheusSink())
+}
+
+// NewWithSink returns a Metrics emitting to sink.
+func NewWithSink(identifiers []string, sink Sink) *Metrics {
+	m := &Metrics{sink: sink, identifiers: make(map[string]bool)}
+	for _, id := range identifiers {
+		m.identifiers[id] = true
+	}
+	return m
+}
+
+// Handler returns an http.Handler serving the metrics if the sink is an http.Handler,
+// such as a PrometheusSink, or responding with 404 otherwise.
+func (m *Metrics) Handler() http.Handler {
+	if h, ok := m.sink.(http.Handler); ok {
+		return h
+	}
+	return http.NotFoundHandler()
+}
+
+// ObserveRejection records a request to method rejected for the given reason.
+func (m *Metrics) ObserveRejection(method string, req interface{}, reason string) {
+	m.sink.IncrCounter(MetricRequestRejections, map[string]string{
+		"method":     shortMethod(method),
+		"identifier": m.identifier(req),
+		"reason":     reason,
+	})
+}
+
+// SetCircuitBreakerState records the state of the circuit breaker of the key:
+// 0 if closed, 1 if half-open and 2 if open.
+func (m *Metrics) SetCircuitBreakerState(identifier string, state int) {
+	if !m.identifiers[identifier] {
+		identifier = unknownIdentifier
+	}
+	m.sink.SetGauge(MetricCircuitBreakerState, map[string]string{"identifier": identifier}, float64(state))
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor recording the rate,
+// errors and duration of the requests.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		method, id, code := shortMethod(info.FullMethod), m.identifier(req), status.Code(err)
+		m.sink.ObserveDuration(MetricRequestDuration, map[string]string{"method": method, "identifier": id}, time.Since(start))
+		labels := map[string]string{"method": method, "identifier": id, "code": code.String()}
+		m.sink.IncrCounter(MetricRequests, labels)
+		if code != codes.OK {
+			m.sink.IncrCounter(MetricRequestErrors, labels)
+		}
+		return resp, err
+	}
+}
+
+// HandlerInterceptor returns a grpc.UnaryServerInterceptor recording the rejections of the handler by the
+// reason of their status code. It must be chained after the interceptors rejecting requests, so that their
+// rejections are not recorded twice. The errors of the requests that already timed out or were canceled
+// are not recorded.
+func (m *Metrics) HandlerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil && ctx.Err() == nil {
+			if reason := rejectionReason(status.Code(err)); reason != "" {
+				m.ObserveRejection(info.FullMethod, req, reason)
+			}
+		}
+		return resp, err
+	}
+}
+
+// rejectionReason returns the rejection reason of a request failing with the status code, or an empty
+// string if the code is not a rejection.
+func rejectionReason(code codes.Code) string {
+	switch code {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.OutOfRange, codes.FailedPrecondition, codes.Unimplemented:
+		return ReasonBadRequest
+	case codes.PermissionDenied, codes.Unauthenticated:
+		return ReasonPolicyDenied
+	case codes.ResourceExhausted:
+		return ReasonQuotaExceeded
+	case codes.Unavailable:
+		return ReasonUnavailable
+	case codes.DeadlineExceeded:
+		return ReasonSaturated
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		return ReasonHSMError
+	}
+	return ""
+}
+
+// identifier returns the key identifier label of req.
+func (m *Metrics) identifier(req interface{}) string {
+	var id string
+	switch r := req.(type) {
+	case *proto.KeyMeta:
+		id = r.GetIdentifier()
+	case interface{ GetKeyMeta() *proto.KeyMeta }:
+		id = r.GetKeyMeta().GetIdentifier()
+	default:
+		return noIdentifier
+	}
+	if !m.identifiers[id] {
+		return unknownIdentifier
+	}
+	return id
+}
+
+// shortMethod returns the method name of a full gRPC method name such as "/v3.Signing/PostSignBlob".
+func shortMethod(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
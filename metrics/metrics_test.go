@@ -0,0 +1,287 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package metrics
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yahoo/crypki/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricNamesAndLabels(t *testing.T) {
+	t.Parallel()
+	expected := map[string][]string{
+		"crypki_requests_total":           {"code", "identifier", "method"},
+		"crypki_request_errors_total":     {"code", "identifier", "method"},
+		"crypki_request_duration_seconds": {"identifier", "method"},
+		"crypki_request_rejections_total": {"identifier", "method", "reason"},
+		"crypki_circuit_breaker_state":    {"identifier"},
+	}
+
+	m := New([]string{"key1"})
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+	failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+	req := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "key1"}}
+	if _, err := interceptor(context.Background(), req, info, failing); err == nil {
+		t.Fatal("expected error from interceptor, got nil")
+	}
+	m.ObserveRejection(info.FullMethod, req, ReasonRateLimited)
+	m.SetCircuitBreakerState("key1", 2)
+
+	families, err := m.sink.(*PrometheusSink).registry.Gather()
+	if err != nil {
+		t.Fatalf("unable to gather metrics: %v", err)
+	}
+	got := make(map[string][]string)
+	for _, mf := range families {
+		for _, metric := range mf.GetMetric() {
+			var labels []string
+			for _, lp := range metric.GetLabel() {
+				labels = append(labels, lp.GetName())
+			}
+			sort.Strings(labels)
+			got[mf.GetName()] = labels
+		}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("metrics mismatch, got: %v, want: %v", got, expected)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	m := New([]string{"key1"})
+	interceptor := m.UnaryServerInterceptor()
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	testcases := map[string]struct {
+		req        interface{}
+		fullMethod string
+		labels     []string
+	}{
+		"key-meta":           {&proto.KeyMeta{Identifier: "key1"}, "/v3.Signing/GetBlobSigningKey", []string{"GetBlobSigningKey", "key1", "OK"}},
+		"request-key-meta":   {&proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "key1"}}, "/v3.Signing/PostSignBlob", []string{"PostSignBlob", "key1", "OK"}},
+		"unknown-identifier": {&proto.KeyMeta{Identifier: "random"}, "/v3.Signing/GetX509CACertificate", []string{"GetX509CACertificate", "unknown", "OK"}},
+		"no-identifier":      {&proto.X509CertificateSigningRequest{}, "/v3.Signing/PostX509Certificate", []string{"PostX509Certificate", "unknown", "OK"}},
+		"no-key-meta":        {&proto.EndpointStatus{}, "/v3.Admin/SetEndpointStatus", []string{"SetEndpointStatus", "none", "OK"}},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			if _, err := interceptor(context.Background(), tt.req, &grpc.UnaryServerInfo{FullMethod: tt.fullMethod}, ok); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := testutil.ToFloat64(m.sink.(*PrometheusSink).counters[MetricRequests].WithLabelValues(tt.labels...)); got != 1 {
+				t.Errorf("got %v requests with labels %v, want 1", got, tt.labels)
+			}
+		})
+	}
+}
+
+func TestHandlerInterceptor(t *testing.T) {
+	t.Parallel()
+	m := New([]string{"key1"})
+	interceptor := m.HandlerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+	req := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "key1"}}
+	codesByReason := map[string][]codes.Code{
+		ReasonBadRequest:    {codes.InvalidArgument, codes.NotFound},
+		ReasonPolicyDenied:  {codes.PermissionDenied},
+		ReasonQuotaExceeded: {codes.ResourceExhausted},
+		ReasonUnavailable:   {codes.Unavailable},
+		ReasonSaturated:     {codes.DeadlineExceeded},
+		ReasonHSMError:      {codes.Internal},
+		"":                  {codes.OK, codes.Canceled},
+	}
+	// The rejections are recorded concurrently.
+	const times = 50
+	var wg sync.WaitGroup
+	for _, cs := range codesByReason {
+		for _, code := range cs {
+			for i := 0; i < times; i++ {
+				wg.Add(1)
+				go func(code codes.Code) {
+					defer wg.Done()
+					handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+						return nil, status.Error(code, code.String())
+					}
+					interceptor(context.Background(), req, info, handler)
+				}(code)
+			}
+		}
+	}
+	wg.Wait()
+
+	counter := m.sink.(*PrometheusSink).counters[MetricRequestRejections]
+	for reason, cs := range codesByReason {
+		if reason == "" {
+			continue
+		}
+		if got, want := testutil.ToFloat64(counter.WithLabelValues("PostSignBlob", "key1", reason)), float64(times*len(cs)); got != want {
+			t.Errorf("got %v rejections with reason %q, want %v", got, reason, want)
+		}
+	}
+	if got, want := testutil.CollectAndCount(counter), len(codesByReason)-1; got != want {
+		t.Errorf("got %d rejection reasons, want %d", got, want)
+	}
+
+	// The errors of the requests that already timed out are recorded by the timeout interceptor.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+	interceptor(ctx, req, info, failing)
+	if got := testutil.ToFloat64(counter.WithLabelValues("PostSignBlob", "key1", ReasonHSMError)); got != times {
+		t.Errorf("got %v rejections with reason %q after a canceled request, want %v", got, ReasonHSMError, times)
+	}
+}
+
+func TestHandlerOpenMetrics(t *testing.T) {
+	t.Parallel()
+	m := New(nil)
+	m.ObserveRejection("/v3.Signing/PostSignBlob", nil, ReasonQuotaExceeded)
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=0.0.1")
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, req)
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Fatalf("unexpected content type: %q", ct)
+	}
+	body, err := ioutil.ReadAll(w.Body)
+	if err != nil {
+		t.Fatalf("unable to read response: %v", err)
+	}
+	want := `crypki_request_rejections_total{identifier="none",method="PostSignBlob",reason="quota_exceeded"} 1.0`
+	if !strings.Contains(string(body), want) {
+		t.Errorf("metric %q not found in response:\n%s", want, body)
+	}
+	if !strings.HasSuffix(string(body), "# EOF\n") {
+		t.Error("response is not terminated by # EOF")
+	}
+}
+
+func TestSetCircuitBreakerState(t *testing.T) {
+	t.Parallel()
+	m := New([]string{"key1"})
+	m.SetCircuitBreakerState("key1", 2)
+	m.SetCircuitBreakerState("random", 1)
+	if got := testutil.ToFloat64(m.sink.(*PrometheusSink).gauges[MetricCircuitBreakerState].WithLabelValues("key1")); got != 2 {
+		t.Errorf("got state %v for key1, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.sink.(*PrometheusSink).gauges[MetricCircuitBreakerState].WithLabelValues(unknownIdentifier)); got != 1 {
+		t.Errorf("got state %v for unknown identifier, want 1", got)
+	}
+}
+
+// fakeSink records the metrics emitted to it.
+type fakeSink struct {
+	counters  map[string][]map[string]string
+	durations map[string][]map[string]string
+	gauges    map[string]float64
+}
+
+func newFakeSink() *fakeSink {
+	return &fakeSink{
+		counters:  make(map[string][]map[string]string),
+		durations: make(map[string][]map[string]string),
+		gauges:    make(map[string]float64),
+	}
+}
+
+func (f *fakeSink) IncrCounter(name string, labels map[string]string) {
+	f.counters[name] = append(f.counters[name], labels)
+}
+
+func (f *fakeSink) ObserveDuration(name string, labels map[string]string, d time.Duration) {
+	f.durations[name] = append(f.durations[name], labels)
+}
+
+func (f *fakeSink) SetGauge(name string, labels map[string]string, value float64) {
+	f.gauges[name+"/"+labels["identifier"]] = value
+}
+
+func TestSink(t *testing.T) {
+	t.Parallel()
+	sink := newFakeSink()
+	m := NewWithSink([]string{"key1"}, sink)
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+	req := &proto.BlobSigningRequest{KeyMeta: &proto.KeyMeta{Identifier: "key1"}}
+	postSignBlob := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &proto.Signature{Signature: "c2lnbmF0dXJl"}, nil
+	}
+	failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+	if _, err := m.UnaryServerInterceptor()(context.Background(), req, info, postSignBlob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := m.UnaryServerInterceptor()(context.Background(), req, info, failing); err == nil {
+		t.Fatal("expected error from interceptor, got nil")
+	}
+	m.ObserveRejection(info.FullMethod, req, ReasonRateLimited)
+	m.SetCircuitBreakerState("key1", 2)
+
+	wantCounters := map[string][]map[string]string{
+		MetricRequests: {
+			{"method": "PostSignBlob", "identifier": "key1", "code": "OK"},
+			{"method": "PostSignBlob", "identifier": "key1", "code": "Internal"},
+		},
+		MetricRequestErrors: {
+			{"method": "PostSignBlob", "identifier": "key1", "code": "Internal"},
+		},
+		MetricRequestRejections: {
+			{"method": "PostSignBlob", "identifier": "key1", "reason": ReasonRateLimited},
+		},
+	}
+	if !reflect.DeepEqual(sink.counters, wantCounters) {
+		t.Errorf("got counters %v, want %v", sink.counters, wantCounters)
+	}
+	wantDurations := map[string][]map[string]string{
+		MetricRequestDuration: {
+			{"method": "PostSignBlob", "identifier": "key1"},
+			{"method": "PostSignBlob", "identifier": "key1"},
+		},
+	}
+	if !reflect.DeepEqual(sink.durations, wantDurations) {
+		t.Errorf("got durations %v, want %v", sink.durations, wantDurations)
+	}
+	if got := sink.gauges[MetricCircuitBreakerState+"/key1"]; got != 2 {
+		t.Errorf("got circuit breaker state %v, want 2", got)
+	}
+}
+
+func TestNopSink(t *testing.T) {
+	t.Parallel()
+	m := NewWithSink(nil, NopSink{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/v3.Signing/PostSignBlob"}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	if _, err := m.UnaryServerInterceptor()(context.Background(), nil, info, ok); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "crypki"
+
+// PrometheusSink is a Sink exposing the metrics of crypki in the Prometheus and OpenMetrics formats.
+// Metrics with names other than the Metric* constants are dropped.
+type PrometheusSink struct {
+	registry   *prometheus.Registry
+	counters   map[string]*prometheus.CounterVec
+	histograms map[string]*prometheus.HistogramVec
+	gauges     map[string]*prometheus.GaugeVec
+}
+
+// NewPrometheusSink returns a PrometheusSink with its collectors registered in a new registry.
+func NewPrometheusSink() *PrometheusSink {
+	p := &PrometheusSink{
+		registry: prometheus.NewRegistry(),
+		counters: map[string]*prometheus.CounterVec{
+			MetricRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      MetricRequests,
+				Help:      "Total number of handled requests.",
+			}, []string{"method", "identifier", "code"}),
+			MetricRequestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      MetricRequestErrors,
+				Help:      "Total number of requests that returned an error.",
+			}, []string{"method", "identifier", "code"}),
+			MetricRequestRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      MetricRequestRejections,
+				Help:      "Total number of rejected requests by reason.",
+			}, []string{"method", "identifier", "reason"}),
+		},
+		histograms: map[string]*prometheus.HistogramVec{
+			MetricRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      MetricRequestDuration,
+				Help:      "Latency of handled requests in seconds.",
+				Buckets:   prometheus.DefBuckets,
+			}, []string{"method", "identifier"}),
+		},
+		gauges: map[string]*prometheus.GaugeVec{
+			MetricCircuitBreakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      MetricCircuitBreakerState,
+				Help:      "State of the circuit breaker of a key: 0 if closed, 1 if half-open, 2 if open.",
+			}, []string{"identifier"}),
+		},
+	}
+	for _, c := range p.counters {
+		p.registry.MustRegister(c)
+	}
+	for _, h := range p.histograms {
+		p.registry.MustRegister(h)
+	}
+	for _, g := range p.gauges {
+		p.registry.MustRegister(g)
+	}
+	return p
+}
+
+// IncrCounter increments the counter with the given name and labels.
+func (p *PrometheusSink) IncrCounter(name string, labels map[string]string) {
+	if c, ok := p.counters[name]; ok {
+		c.With(labels).Inc()
+	}
+}
+
+// ObserveDuration records a duration in the histogram with the given name and labels.
+func (p *PrometheusSink) ObserveDuration(name string, labels map[string]string, d time.Duration) {
+	if h, ok := p.histograms[name]; ok {
+		h.With(labels).Observe(d.Seconds())
+	}
+}
+
+// SetGauge sets the gauge with the given name and labels.
+func (p *PrometheusSink) SetGauge(name string, labels map[string]string, value float64) {
+	if g, ok := p.gauges[name]; ok {
+		g.With(labels).Set(value)
+	}
+}
+
+// ServeHTTP serves the metrics, in the OpenMetrics format if requested by the scraper.
+func (p *PrometheusSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{EnableOpenMetrics: true}).ServeHTTP(w, r)
+}
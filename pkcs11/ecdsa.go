@@ -1,18 +1,151 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
 package pkcs11
 
 import (
+	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
 	"errors"
+	"fmt"
+	"math/big"
 
 	p11 "github.com/miekg/pkcs11"
 )
 
+// ecCurves maps the named curve OIDs of the CKA_EC_PARAMS of the ECDSA keys to their curves.
+var ecCurves = []struct {
+	oid   asn1.ObjectIdentifier
+	curve elliptic.Curve
+}{
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}, elliptic.P256()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 34}, elliptic.P384()},
+	{asn1.ObjectIdentifier{1, 3, 132, 0, 35}, elliptic.P521()},
+}
+
+// ecParams returns the DER encoded CKA_EC_PARAMS of the named curve of the size in bits: 256, 384 or 521.
+func ecParams(bitSize int) ([]byte, error) {
+	for _, c := range ecCurves {
+		if c.curve.Params().BitSize == bitSize {
+			return asn1.Marshal(c.oid)
+		}
+	}
+	return nil, fmt.Errorf("unsupported ECDSA key size %d, it must be 256, 384 or 521", bitSize)
+}
+
 func publicECDSA(s *p11Signer) crypto.PublicKey {
-	// TODO: implement
-	panic("not implemented yet")
+	attrTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_EC_PARAMS, nil),
+		p11.NewAttribute(p11.CKA_EC_POINT, nil),
+	}
+	attr, err := s.context.GetAttributeValue(s.session, s.publicKey, attrTemplate)
+	if err != nil {
+		panic("Error returning public key: " + err.Error())
+	}
+	var params, point []byte
+	for _, a := range attr {
+		switch a.Type {
+		case p11.CKA_EC_PARAMS:
+			params = a.Value
+		case p11.CKA_EC_POINT:
+			point = a.Value
+		}
+	}
+	if params == nil || point == nil {
+		panic("unable to retrieve EC params and/or EC point")
+	}
+	curve, err := ecCurve(params)
+	if err != nil {
+		panic(err.Error())
+	}
+	// CKA_EC_POINT is a DER encoded octet string, though some HSMs return the raw uncompressed point.
+	if size := 1 + 2*((curve.Params().BitSize+7)/8); len(point) != size {
+		if _, err := asn1.Unmarshal(point, &point); err != nil {
+			panic("unable to parse EC point: " + err.Error())
+		}
+	}
+	pub, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+	if err != nil {
+		panic("unable to parse EC point: " + err.Error())
+	}
+	return pub
 }
 
-func signDataECDSA(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.ObjectHandle, data []byte, opts crypto.SignerOpts) ([]byte, error) {
-	// TODO: implement
-	return nil, errors.New("not implemented yet")
+// ecCurve returns the curve of the DER encoded CKA_EC_PARAMS, which must be a named curve.
+func ecCurve(params []byte) (elliptic.Curve, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, fmt.Errorf("unable to parse EC params: %v", err)
+	}
+	for _, c := range ecCurves {
+		if c.oid.Equal(oid) {
+			return c.curve, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported EC curve %v", oid)
+}
+
+// signDataECDSA signs the digest with the mechanism, CKM_ECDSA if 0, which returns the concatenation of r and s,
+// and returns the ASN.1 encoded signature as crypto/ecdsa does.
+func signDataECDSA(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.ObjectHandle, mechanism uint, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash := opts.HashFunc()
+	switch hash {
+	case crypto.SHA1, crypto.SHA224, crypto.SHA256, crypto.SHA384, crypto.SHA512, crypto.SHA3_256, crypto.SHA3_384, crypto.SHA3_512:
+	default:
+		return nil, errors.New("Unsupported hash algorithm")
+	}
+	if len(data) != hash.Size() {
+		return nil, fmt.Errorf("invalid digest length: got %d bytes, want %d", len(data), hash.Size())
+	}
+	if mechanism == 0 {
+		mechanism = p11.CKM_ECDSA
+	}
+	if err := ctx.SignInit(session, []*p11.Mechanism{p11.NewMechanism(mechanism, nil)}, hsmPrivateObject); err != nil {
+		return nil, err
+	}
+	signed, err := ctx.Sign(session, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(signed) == 0 || len(signed)%2 != 0 {
+		return nil, fmt.Errorf("invalid ECDSA signature length: %d", len(signed))
+	}
+	n := len(signed) / 2
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		new(big.Int).SetBytes(signed[:n]),
+		new(big.Int).SetBytes(signed[n:]),
+	})
+}
+
+// deterministicCheckDigest is the digest signed twice by the keys configured with deterministic signatures
+// when they are loaded.
+var deterministicCheckDigest = sha256.Sum256([]byte("crypki deterministic signature check"))
+
+// checkDeterministic returns an error unless the signer signs the same digest twice with the same signature,
+// which verifies with its public key.
+func checkDeterministic(signer crypto.Signer) error {
+	var signatures [2][]byte
+	for i := range signatures {
+		var err error
+		if signatures[i], err = signer.Sign(rand.Reader, deterministicCheckDigest[:], crypto.SHA256); err != nil {
+			return fmt.Errorf("unable to sign: %v", err)
+		}
+	}
+	if !bytes.Equal(signatures[0], signatures[1]) {
+		return errors.New("two signatures of the same digest differ")
+	}
+	public, err := publicKey(signer)
+	if err != nil {
+		return err
+	}
+	pub, ok := public.(*ecdsa.PublicKey)
+	if !ok || !ecdsa.VerifyASN1(pub, deterministicCheckDigest[:], signatures[0]) {
+		return errors.New("signature does not verify with the public key")
+	}
+	return nil
 }
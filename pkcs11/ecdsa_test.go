@@ -0,0 +1,164 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+func TestSignECDSA(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	sha256Digest := sha256.Sum256([]byte("good"))
+	sha384Digest := sha512.Sum384([]byte("good"))
+
+	testcases := map[string]struct {
+		data        []byte
+		opts        crypto.SignerOpts
+		signInitErr error
+		expectError bool
+	}{
+		"good_SHA256": {
+			data: sha256Digest[:],
+			opts: crypto.SHA256,
+		},
+		"good_SHA384": {
+			data: sha384Digest[:],
+			opts: crypto.SHA384,
+		},
+		"bad_no_hash": {
+			data:        []byte("good"),
+			opts:        crypto.Hash(0),
+			expectError: true,
+		},
+		"bad_hash_MD5": {
+			data:        sha256Digest[:16],
+			opts:        crypto.MD5,
+			expectError: true,
+		},
+		"bad_digest_length": {
+			data:        sha256Digest[:],
+			opts:        crypto.SHA384,
+			expectError: true,
+		},
+		"bad_SignInit": {
+			data:        sha256Digest[:],
+			opts:        crypto.SHA256,
+			signInitErr: errors.New("SignInit err"),
+			expectError: true,
+		},
+	}
+
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			signer := &p11Signer{mockCtx, 0, 0, 0, crypki.ECDSA, 0}
+			mockCtx.EXPECT().
+				SignInit(gomock.Any(), []*p11.Mechanism{p11.NewMechanism(p11.CKM_ECDSA, nil)}, gomock.Any()).
+				Return(tt.signInitErr).
+				AnyTimes()
+			// CKM_ECDSA returns the concatenation of r and s, each of the size of the order of the curve.
+			mockCtx.EXPECT().
+				Sign(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, digest []byte) ([]byte, error) {
+					r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+					if err != nil {
+						return nil, err
+					}
+					signed := make([]byte, 96)
+					r.FillBytes(signed[:48])
+					s.FillBytes(signed[48:])
+					return signed, nil
+				}).
+				AnyTimes()
+
+			got, err := signer.Sign(rand.Reader, tt.data, tt.opts)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, but got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ecdsa.VerifyASN1(&key.PublicKey, tt.data, got) {
+				t.Error("signature does not verify with the public key")
+			}
+		})
+	}
+}
+
+func TestPublicECDSA(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	point, err := key.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to encode EC point: %v", err)
+	}
+	derPoint, err := asn1.Marshal(point)
+	if err != nil {
+		t.Fatalf("Failed to encode EC point: %v", err)
+	}
+	p256Params, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+	unknownParams, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+
+	testcases := map[string]struct {
+		params      []byte
+		point       []byte
+		expectPanic bool
+	}{
+		"good_DER_point": {params: p256Params, point: derPoint},
+		"good_raw_point": {params: p256Params, point: point},
+		"bad_curve":      {params: unknownParams, point: derPoint, expectPanic: true},
+		"bad_point":      {params: p256Params, point: []byte{0x04, 0x01}, expectPanic: true},
+	}
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if r := recover(); (r != nil) != tt.expectPanic {
+					t.Errorf("got panic %v, want panic: %v", r, tt.expectPanic)
+				}
+			}()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			mockCtx.EXPECT().GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]*p11.Attribute{
+					p11.NewAttribute(p11.CKA_EC_PARAMS, tt.params),
+					p11.NewAttribute(p11.CKA_EC_POINT, tt.point),
+				}, nil)
+			signer := &p11Signer{mockCtx, 0, 0, 0, crypki.ECDSA, 0}
+			got, ok := signer.Public().(*ecdsa.PublicKey)
+			if !ok || !got.Equal(&key.PublicKey) {
+				t.Errorf("got public key %v, want %v", got, key.PublicKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	p11 "github.com/miekg/pkcs11"
+)
+
+// #include <stdlib.h>
+import "C"
+
+// ckmEdDSA is the CKM_EDDSA mechanism of PKCS#11 v3.0, which is not defined by the pkcs11 package.
+const ckmEdDSA = 0x1057
+
+// maxEd25519ContextSize is the maximum size in bytes of an Ed25519 context as per RFC 8032.
+const maxEd25519ContextSize = 255
+
+// eddsaParams mirrors CK_EDDSA_PARAMS. The parameters are copied to C memory by the pkcs11 package,
+// so the context data must be allocated in C memory as well.
+type eddsaParams struct {
+	phFlag         byte
+	contextDataLen uint
+	contextData    unsafe.Pointer
+}
+
+func publicEd25519(s *p11Signer) crypto.PublicKey {
+	attrTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_EC_POINT, nil),
+	}
+	attr, err := s.context.GetAttributeValue(s.session, s.publicKey, attrTemplate)
+	if err != nil {
+		panic("Error returning public key: " + err.Error())
+	}
+	for _, a := range attr {
+		if a.Type != p11.CKA_EC_POINT {
+			continue
+		}
+		// CKA_EC_POINT is a DER encoded octet string, though some HSMs return the raw public key.
+		point := a.Value
+		if len(point) != ed25519.PublicKeySize {
+			if _, err := asn1.Unmarshal(a.Value, &point); err != nil {
+				panic("unable to parse EC point: " + err.Error())
+			}
+		}
+		if len(point) != ed25519.PublicKeySize {
+			panic(fmt.Sprintf("invalid Ed25519 public key size: %d", len(point)))
+		}
+		return ed25519.PublicKey(point)
+	}
+	panic("unable to retrieve EC point")
+}
+
+// signDataEd25519 signs the message with pure Ed25519 if opts has no hash function, or
+// the SHA512 digest with Ed25519ph if the hash function is SHA512, as crypto/ed25519 does.
+// The context of ed25519.Options, if any, is passed in the CK_EDDSA_PARAMS, which turns
+// pure Ed25519 into Ed25519ctx.
+func signDataEd25519(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.ObjectHandle, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var context string
+	if o, ok := opts.(*ed25519.Options); ok {
+		if len(o.Context) > maxEd25519ContextSize {
+			return nil, fmt.Errorf("Ed25519 context longer than %d bytes", maxEd25519ContextSize)
+		}
+		context = o.Context
+	}
+	var params *eddsaParams
+	switch opts.HashFunc() {
+	case crypto.Hash(0):
+		if context != "" {
+			params = &eddsaParams{}
+		}
+	case crypto.SHA512:
+		if len(data) != sha512.Size {
+			return nil, fmt.Errorf("invalid Ed25519ph digest length: got %d bytes, want %d", len(data), sha512.Size)
+		}
+		params = &eddsaParams{phFlag: 1}
+	default:
+		return nil, errors.New("Unsupported hash algorithm")
+	}
+	mech := p11.NewMechanism(ckmEdDSA, nil)
+	if params != nil {
+		if context != "" {
+			params.contextDataLen = uint(len(context))
+			params.contextData = C.CBytes([]byte(context))
+			defer C.free(params.contextData)
+		}
+		mech = p11.NewMechanism(ckmEdDSA, (*[unsafe.Sizeof(*params)]byte)(unsafe.Pointer(params))[:])
+	}
+	if err := ctx.SignInit(session, []*p11.Mechanism{mech}, hsmPrivateObject); err != nil {
+		return nil, err
+	}
+	return ctx.Sign(session, data)
+}
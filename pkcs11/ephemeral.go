@@ -0,0 +1,114 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+)
+
+// ckkECEdwards is the CKK_EC_EDWARDS key type of PKCS#11 v3.0, which is not defined by the pkcs11 package.
+const ckkECEdwards = 0x40
+
+// ed25519ECParams is the DER encoded id-Ed25519 OID of RFC 8410, the CKA_EC_PARAMS of Ed25519 keys.
+var ed25519ECParams = []byte{0x06, 0x03, 0x2b, 0x65, 0x70}
+
+// SignEphemeral generates a one-time key pair in the HSM, signs the digest with it and destroys it.
+// The key pair is made of session objects, which the HSM also destroys when the session is closed.
+func (s *signer) SignEphemeral(params *crypki.EphemeralKeyParams, digest []byte, opts crypto.SignerOpts) (signature []byte, public crypto.PublicKey, err error) {
+	var keyGen uint
+	switch params.KeyType {
+	case crypki.RSA:
+		if params.KeySize < minRSAKeySize {
+			return nil, nil, fmt.Errorf("RSA key size %d is less than the minimum size %d", params.KeySize, minRSAKeySize)
+		}
+		keyGen = p11.CKM_RSA_PKCS_KEY_PAIR_GEN
+	case crypki.Ed25519:
+		keyGen = ckmECEdwardsKeyPairGen
+	default:
+		return nil, nil, fmt.Errorf("unsupported ephemeral key type: %d", params.KeyType)
+	}
+	m, ok := s.modules[params.Module]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown PKCS#11 module %q", params.Module)
+	}
+	pin, ok := m.slotPins[params.SlotNumber]
+	if !ok {
+		return nil, nil, fmt.Errorf("slot %d has no configured key", params.SlotNumber)
+	}
+	if err := checkMechanism(m.context, params.SlotNumber, keyGen); err != nil {
+		return nil, nil, err
+	}
+
+	session, err := m.context.OpenSession(params.SlotNumber, p11.CKF_SERIAL_SESSION|p11.CKF_RW_SESSION)
+	if err != nil {
+		return nil, nil, errors.New("SignEphemeral: error in OpenSession: " + err.Error())
+	}
+	defer m.context.CloseSession(session)
+	if err := loginUser(m.context, session, pin); err != nil {
+		return nil, nil, errors.New("SignEphemeral: error in Login: " + err.Error())
+	}
+
+	publicTemplate, privateTemplate := ephemeralKeyTemplates(params)
+	publicKey, privateKey, err := m.context.GenerateKeyPair(session, []*p11.Mechanism{p11.NewMechanism(keyGen, nil)}, publicTemplate, privateTemplate)
+	if err != nil {
+		return nil, nil, errors.New("SignEphemeral: error in GenerateKeyPair: " + err.Error())
+	}
+	defer func() {
+		for _, obj := range []p11.ObjectHandle{privateKey, publicKey} {
+			if err := m.context.DestroyObject(session, obj); err != nil {
+				log.Printf("SignEphemeral: unable to destroy ephemeral key object %d, left for the session to close: %v", obj, err)
+			}
+		}
+	}()
+
+	// PKCS#11 signing errors are raised as panics by the p11Signer.
+	defer func() {
+		if r := recover(); r != nil {
+			signature, public, err = nil, nil, fmt.Errorf("failed to sign with ephemeral key: %v", r)
+		}
+	}()
+	signer := &p11Signer{context: m.context, session: session, privateKey: privateKey, publicKey: publicKey, keyType: params.KeyType}
+	signature, err = signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signature, signer.Public(), nil
+}
+
+// ephemeralKeyTemplates returns the templates of the public and private session objects of a one-time key pair.
+func ephemeralKeyTemplates(params *crypki.EphemeralKeyParams) (public, private []*p11.Attribute) {
+	public = []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_PUBLIC_KEY),
+		p11.NewAttribute(p11.CKA_TOKEN, false),
+		p11.NewAttribute(p11.CKA_VERIFY, true),
+	}
+	private = []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_PRIVATE_KEY),
+		p11.NewAttribute(p11.CKA_TOKEN, false),
+		p11.NewAttribute(p11.CKA_PRIVATE, true),
+		p11.NewAttribute(p11.CKA_SIGN, true),
+		p11.NewAttribute(p11.CKA_SENSITIVE, true),
+		p11.NewAttribute(p11.CKA_EXTRACTABLE, false),
+	}
+	if params.KeyType == crypki.Ed25519 {
+		public = append(public,
+			p11.NewAttribute(p11.CKA_KEY_TYPE, ckkECEdwards),
+			p11.NewAttribute(p11.CKA_EC_PARAMS, ed25519ECParams))
+		private = append(private, p11.NewAttribute(p11.CKA_KEY_TYPE, ckkECEdwards))
+		return public, private
+	}
+	public = append(public,
+		p11.NewAttribute(p11.CKA_KEY_TYPE, p11.CKK_RSA),
+		p11.NewAttribute(p11.CKA_MODULUS_BITS, params.KeySize),
+		p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, rsaPublicExponent))
+	private = append(private, p11.NewAttribute(p11.CKA_KEY_TYPE, p11.CKK_RSA))
+	return public, private
+}
@@ -0,0 +1,185 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+func TestSignEphemeral(t *testing.T) {
+	t.Parallel()
+	digest := sha256.Sum256([]byte("ephemeral"))
+	mechanisms := []*p11.Mechanism{p11.NewMechanism(p11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil), p11.NewMechanism(ckmECEdwardsKeyPairGen, nil)}
+
+	testcases := map[string]struct {
+		params         *crypki.EphemeralKeyParams
+		opts           crypto.SignerOpts
+		mechanisms     []*p11.Mechanism
+		errMsg         map[string]error
+		expectError    bool
+		expectGenCall  bool
+		expectDestroys int
+	}{
+		"good-rsa": {
+			params:         &crypki.EphemeralKeyParams{SlotNumber: 1, KeyType: crypki.RSA, KeySize: 2048},
+			opts:           crypto.SHA256,
+			mechanisms:     mechanisms,
+			expectGenCall:  true,
+			expectDestroys: 2,
+		},
+		"good-ed25519": {
+			params:         &crypki.EphemeralKeyParams{SlotNumber: 1, KeyType: crypki.Ed25519},
+			opts:           crypto.Hash(0),
+			mechanisms:     mechanisms,
+			expectGenCall:  true,
+			expectDestroys: 2,
+		},
+		"bad-ecdsa": {
+			params:      &crypki.EphemeralKeyParams{SlotNumber: 1, KeyType: crypki.ECDSA},
+			opts:        crypto.SHA256,
+			mechanisms:  mechanisms,
+			expectError: true,
+		},
+		"bad-small-key-size": {
+			params:      &crypki.EphemeralKeyParams{SlotNumber: 1, KeyType: crypki.RSA, KeySize: 1024},
+			opts:        crypto.SHA256,
+			mechanisms:  mechanisms,
+			expectError: true,
+		},
+		"bad-unknown-slot": {
+			params:      &crypki.EphemeralKeyParams{SlotNumber: 2, KeyType: crypki.RSA, KeySize: 2048},
+			opts:        crypto.SHA256,
+			mechanisms:  mechanisms,
+			expectError: true,
+		},
+		"bad-unsupported-mechanism": {
+			params:      &crypki.EphemeralKeyParams{SlotNumber: 1, KeyType: crypki.Ed25519},
+			opts:        crypto.Hash(0),
+			mechanisms:  mechanisms[:1],
+			expectError: true,
+		},
+		"bad-GenerateKeyPair": {
+			params:        &crypki.EphemeralKeyParams{SlotNumber: 1, KeyType: crypki.RSA, KeySize: 2048},
+			opts:          crypto.SHA256,
+			mechanisms:    mechanisms,
+			errMsg:        map[string]error{"GenerateKeyPair": errors.New("CKR_DEVICE_ERROR")},
+			expectError:   true,
+			expectGenCall: true,
+		},
+		"bad-Sign": {
+			params:         &crypki.EphemeralKeyParams{SlotNumber: 1, KeyType: crypki.RSA, KeySize: 2048},
+			opts:           crypto.SHA256,
+			mechanisms:     mechanisms,
+			errMsg:         map[string]error{"Sign": errors.New("CKR_DEVICE_ERROR")},
+			expectError:    true,
+			expectGenCall:  true,
+			expectDestroys: 2,
+		},
+	}
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+
+			// The mock HSM holds a key pair generated in software, of the requested type.
+			rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				t.Fatalf("unable to generate RSA key: %v", err)
+			}
+			edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatalf("unable to generate Ed25519 key: %v", err)
+			}
+			ecPoint, _ := asn1.Marshal([]byte(edPub))
+
+			record := &keyGenRecord{}
+			destroyed := map[p11.ObjectHandle]bool{}
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			mockCtx.EXPECT().GetMechanismList(gomock.Any()).Return(tt.mechanisms, nil).AnyTimes()
+			mockCtx.EXPECT().OpenSession(gomock.Any(), gomock.Any()).Return(p11.SessionHandle(0), nil).AnyTimes()
+			mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, "pin").Return(nil).AnyTimes()
+			mockCtx.EXPECT().CloseSession(gomock.Any()).Return(nil).AnyTimes()
+			mockCtx.EXPECT().GenerateKeyPair(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(sh p11.SessionHandle, m []*p11.Mechanism, public, private []*p11.Attribute) (p11.ObjectHandle, p11.ObjectHandle, error) {
+					record.called = true
+					record.mechanism, record.public, record.private = m, public, private
+					return 1, 2, tt.errMsg["GenerateKeyPair"]
+				}).AnyTimes()
+			mockCtx.EXPECT().DestroyObject(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(sh p11.SessionHandle, oh p11.ObjectHandle) error {
+					destroyed[oh] = true
+					return nil
+				}).AnyTimes()
+			mockCtx.EXPECT().GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*p11.Attribute{
+				p11.NewAttribute(p11.CKA_MODULUS, rsaKey.N.Bytes()),
+				p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, big.NewInt(int64(rsaKey.E)).Bytes()),
+				p11.NewAttribute(p11.CKA_EC_POINT, ecPoint),
+			}, nil).AnyTimes()
+			mockCtx.EXPECT().SignInit(gomock.Any(), gomock.Any(), p11.ObjectHandle(2)).Return(nil).AnyTimes()
+			mockCtx.EXPECT().Sign(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(sh p11.SessionHandle, data []byte) ([]byte, error) {
+					if err := tt.errMsg["Sign"]; err != nil {
+						return nil, err
+					}
+					if tt.params.KeyType == crypki.Ed25519 {
+						return ed25519.Sign(edKey, data), nil
+					}
+					// The data is the DigestInfo of the digest, which is signed as is.
+					return rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.Hash(0), data)
+				}).AnyTimes()
+
+			s := &signer{modules: map[string]*module{"": {context: mockCtx, slotPins: map[uint]string{1: "pin"}}}}
+			signature, pub, err := s.SignEphemeral(tt.params, digest[:], tt.opts)
+			if record.called != tt.expectGenCall {
+				t.Errorf("GenerateKeyPair called: %v, expected: %v", record.called, tt.expectGenCall)
+			}
+			if len(destroyed) != tt.expectDestroys {
+				t.Errorf("got %d destroyed objects, want %d", len(destroyed), tt.expectDestroys)
+			}
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, attrs := range [][]*p11.Attribute{record.public, record.private} {
+				if got := attributeValue(attrs, p11.CKA_TOKEN); !bytes.Equal(got, p11.NewAttribute(p11.CKA_TOKEN, false).Value) {
+					t.Errorf("ephemeral key must be a session object, got CKA_TOKEN %v", got)
+				}
+			}
+			if got := attributeValue(record.private, p11.CKA_EXTRACTABLE); !bytes.Equal(got, p11.NewAttribute(p11.CKA_EXTRACTABLE, false).Value) {
+				t.Errorf("private key must not be extractable, got %v", got)
+			}
+			switch key := pub.(type) {
+			case *rsa.PublicKey:
+				if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+					t.Errorf("signature does not match the public key: %v", err)
+				}
+			case ed25519.PublicKey:
+				if !ed25519.Verify(key, digest[:], signature) {
+					t.Error("signature does not match the public key")
+				}
+			default:
+				t.Errorf("unexpected public key type %T", pub)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+)
+
+const (
+	minRSAKeySize        = 2048
+	generatedKeyPoolSize = 2
+)
+
+// rsaPublicExponent is the big-endian encoding of the public exponent 65537.
+var rsaPublicExponent = []byte{0x01, 0x00, 0x01}
+
+// GenerateKey generates a new RSA or ECDSA key pair in the HSM and registers it as a signing key of the signer.
+// The endpoints only sign with the key once it is listed in the Keys and KeyUsages of the configuration,
+// which take effect when crypki restarts.
+func (s *signer) GenerateKey(params *crypki.KeyGenParams) (crypto.PublicKey, error) {
+	if params.Identifier == "" || params.KeyLabel == "" {
+		return nil, &crypki.RequestError{Err: errors.New("key identifier and key label must be specified")}
+	}
+	var mechanism, keyType uint
+	var publicAttrs []*p11.Attribute
+	switch params.KeyType {
+	case crypki.RSA:
+		if params.KeySize < minRSAKeySize {
+			return nil, &crypki.RequestError{Err: fmt.Errorf("RSA key size %d is less than the minimum size %d", params.KeySize, minRSAKeySize)}
+		}
+		mechanism, keyType = p11.CKM_RSA_PKCS_KEY_PAIR_GEN, p11.CKK_RSA
+		publicAttrs = []*p11.Attribute{
+			p11.NewAttribute(p11.CKA_MODULUS_BITS, params.KeySize),
+			p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, rsaPublicExponent),
+		}
+	case crypki.ECDSA:
+		ecParams, err := ecParams(params.KeySize)
+		if err != nil {
+			return nil, &crypki.RequestError{Err: err}
+		}
+		mechanism, keyType = p11.CKM_EC_KEY_PAIR_GEN, p11.CKK_EC
+		publicAttrs = []*p11.Attribute{p11.NewAttribute(p11.CKA_EC_PARAMS, ecParams)}
+	default:
+		// The other types of keys are signed with mechanisms that crypki does not generate key pairs for.
+		return nil, &crypki.RequestError{Err: fmt.Errorf("unsupported key type: %d, only RSA and ECDSA keys are generated", params.KeyType)}
+	}
+
+	// Serialize key generation so that concurrent requests cannot register the same identifier.
+	s.genMu.Lock()
+	defer s.genMu.Unlock()
+	if _, ok := s.getPool(params.Identifier); ok {
+		return nil, &crypki.RequestError{Err: fmt.Errorf("key identifier %q already exists", params.Identifier)}
+	}
+	m, ok := s.modules[params.Module]
+	if !ok {
+		return nil, &crypki.RequestError{Err: fmt.Errorf("unknown PKCS#11 module %q", params.Module)}
+	}
+	pin, ok := m.slotPins[params.SlotNumber]
+	if !ok {
+		return nil, &crypki.RequestError{Err: fmt.Errorf("slot %d has no configured key", params.SlotNumber)}
+	}
+	if err := checkMechanism(m.context, params.SlotNumber, mechanism); err != nil {
+		return nil, err
+	}
+	if err := generateKeyPair(m.context, params.SlotNumber, pin, params.KeyLabel, mechanism, keyType, publicAttrs); err != nil {
+		return nil, err
+	}
+
+	pool, err := newSignerPool(m.context, generatedKeyPoolSize, params.SlotNumber, params.KeyLabel, nil, pin, params.KeyType, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize key with identifier %q: %v", params.Identifier, err)
+	}
+	signer := pool.get()
+	defer pool.put(signer)
+	public, err := publicKey(signer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize key with identifier %q: %v", params.Identifier, err)
+	}
+
+	s.mu.Lock()
+	s.sPool[params.Identifier] = pool
+	s.mu.Unlock()
+	return public, nil
+}
+
+// checkMechanism returns an error if the slot does not support the mechanism, which is a *crypki.RequestError
+// unless the mechanisms of the slot cannot be listed.
+func checkMechanism(context PKCS11Ctx, slot uint, mechanism uint) error {
+	mechs, err := context.GetMechanismList(slot)
+	if err != nil {
+		return fmt.Errorf("unable to get mechanism list of slot %d: %v", slot, err)
+	}
+	for _, m := range mechs {
+		if m.Mechanism == mechanism {
+			return nil
+		}
+	}
+	return &crypki.RequestError{Err: fmt.Errorf("mechanism 0x%x is not supported by slot %d", mechanism, slot)}
+}
+
+// generateKeyPair generates a persistent key pair of the PKCS#11 key type with the given label on the slot,
+// whose public key has the type-specific attributes. It returns a *crypki.RequestError if an object of the
+// slot already has the label.
+func generateKeyPair(context PKCS11Ctx, slot uint, pin string, label string, mechanism, keyType uint, publicAttrs []*p11.Attribute) error {
+	session, err := context.OpenSession(slot, p11.CKF_SERIAL_SESSION|p11.CKF_RW_SESSION)
+	if err != nil {
+		return errors.New("generateKeyPair: error in OpenSession: " + err.Error())
+	}
+	defer context.CloseSession(session)
+
+	if err := loginUser(context, session, pin); err != nil {
+		return errors.New("generateKeyPair: error in Login: " + err.Error())
+	}
+
+	objs, err := findObjects(context, session, []*p11.Attribute{p11.NewAttribute(p11.CKA_LABEL, label)})
+	if err != nil {
+		return errors.New("generateKeyPair: error in findObjects: " + err.Error())
+	}
+	if len(objs) != 0 {
+		return &crypki.RequestError{Err: fmt.Errorf("object with label %q already exists on slot %d", label, slot)}
+	}
+
+	publicTemplate := append([]*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_PUBLIC_KEY),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, keyType),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+		p11.NewAttribute(p11.CKA_TOKEN, true),
+		p11.NewAttribute(p11.CKA_VERIFY, true),
+	}, publicAttrs...)
+	privateTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_PRIVATE_KEY),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, keyType),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+		p11.NewAttribute(p11.CKA_TOKEN, true),
+		p11.NewAttribute(p11.CKA_PRIVATE, true),
+		p11.NewAttribute(p11.CKA_SIGN, true),
+		p11.NewAttribute(p11.CKA_SENSITIVE, true),
+		p11.NewAttribute(p11.CKA_EXTRACTABLE, false),
+	}
+	mech := []*p11.Mechanism{p11.NewMechanism(mechanism, nil)}
+	if _, _, err := context.GenerateKeyPair(session, mech, publicTemplate, privateTemplate); err != nil {
+		return errors.New("generateKeyPair: error in GenerateKeyPair: " + err.Error())
+	}
+	return nil
+}
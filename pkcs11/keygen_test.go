@@ -0,0 +1,256 @@
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+// keyGenRecord records the parameters passed to GenerateKeyPair.
+type keyGenRecord struct {
+	called    bool
+	mechanism []*p11.Mechanism
+	public    []*p11.Attribute
+	private   []*p11.Attribute
+}
+
+// attributeValue returns the value of the attribute of type typ in attrs.
+func attributeValue(attrs []*p11.Attribute, typ uint) []byte {
+	for _, a := range attrs {
+		if a.Type == typ {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+func TestGenerateKey(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	ecPoint, err := ecKey.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("unable to encode EC point: %v", err)
+	}
+	p384Params, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 34})
+	goodParams := &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.RSA, KeySize: 2048}
+	ecdsaParams := &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.ECDSA, KeySize: 384}
+	rsaKeyGen := []*p11.Mechanism{p11.NewMechanism(p11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)}
+	ecKeyGen := []*p11.Mechanism{p11.NewMechanism(p11.CKM_EC_KEY_PAIR_GEN, nil)}
+
+	testcases := map[string]struct {
+		params        *crypki.KeyGenParams
+		mechanisms    []*p11.Mechanism
+		labelExists   bool
+		expectError   bool
+		expectGenCall bool
+		// expectHSMError is set if the error is an error of the HSM rather than one of the request.
+		expectHSMError bool
+		errMsg         map[string]error
+	}{
+		"good": {
+			params:        goodParams,
+			mechanisms:    rsaKeyGen,
+			expectGenCall: true,
+		},
+		"good-already-logged-in": {
+			params:        goodParams,
+			mechanisms:    rsaKeyGen,
+			expectGenCall: true,
+			errMsg: map[string]error{
+				"Login": p11.Error(p11.CKR_USER_ALREADY_LOGGED_IN),
+			},
+		},
+		"good-ecdsa": {
+			params:        ecdsaParams,
+			mechanisms:    ecKeyGen,
+			expectGenCall: true,
+		},
+		"bad-ecdsa-key-size": {
+			params:      &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.ECDSA, KeySize: 2048},
+			mechanisms:  ecKeyGen,
+			expectError: true,
+		},
+		"bad-ecdsa-unsupported-mechanism": {
+			params:      ecdsaParams,
+			mechanisms:  rsaKeyGen,
+			expectError: true,
+		},
+		"bad-key-type": {
+			params:      &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.Ed25519},
+			mechanisms:  rsaKeyGen,
+			expectError: true,
+		},
+		"bad-small-key-size": {
+			params:      &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.RSA, KeySize: 1024},
+			mechanisms:  rsaKeyGen,
+			expectError: true,
+		},
+		"bad-missing-label": {
+			params:      &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 1, KeyType: crypki.RSA, KeySize: 2048},
+			mechanisms:  rsaKeyGen,
+			expectError: true,
+		},
+		"bad-existing-identifier": {
+			params:      &crypki.KeyGenParams{Identifier: defaultIdentifier, SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.RSA, KeySize: 2048},
+			mechanisms:  rsaKeyGen,
+			expectError: true,
+		},
+		"bad-unknown-slot": {
+			params:      &crypki.KeyGenParams{Identifier: "newkey", SlotNumber: 2, KeyLabel: "newlabel", KeyType: crypki.RSA, KeySize: 2048},
+			mechanisms:  rsaKeyGen,
+			expectError: true,
+		},
+		"bad-unknown-module": {
+			params:      &crypki.KeyGenParams{Identifier: "newkey", Module: "softhsm", SlotNumber: 1, KeyLabel: "newlabel", KeyType: crypki.RSA, KeySize: 2048},
+			mechanisms:  rsaKeyGen,
+			expectError: true,
+		},
+		"bad-unsupported-mechanism": {
+			params:      goodParams,
+			mechanisms:  []*p11.Mechanism{p11.NewMechanism(p11.CKM_EC_KEY_PAIR_GEN, nil)},
+			expectError: true,
+		},
+		"bad-existing-label": {
+			params:      goodParams,
+			mechanisms:  rsaKeyGen,
+			labelExists: true,
+			expectError: true,
+		},
+		"bad-login": {
+			params:         goodParams,
+			mechanisms:     rsaKeyGen,
+			expectError:    true,
+			expectHSMError: true,
+			errMsg: map[string]error{
+				"Login": errors.New("bad pin"),
+			},
+		},
+		"bad-GenerateKeyPair": {
+			params:         goodParams,
+			mechanisms:     rsaKeyGen,
+			expectError:    true,
+			expectGenCall:  true,
+			expectHSMError: true,
+			errMsg: map[string]error{
+				"GenerateKeyPair": errors.New("CKR_DEVICE_ERROR"),
+			},
+		},
+	}
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+
+			record := &keyGenRecord{}
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			mockCtx.EXPECT().GetMechanismList(gomock.Any()).Return(tt.mechanisms, nil).AnyTimes()
+			mockCtx.EXPECT().OpenSession(gomock.Any(), gomock.Any()).Return(p11.SessionHandle(0), nil).AnyTimes()
+			mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, "pin").Return(tt.errMsg["Login"]).AnyTimes()
+			mockCtx.EXPECT().CloseSession(gomock.Any()).Return(nil).AnyTimes()
+			mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+			mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(sh p11.SessionHandle, max int) ([]p11.ObjectHandle, bool, error) {
+					if tt.labelExists || record.called {
+						return []p11.ObjectHandle{1}, false, nil
+					}
+					return nil, false, nil
+				}).AnyTimes()
+			mockCtx.EXPECT().GenerateKeyPair(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(sh p11.SessionHandle, m []*p11.Mechanism, public, private []*p11.Attribute) (p11.ObjectHandle, p11.ObjectHandle, error) {
+					record.called = true
+					record.mechanism, record.public, record.private = m, public, private
+					return 1, 2, tt.errMsg["GenerateKeyPair"]
+				}).AnyTimes()
+			attrs := []*p11.Attribute{
+				p11.NewAttribute(p11.CKA_MODULUS, rsaKey.N.Bytes()),
+				p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, big.NewInt(int64(rsaKey.E)).Bytes()),
+			}
+			wantMechanism, wantKey := uint(p11.CKM_RSA_PKCS_KEY_PAIR_GEN), crypto.PublicKey(&rsaKey.PublicKey)
+			if tt.params.KeyType == crypki.ECDSA {
+				attrs = []*p11.Attribute{p11.NewAttribute(p11.CKA_EC_PARAMS, p384Params), p11.NewAttribute(p11.CKA_EC_POINT, ecPoint)}
+				wantMechanism, wantKey = p11.CKM_EC_KEY_PAIR_GEN, &ecKey.PublicKey
+			}
+			mockCtx.EXPECT().GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).Return(attrs, nil).AnyTimes()
+
+			s, err := initMockSigner(false)
+			if err != nil {
+				t.Fatalf("unable to init mock signer: %v", err)
+			}
+			s.modules = map[string]*module{"": {context: mockCtx, slotPins: map[uint]string{1: "pin"}}}
+
+			pub, err := s.GenerateKey(tt.params)
+			if record.called != tt.expectGenCall {
+				t.Errorf("GenerateKeyPair called: %v, expected: %v", record.called, tt.expectGenCall)
+			}
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if err != nil {
+				if _, ok := err.(*crypki.RequestError); ok == tt.expectHSMError {
+					t.Errorf("got error %v of the request: %v, want an error of the HSM: %v", err, ok, tt.expectHSMError)
+				}
+				if tt.params.Identifier != defaultIdentifier {
+					if _, ok := s.getPool(tt.params.Identifier); ok {
+						t.Error("key registered after failed generation")
+					}
+				}
+				return
+			}
+
+			if len(record.mechanism) != 1 || record.mechanism[0].Mechanism != wantMechanism {
+				t.Errorf("unexpected mechanism: %+v", record.mechanism)
+			}
+			for _, attrs := range [][]*p11.Attribute{record.public, record.private} {
+				if got := string(attributeValue(attrs, p11.CKA_LABEL)); got != tt.params.KeyLabel {
+					t.Errorf("label mismatch: got %q, want %q", got, tt.params.KeyLabel)
+				}
+			}
+			if tt.params.KeyType == crypki.ECDSA {
+				if got := attributeValue(record.public, p11.CKA_EC_PARAMS); !bytes.Equal(got, p384Params) {
+					t.Errorf("EC params mismatch: got %v, want %v", got, p384Params)
+				}
+			} else {
+				wantBits := p11.NewAttribute(p11.CKA_MODULUS_BITS, tt.params.KeySize).Value
+				if got := attributeValue(record.public, p11.CKA_MODULUS_BITS); !bytes.Equal(got, wantBits) {
+					t.Errorf("modulus bits mismatch: got %v, want %v", got, wantBits)
+				}
+			}
+			if got := attributeValue(record.private, p11.CKA_EXTRACTABLE); !bytes.Equal(got, p11.NewAttribute(p11.CKA_EXTRACTABLE, false).Value) {
+				t.Errorf("private key must not be extractable, got %v", got)
+			}
+
+			wantPub, _ := x509.MarshalPKIXPublicKey(wantKey)
+			gotPub, err := x509.MarshalPKIXPublicKey(pub)
+			if err != nil || !reflect.DeepEqual(gotPub, wantPub) {
+				t.Errorf("public key mismatch, err: %v", err)
+			}
+			if _, ok := s.getPool(tt.params.Identifier); !ok {
+				t.Errorf("key %q not registered", tt.params.Identifier)
+			}
+		})
+	}
+}
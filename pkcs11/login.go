@@ -0,0 +1,117 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki/config"
+)
+
+// loginError is returned when the login to the token of a slot fails, e.g. because of a wrong user pin.
+// It never includes the pin, only the path of the file it was read from.
+type loginError struct {
+	identifier string
+	slot       uint
+	pinPath    string
+	err        error
+}
+
+func (e *loginError) Error() string {
+	msg := fmt.Sprintf("unable to log in to slot %d", e.slot)
+	if e.identifier != "" {
+		msg += fmt.Sprintf(" for key with identifier %q", e.identifier)
+	}
+	if e.pinPath != "" {
+		msg += fmt.Sprintf(", check the user pin in %s", e.pinPath)
+	}
+	return fmt.Sprintf("%s: %v", msg, e.err)
+}
+
+// incorrectPin returns true if the login failed because the pin is incorrect.
+func (e *loginError) incorrectPin() bool {
+	return e.err == p11.Error(p11.CKR_PIN_INCORRECT)
+}
+
+// LoginRetry configures NewCertSign to start even if it fails to log in to the slots of some keys,
+// and to retry loading these keys in the background, so that crypki can come up for diagnosis.
+type LoginRetry struct {
+	// Interval is the delay between the attempts to load the keys.
+	Interval time.Duration
+	// Pending, if set, is called with the identifier of each key that could not be loaded at startup.
+	Pending func(identifier string)
+	// Loaded, if set, is called with the identifier of each pending key once it is loaded.
+	Loaded func(identifier string)
+}
+
+// keyPins returns the contents of the pin files of the key, or "" if one of them cannot be read.
+func keyPins(key config.KeyConfig) string {
+	paths := []string{key.UserPinPath}
+	if len(key.ThresholdShares) != 0 {
+		paths = nil
+		for _, share := range key.ThresholdShares {
+			paths = append(paths, share.UserPinPath)
+		}
+	}
+	var pins []string
+	for _, path := range paths {
+		pin, err := getUserPin(path)
+		if err != nil {
+			return ""
+		}
+		pins = append(pins, pin)
+	}
+	return strings.Join(pins, "\n")
+}
+
+// retryLogin retries loading the pending keys at the interval of the LoginRetry until all of them are loaded
+// or the signer is closed. incorrectPins maps the keys whose login failed because of an incorrect pin to
+// their pins, which are not retried until their pin files change, as repeated logins with an incorrect
+// pin may lock the token.
+func (s *signer) retryLogin(pending []config.KeyConfig, incorrectPins map[string]string, requireX509CACert map[string]bool, hostname string, ips []net.IP) {
+	defer s.retries.Done()
+	ticker := time.NewTicker(s.loginRetry.Interval)
+	defer ticker.Stop()
+	for len(pending) != 0 {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+		var remaining []config.KeyConfig
+		for _, key := range pending {
+			pins, incorrect := incorrectPins[key.Identifier]
+			if incorrect && keyPins(key) == pins {
+				remaining = append(remaining, key)
+				continue
+			}
+			// Loading a key registers its slot pins, which are used by key generation.
+			s.genMu.Lock()
+			err := s.loadKey(key, requireX509CACert, hostname, ips)
+			s.genMu.Unlock()
+			if err == nil {
+				log.Printf("key %q loaded after retrying to log in", key.Identifier)
+				delete(incorrectPins, key.Identifier)
+				if s.loginRetry.Loaded != nil {
+					s.loginRetry.Loaded(key.Identifier)
+				}
+				continue
+			}
+			if le, ok := err.(*loginError); ok && le.incorrectPin() {
+				incorrectPins[key.Identifier] = keyPins(key)
+				log.Printf("%v, retrying once the pin file changes", err)
+			} else {
+				delete(incorrectPins, key.Identifier)
+				log.Printf("%v, retrying in %v", err, s.loginRetry.Interval)
+			}
+			remaining = append(remaining, key)
+		}
+		pending = remaining
+	}
+}
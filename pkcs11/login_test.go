@@ -0,0 +1,188 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+// mockLogin is a PKCS#11 login that fails with failErr for the first failures calls, and then
+// fails with CKR_PIN_INCORRECT unless the pin is goodPin.
+type mockLogin struct {
+	mu       sync.Mutex
+	goodPin  string
+	failErr  error
+	failures int
+	calls    map[string]int
+}
+
+func (m *mockLogin) login(_ p11.SessionHandle, _ uint, pin string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls[pin]++
+	if m.failures > 0 {
+		m.failures--
+		return m.failErr
+	}
+	if pin != m.goodPin {
+		return p11.Error(p11.CKR_PIN_INCORRECT)
+	}
+	return nil
+}
+
+func (m *mockLogin) callsWith(pin string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[pin]
+}
+
+// newLoginTestSigner returns a signer of a module whose logins are done by login.
+func newLoginTestSigner(mockctrl *gomock.Controller, login *mockLogin, loginRetry *LoginRetry) *signer {
+	mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+	mockCtx.EXPECT().OpenSession(uint(1), gomock.Any()).Return(p11.SessionHandle(1), nil).AnyTimes()
+	mockCtx.EXPECT().CloseSession(gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, gomock.Any()).DoAndReturn(login.login).AnyTimes()
+	mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).Return([]p11.ObjectHandle{1}, false, nil).AnyTimes()
+	mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().Finalize().Return(nil).AnyTimes()
+	mockCtx.EXPECT().Destroy().AnyTimes()
+	return &signer{
+		x509CACerts: make(map[string]*x509.Certificate),
+		sPool:       make(map[string]sPool),
+		modules:     map[string]*module{"": {context: mockCtx, slotPins: make(map[uint]string)}},
+		loginRetry:  loginRetry,
+	}
+}
+
+// writePinFile writes the pin to a temporary pin file and returns its path.
+func writePinFile(t *testing.T, pin string) string {
+	t.Helper()
+	pinFile, err := ioutil.TempFile("", "pin")
+	if err != nil {
+		t.Fatalf("unable to create pin file: %v", err)
+	}
+	defer pinFile.Close()
+	if _, err := pinFile.WriteString(pin + "\n"); err != nil {
+		t.Fatalf("unable to write pin file: %v", err)
+	}
+	return pinFile.Name()
+}
+
+func TestLoadKeysLoginFailFast(t *testing.T) {
+	t.Parallel()
+	mockctrl := gomock.NewController(t)
+	defer mockctrl.Finish()
+	pinPath := writePinFile(t, "1234")
+	defer os.Remove(pinPath)
+
+	s := newLoginTestSigner(mockctrl, &mockLogin{goodPin: "5678", calls: make(map[string]int)}, nil)
+	keys := []config.KeyConfig{{Identifier: "key1", SlotNumber: 1, UserPinPath: pinPath, KeyLabel: "foo", SessionPoolSize: 1, KeyType: crypki.RSA}}
+	err := s.loadKeys(keys, nil, "", nil)
+	if _, ok := err.(*loginError); !ok {
+		t.Fatalf("got err %v, want a login error", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, `"key1"`) || !strings.Contains(msg, pinPath) || strings.Contains(msg, "1234") {
+		t.Errorf("got err %q, want a descriptive error without the pin", msg)
+	}
+	if _, ok := s.getPool("key1"); ok {
+		t.Error("key loaded despite the login failure")
+	}
+}
+
+func TestLoadKeysLoginRetry(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		login *mockLogin
+		// change is the pin written to the pin file once the key is pending, if any.
+		change string
+		// expectedBadPinLogins is the number of logins with the pin "1234" if it is incorrect.
+		expectedBadPinLogins int
+	}{
+		"token-not-present": {
+			login: &mockLogin{goodPin: "1234", failErr: p11.Error(p11.CKR_TOKEN_NOT_PRESENT), failures: 3},
+		},
+		"incorrect-pin-retried-once-changed": {
+			login:                &mockLogin{goodPin: "5678"},
+			change:               "5678",
+			expectedBadPinLogins: 1,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+			pinPath := writePinFile(t, "1234")
+			defer os.Remove(pinPath)
+
+			var mu sync.Mutex
+			var pending []string
+			loaded := make(chan string, 1)
+			loginRetry := &LoginRetry{
+				Interval: 10 * time.Millisecond,
+				Pending: func(id string) {
+					mu.Lock()
+					defer mu.Unlock()
+					pending = append(pending, id)
+				},
+				Loaded: func(id string) { loaded <- id },
+			}
+			tt.login.calls = make(map[string]int)
+			s := newLoginTestSigner(mockctrl, tt.login, loginRetry)
+			defer s.Close()
+			keys := []config.KeyConfig{{Identifier: "key1", SlotNumber: 1, UserPinPath: pinPath, KeyLabel: "foo", SessionPoolSize: 1, KeyType: crypki.RSA}}
+			if err := s.loadKeys(keys, nil, "", nil); err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			mu.Lock()
+			if len(pending) != 1 || pending[0] != "key1" {
+				t.Errorf("in test %v: got pending keys %v, want [key1]", label, pending)
+			}
+			mu.Unlock()
+			if _, ok := s.getPool("key1"); ok {
+				t.Errorf("in test %v: key loaded despite the login failure", label)
+			}
+
+			if tt.change != "" {
+				// An incorrect pin is not retried as long as the pin file is unchanged.
+				time.Sleep(10 * loginRetry.Interval)
+				if got := tt.login.callsWith("1234"); got != tt.expectedBadPinLogins {
+					t.Errorf("in test %v: got %d logins with the incorrect pin, want %d", label, got, tt.expectedBadPinLogins)
+				}
+				if err := ioutil.WriteFile(pinPath, []byte(tt.change+"\n"), 0600); err != nil {
+					t.Fatalf("in test %v: unable to write pin file: %v", label, err)
+				}
+			}
+			select {
+			case id := <-loaded:
+				if id != "key1" {
+					t.Errorf("in test %v: got loaded key %q, want key1", label, id)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("in test %v: key not loaded after retrying to log in", label)
+			}
+			if _, ok := s.getPool("key1"); !ok {
+				t.Errorf("in test %v: key not loaded", label)
+			}
+			if tt.change != "" && s.modules[""].slotPins[1] != tt.change {
+				t.Errorf("in test %v: slot pin not updated", label)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+)
+
+// signMechanisms maps the names of the PKCS#11 signing mechanisms that can be configured for a key
+// to their values and the key type they sign with. Mechanisms that hash the data in the HSM, such
+// as CKM_SHA256_RSA_PKCS or CKM_ECDSA_SHA256, are not supported as crypki signs digests.
+var signMechanisms = map[string]struct {
+	mechanism uint
+	keyType   crypki.PublicKeyAlgorithm
+}{
+	"CKM_RSA_PKCS":  {p11.CKM_RSA_PKCS, crypki.RSA},
+	"CKM_RSA_X_509": {p11.CKM_RSA_X_509, crypki.RSA},
+	"CKM_ECDSA":     {p11.CKM_ECDSA, crypki.ECDSA},
+	"CKM_EDDSA":     {ckmEdDSA, crypki.Ed25519},
+}
+
+// signMechanism returns the PKCS#11 mechanism of the given name for keys of keyType,
+// or 0 if name is empty, in which case the mechanism is selected by key type. The ECDSA keys
+// may also use a vendor defined mechanism given by its hex value, which signs like CKM_ECDSA.
+func signMechanism(name string, keyType crypki.PublicKeyAlgorithm) (uint, error) {
+	if name == "" {
+		return 0, nil
+	}
+	if strings.HasPrefix(name, "0x") {
+		v, err := strconv.ParseUint(name[2:], 16, 32)
+		if err != nil || v < p11.CKM_VENDOR_DEFINED {
+			return 0, fmt.Errorf("invalid vendor defined signing mechanism %q", name)
+		}
+		if keyType != crypki.ECDSA {
+			return 0, fmt.Errorf("vendor defined signing mechanism %q is only supported for ECDSA keys", name)
+		}
+		return uint(v), nil
+	}
+	m, ok := signMechanisms[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported signing mechanism %q", name)
+	}
+	if keyType == crypki.UnknownPublicKeyAlgorithm {
+		keyType = crypki.RSA // RSA is the default
+	}
+	if m.keyType != keyType {
+		return 0, fmt.Errorf("signing mechanism %q is not compatible with key type %d", name, keyType)
+	}
+	return m.mechanism, nil
+}
+
+// ckmECEdwardsKeyPairGen is the CKM_EC_EDWARDS_KEY_PAIR_GEN mechanism of PKCS#11 v3.0, which is not defined
+// by the pkcs11 package.
+const ckmECEdwardsKeyPairGen = 0x1055
+
+// mechanismNames maps the values of the PKCS#11 mechanisms listed by ListMechanisms to their names.
+// The mechanisms not in this map, such as the vendor defined ones, are listed by their values.
+var mechanismNames = map[uint]string{
+	p11.CKM_RSA_PKCS_KEY_PAIR_GEN:  "CKM_RSA_PKCS_KEY_PAIR_GEN",
+	p11.CKM_RSA_PKCS:               "CKM_RSA_PKCS",
+	p11.CKM_RSA_X_509:              "CKM_RSA_X_509",
+	p11.CKM_RSA_PKCS_OAEP:          "CKM_RSA_PKCS_OAEP",
+	p11.CKM_RSA_PKCS_PSS:           "CKM_RSA_PKCS_PSS",
+	p11.CKM_SHA1_RSA_PKCS:          "CKM_SHA1_RSA_PKCS",
+	p11.CKM_SHA224_RSA_PKCS:        "CKM_SHA224_RSA_PKCS",
+	p11.CKM_SHA256_RSA_PKCS:        "CKM_SHA256_RSA_PKCS",
+	p11.CKM_SHA384_RSA_PKCS:        "CKM_SHA384_RSA_PKCS",
+	p11.CKM_SHA512_RSA_PKCS:        "CKM_SHA512_RSA_PKCS",
+	p11.CKM_SHA224_RSA_PKCS_PSS:    "CKM_SHA224_RSA_PKCS_PSS",
+	p11.CKM_SHA256_RSA_PKCS_PSS:    "CKM_SHA256_RSA_PKCS_PSS",
+	p11.CKM_SHA384_RSA_PKCS_PSS:    "CKM_SHA384_RSA_PKCS_PSS",
+	p11.CKM_SHA512_RSA_PKCS_PSS:    "CKM_SHA512_RSA_PKCS_PSS",
+	p11.CKM_SHA3_256_RSA_PKCS:      "CKM_SHA3_256_RSA_PKCS",
+	p11.CKM_SHA3_384_RSA_PKCS:      "CKM_SHA3_384_RSA_PKCS",
+	p11.CKM_SHA3_512_RSA_PKCS:      "CKM_SHA3_512_RSA_PKCS",
+	p11.CKM_EC_KEY_PAIR_GEN:        "CKM_EC_KEY_PAIR_GEN",
+	p11.CKM_ECDSA:                  "CKM_ECDSA",
+	p11.CKM_ECDSA_SHA1:             "CKM_ECDSA_SHA1",
+	p11.CKM_ECDSA_SHA224:           "CKM_ECDSA_SHA224",
+	p11.CKM_ECDSA_SHA256:           "CKM_ECDSA_SHA256",
+	p11.CKM_ECDSA_SHA384:           "CKM_ECDSA_SHA384",
+	p11.CKM_ECDSA_SHA512:           "CKM_ECDSA_SHA512",
+	p11.CKM_ECDH1_DERIVE:           "CKM_ECDH1_DERIVE",
+	ckmECEdwardsKeyPairGen:         "CKM_EC_EDWARDS_KEY_PAIR_GEN",
+	ckmEdDSA:                       "CKM_EDDSA",
+	ckmMLDSAKeyPairGen:             "CKM_ML_DSA_KEY_PAIR_GEN",
+	ckmMLDSA:                       "CKM_ML_DSA",
+	ckmHashMLDSA:                   "CKM_HASH_ML_DSA",
+	ckmSLHDSAKeyPairGen:            "CKM_SLH_DSA_KEY_PAIR_GEN",
+	ckmSLHDSA:                      "CKM_SLH_DSA",
+	ckmHashSLHDSA:                  "CKM_HASH_SLH_DSA",
+	p11.CKM_SHA_1:                  "CKM_SHA_1",
+	p11.CKM_SHA224:                 "CKM_SHA224",
+	p11.CKM_SHA256:                 "CKM_SHA256",
+	p11.CKM_SHA384:                 "CKM_SHA384",
+	p11.CKM_SHA512:                 "CKM_SHA512",
+	p11.CKM_SHA3_224:               "CKM_SHA3_224",
+	p11.CKM_SHA3_256:               "CKM_SHA3_256",
+	p11.CKM_SHA3_384:               "CKM_SHA3_384",
+	p11.CKM_SHA3_512:               "CKM_SHA3_512",
+	p11.CKM_SHA256_HMAC:            "CKM_SHA256_HMAC",
+	p11.CKM_SHA384_HMAC:            "CKM_SHA384_HMAC",
+	p11.CKM_SHA512_HMAC:            "CKM_SHA512_HMAC",
+	p11.CKM_GENERIC_SECRET_KEY_GEN: "CKM_GENERIC_SECRET_KEY_GEN",
+	p11.CKM_AES_KEY_GEN:            "CKM_AES_KEY_GEN",
+	p11.CKM_AES_CBC:                "CKM_AES_CBC",
+	p11.CKM_AES_CBC_PAD:            "CKM_AES_CBC_PAD",
+	p11.CKM_AES_GCM:                "CKM_AES_GCM",
+	p11.CKM_AES_KEY_WRAP:           "CKM_AES_KEY_WRAP",
+	p11.CKM_AES_KEY_WRAP_PAD:       "CKM_AES_KEY_WRAP_PAD",
+}
+
+// mechanismFunctions are the names of the functions of the mechanisms, in the order they are listed.
+var mechanismFunctions = []struct {
+	flag uint
+	name string
+}{
+	{p11.CKF_ENCRYPT, "encrypt"},
+	{p11.CKF_DECRYPT, "decrypt"},
+	{p11.CKF_DIGEST, "digest"},
+	{p11.CKF_SIGN, "sign"},
+	{p11.CKF_SIGN_RECOVER, "sign_recover"},
+	{p11.CKF_VERIFY, "verify"},
+	{p11.CKF_VERIFY_RECOVER, "verify_recover"},
+	{p11.CKF_GENERATE, "generate"},
+	{p11.CKF_GENERATE_KEY_PAIR, "generate_key_pair"},
+	{p11.CKF_WRAP, "wrap"},
+	{p11.CKF_UNWRAP, "unwrap"},
+	{p11.CKF_DERIVE, "derive"},
+}
+
+// ListMechanisms returns the mechanisms supported by the slots holding the configured keys, sorted by module
+// name and slot number. The mechanisms of a slot are listed with C_GetMechanismList and C_GetMechanismInfo.
+func (s *signer) ListMechanisms() []crypki.SlotMechanisms {
+	var names []string
+	for name := range s.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var list []crypki.SlotMechanisms
+	for _, name := range names {
+		m := s.modules[name]
+		s.genMu.Lock()
+		var slots []uint
+		for slot := range m.slotPins {
+			slots = append(slots, slot)
+		}
+		s.genMu.Unlock()
+		sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+		for _, slot := range slots {
+			mechs, err := slotMechanisms(m.context, slot)
+			list = append(list, crypki.SlotMechanisms{Module: name, SlotNumber: slot, Mechanisms: mechs, Err: err})
+		}
+	}
+	return list
+}
+
+// slotMechanisms returns the mechanisms supported by the slot, sorted by value.
+func slotMechanisms(context PKCS11Ctx, slot uint) ([]crypki.Mechanism, error) {
+	mechs, err := context.GetMechanismList(slot)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get mechanism list of slot %d: %v", slot, err)
+	}
+	list := make([]crypki.Mechanism, 0, len(mechs))
+	for _, mech := range mechs {
+		info, err := context.GetMechanismInfo(slot, []*p11.Mechanism{mech})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get info of mechanism 0x%x of slot %d: %v", mech.Mechanism, slot, err)
+		}
+		name, ok := mechanismNames[mech.Mechanism]
+		if !ok {
+			name = fmt.Sprintf("0x%08x", mech.Mechanism)
+		}
+		m := crypki.Mechanism{Name: name, Value: mech.Mechanism, MinKeySize: info.MinKeySize, MaxKeySize: info.MaxKeySize}
+		for _, f := range mechanismFunctions {
+			if info.Flags&f.flag != 0 {
+				m.Functions = append(m.Functions, f.name)
+			}
+		}
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Value < list[j].Value })
+	return list, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+func TestListMechanisms(t *testing.T) {
+	t.Parallel()
+	mockctrl := gomock.NewController(t)
+	defer mockctrl.Finish()
+
+	defaultCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+	defaultCtx.EXPECT().GetMechanismList(uint(1)).Return([]*p11.Mechanism{
+		p11.NewMechanism(p11.CKM_SHA256, nil),
+		p11.NewMechanism(p11.CKM_RSA_PKCS, nil),
+		p11.NewMechanism(0x80000001, nil),
+	}, nil)
+	defaultCtx.EXPECT().GetMechanismInfo(uint(1), gomock.Any()).DoAndReturn(func(slot uint, m []*p11.Mechanism) (p11.MechanismInfo, error) {
+		switch m[0].Mechanism {
+		case p11.CKM_RSA_PKCS:
+			return p11.MechanismInfo{MinKeySize: 2048, MaxKeySize: 4096, Flags: p11.CKF_HW | p11.CKF_SIGN | p11.CKF_VERIFY | p11.CKF_ENCRYPT}, nil
+		case p11.CKM_SHA256:
+			return p11.MechanismInfo{Flags: p11.CKF_DIGEST}, nil
+		default:
+			return p11.MechanismInfo{Flags: p11.CKF_GENERATE_KEY_PAIR}, nil
+		}
+	}).Times(3)
+	defaultCtx.EXPECT().GetMechanismList(uint(3)).Return([]*p11.Mechanism{p11.NewMechanism(ckmEdDSA, nil)}, nil)
+	defaultCtx.EXPECT().GetMechanismInfo(uint(3), gomock.Any()).Return(p11.MechanismInfo{MinKeySize: 255, MaxKeySize: 255, Flags: p11.CKF_SIGN}, nil)
+	softhsmCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+	softhsmCtx.EXPECT().GetMechanismList(uint(2)).Return(nil, errors.New("CKR_TOKEN_NOT_PRESENT"))
+
+	s := &signer{modules: map[string]*module{
+		"":        {context: defaultCtx, slotPins: map[uint]string{3: "1234", 1: "1234"}},
+		"softhsm": {context: softhsmCtx, slotPins: map[uint]string{2: "1234"}},
+	}}
+	got := s.ListMechanisms()
+	expected := []crypki.SlotMechanisms{
+		{SlotNumber: 1, Mechanisms: []crypki.Mechanism{
+			{Name: "CKM_RSA_PKCS", Value: p11.CKM_RSA_PKCS, MinKeySize: 2048, MaxKeySize: 4096, Functions: []string{"encrypt", "sign", "verify"}},
+			{Name: "CKM_SHA256", Value: p11.CKM_SHA256, Functions: []string{"digest"}},
+			{Name: "0x80000001", Value: 0x80000001, Functions: []string{"generate_key_pair"}},
+		}},
+		{SlotNumber: 3, Mechanisms: []crypki.Mechanism{
+			{Name: "CKM_EDDSA", Value: ckmEdDSA, MinKeySize: 255, MaxKeySize: 255, Functions: []string{"sign"}},
+		}},
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d slots, want 3: %+v", len(got), got)
+	}
+	if !reflect.DeepEqual(got[:2], expected) {
+		t.Errorf("got mechanisms %+v, want %+v", got[:2], expected)
+	}
+	if got[2].Module != "softhsm" || got[2].SlotNumber != 2 || got[2].Err == nil {
+		t.Errorf("got %+v for the slot failing to list its mechanisms, want an error", got[2])
+	}
+}
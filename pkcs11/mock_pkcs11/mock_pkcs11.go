@@ -224,3 +224,89 @@ func (mr *MockPKCS11CtxMockRecorder) GetTokenInfo(slotID interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenInfo", reflect.TypeOf((*MockPKCS11Ctx)(nil).GetTokenInfo), slotID)
 }
+
+// GetMechanismList mocks base method
+func (m *MockPKCS11Ctx) GetMechanismList(slotID uint) ([]*pkcs11.Mechanism, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMechanismList", slotID)
+	ret0, _ := ret[0].([]*pkcs11.Mechanism)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMechanismList indicates an expected call of GetMechanismList
+func (mr *MockPKCS11CtxMockRecorder) GetMechanismList(slotID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMechanismList", reflect.TypeOf((*MockPKCS11Ctx)(nil).GetMechanismList), slotID)
+}
+
+// GetMechanismInfo mocks base method
+func (m_2 *MockPKCS11Ctx) GetMechanismInfo(slotID uint, m []*pkcs11.Mechanism) (pkcs11.MechanismInfo, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "GetMechanismInfo", slotID, m)
+	ret0, _ := ret[0].(pkcs11.MechanismInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMechanismInfo indicates an expected call of GetMechanismInfo
+func (mr *MockPKCS11CtxMockRecorder) GetMechanismInfo(slotID, m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMechanismInfo", reflect.TypeOf((*MockPKCS11Ctx)(nil).GetMechanismInfo), slotID, m)
+}
+
+// GenerateKeyPair mocks base method
+func (m_2 *MockPKCS11Ctx) GenerateKeyPair(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, public, private []*pkcs11.Attribute) (pkcs11.ObjectHandle, pkcs11.ObjectHandle, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "GenerateKeyPair", sh, m, public, private)
+	ret0, _ := ret[0].(pkcs11.ObjectHandle)
+	ret1, _ := ret[1].(pkcs11.ObjectHandle)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GenerateKeyPair indicates an expected call of GenerateKeyPair
+func (mr *MockPKCS11CtxMockRecorder) GenerateKeyPair(sh, m, public, private interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateKeyPair", reflect.TypeOf((*MockPKCS11Ctx)(nil).GenerateKeyPair), sh, m, public, private)
+}
+
+// DestroyObject mocks base method
+func (m *MockPKCS11Ctx) DestroyObject(sh pkcs11.SessionHandle, oh pkcs11.ObjectHandle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyObject", sh, oh)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyObject indicates an expected call of DestroyObject
+func (mr *MockPKCS11CtxMockRecorder) DestroyObject(sh, oh interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyObject", reflect.TypeOf((*MockPKCS11Ctx)(nil).DestroyObject), sh, oh)
+}
+
+// Finalize mocks base method
+func (m *MockPKCS11Ctx) Finalize() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finalize")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finalize indicates an expected call of Finalize
+func (mr *MockPKCS11CtxMockRecorder) Finalize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finalize", reflect.TypeOf((*MockPKCS11Ctx)(nil).Finalize))
+}
+
+// Destroy mocks base method
+func (m *MockPKCS11Ctx) Destroy() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Destroy")
+}
+
+// Destroy indicates an expected call of Destroy
+func (mr *MockPKCS11CtxMockRecorder) Destroy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Destroy", reflect.TypeOf((*MockPKCS11Ctx)(nil).Destroy))
+}
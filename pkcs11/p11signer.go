@@ -5,7 +5,9 @@ package pkcs11
 
 import (
 	"crypto"
+	"crypto/rsa"
 	"errors"
+	"fmt"
 	"io"
 
 	p11 "github.com/miekg/pkcs11"
@@ -18,48 +20,72 @@ type p11Signer struct {
 	privateKey p11.ObjectHandle
 	publicKey  p11.ObjectHandle
 	keyType    crypki.PublicKeyAlgorithm
+	// mechanism is the PKCS#11 signing mechanism configured for the key. If 0, it is selected by key type.
+	mechanism uint
 }
 
-func makeSigner(context PKCS11Ctx, login bool, slot uint, tokenLabel string, userPin string, keyType crypki.PublicKeyAlgorithm) (*p11Signer, error) {
+func makeSigner(context PKCS11Ctx, login bool, slot uint, tokenLabel string, keyID []byte, userPin string, keyType crypki.PublicKeyAlgorithm, mechanism uint) (*p11Signer, error) {
 	session, err := context.OpenSession(slot, p11.CKF_SERIAL_SESSION)
 	if err != nil {
 		return nil, errors.New("makeSigner: error in OpenSession: " + err.Error())
 	}
 
 	if login {
-		if err = context.Login(session, p11.CKU_USER, userPin); err != nil {
+		if err = loginUser(context, session, userPin); err != nil {
 			context.CloseSession(session)
-			return nil, errors.New("makeSigner: error in Login: " + err.Error())
+			return nil, &loginError{slot: slot, err: err}
 		}
 	}
 
-	privateKey, err := getKey(context, session, tokenLabel, p11.CKO_PRIVATE_KEY)
+	privateKey, err := getKey(context, session, tokenLabel, keyID, p11.CKO_PRIVATE_KEY)
 	if err != nil {
 		context.CloseSession(session)
 		return nil, errors.New("makeSigner: error in getPrivateKey: " + err.Error())
 	}
 
-	publicKey, err := getKey(context, session, tokenLabel, p11.CKO_PUBLIC_KEY)
+	publicKey, err := getKey(context, session, tokenLabel, keyID, p11.CKO_PUBLIC_KEY)
 	if err != nil {
 		context.CloseSession(session)
 		return nil, errors.New("makeSigner: error in getPublicKey: " + err.Error())
 	}
-	return &p11Signer{context, session, privateKey, publicKey, keyType}, nil
+	return &p11Signer{context, session, privateKey, publicKey, keyType, mechanism}, nil
+}
+
+// loginUser logs the user in to the token of the session. The login state is shared by
+// all sessions of the token, so it is not an error if the user is already logged in.
+func loginUser(context PKCS11Ctx, session p11.SessionHandle, userPin string) error {
+	err := context.Login(session, p11.CKU_USER, userPin)
+	if err == p11.Error(p11.CKR_USER_ALREADY_LOGGED_IN) {
+		return nil
+	}
+	return err
 }
 
 // Sign signs the data using PKCS11 library. It is part of the crypto.Signer interface.
 func (s *p11Signer) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
 	switch s.keyType {
 	case crypki.RSA:
-		return signDataRSA(s.context, s.session, s.privateKey, msg, opts)
+		return s.signRSA(msg, opts)
 	case crypki.ECDSA:
-		return signDataECDSA(s.context, s.session, s.privateKey, msg, opts)
+		return signDataECDSA(s.context, s.session, s.privateKey, s.mechanism, msg, opts)
+	case crypki.Ed25519:
+		return signDataEd25519(s.context, s.session, s.privateKey, msg, opts)
+	case crypki.MLDSA, crypki.SLHDSA:
+		return signDataPQC(s.context, s.session, s.privateKey, s.keyType, msg, opts)
 	default: // RSA is the default
-		return signDataRSA(s.context, s.session, s.privateKey, msg, opts)
+		return s.signRSA(msg, opts)
 
 	}
 }
 
+// signRSA signs the data with the RSA mechanism configured for the key.
+func (s *p11Signer) signRSA(msg []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if s.mechanism == p11.CKM_RSA_X_509 {
+		return signDataRawRSA(s.context, s.session, s.privateKey, publicRSA(s).(*rsa.PublicKey).Size(), msg, opts)
+	}
+	return signDataRSA(s.context, s.session, s.privateKey, msg, opts)
+}
+
 // Public returns crypto public key.
 func (s *p11Signer) Public() crypto.PublicKey {
 	switch s.keyType {
@@ -67,11 +93,28 @@ func (s *p11Signer) Public() crypto.PublicKey {
 		return publicRSA(s)
 	case crypki.ECDSA:
 		return publicECDSA(s)
+	case crypki.Ed25519:
+		return publicEd25519(s)
+	case crypki.MLDSA:
+		return publicMLDSA(s)
+	case crypki.SLHDSA:
+		panic("SLH-DSA public keys are not supported")
 	default: // RSA is the default
 		return publicRSA(s)
 	}
 }
 
+// publicKey returns the public key of the signer. The p11Signer raises the PKCS#11 errors reading its
+// public key as panics, which are returned as errors.
+func publicKey(signer crypto.Signer) (pub crypto.PublicKey, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pub, err = nil, fmt.Errorf("unable to read public key: %v", r)
+		}
+	}()
+	return signer.Public(), nil
+}
+
 // signAlgorithm returns the signature algorithm of signer.
 func (s *p11Signer) signAlgorithm() crypki.PublicKeyAlgorithm {
 	return s.keyType
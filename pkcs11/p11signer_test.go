@@ -2,13 +2,20 @@ package pkcs11
 
 import (
 	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/asn1"
 	"errors"
+	"math/big"
+	"strings"
 	"testing"
+	"unsafe"
 
 	"github.com/golang/mock/gomock"
 	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
 	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
 )
 
@@ -48,6 +55,11 @@ func TestSign(t *testing.T) {
 			opt:         crypto.SHA512,
 			expectError: false,
 		},
+		"good_SHA3_256": {
+			data:        []byte("good"),
+			opt:         crypto.SHA3_256,
+			expectError: false,
+		},
 		"bad_opt": {
 			data:        []byte("not supported hash function"),
 			opt:         crypto.MD5,
@@ -93,7 +105,7 @@ func TestSign(t *testing.T) {
 			defer mockctrl.Finish()
 
 			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
-			signer := &p11Signer{mockCtx, 0, 0, 0, 1}
+			signer := &p11Signer{mockCtx, 0, 0, 0, 1, 0}
 
 			mockCtx.EXPECT().
 				SignInit(gomock.Any(), []*p11.Mechanism{p11.NewMechanism(p11.CKM_RSA_PKCS, nil)}, gomock.Any()).
@@ -140,3 +152,311 @@ func TestSign(t *testing.T) {
 	}
 
 }
+
+func TestSignPSS(t *testing.T) {
+	t.Parallel()
+
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	testcases := map[string]struct {
+		opts        *rsa.PSSOptions
+		expectError bool
+	}{
+		"good_SHA256_EqualsHash": {
+			opts: &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash},
+		},
+		"good_SHA384_EqualsHash": {
+			opts: &rsa.PSSOptions{Hash: crypto.SHA384, SaltLength: rsa.PSSSaltLengthEqualsHash},
+		},
+		"good_SHA512_explicit_salt": {
+			opts: &rsa.PSSOptions{Hash: crypto.SHA512, SaltLength: 20},
+		},
+		"bad_auto_salt": {
+			opts:        &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthAuto},
+			expectError: true,
+		},
+		"bad_hash": {
+			opts:        &rsa.PSSOptions{Hash: crypto.MD5, SaltLength: rsa.PSSSaltLengthEqualsHash},
+			expectError: true,
+		},
+	}
+
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			signer := &p11Signer{mockCtx, 0, 0, 0, 1, 0}
+
+			mockCtx.EXPECT().
+				SignInit(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, mech []*p11.Mechanism, _ interface{}) error {
+					if len(mech) != 1 || mech[0].Mechanism != p11.CKM_RSA_PKCS_PSS {
+						t.Errorf("unexpected mechanism: %+v", mech)
+					}
+					return nil
+				}).
+				AnyTimes()
+
+			mockCtx.EXPECT().
+				Sign(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, digest []byte) ([]byte, error) {
+					return rsa.SignPSS(rand.Reader, rsaPrivateKey, tt.opts.Hash, digest, tt.opts)
+				}).
+				AnyTimes()
+
+			h := crypto.SHA256.New()
+			if tt.opts.Hash.Available() {
+				h = tt.opts.Hash.New()
+			}
+			h.Write([]byte("good"))
+			digest := h.Sum(nil)
+
+			got, err := signer.Sign(rand.Reader, digest, tt.opts)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, but got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := rsa.VerifyPSS(&rsaPrivateKey.PublicKey, tt.opts.Hash, digest, got, tt.opts); err != nil {
+				t.Errorf("Failed to verify signature: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignEd25519(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	message := []byte("good")
+	digest := sha512.Sum512(message)
+
+	testcases := map[string]struct {
+		data        []byte
+		opts        crypto.SignerOpts
+		expectPH    bool
+		expectError bool
+	}{
+		"good_Ed25519ph": {
+			data:     digest[:],
+			opts:     &ed25519.Options{Hash: crypto.SHA512},
+			expectPH: true,
+		},
+		"good_Ed25519ph_hash_opts": {
+			data:     digest[:],
+			opts:     crypto.SHA512,
+			expectPH: true,
+		},
+		"good_pure_Ed25519": {
+			data: message,
+			opts: crypto.Hash(0),
+		},
+		"bad_Ed25519ph_digest_length": {
+			data:        digest[:32],
+			opts:        &ed25519.Options{Hash: crypto.SHA512},
+			expectError: true,
+		},
+		"bad_hash": {
+			data:        digest[:32],
+			opts:        crypto.SHA256,
+			expectError: true,
+		},
+		"good_Ed25519ph_context": {
+			data:     digest[:],
+			opts:     &ed25519.Options{Hash: crypto.SHA512, Context: "ctx"},
+			expectPH: true,
+		},
+		"good_Ed25519ctx": {
+			data: message,
+			opts: &ed25519.Options{Context: "ctx"},
+		},
+		"bad_context_too_long": {
+			data:        digest[:],
+			opts:        &ed25519.Options{Hash: crypto.SHA512, Context: strings.Repeat("c", maxEd25519ContextSize+1)},
+			expectError: true,
+		},
+	}
+
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			signer := &p11Signer{mockCtx, 0, 0, 0, crypki.Ed25519, 0}
+
+			var prehash bool
+			var context string
+			mockCtx.EXPECT().
+				SignInit(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, mech []*p11.Mechanism, _ interface{}) error {
+					if len(mech) != 1 || mech[0].Mechanism != ckmEdDSA {
+						t.Errorf("unexpected mechanism: %+v", mech)
+					}
+					// The phFlag is the first byte of CK_EDDSA_PARAMS, which are omitted for pure Ed25519.
+					if len(mech[0].Parameter) == 0 {
+						return nil
+					}
+					params := (*eddsaParams)(unsafe.Pointer(&mech[0].Parameter[0]))
+					prehash = params.phFlag == 1
+					if params.contextDataLen > 0 {
+						context = string((*[maxEd25519ContextSize]byte)(params.contextData)[:params.contextDataLen])
+					}
+					return nil
+				}).
+				AnyTimes()
+
+			mockCtx.EXPECT().
+				Sign(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, data []byte) ([]byte, error) {
+					if prehash {
+						return priv.Sign(nil, data, &ed25519.Options{Hash: crypto.SHA512, Context: context})
+					}
+					return priv.Sign(nil, data, &ed25519.Options{Context: context})
+				}).
+				AnyTimes()
+
+			got, err := signer.Sign(rand.Reader, tt.data, tt.opts)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, but got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if prehash != tt.expectPH {
+				t.Errorf("got prehash %v, want %v", prehash, tt.expectPH)
+			}
+			var wantContext string
+			if o, ok := tt.opts.(*ed25519.Options); ok {
+				wantContext = o.Context
+			}
+			if context != wantContext {
+				t.Errorf("got context %q, want %q", context, wantContext)
+			}
+			if !tt.expectPH {
+				if err := ed25519.VerifyWithOptions(pub, tt.data, got, &ed25519.Options{Context: wantContext}); err != nil {
+					t.Errorf("Failed to verify Ed25519 signature: %v", err)
+				}
+				return
+			}
+			if err := ed25519.VerifyWithOptions(pub, tt.data, got, &ed25519.Options{Hash: crypto.SHA512, Context: wantContext}); err != nil {
+				t.Errorf("Failed to verify Ed25519ph signature: %v", err)
+			}
+			if ed25519.Verify(pub, tt.data, got) {
+				t.Error("Ed25519ph signature verifies as pure Ed25519")
+			}
+		})
+	}
+}
+
+func TestPublicEd25519(t *testing.T) {
+	t.Parallel()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	der, err := asn1.Marshal([]byte(pub))
+	if err != nil {
+		t.Fatalf("Failed to marshal EC point: %v", err)
+	}
+	for name, point := range map[string][]byte{"der": der, "raw": pub} {
+		mockctrl := gomock.NewController(t)
+		mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+		mockCtx.EXPECT().GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*p11.Attribute{p11.NewAttribute(p11.CKA_EC_POINT, point)}, nil)
+		signer := &p11Signer{mockCtx, 0, 0, 0, crypki.Ed25519, 0}
+		if got, ok := signer.Public().(ed25519.PublicKey); !ok || !got.Equal(pub) {
+			t.Errorf("%s: got public key %v, want %v", name, signer.Public(), pub)
+		}
+		mockctrl.Finish()
+	}
+}
+
+func TestSignRawRSA(t *testing.T) {
+	t.Parallel()
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	testcases := map[string]struct {
+		opt         crypto.SignerOpts
+		expectError bool
+	}{
+		"good_SHA256": {opt: crypto.SHA256},
+		"good_SHA512": {opt: crypto.SHA512},
+		"bad_opt":     {opt: crypto.MD5, expectError: true},
+		"bad_PSS":     {opt: &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}, expectError: true},
+	}
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			signer := &p11Signer{mockCtx, 0, 0, 0, crypki.RSA, p11.CKM_RSA_X_509}
+
+			mockCtx.EXPECT().
+				GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]*p11.Attribute{
+					p11.NewAttribute(p11.CKA_MODULUS, rsaPrivateKey.N.Bytes()),
+					p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, big.NewInt(int64(rsaPrivateKey.E)).Bytes()),
+				}, nil).
+				AnyTimes()
+			// The configured mechanism must be used instead of CKM_RSA_PKCS.
+			mockCtx.EXPECT().
+				SignInit(gomock.Any(), []*p11.Mechanism{p11.NewMechanism(p11.CKM_RSA_X_509, nil)}, gomock.Any()).
+				Return(nil).
+				AnyTimes()
+			mockCtx.EXPECT().
+				Sign(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, em []byte) ([]byte, error) {
+					// Raw RSA: em^d mod n, left padded to the modulus size.
+					s := new(big.Int).Exp(new(big.Int).SetBytes(em), rsaPrivateKey.D, rsaPrivateKey.N).Bytes()
+					return append(make([]byte, rsaPrivateKey.Size()-len(s)), s...), nil
+				}).
+				AnyTimes()
+
+			h := tt.opt.HashFunc()
+			var digest []byte
+			if h.Available() {
+				hh := h.New()
+				hh.Write([]byte("good"))
+				digest = hh.Sum(nil)
+			} else {
+				digest = []byte("not supported hash function")
+			}
+			got, err := signer.Sign(rand.Reader, digest, tt.opt)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, but got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := rsa.VerifyPKCS1v15(&rsaPrivateKey.PublicKey, h, digest, got); err != nil {
+				t.Errorf("failed to verify signature: %v", err)
+			}
+		})
+	}
+}
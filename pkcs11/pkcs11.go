@@ -28,6 +28,12 @@ type PKCS11Ctx interface {
 	GetSlotList(tokenPresent bool) ([]uint, error)
 	GetSlotInfo(slotID uint) (p11.SlotInfo, error)
 	GetTokenInfo(slotID uint) (p11.TokenInfo, error)
+	GetMechanismList(slotID uint) ([]*p11.Mechanism, error)
+	GetMechanismInfo(slotID uint, m []*p11.Mechanism) (p11.MechanismInfo, error)
+	GenerateKeyPair(sh p11.SessionHandle, m []*p11.Mechanism, public, private []*p11.Attribute) (p11.ObjectHandle, p11.ObjectHandle, error)
+	DestroyObject(sh p11.SessionHandle, oh p11.ObjectHandle) error
+	Finalize() error
+	Destroy()
 }
 
 // initPKCS11Context initializes PKCS11 context
@@ -42,7 +48,10 @@ func initPKCS11Context(modulePath string) (*p11.Ctx, error) {
 	return context, err
 }
 
-func getKey(context PKCS11Ctx, session p11.SessionHandle, label string, keyType uint) (p11.ObjectHandle, error) {
+// getKey returns the key object of the class with the label, and with the CKA_ID if id is not nil.
+// The key objects sharing a label are told apart by their CKA_ID: a lookup by CKA_ID fails if it
+// matches several objects.
+func getKey(context PKCS11Ctx, session p11.SessionHandle, label string, id []byte, keyType uint) (p11.ObjectHandle, error) {
 	var noKey p11.ObjectHandle
 	if keyType != p11.CKO_PRIVATE_KEY && keyType != p11.CKO_PUBLIC_KEY {
 		return noKey, fmt.Errorf("not supported keyType: %v", keyType)
@@ -51,13 +60,22 @@ func getKey(context PKCS11Ctx, session p11.SessionHandle, label string, keyType
 		p11.NewAttribute(p11.CKA_CLASS, keyType),
 		p11.NewAttribute(p11.CKA_LABEL, label),
 	}
+	if id != nil {
+		template = append(template, p11.NewAttribute(p11.CKA_ID, id))
+	}
 	objs, err := findObjects(context, session, template)
 	if err != nil {
 		return noKey, err
 	}
 	if len(objs) == 0 {
+		if id != nil {
+			return noKey, fmt.Errorf("key with label %q and CKA_ID %x not found", label, id)
+		}
 		return noKey, errors.New("key not found")
 	}
+	if id != nil && len(objs) > 1 {
+		return noKey, fmt.Errorf("several keys with label %q and CKA_ID %x found", label, id)
+	}
 	return objs[0], nil
 }
 
@@ -0,0 +1,102 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package pkcs11
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+// mockObject is a key object of a mock token.
+type mockObject struct {
+	handle p11.ObjectHandle
+	class  uint
+	label  string
+	id     []byte
+}
+
+// matches returns true if the object has all the attributes of the template.
+func (o mockObject) matches(template []*p11.Attribute) bool {
+	for _, a := range template {
+		var value []byte
+		switch a.Type {
+		case p11.CKA_CLASS:
+			value = p11.NewAttribute(p11.CKA_CLASS, o.class).Value
+		case p11.CKA_LABEL:
+			value = []byte(o.label)
+		case p11.CKA_ID:
+			value = o.id
+		default:
+			return false
+		}
+		if !bytes.Equal(value, a.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetKey(t *testing.T) {
+	t.Parallel()
+	var objects []mockObject
+	for i, id := range [][]byte{{1}, {2}, {2}} {
+		objects = append(objects,
+			mockObject{p11.ObjectHandle(2*i + 1), p11.CKO_PRIVATE_KEY, "shared", id},
+			mockObject{p11.ObjectHandle(2*i + 2), p11.CKO_PUBLIC_KEY, "shared", id})
+	}
+	objects = append(objects, mockObject{7, p11.CKO_PRIVATE_KEY, "unique", []byte{3}})
+	testcases := map[string]struct {
+		label          string
+		id             []byte
+		class          uint
+		expectedHandle p11.ObjectHandle
+		expectError    bool
+	}{
+		"private-key-by-id": {label: "shared", id: []byte{1}, class: p11.CKO_PRIVATE_KEY, expectedHandle: 1},
+		"public-key-by-id":  {label: "shared", id: []byte{1}, class: p11.CKO_PUBLIC_KEY, expectedHandle: 2},
+		"unknown-id":        {label: "shared", id: []byte{4}, class: p11.CKO_PRIVATE_KEY, expectError: true},
+		"ambiguous-id":      {label: "shared", id: []byte{2}, class: p11.CKO_PRIVATE_KEY, expectError: true},
+		"id-of-other-label": {label: "unique", id: []byte{1}, class: p11.CKO_PRIVATE_KEY, expectError: true},
+		"label-only":        {label: "unique", class: p11.CKO_PRIVATE_KEY, expectedHandle: 7},
+		"unknown-label":     {label: "none", class: p11.CKO_PRIVATE_KEY, expectError: true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			var template []*p11.Attribute
+			mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(sh p11.SessionHandle, temp []*p11.Attribute) error {
+					template = temp
+					return nil
+				}).AnyTimes()
+			mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(sh p11.SessionHandle, max int) ([]p11.ObjectHandle, bool, error) {
+					var handles []p11.ObjectHandle
+					for _, o := range objects {
+						if o.matches(template) && len(handles) < max {
+							handles = append(handles, o.handle)
+						}
+					}
+					return handles, false, nil
+				}).AnyTimes()
+			mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+
+			handle, err := getKey(mockCtx, 0, tt.label, tt.id, tt.class)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("in test %v: got err %v, expectError %v", label, err, tt.expectError)
+			}
+			if err == nil && handle != tt.expectedHandle {
+				t.Errorf("in test %v: got handle %d, want %d", label, handle, tt.expectedHandle)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/mldsa"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+)
+
+// The ML-DSA and SLH-DSA mechanisms and attributes of PKCS#11 v3.2, which are not defined by the pkcs11 package.
+const (
+	ckmMLDSAKeyPairGen  = 0x1c
+	ckmMLDSA            = 0x1d
+	ckmHashMLDSA        = 0x1f
+	ckmSLHDSAKeyPairGen = 0x2d
+	ckmSLHDSA           = 0x2e
+	ckmHashSLHDSA       = 0x34
+
+	ckaParameterSet = 0x61d
+
+	ckpMLDSA44 = 0x1
+	ckpMLDSA65 = 0x2
+	ckpMLDSA87 = 0x3
+)
+
+// pqcMechanisms maps the post-quantum key types to their pure and pre-hash signing mechanisms.
+var pqcMechanisms = map[crypki.PublicKeyAlgorithm]struct{ pure, prehash uint }{
+	crypki.MLDSA:  {ckmMLDSA, ckmHashMLDSA},
+	crypki.SLHDSA: {ckmSLHDSA, ckmHashSLHDSA},
+}
+
+// pqcHashes maps the hash functions of the digests signed by the pre-hash post-quantum mechanisms to the
+// PKCS#11 digest mechanisms. The 224-bit hashes are too short for the security categories of the keys.
+var pqcHashes = map[crypto.Hash]uint{
+	crypto.SHA256:   p11.CKM_SHA256,
+	crypto.SHA384:   p11.CKM_SHA384,
+	crypto.SHA512:   p11.CKM_SHA512,
+	crypto.SHA3_256: p11.CKM_SHA3_256,
+	crypto.SHA3_384: p11.CKM_SHA3_384,
+	crypto.SHA3_512: p11.CKM_SHA3_512,
+}
+
+// hashSignAdditionalContext mirrors CK_HASH_SIGN_ADDITIONAL_CONTEXT, without a context. The hedge variant
+// zero is CKH_HEDGE_PREFERRED.
+type hashSignAdditionalContext struct {
+	hedgeVariant uint
+	context      unsafe.Pointer
+	contextLen   uint
+	hash         uint
+}
+
+// mldsaParameters maps the CKA_PARAMETER_SET values of the ML-DSA keys to their parameter sets.
+var mldsaParameters = map[uint]func() mldsa.Parameters{
+	ckpMLDSA44: mldsa.MLDSA44,
+	ckpMLDSA65: mldsa.MLDSA65,
+	ckpMLDSA87: mldsa.MLDSA87,
+}
+
+func publicMLDSA(s *p11Signer) crypto.PublicKey {
+	attrTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_VALUE, nil),
+		p11.NewAttribute(ckaParameterSet, nil),
+	}
+	attr, err := s.context.GetAttributeValue(s.session, s.publicKey, attrTemplate)
+	if err != nil {
+		panic("Error returning public key: " + err.Error())
+	}
+	var value []byte
+	var params func() mldsa.Parameters
+	for _, a := range attr {
+		switch a.Type {
+		case p11.CKA_VALUE:
+			value = a.Value
+		case ckaParameterSet:
+			// CKA_PARAMETER_SET is a CK_ULONG in the byte order of the host.
+			if len(a.Value) == int(unsafe.Sizeof(uint(0))) {
+				params = mldsaParameters[*(*uint)(unsafe.Pointer(&a.Value[0]))]
+			}
+		}
+	}
+	if value == nil || params == nil {
+		panic("unable to retrieve ML-DSA public key and/or parameter set")
+	}
+	pub, err := mldsa.NewPublicKey(params(), value)
+	if err != nil {
+		panic("unable to parse ML-DSA public key: " + err.Error())
+	}
+	return pub
+}
+
+// signDataPQC signs the message with the pure mechanism of the post-quantum key type if opts has no hash
+// function, or the digest with its pre-hash mechanism, i.e. HashML-DSA or HashSLH-DSA, otherwise.
+func signDataPQC(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.ObjectHandle, keyType crypki.PublicKeyAlgorithm, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+	mechs, ok := pqcMechanisms[keyType]
+	if !ok {
+		return nil, fmt.Errorf("key type %d is not post-quantum", keyType)
+	}
+	mech := p11.NewMechanism(mechs.pure, nil)
+	if hash := opts.HashFunc(); hash != crypto.Hash(0) {
+		digestMech, ok := pqcHashes[hash]
+		if !ok {
+			return nil, errors.New("Unsupported hash algorithm")
+		}
+		if len(data) != hash.Size() {
+			return nil, fmt.Errorf("invalid %v digest length: got %d bytes, want %d", hash, len(data), hash.Size())
+		}
+		params := hashSignAdditionalContext{hash: digestMech}
+		mech = p11.NewMechanism(mechs.prehash, (*[unsafe.Sizeof(params)]byte)(unsafe.Pointer(&params))[:])
+	}
+	if err := ctx.SignInit(session, []*p11.Mechanism{mech}, hsmPrivateObject); err != nil {
+		return nil, err
+	}
+	return ctx.Sign(session, data)
+}
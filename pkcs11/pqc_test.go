@@ -0,0 +1,153 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/mldsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+	"unsafe"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+func TestSignPQC(t *testing.T) {
+	t.Parallel()
+
+	message := []byte("good")
+	sha256Digest := sha256.Sum256(message)
+	sha512Digest := sha512.Sum512(message)
+
+	testcases := map[string]struct {
+		keyType     crypki.PublicKeyAlgorithm
+		data        []byte
+		opts        crypto.SignerOpts
+		expectMech  uint
+		expectHash  uint
+		expectError bool
+	}{
+		"good_pure_MLDSA": {
+			keyType:    crypki.MLDSA,
+			data:       message,
+			opts:       crypto.Hash(0),
+			expectMech: ckmMLDSA,
+		},
+		"good_HashMLDSA_SHA256": {
+			keyType:    crypki.MLDSA,
+			data:       sha256Digest[:],
+			opts:       crypto.SHA256,
+			expectMech: ckmHashMLDSA,
+			expectHash: p11.CKM_SHA256,
+		},
+		"good_HashMLDSA_SHA512": {
+			keyType:    crypki.MLDSA,
+			data:       sha512Digest[:],
+			opts:       crypto.SHA512,
+			expectMech: ckmHashMLDSA,
+			expectHash: p11.CKM_SHA512,
+		},
+		"good_pure_SLHDSA": {
+			keyType:    crypki.SLHDSA,
+			data:       message,
+			opts:       crypto.Hash(0),
+			expectMech: ckmSLHDSA,
+		},
+		"good_HashSLHDSA_SHA3_256": {
+			keyType:    crypki.SLHDSA,
+			data:       sha256Digest[:],
+			opts:       crypto.SHA3_256,
+			expectMech: ckmHashSLHDSA,
+			expectHash: p11.CKM_SHA3_256,
+		},
+		"bad_hash_SHA224": {
+			keyType:     crypki.MLDSA,
+			data:        sha256Digest[:28],
+			opts:        crypto.SHA224,
+			expectError: true,
+		},
+		"bad_digest_length": {
+			keyType:     crypki.SLHDSA,
+			data:        sha256Digest[:],
+			opts:        crypto.SHA512,
+			expectError: true,
+		},
+	}
+
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			signer := &p11Signer{mockCtx, 0, 0, 0, tt.keyType, 0}
+
+			var gotMech, gotHash uint
+			mockCtx.EXPECT().
+				SignInit(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, mech []*p11.Mechanism, _ interface{}) error {
+					if len(mech) != 1 {
+						t.Fatalf("unexpected mechanisms: %+v", mech)
+					}
+					gotMech = mech[0].Mechanism
+					// The hash is a member of CK_HASH_SIGN_ADDITIONAL_CONTEXT, which is omitted by the pure mechanisms.
+					if len(mech[0].Parameter) != 0 {
+						gotHash = (*hashSignAdditionalContext)(unsafe.Pointer(&mech[0].Parameter[0])).hash
+					}
+					return nil
+				}).
+				AnyTimes()
+			mockCtx.EXPECT().
+				Sign(gomock.Any(), gomock.Any()).
+				Return([]byte("signature"), nil).
+				AnyTimes()
+
+			got, err := signer.Sign(nil, tt.data, tt.opts)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, but got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotMech != tt.expectMech || gotHash != tt.expectHash {
+				t.Errorf("got mechanism %#x with hash %#x, want %#x with hash %#x", gotMech, gotHash, tt.expectMech, tt.expectHash)
+			}
+			if !bytes.Equal(got, []byte("signature")) {
+				t.Errorf("got signature %q, want the one of the HSM", got)
+			}
+		})
+	}
+}
+
+func TestPublicMLDSA(t *testing.T) {
+	t.Parallel()
+
+	key, err := mldsa.GenerateKey(mldsa.MLDSA65())
+	if err != nil {
+		t.Fatalf("Failed to generate ML-DSA key: %v", err)
+	}
+	pub := key.PublicKey()
+
+	mockctrl := gomock.NewController(t)
+	defer mockctrl.Finish()
+	mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+	mockCtx.EXPECT().GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]*p11.Attribute{
+			p11.NewAttribute(p11.CKA_VALUE, pub.Bytes()),
+			p11.NewAttribute(ckaParameterSet, uint(ckpMLDSA65)),
+		}, nil)
+	signer := &p11Signer{mockCtx, 0, 0, 0, crypki.MLDSA, 0}
+	if got, ok := signer.Public().(*mldsa.PublicKey); !ok || !got.Equal(pub) {
+		t.Errorf("got public key %v, want %v", got, pub)
+	}
+}
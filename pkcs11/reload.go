@@ -0,0 +1,53 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yahoo/crypki"
+)
+
+// ReloadSessions replaces the signer pool of the key with one of freshly opened sessions, and closes the
+// sessions of the previous pool once the signing requests using them are done. The signing requests are
+// served throughout, by the new pool as soon as it is opened. It waits for the previous sessions to be
+// closed until ctx is done, after which they are still closed in the background, and returns the error of
+// ctx. The sessions of threshold keys cannot be reloaded.
+func (s *signer) ReloadSessions(ctx context.Context, keyIdentifier string) (int, error) {
+	s.genMu.Lock()
+	pool, ok := s.getPool(keyIdentifier)
+	if !ok {
+		s.genMu.Unlock()
+		return 0, &crypki.RequestError{Err: fmt.Errorf("unknown key identifier %q", keyIdentifier)}
+	}
+	old, ok := pool.(*SignerPool)
+	if !ok {
+		s.genMu.Unlock()
+		return 0, &crypki.RequestError{Err: fmt.Errorf("sessions of key %q cannot be reloaded", keyIdentifier)}
+	}
+	fresh, err := old.reopen()
+	if err != nil {
+		s.genMu.Unlock()
+		return 0, fmt.Errorf("unable to reopen sessions of key %q: %v", keyIdentifier, err)
+	}
+	s.mu.Lock()
+	s.sPool[keyIdentifier] = fresh
+	s.mu.Unlock()
+	closed := old.retire(fresh)
+	s.genMu.Unlock()
+
+	select {
+	case <-closed:
+		log.Printf("sessions of key %q reloaded", keyIdentifier)
+		return cap(fresh.signers), nil
+	case <-ctx.Done():
+		go func() {
+			<-closed
+			log.Printf("previous sessions of key %q closed", keyIdentifier)
+		}()
+		return cap(fresh.signers), ctx.Err()
+	}
+}
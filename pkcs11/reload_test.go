@@ -0,0 +1,177 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+func TestReloadSessions(t *testing.T) {
+	t.Parallel()
+	mockctrl := gomock.NewController(t)
+	defer mockctrl.Finish()
+	pinPath := writePinFile(t, "1234")
+	defer os.Remove(pinPath)
+
+	// Each session opened gets a new handle, and the handles of the closed sessions cannot be used anymore.
+	var mu sync.Mutex
+	var lastSession p11.SessionHandle
+	closed := make(map[p11.SessionHandle]bool)
+	var signedWith []p11.SessionHandle
+	mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+	mockCtx.EXPECT().OpenSession(uint(1), gomock.Any()).DoAndReturn(func(uint, uint) (p11.SessionHandle, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lastSession++
+		return lastSession, nil
+	}).AnyTimes()
+	mockCtx.EXPECT().CloseSession(gomock.Any()).DoAndReturn(func(session p11.SessionHandle) error {
+		mu.Lock()
+		defer mu.Unlock()
+		closed[session] = true
+		return nil
+	}).AnyTimes()
+	mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, "1234").Return(nil).AnyTimes()
+	mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).Return([]p11.ObjectHandle{1}, false, nil).AnyTimes()
+	mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().SignInit(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().Sign(gomock.Any(), gomock.Any()).DoAndReturn(func(session p11.SessionHandle, _ []byte) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed[session] {
+			return nil, p11.Error(p11.CKR_SESSION_HANDLE_INVALID)
+		}
+		signedWith = append(signedWith, session)
+		return []byte("signature"), nil
+	}).AnyTimes()
+
+	s := &signer{
+		x509CACerts: make(map[string]*x509.Certificate),
+		sPool:       make(map[string]sPool),
+		modules:     map[string]*module{"": {context: mockCtx, slotPins: make(map[uint]string)}},
+	}
+	keys := []config.KeyConfig{{Identifier: "key1", SlotNumber: 1, UserPinPath: pinPath, KeyLabel: "foo", SessionPoolSize: 2, KeyType: crypki.RSA}}
+	if err := s.loadKeys(keys, nil, "", nil); err != nil {
+		t.Fatalf("unable to load keys: %v", err)
+	}
+	digest := sha256.Sum256([]byte("good"))
+	// sign signs the digest as the signing requests do, with a session of the current pool of the key.
+	sign := func() error {
+		pool, _ := s.getPool("key1")
+		signer := pool.get()
+		defer pool.put(signer)
+		_, err := signer.Sign(nil, digest[:], crypto.SHA256)
+		return err
+	}
+	if err := sign(); err != nil {
+		t.Fatalf("unable to sign before reloading the sessions: %v", err)
+	}
+	opened := lastSession
+
+	// The reload waits for the session in use by an in-flight signing request to be closed.
+	pool, _ := s.getPool("key1")
+	inFlight := pool.get()
+	reloaded := make(chan error, 1)
+	go func() {
+		n, err := s.ReloadSessions(context.Background(), "key1")
+		if err == nil && n != 2 {
+			t.Errorf("got %d sessions reloaded, want 2", n)
+		}
+		reloaded <- err
+	}()
+	select {
+	case err := <-reloaded:
+		t.Fatalf("sessions reloaded while a signing request was in flight, err: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	mu.Lock()
+	if closed[inFlight.(*p11Signer).session] {
+		t.Error("session of an in-flight signing request closed")
+	}
+	mu.Unlock()
+	// Meanwhile, the signing requests are served by the new sessions, and keys can be generated.
+	if err := sign(); err != nil {
+		t.Errorf("unable to sign while reloading the sessions: %v", err)
+	}
+	locked := make(chan struct{})
+	go func() {
+		s.genMu.Lock()
+		s.genMu.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("key generation blocked while reloading the sessions")
+	}
+	pool.put(inFlight)
+	if err := <-reloaded; err != nil {
+		t.Fatalf("unable to reload sessions: %v", err)
+	}
+
+	mu.Lock()
+	for session := p11.SessionHandle(1); session <= opened; session++ {
+		if !closed[session] {
+			t.Errorf("session %d of the previous pool not closed", session)
+		}
+	}
+	mu.Unlock()
+	for i := 0; i < 3; i++ {
+		if err := sign(); err != nil {
+			t.Fatalf("unable to sign after reloading the sessions: %v", err)
+		}
+	}
+	// A request holding the previous pool is served by the new one.
+	signer := pool.get()
+	pool.put(signer)
+	if session := signer.(*p11Signer).session; session <= opened {
+		t.Errorf("got session %d of the previous pool, want a new session", session)
+	}
+	mu.Lock()
+	for _, session := range signedWith[1:] {
+		if session <= opened {
+			t.Errorf("got signature with session %d of the previous pool, want a new session", session)
+		}
+	}
+	mu.Unlock()
+
+	// The reload returns once ctx is done, and the session in use is closed once put back.
+	pool, _ = s.getPool("key1")
+	inFlight = pool.get()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := s.ReloadSessions(ctx, "key1"); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	pool.put(inFlight)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		mu.Lock()
+		done := closed[inFlight.(*p11Signer).session]
+		mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("session of the previous pool not closed after being put back")
+		}
+	}
+
+	if _, err := s.ReloadSessions(context.Background(), "unknown"); err == nil {
+		t.Error("expected error reloading the sessions of an unknown key")
+	}
+}
@@ -4,17 +4,31 @@ import (
 	"crypto"
 	"crypto/rsa"
 	"errors"
+	"fmt"
 	"math/big"
 
 	p11 "github.com/miekg/pkcs11"
 )
 
 // prefixes copied from https://github.com/golang/go/blob/master/src/crypto/rsa/pkcs1v15.go#L208-L217
+// The SHA3 prefixes are the DigestInfo of the id-sha3-* OIDs of RFC 8702.
 var hashPrefixes = map[crypto.Hash][]byte{
-	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
-	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
-	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
-	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+	crypto.SHA1:     {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA256:   {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384:   {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512:   {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+	crypto.SHA3_256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x08, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA3_384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x09, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA3_512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x0a, 0x05, 0x00, 0x04, 0x40},
+}
+
+// pssHashParams maps a hash function to the PKCS#11 hash mechanism and mask generation
+// function used in CK_RSA_PKCS_PSS_PARAMS.
+var pssHashParams = map[crypto.Hash]struct{ hashAlg, mgf uint }{
+	crypto.SHA1:   {p11.CKM_SHA_1, p11.CKG_MGF1_SHA1},
+	crypto.SHA256: {p11.CKM_SHA256, p11.CKG_MGF1_SHA256},
+	crypto.SHA384: {p11.CKM_SHA384, p11.CKG_MGF1_SHA384},
+	crypto.SHA512: {p11.CKM_SHA512, p11.CKG_MGF1_SHA512},
 }
 
 func publicRSA(s *p11Signer) crypto.PublicKey {
@@ -53,18 +67,28 @@ func signDataRSA(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.
 	privateKeyHandle := hsmPrivateObject
 
 	var buf []byte
-	// We only support SHA1, SHA256, SHA384 and SHA512 hash digest algorithms.
+	// We only support SHA1, SHA256, SHA384, SHA512 and SHA3-256, SHA3-384 and SHA3-512 hash digest algorithms.
 	// If the data is the digest from one of those algorithms,
 	// we need to prepend the hash identifier before generating
 	// the signature for the buffer.
 	hash := opts.HashFunc()
 	mech := make([]*p11.Mechanism, 1)
-	switch hash {
-	case crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512:
-		buf = append(hashPrefixes[hash], data...)
-		mech[0] = p11.NewMechanism(p11.CKM_RSA_PKCS, nil)
-	default:
-		return nil, errors.New("Unsupported hash algorithm")
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		// RSA-PSS signs the digest as is, the hash algorithm is passed in the mechanism parameters.
+		params, err := pssParams(hash, pssOpts)
+		if err != nil {
+			return nil, err
+		}
+		buf = data
+		mech[0] = p11.NewMechanism(p11.CKM_RSA_PKCS_PSS, params)
+	} else {
+		switch hash {
+		case crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512, crypto.SHA3_256, crypto.SHA3_384, crypto.SHA3_512:
+			buf = append(hashPrefixes[hash], data...)
+			mech[0] = p11.NewMechanism(p11.CKM_RSA_PKCS, nil)
+		default:
+			return nil, errors.New("Unsupported hash algorithm")
+		}
 	}
 
 	err := ctx.SignInit(session, mech, privateKeyHandle)
@@ -77,3 +101,66 @@ func signDataRSA(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.
 	}
 	return signed, err
 }
+
+// pssParams returns the CK_RSA_PKCS_PSS_PARAMS for the given hash function and PSS options.
+func pssParams(hash crypto.Hash, opts *rsa.PSSOptions) ([]byte, error) {
+	hp, ok := pssHashParams[hash]
+	if !ok {
+		return nil, errors.New("Unsupported hash algorithm")
+	}
+	var saltLength int
+	switch {
+	case opts.SaltLength == rsa.PSSSaltLengthEqualsHash:
+		saltLength = hash.Size()
+	case opts.SaltLength > 0:
+		saltLength = opts.SaltLength
+	default:
+		return nil, fmt.Errorf("unsupported PSS salt length: %d", opts.SaltLength)
+	}
+	return p11.NewPSSParams(hp.hashAlg, hp.mgf, uint(saltLength)), nil
+}
+
+// signDataRawRSA signs the digest with the raw RSA mechanism CKM_RSA_X_509, for HSMs that do not
+// support CKM_RSA_PKCS. The digest is PKCS #1 v1.5 padded to the modulus size k in software.
+func signDataRawRSA(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.ObjectHandle, k int, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+	em, err := padPKCS1v15(k, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return signRawRSA(ctx, session, hsmPrivateObject, em)
+}
+
+// padPKCS1v15 returns the PKCS #1 v1.5 encoding of the digest for a modulus size of k bytes.
+func padPKCS1v15(k int, data []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return nil, errors.New("RSA-PSS is not supported by CKM_RSA_X_509")
+	}
+	prefix, ok := hashPrefixes[opts.HashFunc()]
+	if !ok {
+		return nil, errors.New("Unsupported hash algorithm")
+	}
+	if len(data) != opts.HashFunc().Size() {
+		return nil, fmt.Errorf("invalid digest length: got %d bytes, want %d", len(data), opts.HashFunc().Size())
+	}
+	tLen := len(prefix) + len(data)
+	if k < tLen+11 {
+		return nil, errors.New("RSA key too short for the digest")
+	}
+	// EM = 0x00 || 0x01 || PS || 0x00 || T, where PS is 0xff padding (RFC 8017 section 9.2).
+	em := make([]byte, k)
+	em[1] = 1
+	for i := 2; i < k-tLen-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-tLen:], prefix)
+	copy(em[k-len(data):], data)
+	return em, nil
+}
+
+// signRawRSA raises the block to the private exponent of the key with the raw RSA mechanism CKM_RSA_X_509.
+func signRawRSA(ctx PKCS11Ctx, session p11.SessionHandle, hsmPrivateObject p11.ObjectHandle, block []byte) ([]byte, error) {
+	if err := ctx.SignInit(session, []*p11.Mechanism{p11.NewMechanism(p11.CKM_RSA_X_509, nil)}, hsmPrivateObject); err != nil {
+		return nil, err
+	}
+	return ctx.Sign(session, block)
+}
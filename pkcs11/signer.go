@@ -2,6 +2,7 @@ package pkcs11
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/x509"
@@ -12,6 +13,7 @@ import (
 	"log"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/yahoo/crypki"
@@ -20,52 +22,216 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
-// signer implements crypki.CertSign interface.
+// module is an initialized PKCS#11 module.
+type module struct {
+	context PKCS11Ctx
+	// slotPins are the pins of the slots holding configured keys, used to generate new keys.
+	slotPins map[uint]string
+}
+
+// signer implements crypki.CertSign, crypki.PrioritizedCertSign, crypki.ContextCertSign, crypki.KeyGenerator, crypki.MechanismLister,
+// crypki.SessionReloader and crypki.EphemeralSigner interfaces.
 type signer struct {
 	x509CACerts map[string]*x509.Certificate
-	sPool       map[string]sPool
+	// mu guards sPool and x509CACerts, which are updated at runtime when a new key is generated
+	// or a key is loaded after retrying to log in.
+	mu    sync.RWMutex
+	sPool map[string]sPool
+	// modules are the PKCS#11 modules mapped by name, with the module at the default path named "".
+	// genMu serializes key generation and session reloads.
+	modules map[string]*module
+	genMu   sync.Mutex
+	// loginRetry, if set, configures the background loading of the keys whose login fails at startup.
+	// stop is closed by Close to stop the retries, and retries waits for them to stop.
+	loginRetry *LoginRetry
+	stop       chan struct{}
+	retries    sync.WaitGroup
+	// certCache, if set, caches the parsed x509 CA certificates of the keys.
+	certCache *x509cert.CertFileCache
 }
 
-// NewCertSign initializes a CertSign object that interacts with PKCS11 compliant device.
-func NewCertSign(pkcs11ModulePath string, keys []config.KeyConfig, requireX509CACert map[string]bool, hostname string, ips []net.IP) (crypki.CertSign, error) {
-	p11ctx, err := initPKCS11Context(pkcs11ModulePath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to initialize PKCS11 context: %v", err)
-	}
+// NewCertSign initializes a CertSign object that interacts with PKCS11 compliant devices.
+// modulePaths maps the module names referred to by the keys to the paths of the PKCS#11 modules.
+// If loginRetry is nil, it fails if it cannot log in to the slot of a key; otherwise such keys
+// are loaded in the background. If certCache is not nil, the x509 CA certificates are parsed through it.
+func NewCertSign(modulePaths map[string]string, keys []config.KeyConfig, requireX509CACert map[string]bool, hostname string, ips []net.IP, loginRetry *LoginRetry, certCache *x509cert.CertFileCache) (crypki.CertSign, error) {
 	s := &signer{
 		x509CACerts: make(map[string]*x509.Certificate),
 		sPool:       make(map[string]sPool),
+		modules:     make(map[string]*module),
+		loginRetry:  loginRetry,
+		certCache:   certCache,
 	}
 	for _, key := range keys {
+		names := []string{key.Module}
+		for _, share := range key.ThresholdShares {
+			names = append(names, share.Module)
+		}
+		for _, name := range names {
+			if _, ok := s.modules[name]; ok {
+				continue
+			}
+			p11ctx, err := initPKCS11Context(modulePaths[name])
+			if err != nil {
+				s.Close()
+				return nil, fmt.Errorf("unable to initialize PKCS11 context of module %q: %v", name, err)
+			}
+			s.modules[name] = &module{context: p11ctx, slotPins: make(map[uint]string)}
+		}
+	}
+	if err := s.loadKeys(keys, requireX509CACert, hostname, ips); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadKeys initializes the signer pools of the keys in their PKCS#11 modules. If the signer has a loginRetry,
+// the keys whose login fails are loaded in the background instead of failing.
+func (s *signer) loadKeys(keys []config.KeyConfig, requireX509CACert map[string]bool, hostname string, ips []net.IP) error {
+	var pending []config.KeyConfig
+	incorrectPins := make(map[string]string)
+	for _, key := range keys {
+		err := s.loadKey(key, requireX509CACert, hostname, ips)
+		le, ok := err.(*loginError)
+		if !ok || s.loginRetry == nil {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if le.incorrectPin() {
+			incorrectPins[key.Identifier] = keyPins(key)
+		}
+		log.Printf("%v, key %q is unavailable until it is loaded in the background", err, key.Identifier)
+		pending = append(pending, key)
+		if s.loginRetry.Pending != nil {
+			s.loginRetry.Pending(key.Identifier)
+		}
+	}
+	if len(pending) != 0 {
+		s.stop = make(chan struct{})
+		s.retries.Add(1)
+		go s.retryLogin(pending, incorrectPins, requireX509CACert, hostname, ips)
+	}
+	return nil
+}
+
+// loadKey initializes the signer pool of the key in its PKCS#11 module, and its x509 CA cert if required.
+// It returns a *loginError if it fails to log in to the slot of the key.
+func (s *signer) loadKey(key config.KeyConfig, requireX509CACert map[string]bool, hostname string, ips []net.IP) error {
+	var pool sPool
+	if len(key.ThresholdShares) != 0 {
+		var err error
+		pool, err = s.loadThresholdKey(key)
+		if le, ok := err.(*loginError); ok {
+			le.identifier = key.Identifier
+			return le
+		}
+		if err != nil {
+			return fmt.Errorf("unable to initialize threshold key with identifier %q: %v", key.Identifier, err)
+		}
+	} else {
+		m, ok := s.modules[key.Module]
+		if !ok {
+			return fmt.Errorf("unknown PKCS#11 module %q for key with identifier %q", key.Module, key.Identifier)
+		}
 		pin, err := getUserPin(key.UserPinPath)
 		if err != nil {
-			return nil, fmt.Errorf("unable to read user pin for key with identifier %q, pin path: %v, err: %v", key.Identifier, key.UserPinPath, err)
+			return fmt.Errorf("unable to read user pin for key with identifier %q, pin path: %v, err: %v", key.Identifier, key.UserPinPath, err)
+		}
+		mechanism, err := signMechanism(key.Mechanism, key.KeyType)
+		if err != nil {
+			return fmt.Errorf("invalid mechanism for key with identifier %q: %v", key.Identifier, err)
+		}
+		if mechanism != 0 {
+			if err := checkMechanism(m.context, key.SlotNumber, mechanism); err != nil {
+				return fmt.Errorf("invalid mechanism for key with identifier %q: %v", key.Identifier, err)
+			}
 		}
-		pool, err := newSignerPool(p11ctx, key.SessionPoolSize, key.SlotNumber, key.KeyLabel, pin, key.KeyType)
+		objectID, err := key.ObjectID()
 		if err != nil {
-			return nil, fmt.Errorf("unable to initialize key with identifier %q: %v", key.Identifier, err)
+			return fmt.Errorf("invalid key object for key with identifier %q: %v", key.Identifier, err)
 		}
-		s.sPool[key.Identifier] = pool
-		// Initialize x509 CA cert if this key will be used for signing x509 certs.
-		if requireX509CACert[key.Identifier] {
-			cert, err := getX509CACert(key, pool, hostname, ips)
+		pool, err = newSignerPool(m.context, key.SessionPoolSize, key.SlotNumber, key.KeyLabel, objectID, pin, key.KeyType, mechanism)
+		if le, ok := err.(*loginError); ok {
+			le.identifier, le.pinPath = key.Identifier, key.UserPinPath
+			return le
+		}
+		if err != nil {
+			return fmt.Errorf("unable to initialize key with identifier %q: %v", key.Identifier, err)
+		}
+		if key.DeterministicSignatures {
+			signer := pool.get()
+			err := checkDeterministic(signer)
+			pool.put(signer)
 			if err != nil {
-				log.Fatalf("failed to get x509 CA cert for key %q: %v", key.Identifier, err)
+				return fmt.Errorf("key with identifier %q is configured with deterministic signatures: %v", key.Identifier, err)
 			}
-			s.x509CACerts[key.Identifier] = cert
-			log.Printf("x509 CA cert loaded for key %q", key.Identifier)
 		}
+		m.slotPins[key.SlotNumber] = pin
 	}
-	return s, nil
+	// Initialize x509 CA cert if this key will be used for signing x509 certs.
+	var cert *x509.Certificate
+	if requireX509CACert[key.Identifier] {
+		var err error
+		if cert, err = getX509CACert(key, pool, hostname, ips, s.certCache); err != nil {
+			return fmt.Errorf("failed to get x509 CA cert for key %q: %v", key.Identifier, err)
+		}
+		log.Printf("x509 CA cert loaded for key %q", key.Identifier)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cert != nil {
+		s.x509CACerts[key.Identifier] = cert
+	}
+	s.sPool[key.Identifier] = pool
+	return nil
 }
 
-func (s *signer) GetSSHCertSigningKey(keyIdentifier string) ([]byte, error) {
+// Close finalizes the PKCS#11 modules of the signer, which cannot be used afterwards.
+// All modules are finalized even if some of them fail to.
+func (s *signer) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		s.retries.Wait()
+	}
+	var firstErr error
+	for name, m := range s.modules {
+		if err := m.context.Finalize(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("unable to finalize PKCS#11 module %q: %v", name, err)
+		}
+		m.context.Destroy()
+	}
+	return firstErr
+}
+
+// getCACert returns the x509 CA cert of the specified key.
+func (s *signer) getCACert(keyIdentifier string) (*x509.Certificate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cert, ok := s.x509CACerts[keyIdentifier]
+	return cert, ok
+}
+
+// getPool returns the signer pool of the specified key.
+func (s *signer) getPool(keyIdentifier string) (sPool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	pool, ok := s.sPool[keyIdentifier]
+	return pool, ok
+}
+
+func (s *signer) GetSSHCertSigningKey(keyIdentifier string) ([]byte, error) {
+	pool, ok := s.getPool(keyIdentifier)
 	if !ok {
 		return nil, fmt.Errorf("unknown key identifier %q", keyIdentifier)
 	}
 	signer := pool.get()
 	defer pool.put(signer)
+	if _, err := publicKey(signer); err != nil {
+		return nil, fmt.Errorf("key %q: %v", keyIdentifier, err)
+	}
 
 	sshSigner, err := ssh.NewSignerFromSigner(signer)
 	if err != nil {
@@ -74,7 +240,44 @@ func (s *signer) GetSSHCertSigningKey(keyIdentifier string) ([]byte, error) {
 	return ssh.MarshalAuthorizedKey(sshSigner.PublicKey()), nil
 }
 
+func (s *signer) PublicKey(keyIdentifier string) (crypto.PublicKey, error) {
+	pool, ok := s.getPool(keyIdentifier)
+	if !ok {
+		return nil, fmt.Errorf("unknown key identifier %q", keyIdentifier)
+	}
+	signer := pool.get()
+	defer pool.put(signer)
+	if signer.signAlgorithm() == crypki.SLHDSA {
+		// There is no Go type of the SLH-DSA public keys.
+		return nil, fmt.Errorf("public key of SLH-DSA key %q is not supported", keyIdentifier)
+	}
+	pub, err := publicKey(signer)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %v", keyIdentifier, err)
+	}
+	return pub, nil
+}
+
+// PoolUsage returns the number of sessions of the specified key in use and the size of its session pool.
+// Both are zero for keys whose pools are not sized, such as threshold keys.
+func (s *signer) PoolUsage(keyIdentifier string) (inUse, size int, err error) {
+	pool, ok := s.getPool(keyIdentifier)
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown key identifier %q", keyIdentifier)
+	}
+	if p, ok := pool.(interface{ usage() (int, int) }); ok {
+		inUse, size = p.usage()
+	}
+	return inUse, size, nil
+}
+
 func (s *signer) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byte, error) {
+	return s.signSSHCert(context.Background(), cert, keyIdentifier, crypki.PriorityNormal)
+}
+
+// signSSHCert signs the SSH cert with the specified key, waiting for a session of the key with the priority
+// until ctx is done. The cert is not signed if ctx is done by the time a session is available.
+func (s *signer) signSSHCert(ctx context.Context, cert *ssh.Certificate, keyIdentifier string, priority crypki.Priority) ([]byte, error) {
 	const methodName = "SignSSHCert"
 	start := time.Now()
 	var ht int64
@@ -86,17 +289,23 @@ func (s *signer) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byt
 	if cert == nil {
 		return nil, errors.New("%s: cannot sign empty cert")
 	}
-	pool, ok := s.sPool[keyIdentifier]
+	pool, ok := s.getPool(keyIdentifier)
 	if !ok {
 		return nil, fmt.Errorf("unknown key identifier %q", keyIdentifier)
 	}
-	signer := pool.get()
+	signer, err := getSigner(ctx, pool, priority)
+	if err != nil {
+		return nil, err
+	}
 	defer pool.put(signer)
 
 	sshSigner, err := ssh.NewSignerFromSigner(signer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to new ssh signer from signer, error :%v", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	// measure time taken by hsm
 	hStart := time.Now()
 	if err := cert.SignCert(rand.Reader, sshSigner); err != nil {
@@ -108,14 +317,14 @@ func (s *signer) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byt
 }
 
 func (s *signer) GetX509CACert(keyIdentifier string) ([]byte, error) {
-	pool, ok := s.sPool[keyIdentifier]
+	pool, ok := s.getPool(keyIdentifier)
 	if !ok {
 		return nil, fmt.Errorf("unknown key identifier %q", keyIdentifier)
 	}
 	signer := pool.get()
 	defer pool.put(signer)
 
-	cert, ok := s.x509CACerts[keyIdentifier]
+	cert, ok := s.getCACert(keyIdentifier)
 	if !ok {
 		return nil, fmt.Errorf("unable to find CA cert for key identifier %q", keyIdentifier)
 	}
@@ -127,6 +336,12 @@ func (s *signer) GetX509CACert(keyIdentifier string) ([]byte, error) {
 }
 
 func (s *signer) SignX509Cert(cert *x509.Certificate, keyIdentifier string) ([]byte, error) {
+	return s.signX509Cert(context.Background(), cert, keyIdentifier, crypki.PriorityNormal)
+}
+
+// signX509Cert signs the x509 cert with the specified key, waiting for a session of the key with the priority
+// until ctx is done. The cert is not signed if ctx is done by the time a session is available.
+func (s *signer) signX509Cert(ctx context.Context, cert *x509.Certificate, keyIdentifier string, priority crypki.Priority) ([]byte, error) {
 	const methodName = "SignX509Cert"
 	start := time.Now()
 	var ht int64
@@ -135,48 +350,155 @@ func (s *signer) SignX509Cert(cert *x509.Certificate, keyIdentifier string) ([]b
 		log.Printf("m=%s: ht=%d, xt=%d", methodName, ht, xt)
 	}()
 
-	pool, ok := s.sPool[keyIdentifier]
+	pool, ok := s.getPool(keyIdentifier)
 	if !ok {
 		return nil, fmt.Errorf("unknown key identifier %q", keyIdentifier)
 	}
-	signer := pool.get()
+	signer, err := getSigner(ctx, pool, priority)
+	if err != nil {
+		return nil, err
+	}
 	defer pool.put(signer)
 
+	ca, ok := s.getCACert(keyIdentifier)
+	if !ok {
+		return nil, fmt.Errorf("unable to find CA cert for key identifier %q", keyIdentifier)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// The issuer is always the subject of the CA cert of the key, never one set in the template.
+	cert.Issuer = ca.Subject
+
 	// measure time taken by hsm
 	hStart := time.Now()
-	signedCert, err := x509.CreateCertificate(rand.Reader, cert, s.x509CACerts[keyIdentifier], cert.PublicKey, signer)
+	signedCert, err := x509.CreateCertificate(rand.Reader, cert, ca, cert.PublicKey, signer)
 	if err != nil {
 		ht = time.Since(hStart).Nanoseconds() / time.Microsecond.Nanoseconds()
 		return nil, err
 	}
 	ht = time.Since(hStart).Nanoseconds() / time.Microsecond.Nanoseconds()
+	if err := checkIssuer(signedCert, ca); err != nil {
+		return nil, err
+	}
 	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signedCert}), nil
 }
 
+// WithPriority returns the signer whose certificate signing requests wait for a session of the key with the priority.
+func (s *signer) WithPriority(priority crypki.Priority) crypki.CertSign {
+	return &prioritizedSigner{signer: s, ctx: context.Background(), priority: priority}
+}
+
+// WithContext returns the signer whose certificate signing requests give up once ctx is done.
+func (s *signer) WithContext(ctx context.Context) crypki.CertSign {
+	return &prioritizedSigner{signer: s, ctx: ctx, priority: crypki.PriorityNormal}
+}
+
+// prioritizedSigner is a signer whose certificate signing requests wait for a session of the key with a priority,
+// until its ctx is done.
+type prioritizedSigner struct {
+	*signer
+	ctx      context.Context
+	priority crypki.Priority
+}
+
+// WithContext returns the signer of the same priority whose certificate signing requests give up once ctx is done.
+func (p *prioritizedSigner) WithContext(ctx context.Context) crypki.CertSign {
+	return &prioritizedSigner{signer: p.signer, ctx: ctx, priority: p.priority}
+}
+
+func (p *prioritizedSigner) SignSSHCert(cert *ssh.Certificate, keyIdentifier string) ([]byte, error) {
+	return p.signSSHCert(p.ctx, cert, keyIdentifier, p.priority)
+}
+
+func (p *prioritizedSigner) SignX509Cert(cert *x509.Certificate, keyIdentifier string) ([]byte, error) {
+	return p.signX509Cert(p.ctx, cert, keyIdentifier, p.priority)
+}
+
+func (p *prioritizedSigner) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
+	return p.sign(p.ctx, digest, opts, keyIdentifier, p.priority)
+}
+
+// checkIssuer returns an error if the issuer of the DER encoded certificate is not the subject of the CA cert,
+// or if its signature does not verify with the public key of the CA cert, i.e. if the certificate would not
+// chain to the CA cert.
+func checkIssuer(der []byte, ca *x509.Certificate) error {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("unable to parse signed certificate: %v", err)
+	}
+	if !bytes.Equal(cert.RawIssuer, ca.RawSubject) {
+		return fmt.Errorf("issuer %q of the signed certificate does not match the CA cert subject %q", cert.Issuer, ca.Subject)
+	}
+	if err := ca.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return fmt.Errorf("signed certificate does not verify with the CA cert: %v", err)
+	}
+	return nil
+}
+
 func (s *signer) GetBlobSigningPublicKey(keyIdentifier string) ([]byte, error) {
-	return nil, errors.New("not implemented")
+	pub, err := s.PublicKey(keyIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal public key of key %q: %v", keyIdentifier, err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
 }
+
 func (s *signer) Sign(digest []byte, opts crypto.SignerOpts, keyIdentifier string) ([]byte, error) {
-	return nil, errors.New("not implemented")
+	return s.sign(context.Background(), digest, opts, keyIdentifier, crypki.PriorityNormal)
+}
+
+// sign signs the digest with the specified key, waiting for a session of the key with the priority
+// until ctx is done. The digest is not signed if ctx is done by the time a session is available.
+func (s *signer) sign(ctx context.Context, digest []byte, opts crypto.SignerOpts, keyIdentifier string, priority crypki.Priority) ([]byte, error) {
+	const methodName = "Sign"
+	start := time.Now()
+	var ht int64
+	defer func() {
+		tt := time.Since(start).Nanoseconds() / time.Microsecond.Nanoseconds()
+		log.Printf("m=%s: ht=%d, tt=%d", methodName, ht, tt)
+	}()
+
+	pool, ok := s.getPool(keyIdentifier)
+	if !ok {
+		return nil, fmt.Errorf("unknown key identifier %q", keyIdentifier)
+	}
+	signer, err := getSigner(ctx, pool, priority)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.put(signer)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// measure time taken by hsm
+	hStart := time.Now()
+	signature, err := signer.Sign(rand.Reader, digest, opts)
+	ht = time.Since(hStart).Nanoseconds() / time.Microsecond.Nanoseconds()
+	return signature, err
 }
 
 // getX509CACert reads and returns x509 CA certificate from X509CACertLocation.
 // If the certificate is not valid, and CreateCACertIfNotExist is true, a new CA
 // certificate will be generated based on the config, and wrote to X509CACertLocation.
-func getX509CACert(key config.KeyConfig, pool sPool, hostname string, ips []net.IP) (*x509.Certificate, error) {
+// The certificate is parsed through certCache.
+func getX509CACert(key config.KeyConfig, pool sPool, hostname string, ips []net.IP, certCache *x509cert.CertFileCache) (*x509.Certificate, error) {
 	// Try parse certificate in the given location.
-	if certBytes, err := ioutil.ReadFile(key.X509CACertLocation); err == nil {
-		block, _ := pem.Decode(certBytes)
-		if cert, err := x509.ParseCertificate(block.Bytes); err != nil {
-			log.Printf("unable to parse x509 certificate: %v", err)
-		} else if time.Now().After(cert.NotAfter) || time.Now().Before(cert.NotBefore) {
+	if certs, err := certCache.Load(key.X509CACertLocation); err == nil {
+		if cert := certs[0]; time.Now().After(cert.NotAfter) || time.Now().Before(cert.NotBefore) {
 			log.Printf("invalid x509 CA certificate: valid between %s and %s", cert.NotBefore.Format(time.RFC822), cert.NotAfter.Format(time.RFC822))
+		} else if err := checkX509CACertKey(key, cert, pool); err != nil {
+			return nil, err
 		} else {
 			// x509 CA certificate is good. return it.
 			return cert, nil
 		}
 	} else {
-		log.Printf("unable to read file %s: %v", key.X509CACertLocation, err)
+		log.Printf("unable to load x509 CA certificate: %v", err)
 	}
 	if !key.CreateCACertIfNotExist {
 		return nil, errors.New("unable to get x509 CA certificate, but CreateCACertIfNotExist is set to false")
@@ -207,6 +529,31 @@ func getX509CACert(key config.KeyConfig, pool sPool, hostname string, ips []net.
 	return cert, nil
 }
 
+// checkX509CACertKey returns an error if the public key of the x509 CA cert of the key is not the public key
+// of the signer pool, as the certs it signed would not verify against the CA cert, unless SkipX509CACertKeyCheck is set.
+func checkX509CACertKey(key config.KeyConfig, cert *x509.Certificate, pool sPool) error {
+	if key.SkipX509CACertKeyCheck {
+		return nil
+	}
+	signer := pool.get()
+	defer pool.put(signer)
+	pub, err := publicKey(signer)
+	if err != nil {
+		// The key still loads, as at startup the other keys are served regardless.
+		log.Printf("unable to check the x509 CA cert of key %q against its public key: %v", key.Identifier, err)
+		return nil
+	}
+	want, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("unable to marshal public key of key %q: %v", key.Identifier, err)
+	}
+	got, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil || !bytes.Equal(got, want) {
+		return fmt.Errorf("public key of x509 CA certificate %s does not match the public key of key %q", key.X509CACertLocation, key.Identifier)
+	}
+	return nil
+}
+
 func getUserPin(pinFilePath string) (string, error) {
 	userPin, err := ioutil.ReadFile(pinFilePath)
 	if err != nil {
@@ -1,20 +1,31 @@
 package pkcs11
 
 import (
+	"context"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/pem"
+	"errors"
 	"io/ioutil"
 	"math/big"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
 	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -78,6 +89,36 @@ func TestGetSSHCertSigningKey(t *testing.T) {
 	}
 }
 
+func TestPublicKey(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		identifier  string
+		expectError bool
+	}{
+		"good-identifier":       {defaultIdentifier, false},
+		"bad-identifier":        {badIdentifier, true},
+		"unreadable-public-key": {"unreadable", true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer, err := initMockSigner(false)
+			if err != nil {
+				t.Fatalf("unable to init mock signer: %v", err)
+			}
+			signer.sPool["unreadable"] = MockSignerPool{&panicSigner{}}
+			pub, err := signer.PublicKey(tt.identifier)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if err == nil && pub == nil {
+				t.Fatal("got nil public key")
+			}
+		})
+	}
+}
+
 func TestSignSSHCert(t *testing.T) {
 	t.Parallel()
 	rsakey, err := rsa.GenerateKey(rand.Reader, 1024)
@@ -218,6 +259,72 @@ func TestGetX509CACert(t *testing.T) {
 	}
 }
 
+func TestGetX509CACertKeyMismatch(t *testing.T) {
+	t.Parallel()
+	pool, err := newMockSignerPool(false)
+	if err != nil {
+		t.Fatalf("unable to init mock signer pool: %v", err)
+	}
+	caSigner := pool.get()
+	pool.put(caSigner)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	// writeCACert writes the self-signed CA cert of the key to a temporary file and returns its path.
+	writeCACert := func(key crypto.Signer) string {
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "My CA"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		if err != nil {
+			t.Fatalf("unable to create CA cert: %v", err)
+		}
+		f, err := ioutil.TempFile("", "cacert")
+		if err != nil {
+			t.Fatalf("unable to create CA cert file: %v", err)
+		}
+		defer f.Close()
+		if err := pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+			t.Fatalf("unable to write CA cert file: %v", err)
+		}
+		return f.Name()
+	}
+	matched, mismatched := writeCACert(caSigner), writeCACert(otherKey)
+	defer os.Remove(matched)
+	defer os.Remove(mismatched)
+	testcases := map[string]struct {
+		key         config.KeyConfig
+		expectError bool
+	}{
+		"matched":           {key: config.KeyConfig{Identifier: "key1", X509CACertLocation: matched}},
+		"mismatched":        {key: config.KeyConfig{Identifier: "key1", X509CACertLocation: mismatched}, expectError: true},
+		"mismatched-create": {key: config.KeyConfig{Identifier: "key1", X509CACertLocation: mismatched, CreateCACertIfNotExist: true}, expectError: true},
+		"check-skipped":     {key: config.KeyConfig{Identifier: "key1", X509CACertLocation: mismatched, SkipX509CACertKeyCheck: true}},
+	}
+	for label, tt := range testcases {
+		t.Run(label, func(t *testing.T) {
+			_, err := getX509CACert(tt.key, pool, "localhost", nil, nil)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if err != nil && !strings.Contains(err.Error(), `"key1"`) {
+				t.Errorf("got err: %v, want the identifier of the key", err)
+			}
+		})
+	}
+	// The CA cert of a key whose public key cannot be read is not checked.
+	if err := checkX509CACertKey(config.KeyConfig{Identifier: "key1"}, nil, MockSignerPool{&panicSigner{}}); err != nil {
+		t.Errorf("got err: %v for a key whose public key cannot be read, want nil", err)
+	}
+}
+
 func TestSignX509Cert(t *testing.T) {
 	t.Parallel()
 	subject := pkix.Name{
@@ -320,3 +427,344 @@ func TestSignX509Cert(t *testing.T) {
 		})
 	}
 }
+
+func TestSignX509CertIssuer(t *testing.T) {
+	t.Parallel()
+	signer, err := initMockSigner(false)
+	if err != nil {
+		t.Fatalf("unable to init mock signer: %v", err)
+	}
+	caSigner := signer.sPool[defaultIdentifier].get()
+	signer.sPool[defaultIdentifier].put(caSigner)
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	root := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Cross Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	// crossSignedCA returns the CA cert of the public key cross-signed by the root CA,
+	// whose subject differs from its issuer.
+	crossSignedCA := func(pub interface{}) *x509.Certificate {
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(2),
+			Subject:               pkix.Name{CommonName: "Issuing CA", Organization: []string{"Foo"}},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, root, pub, rootKey)
+		if err != nil {
+			t.Fatalf("unable to create CA cert: %v", err)
+		}
+		ca, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("unable to parse CA cert: %v", err)
+		}
+		return ca
+	}
+	testcases := map[string]struct {
+		ca          *x509.Certificate
+		expectError bool
+	}{
+		"cross-signed-ca":   {ca: crossSignedCA(caSigner.Public())},
+		"ca-key-mismatched": {ca: crossSignedCA(&otherKey.PublicKey), expectError: true},
+	}
+	for label, tt := range testcases {
+		signer.x509CACerts[defaultIdentifier] = tt.ca
+		leaf := &x509.Certificate{
+			SerialNumber: big.NewInt(3),
+			Subject:      pkix.Name{CommonName: "foo.bar.com"},
+			// The issuer of the template must be ignored.
+			Issuer:    pkix.Name{CommonName: "Forged CA"},
+			PublicKey: &otherKey.PublicKey,
+			NotBefore: time.Now(),
+			NotAfter:  time.Now().Add(time.Hour),
+		}
+		data, err := signer.SignX509Cert(leaf, defaultIdentifier)
+		if err != nil != tt.expectError {
+			t.Fatalf("in test %v: got err: %v, expect err: %v", label, err, tt.expectError)
+		}
+		if err != nil {
+			continue
+		}
+		block, _ := pem.Decode(data)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("in test %v: unable to parse certificate: %v", label, err)
+		}
+		if cert.Issuer.String() != tt.ca.Subject.String() {
+			t.Errorf("in test %v: got issuer %q, want CA subject %q", label, cert.Issuer, tt.ca.Subject)
+		}
+		if err := cert.CheckSignatureFrom(tt.ca); err != nil {
+			t.Errorf("in test %v: certificate does not verify with the CA cert: %v", label, err)
+		}
+	}
+}
+
+func TestMultipleModules(t *testing.T) {
+	t.Parallel()
+	mockctrl := gomock.NewController(t)
+	defer mockctrl.Finish()
+
+	pinFile, err := ioutil.TempFile("", "pin")
+	if err != nil {
+		t.Fatalf("unable to create pin file: %v", err)
+	}
+	defer os.Remove(pinFile.Name())
+	if _, err := pinFile.WriteString("1234\n"); err != nil {
+		t.Fatalf("unable to write pin file: %v", err)
+	}
+	pinFile.Close()
+
+	// Each module only holds the key on its own slot, and must be finalized exactly once.
+	newModule := func(slot uint, finalizeErr error) *mock_pkcs11.MockPKCS11Ctx {
+		mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+		mockCtx.EXPECT().OpenSession(slot, gomock.Any()).Return(p11.SessionHandle(slot), nil).AnyTimes()
+		mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, "1234").Return(nil).AnyTimes()
+		mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).Return([]p11.ObjectHandle{1}, false, nil).AnyTimes()
+		mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+		mockCtx.EXPECT().Finalize().Return(finalizeErr).Times(1)
+		mockCtx.EXPECT().Destroy().Times(1)
+		return mockCtx
+	}
+	defaultCtx := newModule(1, nil)
+	softhsmCtx := newModule(2, errors.New("CKR_DEVICE_ERROR"))
+
+	s := &signer{
+		x509CACerts: make(map[string]*x509.Certificate),
+		sPool:       make(map[string]sPool),
+		modules: map[string]*module{
+			"":        {context: defaultCtx, slotPins: make(map[uint]string)},
+			"softhsm": {context: softhsmCtx, slotPins: make(map[uint]string)},
+		},
+	}
+	keys := []config.KeyConfig{
+		{Identifier: "key1", SlotNumber: 1, UserPinPath: pinFile.Name(), KeyLabel: "foo", SessionPoolSize: 1, KeyType: crypki.RSA},
+		{Identifier: "key2", Module: "softhsm", SlotNumber: 2, UserPinPath: pinFile.Name(), KeyLabel: "bar", SessionPoolSize: 1, KeyType: crypki.RSA},
+	}
+	if err := s.loadKeys(keys, nil, "", nil); err != nil {
+		t.Fatalf("unable to load keys: %v", err)
+	}
+	for id, want := range map[string]PKCS11Ctx{"key1": defaultCtx, "key2": softhsmCtx} {
+		pool, ok := s.getPool(id)
+		if !ok {
+			t.Fatalf("key %q not loaded", id)
+		}
+		signer := pool.get()
+		if got := signer.(*p11Signer).context; got != want {
+			t.Errorf("key %q loaded from the wrong module", id)
+		}
+		pool.put(signer)
+	}
+	if pin := s.modules["softhsm"].slotPins[2]; pin != "1234" {
+		t.Errorf("got pin %q for slot 2 of module softhsm, want %q", pin, "1234")
+	}
+
+	unknown := []config.KeyConfig{{Identifier: "key3", Module: "cloudhsm", SlotNumber: 1, UserPinPath: pinFile.Name(), KeyLabel: "baz"}}
+	if err := s.loadKeys(unknown, nil, "", nil); err == nil {
+		t.Error("expected error loading a key of an unknown module")
+	}
+
+	// Close reports the failure of the softhsm module after finalizing both modules.
+	if err := s.Close(); err == nil {
+		t.Error("expected error finalizing the modules")
+	}
+}
+
+func TestLoadKeysMechanism(t *testing.T) {
+	t.Parallel()
+	pinFile, err := ioutil.TempFile("", "pin")
+	if err != nil {
+		t.Fatalf("unable to create pin file: %v", err)
+	}
+	defer os.Remove(pinFile.Name())
+	if _, err := pinFile.WriteString("1234\n"); err != nil {
+		t.Fatalf("unable to write pin file: %v", err)
+	}
+	pinFile.Close()
+
+	testcases := map[string]struct {
+		keyType      crypki.PublicKeyAlgorithm
+		mechanism    string
+		expectedMech uint
+		expectError  bool
+	}{
+		"default":               {keyType: crypki.RSA, expectedMech: 0},
+		"rsa-x509":              {keyType: crypki.RSA, mechanism: "CKM_RSA_X_509", expectedMech: p11.CKM_RSA_X_509},
+		"incompatible-key-type": {keyType: crypki.Ed25519, mechanism: "CKM_RSA_X_509", expectError: true},
+		"unsupported-mechanism": {keyType: crypki.RSA, mechanism: "CKM_SHA256_RSA_PKCS", expectError: true},
+		"unsupported-by-slot":   {keyType: crypki.RSA, mechanism: "CKM_RSA_PKCS", expectError: true},
+		"vendor-ecdsa":          {keyType: crypki.ECDSA, mechanism: "0x80000101", expectedMech: 0x80000101},
+		"vendor-rsa":            {keyType: crypki.RSA, mechanism: "0x80000101", expectError: true},
+		"vendor-not-vendor":     {keyType: crypki.ECDSA, mechanism: "0x1041", expectError: true},
+	}
+	for label, tt := range testcases {
+		t.Run(label, func(t *testing.T) {
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			mockCtx.EXPECT().GetMechanismList(uint(1)).Return([]*p11.Mechanism{
+				p11.NewMechanism(p11.CKM_RSA_X_509, nil),
+				p11.NewMechanism(0x80000101, nil),
+			}, nil).AnyTimes()
+			mockCtx.EXPECT().OpenSession(uint(1), gomock.Any()).Return(p11.SessionHandle(1), nil).AnyTimes()
+			mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, "1234").Return(nil).AnyTimes()
+			mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).Return([]p11.ObjectHandle{1}, false, nil).AnyTimes()
+			mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+
+			s := &signer{
+				x509CACerts: make(map[string]*x509.Certificate),
+				sPool:       make(map[string]sPool),
+				modules:     map[string]*module{"": {context: mockCtx, slotPins: make(map[uint]string)}},
+			}
+			keys := []config.KeyConfig{{Identifier: "key1", SlotNumber: 1, UserPinPath: pinFile.Name(), KeyLabel: "foo", SessionPoolSize: 1, KeyType: tt.keyType, Mechanism: tt.mechanism}}
+			err := s.loadKeys(keys, nil, "", nil)
+			if err != nil != tt.expectError {
+				t.Fatalf("in test %v: got err: %v, expect err: %v", label, err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+			pool, _ := s.getPool("key1")
+			signer := pool.get()
+			defer pool.put(signer)
+			if got := signer.(*p11Signer).mechanism; got != tt.expectedMech {
+				t.Errorf("in test %v: got mechanism 0x%x, want 0x%x", label, got, tt.expectedMech)
+			}
+		})
+	}
+}
+
+func TestLoadKeysDeterministic(t *testing.T) {
+	t.Parallel()
+	pinFile, err := ioutil.TempFile("", "pin")
+	if err != nil {
+		t.Fatalf("unable to create pin file: %v", err)
+	}
+	defer os.Remove(pinFile.Name())
+	if _, err := pinFile.WriteString("1234\n"); err != nil {
+		t.Fatalf("unable to write pin file: %v", err)
+	}
+	pinFile.Close()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	point, err := key.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("unable to encode EC point: %v", err)
+	}
+	params, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+
+	testcases := map[string]struct {
+		deterministic bool
+		badSignature  bool
+		expectError   bool
+	}{
+		"deterministic":     {deterministic: true},
+		"not-deterministic": {expectError: true},
+		"bad-signature":     {deterministic: true, badSignature: true, expectError: true},
+	}
+	for label, tt := range testcases {
+		t.Run(label, func(t *testing.T) {
+			mockctrl := gomock.NewController(t)
+			defer mockctrl.Finish()
+			mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+			mockCtx.EXPECT().GetMechanismList(uint(1)).Return([]*p11.Mechanism{p11.NewMechanism(0x80000101, nil)}, nil).AnyTimes()
+			mockCtx.EXPECT().OpenSession(uint(1), gomock.Any()).Return(p11.SessionHandle(1), nil).AnyTimes()
+			mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, "1234").Return(nil).AnyTimes()
+			mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).Return([]p11.ObjectHandle{1}, false, nil).AnyTimes()
+			mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+			mockCtx.EXPECT().GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*p11.Attribute{
+				p11.NewAttribute(p11.CKA_EC_PARAMS, params),
+				p11.NewAttribute(p11.CKA_EC_POINT, point),
+			}, nil).AnyTimes()
+			mockCtx.EXPECT().SignInit(gomock.Any(), []*p11.Mechanism{p11.NewMechanism(0x80000101, nil)}, gomock.Any()).Return(nil).AnyTimes()
+			var last []byte
+			mockCtx.EXPECT().Sign(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, digest []byte) ([]byte, error) {
+				if tt.deterministic && last != nil {
+					return last, nil
+				}
+				r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+				if err != nil {
+					return nil, err
+				}
+				if tt.badSignature {
+					r.Add(r, big.NewInt(1))
+				}
+				last = make([]byte, 64)
+				r.FillBytes(last[:32])
+				s.FillBytes(last[32:])
+				return last, nil
+			}).AnyTimes()
+
+			s := &signer{
+				x509CACerts: make(map[string]*x509.Certificate),
+				sPool:       make(map[string]sPool),
+				modules:     map[string]*module{"": {context: mockCtx, slotPins: make(map[uint]string)}},
+			}
+			keys := []config.KeyConfig{{Identifier: "key1", SlotNumber: 1, UserPinPath: pinFile.Name(), KeyLabel: "foo", SessionPoolSize: 1,
+				KeyType: crypki.ECDSA, Mechanism: "0x80000101", DeterministicSignatures: true}}
+			if err := s.loadKeys(keys, nil, "", nil); err != nil != tt.expectError {
+				t.Errorf("in test %v: got err: %v, expect err: %v", label, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSignBlob(t *testing.T) {
+	t.Parallel()
+	signer, err := initMockSigner(false)
+	if err != nil {
+		t.Fatalf("unable to init mock signer: %v", err)
+	}
+	pemKey, err := signer.GetBlobSigningPublicKey(defaultIdentifier)
+	if err != nil {
+		t.Fatalf("unable to get blob signing public key: %v", err)
+	}
+	block, _ := pem.Decode(pemKey)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		t.Fatalf("got public key %q, want a PEM encoded public key", pemKey)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("unable to parse public key: %v", err)
+	}
+	digest := sha256.Sum256([]byte("good"))
+	for _, s := range []crypki.CertSign{signer, signer.WithPriority(crypki.PriorityHigh)} {
+		signature, err := s.Sign(digest[:], crypto.SHA256, defaultIdentifier)
+		if err != nil {
+			t.Fatalf("unable to sign digest: %v", err)
+		}
+		if err := rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], signature); err != nil {
+			t.Errorf("signature does not verify with the blob signing public key: %v", err)
+		}
+	}
+	if _, err := signer.Sign(digest[:], crypto.SHA256, badIdentifier); err == nil {
+		t.Error("got no error signing with an unknown key")
+	}
+	if _, err := signer.GetBlobSigningPublicKey(badIdentifier); err == nil {
+		t.Error("got no error getting the public key of an unknown key")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := signer.WithContext(ctx).Sign(digest[:], crypto.SHA256, defaultIdentifier); err == nil {
+		t.Error("got no error signing once the context is done")
+	}
+}
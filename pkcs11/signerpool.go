@@ -4,8 +4,10 @@
 package pkcs11
 
 import (
+	"context"
 	"crypto"
 	"fmt"
+	"sync"
 
 	"github.com/yahoo/crypki"
 )
@@ -16,6 +18,26 @@ type sPool interface {
 	put(s signerWithSignAlgorithm)
 }
 
+// priorityPool is implemented by the sPools that serve the waiting requests by priority.
+type priorityPool interface {
+	getWithContext(ctx context.Context, priority crypki.Priority) (signerWithSignAlgorithm, error)
+}
+
+// getSigner gets a signer from the pool with the priority, if the pool supports priorities. It gives up
+// waiting for a signer once ctx is done, and returns the error of ctx.
+func getSigner(ctx context.Context, pool sPool, priority crypki.Priority) (signerWithSignAlgorithm, error) {
+	if p, ok := pool.(priorityPool); ok {
+		return p.getWithContext(ctx, priority)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return pool.get(), nil
+}
+
+// priorityOrder lists the priorities from the first to the last served.
+var priorityOrder = [...]crypki.Priority{crypki.PriorityHigh, crypki.PriorityNormal, crypki.PriorityLow}
+
 type signerWithSignAlgorithm interface {
 	crypto.Signer
 	signAlgorithm() crypki.PublicKeyAlgorithm
@@ -24,36 +46,195 @@ type signerWithSignAlgorithm interface {
 // SignerPool is a pool of PKCS11 signers
 // each key is corresponding with a SignerPool
 type SignerPool struct {
+	// mu guards the handover of the signers to the waiting requests.
+	mu      sync.Mutex
 	signers chan signerWithSignAlgorithm
+	// waiting holds the FIFO queues of the requests waiting for a signer, by priority.
+	waiting map[crypki.Priority][]chan signerWithSignAlgorithm
 	// per pkcs11, by default, Login() is required only once.
 	// we use dummySigner to login to the token, and for absolutely nothing else.
 	dummySigner *p11Signer
+	// replacement, once set by retire, serves the requests of the pool in its place.
+	replacement *SignerPool
+	// own is the set of the signers of the pool. Once the pool is retired, they are sent to retired as they
+	// are put back, to have their sessions closed.
+	own     map[signerWithSignAlgorithm]bool
+	retired chan signerWithSignAlgorithm
+
+	// The parameters of the pool, to reopen its sessions.
+	context    PKCS11Ctx
+	slot       uint
+	tokenLabel string
+	keyID      []byte
+	pin        string
+	keyType    crypki.PublicKeyAlgorithm
+	mechanism  uint
 }
 
 // newSignerPool initializes a signer pool based on the configuration parameters
-func newSignerPool(context PKCS11Ctx, nSigners int, slot uint, tokenLabel string, pin string, keyType crypki.PublicKeyAlgorithm) (sPool, error) {
-	dummySigner, err := makeSigner(context, true, slot, tokenLabel, pin, keyType)
+func newSignerPool(context PKCS11Ctx, nSigners int, slot uint, tokenLabel string, keyID []byte, pin string, keyType crypki.PublicKeyAlgorithm, mechanism uint) (sPool, error) {
+	dummySigner, err := makeSigner(context, true, slot, tokenLabel, keyID, pin, keyType, mechanism)
+	if le, ok := err.(*loginError); ok {
+		return &SignerPool{}, le
+	}
 	if err != nil {
-		return &SignerPool{nil, nil}, fmt.Errorf("error making dummy signer: %v", err)
+		return &SignerPool{}, fmt.Errorf("error making dummy signer: %v", err)
 	}
 	signers := make(chan signerWithSignAlgorithm, nSigners)
+	own := make(map[signerWithSignAlgorithm]bool)
 	for i := 0; i < nSigners; i++ {
-		signerInstance, err := makeSigner(context, false, slot, tokenLabel, pin, keyType)
+		signerInstance, err := makeSigner(context, false, slot, tokenLabel, keyID, pin, keyType, mechanism)
 		if err != nil {
-			return &SignerPool{nil, nil}, fmt.Errorf("error making signer: %v", err)
+			return &SignerPool{}, fmt.Errorf("error making signer: %v", err)
 		}
 		signers <- signerInstance
+		own[signerInstance] = true
 	}
 	return &SignerPool{
 		signers:     signers,
+		own:         own,
 		dummySigner: dummySigner,
+		context:     context,
+		slot:        slot,
+		tokenLabel:  tokenLabel,
+		keyID:       keyID,
+		pin:         pin,
+		keyType:     keyType,
+		mechanism:   mechanism,
 	}, nil
 }
 
 func (c *SignerPool) get() signerWithSignAlgorithm {
-	return <-c.signers
+	return c.getWithPriority(crypki.PriorityNormal)
+}
+
+// getWithPriority returns a free signer, or waits for one to be put back. The waiting requests of
+// higher priority are served first, and those of the same priority in the order they arrived.
+func (c *SignerPool) getWithPriority(priority crypki.Priority) signerWithSignAlgorithm {
+	instance, _ := c.getWithContext(context.Background(), priority)
+	return instance
+}
+
+// getWithContext is getWithPriority, which gives up waiting for a signer once ctx is done and returns
+// the error of ctx.
+func (c *SignerPool) getWithContext(ctx context.Context, priority crypki.Priority) (signerWithSignAlgorithm, error) {
+	switch priority {
+	case crypki.PriorityHigh, crypki.PriorityLow:
+	default:
+		priority = crypki.PriorityNormal
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if r := c.replacement; r != nil {
+		c.mu.Unlock()
+		return r.getWithContext(ctx, priority)
+	}
+	select {
+	case instance := <-c.signers:
+		c.mu.Unlock()
+		return instance, nil
+	default:
+	}
+	if c.waiting == nil {
+		c.waiting = make(map[crypki.Priority][]chan signerWithSignAlgorithm)
+	}
+	wait := make(chan signerWithSignAlgorithm, 1)
+	c.waiting[priority] = append(c.waiting[priority], wait)
+	c.mu.Unlock()
+	select {
+	case instance := <-wait:
+		return instance, nil
+	case <-ctx.Done():
+	}
+	c.mu.Lock()
+	queue := c.waiting[priority]
+	for i, w := range queue {
+		if w == wait {
+			c.waiting[priority] = append(queue[:i:i], queue[i+1:]...)
+			c.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+	c.mu.Unlock()
+	// The request was no longer waiting, so a signer was or is being handed over to it.
+	c.put(<-wait)
+	return nil, ctx.Err()
 }
 
 func (c *SignerPool) put(instance signerWithSignAlgorithm) {
+	c.mu.Lock()
+	if r := c.replacement; r != nil {
+		c.mu.Unlock()
+		if c.own[instance] {
+			c.retired <- instance
+			return
+		}
+		// The signer was got from the replacement through the pool.
+		r.put(instance)
+		return
+	}
+	defer c.mu.Unlock()
+	for _, priority := range priorityOrder {
+		if queue := c.waiting[priority]; len(queue) != 0 {
+			c.waiting[priority] = queue[1:]
+			queue[0] <- instance
+			return
+		}
+	}
 	c.signers <- instance
 }
+
+// usage returns the number of signers in use and the size of the pool.
+func (c *SignerPool) usage() (inUse, size int) {
+	size = cap(c.signers)
+	return size - len(c.signers), size
+}
+
+// reopen returns a new pool of the same size with freshly opened sessions.
+func (c *SignerPool) reopen() (*SignerPool, error) {
+	return c.open(cap(c.signers))
+}
+
+// open returns a new pool of nSigners freshly opened sessions of the key of the pool.
+func (c *SignerPool) open(nSigners int) (*SignerPool, error) {
+	pool, err := newSignerPool(c.context, nSigners, c.slot, c.tokenLabel, c.keyID, c.pin, c.keyType, c.mechanism)
+	if err != nil {
+		return nil, err
+	}
+	return pool.(*SignerPool), nil
+}
+
+// retire makes the replacement serve the requests of the pool in its place, including those waiting for
+// a signer, and closes the sessions of the signers of the pool in the background as they are put back.
+// The returned channel is closed once all the sessions are closed.
+func (c *SignerPool) retire(replacement *SignerPool) <-chan struct{} {
+	c.mu.Lock()
+	c.retired = make(chan signerWithSignAlgorithm, cap(c.signers))
+	c.replacement = replacement
+	waiting := c.waiting
+	c.waiting = nil
+	for idle := len(c.signers); idle > 0; idle-- {
+		c.retired <- <-c.signers
+	}
+	c.mu.Unlock()
+	for priority, queue := range waiting {
+		for _, wait := range queue {
+			go func(priority crypki.Priority, wait chan signerWithSignAlgorithm) {
+				wait <- replacement.getWithPriority(priority)
+			}(priority, wait)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < cap(c.signers); i++ {
+			if p, ok := (<-c.retired).(*p11Signer); ok {
+				p.context.CloseSession(p.session)
+			}
+		}
+		c.dummySigner.context.CloseSession(c.dummySigner.session)
+		close(done)
+	}()
+	return done
+}
@@ -1,6 +1,7 @@
 package pkcs11
 
 import (
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/pem"
@@ -8,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	p11 "github.com/miekg/pkcs11"
@@ -26,6 +29,17 @@ func (b *badSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts)
 	return nil, errors.New("bad signer")
 }
 
+// panicSigner raises the errors reading its public key as panics, as the p11Signer does.
+type panicSigner struct{}
+
+func (p *panicSigner) Public() crypto.PublicKey {
+	panic("Error returning public key: CKR_DEVICE_ERROR")
+}
+
+func (p *panicSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("panic signer")
+}
+
 type MockSignerPool struct {
 	signer crypto.Signer
 }
@@ -190,7 +204,7 @@ func TestNewSignerPool(t *testing.T) {
 				Return(tt.errMsg["FindObjectsFinal"]).
 				AnyTimes()
 
-			ret, err := newSignerPool(mockCtx, tt.nSigners, tt.slot, tt.token, tt.pin, tt.keyType)
+			ret, err := newSignerPool(mockCtx, tt.nSigners, tt.slot, tt.token, nil, tt.pin, tt.keyType, 0)
 			if tt.expectError {
 				if err == nil {
 					t.Error("expected error, but got nil")
@@ -215,3 +229,116 @@ func TestNewSignerPool(t *testing.T) {
 		})
 	}
 }
+
+func TestSignerPoolUsage(t *testing.T) {
+	t.Parallel()
+	signers := make(chan signerWithSignAlgorithm, 3)
+	for i := 0; i < 3; i++ {
+		signers <- MockSignerPool{}
+	}
+	pool := &SignerPool{signers: signers}
+	if inUse, size := pool.usage(); inUse != 0 || size != 3 {
+		t.Fatalf("got usage %d/%d, want 0/3", inUse, size)
+	}
+	s1, s2 := pool.get(), pool.get()
+	if inUse, size := pool.usage(); inUse != 2 || size != 3 {
+		t.Fatalf("got usage %d/%d, want 2/3", inUse, size)
+	}
+	pool.put(s1)
+	pool.put(s2)
+	if inUse, _ := pool.usage(); inUse != 0 {
+		t.Fatalf("got %d signers in use after put, want 0", inUse)
+	}
+}
+
+// waitingRequests returns the number of requests waiting for a signer of the pool.
+func waitingRequests(pool *SignerPool) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	n := 0
+	for _, queue := range pool.waiting {
+		n += len(queue)
+	}
+	return n
+}
+
+func TestSignerPoolPriority(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		// priorities are the priorities of the requests, queued in order while the only signer is in use.
+		priorities []crypki.Priority
+		// expectedOrder lists the indexes of the requests in the order they are served.
+		expectedOrder []int
+	}{
+		"high-before-low": {
+			priorities:    []crypki.Priority{crypki.PriorityLow, crypki.PriorityHigh},
+			expectedOrder: []int{1, 0},
+		},
+		"normal-between-high-and-low": {
+			priorities:    []crypki.Priority{crypki.PriorityLow, crypki.PriorityNormal, crypki.PriorityHigh},
+			expectedOrder: []int{2, 1, 0},
+		},
+		"fifo-within-priority": {
+			priorities:    []crypki.Priority{crypki.PriorityLow, crypki.PriorityNormal, crypki.PriorityLow, crypki.PriorityNormal},
+			expectedOrder: []int{1, 3, 0, 2},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			pool := &SignerPool{signers: make(chan signerWithSignAlgorithm, 1)}
+			pool.signers <- MockSignerPool{}
+			held := pool.get()
+			served := make(chan int, len(tt.priorities))
+			for i, priority := range tt.priorities {
+				go func(i int, priority crypki.Priority) {
+					s := pool.getWithPriority(priority)
+					served <- i
+					pool.put(s)
+				}(i, priority)
+				for deadline := time.Now().Add(5 * time.Second); waitingRequests(pool) != i+1; {
+					if time.Now().After(deadline) {
+						t.Fatalf("in test %v: request %d not queued", label, i)
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}
+			pool.put(held)
+			var order []int
+			for range tt.priorities {
+				order = append(order, <-served)
+			}
+			if !reflect.DeepEqual(order, tt.expectedOrder) {
+				t.Errorf("in test %v: got requests served in order %v, want %v", label, order, tt.expectedOrder)
+			}
+		})
+	}
+}
+
+func TestSignerPoolContext(t *testing.T) {
+	t.Parallel()
+	pool := &SignerPool{signers: make(chan signerWithSignAlgorithm, 1)}
+	pool.signers <- MockSignerPool{}
+	held := pool.get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.getWithContext(ctx, crypki.PriorityNormal); err != context.DeadlineExceeded {
+		t.Fatalf("got error %v while the only signer is in use, want %v", err, context.DeadlineExceeded)
+	}
+	if n := waitingRequests(pool); n != 0 {
+		t.Fatalf("got %d waiting requests after the deadline, want 0", n)
+	}
+	if _, err := pool.getWithContext(ctx, crypki.PriorityNormal); err != context.DeadlineExceeded {
+		t.Errorf("got error %v with an expired context, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The signer given up by the request whose context expired goes back to the pool.
+	pool.put(held)
+	s, err := pool.getWithContext(context.Background(), crypki.PriorityNormal)
+	if err != nil || s == nil {
+		t.Fatalf("got signer %v, error %v, want the signer put back", s, err)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"sort"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+)
+
+// thresholdShare is a share of a threshold RSA key, held by one HSM.
+type thresholdShare struct {
+	// index is the non-zero x-coordinate of the share in the sharing polynomial.
+	index int
+	// sign raises the block to the share exponent 2*delta*s_i modulo the modulus of the key.
+	sign func(block []byte) ([]byte, error)
+}
+
+// thresholdSigner signs with a threshold RSA key whose private exponent is split into shares held by
+// different HSMs, as per Shoup, "Practical Threshold Signatures" (EUROCRYPT 2000). The signature is
+// the combination of the partial signatures of any threshold of the shares; the private exponent
+// is never assembled.
+//
+// The share with index i holds the exponent 2*delta*f(i) as a raw RSA private key with the modulus of
+// the key, where delta is the factorial of the number of shares and f is a random polynomial of degree
+// threshold-1 over Z_lambda(N) whose constant term is the private exponent.
+type thresholdSigner struct {
+	public    *rsa.PublicKey
+	threshold int
+	shares    []thresholdShare
+	delta     *big.Int
+	// strict specifies whether all the shares are required to sign rather than threshold of them,
+	// so that an unavailable HSM fails the signatures instead of going unnoticed.
+	strict bool
+}
+
+// newThresholdSigner returns a thresholdSigner of the RSA public key requiring threshold of the shares.
+func newThresholdSigner(public *rsa.PublicKey, threshold int, shares []thresholdShare) (*thresholdSigner, error) {
+	if threshold < 1 || threshold > len(shares) {
+		return nil, fmt.Errorf("invalid threshold %d of %d shares", threshold, len(shares))
+	}
+	seen := make(map[int]bool)
+	for _, share := range shares {
+		if share.index < 1 || share.index > len(shares) || seen[share.index] {
+			return nil, fmt.Errorf("invalid or duplicate share index %d", share.index)
+		}
+		seen[share.index] = true
+	}
+	delta := new(big.Int).MulRange(1, int64(len(shares)))
+	// The combination requires gcd(e, 4*delta^2) = 1.
+	fourDelta2 := new(big.Int).Lsh(new(big.Int).Mul(delta, delta), 2)
+	if new(big.Int).GCD(nil, nil, big.NewInt(int64(public.E)), fourDelta2).Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("public exponent %d is not coprime with 4*(%d!)^2", public.E, len(shares))
+	}
+	return &thresholdSigner{public: public, threshold: threshold, shares: shares, delta: delta}, nil
+}
+
+// partialSignature is the partial signature of a share.
+type partialSignature struct {
+	index int
+	sig   *big.Int
+	err   error
+}
+
+// Sign signs the digest with PKCS #1 v1.5 padding. The shares are asked for their partial
+// signatures concurrently, and the first threshold of them are combined. It fails if fewer than
+// threshold shares respond, or if any share fails in strict mode. It is part of the crypto.Signer interface.
+func (t *thresholdSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	k := t.public.Size()
+	em, err := padPKCS1v15(k, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(em)
+
+	results := make(chan partialSignature, len(t.shares))
+	for _, share := range t.shares {
+		go func(share thresholdShare) {
+			sig, err := share.sign(em)
+			if err != nil {
+				results <- partialSignature{index: share.index, err: err}
+				return
+			}
+			results <- partialSignature{index: share.index, sig: new(big.Int).SetBytes(sig)}
+		}(share)
+	}
+	required := t.threshold
+	if t.strict {
+		required = len(t.shares)
+	}
+	var partials []partialSignature
+	for i := 0; i < len(t.shares) && len(partials) < required; i++ {
+		r := <-results
+		if r.err != nil {
+			log.Printf("threshold signer: share %d failed: %v", r.index, r.err)
+			continue
+		}
+		partials = append(partials, r)
+	}
+	if len(partials) < required {
+		return nil, fmt.Errorf("only %d of %d shares signed, %d required", len(partials), len(t.shares), required)
+	}
+
+	y, err := t.combine(x, partials[:t.threshold])
+	if err != nil {
+		return nil, err
+	}
+	sig := y.Bytes()
+	if len(sig) < k {
+		sig = append(make([]byte, k-len(sig)), sig...)
+	}
+	// A corrupted share produces an invalid signature rather than an error.
+	if err := rsa.VerifyPKCS1v15(t.public, opts.HashFunc(), digest, sig); err != nil {
+		return nil, fmt.Errorf("combined threshold signature is invalid: %v", err)
+	}
+	return sig, nil
+}
+
+// combine returns x^d mod N from the partial signatures x_i = x^(2*delta*s_i) of a threshold of the shares:
+// w = prod x_i^(2*lambda_i) = x^(4*delta^2*d), and y = w^a * x^b where a*4*delta^2 + b*e = 1.
+func (t *thresholdSigner) combine(x *big.Int, partials []partialSignature) (*big.Int, error) {
+	sort.Slice(partials, func(i, j int) bool { return partials[i].index < partials[j].index })
+	n := t.public.N
+	w := big.NewInt(1)
+	for _, p := range partials {
+		// lambda_i = delta * prod_{j != i} j / (j - i), which is an integer.
+		num := new(big.Int).Set(t.delta)
+		den := big.NewInt(1)
+		for _, q := range partials {
+			if q.index == p.index {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(q.index)))
+			den.Mul(den, big.NewInt(int64(q.index-p.index)))
+		}
+		lambda := num.Quo(num, den)
+		term, err := modExp(p.sig, lambda.Lsh(lambda, 1), n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partial signature of share %d: %v", p.index, err)
+		}
+		w.Mul(w, term).Mod(w, n)
+	}
+	a, b := new(big.Int), new(big.Int)
+	fourDelta2 := new(big.Int).Lsh(new(big.Int).Mul(t.delta, t.delta), 2)
+	new(big.Int).GCD(a, b, fourDelta2, big.NewInt(int64(t.public.E)))
+	wa, err := modExp(w, a, n)
+	if err != nil {
+		return nil, err
+	}
+	xb, err := modExp(x, b, n)
+	if err != nil {
+		return nil, err
+	}
+	return wa.Mul(wa, xb).Mod(wa, n), nil
+}
+
+// modExp returns base^exp mod n for a possibly negative exp.
+func modExp(base, exp, n *big.Int) (*big.Int, error) {
+	if exp.Sign() >= 0 {
+		return new(big.Int).Exp(base, exp, n), nil
+	}
+	inv := new(big.Int).ModInverse(base, n)
+	if inv == nil {
+		return nil, errors.New("value is not invertible")
+	}
+	return inv.Exp(inv, new(big.Int).Neg(exp), n), nil
+}
+
+// Public returns the RSA public key of the threshold key.
+func (t *thresholdSigner) Public() crypto.PublicKey {
+	return t.public
+}
+
+// signAlgorithm returns the signature algorithm of the threshold key, which is always RSA.
+func (t *thresholdSigner) signAlgorithm() crypki.PublicKeyAlgorithm {
+	return crypki.RSA
+}
+
+// thresholdPool is the sPool of a threshold key. Its single thresholdSigner is safe for concurrent use,
+// as each signature takes a session of each share from the signer pool of the share.
+type thresholdPool struct {
+	signer *thresholdSigner
+}
+
+func (p *thresholdPool) get() signerWithSignAlgorithm {
+	return p.signer
+}
+
+func (p *thresholdPool) put(s signerWithSignAlgorithm) {}
+
+// poolShare returns the thresholdShare with the index whose signatures use the sessions of the signer pool.
+func poolShare(index int, pool sPool) thresholdShare {
+	return thresholdShare{index: index, sign: func(block []byte) ([]byte, error) {
+		s := pool.get()
+		defer pool.put(s)
+		p, ok := s.(*p11Signer)
+		if !ok {
+			return nil, errors.New("share is not held by a PKCS#11 signer")
+		}
+		return signRawRSA(p.context, p.session, p.privateKey, block)
+	}}
+}
+
+// loadThresholdKey initializes the signer pools of the threshold shares of the key in their PKCS#11 modules,
+// and returns the sPool of the threshold key.
+func (s *signer) loadThresholdKey(key config.KeyConfig) (sPool, error) {
+	var public *rsa.PublicKey
+	var shares []thresholdShare
+	for _, share := range key.ThresholdShares {
+		m, ok := s.modules[share.Module]
+		if !ok {
+			return nil, fmt.Errorf("unknown PKCS#11 module %q for share %d", share.Module, share.Index)
+		}
+		pin, err := getUserPin(share.UserPinPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read user pin for share %d, pin path: %v, err: %v", share.Index, share.UserPinPath, err)
+		}
+		pool, err := newSignerPool(m.context, key.SessionPoolSize, share.SlotNumber, share.KeyLabel, nil, pin, crypki.RSA, p11.CKM_RSA_X_509)
+		if le, ok := err.(*loginError); ok {
+			le.pinPath = share.UserPinPath
+			return nil, le
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize share %d: %v", share.Index, err)
+		}
+		m.slotPins[share.SlotNumber] = pin
+		ps := pool.get()
+		pub, ok := ps.Public().(*rsa.PublicKey)
+		pool.put(ps)
+		if !ok {
+			return nil, fmt.Errorf("share %d is not an RSA key", share.Index)
+		}
+		if public == nil {
+			public = pub
+		} else if public.N.Cmp(pub.N) != 0 || public.E != pub.E {
+			return nil, fmt.Errorf("public key of share %d differs from the other shares", share.Index)
+		}
+		shares = append(shares, poolShare(share.Index, pool))
+	}
+	t, err := newThresholdSigner(public, key.Threshold, shares)
+	if err != nil {
+		return nil, err
+	}
+	t.strict = key.PartialAvailability == config.PartialAvailabilityStrict
+	return &thresholdPool{signer: t}, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// dealThresholdShares splits the private exponent of the key into n shares of which any threshold
+// can sign, and returns the share exponents 2*delta*f(i) indexed from 1.
+func dealThresholdShares(t *testing.T, key *rsa.PrivateKey, threshold, n int) []*big.Int {
+	t.Helper()
+	one := big.NewInt(1)
+	p1 := new(big.Int).Sub(key.Primes[0], one)
+	q1 := new(big.Int).Sub(key.Primes[1], one)
+	gcd := new(big.Int).GCD(nil, nil, p1, q1)
+	lambda := new(big.Int).Div(new(big.Int).Mul(p1, q1), gcd)
+
+	coeffs := []*big.Int{new(big.Int).Mod(key.D, lambda)}
+	for i := 1; i < threshold; i++ {
+		c, err := rand.Int(rand.Reader, lambda)
+		if err != nil {
+			t.Fatalf("unable to generate coefficient: %v", err)
+		}
+		coeffs = append(coeffs, c)
+	}
+	delta := new(big.Int).MulRange(1, int64(n))
+	exps := make([]*big.Int, n+1)
+	for i := 1; i <= n; i++ {
+		x := big.NewInt(int64(i))
+		f := new(big.Int)
+		for j := len(coeffs) - 1; j >= 0; j-- {
+			f.Mul(f, x).Add(f, coeffs[j]).Mod(f, lambda)
+		}
+		exps[i] = f.Mul(f, delta).Lsh(f, 1)
+	}
+	return exps
+}
+
+// mockShare returns a thresholdShare that signs like an HSM holding the share exponent with raw RSA.
+func mockShare(key *rsa.PrivateKey, index int, exp *big.Int, fail bool) thresholdShare {
+	return thresholdShare{index: index, sign: func(block []byte) ([]byte, error) {
+		if fail {
+			return nil, errors.New("HSM unavailable")
+		}
+		s := new(big.Int).Exp(new(big.Int).SetBytes(block), exp, key.N).Bytes()
+		return append(make([]byte, key.Size()-len(s)), s...), nil
+	}}
+}
+
+func TestThresholdSign(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	digest := sha256.Sum256([]byte("good"))
+	testcases := map[string]struct {
+		threshold   int
+		shares      int
+		failing     map[int]bool
+		corrupt     bool
+		strict      bool
+		expectError bool
+	}{
+		"1-of-1":                 {threshold: 1, shares: 1},
+		"2-of-3":                 {threshold: 2, shares: 3},
+		"3-of-5":                 {threshold: 3, shares: 5},
+		"5-of-5":                 {threshold: 5, shares: 5},
+		"2-of-3-one-failing":     {threshold: 2, shares: 3, failing: map[int]bool{1: true}},
+		"3-of-5-two-failing":     {threshold: 3, shares: 5, failing: map[int]bool{2: true, 5: true}},
+		"2-of-3-insufficient":    {threshold: 2, shares: 3, failing: map[int]bool{1: true, 3: true}, expectError: true},
+		"3-of-5-insufficient":    {threshold: 3, shares: 5, failing: map[int]bool{1: true, 2: true, 4: true}, expectError: true},
+		"3-of-3-corrupted-share": {threshold: 3, shares: 3, corrupt: true, expectError: true},
+		"2-of-3-strict":          {threshold: 2, shares: 3, strict: true},
+		"2-of-3-strict-failing":  {threshold: 2, shares: 3, failing: map[int]bool{3: true}, strict: true, expectError: true},
+	}
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			exps := dealThresholdShares(t, key, tt.threshold, tt.shares)
+			if tt.corrupt {
+				exps[1].Add(exps[1], big.NewInt(2))
+			}
+			var shares []thresholdShare
+			for i := 1; i <= tt.shares; i++ {
+				shares = append(shares, mockShare(key, i, exps[i], tt.failing[i]))
+			}
+			signer, err := newThresholdSigner(&key.PublicKey, tt.threshold, shares)
+			if err != nil {
+				t.Fatalf("unable to create threshold signer: %v", err)
+			}
+			signer.strict = tt.strict
+			sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+				t.Errorf("invalid signature: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewThresholdSigner(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	share := func(index int) thresholdShare { return mockShare(key, index, big.NewInt(1), false) }
+	testcases := map[string]struct {
+		public      *rsa.PublicKey
+		threshold   int
+		shares      []thresholdShare
+		expectError bool
+	}{
+		"good":             {public: &key.PublicKey, threshold: 2, shares: []thresholdShare{share(1), share(2), share(3)}},
+		"zero-threshold":   {public: &key.PublicKey, threshold: 0, shares: []thresholdShare{share(1), share(2)}, expectError: true},
+		"threshold-excess": {public: &key.PublicKey, threshold: 3, shares: []thresholdShare{share(1), share(2)}, expectError: true},
+		"duplicate-index":  {public: &key.PublicKey, threshold: 2, shares: []thresholdShare{share(1), share(1)}, expectError: true},
+		"index-too-large":  {public: &key.PublicKey, threshold: 2, shares: []thresholdShare{share(1), share(3)}, expectError: true},
+		"exponent-3-of-3":  {public: &rsa.PublicKey{N: key.N, E: 3}, threshold: 2, shares: []thresholdShare{share(1), share(2), share(3)}, expectError: true},
+	}
+	for name, tt := range testcases {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := newThresholdSigner(tt.public, tt.threshold, tt.shares)
+			if err != nil != tt.expectError {
+				t.Errorf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package pkcs11
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yahoo/crypki"
+)
+
+// tlsSignTimeout is how long a TLS handshake waits for a session of the TLS server key before it fails.
+const tlsSignTimeout = 2 * time.Second
+
+// tlsSigner is a crypto.Signer backed by a signer pool which can be used as the private key
+// of a tls.Certificate. A signer is taken from the pool only for the duration of a single
+// signing operation, so TLS handshakes never hold on to a PKCS#11 session.
+type tlsSigner struct {
+	pool    sPool
+	public  crypto.PublicKey
+	timeout time.Duration
+}
+
+// NewTLSSigner returns a crypto.Signer for the key with the specified identifier which can be
+// used as the private key of the TLS server certificate. certSign must be created by NewCertSign.
+// If sessions is positive, the handshakes sign with a pool of as many sessions of their own, apart
+// from the signing requests of the key; otherwise, or if the key is not in a single slot, such as a
+// threshold key, they share the sessions of the key.
+func NewTLSSigner(certSign crypki.CertSign, keyIdentifier string, sessions int) (crypto.Signer, error) {
+	s, ok := certSign.(*signer)
+	if !ok {
+		return nil, errors.New("cert signer is not backed by a PKCS#11 device")
+	}
+	pool, ok := s.getPool(keyIdentifier)
+	if !ok {
+		return nil, fmt.Errorf("unknown key identifier %q", keyIdentifier)
+	}
+	if p, ok := pool.(*SignerPool); ok && sessions > 0 {
+		dedicated, err := p.open(sessions)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open the TLS sessions of key %q: %v", keyIdentifier, err)
+		}
+		pool = dedicated
+	}
+	// Cache the public key so that handshakes don't need a session to look it up.
+	signer := pool.get()
+	defer pool.put(signer)
+	public, err := publicKey(signer)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %v", keyIdentifier, err)
+	}
+	return &tlsSigner{pool: pool, public: public, timeout: tlsSignTimeout}, nil
+}
+
+// Public returns crypto public key.
+func (t *tlsSigner) Public() crypto.PublicKey {
+	return t.public
+}
+
+// Sign signs the digest using a signer from the pool, or fails if none is available within the timeout
+// of the signer. It is part of the crypto.Signer interface.
+func (t *tlsSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	signer, err := getSigner(ctx, t.pool, crypki.PriorityNormal)
+	if err != nil {
+		return nil, fmt.Errorf("no session of the TLS server key available: %v", err)
+	}
+	defer t.pool.put(signer)
+	// PKCS#11 signing errors are raised as panics, which shouldn't tear down the connection goroutine.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to sign: %v", r)
+		}
+	}()
+	return signer.Sign(rand, digest, opts)
+}
@@ -0,0 +1,193 @@
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/yahoo/crypki"
+	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/pkcs11/mock_pkcs11"
+)
+
+func TestNewTLSSigner(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		identifier  string
+		expectError bool
+	}{
+		"good-identifier": {defaultIdentifier, false},
+		"bad-identifier":  {badIdentifier, true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer, err := initMockSigner(false)
+			if err != nil {
+				t.Fatalf("unable to init mock signer: %v", err)
+			}
+			_, err = NewTLSSigner(signer, tt.identifier, 0)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestTLSSignerHandshake(t *testing.T) {
+	t.Parallel()
+	const nHandshakes = 5
+	testcases := map[string]struct {
+		maxVersion uint16
+	}{
+		"tls-1.2": {tls.VersionTLS12},
+		"tls-1.3": {tls.VersionTLS13},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			mockPool, err := newMockSignerPool(false)
+			if err != nil {
+				t.Fatalf("unable to init mock signer pool: %v", err)
+			}
+			// Only one session is available, handshakes must release it after each signing operation.
+			pool := &SignerPool{signers: make(chan signerWithSignAlgorithm, 1)}
+			pool.put(mockPool.get())
+			s := &signer{sPool: map[string]sPool{defaultIdentifier: pool}}
+
+			tlsKey, err := NewTLSSigner(s, defaultIdentifier, 0)
+			if err != nil {
+				t.Fatalf("unable to create TLS signer: %v", err)
+			}
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(1),
+				Subject:      pkix.Name{CommonName: "localhost"},
+				DNSNames:     []string{"localhost"},
+				NotBefore:    time.Now().Add(-time.Hour),
+				NotAfter:     time.Now().Add(time.Hour),
+				KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+				ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, template, tlsKey.Public(), tlsKey)
+			if err != nil {
+				t.Fatalf("unable to create TLS certificate: %v", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatalf("unable to parse TLS certificate: %v", err)
+			}
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			serverConfig := &tls.Config{
+				Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: tlsKey}},
+				MaxVersion:   tt.maxVersion,
+			}
+			clientConfig := &tls.Config{RootCAs: roots, ServerName: "localhost", MaxVersion: tt.maxVersion}
+
+			for i := 0; i < nHandshakes; i++ {
+				serverConn, clientConn := net.Pipe()
+				errCh := make(chan error, 1)
+				go func() {
+					defer serverConn.Close()
+					errCh <- tls.Server(serverConn, serverConfig).Handshake()
+				}()
+				client := tls.Client(clientConn, clientConfig)
+				if err := client.Handshake(); err != nil {
+					t.Fatalf("client handshake failed: %v", err)
+				}
+				if err := <-errCh; err != nil {
+					t.Fatalf("server handshake failed: %v", err)
+				}
+				if got := client.ConnectionState().Version; got != tt.maxVersion {
+					t.Errorf("unexpected TLS version: got %x, want %x", got, tt.maxVersion)
+				}
+				clientConn.Close()
+			}
+			if len(pool.signers) != 1 {
+				t.Errorf("signer was not returned to the pool, pool size: %d", len(pool.signers))
+			}
+		})
+	}
+}
+
+func TestTLSSignerSessions(t *testing.T) {
+	t.Parallel()
+	mockctrl := gomock.NewController(t)
+	defer mockctrl.Finish()
+	pinPath := writePinFile(t, "1234")
+	defer os.Remove(pinPath)
+
+	var mu sync.Mutex
+	var lastSession p11.SessionHandle
+	mockCtx := mock_pkcs11.NewMockPKCS11Ctx(mockctrl)
+	mockCtx.EXPECT().OpenSession(uint(1), gomock.Any()).DoAndReturn(func(uint, uint) (p11.SessionHandle, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lastSession++
+		return lastSession, nil
+	}).AnyTimes()
+	mockCtx.EXPECT().Login(gomock.Any(), p11.CKU_USER, "1234").Return(nil).AnyTimes()
+	mockCtx.EXPECT().FindObjectsInit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().FindObjects(gomock.Any(), gomock.Any()).Return([]p11.ObjectHandle{1}, false, nil).AnyTimes()
+	mockCtx.EXPECT().FindObjectsFinal(gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().GetAttributeValue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*p11.Attribute{
+		p11.NewAttribute(p11.CKA_MODULUS, big.NewInt(3233).Bytes()),
+		p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, big.NewInt(17).Bytes()),
+	}, nil).AnyTimes()
+	mockCtx.EXPECT().SignInit(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockCtx.EXPECT().Sign(gomock.Any(), gomock.Any()).Return([]byte("signature"), nil).AnyTimes()
+
+	s := &signer{
+		x509CACerts: make(map[string]*x509.Certificate),
+		sPool:       make(map[string]sPool),
+		modules:     map[string]*module{"": {context: mockCtx, slotPins: make(map[uint]string)}},
+	}
+	keys := []config.KeyConfig{{Identifier: "key1", SlotNumber: 1, UserPinPath: pinPath, KeyLabel: "foo", SessionPoolSize: 1, KeyType: crypki.RSA}}
+	if err := s.loadKeys(keys, nil, "", nil); err != nil {
+		t.Fatalf("unable to load keys: %v", err)
+	}
+	pool, _ := s.getPool("key1")
+	digest := sha256.Sum256([]byte("good"))
+
+	// The handshakes sign with sessions of their own while the only session of the key is in use.
+	dedicated, err := NewTLSSigner(s, "key1", 2)
+	if err != nil {
+		t.Fatalf("unable to create TLS signer: %v", err)
+	}
+	if got := cap(dedicated.(*tlsSigner).pool.(*SignerPool).signers); got != 2 {
+		t.Errorf("got %d TLS sessions, want 2", got)
+	}
+	held := pool.get()
+	if _, err := dedicated.Sign(nil, digest[:], crypto.SHA256); err != nil {
+		t.Errorf("unable to sign with the TLS sessions while the key is in use: %v", err)
+	}
+
+	// The handshakes sharing the sessions of the key give up once no session is available in time.
+	pool.put(held)
+	shared, err := NewTLSSigner(s, "key1", 0)
+	if err != nil {
+		t.Fatalf("unable to create TLS signer: %v", err)
+	}
+	shared.(*tlsSigner).timeout = 10 * time.Millisecond
+	held = pool.get()
+	if _, err := shared.Sign(nil, digest[:], crypto.SHA256); err == nil {
+		t.Error("expected error while the session of the key is in use, but got nil")
+	}
+	pool.put(held)
+	if _, err := shared.Sign(nil, digest[:], crypto.SHA256); err != nil {
+		t.Errorf("unable to sign with the session of the key: %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	proto "github.com/yahoo/crypki/proto"
 	context "golang.org/x/net/context"
 	grpc "google.golang.org/grpc"
+	metadata "google.golang.org/grpc/metadata"
 	reflect "reflect"
 )
 
@@ -37,7 +38,7 @@ func (m *MockSigningClient) EXPECT() *MockSigningClientMockRecorder {
 }
 
 // GetX509CertificateAvailableSigningKeys mocks base method
-func (m *MockSigningClient) GetX509CertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
+func (m *MockSigningClient) GetX509CertificateAvailableSigningKeys(ctx context.Context, in *proto.KeyFilter, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, in}
 	for _, a := range opts {
@@ -76,6 +77,26 @@ func (mr *MockSigningClientMockRecorder) GetX509CACertificate(ctx, in interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetX509CACertificate", reflect.TypeOf((*MockSigningClient)(nil).GetX509CACertificate), varargs...)
 }
 
+// ExportX509CACertificatePKCS12 mocks base method
+func (m *MockSigningClient) ExportX509CACertificatePKCS12(ctx context.Context, in *proto.X509CACertificatePKCS12Request, opts ...grpc.CallOption) (*proto.PKCS12, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportX509CACertificatePKCS12", varargs...)
+	ret0, _ := ret[0].(*proto.PKCS12)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportX509CACertificatePKCS12 indicates an expected call of ExportX509CACertificatePKCS12
+func (mr *MockSigningClientMockRecorder) ExportX509CACertificatePKCS12(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportX509CACertificatePKCS12", reflect.TypeOf((*MockSigningClient)(nil).ExportX509CACertificatePKCS12), varargs...)
+}
+
 // PostX509Certificate mocks base method
 func (m *MockSigningClient) PostX509Certificate(ctx context.Context, in *proto.X509CertificateSigningRequest, opts ...grpc.CallOption) (*proto.X509Certificate, error) {
 	m.ctrl.T.Helper()
@@ -96,8 +117,28 @@ func (mr *MockSigningClientMockRecorder) PostX509Certificate(ctx, in interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostX509Certificate", reflect.TypeOf((*MockSigningClient)(nil).PostX509Certificate), varargs...)
 }
 
+// PreviewX509Certificate mocks base method
+func (m *MockSigningClient) PreviewX509Certificate(ctx context.Context, in *proto.X509CertificateSigningRequest, opts ...grpc.CallOption) (*proto.X509CertificatePreview, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PreviewX509Certificate", varargs...)
+	ret0, _ := ret[0].(*proto.X509CertificatePreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewX509Certificate indicates an expected call of PreviewX509Certificate
+func (mr *MockSigningClientMockRecorder) PreviewX509Certificate(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewX509Certificate", reflect.TypeOf((*MockSigningClient)(nil).PreviewX509Certificate), varargs...)
+}
+
 // GetUserSSHCertificateAvailableSigningKeys mocks base method
-func (m *MockSigningClient) GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
+func (m *MockSigningClient) GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, in *proto.KeyFilter, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, in}
 	for _, a := range opts {
@@ -157,7 +198,7 @@ func (mr *MockSigningClientMockRecorder) PostUserSSHCertificate(ctx, in interfac
 }
 
 // GetHostSSHCertificateAvailableSigningKeys mocks base method
-func (m *MockSigningClient) GetHostSSHCertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
+func (m *MockSigningClient) GetHostSSHCertificateAvailableSigningKeys(ctx context.Context, in *proto.KeyFilter, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, in}
 	for _, a := range opts {
@@ -216,8 +257,28 @@ func (mr *MockSigningClientMockRecorder) PostHostSSHCertificate(ctx, in interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostHostSSHCertificate", reflect.TypeOf((*MockSigningClient)(nil).PostHostSSHCertificate), varargs...)
 }
 
+// VerifySSHCertificate mocks base method
+func (m *MockSigningClient) VerifySSHCertificate(ctx context.Context, in *proto.SSHCertificateVerificationRequest, opts ...grpc.CallOption) (*proto.SSHCertificateVerification, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifySSHCertificate", varargs...)
+	ret0, _ := ret[0].(*proto.SSHCertificateVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySSHCertificate indicates an expected call of VerifySSHCertificate
+func (mr *MockSigningClientMockRecorder) VerifySSHCertificate(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySSHCertificate", reflect.TypeOf((*MockSigningClient)(nil).VerifySSHCertificate), varargs...)
+}
+
 // GetBlobAvailableSigningKeys mocks base method
-func (m *MockSigningClient) GetBlobAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
+func (m *MockSigningClient) GetBlobAvailableSigningKeys(ctx context.Context, in *proto.KeyFilter, opts ...grpc.CallOption) (*proto.KeyMetas, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, in}
 	for _, a := range opts {
@@ -276,205 +337,1542 @@ func (mr *MockSigningClientMockRecorder) PostSignBlob(ctx, in interface{}, opts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlob", reflect.TypeOf((*MockSigningClient)(nil).PostSignBlob), varargs...)
 }
 
-// MockSigningServer is a mock of SigningServer interface
-type MockSigningServer struct {
-	ctrl     *gomock.Controller
-	recorder *MockSigningServerMockRecorder
+// PostSignBlobBatch mocks base method
+func (m *MockSigningClient) PostSignBlobBatch(ctx context.Context, in *proto.BlobBatchSigningRequest, opts ...grpc.CallOption) (*proto.BlobBatchSignatures, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignBlobBatch", varargs...)
+	ret0, _ := ret[0].(*proto.BlobBatchSignatures)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// MockSigningServerMockRecorder is the mock recorder for MockSigningServer
-type MockSigningServerMockRecorder struct {
-	mock *MockSigningServer
+// PostSignBlobBatch indicates an expected call of PostSignBlobBatch
+func (mr *MockSigningClientMockRecorder) PostSignBlobBatch(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobBatch", reflect.TypeOf((*MockSigningClient)(nil).PostSignBlobBatch), varargs...)
 }
 
-// NewMockSigningServer creates a new mock instance
-func NewMockSigningServer(ctrl *gomock.Controller) *MockSigningServer {
-	mock := &MockSigningServer{ctrl: ctrl}
-	mock.recorder = &MockSigningServerMockRecorder{mock}
-	return mock
+// PostSignBlobBatchStream mocks base method
+func (m *MockSigningClient) PostSignBlobBatchStream(ctx context.Context, in *proto.BlobBatchSigningRequest, opts ...grpc.CallOption) (proto.Signing_PostSignBlobBatchStreamClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignBlobBatchStream", varargs...)
+	ret0, _ := ret[0].(proto.Signing_PostSignBlobBatchStreamClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockSigningServer) EXPECT() *MockSigningServerMockRecorder {
-	return m.recorder
+// PostSignBlobBatchStream indicates an expected call of PostSignBlobBatchStream
+func (mr *MockSigningClientMockRecorder) PostSignBlobBatchStream(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobBatchStream", reflect.TypeOf((*MockSigningClient)(nil).PostSignBlobBatchStream), varargs...)
 }
 
-// GetX509CertificateAvailableSigningKeys mocks base method
-func (m *MockSigningServer) GetX509CertificateAvailableSigningKeys(arg0 context.Context, arg1 *empty.Empty) (*proto.KeyMetas, error) {
+// PostSignBlobCMS mocks base method
+func (m *MockSigningClient) PostSignBlobCMS(ctx context.Context, in *proto.CMSSigningRequest, opts ...grpc.CallOption) (*proto.CMSSignature, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetX509CertificateAvailableSigningKeys", arg0, arg1)
-	ret0, _ := ret[0].(*proto.KeyMetas)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignBlobCMS", varargs...)
+	ret0, _ := ret[0].(*proto.CMSSignature)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetX509CertificateAvailableSigningKeys indicates an expected call of GetX509CertificateAvailableSigningKeys
-func (mr *MockSigningServerMockRecorder) GetX509CertificateAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+// PostSignBlobCMS indicates an expected call of PostSignBlobCMS
+func (mr *MockSigningClientMockRecorder) PostSignBlobCMS(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetX509CertificateAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetX509CertificateAvailableSigningKeys), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobCMS", reflect.TypeOf((*MockSigningClient)(nil).PostSignBlobCMS), varargs...)
 }
 
-// GetX509CACertificate mocks base method
-func (m *MockSigningServer) GetX509CACertificate(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.X509Certificate, error) {
+// PostEphemeralSignature mocks base method
+func (m *MockSigningClient) PostEphemeralSignature(ctx context.Context, in *proto.EphemeralSigningRequest, opts ...grpc.CallOption) (*proto.EphemeralSignature, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetX509CACertificate", arg0, arg1)
-	ret0, _ := ret[0].(*proto.X509Certificate)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostEphemeralSignature", varargs...)
+	ret0, _ := ret[0].(*proto.EphemeralSignature)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetX509CACertificate indicates an expected call of GetX509CACertificate
-func (mr *MockSigningServerMockRecorder) GetX509CACertificate(arg0, arg1 interface{}) *gomock.Call {
+// PostEphemeralSignature indicates an expected call of PostEphemeralSignature
+func (mr *MockSigningClientMockRecorder) PostEphemeralSignature(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetX509CACertificate", reflect.TypeOf((*MockSigningServer)(nil).GetX509CACertificate), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostEphemeralSignature", reflect.TypeOf((*MockSigningClient)(nil).PostEphemeralSignature), varargs...)
 }
 
-// PostX509Certificate mocks base method
-func (m *MockSigningServer) PostX509Certificate(arg0 context.Context, arg1 *proto.X509CertificateSigningRequest) (*proto.X509Certificate, error) {
+// PostTenantSignature mocks base method
+func (m *MockSigningClient) PostTenantSignature(ctx context.Context, in *proto.TenantSigningRequest, opts ...grpc.CallOption) (*proto.TenantSignature, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostX509Certificate", arg0, arg1)
-	ret0, _ := ret[0].(*proto.X509Certificate)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostTenantSignature", varargs...)
+	ret0, _ := ret[0].(*proto.TenantSignature)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PostX509Certificate indicates an expected call of PostX509Certificate
-func (mr *MockSigningServerMockRecorder) PostX509Certificate(arg0, arg1 interface{}) *gomock.Call {
+// PostTenantSignature indicates an expected call of PostTenantSignature
+func (mr *MockSigningClientMockRecorder) PostTenantSignature(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostX509Certificate", reflect.TypeOf((*MockSigningServer)(nil).PostX509Certificate), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTenantSignature", reflect.TypeOf((*MockSigningClient)(nil).PostTenantSignature), varargs...)
 }
 
-// GetUserSSHCertificateAvailableSigningKeys mocks base method
-func (m *MockSigningServer) GetUserSSHCertificateAvailableSigningKeys(arg0 context.Context, arg1 *empty.Empty) (*proto.KeyMetas, error) {
+// PostSignBlobStream mocks base method
+func (m *MockSigningClient) PostSignBlobStream(ctx context.Context, opts ...grpc.CallOption) (proto.Signing_PostSignBlobStreamClient, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserSSHCertificateAvailableSigningKeys", arg0, arg1)
-	ret0, _ := ret[0].(*proto.KeyMetas)
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignBlobStream", varargs...)
+	ret0, _ := ret[0].(proto.Signing_PostSignBlobStreamClient)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserSSHCertificateAvailableSigningKeys indicates an expected call of GetUserSSHCertificateAvailableSigningKeys
-func (mr *MockSigningServerMockRecorder) GetUserSSHCertificateAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+// PostSignBlobStream indicates an expected call of PostSignBlobStream
+func (mr *MockSigningClientMockRecorder) PostSignBlobStream(ctx interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSSHCertificateAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetUserSSHCertificateAvailableSigningKeys), arg0, arg1)
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobStream", reflect.TypeOf((*MockSigningClient)(nil).PostSignBlobStream), varargs...)
 }
 
-// GetUserSSHCertificateSigningKey mocks base method
-func (m *MockSigningServer) GetUserSSHCertificateSigningKey(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.SSHKey, error) {
+// PostTimestamp mocks base method
+func (m *MockSigningClient) PostTimestamp(ctx context.Context, in *proto.TimestampRequest, opts ...grpc.CallOption) (*proto.TimestampResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserSSHCertificateSigningKey", arg0, arg1)
-	ret0, _ := ret[0].(*proto.SSHKey)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostTimestamp", varargs...)
+	ret0, _ := ret[0].(*proto.TimestampResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserSSHCertificateSigningKey indicates an expected call of GetUserSSHCertificateSigningKey
-func (mr *MockSigningServerMockRecorder) GetUserSSHCertificateSigningKey(arg0, arg1 interface{}) *gomock.Call {
+// PostTimestamp indicates an expected call of PostTimestamp
+func (mr *MockSigningClientMockRecorder) PostTimestamp(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSSHCertificateSigningKey", reflect.TypeOf((*MockSigningServer)(nil).GetUserSSHCertificateSigningKey), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTimestamp", reflect.TypeOf((*MockSigningClient)(nil).PostTimestamp), varargs...)
 }
 
-// PostUserSSHCertificate mocks base method
-func (m *MockSigningServer) PostUserSSHCertificate(arg0 context.Context, arg1 *proto.SSHCertificateSigningRequest) (*proto.SSHKey, error) {
+// PostSignGitObject mocks base method
+func (m *MockSigningClient) PostSignGitObject(ctx context.Context, in *proto.GitSigningRequest, opts ...grpc.CallOption) (*proto.GitSignature, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostUserSSHCertificate", arg0, arg1)
-	ret0, _ := ret[0].(*proto.SSHKey)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignGitObject", varargs...)
+	ret0, _ := ret[0].(*proto.GitSignature)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PostUserSSHCertificate indicates an expected call of PostUserSSHCertificate
-func (mr *MockSigningServerMockRecorder) PostUserSSHCertificate(arg0, arg1 interface{}) *gomock.Call {
+// PostSignGitObject indicates an expected call of PostSignGitObject
+func (mr *MockSigningClientMockRecorder) PostSignGitObject(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostUserSSHCertificate", reflect.TypeOf((*MockSigningServer)(nil).PostUserSSHCertificate), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignGitObject", reflect.TypeOf((*MockSigningClient)(nil).PostSignGitObject), varargs...)
 }
 
-// GetHostSSHCertificateAvailableSigningKeys mocks base method
-func (m *MockSigningServer) GetHostSSHCertificateAvailableSigningKeys(arg0 context.Context, arg1 *empty.Empty) (*proto.KeyMetas, error) {
+// PostSignDNSSEC mocks base method
+func (m *MockSigningClient) PostSignDNSSEC(ctx context.Context, in *proto.DNSSECSigningRequest, opts ...grpc.CallOption) (*proto.DNSSECSignature, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHostSSHCertificateAvailableSigningKeys", arg0, arg1)
-	ret0, _ := ret[0].(*proto.KeyMetas)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignDNSSEC", varargs...)
+	ret0, _ := ret[0].(*proto.DNSSECSignature)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetHostSSHCertificateAvailableSigningKeys indicates an expected call of GetHostSSHCertificateAvailableSigningKeys
-func (mr *MockSigningServerMockRecorder) GetHostSSHCertificateAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+// PostSignDNSSEC indicates an expected call of PostSignDNSSEC
+func (mr *MockSigningClientMockRecorder) PostSignDNSSEC(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostSSHCertificateAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetHostSSHCertificateAvailableSigningKeys), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignDNSSEC", reflect.TypeOf((*MockSigningClient)(nil).PostSignDNSSEC), varargs...)
 }
 
-// GetHostSSHCertificateSigningKey mocks base method
-func (m *MockSigningServer) GetHostSSHCertificateSigningKey(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.SSHKey, error) {
+// PostSignAttestation mocks base method
+func (m *MockSigningClient) PostSignAttestation(ctx context.Context, in *proto.AttestationSigningRequest, opts ...grpc.CallOption) (*proto.AttestationStatement, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHostSSHCertificateSigningKey", arg0, arg1)
-	ret0, _ := ret[0].(*proto.SSHKey)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignAttestation", varargs...)
+	ret0, _ := ret[0].(*proto.AttestationStatement)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetHostSSHCertificateSigningKey indicates an expected call of GetHostSSHCertificateSigningKey
-func (mr *MockSigningServerMockRecorder) GetHostSSHCertificateSigningKey(arg0, arg1 interface{}) *gomock.Call {
+// PostSignAttestation indicates an expected call of PostSignAttestation
+func (mr *MockSigningClientMockRecorder) PostSignAttestation(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostSSHCertificateSigningKey", reflect.TypeOf((*MockSigningServer)(nil).GetHostSSHCertificateSigningKey), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignAttestation", reflect.TypeOf((*MockSigningClient)(nil).PostSignAttestation), varargs...)
 }
 
-// PostHostSSHCertificate mocks base method
-func (m *MockSigningServer) PostHostSSHCertificate(arg0 context.Context, arg1 *proto.SSHCertificateSigningRequest) (*proto.SSHKey, error) {
+// PostSignContainerImage mocks base method
+func (m *MockSigningClient) PostSignContainerImage(ctx context.Context, in *proto.ContainerImageSigningRequest, opts ...grpc.CallOption) (*proto.ContainerImageSignature, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostHostSSHCertificate", arg0, arg1)
-	ret0, _ := ret[0].(*proto.SSHKey)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostSignContainerImage", varargs...)
+	ret0, _ := ret[0].(*proto.ContainerImageSignature)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PostHostSSHCertificate indicates an expected call of PostHostSSHCertificate
-func (mr *MockSigningServerMockRecorder) PostHostSSHCertificate(arg0, arg1 interface{}) *gomock.Call {
+// PostSignContainerImage indicates an expected call of PostSignContainerImage
+func (mr *MockSigningClientMockRecorder) PostSignContainerImage(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostHostSSHCertificate", reflect.TypeOf((*MockSigningServer)(nil).PostHostSSHCertificate), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignContainerImage", reflect.TypeOf((*MockSigningClient)(nil).PostSignContainerImage), varargs...)
 }
 
-// GetBlobAvailableSigningKeys mocks base method
-func (m *MockSigningServer) GetBlobAvailableSigningKeys(arg0 context.Context, arg1 *empty.Empty) (*proto.KeyMetas, error) {
+// PostIssuanceManifest mocks base method
+func (m *MockSigningClient) PostIssuanceManifest(ctx context.Context, in *proto.IssuanceManifestRequest, opts ...grpc.CallOption) (*proto.IssuanceReceipt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlobAvailableSigningKeys", arg0, arg1)
-	ret0, _ := ret[0].(*proto.KeyMetas)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PostIssuanceManifest", varargs...)
+	ret0, _ := ret[0].(*proto.IssuanceReceipt)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBlobAvailableSigningKeys indicates an expected call of GetBlobAvailableSigningKeys
-func (mr *MockSigningServerMockRecorder) GetBlobAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+// PostIssuanceManifest indicates an expected call of PostIssuanceManifest
+func (mr *MockSigningClientMockRecorder) PostIssuanceManifest(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetBlobAvailableSigningKeys), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostIssuanceManifest", reflect.TypeOf((*MockSigningClient)(nil).PostIssuanceManifest), varargs...)
 }
 
-// GetBlobSigningKey mocks base method
-func (m *MockSigningServer) GetBlobSigningKey(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.PublicKey, error) {
+// GetKeyCapabilities mocks base method
+func (m *MockSigningClient) GetKeyCapabilities(ctx context.Context, in *proto.KeyMeta, opts ...grpc.CallOption) (*proto.KeyCapabilities, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlobSigningKey", arg0, arg1)
-	ret0, _ := ret[0].(*proto.PublicKey)
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetKeyCapabilities", varargs...)
+	ret0, _ := ret[0].(*proto.KeyCapabilities)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBlobSigningKey indicates an expected call of GetBlobSigningKey
-func (mr *MockSigningServerMockRecorder) GetBlobSigningKey(arg0, arg1 interface{}) *gomock.Call {
+// GetKeyCapabilities indicates an expected call of GetKeyCapabilities
+func (mr *MockSigningClientMockRecorder) GetKeyCapabilities(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobSigningKey", reflect.TypeOf((*MockSigningServer)(nil).GetBlobSigningKey), arg0, arg1)
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyCapabilities", reflect.TypeOf((*MockSigningClient)(nil).GetKeyCapabilities), varargs...)
 }
 
-// PostSignBlob mocks base method
-func (m *MockSigningServer) PostSignBlob(arg0 context.Context, arg1 *proto.BlobSigningRequest) (*proto.Signature, error) {
+// MockSigning_PostSignBlobBatchStreamClient is a mock of Signing_PostSignBlobBatchStreamClient interface
+type MockSigning_PostSignBlobBatchStreamClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSigning_PostSignBlobBatchStreamClientMockRecorder
+}
+
+// MockSigning_PostSignBlobBatchStreamClientMockRecorder is the mock recorder for MockSigning_PostSignBlobBatchStreamClient
+type MockSigning_PostSignBlobBatchStreamClientMockRecorder struct {
+	mock *MockSigning_PostSignBlobBatchStreamClient
+}
+
+// NewMockSigning_PostSignBlobBatchStreamClient creates a new mock instance
+func NewMockSigning_PostSignBlobBatchStreamClient(ctrl *gomock.Controller) *MockSigning_PostSignBlobBatchStreamClient {
+	mock := &MockSigning_PostSignBlobBatchStreamClient{ctrl: ctrl}
+	mock.recorder = &MockSigning_PostSignBlobBatchStreamClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSigning_PostSignBlobBatchStreamClient) EXPECT() *MockSigning_PostSignBlobBatchStreamClientMockRecorder {
+	return m.recorder
+}
+
+// Recv mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamClient) Recv() (*proto.BlobBatchEntrySignature, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PostSignBlob", arg0, arg1)
-	ret0, _ := ret[0].(*proto.Signature)
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*proto.BlobBatchEntrySignature)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PostSignBlob indicates an expected call of PostSignBlob
-func (mr *MockSigningServerMockRecorder) PostSignBlob(arg0, arg1 interface{}) *gomock.Call {
+// Recv indicates an expected call of Recv
+func (mr *MockSigning_PostSignBlobBatchStreamClientMockRecorder) Recv() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlob", reflect.TypeOf((*MockSigningServer)(nil).PostSignBlob), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamClient)(nil).Recv))
+}
+
+// Header mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header
+func (mr *MockSigning_PostSignBlobBatchStreamClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamClient)(nil).Header))
+}
+
+// Trailer mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer
+func (mr *MockSigning_PostSignBlobBatchStreamClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamClient)(nil).Trailer))
+}
+
+// CloseSend mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend
+func (mr *MockSigning_PostSignBlobBatchStreamClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamClient)(nil).CloseSend))
+}
+
+// Context mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockSigning_PostSignBlobBatchStreamClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamClient)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobBatchStreamClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockSigning_PostSignBlobBatchStreamClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamClient)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobBatchStreamClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockSigning_PostSignBlobBatchStreamClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamClient)(nil).RecvMsg), m)
+}
+
+// MockSigning_PostSignBlobStreamClient is a mock of Signing_PostSignBlobStreamClient interface
+type MockSigning_PostSignBlobStreamClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSigning_PostSignBlobStreamClientMockRecorder
+}
+
+// MockSigning_PostSignBlobStreamClientMockRecorder is the mock recorder for MockSigning_PostSignBlobStreamClient
+type MockSigning_PostSignBlobStreamClientMockRecorder struct {
+	mock *MockSigning_PostSignBlobStreamClient
+}
+
+// NewMockSigning_PostSignBlobStreamClient creates a new mock instance
+func NewMockSigning_PostSignBlobStreamClient(ctrl *gomock.Controller) *MockSigning_PostSignBlobStreamClient {
+	mock := &MockSigning_PostSignBlobStreamClient{ctrl: ctrl}
+	mock.recorder = &MockSigning_PostSignBlobStreamClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSigning_PostSignBlobStreamClient) EXPECT() *MockSigning_PostSignBlobStreamClientMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockSigning_PostSignBlobStreamClient) Send(arg0 *proto.BlobStreamRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).Send), arg0)
+}
+
+// CloseAndRecv mocks base method
+func (m *MockSigning_PostSignBlobStreamClient) CloseAndRecv() (*proto.BlobStreamSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*proto.BlobStreamSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAndRecv indicates an expected call of CloseAndRecv
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) CloseAndRecv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).CloseAndRecv))
+}
+
+// Header mocks base method
+func (m *MockSigning_PostSignBlobStreamClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).Header))
+}
+
+// Trailer mocks base method
+func (m *MockSigning_PostSignBlobStreamClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).Trailer))
+}
+
+// CloseSend mocks base method
+func (m *MockSigning_PostSignBlobStreamClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).CloseSend))
+}
+
+// Context mocks base method
+func (m *MockSigning_PostSignBlobStreamClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobStreamClient) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobStreamClient) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockSigning_PostSignBlobStreamClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockSigning_PostSignBlobStreamClient)(nil).RecvMsg), m)
+}
+
+// MockSigningServer is a mock of SigningServer interface
+type MockSigningServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSigningServerMockRecorder
+}
+
+// MockSigningServerMockRecorder is the mock recorder for MockSigningServer
+type MockSigningServerMockRecorder struct {
+	mock *MockSigningServer
+}
+
+// NewMockSigningServer creates a new mock instance
+func NewMockSigningServer(ctrl *gomock.Controller) *MockSigningServer {
+	mock := &MockSigningServer{ctrl: ctrl}
+	mock.recorder = &MockSigningServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSigningServer) EXPECT() *MockSigningServerMockRecorder {
+	return m.recorder
+}
+
+// GetX509CertificateAvailableSigningKeys mocks base method
+func (m *MockSigningServer) GetX509CertificateAvailableSigningKeys(arg0 context.Context, arg1 *proto.KeyFilter) (*proto.KeyMetas, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetX509CertificateAvailableSigningKeys", arg0, arg1)
+	ret0, _ := ret[0].(*proto.KeyMetas)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetX509CertificateAvailableSigningKeys indicates an expected call of GetX509CertificateAvailableSigningKeys
+func (mr *MockSigningServerMockRecorder) GetX509CertificateAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetX509CertificateAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetX509CertificateAvailableSigningKeys), arg0, arg1)
+}
+
+// GetX509CACertificate mocks base method
+func (m *MockSigningServer) GetX509CACertificate(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.X509Certificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetX509CACertificate", arg0, arg1)
+	ret0, _ := ret[0].(*proto.X509Certificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetX509CACertificate indicates an expected call of GetX509CACertificate
+func (mr *MockSigningServerMockRecorder) GetX509CACertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetX509CACertificate", reflect.TypeOf((*MockSigningServer)(nil).GetX509CACertificate), arg0, arg1)
+}
+
+// ExportX509CACertificatePKCS12 mocks base method
+func (m *MockSigningServer) ExportX509CACertificatePKCS12(arg0 context.Context, arg1 *proto.X509CACertificatePKCS12Request) (*proto.PKCS12, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportX509CACertificatePKCS12", arg0, arg1)
+	ret0, _ := ret[0].(*proto.PKCS12)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportX509CACertificatePKCS12 indicates an expected call of ExportX509CACertificatePKCS12
+func (mr *MockSigningServerMockRecorder) ExportX509CACertificatePKCS12(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportX509CACertificatePKCS12", reflect.TypeOf((*MockSigningServer)(nil).ExportX509CACertificatePKCS12), arg0, arg1)
+}
+
+// PostX509Certificate mocks base method
+func (m *MockSigningServer) PostX509Certificate(arg0 context.Context, arg1 *proto.X509CertificateSigningRequest) (*proto.X509Certificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostX509Certificate", arg0, arg1)
+	ret0, _ := ret[0].(*proto.X509Certificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostX509Certificate indicates an expected call of PostX509Certificate
+func (mr *MockSigningServerMockRecorder) PostX509Certificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostX509Certificate", reflect.TypeOf((*MockSigningServer)(nil).PostX509Certificate), arg0, arg1)
+}
+
+// PreviewX509Certificate mocks base method
+func (m *MockSigningServer) PreviewX509Certificate(arg0 context.Context, arg1 *proto.X509CertificateSigningRequest) (*proto.X509CertificatePreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewX509Certificate", arg0, arg1)
+	ret0, _ := ret[0].(*proto.X509CertificatePreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewX509Certificate indicates an expected call of PreviewX509Certificate
+func (mr *MockSigningServerMockRecorder) PreviewX509Certificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewX509Certificate", reflect.TypeOf((*MockSigningServer)(nil).PreviewX509Certificate), arg0, arg1)
+}
+
+// GetUserSSHCertificateAvailableSigningKeys mocks base method
+func (m *MockSigningServer) GetUserSSHCertificateAvailableSigningKeys(arg0 context.Context, arg1 *proto.KeyFilter) (*proto.KeyMetas, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSSHCertificateAvailableSigningKeys", arg0, arg1)
+	ret0, _ := ret[0].(*proto.KeyMetas)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSSHCertificateAvailableSigningKeys indicates an expected call of GetUserSSHCertificateAvailableSigningKeys
+func (mr *MockSigningServerMockRecorder) GetUserSSHCertificateAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSSHCertificateAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetUserSSHCertificateAvailableSigningKeys), arg0, arg1)
+}
+
+// GetUserSSHCertificateSigningKey mocks base method
+func (m *MockSigningServer) GetUserSSHCertificateSigningKey(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.SSHKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSSHCertificateSigningKey", arg0, arg1)
+	ret0, _ := ret[0].(*proto.SSHKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSSHCertificateSigningKey indicates an expected call of GetUserSSHCertificateSigningKey
+func (mr *MockSigningServerMockRecorder) GetUserSSHCertificateSigningKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSSHCertificateSigningKey", reflect.TypeOf((*MockSigningServer)(nil).GetUserSSHCertificateSigningKey), arg0, arg1)
+}
+
+// PostUserSSHCertificate mocks base method
+func (m *MockSigningServer) PostUserSSHCertificate(arg0 context.Context, arg1 *proto.SSHCertificateSigningRequest) (*proto.SSHKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostUserSSHCertificate", arg0, arg1)
+	ret0, _ := ret[0].(*proto.SSHKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostUserSSHCertificate indicates an expected call of PostUserSSHCertificate
+func (mr *MockSigningServerMockRecorder) PostUserSSHCertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostUserSSHCertificate", reflect.TypeOf((*MockSigningServer)(nil).PostUserSSHCertificate), arg0, arg1)
+}
+
+// GetHostSSHCertificateAvailableSigningKeys mocks base method
+func (m *MockSigningServer) GetHostSSHCertificateAvailableSigningKeys(arg0 context.Context, arg1 *proto.KeyFilter) (*proto.KeyMetas, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHostSSHCertificateAvailableSigningKeys", arg0, arg1)
+	ret0, _ := ret[0].(*proto.KeyMetas)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHostSSHCertificateAvailableSigningKeys indicates an expected call of GetHostSSHCertificateAvailableSigningKeys
+func (mr *MockSigningServerMockRecorder) GetHostSSHCertificateAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostSSHCertificateAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetHostSSHCertificateAvailableSigningKeys), arg0, arg1)
+}
+
+// GetHostSSHCertificateSigningKey mocks base method
+func (m *MockSigningServer) GetHostSSHCertificateSigningKey(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.SSHKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHostSSHCertificateSigningKey", arg0, arg1)
+	ret0, _ := ret[0].(*proto.SSHKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHostSSHCertificateSigningKey indicates an expected call of GetHostSSHCertificateSigningKey
+func (mr *MockSigningServerMockRecorder) GetHostSSHCertificateSigningKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostSSHCertificateSigningKey", reflect.TypeOf((*MockSigningServer)(nil).GetHostSSHCertificateSigningKey), arg0, arg1)
+}
+
+// PostHostSSHCertificate mocks base method
+func (m *MockSigningServer) PostHostSSHCertificate(arg0 context.Context, arg1 *proto.SSHCertificateSigningRequest) (*proto.SSHKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostHostSSHCertificate", arg0, arg1)
+	ret0, _ := ret[0].(*proto.SSHKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostHostSSHCertificate indicates an expected call of PostHostSSHCertificate
+func (mr *MockSigningServerMockRecorder) PostHostSSHCertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostHostSSHCertificate", reflect.TypeOf((*MockSigningServer)(nil).PostHostSSHCertificate), arg0, arg1)
+}
+
+// VerifySSHCertificate mocks base method
+func (m *MockSigningServer) VerifySSHCertificate(arg0 context.Context, arg1 *proto.SSHCertificateVerificationRequest) (*proto.SSHCertificateVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySSHCertificate", arg0, arg1)
+	ret0, _ := ret[0].(*proto.SSHCertificateVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySSHCertificate indicates an expected call of VerifySSHCertificate
+func (mr *MockSigningServerMockRecorder) VerifySSHCertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySSHCertificate", reflect.TypeOf((*MockSigningServer)(nil).VerifySSHCertificate), arg0, arg1)
+}
+
+// GetBlobAvailableSigningKeys mocks base method
+func (m *MockSigningServer) GetBlobAvailableSigningKeys(arg0 context.Context, arg1 *proto.KeyFilter) (*proto.KeyMetas, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobAvailableSigningKeys", arg0, arg1)
+	ret0, _ := ret[0].(*proto.KeyMetas)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlobAvailableSigningKeys indicates an expected call of GetBlobAvailableSigningKeys
+func (mr *MockSigningServerMockRecorder) GetBlobAvailableSigningKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobAvailableSigningKeys", reflect.TypeOf((*MockSigningServer)(nil).GetBlobAvailableSigningKeys), arg0, arg1)
+}
+
+// GetBlobSigningKey mocks base method
+func (m *MockSigningServer) GetBlobSigningKey(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.PublicKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobSigningKey", arg0, arg1)
+	ret0, _ := ret[0].(*proto.PublicKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlobSigningKey indicates an expected call of GetBlobSigningKey
+func (mr *MockSigningServerMockRecorder) GetBlobSigningKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobSigningKey", reflect.TypeOf((*MockSigningServer)(nil).GetBlobSigningKey), arg0, arg1)
+}
+
+// PostSignBlob mocks base method
+func (m *MockSigningServer) PostSignBlob(arg0 context.Context, arg1 *proto.BlobSigningRequest) (*proto.Signature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignBlob", arg0, arg1)
+	ret0, _ := ret[0].(*proto.Signature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostSignBlob indicates an expected call of PostSignBlob
+func (mr *MockSigningServerMockRecorder) PostSignBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlob", reflect.TypeOf((*MockSigningServer)(nil).PostSignBlob), arg0, arg1)
+}
+
+// PostSignBlobBatch mocks base method
+func (m *MockSigningServer) PostSignBlobBatch(arg0 context.Context, arg1 *proto.BlobBatchSigningRequest) (*proto.BlobBatchSignatures, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignBlobBatch", arg0, arg1)
+	ret0, _ := ret[0].(*proto.BlobBatchSignatures)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostSignBlobBatch indicates an expected call of PostSignBlobBatch
+func (mr *MockSigningServerMockRecorder) PostSignBlobBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobBatch", reflect.TypeOf((*MockSigningServer)(nil).PostSignBlobBatch), arg0, arg1)
+}
+
+// PostSignBlobBatchStream mocks base method
+func (m *MockSigningServer) PostSignBlobBatchStream(arg0 *proto.BlobBatchSigningRequest, arg1 proto.Signing_PostSignBlobBatchStreamServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignBlobBatchStream", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostSignBlobBatchStream indicates an expected call of PostSignBlobBatchStream
+func (mr *MockSigningServerMockRecorder) PostSignBlobBatchStream(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobBatchStream", reflect.TypeOf((*MockSigningServer)(nil).PostSignBlobBatchStream), arg0, arg1)
+}
+
+// PostSignBlobCMS mocks base method
+func (m *MockSigningServer) PostSignBlobCMS(arg0 context.Context, arg1 *proto.CMSSigningRequest) (*proto.CMSSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignBlobCMS", arg0, arg1)
+	ret0, _ := ret[0].(*proto.CMSSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostSignBlobCMS indicates an expected call of PostSignBlobCMS
+func (mr *MockSigningServerMockRecorder) PostSignBlobCMS(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobCMS", reflect.TypeOf((*MockSigningServer)(nil).PostSignBlobCMS), arg0, arg1)
+}
+
+// PostEphemeralSignature mocks base method
+func (m *MockSigningServer) PostEphemeralSignature(arg0 context.Context, arg1 *proto.EphemeralSigningRequest) (*proto.EphemeralSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostEphemeralSignature", arg0, arg1)
+	ret0, _ := ret[0].(*proto.EphemeralSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostEphemeralSignature indicates an expected call of PostEphemeralSignature
+func (mr *MockSigningServerMockRecorder) PostEphemeralSignature(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostEphemeralSignature", reflect.TypeOf((*MockSigningServer)(nil).PostEphemeralSignature), arg0, arg1)
+}
+
+// PostTenantSignature mocks base method
+func (m *MockSigningServer) PostTenantSignature(arg0 context.Context, arg1 *proto.TenantSigningRequest) (*proto.TenantSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostTenantSignature", arg0, arg1)
+	ret0, _ := ret[0].(*proto.TenantSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostTenantSignature indicates an expected call of PostTenantSignature
+func (mr *MockSigningServerMockRecorder) PostTenantSignature(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTenantSignature", reflect.TypeOf((*MockSigningServer)(nil).PostTenantSignature), arg0, arg1)
+}
+
+// PostSignBlobStream mocks base method
+func (m *MockSigningServer) PostSignBlobStream(arg0 proto.Signing_PostSignBlobStreamServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignBlobStream", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostSignBlobStream indicates an expected call of PostSignBlobStream
+func (mr *MockSigningServerMockRecorder) PostSignBlobStream(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignBlobStream", reflect.TypeOf((*MockSigningServer)(nil).PostSignBlobStream), arg0)
+}
+
+// PostTimestamp mocks base method
+func (m *MockSigningServer) PostTimestamp(arg0 context.Context, arg1 *proto.TimestampRequest) (*proto.TimestampResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostTimestamp", arg0, arg1)
+	ret0, _ := ret[0].(*proto.TimestampResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostTimestamp indicates an expected call of PostTimestamp
+func (mr *MockSigningServerMockRecorder) PostTimestamp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTimestamp", reflect.TypeOf((*MockSigningServer)(nil).PostTimestamp), arg0, arg1)
+}
+
+// PostSignGitObject mocks base method
+func (m *MockSigningServer) PostSignGitObject(arg0 context.Context, arg1 *proto.GitSigningRequest) (*proto.GitSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignGitObject", arg0, arg1)
+	ret0, _ := ret[0].(*proto.GitSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostSignGitObject indicates an expected call of PostSignGitObject
+func (mr *MockSigningServerMockRecorder) PostSignGitObject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignGitObject", reflect.TypeOf((*MockSigningServer)(nil).PostSignGitObject), arg0, arg1)
+}
+
+// PostSignDNSSEC mocks base method
+func (m *MockSigningServer) PostSignDNSSEC(arg0 context.Context, arg1 *proto.DNSSECSigningRequest) (*proto.DNSSECSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignDNSSEC", arg0, arg1)
+	ret0, _ := ret[0].(*proto.DNSSECSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostSignDNSSEC indicates an expected call of PostSignDNSSEC
+func (mr *MockSigningServerMockRecorder) PostSignDNSSEC(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignDNSSEC", reflect.TypeOf((*MockSigningServer)(nil).PostSignDNSSEC), arg0, arg1)
+}
+
+// PostSignAttestation mocks base method
+func (m *MockSigningServer) PostSignAttestation(arg0 context.Context, arg1 *proto.AttestationSigningRequest) (*proto.AttestationStatement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignAttestation", arg0, arg1)
+	ret0, _ := ret[0].(*proto.AttestationStatement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostSignAttestation indicates an expected call of PostSignAttestation
+func (mr *MockSigningServerMockRecorder) PostSignAttestation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignAttestation", reflect.TypeOf((*MockSigningServer)(nil).PostSignAttestation), arg0, arg1)
+}
+
+// PostSignContainerImage mocks base method
+func (m *MockSigningServer) PostSignContainerImage(arg0 context.Context, arg1 *proto.ContainerImageSigningRequest) (*proto.ContainerImageSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostSignContainerImage", arg0, arg1)
+	ret0, _ := ret[0].(*proto.ContainerImageSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostSignContainerImage indicates an expected call of PostSignContainerImage
+func (mr *MockSigningServerMockRecorder) PostSignContainerImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostSignContainerImage", reflect.TypeOf((*MockSigningServer)(nil).PostSignContainerImage), arg0, arg1)
+}
+
+// PostIssuanceManifest mocks base method
+func (m *MockSigningServer) PostIssuanceManifest(arg0 context.Context, arg1 *proto.IssuanceManifestRequest) (*proto.IssuanceReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostIssuanceManifest", arg0, arg1)
+	ret0, _ := ret[0].(*proto.IssuanceReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostIssuanceManifest indicates an expected call of PostIssuanceManifest
+func (mr *MockSigningServerMockRecorder) PostIssuanceManifest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostIssuanceManifest", reflect.TypeOf((*MockSigningServer)(nil).PostIssuanceManifest), arg0, arg1)
+}
+
+// GetKeyCapabilities mocks base method
+func (m *MockSigningServer) GetKeyCapabilities(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.KeyCapabilities, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyCapabilities", arg0, arg1)
+	ret0, _ := ret[0].(*proto.KeyCapabilities)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyCapabilities indicates an expected call of GetKeyCapabilities
+func (mr *MockSigningServerMockRecorder) GetKeyCapabilities(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyCapabilities", reflect.TypeOf((*MockSigningServer)(nil).GetKeyCapabilities), arg0, arg1)
+}
+
+// MockSigning_PostSignBlobBatchStreamServer is a mock of Signing_PostSignBlobBatchStreamServer interface
+type MockSigning_PostSignBlobBatchStreamServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSigning_PostSignBlobBatchStreamServerMockRecorder
+}
+
+// MockSigning_PostSignBlobBatchStreamServerMockRecorder is the mock recorder for MockSigning_PostSignBlobBatchStreamServer
+type MockSigning_PostSignBlobBatchStreamServerMockRecorder struct {
+	mock *MockSigning_PostSignBlobBatchStreamServer
+}
+
+// NewMockSigning_PostSignBlobBatchStreamServer creates a new mock instance
+func NewMockSigning_PostSignBlobBatchStreamServer(ctrl *gomock.Controller) *MockSigning_PostSignBlobBatchStreamServer {
+	mock := &MockSigning_PostSignBlobBatchStreamServer{ctrl: ctrl}
+	mock.recorder = &MockSigning_PostSignBlobBatchStreamServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSigning_PostSignBlobBatchStreamServer) EXPECT() *MockSigning_PostSignBlobBatchStreamServerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamServer) Send(arg0 *proto.BlobBatchEntrySignature) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send
+func (mr *MockSigning_PostSignBlobBatchStreamServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamServer)(nil).Send), arg0)
+}
+
+// SetHeader mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockSigning_PostSignBlobBatchStreamServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamServer)(nil).SetHeader), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockSigning_PostSignBlobBatchStreamServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamServer)(nil).SendHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockSigning_PostSignBlobBatchStreamServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamServer)(nil).SetTrailer), arg0)
+}
+
+// Context mocks base method
+func (m *MockSigning_PostSignBlobBatchStreamServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockSigning_PostSignBlobBatchStreamServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamServer)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobBatchStreamServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockSigning_PostSignBlobBatchStreamServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamServer)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobBatchStreamServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockSigning_PostSignBlobBatchStreamServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockSigning_PostSignBlobBatchStreamServer)(nil).RecvMsg), m)
+}
+
+// MockSigning_PostSignBlobStreamServer is a mock of Signing_PostSignBlobStreamServer interface
+type MockSigning_PostSignBlobStreamServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSigning_PostSignBlobStreamServerMockRecorder
+}
+
+// MockSigning_PostSignBlobStreamServerMockRecorder is the mock recorder for MockSigning_PostSignBlobStreamServer
+type MockSigning_PostSignBlobStreamServerMockRecorder struct {
+	mock *MockSigning_PostSignBlobStreamServer
+}
+
+// NewMockSigning_PostSignBlobStreamServer creates a new mock instance
+func NewMockSigning_PostSignBlobStreamServer(ctrl *gomock.Controller) *MockSigning_PostSignBlobStreamServer {
+	mock := &MockSigning_PostSignBlobStreamServer{ctrl: ctrl}
+	mock.recorder = &MockSigning_PostSignBlobStreamServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSigning_PostSignBlobStreamServer) EXPECT() *MockSigning_PostSignBlobStreamServerMockRecorder {
+	return m.recorder
+}
+
+// SendAndClose mocks base method
+func (m *MockSigning_PostSignBlobStreamServer) SendAndClose(arg0 *proto.BlobStreamSignature) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAndClose indicates an expected call of SendAndClose
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) SendAndClose(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).SendAndClose), arg0)
+}
+
+// Recv mocks base method
+func (m *MockSigning_PostSignBlobStreamServer) Recv() (*proto.BlobStreamRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*proto.BlobStreamRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).Recv))
+}
+
+// SetHeader mocks base method
+func (m *MockSigning_PostSignBlobStreamServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).SetHeader), arg0)
+}
+
+// SendHeader mocks base method
+func (m *MockSigning_PostSignBlobStreamServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).SendHeader), arg0)
+}
+
+// SetTrailer mocks base method
+func (m *MockSigning_PostSignBlobStreamServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).SetTrailer), arg0)
+}
+
+// Context mocks base method
+func (m *MockSigning_PostSignBlobStreamServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).Context))
+}
+
+// SendMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobStreamServer) SendMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).SendMsg), m)
+}
+
+// RecvMsg mocks base method
+func (m_2 *MockSigning_PostSignBlobStreamServer) RecvMsg(m interface{}) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg
+func (mr *MockSigning_PostSignBlobStreamServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockSigning_PostSignBlobStreamServer)(nil).RecvMsg), m)
+}
+
+// MockAdminClient is a mock of AdminClient interface
+type MockAdminClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminClientMockRecorder
+}
+
+// MockAdminClientMockRecorder is the mock recorder for MockAdminClient
+type MockAdminClientMockRecorder struct {
+	mock *MockAdminClient
+}
+
+// NewMockAdminClient creates a new mock instance
+func NewMockAdminClient(ctrl *gomock.Controller) *MockAdminClient {
+	mock := &MockAdminClient{ctrl: ctrl}
+	mock.recorder = &MockAdminClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAdminClient) EXPECT() *MockAdminClientMockRecorder {
+	return m.recorder
+}
+
+// GetServerInfo mocks base method
+func (m *MockAdminClient) GetServerInfo(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.ServerInfo, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetServerInfo", varargs...)
+	ret0, _ := ret[0].(*proto.ServerInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerInfo indicates an expected call of GetServerInfo
+func (mr *MockAdminClientMockRecorder) GetServerInfo(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerInfo", reflect.TypeOf((*MockAdminClient)(nil).GetServerInfo), varargs...)
+}
+
+// SetEndpointStatus mocks base method
+func (m *MockAdminClient) SetEndpointStatus(ctx context.Context, in *proto.EndpointStatus, opts ...grpc.CallOption) (*proto.EndpointStatus, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetEndpointStatus", varargs...)
+	ret0, _ := ret[0].(*proto.EndpointStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetEndpointStatus indicates an expected call of SetEndpointStatus
+func (mr *MockAdminClientMockRecorder) SetEndpointStatus(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEndpointStatus", reflect.TypeOf((*MockAdminClient)(nil).SetEndpointStatus), varargs...)
+}
+
+// GenerateKey mocks base method
+func (m *MockAdminClient) GenerateKey(ctx context.Context, in *proto.KeyGenerationRequest, opts ...grpc.CallOption) (*proto.GeneratedKey, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GenerateKey", varargs...)
+	ret0, _ := ret[0].(*proto.GeneratedKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateKey indicates an expected call of GenerateKey
+func (mr *MockAdminClientMockRecorder) GenerateKey(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateKey", reflect.TypeOf((*MockAdminClient)(nil).GenerateKey), varargs...)
+}
+
+// ReloadKeySessions mocks base method
+func (m *MockAdminClient) ReloadKeySessions(ctx context.Context, in *proto.KeyMeta, opts ...grpc.CallOption) (*proto.ReloadedSessions, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReloadKeySessions", varargs...)
+	ret0, _ := ret[0].(*proto.ReloadedSessions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadKeySessions indicates an expected call of ReloadKeySessions
+func (mr *MockAdminClientMockRecorder) ReloadKeySessions(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadKeySessions", reflect.TypeOf((*MockAdminClient)(nil).ReloadKeySessions), varargs...)
+}
+
+// ListRecentIssuance mocks base method
+func (m *MockAdminClient) ListRecentIssuance(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.IssuanceRecords, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListRecentIssuance", varargs...)
+	ret0, _ := ret[0].(*proto.IssuanceRecords)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentIssuance indicates an expected call of ListRecentIssuance
+func (mr *MockAdminClientMockRecorder) ListRecentIssuance(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentIssuance", reflect.TypeOf((*MockAdminClient)(nil).ListRecentIssuance), varargs...)
+}
+
+// GetKeyUsageStats mocks base method
+func (m *MockAdminClient) GetKeyUsageStats(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.KeyUsageStatsList, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetKeyUsageStats", varargs...)
+	ret0, _ := ret[0].(*proto.KeyUsageStatsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyUsageStats indicates an expected call of GetKeyUsageStats
+func (mr *MockAdminClientMockRecorder) GetKeyUsageStats(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyUsageStats", reflect.TypeOf((*MockAdminClient)(nil).GetKeyUsageStats), varargs...)
+}
+
+// ListHSMMechanisms mocks base method
+func (m *MockAdminClient) ListHSMMechanisms(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.SlotMechanismsList, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListHSMMechanisms", varargs...)
+	ret0, _ := ret[0].(*proto.SlotMechanismsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHSMMechanisms indicates an expected call of ListHSMMechanisms
+func (mr *MockAdminClientMockRecorder) ListHSMMechanisms(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHSMMechanisms", reflect.TypeOf((*MockAdminClient)(nil).ListHSMMechanisms), varargs...)
+}
+
+// GetEffectiveConfig mocks base method
+func (m *MockAdminClient) GetEffectiveConfig(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*proto.EffectiveConfig, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetEffectiveConfig", varargs...)
+	ret0, _ := ret[0].(*proto.EffectiveConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEffectiveConfig indicates an expected call of GetEffectiveConfig
+func (mr *MockAdminClientMockRecorder) GetEffectiveConfig(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEffectiveConfig", reflect.TypeOf((*MockAdminClient)(nil).GetEffectiveConfig), varargs...)
+}
+
+// MockAdminServer is a mock of AdminServer interface
+type MockAdminServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServerMockRecorder
+}
+
+// MockAdminServerMockRecorder is the mock recorder for MockAdminServer
+type MockAdminServerMockRecorder struct {
+	mock *MockAdminServer
+}
+
+// NewMockAdminServer creates a new mock instance
+func NewMockAdminServer(ctrl *gomock.Controller) *MockAdminServer {
+	mock := &MockAdminServer{ctrl: ctrl}
+	mock.recorder = &MockAdminServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAdminServer) EXPECT() *MockAdminServerMockRecorder {
+	return m.recorder
+}
+
+// GetServerInfo mocks base method
+func (m *MockAdminServer) GetServerInfo(arg0 context.Context, arg1 *empty.Empty) (*proto.ServerInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerInfo", arg0, arg1)
+	ret0, _ := ret[0].(*proto.ServerInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServerInfo indicates an expected call of GetServerInfo
+func (mr *MockAdminServerMockRecorder) GetServerInfo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerInfo", reflect.TypeOf((*MockAdminServer)(nil).GetServerInfo), arg0, arg1)
+}
+
+// SetEndpointStatus mocks base method
+func (m *MockAdminServer) SetEndpointStatus(arg0 context.Context, arg1 *proto.EndpointStatus) (*proto.EndpointStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEndpointStatus", arg0, arg1)
+	ret0, _ := ret[0].(*proto.EndpointStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetEndpointStatus indicates an expected call of SetEndpointStatus
+func (mr *MockAdminServerMockRecorder) SetEndpointStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEndpointStatus", reflect.TypeOf((*MockAdminServer)(nil).SetEndpointStatus), arg0, arg1)
+}
+
+// GenerateKey mocks base method
+func (m *MockAdminServer) GenerateKey(arg0 context.Context, arg1 *proto.KeyGenerationRequest) (*proto.GeneratedKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateKey", arg0, arg1)
+	ret0, _ := ret[0].(*proto.GeneratedKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateKey indicates an expected call of GenerateKey
+func (mr *MockAdminServerMockRecorder) GenerateKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateKey", reflect.TypeOf((*MockAdminServer)(nil).GenerateKey), arg0, arg1)
+}
+
+// ReloadKeySessions mocks base method
+func (m *MockAdminServer) ReloadKeySessions(arg0 context.Context, arg1 *proto.KeyMeta) (*proto.ReloadedSessions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadKeySessions", arg0, arg1)
+	ret0, _ := ret[0].(*proto.ReloadedSessions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReloadKeySessions indicates an expected call of ReloadKeySessions
+func (mr *MockAdminServerMockRecorder) ReloadKeySessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadKeySessions", reflect.TypeOf((*MockAdminServer)(nil).ReloadKeySessions), arg0, arg1)
+}
+
+// ListRecentIssuance mocks base method
+func (m *MockAdminServer) ListRecentIssuance(arg0 context.Context, arg1 *empty.Empty) (*proto.IssuanceRecords, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecentIssuance", arg0, arg1)
+	ret0, _ := ret[0].(*proto.IssuanceRecords)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentIssuance indicates an expected call of ListRecentIssuance
+func (mr *MockAdminServerMockRecorder) ListRecentIssuance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentIssuance", reflect.TypeOf((*MockAdminServer)(nil).ListRecentIssuance), arg0, arg1)
+}
+
+// GetKeyUsageStats mocks base method
+func (m *MockAdminServer) GetKeyUsageStats(arg0 context.Context, arg1 *empty.Empty) (*proto.KeyUsageStatsList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyUsageStats", arg0, arg1)
+	ret0, _ := ret[0].(*proto.KeyUsageStatsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyUsageStats indicates an expected call of GetKeyUsageStats
+func (mr *MockAdminServerMockRecorder) GetKeyUsageStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyUsageStats", reflect.TypeOf((*MockAdminServer)(nil).GetKeyUsageStats), arg0, arg1)
+}
+
+// ListHSMMechanisms mocks base method
+func (m *MockAdminServer) ListHSMMechanisms(arg0 context.Context, arg1 *empty.Empty) (*proto.SlotMechanismsList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHSMMechanisms", arg0, arg1)
+	ret0, _ := ret[0].(*proto.SlotMechanismsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHSMMechanisms indicates an expected call of ListHSMMechanisms
+func (mr *MockAdminServerMockRecorder) ListHSMMechanisms(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHSMMechanisms", reflect.TypeOf((*MockAdminServer)(nil).ListHSMMechanisms), arg0, arg1)
+}
+
+// GetEffectiveConfig mocks base method
+func (m *MockAdminServer) GetEffectiveConfig(arg0 context.Context, arg1 *empty.Empty) (*proto.EffectiveConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEffectiveConfig", arg0, arg1)
+	ret0, _ := ret[0].(*proto.EffectiveConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEffectiveConfig indicates an expected call of GetEffectiveConfig
+func (mr *MockAdminServerMockRecorder) GetEffectiveConfig(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEffectiveConfig", reflect.TypeOf((*MockAdminServer)(nil).GetEffectiveConfig), arg0, arg1)
 }
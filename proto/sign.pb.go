@@ -7,6 +7,8 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import empty "github.com/golang/protobuf/ptypes/empty"
+import _struct "github.com/golang/protobuf/ptypes/struct"
+import wrappers "github.com/golang/protobuf/ptypes/wrappers"
 import _ "google.golang.org/genproto/googleapis/api/annotations"
 
 import (
@@ -25,6 +27,100 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// KeyHealth is the health of a key, as observed from the results of its recent signing requests.
+type KeyHealth int32
+
+const (
+	KeyHealth_Unspecified_KeyHealth KeyHealth = 0
+	// The last signing request of the key, if any, succeeded.
+	KeyHealth_HEALTHY KeyHealth = 1
+	// Recent signing requests of the key failed, or its circuit breaker is testing its recovery.
+	KeyHealth_DEGRADED KeyHealth = 2
+	// The signing requests of the key are rejected, as its circuit breaker is open or the key is being (re)loaded.
+	KeyHealth_UNAVAILABLE KeyHealth = 3
+)
+
+var KeyHealth_name = map[int32]string{
+	0: "Unspecified_KeyHealth",
+	1: "HEALTHY",
+	2: "DEGRADED",
+	3: "UNAVAILABLE",
+}
+var KeyHealth_value = map[string]int32{
+	"Unspecified_KeyHealth": 0,
+	"HEALTHY":               1,
+	"DEGRADED":              2,
+	"UNAVAILABLE":           3,
+}
+
+func (x KeyHealth) String() string {
+	return proto.EnumName(KeyHealth_name, int32(x))
+}
+func (KeyHealth) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{0}
+}
+
+// PublicKeyFormat specifies the encoding of a public key.
+type PublicKeyFormat int32
+
+const (
+	// PEM encoded SubjectPublicKeyInfo.
+	PublicKeyFormat_PEM PublicKeyFormat = 0
+	// JSON Web Key (RFC 7517) whose kid is the key identifier.
+	PublicKeyFormat_JWK PublicKeyFormat = 1
+	// Base64 encoded DER SubjectPublicKeyInfo.
+	PublicKeyFormat_DER PublicKeyFormat = 2
+)
+
+var PublicKeyFormat_name = map[int32]string{
+	0: "PEM",
+	1: "JWK",
+	2: "DER",
+}
+var PublicKeyFormat_value = map[string]int32{
+	"PEM": 0,
+	"JWK": 1,
+	"DER": 2,
+}
+
+func (x PublicKeyFormat) String() string {
+	return proto.EnumName(PublicKeyFormat_name, int32(x))
+}
+func (PublicKeyFormat) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{1}
+}
+
+// Priority specifies the order in which signing requests waiting for a session of the signing key are served.
+// Requests of the same priority are served in the order they arrived.
+type Priority int32
+
+const (
+	// Served after HIGH requests and before LOW requests.
+	Priority_NORMAL Priority = 0
+	// Latency-critical requests, e.g. interactive SSH logins.
+	Priority_HIGH Priority = 1
+	// Batch requests, e.g. artifact signing, which yield to the others.
+	Priority_LOW Priority = 2
+)
+
+var Priority_name = map[int32]string{
+	0: "NORMAL",
+	1: "HIGH",
+	2: "LOW",
+}
+var Priority_value = map[string]int32{
+	"NORMAL": 0,
+	"HIGH":   1,
+	"LOW":    2,
+}
+
+func (x Priority) String() string {
+	return proto.EnumName(Priority_name, int32(x))
+}
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{2}
+}
+
 type HashAlgo int32
 
 const (
@@ -33,6 +129,10 @@ const (
 	HashAlgo_SHA256           HashAlgo = 2
 	HashAlgo_SHA384           HashAlgo = 3
 	HashAlgo_SHA512           HashAlgo = 4
+	HashAlgo_SHA3_224         HashAlgo = 5
+	HashAlgo_SHA3_256         HashAlgo = 6
+	HashAlgo_SHA3_384         HashAlgo = 7
+	HashAlgo_SHA3_512         HashAlgo = 8
 )
 
 var HashAlgo_name = map[int32]string{
@@ -41,6 +141,10 @@ var HashAlgo_name = map[int32]string{
 	2: "SHA256",
 	3: "SHA384",
 	4: "SHA512",
+	5: "SHA3_224",
+	6: "SHA3_256",
+	7: "SHA3_384",
+	8: "SHA3_512",
 }
 var HashAlgo_value = map[string]int32{
 	"Unspecified_Hash": 0,
@@ -48,19 +152,182 @@ var HashAlgo_value = map[string]int32{
 	"SHA256":           2,
 	"SHA384":           3,
 	"SHA512":           4,
+	"SHA3_224":         5,
+	"SHA3_256":         6,
+	"SHA3_384":         7,
+	"SHA3_512":         8,
 }
 
 func (x HashAlgo) String() string {
 	return proto.EnumName(HashAlgo_name, int32(x))
 }
 func (HashAlgo) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{0}
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{3}
+}
+
+// SignatureScheme specifies the scheme of a blob signature.
+type SignatureScheme int32
+
+const (
+	// The default scheme of the key type, i.e. PKCS1v15 for RSA keys, ECDSA_ASN1 for ECDSA keys,
+	// Ed25519ph for Ed25519 keys, HashMLDSA for ML-DSA keys and HashSLHDSA for SLH-DSA keys.
+	SignatureScheme_Unspecified_SignatureScheme SignatureScheme = 0
+	// RSASSA-PKCS1-v1_5.
+	SignatureScheme_PKCS1v15 SignatureScheme = 1
+	// RSASSA-PSS with the salt length configured for the key, by default equal to the hash length.
+	SignatureScheme_PSS SignatureScheme = 2
+	// ASN.1 DER encoded ECDSA signature.
+	SignatureScheme_ECDSA_ASN1 SignatureScheme = 3
+	// Ed25519ph (RFC 8032) of a SHA512 digest.
+	SignatureScheme_Ed25519ph SignatureScheme = 4
+	// IEEE P1363 encoded ECDSA signature, i.e. the fixed size concatenation of r and s used by JWS.
+	SignatureScheme_ECDSA_P1363 SignatureScheme = 5
+	// HashML-DSA (FIPS 204) of a SHA256, SHA384, SHA512 or SHA3 digest of 256 bits or more.
+	SignatureScheme_HashMLDSA SignatureScheme = 6
+	// HashSLH-DSA (FIPS 205) of a SHA256, SHA384, SHA512 or SHA3 digest of 256 bits or more.
+	SignatureScheme_HashSLHDSA SignatureScheme = 7
+)
+
+var SignatureScheme_name = map[int32]string{
+	0: "Unspecified_SignatureScheme",
+	1: "PKCS1v15",
+	2: "PSS",
+	3: "ECDSA_ASN1",
+	4: "Ed25519ph",
+	5: "ECDSA_P1363",
+	6: "HashMLDSA",
+	7: "HashSLHDSA",
+}
+var SignatureScheme_value = map[string]int32{
+	"Unspecified_SignatureScheme": 0,
+	"PKCS1v15":                    1,
+	"PSS":                         2,
+	"ECDSA_ASN1":                  3,
+	"Ed25519ph":                   4,
+	"ECDSA_P1363":                 5,
+	"HashMLDSA":                   6,
+	"HashSLHDSA":                  7,
+}
+
+func (x SignatureScheme) String() string {
+	return proto.EnumName(SignatureScheme_name, int32(x))
+}
+func (SignatureScheme) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{4}
+}
+
+// DigestEncoding specifies the encoding of the digest of a blob signing request.
+type DigestEncoding int32
+
+const (
+	DigestEncoding_Unspecified_DigestEncoding DigestEncoding = 0
+	// Standard base64 encoding with padding.
+	DigestEncoding_BASE64 DigestEncoding = 1
+	// Hexadecimal encoding, in lower or upper case.
+	DigestEncoding_HEX DigestEncoding = 2
+)
+
+var DigestEncoding_name = map[int32]string{
+	0: "Unspecified_DigestEncoding",
+	1: "BASE64",
+	2: "HEX",
+}
+var DigestEncoding_value = map[string]int32{
+	"Unspecified_DigestEncoding": 0,
+	"BASE64":                     1,
+	"HEX":                        2,
+}
+
+func (x DigestEncoding) String() string {
+	return proto.EnumName(DigestEncoding_name, int32(x))
+}
+func (DigestEncoding) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{5}
+}
+
+// PartialAvailability specifies the behavior of a signing request when some of the keys it requires are unavailable.
+type PartialAvailability int32
+
+const (
+	PartialAvailability_Unspecified_PartialAvailability PartialAvailability = 0
+	// The request fails if any of the keys fails to sign.
+	PartialAvailability_STRICT PartialAvailability = 1
+	// The request returns the signatures of the keys that signed, and fails only if none did.
+	PartialAvailability_BEST_EFFORT PartialAvailability = 2
+)
+
+var PartialAvailability_name = map[int32]string{
+	0: "Unspecified_PartialAvailability",
+	1: "STRICT",
+	2: "BEST_EFFORT",
+}
+var PartialAvailability_value = map[string]int32{
+	"Unspecified_PartialAvailability": 0,
+	"STRICT":                          1,
+	"BEST_EFFORT":                     2,
+}
+
+func (x PartialAvailability) String() string {
+	return proto.EnumName(PartialAvailability_name, int32(x))
+}
+func (PartialAvailability) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{6}
+}
+
+// KeyType specifies the type of a key pair.
+type KeyType int32
+
+const (
+	KeyType_Unspecified_KeyType KeyType = 0
+	KeyType_RSA                 KeyType = 1
+	KeyType_ECDSA               KeyType = 2
+	KeyType_Ed25519             KeyType = 3
+	// Post-quantum ML-DSA (FIPS 204).
+	KeyType_MLDSA KeyType = 4
+	// Post-quantum SLH-DSA (FIPS 205).
+	KeyType_SLHDSA KeyType = 5
+)
+
+var KeyType_name = map[int32]string{
+	0: "Unspecified_KeyType",
+	1: "RSA",
+	2: "ECDSA",
+	3: "Ed25519",
+	4: "MLDSA",
+	5: "SLHDSA",
+}
+var KeyType_value = map[string]int32{
+	"Unspecified_KeyType": 0,
+	"RSA":                 1,
+	"ECDSA":               2,
+	"Ed25519":             3,
+	"MLDSA":               4,
+	"SLHDSA":              5,
+}
+
+func (x KeyType) String() string {
+	return proto.EnumName(KeyType_name, int32(x))
+}
+func (KeyType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{7}
 }
 
 // KeyMeta identifies the private key used in crypto operations.
 type KeyMeta struct {
 	// The id of the key that will be used in crypto operations.
-	Identifier           string   `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Identifier string `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	// Hex encoded SHA256 fingerprint of the DER encoded public key of the key, which can be
+	// used instead of the identifier to refer to the key.
+	Fingerprint string `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	// Encoding of the public key returned by GetBlobSigningKey. If PEM, the encoding preferred in
+	// the x-crypki-public-key-format request metadata, if any, is used instead.
+	Format PublicKeyFormat `protobuf:"varint,3,opt,name=format,proto3,enum=v3.PublicKeyFormat" json:"format,omitempty"`
+	// The health of the key, only set in the listings of the Get*AvailableSigningKeys requests with
+	// include_health.
+	Health KeyHealth `protobuf:"varint,4,opt,name=health,proto3,enum=v3.KeyHealth" json:"health,omitempty"`
+	// Whether GetBlobSigningKey also returns the signing certificate of the key. The request fails
+	// with NotFound if the key has no signing certificate.
+	IncludeCertificate   bool     `protobuf:"varint,5,opt,name=include_certificate,json=includeCertificate,proto3" json:"include_certificate,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -70,7 +337,7 @@ func (m *KeyMeta) Reset()         { *m = KeyMeta{} }
 func (m *KeyMeta) String() string { return proto.CompactTextString(m) }
 func (*KeyMeta) ProtoMessage()    {}
 func (*KeyMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{0}
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{0}
 }
 func (m *KeyMeta) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_KeyMeta.Unmarshal(m, b)
@@ -97,6 +364,34 @@ func (m *KeyMeta) GetIdentifier() string {
 	return ""
 }
 
+func (m *KeyMeta) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+func (m *KeyMeta) GetFormat() PublicKeyFormat {
+	if m != nil {
+		return m.Format
+	}
+	return PublicKeyFormat_PEM
+}
+
+func (m *KeyMeta) GetHealth() KeyHealth {
+	if m != nil {
+		return m.Health
+	}
+	return KeyHealth_Unspecified_KeyHealth
+}
+
+func (m *KeyMeta) GetIncludeCertificate() bool {
+	if m != nil {
+		return m.IncludeCertificate
+	}
+	return false
+}
+
 // KeyMetas contains a list of KeyMetas.
 type KeyMetas struct {
 	Keys                 []*KeyMeta `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
@@ -109,7 +404,7 @@ func (m *KeyMetas) Reset()         { *m = KeyMetas{} }
 func (m *KeyMetas) String() string { return proto.CompactTextString(m) }
 func (*KeyMetas) ProtoMessage()    {}
 func (*KeyMetas) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{1}
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{1}
 }
 func (m *KeyMetas) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_KeyMetas.Unmarshal(m, b)
@@ -136,6 +431,65 @@ func (m *KeyMetas) GetKeys() []*KeyMeta {
 	return nil
 }
 
+// KeyFilter selects the keys listed by the Get*AvailableSigningKeys requests. Keys match if they match
+// all the fields set; an empty filter lists all the keys.
+type KeyFilter struct {
+	// Type of the keys, any type if unspecified.
+	KeyType KeyType `protobuf:"varint,1,opt,name=key_type,json=keyType,proto3,enum=v3.KeyType" json:"key_type,omitempty"`
+	// Tenant the keys are configured for, any tenant if empty.
+	Tenant string `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	// Whether the health of each listed key is included in its KeyMeta.
+	IncludeHealth        bool     `protobuf:"varint,3,opt,name=include_health,json=includeHealth,proto3" json:"include_health,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyFilter) Reset()         { *m = KeyFilter{} }
+func (m *KeyFilter) String() string { return proto.CompactTextString(m) }
+func (*KeyFilter) ProtoMessage()    {}
+func (*KeyFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{2}
+}
+func (m *KeyFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyFilter.Unmarshal(m, b)
+}
+func (m *KeyFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyFilter.Marshal(b, m, deterministic)
+}
+func (dst *KeyFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyFilter.Merge(dst, src)
+}
+func (m *KeyFilter) XXX_Size() int {
+	return xxx_messageInfo_KeyFilter.Size(m)
+}
+func (m *KeyFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyFilter proto.InternalMessageInfo
+
+func (m *KeyFilter) GetKeyType() KeyType {
+	if m != nil {
+		return m.KeyType
+	}
+	return KeyType_Unspecified_KeyType
+}
+
+func (m *KeyFilter) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *KeyFilter) GetIncludeHealth() bool {
+	if m != nil {
+		return m.IncludeHealth
+	}
+	return false
+}
+
 // SSHCertificateSigningRequest specifies the info used for signing an SSH certificate.
 type SSHCertificateSigningRequest struct {
 	// Identifies the signing key in the HSM used for signing the certificate.
@@ -144,24 +498,29 @@ type SSHCertificateSigningRequest struct {
 	Principals []string `protobuf:"bytes,2,rep,name=principals,proto3" json:"principals,omitempty"`
 	// SSH public key.
 	PublicKey string `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
-	// Validity period of the certificate in seconds.
+	// Validity period of the certificate in seconds. The maximum value, 18446744073709551615, requests a
+	// certificate that never expires, which most keys do not sign.
 	Validity uint64 `protobuf:"varint,4,opt,name=validity,proto3" json:"validity,omitempty"`
 	// Key ID in the certificate.
 	KeyId string `protobuf:"bytes,5,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
 	// Critical Options field in the certificate.
 	CriticalOptions map[string]string `protobuf:"bytes,6,rep,name=critical_options,json=criticalOptions,proto3" json:"critical_options,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// Extensions field in the certificate.
-	Extensions           map[string]string `protobuf:"bytes,7,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Extensions map[string]string `protobuf:"bytes,7,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Whether the response includes the to-be-signed bytes of the certificate.
+	ReturnTbs bool `protobuf:"varint,8,opt,name=return_tbs,json=returnTbs,proto3" json:"return_tbs,omitempty"`
+	// The priority of the request for a session of the signing key.
+	Priority             Priority `protobuf:"varint,9,opt,name=priority,proto3,enum=v3.Priority" json:"priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SSHCertificateSigningRequest) Reset()         { *m = SSHCertificateSigningRequest{} }
 func (m *SSHCertificateSigningRequest) String() string { return proto.CompactTextString(m) }
 func (*SSHCertificateSigningRequest) ProtoMessage()    {}
 func (*SSHCertificateSigningRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{2}
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{3}
 }
 func (m *SSHCertificateSigningRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SSHCertificateSigningRequest.Unmarshal(m, b)
@@ -230,22 +589,45 @@ func (m *SSHCertificateSigningRequest) GetExtensions() map[string]string {
 	return nil
 }
 
+func (m *SSHCertificateSigningRequest) GetReturnTbs() bool {
+	if m != nil {
+		return m.ReturnTbs
+	}
+	return false
+}
+
+func (m *SSHCertificateSigningRequest) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_NORMAL
+}
+
 // SSHKey specifies an SSH key that can either be an:
 // 1. SSH public key, or
 // 2. SSH user/host certificate
 type SSHKey struct {
 	// The encoded string of the SSH key.
-	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// SHA256 fingerprint of the certified public key, in the format of ssh-keygen -l.
+	// Only set in the responses of certificate signing requests.
+	Fingerprint string `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	// The bytes of the certificate covered by its signature, i.e. the certificate without the signature field.
+	// Only set in the responses of certificate signing requests with return_tbs.
+	Tbs []byte `protobuf:"bytes,3,opt,name=tbs,proto3" json:"tbs,omitempty"`
+	// The receipt of the issuance of the certificate. Only set in the responses of certificate signing
+	// requests if the server is configured with a receipt key.
+	Receipt              *IssuanceReceipt `protobuf:"bytes,4,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *SSHKey) Reset()         { *m = SSHKey{} }
 func (m *SSHKey) String() string { return proto.CompactTextString(m) }
 func (*SSHKey) ProtoMessage()    {}
 func (*SSHKey) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{3}
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{4}
 }
 func (m *SSHKey) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SSHKey.Unmarshal(m, b)
@@ -272,6 +654,88 @@ func (m *SSHKey) GetKey() string {
 	return ""
 }
 
+func (m *SSHKey) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+func (m *SSHKey) GetTbs() []byte {
+	if m != nil {
+		return m.Tbs
+	}
+	return nil
+}
+
+func (m *SSHKey) GetReceipt() *IssuanceReceipt {
+	if m != nil {
+		return m.Receipt
+	}
+	return nil
+}
+
+// IssuanceReceipt attests the issuance of a certificate by crypki, or of the certificates of a manifest,
+// for the non-repudiation of the issuance.
+type IssuanceReceipt struct {
+	// The JSON document attesting the issuance, with the identifier of the issuing key, the type, serial
+	// number, subject and fingerprint of the certificate, and the Unix time of the issuance.
+	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The base64 encoded signature of the payload by the receipt key: a PKCS#1 v1.5 or ASN.1 DER ECDSA
+	// signature of its SHA256 digest, or an Ed25519 signature of the payload.
+	Signature string `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Identifies the receipt key, whose public key is distributed to the verifiers of the receipts.
+	KeyMeta              *KeyMeta `protobuf:"bytes,3,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IssuanceReceipt) Reset()         { *m = IssuanceReceipt{} }
+func (m *IssuanceReceipt) String() string { return proto.CompactTextString(m) }
+func (*IssuanceReceipt) ProtoMessage()    {}
+func (*IssuanceReceipt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{5}
+}
+func (m *IssuanceReceipt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IssuanceReceipt.Unmarshal(m, b)
+}
+func (m *IssuanceReceipt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IssuanceReceipt.Marshal(b, m, deterministic)
+}
+func (dst *IssuanceReceipt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IssuanceReceipt.Merge(dst, src)
+}
+func (m *IssuanceReceipt) XXX_Size() int {
+	return xxx_messageInfo_IssuanceReceipt.Size(m)
+}
+func (m *IssuanceReceipt) XXX_DiscardUnknown() {
+	xxx_messageInfo_IssuanceReceipt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IssuanceReceipt proto.InternalMessageInfo
+
+func (m *IssuanceReceipt) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *IssuanceReceipt) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *IssuanceReceipt) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
 // X509CertificateSigningRequest specifies the info used for signing an X509 certificate.
 type X509CertificateSigningRequest struct {
 	// Identifies the signing key in the HSM used for signing the certificate.
@@ -282,17 +746,33 @@ type X509CertificateSigningRequest struct {
 	Validity uint64 `protobuf:"varint,3,opt,name=validity,proto3" json:"validity,omitempty"`
 	// X509 certificate ExtKeyUsage.
 	// https://godoc.org/crypto/x509#ExtKeyUsage
-	ExtKeyUsage          []int32  `protobuf:"varint,4,rep,packed,name=ext_key_usage,json=extKeyUsage,proto3" json:"ext_key_usage,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	ExtKeyUsage []int32 `protobuf:"varint,4,rep,packed,name=ext_key_usage,json=extKeyUsage,proto3" json:"ext_key_usage,omitempty"`
+	// Whether the response includes the DER encoded TBSCertificate of the certificate.
+	ReturnTbs bool `protobuf:"varint,5,opt,name=return_tbs,json=returnTbs,proto3" json:"return_tbs,omitempty"`
+	// The priority of the request for a session of the signing key.
+	Priority Priority `protobuf:"varint,6,opt,name=priority,proto3,enum=v3.Priority" json:"priority,omitempty"`
+	// The issuer of the CA certificate of the key whose chain is returned, for keys with cross-signed CA
+	// certificates: the subject of the issuer in the RFC 2253 string form, or the index of the CA certificate,
+	// where 0 is the X509 CA certificate of the key and the cross-signed ones follow in their configured order.
+	// If empty, no chain is returned.
+	Issuer string `protobuf:"bytes,7,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	// The x509 profile of the key, such as short-lived client certificates, whose maximum validity applies to
+	// the certificate. If empty, the maximum validity of the endpoint applies.
+	Profile string `protobuf:"bytes,8,opt,name=profile,proto3" json:"profile,omitempty"`
+	// The path length constraint of the intermediate CA certificate, for the keys issuing CA certificates: the
+	// number of levels of intermediate CAs it may issue below it. It may not exceed the maximum path length of
+	// the key. If not specified, the maximum path length of the key is used.
+	MaxPathLen           *wrappers.UInt32Value `protobuf:"bytes,9,opt,name=max_path_len,json=maxPathLen,proto3" json:"max_path_len,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
 func (m *X509CertificateSigningRequest) Reset()         { *m = X509CertificateSigningRequest{} }
 func (m *X509CertificateSigningRequest) String() string { return proto.CompactTextString(m) }
 func (*X509CertificateSigningRequest) ProtoMessage()    {}
 func (*X509CertificateSigningRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{4}
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{6}
 }
 func (m *X509CertificateSigningRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_X509CertificateSigningRequest.Unmarshal(m, b)
@@ -340,10 +820,57 @@ func (m *X509CertificateSigningRequest) GetExtKeyUsage() []int32 {
 	return nil
 }
 
+func (m *X509CertificateSigningRequest) GetReturnTbs() bool {
+	if m != nil {
+		return m.ReturnTbs
+	}
+	return false
+}
+
+func (m *X509CertificateSigningRequest) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_NORMAL
+}
+
+func (m *X509CertificateSigningRequest) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *X509CertificateSigningRequest) GetProfile() string {
+	if m != nil {
+		return m.Profile
+	}
+	return ""
+}
+
+func (m *X509CertificateSigningRequest) GetMaxPathLen() *wrappers.UInt32Value {
+	if m != nil {
+		return m.MaxPathLen
+	}
+	return nil
+}
+
 // X509Certificate specifies an X509 certificate.
 type X509Certificate struct {
 	// The X509 certificate encoded in PEM format.
-	Cert                 string   `protobuf:"bytes,1,opt,name=cert,proto3" json:"cert,omitempty"`
+	Cert string `protobuf:"bytes,1,opt,name=cert,proto3" json:"cert,omitempty"`
+	// Hex encoded SHA256 fingerprint of the DER encoded certificate.
+	// Only set in the responses of certificate signing requests.
+	Fingerprint string `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	// The DER encoded TBSCertificate covered by the signature of the certificate.
+	// Only set in the responses of certificate signing requests with return_tbs.
+	TbsCertificate []byte `protobuf:"bytes,3,opt,name=tbs_certificate,json=tbsCertificate,proto3" json:"tbs_certificate,omitempty"`
+	// The receipt of the issuance of the certificate. Only set in the responses of certificate signing
+	// requests if the server is configured with a receipt key.
+	Receipt *IssuanceReceipt `protobuf:"bytes,4,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	// The PEM encoded chain of the issuer selected by the request, starting with the CA certificate of the key.
+	// Only set in the responses of certificate signing requests with an issuer.
+	IssuerChain          []string `protobuf:"bytes,5,rep,name=issuer_chain,json=issuerChain,proto3" json:"issuer_chain,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -353,7 +880,7 @@ func (m *X509Certificate) Reset()         { *m = X509Certificate{} }
 func (m *X509Certificate) String() string { return proto.CompactTextString(m) }
 func (*X509Certificate) ProtoMessage()    {}
 func (*X509Certificate) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{5}
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{7}
 }
 func (m *X509Certificate) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_X509Certificate.Unmarshal(m, b)
@@ -380,683 +907,4763 @@ func (m *X509Certificate) GetCert() string {
 	return ""
 }
 
-// PublicKey is a encoded string of the public key specified by users.
-type PublicKey struct {
-	// The encoded string of the public key.
-	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+func (m *X509Certificate) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+func (m *X509Certificate) GetTbsCertificate() []byte {
+	if m != nil {
+		return m.TbsCertificate
+	}
+	return nil
+}
+
+func (m *X509Certificate) GetReceipt() *IssuanceReceipt {
+	if m != nil {
+		return m.Receipt
+	}
+	return nil
+}
+
+func (m *X509Certificate) GetIssuerChain() []string {
+	if m != nil {
+		return m.IssuerChain
+	}
+	return nil
+}
+
+// X509CACertificatePKCS12Request specifies the key whose CA X509 certificate is exported as a PKCS#12 file.
+type X509CACertificatePKCS12Request struct {
+	// Identifies the key in the HSM whose CA certificate is exported.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// Password protecting the PKCS#12 file.
+	Password             string   `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *PublicKey) Reset()         { *m = PublicKey{} }
-func (m *PublicKey) String() string { return proto.CompactTextString(m) }
-func (*PublicKey) ProtoMessage()    {}
-func (*PublicKey) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{6}
+func (m *X509CACertificatePKCS12Request) Reset()         { *m = X509CACertificatePKCS12Request{} }
+func (m *X509CACertificatePKCS12Request) String() string { return proto.CompactTextString(m) }
+func (*X509CACertificatePKCS12Request) ProtoMessage()    {}
+func (*X509CACertificatePKCS12Request) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{8}
 }
-func (m *PublicKey) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_PublicKey.Unmarshal(m, b)
+func (m *X509CACertificatePKCS12Request) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_X509CACertificatePKCS12Request.Unmarshal(m, b)
 }
-func (m *PublicKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_PublicKey.Marshal(b, m, deterministic)
+func (m *X509CACertificatePKCS12Request) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_X509CACertificatePKCS12Request.Marshal(b, m, deterministic)
 }
-func (dst *PublicKey) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_PublicKey.Merge(dst, src)
+func (dst *X509CACertificatePKCS12Request) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_X509CACertificatePKCS12Request.Merge(dst, src)
 }
-func (m *PublicKey) XXX_Size() int {
-	return xxx_messageInfo_PublicKey.Size(m)
+func (m *X509CACertificatePKCS12Request) XXX_Size() int {
+	return xxx_messageInfo_X509CACertificatePKCS12Request.Size(m)
 }
-func (m *PublicKey) XXX_DiscardUnknown() {
-	xxx_messageInfo_PublicKey.DiscardUnknown(m)
+func (m *X509CACertificatePKCS12Request) XXX_DiscardUnknown() {
+	xxx_messageInfo_X509CACertificatePKCS12Request.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_PublicKey proto.InternalMessageInfo
+var xxx_messageInfo_X509CACertificatePKCS12Request proto.InternalMessageInfo
 
-func (m *PublicKey) GetKey() string {
+func (m *X509CACertificatePKCS12Request) GetKeyMeta() *KeyMeta {
 	if m != nil {
-		return m.Key
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *X509CACertificatePKCS12Request) GetPassword() string {
+	if m != nil {
+		return m.Password
 	}
 	return ""
 }
 
-type BlobSigningRequest struct {
-	// Identifies the signing key in the PKCS#11 device used for signing the blob.
-	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
-	// the hash digest of blob in base64 which will be signed.
-	Digest string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
-	// the algorithm of hash function used to generate the digest
-	// https://golang.org/pkg/crypto/#Hash.
-	HashAlgorithm        HashAlgo `protobuf:"varint,3,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+// PKCS12 specifies a DER encoded PKCS#12 file.
+type PKCS12 struct {
+	Pkcs12               []byte   `protobuf:"bytes,1,opt,name=pkcs12,proto3" json:"pkcs12,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *BlobSigningRequest) Reset()         { *m = BlobSigningRequest{} }
-func (m *BlobSigningRequest) String() string { return proto.CompactTextString(m) }
-func (*BlobSigningRequest) ProtoMessage()    {}
-func (*BlobSigningRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{7}
+func (m *PKCS12) Reset()         { *m = PKCS12{} }
+func (m *PKCS12) String() string { return proto.CompactTextString(m) }
+func (*PKCS12) ProtoMessage()    {}
+func (*PKCS12) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{9}
 }
-func (m *BlobSigningRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_BlobSigningRequest.Unmarshal(m, b)
+func (m *PKCS12) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PKCS12.Unmarshal(m, b)
 }
-func (m *BlobSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_BlobSigningRequest.Marshal(b, m, deterministic)
+func (m *PKCS12) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PKCS12.Marshal(b, m, deterministic)
 }
-func (dst *BlobSigningRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_BlobSigningRequest.Merge(dst, src)
+func (dst *PKCS12) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PKCS12.Merge(dst, src)
 }
-func (m *BlobSigningRequest) XXX_Size() int {
-	return xxx_messageInfo_BlobSigningRequest.Size(m)
+func (m *PKCS12) XXX_Size() int {
+	return xxx_messageInfo_PKCS12.Size(m)
 }
-func (m *BlobSigningRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_BlobSigningRequest.DiscardUnknown(m)
+func (m *PKCS12) XXX_DiscardUnknown() {
+	xxx_messageInfo_PKCS12.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_BlobSigningRequest proto.InternalMessageInfo
+var xxx_messageInfo_PKCS12 proto.InternalMessageInfo
 
-func (m *BlobSigningRequest) GetKeyMeta() *KeyMeta {
+func (m *PKCS12) GetPkcs12() []byte {
+	if m != nil {
+		return m.Pkcs12
+	}
+	return nil
+}
+
+// X509CertificatePreview describes the certificate that would be issued for an X509 certificate signing request.
+type X509CertificatePreview struct {
+	// Subject distinguished name of the certificate.
+	Subject string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Issuer distinguished name of the certificate.
+	Issuer string `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	// Start of the validity period in seconds since the Unix epoch.
+	NotBefore int64 `protobuf:"varint,3,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	// End of the validity period in seconds since the Unix epoch.
+	NotAfter int64 `protobuf:"varint,4,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	// Algorithm of the subject public key, such as "RSA" or "ECDSA".
+	PublicKeyAlgorithm string `protobuf:"bytes,5,opt,name=public_key_algorithm,json=publicKeyAlgorithm,proto3" json:"public_key_algorithm,omitempty"`
+	// Subject alternative names.
+	DnsNames       []string `protobuf:"bytes,6,rep,name=dns_names,json=dnsNames,proto3" json:"dns_names,omitempty"`
+	IpAddresses    []string `protobuf:"bytes,7,rep,name=ip_addresses,json=ipAddresses,proto3" json:"ip_addresses,omitempty"`
+	EmailAddresses []string `protobuf:"bytes,8,rep,name=email_addresses,json=emailAddresses,proto3" json:"email_addresses,omitempty"`
+	Uris           []string `protobuf:"bytes,9,rep,name=uris,proto3" json:"uris,omitempty"`
+	// Key usages, such as "DigitalSignature".
+	KeyUsage []string `protobuf:"bytes,10,rep,name=key_usage,json=keyUsage,proto3" json:"key_usage,omitempty"`
+	// X509 certificate ExtKeyUsage.
+	// https://godoc.org/crypto/x509#ExtKeyUsage
+	ExtKeyUsage []int32 `protobuf:"varint,11,rep,packed,name=ext_key_usage,json=extKeyUsage,proto3" json:"ext_key_usage,omitempty"`
+	// CRL distribution point URLs.
+	CrlDistributionPoints []string `protobuf:"bytes,12,rep,name=crl_distribution_points,json=crlDistributionPoints,proto3" json:"crl_distribution_points,omitempty"`
+	// OCSP responder URLs of the authority information access extension.
+	OcspServers []string `protobuf:"bytes,13,rep,name=ocsp_servers,json=ocspServers,proto3" json:"ocsp_servers,omitempty"`
+	// CA issuers URLs of the authority information access extension.
+	IssuingCertificateUrls []string `protobuf:"bytes,14,rep,name=issuing_certificate_urls,json=issuingCertificateUrls,proto3" json:"issuing_certificate_urls,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{} `json:"-"`
+	XXX_unrecognized       []byte   `json:"-"`
+	XXX_sizecache          int32    `json:"-"`
+}
+
+func (m *X509CertificatePreview) Reset()         { *m = X509CertificatePreview{} }
+func (m *X509CertificatePreview) String() string { return proto.CompactTextString(m) }
+func (*X509CertificatePreview) ProtoMessage()    {}
+func (*X509CertificatePreview) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{10}
+}
+func (m *X509CertificatePreview) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_X509CertificatePreview.Unmarshal(m, b)
+}
+func (m *X509CertificatePreview) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_X509CertificatePreview.Marshal(b, m, deterministic)
+}
+func (dst *X509CertificatePreview) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_X509CertificatePreview.Merge(dst, src)
+}
+func (m *X509CertificatePreview) XXX_Size() int {
+	return xxx_messageInfo_X509CertificatePreview.Size(m)
+}
+func (m *X509CertificatePreview) XXX_DiscardUnknown() {
+	xxx_messageInfo_X509CertificatePreview.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_X509CertificatePreview proto.InternalMessageInfo
+
+func (m *X509CertificatePreview) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *X509CertificatePreview) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *X509CertificatePreview) GetNotBefore() int64 {
+	if m != nil {
+		return m.NotBefore
+	}
+	return 0
+}
+
+func (m *X509CertificatePreview) GetNotAfter() int64 {
+	if m != nil {
+		return m.NotAfter
+	}
+	return 0
+}
+
+func (m *X509CertificatePreview) GetPublicKeyAlgorithm() string {
+	if m != nil {
+		return m.PublicKeyAlgorithm
+	}
+	return ""
+}
+
+func (m *X509CertificatePreview) GetDnsNames() []string {
+	if m != nil {
+		return m.DnsNames
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetIpAddresses() []string {
+	if m != nil {
+		return m.IpAddresses
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetEmailAddresses() []string {
+	if m != nil {
+		return m.EmailAddresses
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetUris() []string {
+	if m != nil {
+		return m.Uris
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetKeyUsage() []string {
+	if m != nil {
+		return m.KeyUsage
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetExtKeyUsage() []int32 {
+	if m != nil {
+		return m.ExtKeyUsage
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetCrlDistributionPoints() []string {
+	if m != nil {
+		return m.CrlDistributionPoints
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetOcspServers() []string {
+	if m != nil {
+		return m.OcspServers
+	}
+	return nil
+}
+
+func (m *X509CertificatePreview) GetIssuingCertificateUrls() []string {
+	if m != nil {
+		return m.IssuingCertificateUrls
+	}
+	return nil
+}
+
+// TimestampRequest specifies an RFC 3161 time-stamp request.
+type TimestampRequest struct {
+	// Identifies the TSA key in the HSM used for signing the time-stamp token.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// DER encoded RFC 3161 TimeStampReq.
+	Request              []byte   `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TimestampRequest) Reset()         { *m = TimestampRequest{} }
+func (m *TimestampRequest) String() string { return proto.CompactTextString(m) }
+func (*TimestampRequest) ProtoMessage()    {}
+func (*TimestampRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{11}
+}
+func (m *TimestampRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimestampRequest.Unmarshal(m, b)
+}
+func (m *TimestampRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimestampRequest.Marshal(b, m, deterministic)
+}
+func (dst *TimestampRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimestampRequest.Merge(dst, src)
+}
+func (m *TimestampRequest) XXX_Size() int {
+	return xxx_messageInfo_TimestampRequest.Size(m)
+}
+func (m *TimestampRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimestampRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimestampRequest proto.InternalMessageInfo
+
+func (m *TimestampRequest) GetKeyMeta() *KeyMeta {
 	if m != nil {
 		return m.KeyMeta
 	}
 	return nil
 }
 
-func (m *BlobSigningRequest) GetDigest() string {
+func (m *TimestampRequest) GetRequest() []byte {
 	if m != nil {
-		return m.Digest
+		return m.Request
+	}
+	return nil
+}
+
+// TimestampResponse specifies an RFC 3161 time-stamp response.
+type TimestampResponse struct {
+	// DER encoded RFC 3161 TimeStampResp. A rejected request is reported in its status.
+	Response             []byte   `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TimestampResponse) Reset()         { *m = TimestampResponse{} }
+func (m *TimestampResponse) String() string { return proto.CompactTextString(m) }
+func (*TimestampResponse) ProtoMessage()    {}
+func (*TimestampResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{12}
+}
+func (m *TimestampResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TimestampResponse.Unmarshal(m, b)
+}
+func (m *TimestampResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TimestampResponse.Marshal(b, m, deterministic)
+}
+func (dst *TimestampResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimestampResponse.Merge(dst, src)
+}
+func (m *TimestampResponse) XXX_Size() int {
+	return xxx_messageInfo_TimestampResponse.Size(m)
+}
+func (m *TimestampResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimestampResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimestampResponse proto.InternalMessageInfo
+
+func (m *TimestampResponse) GetResponse() []byte {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+// GitSigningRequest specifies a git commit or tag object to be signed.
+type GitSigningRequest struct {
+	// Identifies the SSH key in the HSM used for signing the object.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The git object payload, as passed by git to the program configured by gpg.ssh.program.
+	Payload              []byte   `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GitSigningRequest) Reset()         { *m = GitSigningRequest{} }
+func (m *GitSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*GitSigningRequest) ProtoMessage()    {}
+func (*GitSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{13}
+}
+func (m *GitSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GitSigningRequest.Unmarshal(m, b)
+}
+func (m *GitSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GitSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *GitSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GitSigningRequest.Merge(dst, src)
+}
+func (m *GitSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_GitSigningRequest.Size(m)
+}
+func (m *GitSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GitSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GitSigningRequest proto.InternalMessageInfo
+
+func (m *GitSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *GitSigningRequest) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+// GitSignature specifies the signature of a git object.
+type GitSignature struct {
+	// The armored SSHSIG signature in the "git" namespace, to be stored in the object by git.
+	Signature            string   `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GitSignature) Reset()         { *m = GitSignature{} }
+func (m *GitSignature) String() string { return proto.CompactTextString(m) }
+func (*GitSignature) ProtoMessage()    {}
+func (*GitSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{14}
+}
+func (m *GitSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GitSignature.Unmarshal(m, b)
+}
+func (m *GitSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GitSignature.Marshal(b, m, deterministic)
+}
+func (dst *GitSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GitSignature.Merge(dst, src)
+}
+func (m *GitSignature) XXX_Size() int {
+	return xxx_messageInfo_GitSignature.Size(m)
+}
+func (m *GitSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_GitSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GitSignature proto.InternalMessageInfo
+
+func (m *GitSignature) GetSignature() string {
+	if m != nil {
+		return m.Signature
 	}
 	return ""
 }
 
-func (m *BlobSigningRequest) GetHashAlgorithm() HashAlgo {
+// DNSSECSigningRequest specifies the signing data of an RRSIG record to be signed.
+type DNSSECSigningRequest struct {
+	// Identifies the zone-signing key in the HSM used for signing the RRset.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The signing data as per RFC 4034 section 3.1.8.1, i.e. the RRSIG RDATA without the signature
+	// followed by the RRset in canonical form and order.
+	SigningData []byte `protobuf:"bytes,2,opt,name=signing_data,json=signingData,proto3" json:"signing_data,omitempty"`
+	// The hash algorithm of an RSA key, SHA256 for RSASHA256 or SHA512 for RSASHA512. Default is SHA256.
+	// The hash algorithm of an ECDSA key is determined by its curve, and must be unspecified or match it.
+	HashAlgorithm        HashAlgo `protobuf:"varint,3,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DNSSECSigningRequest) Reset()         { *m = DNSSECSigningRequest{} }
+func (m *DNSSECSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*DNSSECSigningRequest) ProtoMessage()    {}
+func (*DNSSECSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{15}
+}
+func (m *DNSSECSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DNSSECSigningRequest.Unmarshal(m, b)
+}
+func (m *DNSSECSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DNSSECSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *DNSSECSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DNSSECSigningRequest.Merge(dst, src)
+}
+func (m *DNSSECSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_DNSSECSigningRequest.Size(m)
+}
+func (m *DNSSECSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DNSSECSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DNSSECSigningRequest proto.InternalMessageInfo
+
+func (m *DNSSECSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *DNSSECSigningRequest) GetSigningData() []byte {
+	if m != nil {
+		return m.SigningData
+	}
+	return nil
+}
+
+func (m *DNSSECSigningRequest) GetHashAlgorithm() HashAlgo {
 	if m != nil {
 		return m.HashAlgorithm
 	}
 	return HashAlgo_Unspecified_Hash
 }
 
-// Signature is a base64 encoded result of signing a blob.
-type Signature struct {
-	Signature            string   `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+// DNSSECSignature specifies the signature of an RRSIG record.
+type DNSSECSignature struct {
+	// The signature in the wire format of the RRSIG signature field, i.e. PKCS #1 v1.5 for RSA keys
+	// as per RFC 5702 and the concatenation of r and s for ECDSA keys as per RFC 6605.
+	Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The DNSSEC algorithm number of the signature, to be set in the algorithm field of the RRSIG record,
+	// such as 8 for RSASHA256 or 13 for ECDSAP256SHA256.
+	Algorithm            uint32   `protobuf:"varint,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Signature) Reset()         { *m = Signature{} }
-func (m *Signature) String() string { return proto.CompactTextString(m) }
-func (*Signature) ProtoMessage()    {}
-func (*Signature) Descriptor() ([]byte, []int) {
-	return fileDescriptor_sign_726672ce56df3d9d, []int{8}
+func (m *DNSSECSignature) Reset()         { *m = DNSSECSignature{} }
+func (m *DNSSECSignature) String() string { return proto.CompactTextString(m) }
+func (*DNSSECSignature) ProtoMessage()    {}
+func (*DNSSECSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{16}
 }
-func (m *Signature) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Signature.Unmarshal(m, b)
+func (m *DNSSECSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DNSSECSignature.Unmarshal(m, b)
 }
-func (m *Signature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Signature.Marshal(b, m, deterministic)
+func (m *DNSSECSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DNSSECSignature.Marshal(b, m, deterministic)
 }
-func (dst *Signature) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Signature.Merge(dst, src)
+func (dst *DNSSECSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DNSSECSignature.Merge(dst, src)
 }
-func (m *Signature) XXX_Size() int {
-	return xxx_messageInfo_Signature.Size(m)
+func (m *DNSSECSignature) XXX_Size() int {
+	return xxx_messageInfo_DNSSECSignature.Size(m)
 }
-func (m *Signature) XXX_DiscardUnknown() {
-	xxx_messageInfo_Signature.DiscardUnknown(m)
+func (m *DNSSECSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_DNSSECSignature.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Signature proto.InternalMessageInfo
+var xxx_messageInfo_DNSSECSignature proto.InternalMessageInfo
 
-func (m *Signature) GetSignature() string {
+func (m *DNSSECSignature) GetSignature() []byte {
 	if m != nil {
 		return m.Signature
 	}
-	return ""
+	return nil
 }
 
-func init() {
-	proto.RegisterType((*KeyMeta)(nil), "v3.KeyMeta")
-	proto.RegisterType((*KeyMetas)(nil), "v3.KeyMetas")
-	proto.RegisterType((*SSHCertificateSigningRequest)(nil), "v3.SSHCertificateSigningRequest")
-	proto.RegisterMapType((map[string]string)(nil), "v3.SSHCertificateSigningRequest.CriticalOptionsEntry")
-	proto.RegisterMapType((map[string]string)(nil), "v3.SSHCertificateSigningRequest.ExtensionsEntry")
-	proto.RegisterType((*SSHKey)(nil), "v3.SSHKey")
-	proto.RegisterType((*X509CertificateSigningRequest)(nil), "v3.X509CertificateSigningRequest")
-	proto.RegisterType((*X509Certificate)(nil), "v3.X509Certificate")
-	proto.RegisterType((*PublicKey)(nil), "v3.PublicKey")
-	proto.RegisterType((*BlobSigningRequest)(nil), "v3.BlobSigningRequest")
-	proto.RegisterType((*Signature)(nil), "v3.Signature")
-	proto.RegisterEnum("v3.HashAlgo", HashAlgo_name, HashAlgo_value)
+func (m *DNSSECSignature) GetAlgorithm() uint32 {
+	if m != nil {
+		return m.Algorithm
+	}
+	return 0
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+// AttestationSigningRequest specifies a WebAuthn attestation to be signed in the packed attestation statement format.
+type AttestationSigningRequest struct {
+	// Identifies the attestation key in the HSM used for signing the attestation.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The authenticator data of the attestation object, at least 37 bytes long.
+	AuthenticatorData []byte `protobuf:"bytes,2,opt,name=authenticator_data,json=authenticatorData,proto3" json:"authenticator_data,omitempty"`
+	// The SHA256 hash of the serialized client data.
+	ClientDataHash       []byte   `protobuf:"bytes,3,opt,name=client_data_hash,json=clientDataHash,proto3" json:"client_data_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-// This is a compile-time assertion to ensure that this generated file
+func (m *AttestationSigningRequest) Reset()         { *m = AttestationSigningRequest{} }
+func (m *AttestationSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*AttestationSigningRequest) ProtoMessage()    {}
+func (*AttestationSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{17}
+}
+func (m *AttestationSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttestationSigningRequest.Unmarshal(m, b)
+}
+func (m *AttestationSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttestationSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *AttestationSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttestationSigningRequest.Merge(dst, src)
+}
+func (m *AttestationSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_AttestationSigningRequest.Size(m)
+}
+func (m *AttestationSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttestationSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttestationSigningRequest proto.InternalMessageInfo
+
+func (m *AttestationSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *AttestationSigningRequest) GetAuthenticatorData() []byte {
+	if m != nil {
+		return m.AuthenticatorData
+	}
+	return nil
+}
+
+func (m *AttestationSigningRequest) GetClientDataHash() []byte {
+	if m != nil {
+		return m.ClientDataHash
+	}
+	return nil
+}
+
+// AttestationStatement specifies a packed attestation statement, as per section 8.2 of WebAuthn.
+type AttestationStatement struct {
+	// The COSE algorithm identifier of the signature, such as -7 for ES256 or -257 for RS256.
+	Alg int64 `protobuf:"varint,1,opt,name=alg,proto3" json:"alg,omitempty"`
+	// The signature of the concatenation of the authenticator data and the client data hash,
+	// ASN.1 DER encoded for ECDSA keys and PKCS #1 v1.5 for RSA keys.
+	Sig []byte `protobuf:"bytes,2,opt,name=sig,proto3" json:"sig,omitempty"`
+	// The DER encoded attestation certificate, i.e. the X509 CA certificate of the attestation key.
+	X5C                  [][]byte `protobuf:"bytes,3,rep,name=x5c,proto3" json:"x5c,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AttestationStatement) Reset()         { *m = AttestationStatement{} }
+func (m *AttestationStatement) String() string { return proto.CompactTextString(m) }
+func (*AttestationStatement) ProtoMessage()    {}
+func (*AttestationStatement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{18}
+}
+func (m *AttestationStatement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttestationStatement.Unmarshal(m, b)
+}
+func (m *AttestationStatement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttestationStatement.Marshal(b, m, deterministic)
+}
+func (dst *AttestationStatement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttestationStatement.Merge(dst, src)
+}
+func (m *AttestationStatement) XXX_Size() int {
+	return xxx_messageInfo_AttestationStatement.Size(m)
+}
+func (m *AttestationStatement) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttestationStatement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttestationStatement proto.InternalMessageInfo
+
+func (m *AttestationStatement) GetAlg() int64 {
+	if m != nil {
+		return m.Alg
+	}
+	return 0
+}
+
+func (m *AttestationStatement) GetSig() []byte {
+	if m != nil {
+		return m.Sig
+	}
+	return nil
+}
+
+func (m *AttestationStatement) GetX5C() [][]byte {
+	if m != nil {
+		return m.X5C
+	}
+	return nil
+}
+
+// ContainerImageSigningRequest specifies a container image to be signed in the cosign simple signing format.
+type ContainerImageSigningRequest struct {
+	// Identifies the ECDSA signing key in the HSM used for signing the image.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The simple signing JSON payload to be signed, as generated by "cosign generate". If empty, the payload
+	// is composed from docker_reference and manifest_digest.
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The docker reference of the image, such as "registry.example.com/app".
+	DockerReference string `protobuf:"bytes,3,opt,name=docker_reference,json=dockerReference,proto3" json:"docker_reference,omitempty"`
+	// The digest of the image manifest, such as "sha256:<hex>". If specified along with the payload,
+	// it must match the digest in the payload.
+	ManifestDigest       string   `protobuf:"bytes,4,opt,name=manifest_digest,json=manifestDigest,proto3" json:"manifest_digest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContainerImageSigningRequest) Reset()         { *m = ContainerImageSigningRequest{} }
+func (m *ContainerImageSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*ContainerImageSigningRequest) ProtoMessage()    {}
+func (*ContainerImageSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{19}
+}
+func (m *ContainerImageSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContainerImageSigningRequest.Unmarshal(m, b)
+}
+func (m *ContainerImageSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContainerImageSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *ContainerImageSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContainerImageSigningRequest.Merge(dst, src)
+}
+func (m *ContainerImageSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_ContainerImageSigningRequest.Size(m)
+}
+func (m *ContainerImageSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContainerImageSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContainerImageSigningRequest proto.InternalMessageInfo
+
+func (m *ContainerImageSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *ContainerImageSigningRequest) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *ContainerImageSigningRequest) GetDockerReference() string {
+	if m != nil {
+		return m.DockerReference
+	}
+	return ""
+}
+
+func (m *ContainerImageSigningRequest) GetManifestDigest() string {
+	if m != nil {
+		return m.ManifestDigest
+	}
+	return ""
+}
+
+// ContainerImageSignature specifies a cosign signature of a container image.
+type ContainerImageSignature struct {
+	// The simple signing payload that is signed, to be uploaded as the layer of the signature image.
+	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The base64 encoded ASN.1 DER ECDSA signature of the payload, the value of the
+	// "dev.cosignproject.cosign/signature" annotation of the layer.
+	Signature string `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The PEM encoded public key of the signing key, as read by "cosign verify --key".
+	PublicKey            string   `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContainerImageSignature) Reset()         { *m = ContainerImageSignature{} }
+func (m *ContainerImageSignature) String() string { return proto.CompactTextString(m) }
+func (*ContainerImageSignature) ProtoMessage()    {}
+func (*ContainerImageSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{20}
+}
+func (m *ContainerImageSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContainerImageSignature.Unmarshal(m, b)
+}
+func (m *ContainerImageSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContainerImageSignature.Marshal(b, m, deterministic)
+}
+func (dst *ContainerImageSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContainerImageSignature.Merge(dst, src)
+}
+func (m *ContainerImageSignature) XXX_Size() int {
+	return xxx_messageInfo_ContainerImageSignature.Size(m)
+}
+func (m *ContainerImageSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContainerImageSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContainerImageSignature proto.InternalMessageInfo
+
+func (m *ContainerImageSignature) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *ContainerImageSignature) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *ContainerImageSignature) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+// PublicKey is a encoded string of the public key specified by users.
+type PublicKey struct {
+	// The encoded string of the public key.
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// The PEM encoded signing certificate of the key, followed by its chain, if requested with
+	// include_certificate.
+	Certificate          string   `protobuf:"bytes,2,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PublicKey) Reset()         { *m = PublicKey{} }
+func (m *PublicKey) String() string { return proto.CompactTextString(m) }
+func (*PublicKey) ProtoMessage()    {}
+func (*PublicKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{21}
+}
+func (m *PublicKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublicKey.Unmarshal(m, b)
+}
+func (m *PublicKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublicKey.Marshal(b, m, deterministic)
+}
+func (dst *PublicKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublicKey.Merge(dst, src)
+}
+func (m *PublicKey) XXX_Size() int {
+	return xxx_messageInfo_PublicKey.Size(m)
+}
+func (m *PublicKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublicKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublicKey proto.InternalMessageInfo
+
+func (m *PublicKey) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *PublicKey) GetCertificate() string {
+	if m != nil {
+		return m.Certificate
+	}
+	return ""
+}
+
+type BlobSigningRequest struct {
+	// Identifies the signing key in the PKCS#11 device used for signing the blob.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// the hash digest of blob in base64 which will be signed, or in the encoding of digest_encoding.
+	Digest string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// the algorithm of hash function used to generate the digest
+	// https://golang.org/pkg/crypto/#Hash.
+	HashAlgorithm HashAlgo `protobuf:"varint,3,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	// the signature scheme, which must be supported by the key. If unspecified for an ECDSA key,
+	// the encoding preferred in the x-crypki-signature-format request metadata, if any, is used.
+	SignatureScheme SignatureScheme `protobuf:"varint,4,opt,name=signature_scheme,json=signatureScheme,proto3,enum=v3.SignatureScheme" json:"signature_scheme,omitempty"`
+	// the context string of at most 255 bytes that is mixed into the signature for domain separation,
+	// as Ed25519ph does per RFC 8032. It is only supported by Ed25519 keys and is rejected for the others.
+	Context string `protobuf:"bytes,5,opt,name=context,proto3" json:"context,omitempty"`
+	// The priority of the request for a session of the signing key.
+	Priority Priority `protobuf:"varint,6,opt,name=priority,proto3,enum=v3.Priority" json:"priority,omitempty"`
+	// The keys that sign the digest along with the key of key_meta, e.g. to dual sign during the migration
+	// to a new key. Each key must be usable for blob signing and is checked as the key of key_meta.
+	CoSignerKeyMetas []*KeyMeta `protobuf:"bytes,7,rep,name=co_signer_key_metas,json=coSignerKeyMetas,proto3" json:"co_signer_key_metas,omitempty"`
+	// The behavior of the request if some of the keys fail to sign. If unspecified, the PartialAvailability
+	// configured for the key of key_meta is used.
+	PartialAvailability PartialAvailability `protobuf:"varint,8,opt,name=partial_availability,json=partialAvailability,proto3,enum=v3.PartialAvailability" json:"partial_availability,omitempty"`
+	// The encoding of the digest. If unspecified, the digest is base64 encoded.
+	DigestEncoding       DigestEncoding `protobuf:"varint,9,opt,name=digest_encoding,json=digestEncoding,proto3,enum=v3.DigestEncoding" json:"digest_encoding,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *BlobSigningRequest) Reset()         { *m = BlobSigningRequest{} }
+func (m *BlobSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*BlobSigningRequest) ProtoMessage()    {}
+func (*BlobSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{22}
+}
+func (m *BlobSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlobSigningRequest.Unmarshal(m, b)
+}
+func (m *BlobSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlobSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *BlobSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlobSigningRequest.Merge(dst, src)
+}
+func (m *BlobSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_BlobSigningRequest.Size(m)
+}
+func (m *BlobSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlobSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlobSigningRequest proto.InternalMessageInfo
+
+func (m *BlobSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *BlobSigningRequest) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *BlobSigningRequest) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+func (m *BlobSigningRequest) GetSignatureScheme() SignatureScheme {
+	if m != nil {
+		return m.SignatureScheme
+	}
+	return SignatureScheme_Unspecified_SignatureScheme
+}
+
+func (m *BlobSigningRequest) GetContext() string {
+	if m != nil {
+		return m.Context
+	}
+	return ""
+}
+
+func (m *BlobSigningRequest) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_NORMAL
+}
+
+func (m *BlobSigningRequest) GetCoSignerKeyMetas() []*KeyMeta {
+	if m != nil {
+		return m.CoSignerKeyMetas
+	}
+	return nil
+}
+
+func (m *BlobSigningRequest) GetPartialAvailability() PartialAvailability {
+	if m != nil {
+		return m.PartialAvailability
+	}
+	return PartialAvailability_Unspecified_PartialAvailability
+}
+
+func (m *BlobSigningRequest) GetDigestEncoding() DigestEncoding {
+	if m != nil {
+		return m.DigestEncoding
+	}
+	return DigestEncoding_Unspecified_DigestEncoding
+}
+
+// BlobBatchEntry is a digest of a batch signing request.
+type BlobBatchEntry struct {
+	// The base64 encoded digest to sign.
+	Digest string `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The hash algorithm of the digest, which must be supported by the key. If unspecified, the
+	// hash_algorithm of the request is used.
+	HashAlgorithm        HashAlgo `protobuf:"varint,2,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlobBatchEntry) Reset()         { *m = BlobBatchEntry{} }
+func (m *BlobBatchEntry) String() string { return proto.CompactTextString(m) }
+func (*BlobBatchEntry) ProtoMessage()    {}
+func (*BlobBatchEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{23}
+}
+func (m *BlobBatchEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlobBatchEntry.Unmarshal(m, b)
+}
+func (m *BlobBatchEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlobBatchEntry.Marshal(b, m, deterministic)
+}
+func (dst *BlobBatchEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlobBatchEntry.Merge(dst, src)
+}
+func (m *BlobBatchEntry) XXX_Size() int {
+	return xxx_messageInfo_BlobBatchEntry.Size(m)
+}
+func (m *BlobBatchEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlobBatchEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlobBatchEntry proto.InternalMessageInfo
+
+func (m *BlobBatchEntry) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *BlobBatchEntry) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+// BlobBatchSigningRequest specifies digests to sign with the same key, each with its own hash algorithm.
+type BlobBatchSigningRequest struct {
+	// Identifies the signing key.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The digests to sign, at most MaxBlobBatchSize of them.
+	Entries []*BlobBatchEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	// The hash algorithm of the entries that do not specify theirs.
+	HashAlgorithm HashAlgo `protobuf:"varint,3,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	// The signature scheme, as in BlobSigningRequest.
+	SignatureScheme SignatureScheme `protobuf:"varint,4,opt,name=signature_scheme,json=signatureScheme,proto3,enum=v3.SignatureScheme" json:"signature_scheme,omitempty"`
+	// The priority of the request for a session of the signing key.
+	Priority             Priority `protobuf:"varint,5,opt,name=priority,proto3,enum=v3.Priority" json:"priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlobBatchSigningRequest) Reset()         { *m = BlobBatchSigningRequest{} }
+func (m *BlobBatchSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*BlobBatchSigningRequest) ProtoMessage()    {}
+func (*BlobBatchSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{24}
+}
+func (m *BlobBatchSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlobBatchSigningRequest.Unmarshal(m, b)
+}
+func (m *BlobBatchSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlobBatchSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *BlobBatchSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlobBatchSigningRequest.Merge(dst, src)
+}
+func (m *BlobBatchSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_BlobBatchSigningRequest.Size(m)
+}
+func (m *BlobBatchSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlobBatchSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlobBatchSigningRequest proto.InternalMessageInfo
+
+func (m *BlobBatchSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *BlobBatchSigningRequest) GetEntries() []*BlobBatchEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *BlobBatchSigningRequest) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+func (m *BlobBatchSigningRequest) GetSignatureScheme() SignatureScheme {
+	if m != nil {
+		return m.SignatureScheme
+	}
+	return SignatureScheme_Unspecified_SignatureScheme
+}
+
+func (m *BlobBatchSigningRequest) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_NORMAL
+}
+
+// BlobBatchSignatures contains the signatures of the entries of a batch signing request.
+type BlobBatchSignatures struct {
+	// The base64 encoded signatures, in the order of the entries.
+	Signatures           []string `protobuf:"bytes,1,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlobBatchSignatures) Reset()         { *m = BlobBatchSignatures{} }
+func (m *BlobBatchSignatures) String() string { return proto.CompactTextString(m) }
+func (*BlobBatchSignatures) ProtoMessage()    {}
+func (*BlobBatchSignatures) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{25}
+}
+func (m *BlobBatchSignatures) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlobBatchSignatures.Unmarshal(m, b)
+}
+func (m *BlobBatchSignatures) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlobBatchSignatures.Marshal(b, m, deterministic)
+}
+func (dst *BlobBatchSignatures) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlobBatchSignatures.Merge(dst, src)
+}
+func (m *BlobBatchSignatures) XXX_Size() int {
+	return xxx_messageInfo_BlobBatchSignatures.Size(m)
+}
+func (m *BlobBatchSignatures) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlobBatchSignatures.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlobBatchSignatures proto.InternalMessageInfo
+
+func (m *BlobBatchSignatures) GetSignatures() []string {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+// BlobBatchEntrySignature is the result of signing an entry of a streamed batch signing request.
+type BlobBatchEntrySignature struct {
+	// The index of the entry in the request.
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// The base64 encoded signature of the entry. Empty if the entry could not be signed.
+	Signature string `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The gRPC status code of the signing of the entry, OK (0) if it was signed.
+	Code int32 `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	// The error message of the signing of the entry, if it was not signed.
+	Message              string   `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlobBatchEntrySignature) Reset()         { *m = BlobBatchEntrySignature{} }
+func (m *BlobBatchEntrySignature) String() string { return proto.CompactTextString(m) }
+func (*BlobBatchEntrySignature) ProtoMessage()    {}
+func (*BlobBatchEntrySignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{26}
+}
+func (m *BlobBatchEntrySignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlobBatchEntrySignature.Unmarshal(m, b)
+}
+func (m *BlobBatchEntrySignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlobBatchEntrySignature.Marshal(b, m, deterministic)
+}
+func (dst *BlobBatchEntrySignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlobBatchEntrySignature.Merge(dst, src)
+}
+func (m *BlobBatchEntrySignature) XXX_Size() int {
+	return xxx_messageInfo_BlobBatchEntrySignature.Size(m)
+}
+func (m *BlobBatchEntrySignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlobBatchEntrySignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlobBatchEntrySignature proto.InternalMessageInfo
+
+func (m *BlobBatchEntrySignature) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *BlobBatchEntrySignature) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *BlobBatchEntrySignature) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *BlobBatchEntrySignature) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// SSHCertificateVerificationRequest specifies the SSH certificate to verify against a CA key.
+type SSHCertificateVerificationRequest struct {
+	// Identifies the CA key expected to have signed the certificate. It must be usable for the
+	// endpoint of the type of the certificate.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The certificate in authorized_keys format, as returned by PostUserSSHCertificate and PostHostSSHCertificate.
+	Certificate          string   `protobuf:"bytes,2,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SSHCertificateVerificationRequest) Reset()         { *m = SSHCertificateVerificationRequest{} }
+func (m *SSHCertificateVerificationRequest) String() string { return proto.CompactTextString(m) }
+func (*SSHCertificateVerificationRequest) ProtoMessage()    {}
+func (*SSHCertificateVerificationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{27}
+}
+func (m *SSHCertificateVerificationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SSHCertificateVerificationRequest.Unmarshal(m, b)
+}
+func (m *SSHCertificateVerificationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SSHCertificateVerificationRequest.Marshal(b, m, deterministic)
+}
+func (dst *SSHCertificateVerificationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SSHCertificateVerificationRequest.Merge(dst, src)
+}
+func (m *SSHCertificateVerificationRequest) XXX_Size() int {
+	return xxx_messageInfo_SSHCertificateVerificationRequest.Size(m)
+}
+func (m *SSHCertificateVerificationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SSHCertificateVerificationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SSHCertificateVerificationRequest proto.InternalMessageInfo
+
+func (m *SSHCertificateVerificationRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *SSHCertificateVerificationRequest) GetCertificate() string {
+	if m != nil {
+		return m.Certificate
+	}
+	return ""
+}
+
+// SSHCertificateVerification is the result of the verification of an SSH certificate.
+type SSHCertificateVerification struct {
+	// Whether the certificate is signed by the CA key.
+	SignatureValid bool `protobuf:"varint,1,opt,name=signature_valid,json=signatureValid,proto3" json:"signature_valid,omitempty"`
+	// Whether the current time is within the validity period of the certificate.
+	WithinValidity bool `protobuf:"varint,2,opt,name=within_validity,json=withinValidity,proto3" json:"within_validity,omitempty"`
+	// The validity period of the certificate, in seconds since the epoch.
+	ValidAfter  uint64 `protobuf:"varint,3,opt,name=valid_after,json=validAfter,proto3" json:"valid_after,omitempty"`
+	ValidBefore uint64 `protobuf:"varint,4,opt,name=valid_before,json=validBefore,proto3" json:"valid_before,omitempty"`
+	// The reason the certificate is not valid, empty if it is valid.
+	Reason               string   `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SSHCertificateVerification) Reset()         { *m = SSHCertificateVerification{} }
+func (m *SSHCertificateVerification) String() string { return proto.CompactTextString(m) }
+func (*SSHCertificateVerification) ProtoMessage()    {}
+func (*SSHCertificateVerification) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{28}
+}
+func (m *SSHCertificateVerification) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SSHCertificateVerification.Unmarshal(m, b)
+}
+func (m *SSHCertificateVerification) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SSHCertificateVerification.Marshal(b, m, deterministic)
+}
+func (dst *SSHCertificateVerification) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SSHCertificateVerification.Merge(dst, src)
+}
+func (m *SSHCertificateVerification) XXX_Size() int {
+	return xxx_messageInfo_SSHCertificateVerification.Size(m)
+}
+func (m *SSHCertificateVerification) XXX_DiscardUnknown() {
+	xxx_messageInfo_SSHCertificateVerification.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SSHCertificateVerification proto.InternalMessageInfo
+
+func (m *SSHCertificateVerification) GetSignatureValid() bool {
+	if m != nil {
+		return m.SignatureValid
+	}
+	return false
+}
+
+func (m *SSHCertificateVerification) GetWithinValidity() bool {
+	if m != nil {
+		return m.WithinValidity
+	}
+	return false
+}
+
+func (m *SSHCertificateVerification) GetValidAfter() uint64 {
+	if m != nil {
+		return m.ValidAfter
+	}
+	return 0
+}
+
+func (m *SSHCertificateVerification) GetValidBefore() uint64 {
+	if m != nil {
+		return m.ValidBefore
+	}
+	return 0
+}
+
+func (m *SSHCertificateVerification) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+// CMSSigningRequest specifies the digest of the content to sign in a detached CMS signature.
+type CMSSigningRequest struct {
+	// Identifies the signing key, whose X509 CA certificate identifies the signer.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The base64 encoded digest of the content, which is the message-digest signed attribute.
+	Digest string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The algorithm of the hash function used to generate the digest: SHA256, SHA384 or SHA512.
+	// The signed attributes are hashed with the same algorithm.
+	HashAlgorithm        HashAlgo `protobuf:"varint,3,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CMSSigningRequest) Reset()         { *m = CMSSigningRequest{} }
+func (m *CMSSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*CMSSigningRequest) ProtoMessage()    {}
+func (*CMSSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{29}
+}
+func (m *CMSSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CMSSigningRequest.Unmarshal(m, b)
+}
+func (m *CMSSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CMSSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *CMSSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CMSSigningRequest.Merge(dst, src)
+}
+func (m *CMSSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_CMSSigningRequest.Size(m)
+}
+func (m *CMSSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CMSSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CMSSigningRequest proto.InternalMessageInfo
+
+func (m *CMSSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *CMSSigningRequest) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *CMSSigningRequest) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+// CMSSignature contains a detached CMS signature.
+type CMSSignature struct {
+	// The base64 encoded DER ContentInfo of the SignedData, such as an S/MIME application/pkcs7-signature.
+	Cms                  string   `protobuf:"bytes,1,opt,name=cms,proto3" json:"cms,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CMSSignature) Reset()         { *m = CMSSignature{} }
+func (m *CMSSignature) String() string { return proto.CompactTextString(m) }
+func (*CMSSignature) ProtoMessage()    {}
+func (*CMSSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{30}
+}
+func (m *CMSSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CMSSignature.Unmarshal(m, b)
+}
+func (m *CMSSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CMSSignature.Marshal(b, m, deterministic)
+}
+func (dst *CMSSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CMSSignature.Merge(dst, src)
+}
+func (m *CMSSignature) XXX_Size() int {
+	return xxx_messageInfo_CMSSignature.Size(m)
+}
+func (m *CMSSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_CMSSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CMSSignature proto.InternalMessageInfo
+
+func (m *CMSSignature) GetCms() string {
+	if m != nil {
+		return m.Cms
+	}
+	return ""
+}
+
+// EphemeralSigningRequest specifies the digest to sign with a one-time key generated in the HSM.
+type EphemeralSigningRequest struct {
+	// Identifies the configuration of the one-time key.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The base64 encoded digest to sign.
+	Digest string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The algorithm of the hash function used to generate the digest. It must be specified for RSA keys.
+	// For Ed25519 keys, the digest is signed as the message with pure Ed25519 if unspecified, and with
+	// Ed25519ph if SHA512.
+	HashAlgorithm        HashAlgo `protobuf:"varint,3,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EphemeralSigningRequest) Reset()         { *m = EphemeralSigningRequest{} }
+func (m *EphemeralSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*EphemeralSigningRequest) ProtoMessage()    {}
+func (*EphemeralSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{31}
+}
+func (m *EphemeralSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EphemeralSigningRequest.Unmarshal(m, b)
+}
+func (m *EphemeralSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EphemeralSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *EphemeralSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EphemeralSigningRequest.Merge(dst, src)
+}
+func (m *EphemeralSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_EphemeralSigningRequest.Size(m)
+}
+func (m *EphemeralSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EphemeralSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EphemeralSigningRequest proto.InternalMessageInfo
+
+func (m *EphemeralSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *EphemeralSigningRequest) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *EphemeralSigningRequest) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+// EphemeralSignature contains the signature of a one-time key and its public key.
+type EphemeralSignature struct {
+	// The base64 encoded signature.
+	Signature string `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The public key of the one-time key encoded in PEM format.
+	PublicKey            string   `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EphemeralSignature) Reset()         { *m = EphemeralSignature{} }
+func (m *EphemeralSignature) String() string { return proto.CompactTextString(m) }
+func (*EphemeralSignature) ProtoMessage()    {}
+func (*EphemeralSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{32}
+}
+func (m *EphemeralSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EphemeralSignature.Unmarshal(m, b)
+}
+func (m *EphemeralSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EphemeralSignature.Marshal(b, m, deterministic)
+}
+func (dst *EphemeralSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EphemeralSignature.Merge(dst, src)
+}
+func (m *EphemeralSignature) XXX_Size() int {
+	return xxx_messageInfo_EphemeralSignature.Size(m)
+}
+func (m *EphemeralSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_EphemeralSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EphemeralSignature proto.InternalMessageInfo
+
+func (m *EphemeralSignature) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *EphemeralSignature) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+// TenantSigningRequest specifies the digest to sign with the subkey of a tenant derived from a tenant key.
+type TenantSigningRequest struct {
+	// Identifies the configuration of the tenant key.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The label of the tenant, from which its subkey is derived.
+	Tenant string `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	// The base64 encoded digest to sign.
+	Digest string `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The algorithm of the hash function used to generate the digest. The digest is signed as the message
+	// with pure Ed25519 if unspecified, and with Ed25519ph if SHA512.
+	HashAlgorithm        HashAlgo `protobuf:"varint,4,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TenantSigningRequest) Reset()         { *m = TenantSigningRequest{} }
+func (m *TenantSigningRequest) String() string { return proto.CompactTextString(m) }
+func (*TenantSigningRequest) ProtoMessage()    {}
+func (*TenantSigningRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{33}
+}
+func (m *TenantSigningRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TenantSigningRequest.Unmarshal(m, b)
+}
+func (m *TenantSigningRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TenantSigningRequest.Marshal(b, m, deterministic)
+}
+func (dst *TenantSigningRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TenantSigningRequest.Merge(dst, src)
+}
+func (m *TenantSigningRequest) XXX_Size() int {
+	return xxx_messageInfo_TenantSigningRequest.Size(m)
+}
+func (m *TenantSigningRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TenantSigningRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TenantSigningRequest proto.InternalMessageInfo
+
+func (m *TenantSigningRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *TenantSigningRequest) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *TenantSigningRequest) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *TenantSigningRequest) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+// TenantSignature contains the signature of the subkey of a tenant and its public key.
+type TenantSignature struct {
+	// The base64 encoded signature.
+	Signature string `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The public key of the subkey of the tenant encoded in PEM format.
+	PublicKey            string   `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TenantSignature) Reset()         { *m = TenantSignature{} }
+func (m *TenantSignature) String() string { return proto.CompactTextString(m) }
+func (*TenantSignature) ProtoMessage()    {}
+func (*TenantSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{34}
+}
+func (m *TenantSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TenantSignature.Unmarshal(m, b)
+}
+func (m *TenantSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TenantSignature.Marshal(b, m, deterministic)
+}
+func (dst *TenantSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TenantSignature.Merge(dst, src)
+}
+func (m *TenantSignature) XXX_Size() int {
+	return xxx_messageInfo_TenantSignature.Size(m)
+}
+func (m *TenantSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_TenantSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TenantSignature proto.InternalMessageInfo
+
+func (m *TenantSignature) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *TenantSignature) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+// BlobStreamRequest is a message of the stream of a blob that crypki hashes and signs.
+type BlobStreamRequest struct {
+	// Identifies the signing key. It is only read from the first message of the stream.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The hash algorithm with which crypki hashes the blob, which must be supported by the key.
+	// It is only read from the first message of the stream.
+	HashAlgorithm HashAlgo `protobuf:"varint,2,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	// The signature scheme, as in BlobSigningRequest. It is only read from the first message of the stream.
+	SignatureScheme SignatureScheme `protobuf:"varint,3,opt,name=signature_scheme,json=signatureScheme,proto3,enum=v3.SignatureScheme" json:"signature_scheme,omitempty"`
+	// The next chunk of the blob.
+	Chunk                []byte   `protobuf:"bytes,4,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlobStreamRequest) Reset()         { *m = BlobStreamRequest{} }
+func (m *BlobStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*BlobStreamRequest) ProtoMessage()    {}
+func (*BlobStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{35}
+}
+func (m *BlobStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlobStreamRequest.Unmarshal(m, b)
+}
+func (m *BlobStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlobStreamRequest.Marshal(b, m, deterministic)
+}
+func (dst *BlobStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlobStreamRequest.Merge(dst, src)
+}
+func (m *BlobStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_BlobStreamRequest.Size(m)
+}
+func (m *BlobStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlobStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlobStreamRequest proto.InternalMessageInfo
+
+func (m *BlobStreamRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *BlobStreamRequest) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+func (m *BlobStreamRequest) GetSignatureScheme() SignatureScheme {
+	if m != nil {
+		return m.SignatureScheme
+	}
+	return SignatureScheme_Unspecified_SignatureScheme
+}
+
+func (m *BlobStreamRequest) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+// BlobStreamSignature is the signature of a streamed blob.
+type BlobStreamSignature struct {
+	// The base64 encoded signature of the digest of the blob.
+	Signature string `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The base64 encoded digest of the blob computed by crypki, for the client to check against its own.
+	Digest string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The hash algorithm of the digest.
+	HashAlgorithm        HashAlgo `protobuf:"varint,3,opt,name=hash_algorithm,json=hashAlgorithm,proto3,enum=v3.HashAlgo" json:"hash_algorithm,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlobStreamSignature) Reset()         { *m = BlobStreamSignature{} }
+func (m *BlobStreamSignature) String() string { return proto.CompactTextString(m) }
+func (*BlobStreamSignature) ProtoMessage()    {}
+func (*BlobStreamSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{36}
+}
+func (m *BlobStreamSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlobStreamSignature.Unmarshal(m, b)
+}
+func (m *BlobStreamSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlobStreamSignature.Marshal(b, m, deterministic)
+}
+func (dst *BlobStreamSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlobStreamSignature.Merge(dst, src)
+}
+func (m *BlobStreamSignature) XXX_Size() int {
+	return xxx_messageInfo_BlobStreamSignature.Size(m)
+}
+func (m *BlobStreamSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlobStreamSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlobStreamSignature proto.InternalMessageInfo
+
+func (m *BlobStreamSignature) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *BlobStreamSignature) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *BlobStreamSignature) GetHashAlgorithm() HashAlgo {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return HashAlgo_Unspecified_Hash
+}
+
+// Signature is a base64 encoded result of signing a blob.
+type Signature struct {
+	// The signature of the key of key_meta, empty if it failed to sign in best-effort mode.
+	Signature string `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The results of every key of a request with co-signers, the key of key_meta first.
+	Signatures           []*KeySignature `protobuf:"bytes,2,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Signature) Reset()         { *m = Signature{} }
+func (m *Signature) String() string { return proto.CompactTextString(m) }
+func (*Signature) ProtoMessage()    {}
+func (*Signature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{37}
+}
+func (m *Signature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Signature.Unmarshal(m, b)
+}
+func (m *Signature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Signature.Marshal(b, m, deterministic)
+}
+func (dst *Signature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Signature.Merge(dst, src)
+}
+func (m *Signature) XXX_Size() int {
+	return xxx_messageInfo_Signature.Size(m)
+}
+func (m *Signature) XXX_DiscardUnknown() {
+	xxx_messageInfo_Signature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Signature proto.InternalMessageInfo
+
+func (m *Signature) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *Signature) GetSignatures() []*KeySignature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+// KeySignature is the result of one of the keys of a blob signing request with co-signers.
+type KeySignature struct {
+	// The identifier of the key.
+	Identifier string `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	// The base64 encoded signature of the key, empty if it failed to sign.
+	Signature string `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The error of the key if it failed to sign.
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeySignature) Reset()         { *m = KeySignature{} }
+func (m *KeySignature) String() string { return proto.CompactTextString(m) }
+func (*KeySignature) ProtoMessage()    {}
+func (*KeySignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{38}
+}
+func (m *KeySignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeySignature.Unmarshal(m, b)
+}
+func (m *KeySignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeySignature.Marshal(b, m, deterministic)
+}
+func (dst *KeySignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeySignature.Merge(dst, src)
+}
+func (m *KeySignature) XXX_Size() int {
+	return xxx_messageInfo_KeySignature.Size(m)
+}
+func (m *KeySignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeySignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeySignature proto.InternalMessageInfo
+
+func (m *KeySignature) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *KeySignature) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *KeySignature) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// EndpointStatus specifies whether the signing requests of an endpoint are served.
+type EndpointStatus struct {
+	// The endpoint, e.g. "/sig/blob".
+	Endpoint string `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	// Whether the signing requests of the endpoint are served.
+	Enabled              bool     `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EndpointStatus) Reset()         { *m = EndpointStatus{} }
+func (m *EndpointStatus) String() string { return proto.CompactTextString(m) }
+func (*EndpointStatus) ProtoMessage()    {}
+func (*EndpointStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{39}
+}
+func (m *EndpointStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EndpointStatus.Unmarshal(m, b)
+}
+func (m *EndpointStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EndpointStatus.Marshal(b, m, deterministic)
+}
+func (dst *EndpointStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EndpointStatus.Merge(dst, src)
+}
+func (m *EndpointStatus) XXX_Size() int {
+	return xxx_messageInfo_EndpointStatus.Size(m)
+}
+func (m *EndpointStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_EndpointStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EndpointStatus proto.InternalMessageInfo
+
+func (m *EndpointStatus) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *EndpointStatus) GetEnabled() bool {
+	if m != nil {
+		return m.Enabled
+	}
+	return false
+}
+
+// CircuitBreakerStatus contains the state of the circuit breaker of a signing key.
+type CircuitBreakerStatus struct {
+	// The key identifier.
+	Identifier string `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	// The state of the circuit breaker: "closed", "open" or "half-open".
+	State string `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	// The number of consecutive signing failures of the key.
+	ConsecutiveFailures  uint32   `protobuf:"varint,3,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CircuitBreakerStatus) Reset()         { *m = CircuitBreakerStatus{} }
+func (m *CircuitBreakerStatus) String() string { return proto.CompactTextString(m) }
+func (*CircuitBreakerStatus) ProtoMessage()    {}
+func (*CircuitBreakerStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{40}
+}
+func (m *CircuitBreakerStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CircuitBreakerStatus.Unmarshal(m, b)
+}
+func (m *CircuitBreakerStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CircuitBreakerStatus.Marshal(b, m, deterministic)
+}
+func (dst *CircuitBreakerStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CircuitBreakerStatus.Merge(dst, src)
+}
+func (m *CircuitBreakerStatus) XXX_Size() int {
+	return xxx_messageInfo_CircuitBreakerStatus.Size(m)
+}
+func (m *CircuitBreakerStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_CircuitBreakerStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CircuitBreakerStatus proto.InternalMessageInfo
+
+func (m *CircuitBreakerStatus) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *CircuitBreakerStatus) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *CircuitBreakerStatus) GetConsecutiveFailures() uint32 {
+	if m != nil {
+		return m.ConsecutiveFailures
+	}
+	return 0
+}
+
+// ServerInfo contains the runtime state of the server.
+type ServerInfo struct {
+	// Status of each endpoint.
+	Endpoints []*EndpointStatus `protobuf:"bytes,1,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	// Status of the circuit breaker of each key that has served a signing request.
+	CircuitBreakers      []*CircuitBreakerStatus `protobuf:"bytes,2,rep,name=circuit_breakers,json=circuitBreakers,proto3" json:"circuit_breakers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *ServerInfo) Reset()         { *m = ServerInfo{} }
+func (m *ServerInfo) String() string { return proto.CompactTextString(m) }
+func (*ServerInfo) ProtoMessage()    {}
+func (*ServerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{41}
+}
+func (m *ServerInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerInfo.Unmarshal(m, b)
+}
+func (m *ServerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerInfo.Marshal(b, m, deterministic)
+}
+func (dst *ServerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerInfo.Merge(dst, src)
+}
+func (m *ServerInfo) XXX_Size() int {
+	return xxx_messageInfo_ServerInfo.Size(m)
+}
+func (m *ServerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerInfo proto.InternalMessageInfo
+
+func (m *ServerInfo) GetEndpoints() []*EndpointStatus {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
+func (m *ServerInfo) GetCircuitBreakers() []*CircuitBreakerStatus {
+	if m != nil {
+		return m.CircuitBreakers
+	}
+	return nil
+}
+
+// IssuanceRecord describes a certificate issued by crypki. It carries metadata only.
+type IssuanceRecord struct {
+	// The type of the certificate: "x509", "ssh-user" or "ssh-host".
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// The identifier of the key that signed the certificate.
+	Identifier string `protobuf:"bytes,2,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	// The decimal serial number of the certificate.
+	Serial string `protobuf:"bytes,3,opt,name=serial,proto3" json:"serial,omitempty"`
+	// The subject of an x509 certificate.
+	Subject string `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	// The key ID of an SSH certificate.
+	KeyId string `protobuf:"bytes,5,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// The principals of an SSH certificate.
+	Principals []string `protobuf:"bytes,6,rep,name=principals,proto3" json:"principals,omitempty"`
+	// The time the certificate was issued, in seconds since the epoch.
+	IssuedAt int64 `protobuf:"varint,7,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	// The validity period of the certificate, in seconds since the epoch.
+	NotBefore int64 `protobuf:"varint,8,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	NotAfter  int64 `protobuf:"varint,9,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	// The fingerprint returned along with the certificate.
+	Fingerprint          string   `protobuf:"bytes,10,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IssuanceRecord) Reset()         { *m = IssuanceRecord{} }
+func (m *IssuanceRecord) String() string { return proto.CompactTextString(m) }
+func (*IssuanceRecord) ProtoMessage()    {}
+func (*IssuanceRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{42}
+}
+func (m *IssuanceRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IssuanceRecord.Unmarshal(m, b)
+}
+func (m *IssuanceRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IssuanceRecord.Marshal(b, m, deterministic)
+}
+func (dst *IssuanceRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IssuanceRecord.Merge(dst, src)
+}
+func (m *IssuanceRecord) XXX_Size() int {
+	return xxx_messageInfo_IssuanceRecord.Size(m)
+}
+func (m *IssuanceRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_IssuanceRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IssuanceRecord proto.InternalMessageInfo
+
+func (m *IssuanceRecord) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *IssuanceRecord) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *IssuanceRecord) GetSerial() string {
+	if m != nil {
+		return m.Serial
+	}
+	return ""
+}
+
+func (m *IssuanceRecord) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *IssuanceRecord) GetKeyId() string {
+	if m != nil {
+		return m.KeyId
+	}
+	return ""
+}
+
+func (m *IssuanceRecord) GetPrincipals() []string {
+	if m != nil {
+		return m.Principals
+	}
+	return nil
+}
+
+func (m *IssuanceRecord) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+func (m *IssuanceRecord) GetNotBefore() int64 {
+	if m != nil {
+		return m.NotBefore
+	}
+	return 0
+}
+
+func (m *IssuanceRecord) GetNotAfter() int64 {
+	if m != nil {
+		return m.NotAfter
+	}
+	return 0
+}
+
+func (m *IssuanceRecord) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+// IssuanceManifestRequest specifies the certificates issued in a session, such as a batch of an issuance
+// pipeline, whose manifest is signed.
+type IssuanceManifestRequest struct {
+	// The label of the session, included in the manifest.
+	Session string `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	// The certificates of the session.
+	Entries              []*IssuanceManifestEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *IssuanceManifestRequest) Reset()         { *m = IssuanceManifestRequest{} }
+func (m *IssuanceManifestRequest) String() string { return proto.CompactTextString(m) }
+func (*IssuanceManifestRequest) ProtoMessage()    {}
+func (*IssuanceManifestRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{43}
+}
+func (m *IssuanceManifestRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IssuanceManifestRequest.Unmarshal(m, b)
+}
+func (m *IssuanceManifestRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IssuanceManifestRequest.Marshal(b, m, deterministic)
+}
+func (dst *IssuanceManifestRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IssuanceManifestRequest.Merge(dst, src)
+}
+func (m *IssuanceManifestRequest) XXX_Size() int {
+	return xxx_messageInfo_IssuanceManifestRequest.Size(m)
+}
+func (m *IssuanceManifestRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_IssuanceManifestRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IssuanceManifestRequest proto.InternalMessageInfo
+
+func (m *IssuanceManifestRequest) GetSession() string {
+	if m != nil {
+		return m.Session
+	}
+	return ""
+}
+
+func (m *IssuanceManifestRequest) GetEntries() []*IssuanceManifestEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+// IssuanceManifestEntry identifies a certificate issued by crypki.
+type IssuanceManifestEntry struct {
+	// The identifier of the key that signed the certificate.
+	Identifier string `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	// The decimal serial number of the certificate. If empty, the certificate is identified by its fingerprint.
+	Serial string `protobuf:"bytes,2,opt,name=serial,proto3" json:"serial,omitempty"`
+	// The fingerprint returned along with the certificate. If empty, the certificate is identified by its serial.
+	Fingerprint          string   `protobuf:"bytes,3,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IssuanceManifestEntry) Reset()         { *m = IssuanceManifestEntry{} }
+func (m *IssuanceManifestEntry) String() string { return proto.CompactTextString(m) }
+func (*IssuanceManifestEntry) ProtoMessage()    {}
+func (*IssuanceManifestEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{44}
+}
+func (m *IssuanceManifestEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IssuanceManifestEntry.Unmarshal(m, b)
+}
+func (m *IssuanceManifestEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IssuanceManifestEntry.Marshal(b, m, deterministic)
+}
+func (dst *IssuanceManifestEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IssuanceManifestEntry.Merge(dst, src)
+}
+func (m *IssuanceManifestEntry) XXX_Size() int {
+	return xxx_messageInfo_IssuanceManifestEntry.Size(m)
+}
+func (m *IssuanceManifestEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_IssuanceManifestEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IssuanceManifestEntry proto.InternalMessageInfo
+
+func (m *IssuanceManifestEntry) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *IssuanceManifestEntry) GetSerial() string {
+	if m != nil {
+		return m.Serial
+	}
+	return ""
+}
+
+func (m *IssuanceManifestEntry) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
+// IssuanceRecords contains the recently issued certificates, most recent first.
+type IssuanceRecords struct {
+	Records              []*IssuanceRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *IssuanceRecords) Reset()         { *m = IssuanceRecords{} }
+func (m *IssuanceRecords) String() string { return proto.CompactTextString(m) }
+func (*IssuanceRecords) ProtoMessage()    {}
+func (*IssuanceRecords) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{45}
+}
+func (m *IssuanceRecords) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IssuanceRecords.Unmarshal(m, b)
+}
+func (m *IssuanceRecords) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IssuanceRecords.Marshal(b, m, deterministic)
+}
+func (dst *IssuanceRecords) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IssuanceRecords.Merge(dst, src)
+}
+func (m *IssuanceRecords) XXX_Size() int {
+	return xxx_messageInfo_IssuanceRecords.Size(m)
+}
+func (m *IssuanceRecords) XXX_DiscardUnknown() {
+	xxx_messageInfo_IssuanceRecords.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IssuanceRecords proto.InternalMessageInfo
+
+func (m *IssuanceRecords) GetRecords() []*IssuanceRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+// KeyUsageStats contains the usage counters of a signing key since the server started.
+type KeyUsageStats struct {
+	// The identifier of the key.
+	Identifier string `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	// The number of sign operations of the key, including the failed ones.
+	Signs uint64 `protobuf:"varint,2,opt,name=signs,proto3" json:"signs,omitempty"`
+	// The number of failed sign operations of the key.
+	Errors uint64 `protobuf:"varint,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	// The Unix time in seconds of the last sign operation of the key, zero if it has not been used.
+	LastUsed int64 `protobuf:"varint,4,opt,name=last_used,json=lastUsed,proto3" json:"last_used,omitempty"`
+	// The number of HSM sessions of the key in use, zero if unknown.
+	PoolInUse int32 `protobuf:"varint,5,opt,name=pool_in_use,json=poolInUse,proto3" json:"pool_in_use,omitempty"`
+	// The number of HSM sessions of the key, zero if unknown.
+	PoolSize             int32    `protobuf:"varint,6,opt,name=pool_size,json=poolSize,proto3" json:"pool_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyUsageStats) Reset()         { *m = KeyUsageStats{} }
+func (m *KeyUsageStats) String() string { return proto.CompactTextString(m) }
+func (*KeyUsageStats) ProtoMessage()    {}
+func (*KeyUsageStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{46}
+}
+func (m *KeyUsageStats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyUsageStats.Unmarshal(m, b)
+}
+func (m *KeyUsageStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyUsageStats.Marshal(b, m, deterministic)
+}
+func (dst *KeyUsageStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyUsageStats.Merge(dst, src)
+}
+func (m *KeyUsageStats) XXX_Size() int {
+	return xxx_messageInfo_KeyUsageStats.Size(m)
+}
+func (m *KeyUsageStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyUsageStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyUsageStats proto.InternalMessageInfo
+
+func (m *KeyUsageStats) GetIdentifier() string {
+	if m != nil {
+		return m.Identifier
+	}
+	return ""
+}
+
+func (m *KeyUsageStats) GetSigns() uint64 {
+	if m != nil {
+		return m.Signs
+	}
+	return 0
+}
+
+func (m *KeyUsageStats) GetErrors() uint64 {
+	if m != nil {
+		return m.Errors
+	}
+	return 0
+}
+
+func (m *KeyUsageStats) GetLastUsed() int64 {
+	if m != nil {
+		return m.LastUsed
+	}
+	return 0
+}
+
+func (m *KeyUsageStats) GetPoolInUse() int32 {
+	if m != nil {
+		return m.PoolInUse
+	}
+	return 0
+}
+
+func (m *KeyUsageStats) GetPoolSize() int32 {
+	if m != nil {
+		return m.PoolSize
+	}
+	return 0
+}
+
+// KeyUsageStatsList contains the usage counters of all signing keys, sorted by identifier.
+type KeyUsageStatsList struct {
+	Keys                 []*KeyUsageStats `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *KeyUsageStatsList) Reset()         { *m = KeyUsageStatsList{} }
+func (m *KeyUsageStatsList) String() string { return proto.CompactTextString(m) }
+func (*KeyUsageStatsList) ProtoMessage()    {}
+func (*KeyUsageStatsList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{47}
+}
+func (m *KeyUsageStatsList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyUsageStatsList.Unmarshal(m, b)
+}
+func (m *KeyUsageStatsList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyUsageStatsList.Marshal(b, m, deterministic)
+}
+func (dst *KeyUsageStatsList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyUsageStatsList.Merge(dst, src)
+}
+func (m *KeyUsageStatsList) XXX_Size() int {
+	return xxx_messageInfo_KeyUsageStatsList.Size(m)
+}
+func (m *KeyUsageStatsList) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyUsageStatsList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyUsageStatsList proto.InternalMessageInfo
+
+func (m *KeyUsageStatsList) GetKeys() []*KeyUsageStats {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+// KeyGenerationRequest specifies the key pair to generate in the HSM.
+type KeyGenerationRequest struct {
+	// Identifies the new key in crypki. It must not be used by any existing key.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The slot number in the HSM to generate the key pair in. The slot must hold at least one configured key.
+	SlotNumber uint32 `protobuf:"varint,2,opt,name=slot_number,json=slotNumber,proto3" json:"slot_number,omitempty"`
+	// The label of the key pair on the slot. It must not be used by any existing object on the slot.
+	KeyLabel string `protobuf:"bytes,3,opt,name=key_label,json=keyLabel,proto3" json:"key_label,omitempty"`
+	// The type of the key pair, RSA or ECDSA. The other types are rejected with InvalidArgument.
+	KeyType KeyType `protobuf:"varint,4,opt,name=key_type,json=keyType,proto3,enum=v3.KeyType" json:"key_type,omitempty"`
+	// The modulus size in bits of an RSA key pair, or the size in bits of the curve of an ECDSA key pair:
+	// 256, 384 or 521.
+	KeySize uint32 `protobuf:"varint,5,opt,name=key_size,json=keySize,proto3" json:"key_size,omitempty"`
+	// The name of the PKCS#11 module of the HSM to generate the key pair in. If empty, the default module is used.
+	Module               string   `protobuf:"bytes,6,opt,name=module,proto3" json:"module,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyGenerationRequest) Reset()         { *m = KeyGenerationRequest{} }
+func (m *KeyGenerationRequest) String() string { return proto.CompactTextString(m) }
+func (*KeyGenerationRequest) ProtoMessage()    {}
+func (*KeyGenerationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{48}
+}
+func (m *KeyGenerationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyGenerationRequest.Unmarshal(m, b)
+}
+func (m *KeyGenerationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyGenerationRequest.Marshal(b, m, deterministic)
+}
+func (dst *KeyGenerationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyGenerationRequest.Merge(dst, src)
+}
+func (m *KeyGenerationRequest) XXX_Size() int {
+	return xxx_messageInfo_KeyGenerationRequest.Size(m)
+}
+func (m *KeyGenerationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyGenerationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyGenerationRequest proto.InternalMessageInfo
+
+func (m *KeyGenerationRequest) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *KeyGenerationRequest) GetSlotNumber() uint32 {
+	if m != nil {
+		return m.SlotNumber
+	}
+	return 0
+}
+
+func (m *KeyGenerationRequest) GetKeyLabel() string {
+	if m != nil {
+		return m.KeyLabel
+	}
+	return ""
+}
+
+func (m *KeyGenerationRequest) GetKeyType() KeyType {
+	if m != nil {
+		return m.KeyType
+	}
+	return KeyType_Unspecified_KeyType
+}
+
+func (m *KeyGenerationRequest) GetKeySize() uint32 {
+	if m != nil {
+		return m.KeySize
+	}
+	return 0
+}
+
+func (m *KeyGenerationRequest) GetModule() string {
+	if m != nil {
+		return m.Module
+	}
+	return ""
+}
+
+// GeneratedKey contains the info of a key pair generated in the HSM.
+type GeneratedKey struct {
+	// Identifies the new key in crypki.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The public key encoded in PEM format.
+	PublicKey            string   `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GeneratedKey) Reset()         { *m = GeneratedKey{} }
+func (m *GeneratedKey) String() string { return proto.CompactTextString(m) }
+func (*GeneratedKey) ProtoMessage()    {}
+func (*GeneratedKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{49}
+}
+func (m *GeneratedKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GeneratedKey.Unmarshal(m, b)
+}
+func (m *GeneratedKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GeneratedKey.Marshal(b, m, deterministic)
+}
+func (dst *GeneratedKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GeneratedKey.Merge(dst, src)
+}
+func (m *GeneratedKey) XXX_Size() int {
+	return xxx_messageInfo_GeneratedKey.Size(m)
+}
+func (m *GeneratedKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_GeneratedKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GeneratedKey proto.InternalMessageInfo
+
+func (m *GeneratedKey) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *GeneratedKey) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+// ReloadedSessions contains the result of reloading the sessions of a signing key.
+type ReloadedSessions struct {
+	// Identifies the key whose sessions were reloaded.
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The number of sessions freshly opened.
+	Sessions             uint32   `protobuf:"varint,2,opt,name=sessions,proto3" json:"sessions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReloadedSessions) Reset()         { *m = ReloadedSessions{} }
+func (m *ReloadedSessions) String() string { return proto.CompactTextString(m) }
+func (*ReloadedSessions) ProtoMessage()    {}
+func (*ReloadedSessions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{50}
+}
+func (m *ReloadedSessions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReloadedSessions.Unmarshal(m, b)
+}
+func (m *ReloadedSessions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReloadedSessions.Marshal(b, m, deterministic)
+}
+func (dst *ReloadedSessions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReloadedSessions.Merge(dst, src)
+}
+func (m *ReloadedSessions) XXX_Size() int {
+	return xxx_messageInfo_ReloadedSessions.Size(m)
+}
+func (m *ReloadedSessions) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReloadedSessions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReloadedSessions proto.InternalMessageInfo
+
+func (m *ReloadedSessions) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *ReloadedSessions) GetSessions() uint32 {
+	if m != nil {
+		return m.Sessions
+	}
+	return 0
+}
+
+// HSMMechanism describes a PKCS#11 mechanism supported by a slot of an HSM.
+type HSMMechanism struct {
+	// The name of the mechanism, such as CKM_RSA_PKCS, or its value in hexadecimal if crypki does not know it.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The value of the mechanism.
+	Value uint32 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	// The range of key sizes supported by the mechanism, in bits or bytes depending on the mechanism.
+	MinKeySize uint32 `protobuf:"varint,3,opt,name=min_key_size,json=minKeySize,proto3" json:"min_key_size,omitempty"`
+	MaxKeySize uint32 `protobuf:"varint,4,opt,name=max_key_size,json=maxKeySize,proto3" json:"max_key_size,omitempty"`
+	// The functions the mechanism supports, such as sign or generate_key_pair.
+	Functions            []string `protobuf:"bytes,5,rep,name=functions,proto3" json:"functions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HSMMechanism) Reset()         { *m = HSMMechanism{} }
+func (m *HSMMechanism) String() string { return proto.CompactTextString(m) }
+func (*HSMMechanism) ProtoMessage()    {}
+func (*HSMMechanism) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{51}
+}
+func (m *HSMMechanism) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HSMMechanism.Unmarshal(m, b)
+}
+func (m *HSMMechanism) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HSMMechanism.Marshal(b, m, deterministic)
+}
+func (dst *HSMMechanism) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HSMMechanism.Merge(dst, src)
+}
+func (m *HSMMechanism) XXX_Size() int {
+	return xxx_messageInfo_HSMMechanism.Size(m)
+}
+func (m *HSMMechanism) XXX_DiscardUnknown() {
+	xxx_messageInfo_HSMMechanism.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HSMMechanism proto.InternalMessageInfo
+
+func (m *HSMMechanism) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *HSMMechanism) GetValue() uint32 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *HSMMechanism) GetMinKeySize() uint32 {
+	if m != nil {
+		return m.MinKeySize
+	}
+	return 0
+}
+
+func (m *HSMMechanism) GetMaxKeySize() uint32 {
+	if m != nil {
+		return m.MaxKeySize
+	}
+	return 0
+}
+
+func (m *HSMMechanism) GetFunctions() []string {
+	if m != nil {
+		return m.Functions
+	}
+	return nil
+}
+
+// SlotMechanisms contains the mechanisms supported by a slot of an HSM.
+type SlotMechanisms struct {
+	// The name of the PKCS#11 module of the HSM, empty for the default module.
+	Module string `protobuf:"bytes,1,opt,name=module,proto3" json:"module,omitempty"`
+	// The slot number in the HSM.
+	SlotNumber uint32 `protobuf:"varint,2,opt,name=slot_number,json=slotNumber,proto3" json:"slot_number,omitempty"`
+	// The mechanisms supported by the slot, sorted by value.
+	Mechanisms []*HSMMechanism `protobuf:"bytes,3,rep,name=mechanisms,proto3" json:"mechanisms,omitempty"`
+	// The error listing the mechanisms of the slot, if any.
+	Error                string   `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SlotMechanisms) Reset()         { *m = SlotMechanisms{} }
+func (m *SlotMechanisms) String() string { return proto.CompactTextString(m) }
+func (*SlotMechanisms) ProtoMessage()    {}
+func (*SlotMechanisms) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{52}
+}
+func (m *SlotMechanisms) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SlotMechanisms.Unmarshal(m, b)
+}
+func (m *SlotMechanisms) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SlotMechanisms.Marshal(b, m, deterministic)
+}
+func (dst *SlotMechanisms) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SlotMechanisms.Merge(dst, src)
+}
+func (m *SlotMechanisms) XXX_Size() int {
+	return xxx_messageInfo_SlotMechanisms.Size(m)
+}
+func (m *SlotMechanisms) XXX_DiscardUnknown() {
+	xxx_messageInfo_SlotMechanisms.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SlotMechanisms proto.InternalMessageInfo
+
+func (m *SlotMechanisms) GetModule() string {
+	if m != nil {
+		return m.Module
+	}
+	return ""
+}
+
+func (m *SlotMechanisms) GetSlotNumber() uint32 {
+	if m != nil {
+		return m.SlotNumber
+	}
+	return 0
+}
+
+func (m *SlotMechanisms) GetMechanisms() []*HSMMechanism {
+	if m != nil {
+		return m.Mechanisms
+	}
+	return nil
+}
+
+func (m *SlotMechanisms) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// SlotMechanismsList contains the mechanisms supported by the slots holding the signing keys,
+// sorted by module and slot number.
+type SlotMechanismsList struct {
+	Slots                []*SlotMechanisms `protobuf:"bytes,1,rep,name=slots,proto3" json:"slots,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SlotMechanismsList) Reset()         { *m = SlotMechanismsList{} }
+func (m *SlotMechanismsList) String() string { return proto.CompactTextString(m) }
+func (*SlotMechanismsList) ProtoMessage()    {}
+func (*SlotMechanismsList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{53}
+}
+func (m *SlotMechanismsList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SlotMechanismsList.Unmarshal(m, b)
+}
+func (m *SlotMechanismsList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SlotMechanismsList.Marshal(b, m, deterministic)
+}
+func (dst *SlotMechanismsList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SlotMechanismsList.Merge(dst, src)
+}
+func (m *SlotMechanismsList) XXX_Size() int {
+	return xxx_messageInfo_SlotMechanismsList.Size(m)
+}
+func (m *SlotMechanismsList) XXX_DiscardUnknown() {
+	xxx_messageInfo_SlotMechanismsList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SlotMechanismsList proto.InternalMessageInfo
+
+func (m *SlotMechanismsList) GetSlots() []*SlotMechanisms {
+	if m != nil {
+		return m.Slots
+	}
+	return nil
+}
+
+// EffectiveConfig contains the configuration the server is running with, after the defaults were
+// applied. The pin paths and the TLS server key path are redacted.
+type EffectiveConfig struct {
+	Config               *_struct.Struct `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *EffectiveConfig) Reset()         { *m = EffectiveConfig{} }
+func (m *EffectiveConfig) String() string { return proto.CompactTextString(m) }
+func (*EffectiveConfig) ProtoMessage()    {}
+func (*EffectiveConfig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{54}
+}
+func (m *EffectiveConfig) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EffectiveConfig.Unmarshal(m, b)
+}
+func (m *EffectiveConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EffectiveConfig.Marshal(b, m, deterministic)
+}
+func (dst *EffectiveConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EffectiveConfig.Merge(dst, src)
+}
+func (m *EffectiveConfig) XXX_Size() int {
+	return xxx_messageInfo_EffectiveConfig.Size(m)
+}
+func (m *EffectiveConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_EffectiveConfig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EffectiveConfig proto.InternalMessageInfo
+
+func (m *EffectiveConfig) GetConfig() *_struct.Struct {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+// KeyCapabilities describes the capabilities of a signing key.
+type KeyCapabilities struct {
+	KeyMeta *KeyMeta `protobuf:"bytes,1,opt,name=key_meta,json=keyMeta,proto3" json:"key_meta,omitempty"`
+	// The type of the key.
+	KeyType KeyType `protobuf:"varint,2,opt,name=key_type,json=keyType,proto3,enum=v3.KeyType" json:"key_type,omitempty"`
+	// Size in bits of the RSA modulus or of the ECDSA curve, zero if unknown or for post-quantum keys.
+	KeySize int32 `protobuf:"varint,3,opt,name=key_size,json=keySize,proto3" json:"key_size,omitempty"`
+	// Hash algorithms of the digests accepted for blob signing.
+	HashAlgorithms []HashAlgo `protobuf:"varint,4,rep,packed,name=hash_algorithms,json=hashAlgorithms,proto3,enum=v3.HashAlgo" json:"hash_algorithms,omitempty"`
+	// Signature schemes accepted for blob signing.
+	SignatureSchemes []SignatureScheme `protobuf:"varint,5,rep,packed,name=signature_schemes,json=signatureSchemes,proto3,enum=v3.SignatureScheme" json:"signature_schemes,omitempty"`
+	// Encodings of the outputs of the signing requests served by the key,
+	// e.g. "PEM" for x509 certificates, "OpenSSH" for SSH certificates and "base64" for blob signatures.
+	OutputEncodings []string `protobuf:"bytes,6,rep,name=output_encodings,json=outputEncodings,proto3" json:"output_encodings,omitempty"`
+	// Whether the key signs SSH user certificates.
+	SshUserCa bool `protobuf:"varint,7,opt,name=ssh_user_ca,json=sshUserCa,proto3" json:"ssh_user_ca,omitempty"`
+	// Whether the key signs SSH host certificates.
+	SshHostCa bool `protobuf:"varint,8,opt,name=ssh_host_ca,json=sshHostCa,proto3" json:"ssh_host_ca,omitempty"`
+	// Whether the key signs x509 certificates.
+	X509Ca bool `protobuf:"varint,9,opt,name=x509_ca,json=x509Ca,proto3" json:"x509_ca,omitempty"`
+	// Whether the key signs blobs.
+	BlobSigning bool `protobuf:"varint,10,opt,name=blob_signing,json=blobSigning,proto3" json:"blob_signing,omitempty"`
+	// The parameter set of a post-quantum key, such as "ML-DSA-65", empty if unknown.
+	ParameterSet         string   `protobuf:"bytes,11,opt,name=parameter_set,json=parameterSet,proto3" json:"parameter_set,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyCapabilities) Reset()         { *m = KeyCapabilities{} }
+func (m *KeyCapabilities) String() string { return proto.CompactTextString(m) }
+func (*KeyCapabilities) ProtoMessage()    {}
+func (*KeyCapabilities) Descriptor() ([]byte, []int) {
+	return fileDescriptor_sign_fa781ddcbfcb2f53, []int{55}
+}
+func (m *KeyCapabilities) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyCapabilities.Unmarshal(m, b)
+}
+func (m *KeyCapabilities) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyCapabilities.Marshal(b, m, deterministic)
+}
+func (dst *KeyCapabilities) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyCapabilities.Merge(dst, src)
+}
+func (m *KeyCapabilities) XXX_Size() int {
+	return xxx_messageInfo_KeyCapabilities.Size(m)
+}
+func (m *KeyCapabilities) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyCapabilities.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyCapabilities proto.InternalMessageInfo
+
+func (m *KeyCapabilities) GetKeyMeta() *KeyMeta {
+	if m != nil {
+		return m.KeyMeta
+	}
+	return nil
+}
+
+func (m *KeyCapabilities) GetKeyType() KeyType {
+	if m != nil {
+		return m.KeyType
+	}
+	return KeyType_Unspecified_KeyType
+}
+
+func (m *KeyCapabilities) GetKeySize() int32 {
+	if m != nil {
+		return m.KeySize
+	}
+	return 0
+}
+
+func (m *KeyCapabilities) GetHashAlgorithms() []HashAlgo {
+	if m != nil {
+		return m.HashAlgorithms
+	}
+	return nil
+}
+
+func (m *KeyCapabilities) GetSignatureSchemes() []SignatureScheme {
+	if m != nil {
+		return m.SignatureSchemes
+	}
+	return nil
+}
+
+func (m *KeyCapabilities) GetOutputEncodings() []string {
+	if m != nil {
+		return m.OutputEncodings
+	}
+	return nil
+}
+
+func (m *KeyCapabilities) GetSshUserCa() bool {
+	if m != nil {
+		return m.SshUserCa
+	}
+	return false
+}
+
+func (m *KeyCapabilities) GetSshHostCa() bool {
+	if m != nil {
+		return m.SshHostCa
+	}
+	return false
+}
+
+func (m *KeyCapabilities) GetX509Ca() bool {
+	if m != nil {
+		return m.X509Ca
+	}
+	return false
+}
+
+func (m *KeyCapabilities) GetBlobSigning() bool {
+	if m != nil {
+		return m.BlobSigning
+	}
+	return false
+}
+
+func (m *KeyCapabilities) GetParameterSet() string {
+	if m != nil {
+		return m.ParameterSet
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*KeyMeta)(nil), "v3.KeyMeta")
+	proto.RegisterType((*KeyMetas)(nil), "v3.KeyMetas")
+	proto.RegisterType((*KeyFilter)(nil), "v3.KeyFilter")
+	proto.RegisterType((*SSHCertificateSigningRequest)(nil), "v3.SSHCertificateSigningRequest")
+	proto.RegisterMapType((map[string]string)(nil), "v3.SSHCertificateSigningRequest.CriticalOptionsEntry")
+	proto.RegisterMapType((map[string]string)(nil), "v3.SSHCertificateSigningRequest.ExtensionsEntry")
+	proto.RegisterType((*SSHKey)(nil), "v3.SSHKey")
+	proto.RegisterType((*IssuanceReceipt)(nil), "v3.IssuanceReceipt")
+	proto.RegisterType((*X509CertificateSigningRequest)(nil), "v3.X509CertificateSigningRequest")
+	proto.RegisterType((*X509Certificate)(nil), "v3.X509Certificate")
+	proto.RegisterType((*X509CACertificatePKCS12Request)(nil), "v3.X509CACertificatePKCS12Request")
+	proto.RegisterType((*PKCS12)(nil), "v3.PKCS12")
+	proto.RegisterType((*X509CertificatePreview)(nil), "v3.X509CertificatePreview")
+	proto.RegisterType((*TimestampRequest)(nil), "v3.TimestampRequest")
+	proto.RegisterType((*TimestampResponse)(nil), "v3.TimestampResponse")
+	proto.RegisterType((*GitSigningRequest)(nil), "v3.GitSigningRequest")
+	proto.RegisterType((*GitSignature)(nil), "v3.GitSignature")
+	proto.RegisterType((*DNSSECSigningRequest)(nil), "v3.DNSSECSigningRequest")
+	proto.RegisterType((*DNSSECSignature)(nil), "v3.DNSSECSignature")
+	proto.RegisterType((*AttestationSigningRequest)(nil), "v3.AttestationSigningRequest")
+	proto.RegisterType((*AttestationStatement)(nil), "v3.AttestationStatement")
+	proto.RegisterType((*ContainerImageSigningRequest)(nil), "v3.ContainerImageSigningRequest")
+	proto.RegisterType((*ContainerImageSignature)(nil), "v3.ContainerImageSignature")
+	proto.RegisterType((*PublicKey)(nil), "v3.PublicKey")
+	proto.RegisterType((*BlobSigningRequest)(nil), "v3.BlobSigningRequest")
+	proto.RegisterType((*BlobBatchEntry)(nil), "v3.BlobBatchEntry")
+	proto.RegisterType((*BlobBatchSigningRequest)(nil), "v3.BlobBatchSigningRequest")
+	proto.RegisterType((*BlobBatchSignatures)(nil), "v3.BlobBatchSignatures")
+	proto.RegisterType((*BlobBatchEntrySignature)(nil), "v3.BlobBatchEntrySignature")
+	proto.RegisterType((*SSHCertificateVerificationRequest)(nil), "v3.SSHCertificateVerificationRequest")
+	proto.RegisterType((*SSHCertificateVerification)(nil), "v3.SSHCertificateVerification")
+	proto.RegisterType((*CMSSigningRequest)(nil), "v3.CMSSigningRequest")
+	proto.RegisterType((*CMSSignature)(nil), "v3.CMSSignature")
+	proto.RegisterType((*EphemeralSigningRequest)(nil), "v3.EphemeralSigningRequest")
+	proto.RegisterType((*EphemeralSignature)(nil), "v3.EphemeralSignature")
+	proto.RegisterType((*TenantSigningRequest)(nil), "v3.TenantSigningRequest")
+	proto.RegisterType((*TenantSignature)(nil), "v3.TenantSignature")
+	proto.RegisterType((*BlobStreamRequest)(nil), "v3.BlobStreamRequest")
+	proto.RegisterType((*BlobStreamSignature)(nil), "v3.BlobStreamSignature")
+	proto.RegisterType((*Signature)(nil), "v3.Signature")
+	proto.RegisterType((*KeySignature)(nil), "v3.KeySignature")
+	proto.RegisterType((*EndpointStatus)(nil), "v3.EndpointStatus")
+	proto.RegisterType((*CircuitBreakerStatus)(nil), "v3.CircuitBreakerStatus")
+	proto.RegisterType((*ServerInfo)(nil), "v3.ServerInfo")
+	proto.RegisterType((*IssuanceRecord)(nil), "v3.IssuanceRecord")
+	proto.RegisterType((*IssuanceManifestRequest)(nil), "v3.IssuanceManifestRequest")
+	proto.RegisterType((*IssuanceManifestEntry)(nil), "v3.IssuanceManifestEntry")
+	proto.RegisterType((*IssuanceRecords)(nil), "v3.IssuanceRecords")
+	proto.RegisterType((*KeyUsageStats)(nil), "v3.KeyUsageStats")
+	proto.RegisterType((*KeyUsageStatsList)(nil), "v3.KeyUsageStatsList")
+	proto.RegisterType((*KeyGenerationRequest)(nil), "v3.KeyGenerationRequest")
+	proto.RegisterType((*GeneratedKey)(nil), "v3.GeneratedKey")
+	proto.RegisterType((*ReloadedSessions)(nil), "v3.ReloadedSessions")
+	proto.RegisterType((*HSMMechanism)(nil), "v3.HSMMechanism")
+	proto.RegisterType((*SlotMechanisms)(nil), "v3.SlotMechanisms")
+	proto.RegisterType((*SlotMechanismsList)(nil), "v3.SlotMechanismsList")
+	proto.RegisterType((*EffectiveConfig)(nil), "v3.EffectiveConfig")
+	proto.RegisterType((*KeyCapabilities)(nil), "v3.KeyCapabilities")
+	proto.RegisterEnum("v3.KeyHealth", KeyHealth_name, KeyHealth_value)
+	proto.RegisterEnum("v3.PublicKeyFormat", PublicKeyFormat_name, PublicKeyFormat_value)
+	proto.RegisterEnum("v3.Priority", Priority_name, Priority_value)
+	proto.RegisterEnum("v3.HashAlgo", HashAlgo_name, HashAlgo_value)
+	proto.RegisterEnum("v3.SignatureScheme", SignatureScheme_name, SignatureScheme_value)
+	proto.RegisterEnum("v3.DigestEncoding", DigestEncoding_name, DigestEncoding_value)
+	proto.RegisterEnum("v3.PartialAvailability", PartialAvailability_name, PartialAvailability_value)
+	proto.RegisterEnum("v3.KeyType", KeyType_name, KeyType_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
 const _ = grpc.SupportPackageIsVersion4
 
-// SigningClient is the client API for Signing service.
+// SigningClient is the client API for Signing service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SigningClient interface {
+	// GetX509CertificateAvailableSigningKeys returns all available keys that can sign X509 certificates.
+	GetX509CertificateAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error)
+	// GetX509CACertificate returns the CA X509 certificate self-signed by the specified key.
+	GetX509CACertificate(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*X509Certificate, error)
+	// ExportX509CACertificatePKCS12 returns the CA X509 certificate chain of the specified key as a password
+	// protected PKCS#12 file holding no private key, for import into the trust stores of Java clients.
+	ExportX509CACertificatePKCS12(ctx context.Context, in *X509CACertificatePKCS12Request, opts ...grpc.CallOption) (*PKCS12, error)
+	// PostX509Certificate signs the given CSR using the specified key and returns a PEM encoded X509 certificate.
+	PostX509Certificate(ctx context.Context, in *X509CertificateSigningRequest, opts ...grpc.CallOption) (*X509Certificate, error)
+	// PreviewX509Certificate returns the certificate that PostX509Certificate would issue for the given CSR,
+	// with all the profiles, policies and defaults of the specified key applied, without signing it.
+	PreviewX509Certificate(ctx context.Context, in *X509CertificateSigningRequest, opts ...grpc.CallOption) (*X509CertificatePreview, error)
+	// GetUserSSHCertificateAvailableSigningKeys returns all available keys that can sign user SSH certificates.
+	GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error)
+	// GetUserSSHCertificateSigningKey returns the public signing key of the
+	// specified key that signs the user ssh certificate.
+	GetUserSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error)
+	// PostUserSSHCertificate signs the SSH user certificate given request fields using the specified key.
+	PostUserSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error)
+	// GetHostSSHCertificateAvailableSigningKeys returns all available keys that can sign host SSH certificates.
+	GetHostSSHCertificateAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error)
+	// GetHostSSHCertificateSigningKey returns the public signing key of the
+	// specified key that signs the host ssh certificate.
+	GetHostSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error)
+	// PostHostSSHCertificate signs the SSH host certificate given request fields using the specified key.
+	PostHostSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error)
+	// VerifySSHCertificate checks that an SSH certificate is signed by the specified CA key and is
+	// currently within its validity period. It is read-only and does not use the private key.
+	VerifySSHCertificate(ctx context.Context, in *SSHCertificateVerificationRequest, opts ...grpc.CallOption) (*SSHCertificateVerification, error)
+	// GetBlobAvailableSigningKeys returns all available keys that can sign
+	GetBlobAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error)
+	// GetBlobSigningKey returns the public signing key of the
+	// specified key that signs the user's data.
+	GetBlobSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*PublicKey, error)
+	// PostSignBlob signs the digest using the specified key.
+	PostSignBlob(ctx context.Context, in *BlobSigningRequest, opts ...grpc.CallOption) (*Signature, error)
+	// PostSignBlobBatch signs the digests of the entries using the specified key, each with the signer
+	// options of its hash algorithm. The request fails if any of the entries cannot be signed.
+	PostSignBlobBatch(ctx context.Context, in *BlobBatchSigningRequest, opts ...grpc.CallOption) (*BlobBatchSignatures, error)
+	// PostSignBlobBatchStream signs the digests of the entries as PostSignBlobBatch does, for batches too large
+	// for a single response: the result of each entry is streamed, in the order of the entries, as soon as the
+	// entry is signed, and an entry that cannot be signed does not fail the others.
+	PostSignBlobBatchStream(ctx context.Context, in *BlobBatchSigningRequest, opts ...grpc.CallOption) (Signing_PostSignBlobBatchStreamClient, error)
+	// PostSignBlobCMS returns a detached CMS signature of the content of the digest using the specified key.
+	// The signature covers the content-type, message-digest and signing-time signed attributes, the latter
+	// being the server time, and the SignedData includes the X509 CA certificate of the key.
+	PostSignBlobCMS(ctx context.Context, in *CMSSigningRequest, opts ...grpc.CallOption) (*CMSSignature, error)
+	// PostEphemeralSignature signs the digest with a one-time key generated in the HSM as specified by
+	// the configuration of key_meta, and returns the signature along with the public key. The private
+	// key is destroyed once the digest is signed.
+	PostEphemeralSignature(ctx context.Context, in *EphemeralSigningRequest, opts ...grpc.CallOption) (*EphemeralSignature, error)
+	// PostTenantSignature would sign the digest with a subkey of the tenant derived from key_meta. It is not
+	// supported and fails with Unimplemented, as the subkeys could not be derived and held in the HSM.
+	PostTenantSignature(ctx context.Context, in *TenantSigningRequest, opts ...grpc.CallOption) (*TenantSignature, error)
+	// PostSignBlobStream hashes the streamed blob with the requested hash algorithm and signs its digest
+	// using the specified key, as PostSignBlob does. It is only served over gRPC.
+	PostSignBlobStream(ctx context.Context, opts ...grpc.CallOption) (Signing_PostSignBlobStreamClient, error)
+	// PostTimestamp returns the RFC 3161 time-stamp response to the time-stamp request,
+	// with the time-stamp token signed by the specified TSA key.
+	PostTimestamp(ctx context.Context, in *TimestampRequest, opts ...grpc.CallOption) (*TimestampResponse, error)
+	// PostSignGitObject returns the SSHSIG signature of the git commit or tag object,
+	// as produced by "ssh-keygen -Y sign -n git", signed by the specified SSH key.
+	PostSignGitObject(ctx context.Context, in *GitSigningRequest, opts ...grpc.CallOption) (*GitSignature, error)
+	// PostSignDNSSEC returns the RRSIG signature of the RRset signing data, signed by the specified
+	// RSA or ECDSA zone-signing key, with the DNSSEC algorithm number of the signature.
+	PostSignDNSSEC(ctx context.Context, in *DNSSECSigningRequest, opts ...grpc.CallOption) (*DNSSECSignature, error)
+	// PostSignAttestation returns the packed attestation statement of the WebAuthn attestation,
+	// signed by the specified attestation key, along with its attestation certificate.
+	PostSignAttestation(ctx context.Context, in *AttestationSigningRequest, opts ...grpc.CallOption) (*AttestationStatement, error)
+	// PostSignContainerImage returns the cosign signature of the container image manifest,
+	// signed by the specified ECDSA key, along with the signed payload and the public key.
+	PostSignContainerImage(ctx context.Context, in *ContainerImageSigningRequest, opts ...grpc.CallOption) (*ContainerImageSignature, error)
+	// PostIssuanceManifest returns the receipt of the certificates of a session, whose payload is the manifest
+	// of the session: the type, serial number, subject and fingerprint of each certificate. The certificates
+	// are looked up by serial number or fingerprint in the issuance store, which only holds the certificates
+	// of the other crypki replicas if its directory is on a filesystem shared by them.
+	PostIssuanceManifest(ctx context.Context, in *IssuanceManifestRequest, opts ...grpc.CallOption) (*IssuanceReceipt, error)
+	// GetKeyCapabilities returns the capabilities of the specified key.
+	GetKeyCapabilities(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*KeyCapabilities, error)
+}
+
+type signingClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSigningClient(cc *grpc.ClientConn) SigningClient {
+	return &signingClient{cc}
+}
+
+func (c *signingClient) GetX509CertificateAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error) {
+	out := new(KeyMetas)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetX509CertificateAvailableSigningKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetX509CACertificate(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*X509Certificate, error) {
+	out := new(X509Certificate)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetX509CACertificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) ExportX509CACertificatePKCS12(ctx context.Context, in *X509CACertificatePKCS12Request, opts ...grpc.CallOption) (*PKCS12, error) {
+	out := new(PKCS12)
+	err := c.cc.Invoke(ctx, "/v3.Signing/ExportX509CACertificatePKCS12", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostX509Certificate(ctx context.Context, in *X509CertificateSigningRequest, opts ...grpc.CallOption) (*X509Certificate, error) {
+	out := new(X509Certificate)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostX509Certificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PreviewX509Certificate(ctx context.Context, in *X509CertificateSigningRequest, opts ...grpc.CallOption) (*X509CertificatePreview, error) {
+	out := new(X509CertificatePreview)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PreviewX509Certificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error) {
+	out := new(KeyMetas)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetUserSSHCertificateAvailableSigningKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetUserSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error) {
+	out := new(SSHKey)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetUserSSHCertificateSigningKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostUserSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error) {
+	out := new(SSHKey)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostUserSSHCertificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetHostSSHCertificateAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error) {
+	out := new(KeyMetas)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetHostSSHCertificateAvailableSigningKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetHostSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error) {
+	out := new(SSHKey)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetHostSSHCertificateSigningKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostHostSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error) {
+	out := new(SSHKey)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostHostSSHCertificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) VerifySSHCertificate(ctx context.Context, in *SSHCertificateVerificationRequest, opts ...grpc.CallOption) (*SSHCertificateVerification, error) {
+	out := new(SSHCertificateVerification)
+	err := c.cc.Invoke(ctx, "/v3.Signing/VerifySSHCertificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetBlobAvailableSigningKeys(ctx context.Context, in *KeyFilter, opts ...grpc.CallOption) (*KeyMetas, error) {
+	out := new(KeyMetas)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetBlobAvailableSigningKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetBlobSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*PublicKey, error) {
+	out := new(PublicKey)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetBlobSigningKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignBlob(ctx context.Context, in *BlobSigningRequest, opts ...grpc.CallOption) (*Signature, error) {
+	out := new(Signature)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignBlob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignBlobBatch(ctx context.Context, in *BlobBatchSigningRequest, opts ...grpc.CallOption) (*BlobBatchSignatures, error) {
+	out := new(BlobBatchSignatures)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignBlobBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignBlobBatchStream(ctx context.Context, in *BlobBatchSigningRequest, opts ...grpc.CallOption) (Signing_PostSignBlobBatchStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Signing_serviceDesc.Streams[0], "/v3.Signing/PostSignBlobBatchStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &signingPostSignBlobBatchStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Signing_PostSignBlobBatchStreamClient interface {
+	Recv() (*BlobBatchEntrySignature, error)
+	grpc.ClientStream
+}
+
+type signingPostSignBlobBatchStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *signingPostSignBlobBatchStreamClient) Recv() (*BlobBatchEntrySignature, error) {
+	m := new(BlobBatchEntrySignature)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *signingClient) PostSignBlobCMS(ctx context.Context, in *CMSSigningRequest, opts ...grpc.CallOption) (*CMSSignature, error) {
+	out := new(CMSSignature)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignBlobCMS", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostEphemeralSignature(ctx context.Context, in *EphemeralSigningRequest, opts ...grpc.CallOption) (*EphemeralSignature, error) {
+	out := new(EphemeralSignature)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostEphemeralSignature", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostTenantSignature(ctx context.Context, in *TenantSigningRequest, opts ...grpc.CallOption) (*TenantSignature, error) {
+	out := new(TenantSignature)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostTenantSignature", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignBlobStream(ctx context.Context, opts ...grpc.CallOption) (Signing_PostSignBlobStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Signing_serviceDesc.Streams[1], "/v3.Signing/PostSignBlobStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &signingPostSignBlobStreamClient{stream}
+	return x, nil
+}
+
+type Signing_PostSignBlobStreamClient interface {
+	Send(*BlobStreamRequest) error
+	CloseAndRecv() (*BlobStreamSignature, error)
+	grpc.ClientStream
+}
+
+type signingPostSignBlobStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *signingPostSignBlobStreamClient) Send(m *BlobStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *signingPostSignBlobStreamClient) CloseAndRecv() (*BlobStreamSignature, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BlobStreamSignature)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *signingClient) PostTimestamp(ctx context.Context, in *TimestampRequest, opts ...grpc.CallOption) (*TimestampResponse, error) {
+	out := new(TimestampResponse)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostTimestamp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignGitObject(ctx context.Context, in *GitSigningRequest, opts ...grpc.CallOption) (*GitSignature, error) {
+	out := new(GitSignature)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignGitObject", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignDNSSEC(ctx context.Context, in *DNSSECSigningRequest, opts ...grpc.CallOption) (*DNSSECSignature, error) {
+	out := new(DNSSECSignature)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignDNSSEC", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignAttestation(ctx context.Context, in *AttestationSigningRequest, opts ...grpc.CallOption) (*AttestationStatement, error) {
+	out := new(AttestationStatement)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignAttestation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostSignContainerImage(ctx context.Context, in *ContainerImageSigningRequest, opts ...grpc.CallOption) (*ContainerImageSignature, error) {
+	out := new(ContainerImageSignature)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignContainerImage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) PostIssuanceManifest(ctx context.Context, in *IssuanceManifestRequest, opts ...grpc.CallOption) (*IssuanceReceipt, error) {
+	out := new(IssuanceReceipt)
+	err := c.cc.Invoke(ctx, "/v3.Signing/PostIssuanceManifest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingClient) GetKeyCapabilities(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*KeyCapabilities, error) {
+	out := new(KeyCapabilities)
+	err := c.cc.Invoke(ctx, "/v3.Signing/GetKeyCapabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SigningServer is the server API for Signing service.
+type SigningServer interface {
+	// GetX509CertificateAvailableSigningKeys returns all available keys that can sign X509 certificates.
+	GetX509CertificateAvailableSigningKeys(context.Context, *KeyFilter) (*KeyMetas, error)
+	// GetX509CACertificate returns the CA X509 certificate self-signed by the specified key.
+	GetX509CACertificate(context.Context, *KeyMeta) (*X509Certificate, error)
+	// ExportX509CACertificatePKCS12 returns the CA X509 certificate chain of the specified key as a password
+	// protected PKCS#12 file holding no private key, for import into the trust stores of Java clients.
+	ExportX509CACertificatePKCS12(context.Context, *X509CACertificatePKCS12Request) (*PKCS12, error)
+	// PostX509Certificate signs the given CSR using the specified key and returns a PEM encoded X509 certificate.
+	PostX509Certificate(context.Context, *X509CertificateSigningRequest) (*X509Certificate, error)
+	// PreviewX509Certificate returns the certificate that PostX509Certificate would issue for the given CSR,
+	// with all the profiles, policies and defaults of the specified key applied, without signing it.
+	PreviewX509Certificate(context.Context, *X509CertificateSigningRequest) (*X509CertificatePreview, error)
+	// GetUserSSHCertificateAvailableSigningKeys returns all available keys that can sign user SSH certificates.
+	GetUserSSHCertificateAvailableSigningKeys(context.Context, *KeyFilter) (*KeyMetas, error)
+	// GetUserSSHCertificateSigningKey returns the public signing key of the
+	// specified key that signs the user ssh certificate.
+	GetUserSSHCertificateSigningKey(context.Context, *KeyMeta) (*SSHKey, error)
+	// PostUserSSHCertificate signs the SSH user certificate given request fields using the specified key.
+	PostUserSSHCertificate(context.Context, *SSHCertificateSigningRequest) (*SSHKey, error)
+	// GetHostSSHCertificateAvailableSigningKeys returns all available keys that can sign host SSH certificates.
+	GetHostSSHCertificateAvailableSigningKeys(context.Context, *KeyFilter) (*KeyMetas, error)
+	// GetHostSSHCertificateSigningKey returns the public signing key of the
+	// specified key that signs the host ssh certificate.
+	GetHostSSHCertificateSigningKey(context.Context, *KeyMeta) (*SSHKey, error)
+	// PostHostSSHCertificate signs the SSH host certificate given request fields using the specified key.
+	PostHostSSHCertificate(context.Context, *SSHCertificateSigningRequest) (*SSHKey, error)
+	// VerifySSHCertificate checks that an SSH certificate is signed by the specified CA key and is
+	// currently within its validity period. It is read-only and does not use the private key.
+	VerifySSHCertificate(context.Context, *SSHCertificateVerificationRequest) (*SSHCertificateVerification, error)
+	// GetBlobAvailableSigningKeys returns all available keys that can sign
+	GetBlobAvailableSigningKeys(context.Context, *KeyFilter) (*KeyMetas, error)
+	// GetBlobSigningKey returns the public signing key of the
+	// specified key that signs the user's data.
+	GetBlobSigningKey(context.Context, *KeyMeta) (*PublicKey, error)
+	// PostSignBlob signs the digest using the specified key.
+	PostSignBlob(context.Context, *BlobSigningRequest) (*Signature, error)
+	// PostSignBlobBatch signs the digests of the entries using the specified key, each with the signer
+	// options of its hash algorithm. The request fails if any of the entries cannot be signed.
+	PostSignBlobBatch(context.Context, *BlobBatchSigningRequest) (*BlobBatchSignatures, error)
+	// PostSignBlobBatchStream signs the digests of the entries as PostSignBlobBatch does, for batches too large
+	// for a single response: the result of each entry is streamed, in the order of the entries, as soon as the
+	// entry is signed, and an entry that cannot be signed does not fail the others.
+	PostSignBlobBatchStream(*BlobBatchSigningRequest, Signing_PostSignBlobBatchStreamServer) error
+	// PostSignBlobCMS returns a detached CMS signature of the content of the digest using the specified key.
+	// The signature covers the content-type, message-digest and signing-time signed attributes, the latter
+	// being the server time, and the SignedData includes the X509 CA certificate of the key.
+	PostSignBlobCMS(context.Context, *CMSSigningRequest) (*CMSSignature, error)
+	// PostEphemeralSignature signs the digest with a one-time key generated in the HSM as specified by
+	// the configuration of key_meta, and returns the signature along with the public key. The private
+	// key is destroyed once the digest is signed.
+	PostEphemeralSignature(context.Context, *EphemeralSigningRequest) (*EphemeralSignature, error)
+	// PostTenantSignature would sign the digest with a subkey of the tenant derived from key_meta. It is not
+	// supported and fails with Unimplemented, as the subkeys could not be derived and held in the HSM.
+	PostTenantSignature(context.Context, *TenantSigningRequest) (*TenantSignature, error)
+	// PostSignBlobStream hashes the streamed blob with the requested hash algorithm and signs its digest
+	// using the specified key, as PostSignBlob does. It is only served over gRPC.
+	PostSignBlobStream(Signing_PostSignBlobStreamServer) error
+	// PostTimestamp returns the RFC 3161 time-stamp response to the time-stamp request,
+	// with the time-stamp token signed by the specified TSA key.
+	PostTimestamp(context.Context, *TimestampRequest) (*TimestampResponse, error)
+	// PostSignGitObject returns the SSHSIG signature of the git commit or tag object,
+	// as produced by "ssh-keygen -Y sign -n git", signed by the specified SSH key.
+	PostSignGitObject(context.Context, *GitSigningRequest) (*GitSignature, error)
+	// PostSignDNSSEC returns the RRSIG signature of the RRset signing data, signed by the specified
+	// RSA or ECDSA zone-signing key, with the DNSSEC algorithm number of the signature.
+	PostSignDNSSEC(context.Context, *DNSSECSigningRequest) (*DNSSECSignature, error)
+	// PostSignAttestation returns the packed attestation statement of the WebAuthn attestation,
+	// signed by the specified attestation key, along with its attestation certificate.
+	PostSignAttestation(context.Context, *AttestationSigningRequest) (*AttestationStatement, error)
+	// PostSignContainerImage returns the cosign signature of the container image manifest,
+	// signed by the specified ECDSA key, along with the signed payload and the public key.
+	PostSignContainerImage(context.Context, *ContainerImageSigningRequest) (*ContainerImageSignature, error)
+	// PostIssuanceManifest returns the receipt of the certificates of a session, whose payload is the manifest
+	// of the session: the type, serial number, subject and fingerprint of each certificate. The certificates
+	// are looked up by serial number or fingerprint in the issuance store, which only holds the certificates
+	// of the other crypki replicas if its directory is on a filesystem shared by them.
+	PostIssuanceManifest(context.Context, *IssuanceManifestRequest) (*IssuanceReceipt, error)
+	// GetKeyCapabilities returns the capabilities of the specified key.
+	GetKeyCapabilities(context.Context, *KeyMeta) (*KeyCapabilities, error)
+}
+
+func RegisterSigningServer(s *grpc.Server, srv SigningServer) {
+	s.RegisterService(&_Signing_serviceDesc, srv)
+}
+
+func _Signing_GetX509CertificateAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetX509CertificateAvailableSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetX509CertificateAvailableSigningKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetX509CertificateAvailableSigningKeys(ctx, req.(*KeyFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetX509CACertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyMeta)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetX509CACertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetX509CACertificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetX509CACertificate(ctx, req.(*KeyMeta))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_ExportX509CACertificatePKCS12_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(X509CACertificatePKCS12Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).ExportX509CACertificatePKCS12(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/ExportX509CACertificatePKCS12",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).ExportX509CACertificatePKCS12(ctx, req.(*X509CACertificatePKCS12Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostX509Certificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(X509CertificateSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostX509Certificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostX509Certificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostX509Certificate(ctx, req.(*X509CertificateSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PreviewX509Certificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(X509CertificateSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PreviewX509Certificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PreviewX509Certificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PreviewX509Certificate(ctx, req.(*X509CertificateSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetUserSSHCertificateAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetUserSSHCertificateAvailableSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetUserSSHCertificateAvailableSigningKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetUserSSHCertificateAvailableSigningKeys(ctx, req.(*KeyFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetUserSSHCertificateSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyMeta)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetUserSSHCertificateSigningKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetUserSSHCertificateSigningKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetUserSSHCertificateSigningKey(ctx, req.(*KeyMeta))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostUserSSHCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SSHCertificateSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostUserSSHCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostUserSSHCertificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostUserSSHCertificate(ctx, req.(*SSHCertificateSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetHostSSHCertificateAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetHostSSHCertificateAvailableSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetHostSSHCertificateAvailableSigningKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetHostSSHCertificateAvailableSigningKeys(ctx, req.(*KeyFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetHostSSHCertificateSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyMeta)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetHostSSHCertificateSigningKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetHostSSHCertificateSigningKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetHostSSHCertificateSigningKey(ctx, req.(*KeyMeta))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostHostSSHCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SSHCertificateSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostHostSSHCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostHostSSHCertificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostHostSSHCertificate(ctx, req.(*SSHCertificateSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_VerifySSHCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SSHCertificateVerificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).VerifySSHCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/VerifySSHCertificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).VerifySSHCertificate(ctx, req.(*SSHCertificateVerificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetBlobAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetBlobAvailableSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetBlobAvailableSigningKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetBlobAvailableSigningKeys(ctx, req.(*KeyFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetBlobSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyMeta)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetBlobSigningKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetBlobSigningKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetBlobSigningKey(ctx, req.(*KeyMeta))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlobSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostSignBlob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostSignBlob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostSignBlob(ctx, req.(*BlobSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignBlobBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlobBatchSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostSignBlobBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostSignBlobBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostSignBlobBatch(ctx, req.(*BlobBatchSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignBlobBatchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BlobBatchSigningRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SigningServer).PostSignBlobBatchStream(m, &signingPostSignBlobBatchStreamServer{stream})
+}
+
+type Signing_PostSignBlobBatchStreamServer interface {
+	Send(*BlobBatchEntrySignature) error
+	grpc.ServerStream
+}
+
+type signingPostSignBlobBatchStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *signingPostSignBlobBatchStreamServer) Send(m *BlobBatchEntrySignature) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Signing_PostSignBlobCMS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CMSSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostSignBlobCMS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostSignBlobCMS",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostSignBlobCMS(ctx, req.(*CMSSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostEphemeralSignature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EphemeralSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostEphemeralSignature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostEphemeralSignature",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostEphemeralSignature(ctx, req.(*EphemeralSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostTenantSignature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TenantSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostTenantSignature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostTenantSignature",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostTenantSignature(ctx, req.(*TenantSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignBlobStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SigningServer).PostSignBlobStream(&signingPostSignBlobStreamServer{stream})
+}
+
+type Signing_PostSignBlobStreamServer interface {
+	SendAndClose(*BlobStreamSignature) error
+	Recv() (*BlobStreamRequest, error)
+	grpc.ServerStream
+}
+
+type signingPostSignBlobStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *signingPostSignBlobStreamServer) SendAndClose(m *BlobStreamSignature) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *signingPostSignBlobStreamServer) Recv() (*BlobStreamRequest, error) {
+	m := new(BlobStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Signing_PostTimestamp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimestampRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostTimestamp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostTimestamp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostTimestamp(ctx, req.(*TimestampRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignGitObject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GitSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostSignGitObject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostSignGitObject",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostSignGitObject(ctx, req.(*GitSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignDNSSEC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSSECSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostSignDNSSEC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostSignDNSSEC",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostSignDNSSEC(ctx, req.(*DNSSECSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignAttestation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttestationSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostSignAttestation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostSignAttestation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostSignAttestation(ctx, req.(*AttestationSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostSignContainerImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainerImageSigningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostSignContainerImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostSignContainerImage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostSignContainerImage(ctx, req.(*ContainerImageSigningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_PostIssuanceManifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssuanceManifestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).PostIssuanceManifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/PostIssuanceManifest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).PostIssuanceManifest(ctx, req.(*IssuanceManifestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Signing_GetKeyCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyMeta)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServer).GetKeyCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v3.Signing/GetKeyCapabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServer).GetKeyCapabilities(ctx, req.(*KeyMeta))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Signing_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v3.Signing",
+	HandlerType: (*SigningServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetX509CertificateAvailableSigningKeys",
+			Handler:    _Signing_GetX509CertificateAvailableSigningKeys_Handler,
+		},
+		{
+			MethodName: "GetX509CACertificate",
+			Handler:    _Signing_GetX509CACertificate_Handler,
+		},
+		{
+			MethodName: "ExportX509CACertificatePKCS12",
+			Handler:    _Signing_ExportX509CACertificatePKCS12_Handler,
+		},
+		{
+			MethodName: "PostX509Certificate",
+			Handler:    _Signing_PostX509Certificate_Handler,
+		},
+		{
+			MethodName: "PreviewX509Certificate",
+			Handler:    _Signing_PreviewX509Certificate_Handler,
+		},
+		{
+			MethodName: "GetUserSSHCertificateAvailableSigningKeys",
+			Handler:    _Signing_GetUserSSHCertificateAvailableSigningKeys_Handler,
+		},
+		{
+			MethodName: "GetUserSSHCertificateSigningKey",
+			Handler:    _Signing_GetUserSSHCertificateSigningKey_Handler,
+		},
+		{
+			MethodName: "PostUserSSHCertificate",
+			Handler:    _Signing_PostUserSSHCertificate_Handler,
+		},
+		{
+			MethodName: "GetHostSSHCertificateAvailableSigningKeys",
+			Handler:    _Signing_GetHostSSHCertificateAvailableSigningKeys_Handler,
+		},
+		{
+			MethodName: "GetHostSSHCertificateSigningKey",
+			Handler:    _Signing_GetHostSSHCertificateSigningKey_Handler,
+		},
+		{
+			MethodName: "PostHostSSHCertificate",
+			Handler:    _Signing_PostHostSSHCertificate_Handler,
+		},
+		{
+			MethodName: "VerifySSHCertificate",
+			Handler:    _Signing_VerifySSHCertificate_Handler,
+		},
+		{
+			MethodName: "GetBlobAvailableSigningKeys",
+			Handler:    _Signing_GetBlobAvailableSigningKeys_Handler,
+		},
+		{
+			MethodName: "GetBlobSigningKey",
+			Handler:    _Signing_GetBlobSigningKey_Handler,
+		},
+		{
+			MethodName: "PostSignBlob",
+			Handler:    _Signing_PostSignBlob_Handler,
+		},
+		{
+			MethodName: "PostSignBlobBatch",
+			Handler:    _Signing_PostSignBlobBatch_Handler,
+		},
+		{
+			MethodName: "PostSignBlobCMS",
+			Handler:    _Signing_PostSignBlobCMS_Handler,
+		},
+		{
+			MethodName: "PostEphemeralSignature",
+			Handler:    _Signing_PostEphemeralSignature_Handler,
+		},
+		{
+			MethodName: "PostTenantSignature",
+			Handler:    _Signing_PostTenantSignature_Handler,
+		},
+		{
+			MethodName: "PostTimestamp",
+			Handler:    _Signing_PostTimestamp_Handler,
+		},
+		{
+			MethodName: "PostSignGitObject",
+			Handler:    _Signing_PostSignGitObject_Handler,
+		},
+		{
+			MethodName: "PostSignDNSSEC",
+			Handler:    _Signing_PostSignDNSSEC_Handler,
+		},
+		{
+			MethodName: "PostSignAttestation",
+			Handler:    _Signing_PostSignAttestation_Handler,
+		},
+		{
+			MethodName: "PostSignContainerImage",
+			Handler:    _Signing_PostSignContainerImage_Handler,
+		},
+		{
+			MethodName: "PostIssuanceManifest",
+			Handler:    _Signing_PostIssuanceManifest_Handler,
+		},
+		{
+			MethodName: "GetKeyCapabilities",
+			Handler:    _Signing_GetKeyCapabilities_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PostSignBlobBatchStream",
+			Handler:       _Signing_PostSignBlobBatchStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PostSignBlobStream",
+			Handler:       _Signing_PostSignBlobStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "sign.proto",
+}
+
+// AdminClient is the client API for Admin service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type SigningClient interface {
-	// GetX509CertificateAvailableSigningKeys returns all available keys that can sign X509 certificates.
-	GetX509CertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error)
-	// GetX509CACertificate returns the CA X509 certificate self-signed by the specified key.
-	GetX509CACertificate(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*X509Certificate, error)
-	// PostX509Certificate signs the given CSR using the specified key and returns a PEM encoded X509 certificate.
-	PostX509Certificate(ctx context.Context, in *X509CertificateSigningRequest, opts ...grpc.CallOption) (*X509Certificate, error)
-	// GetUserSSHCertificateAvailableSigningKeys returns all available keys that can sign user SSH certificates.
-	GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error)
-	// GetUserSSHCertificateSigningKey returns the public signing key of the
-	// specified key that signs the user ssh certificate.
-	GetUserSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error)
-	// PostUserSSHCertificate signs the SSH user certificate given request fields using the specified key.
-	PostUserSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error)
-	// GetHostSSHCertificateAvailableSigningKeys returns all available keys that can sign host SSH certificates.
-	GetHostSSHCertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error)
-	// GetHostSSHCertificateSigningKey returns the public signing key of the
-	// specified key that signs the host ssh certificate.
-	GetHostSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error)
-	// PostHostSSHCertificate signs the SSH host certificate given request fields using the specified key.
-	PostHostSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error)
-	// GetBlobAvailableSigningKeys returns all available keys that can sign
-	GetBlobAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error)
-	// GetBlobSigningKey returns the public signing key of the
-	// specified key that signs the user's data.
-	GetBlobSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*PublicKey, error)
-	// PostBlob signs the digest using the specified key.
-	PostSignBlob(ctx context.Context, in *BlobSigningRequest, opts ...grpc.CallOption) (*Signature, error)
+type AdminClient interface {
+	// GetServerInfo returns the runtime state of the server.
+	GetServerInfo(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ServerInfo, error)
+	// SetEndpointStatus enables or disables the signing requests of an endpoint at runtime.
+	SetEndpointStatus(ctx context.Context, in *EndpointStatus, opts ...grpc.CallOption) (*EndpointStatus, error)
+	// GenerateKey generates a new key pair in the HSM and returns its public key. No endpoint signs with
+	// the key until it is added to the Keys and KeyUsages of the configuration and crypki is restarted.
+	GenerateKey(ctx context.Context, in *KeyGenerationRequest, opts ...grpc.CallOption) (*GeneratedKey, error)
+	// ReloadKeySessions closes the PKCS#11 sessions of a signing key once the signing requests using them are
+	// done, and opens new ones, to recover a key whose sessions went bad without restarting the server.
+	ReloadKeySessions(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*ReloadedSessions, error)
+	// ListRecentIssuance returns the metadata of the certificates recently issued by this server,
+	// most recent first. The records are kept in memory, are bounded in number and are lost on restart.
+	ListRecentIssuance(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*IssuanceRecords, error)
+	// GetKeyUsageStats returns the usage counters of the signing keys, including the unused ones.
+	// The counters are kept in memory and are reset on restart.
+	GetKeyUsageStats(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyUsageStatsList, error)
+	// ListHSMMechanisms returns the PKCS#11 mechanisms supported by each slot holding signing keys,
+	// to validate the configured mechanisms and diagnose "mechanism not supported" errors.
+	ListHSMMechanisms(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SlotMechanismsList, error)
+	// GetEffectiveConfig returns the configuration the server is running with, to confirm which of the
+	// configured and default values are in effect. The secrets and the paths to them are redacted.
+	GetEffectiveConfig(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*EffectiveConfig, error)
 }
 
-type signingClient struct {
+type adminClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewSigningClient(cc *grpc.ClientConn) SigningClient {
-	return &signingClient{cc}
-}
-
-func (c *signingClient) GetX509CertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error) {
-	out := new(KeyMetas)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetX509CertificateAvailableSigningKeys", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-func (c *signingClient) GetX509CACertificate(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*X509Certificate, error) {
-	out := new(X509Certificate)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetX509CACertificate", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-func (c *signingClient) PostX509Certificate(ctx context.Context, in *X509CertificateSigningRequest, opts ...grpc.CallOption) (*X509Certificate, error) {
-	out := new(X509Certificate)
-	err := c.cc.Invoke(ctx, "/v3.Signing/PostX509Certificate", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-func (c *signingClient) GetUserSSHCertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error) {
-	out := new(KeyMetas)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetUserSSHCertificateAvailableSigningKeys", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
 }
 
-func (c *signingClient) GetUserSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error) {
-	out := new(SSHKey)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetUserSSHCertificateSigningKey", in, out, opts...)
+func (c *adminClient) GetServerInfo(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ServerInfo, error) {
+	out := new(ServerInfo)
+	err := c.cc.Invoke(ctx, "/v3.Admin/GetServerInfo", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *signingClient) PostUserSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error) {
-	out := new(SSHKey)
-	err := c.cc.Invoke(ctx, "/v3.Signing/PostUserSSHCertificate", in, out, opts...)
+func (c *adminClient) SetEndpointStatus(ctx context.Context, in *EndpointStatus, opts ...grpc.CallOption) (*EndpointStatus, error) {
+	out := new(EndpointStatus)
+	err := c.cc.Invoke(ctx, "/v3.Admin/SetEndpointStatus", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *signingClient) GetHostSSHCertificateAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error) {
-	out := new(KeyMetas)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetHostSSHCertificateAvailableSigningKeys", in, out, opts...)
+func (c *adminClient) GenerateKey(ctx context.Context, in *KeyGenerationRequest, opts ...grpc.CallOption) (*GeneratedKey, error) {
+	out := new(GeneratedKey)
+	err := c.cc.Invoke(ctx, "/v3.Admin/GenerateKey", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *signingClient) GetHostSSHCertificateSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*SSHKey, error) {
-	out := new(SSHKey)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetHostSSHCertificateSigningKey", in, out, opts...)
+func (c *adminClient) ReloadKeySessions(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*ReloadedSessions, error) {
+	out := new(ReloadedSessions)
+	err := c.cc.Invoke(ctx, "/v3.Admin/ReloadKeySessions", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *signingClient) PostHostSSHCertificate(ctx context.Context, in *SSHCertificateSigningRequest, opts ...grpc.CallOption) (*SSHKey, error) {
-	out := new(SSHKey)
-	err := c.cc.Invoke(ctx, "/v3.Signing/PostHostSSHCertificate", in, out, opts...)
+func (c *adminClient) ListRecentIssuance(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*IssuanceRecords, error) {
+	out := new(IssuanceRecords)
+	err := c.cc.Invoke(ctx, "/v3.Admin/ListRecentIssuance", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *signingClient) GetBlobAvailableSigningKeys(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyMetas, error) {
-	out := new(KeyMetas)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetBlobAvailableSigningKeys", in, out, opts...)
+func (c *adminClient) GetKeyUsageStats(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*KeyUsageStatsList, error) {
+	out := new(KeyUsageStatsList)
+	err := c.cc.Invoke(ctx, "/v3.Admin/GetKeyUsageStats", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *signingClient) GetBlobSigningKey(ctx context.Context, in *KeyMeta, opts ...grpc.CallOption) (*PublicKey, error) {
-	out := new(PublicKey)
-	err := c.cc.Invoke(ctx, "/v3.Signing/GetBlobSigningKey", in, out, opts...)
+func (c *adminClient) ListHSMMechanisms(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SlotMechanismsList, error) {
+	out := new(SlotMechanismsList)
+	err := c.cc.Invoke(ctx, "/v3.Admin/ListHSMMechanisms", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *signingClient) PostSignBlob(ctx context.Context, in *BlobSigningRequest, opts ...grpc.CallOption) (*Signature, error) {
-	out := new(Signature)
-	err := c.cc.Invoke(ctx, "/v3.Signing/PostSignBlob", in, out, opts...)
+func (c *adminClient) GetEffectiveConfig(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*EffectiveConfig, error) {
+	out := new(EffectiveConfig)
+	err := c.cc.Invoke(ctx, "/v3.Admin/GetEffectiveConfig", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// SigningServer is the server API for Signing service.
-type SigningServer interface {
-	// GetX509CertificateAvailableSigningKeys returns all available keys that can sign X509 certificates.
-	GetX509CertificateAvailableSigningKeys(context.Context, *empty.Empty) (*KeyMetas, error)
-	// GetX509CACertificate returns the CA X509 certificate self-signed by the specified key.
-	GetX509CACertificate(context.Context, *KeyMeta) (*X509Certificate, error)
-	// PostX509Certificate signs the given CSR using the specified key and returns a PEM encoded X509 certificate.
-	PostX509Certificate(context.Context, *X509CertificateSigningRequest) (*X509Certificate, error)
-	// GetUserSSHCertificateAvailableSigningKeys returns all available keys that can sign user SSH certificates.
-	GetUserSSHCertificateAvailableSigningKeys(context.Context, *empty.Empty) (*KeyMetas, error)
-	// GetUserSSHCertificateSigningKey returns the public signing key of the
-	// specified key that signs the user ssh certificate.
-	GetUserSSHCertificateSigningKey(context.Context, *KeyMeta) (*SSHKey, error)
-	// PostUserSSHCertificate signs the SSH user certificate given request fields using the specified key.
-	PostUserSSHCertificate(context.Context, *SSHCertificateSigningRequest) (*SSHKey, error)
-	// GetHostSSHCertificateAvailableSigningKeys returns all available keys that can sign host SSH certificates.
-	GetHostSSHCertificateAvailableSigningKeys(context.Context, *empty.Empty) (*KeyMetas, error)
-	// GetHostSSHCertificateSigningKey returns the public signing key of the
-	// specified key that signs the host ssh certificate.
-	GetHostSSHCertificateSigningKey(context.Context, *KeyMeta) (*SSHKey, error)
-	// PostHostSSHCertificate signs the SSH host certificate given request fields using the specified key.
-	PostHostSSHCertificate(context.Context, *SSHCertificateSigningRequest) (*SSHKey, error)
-	// GetBlobAvailableSigningKeys returns all available keys that can sign
-	GetBlobAvailableSigningKeys(context.Context, *empty.Empty) (*KeyMetas, error)
-	// GetBlobSigningKey returns the public signing key of the
-	// specified key that signs the user's data.
-	GetBlobSigningKey(context.Context, *KeyMeta) (*PublicKey, error)
-	// PostBlob signs the digest using the specified key.
-	PostSignBlob(context.Context, *BlobSigningRequest) (*Signature, error)
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	// GetServerInfo returns the runtime state of the server.
+	GetServerInfo(context.Context, *empty.Empty) (*ServerInfo, error)
+	// SetEndpointStatus enables or disables the signing requests of an endpoint at runtime.
+	SetEndpointStatus(context.Context, *EndpointStatus) (*EndpointStatus, error)
+	// GenerateKey generates a new key pair in the HSM and returns its public key. No endpoint signs with
+	// the key until it is added to the Keys and KeyUsages of the configuration and crypki is restarted.
+	GenerateKey(context.Context, *KeyGenerationRequest) (*GeneratedKey, error)
+	// ReloadKeySessions closes the PKCS#11 sessions of a signing key once the signing requests using them are
+	// done, and opens new ones, to recover a key whose sessions went bad without restarting the server.
+	ReloadKeySessions(context.Context, *KeyMeta) (*ReloadedSessions, error)
+	// ListRecentIssuance returns the metadata of the certificates recently issued by this server,
+	// most recent first. The records are kept in memory, are bounded in number and are lost on restart.
+	ListRecentIssuance(context.Context, *empty.Empty) (*IssuanceRecords, error)
+	// GetKeyUsageStats returns the usage counters of the signing keys, including the unused ones.
+	// The counters are kept in memory and are reset on restart.
+	GetKeyUsageStats(context.Context, *empty.Empty) (*KeyUsageStatsList, error)
+	// ListHSMMechanisms returns the PKCS#11 mechanisms supported by each slot holding signing keys,
+	// to validate the configured mechanisms and diagnose "mechanism not supported" errors.
+	ListHSMMechanisms(context.Context, *empty.Empty) (*SlotMechanismsList, error)
+	// GetEffectiveConfig returns the configuration the server is running with, to confirm which of the
+	// configured and default values are in effect. The secrets and the paths to them are redacted.
+	GetEffectiveConfig(context.Context, *empty.Empty) (*EffectiveConfig, error)
 }
 
-func RegisterSigningServer(s *grpc.Server, srv SigningServer) {
-	s.RegisterService(&_Signing_serviceDesc, srv)
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
 }
 
-func _Signing_GetX509CertificateAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _Admin_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).GetX509CertificateAvailableSigningKeys(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/v3.Signing/GetX509CertificateAvailableSigningKeys",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetX509CertificateAvailableSigningKeys(ctx, req.(*empty.Empty))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-func _Signing_GetX509CACertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(KeyMeta)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(SigningServer).GetX509CACertificate(ctx, in)
+		return srv.(AdminServer).GetServerInfo(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/GetX509CACertificate",
+		FullMethod: "/v3.Admin/GetServerInfo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetX509CACertificate(ctx, req.(*KeyMeta))
+		return srv.(AdminServer).GetServerInfo(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Signing_PostX509Certificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(X509CertificateSigningRequest)
+func _Admin_SetEndpointStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EndpointStatus)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).PostX509Certificate(ctx, in)
+		return srv.(AdminServer).SetEndpointStatus(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/PostX509Certificate",
+		FullMethod: "/v3.Admin/SetEndpointStatus",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).PostX509Certificate(ctx, req.(*X509CertificateSigningRequest))
+		return srv.(AdminServer).SetEndpointStatus(ctx, req.(*EndpointStatus))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Signing_GetUserSSHCertificateAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(empty.Empty)
+func _Admin_GenerateKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyGenerationRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).GetUserSSHCertificateAvailableSigningKeys(ctx, in)
+		return srv.(AdminServer).GenerateKey(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/GetUserSSHCertificateAvailableSigningKeys",
+		FullMethod: "/v3.Admin/GenerateKey",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetUserSSHCertificateAvailableSigningKeys(ctx, req.(*empty.Empty))
+		return srv.(AdminServer).GenerateKey(ctx, req.(*KeyGenerationRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Signing_GetUserSSHCertificateSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _Admin_ReloadKeySessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(KeyMeta)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).GetUserSSHCertificateSigningKey(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/v3.Signing/GetUserSSHCertificateSigningKey",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetUserSSHCertificateSigningKey(ctx, req.(*KeyMeta))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-func _Signing_PostUserSSHCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SSHCertificateSigningRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(SigningServer).PostUserSSHCertificate(ctx, in)
+		return srv.(AdminServer).ReloadKeySessions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/PostUserSSHCertificate",
+		FullMethod: "/v3.Admin/ReloadKeySessions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).PostUserSSHCertificate(ctx, req.(*SSHCertificateSigningRequest))
+		return srv.(AdminServer).ReloadKeySessions(ctx, req.(*KeyMeta))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Signing_GetHostSSHCertificateAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _Admin_ListRecentIssuance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).GetHostSSHCertificateAvailableSigningKeys(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/v3.Signing/GetHostSSHCertificateAvailableSigningKeys",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetHostSSHCertificateAvailableSigningKeys(ctx, req.(*empty.Empty))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-func _Signing_GetHostSSHCertificateSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(KeyMeta)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(SigningServer).GetHostSSHCertificateSigningKey(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/v3.Signing/GetHostSSHCertificateSigningKey",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetHostSSHCertificateSigningKey(ctx, req.(*KeyMeta))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-func _Signing_PostHostSSHCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SSHCertificateSigningRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(SigningServer).PostHostSSHCertificate(ctx, in)
+		return srv.(AdminServer).ListRecentIssuance(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/PostHostSSHCertificate",
+		FullMethod: "/v3.Admin/ListRecentIssuance",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).PostHostSSHCertificate(ctx, req.(*SSHCertificateSigningRequest))
+		return srv.(AdminServer).ListRecentIssuance(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Signing_GetBlobAvailableSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _Admin_GetKeyUsageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).GetBlobAvailableSigningKeys(ctx, in)
+		return srv.(AdminServer).GetKeyUsageStats(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/GetBlobAvailableSigningKeys",
+		FullMethod: "/v3.Admin/GetKeyUsageStats",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetBlobAvailableSigningKeys(ctx, req.(*empty.Empty))
+		return srv.(AdminServer).GetKeyUsageStats(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Signing_GetBlobSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(KeyMeta)
+func _Admin_ListHSMMechanisms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).GetBlobSigningKey(ctx, in)
+		return srv.(AdminServer).ListHSMMechanisms(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/GetBlobSigningKey",
+		FullMethod: "/v3.Admin/ListHSMMechanisms",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).GetBlobSigningKey(ctx, req.(*KeyMeta))
+		return srv.(AdminServer).ListHSMMechanisms(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Signing_PostSignBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BlobSigningRequest)
+func _Admin_GetEffectiveConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(SigningServer).PostSignBlob(ctx, in)
+		return srv.(AdminServer).GetEffectiveConfig(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/v3.Signing/PostSignBlob",
+		FullMethod: "/v3.Admin/GetEffectiveConfig",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SigningServer).PostSignBlob(ctx, req.(*BlobSigningRequest))
+		return srv.(AdminServer).GetEffectiveConfig(ctx, req.(*empty.Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Signing_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "v3.Signing",
-	HandlerType: (*SigningServer)(nil),
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v3.Admin",
+	HandlerType: (*AdminServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "GetX509CertificateAvailableSigningKeys",
-			Handler:    _Signing_GetX509CertificateAvailableSigningKeys_Handler,
-		},
-		{
-			MethodName: "GetX509CACertificate",
-			Handler:    _Signing_GetX509CACertificate_Handler,
-		},
-		{
-			MethodName: "PostX509Certificate",
-			Handler:    _Signing_PostX509Certificate_Handler,
-		},
-		{
-			MethodName: "GetUserSSHCertificateAvailableSigningKeys",
-			Handler:    _Signing_GetUserSSHCertificateAvailableSigningKeys_Handler,
-		},
-		{
-			MethodName: "GetUserSSHCertificateSigningKey",
-			Handler:    _Signing_GetUserSSHCertificateSigningKey_Handler,
+			MethodName: "GetServerInfo",
+			Handler:    _Admin_GetServerInfo_Handler,
 		},
 		{
-			MethodName: "PostUserSSHCertificate",
-			Handler:    _Signing_PostUserSSHCertificate_Handler,
+			MethodName: "SetEndpointStatus",
+			Handler:    _Admin_SetEndpointStatus_Handler,
 		},
 		{
-			MethodName: "GetHostSSHCertificateAvailableSigningKeys",
-			Handler:    _Signing_GetHostSSHCertificateAvailableSigningKeys_Handler,
+			MethodName: "GenerateKey",
+			Handler:    _Admin_GenerateKey_Handler,
 		},
 		{
-			MethodName: "GetHostSSHCertificateSigningKey",
-			Handler:    _Signing_GetHostSSHCertificateSigningKey_Handler,
+			MethodName: "ReloadKeySessions",
+			Handler:    _Admin_ReloadKeySessions_Handler,
 		},
 		{
-			MethodName: "PostHostSSHCertificate",
-			Handler:    _Signing_PostHostSSHCertificate_Handler,
+			MethodName: "ListRecentIssuance",
+			Handler:    _Admin_ListRecentIssuance_Handler,
 		},
 		{
-			MethodName: "GetBlobAvailableSigningKeys",
-			Handler:    _Signing_GetBlobAvailableSigningKeys_Handler,
+			MethodName: "GetKeyUsageStats",
+			Handler:    _Admin_GetKeyUsageStats_Handler,
 		},
 		{
-			MethodName: "GetBlobSigningKey",
-			Handler:    _Signing_GetBlobSigningKey_Handler,
+			MethodName: "ListHSMMechanisms",
+			Handler:    _Admin_ListHSMMechanisms_Handler,
 		},
 		{
-			MethodName: "PostSignBlob",
-			Handler:    _Signing_PostSignBlob_Handler,
+			MethodName: "GetEffectiveConfig",
+			Handler:    _Admin_GetEffectiveConfig_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "sign.proto",
 }
 
-func init() { proto.RegisterFile("sign.proto", fileDescriptor_sign_726672ce56df3d9d) }
-
-var fileDescriptor_sign_726672ce56df3d9d = []byte{
-	// 926 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x5d, 0x6f, 0xe3, 0x44,
-	0x14, 0xc5, 0x71, 0x3e, 0x9a, 0xdb, 0x76, 0xeb, 0x9d, 0x96, 0xc8, 0x78, 0xdb, 0x6e, 0x18, 0xb4,
-	0xdd, 0x76, 0x3f, 0xe2, 0x6e, 0xb2, 0x81, 0xdd, 0x45, 0x3c, 0xb4, 0xab, 0xaa, 0x41, 0x11, 0xa2,
-	0x4a, 0x54, 0x81, 0x78, 0x20, 0x38, 0xce, 0xac, 0x33, 0x8a, 0x6b, 0x07, 0xcf, 0x24, 0xaa, 0x85,
-	0x78, 0x01, 0x09, 0x89, 0x67, 0x9e, 0xf8, 0x5d, 0xfc, 0x02, 0x24, 0xde, 0xf9, 0x0b, 0x68, 0xc6,
-	0x76, 0x12, 0xe7, 0xa3, 0x2b, 0xb6, 0xf0, 0xd4, 0x7b, 0xef, 0xf8, 0x9e, 0x73, 0xee, 0x99, 0xe9,
-	0x0d, 0x00, 0xa3, 0x8e, 0x57, 0x19, 0x06, 0x3e, 0xf7, 0x51, 0x66, 0x5c, 0x33, 0x76, 0x1d, 0xdf,
-	0x77, 0x5c, 0x62, 0x5a, 0x43, 0x6a, 0x5a, 0x9e, 0xe7, 0x73, 0x8b, 0x53, 0xdf, 0x63, 0xd1, 0x17,
-	0xc6, 0xbd, 0xf8, 0x54, 0x66, 0xdd, 0xd1, 0x1b, 0x93, 0x5c, 0x0d, 0x79, 0x18, 0x1d, 0xe2, 0x23,
-	0x28, 0x34, 0x49, 0xf8, 0x05, 0xe1, 0x16, 0xda, 0x07, 0xa0, 0x3d, 0xe2, 0x71, 0xfa, 0x86, 0x92,
-	0x40, 0x57, 0xca, 0xca, 0x61, 0xb1, 0x35, 0x53, 0xc1, 0x8f, 0x61, 0x2d, 0xfe, 0x94, 0xa1, 0xfb,
-	0x90, 0x1d, 0x90, 0x90, 0xe9, 0x4a, 0x59, 0x3d, 0x5c, 0xaf, 0xae, 0x57, 0xc6, 0xb5, 0x4a, 0x7c,
-	0xd6, 0x92, 0x07, 0xf8, 0x6f, 0x15, 0x76, 0xdb, 0xed, 0xc6, 0x6b, 0x12, 0x88, 0x6e, 0xdb, 0xe2,
-	0xa4, 0x4d, 0x1d, 0x8f, 0x7a, 0x4e, 0x8b, 0x7c, 0x3f, 0x22, 0x8c, 0xa3, 0x03, 0x58, 0x1b, 0x90,
-	0xb0, 0x73, 0x45, 0xb8, 0x25, 0xb9, 0xe6, 0x50, 0x0a, 0x83, 0xa9, 0xaa, 0x61, 0x40, 0x3d, 0x9b,
-	0x0e, 0x2d, 0x97, 0xe9, 0x99, 0xb2, 0x2a, 0x54, 0x4d, 0x2b, 0x68, 0x0f, 0x60, 0x38, 0xea, 0xba,
-	0xd4, 0xee, 0x0c, 0x48, 0xa8, 0xab, 0x52, 0x75, 0x31, 0xaa, 0x34, 0x49, 0x88, 0x0c, 0x58, 0x1b,
-	0x5b, 0x2e, 0xed, 0x51, 0x1e, 0xea, 0xd9, 0xb2, 0x72, 0x98, 0x6d, 0x4d, 0x72, 0xf4, 0x3e, 0xe4,
-	0x85, 0x04, 0xda, 0xd3, 0x73, 0xb2, 0x2d, 0x37, 0x20, 0xe1, 0xe7, 0x3d, 0xf4, 0x1d, 0x68, 0x76,
-	0x40, 0x39, 0xb5, 0x2d, 0xb7, 0xe3, 0x0f, 0xa5, 0x93, 0x7a, 0x5e, 0xce, 0x59, 0x17, 0x0a, 0x6f,
-	0x9a, 0xaa, 0xf2, 0x3a, 0x6e, 0xfc, 0x32, 0xea, 0x3b, 0xf3, 0x78, 0x10, 0xb6, 0xb6, 0xec, 0x74,
-	0x15, 0x5d, 0x00, 0x90, 0x6b, 0x4e, 0x3c, 0x26, 0xb1, 0x0b, 0x12, 0xfb, 0xf8, 0xad, 0xd8, 0x67,
-	0x93, 0x96, 0x08, 0x76, 0x06, 0xc3, 0x38, 0x85, 0x9d, 0x65, 0xd4, 0x48, 0x03, 0x55, 0xd8, 0x12,
-	0x5d, 0xa6, 0x08, 0xd1, 0x0e, 0xe4, 0xc6, 0x96, 0x3b, 0x22, 0x7a, 0x26, 0x9a, 0x59, 0x26, 0xaf,
-	0x32, 0x2f, 0x14, 0xe3, 0x33, 0xd8, 0x9a, 0xa3, 0xf8, 0x37, 0xed, 0xd8, 0x80, 0x7c, 0xbb, 0xdd,
-	0x10, 0x9e, 0x2f, 0x74, 0xe1, 0xdf, 0x15, 0xd8, 0xfb, 0xba, 0x7e, 0xfc, 0xf2, 0xf6, 0xcf, 0x41,
-	0x03, 0xd5, 0x66, 0x41, 0xcc, 0x2e, 0xc2, 0xd4, 0x0d, 0xab, 0x73, 0x37, 0x8c, 0x61, 0x93, 0x5c,
-	0x73, 0xf1, 0x32, 0x3a, 0x23, 0x66, 0x39, 0x44, 0xcf, 0x96, 0xd5, 0xc3, 0x5c, 0x6b, 0x9d, 0x5c,
-	0xf3, 0x26, 0x09, 0x2f, 0x45, 0x09, 0x3f, 0x80, 0xad, 0x39, 0x69, 0x08, 0x41, 0xd6, 0x26, 0x01,
-	0x8f, 0x27, 0x90, 0x31, 0xde, 0x83, 0xe2, 0xc5, 0xe4, 0x55, 0x2d, 0x4e, 0xf8, 0xab, 0x02, 0xe8,
-	0xd4, 0xf5, 0xbb, 0xef, 0x38, 0x56, 0x09, 0xf2, 0x3d, 0xea, 0x10, 0xc6, 0xe3, 0xc9, 0xe2, 0x0c,
-	0xd5, 0xe0, 0x4e, 0xdf, 0x62, 0xfd, 0x8e, 0xe5, 0x3a, 0x7e, 0x40, 0x79, 0xff, 0x4a, 0x8e, 0x78,
-	0xa7, 0xba, 0x21, 0x50, 0x1a, 0x16, 0xeb, 0x9f, 0xb8, 0x8e, 0xdf, 0xda, 0xec, 0xc7, 0x91, 0xfc,
-	0x04, 0x1f, 0x41, 0x51, 0xc8, 0xb0, 0xf8, 0x28, 0x20, 0x68, 0x17, 0x8a, 0x2c, 0x49, 0x62, 0xc1,
-	0xd3, 0xc2, 0xa3, 0x0b, 0x58, 0x4b, 0x50, 0xd0, 0x0e, 0x68, 0x97, 0x1e, 0x1b, 0x12, 0x5b, 0xfc,
-	0xbb, 0xf7, 0x3a, 0xa2, 0xae, 0xbd, 0x87, 0x00, 0xf2, 0xed, 0xc6, 0x49, 0xb5, 0xfa, 0x5c, 0x53,
-	0x92, 0xb8, 0xfe, 0xb1, 0x96, 0x89, 0xe3, 0xda, 0x8b, 0xe7, 0x9a, 0x1a, 0xc7, 0xf5, 0x67, 0x55,
-	0x2d, 0x5b, 0xfd, 0x13, 0xa0, 0x10, 0x9b, 0x80, 0x3c, 0x38, 0x38, 0x27, 0x7c, 0xce, 0xdd, 0x93,
-	0xb1, 0x45, 0x5d, 0xab, 0xeb, 0x26, 0x2f, 0xa0, 0x49, 0x42, 0x86, 0x4a, 0x95, 0x68, 0x49, 0x55,
-	0x92, 0x25, 0x55, 0x39, 0x13, 0x4b, 0xca, 0xd8, 0x98, 0x71, 0x8b, 0xe1, 0xfd, 0x9f, 0xfe, 0xf8,
-	0xeb, 0xb7, 0x8c, 0x8e, 0x4a, 0xe6, 0xb8, 0x66, 0x32, 0xea, 0x98, 0xd7, 0xf5, 0xe3, 0x97, 0x4f,
-	0xc5, 0xf5, 0x98, 0x62, 0xe9, 0x20, 0x02, 0x3b, 0x09, 0xdf, 0xc9, 0xec, 0x7d, 0xce, 0x7a, 0x6e,
-	0x6c, 0x8b, 0x64, 0x4e, 0x13, 0x7e, 0x2c, 0x91, 0x1f, 0xa0, 0x8f, 0x96, 0x23, 0x9b, 0x3f, 0x4c,
-	0xf7, 0xe0, 0x8f, 0xe8, 0x17, 0x05, 0xb6, 0x2f, 0x7c, 0x36, 0x3f, 0x18, 0xfa, 0x70, 0x09, 0x72,
-	0xfa, 0x3d, 0x2c, 0x27, 0xff, 0x44, 0x92, 0x3f, 0xc3, 0x4f, 0x56, 0x91, 0x27, 0x4f, 0xa8, 0x32,
-	0xa3, 0xe2, 0x95, 0xf2, 0x08, 0x8d, 0xe0, 0xe8, 0x9c, 0xf0, 0x4b, 0x46, 0x82, 0xf4, 0xe2, 0xb8,
-	0x85, 0xc5, 0x58, 0x6a, 0xd9, 0x45, 0x46, 0xa2, 0x85, 0xb1, 0xfe, 0xd3, 0x11, 0x23, 0xc1, 0x8c,
-	0xcd, 0x03, 0xb8, 0xbf, 0x94, 0x76, 0xca, 0x96, 0x76, 0x1c, 0xe2, 0xd5, 0xd6, 0x24, 0x21, 0x36,
-	0x25, 0xfe, 0x11, 0x7a, 0xb8, 0x1a, 0x3f, 0x6d, 0xf6, 0xcf, 0x0a, 0x94, 0x84, 0xd9, 0x8b, 0x74,
-	0xa8, 0xfc, 0xb6, 0x95, 0x99, 0x62, 0xfe, 0x54, 0x32, 0xd7, 0xf1, 0xf1, 0x4d, 0xcc, 0x37, 0x3b,
-	0xdd, 0xf0, 0x19, 0xff, 0x7f, 0x9d, 0xee, 0xfb, 0x8c, 0x2f, 0x38, 0xbd, 0x48, 0xfb, 0xce, 0x4e,
-	0xa7, 0xf1, 0x97, 0x3b, 0xbd, 0x48, 0xf7, 0x5f, 0x38, 0x3d, 0xcf, 0xbc, 0xca, 0xe9, 0x6f, 0xe1,
-	0xde, 0x39, 0xe1, 0x62, 0x95, 0xde, 0xc2, 0xdb, 0x0f, 0xa4, 0x82, 0x6d, 0x74, 0x37, 0x51, 0xd0,
-	0x75, 0xfd, 0x6e, 0x64, 0xe9, 0x57, 0x70, 0x37, 0xc6, 0x5f, 0x65, 0xe2, 0xa6, 0x48, 0x26, 0xbb,
-	0x1e, 0x1f, 0x48, 0xac, 0x32, 0xda, 0x5f, 0xc0, 0x4a, 0xdb, 0x47, 0x61, 0x43, 0xb8, 0x27, 0x50,
-	0x05, 0x3a, 0x2a, 0x09, 0x98, 0xc5, 0x9f, 0x84, 0x08, 0x7e, 0xb2, 0x9f, 0x71, 0x55, 0xc2, 0x3f,
-	0xc1, 0x0f, 0x97, 0xc0, 0xaf, 0xf0, 0xe8, 0xb4, 0xf0, 0x4d, 0x2e, 0x1a, 0x3f, 0x2f, 0xff, 0xd4,
-	0xfe, 0x09, 0x00, 0x00, 0xff, 0xff, 0x2b, 0x27, 0x53, 0xae, 0x11, 0x0a, 0x00, 0x00,
+func init() { proto.RegisterFile("sign.proto", fileDescriptor_sign_fa781ddcbfcb2f53) }
+
+var fileDescriptor_sign_fa781ddcbfcb2f53 = []byte{
+	// 4250 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x7b, 0x4f, 0x6f, 0x23, 0x47,
+	0x76, 0xb8, 0x9b, 0x14, 0x25, 0xf2, 0x89, 0x22, 0x5b, 0x25, 0x8e, 0x44, 0x73, 0xe4, 0x19, 0xb9,
+	0xbd, 0xb6, 0xc7, 0x9a, 0x19, 0xfd, 0x1d, 0xcd, 0xce, 0xcc, 0x62, 0xed, 0x1f, 0x25, 0x71, 0xa4,
+	0x59, 0x49, 0x33, 0xfa, 0x35, 0xa5, 0xf1, 0xc6, 0xc6, 0xa2, 0xd3, 0x6c, 0x96, 0xc4, 0x5e, 0x91,
+	0xdd, 0x74, 0x57, 0x53, 0x96, 0xbc, 0x58, 0x24, 0x88, 0x81, 0x60, 0x83, 0x00, 0x1b, 0x04, 0x01,
+	0x8c, 0x45, 0xb0, 0xb7, 0x5c, 0xf2, 0x01, 0x02, 0x24, 0x97, 0x1c, 0x92, 0x43, 0x0e, 0x39, 0x05,
+	0x49, 0x80, 0x7c, 0x81, 0x1c, 0xf3, 0x21, 0x82, 0x57, 0x55, 0x4d, 0x76, 0x37, 0x49, 0x51, 0x52,
+	0x1c, 0x64, 0x4f, 0xaa, 0x7a, 0xef, 0xf1, 0xfd, 0xaf, 0x57, 0x55, 0xaf, 0x5a, 0x00, 0xcc, 0x3e,
+	0x75, 0x96, 0xda, 0x9e, 0xeb, 0xbb, 0x24, 0x71, 0xbe, 0x5e, 0x9a, 0x3f, 0x75, 0xdd, 0xd3, 0x26,
+	0x5d, 0x36, 0xdb, 0xf6, 0xb2, 0xe9, 0x38, 0xae, 0x6f, 0xfa, 0xb6, 0xeb, 0x30, 0x41, 0x51, 0xba,
+	0x2b, 0xb1, 0x7c, 0x56, 0xeb, 0x9c, 0x2c, 0xd3, 0x56, 0xdb, 0xbf, 0x94, 0xc8, 0xf9, 0x38, 0x92,
+	0xf9, 0x5e, 0xc7, 0xf2, 0x25, 0xf6, 0x5e, 0x1c, 0xfb, 0xb5, 0x67, 0xb6, 0xdb, 0xd4, 0x93, 0xac,
+	0xb5, 0x7f, 0x51, 0x60, 0x62, 0x8f, 0x5e, 0x1e, 0x50, 0xdf, 0x24, 0xf7, 0x00, 0xec, 0x3a, 0x75,
+	0x7c, 0xfb, 0xc4, 0xa6, 0x5e, 0x51, 0x59, 0x50, 0x1e, 0x64, 0xf4, 0x10, 0x84, 0x2c, 0xc0, 0xe4,
+	0x89, 0xed, 0x9c, 0x52, 0xaf, 0xed, 0xd9, 0x8e, 0x5f, 0x4c, 0x70, 0x82, 0x30, 0x88, 0x3c, 0x84,
+	0xf1, 0x13, 0xd7, 0x6b, 0x99, 0x7e, 0x31, 0xb9, 0xa0, 0x3c, 0xc8, 0xad, 0xcd, 0x2c, 0x9d, 0xaf,
+	0x2f, 0x1d, 0x76, 0x6a, 0x4d, 0xdb, 0xda, 0xa3, 0x97, 0x2f, 0x39, 0x4a, 0x97, 0x24, 0xe4, 0x43,
+	0x18, 0x6f, 0x50, 0xb3, 0xe9, 0x37, 0x8a, 0x63, 0x9c, 0x78, 0x0a, 0x89, 0xf7, 0xe8, 0xe5, 0x2e,
+	0x07, 0xea, 0x12, 0x49, 0x96, 0x61, 0xc6, 0x76, 0xac, 0x66, 0xa7, 0x4e, 0x0d, 0x8b, 0x7a, 0xa8,
+	0x8a, 0x65, 0xfa, 0xb4, 0x98, 0x5a, 0x50, 0x1e, 0xa4, 0x75, 0x22, 0x51, 0x5b, 0x3d, 0x8c, 0xf6,
+	0x10, 0xd2, 0xd2, 0x22, 0x46, 0xee, 0xc3, 0xd8, 0x19, 0xbd, 0x64, 0x45, 0x65, 0x21, 0xf9, 0x60,
+	0x72, 0x6d, 0x52, 0x4a, 0x40, 0x9c, 0xce, 0x11, 0x9a, 0x07, 0x19, 0xd4, 0xcc, 0x6e, 0xfa, 0xd4,
+	0x23, 0x1f, 0x41, 0xfa, 0x8c, 0x5e, 0x1a, 0xfe, 0x65, 0x9b, 0x72, 0xf3, 0x73, 0xdd, 0x5f, 0x1c,
+	0x5d, 0xb6, 0xa9, 0x3e, 0x71, 0x26, 0x06, 0x64, 0x16, 0xc6, 0x7d, 0xea, 0x98, 0x5d, 0x1f, 0xc8,
+	0x19, 0xf9, 0x10, 0x72, 0x81, 0xaa, 0xd2, 0xb2, 0x24, 0xd7, 0x72, 0x4a, 0x42, 0x85, 0x65, 0xda,
+	0xdf, 0x8d, 0xc1, 0x7c, 0xb5, 0xba, 0x1b, 0xd2, 0xb9, 0x6a, 0x9f, 0x3a, 0xb6, 0x73, 0xaa, 0xd3,
+	0xaf, 0x3a, 0x94, 0xf9, 0x81, 0x1e, 0x2d, 0xea, 0x9b, 0x5c, 0x8f, 0x98, 0xe6, 0x13, 0x67, 0x62,
+	0x80, 0x01, 0x43, 0xbf, 0x5b, 0x76, 0xdb, 0x6c, 0xb2, 0x62, 0x62, 0x21, 0x89, 0x01, 0xeb, 0x41,
+	0xc8, 0x7b, 0x00, 0x6d, 0xee, 0x7c, 0xe3, 0x8c, 0x5e, 0x72, 0x5d, 0x32, 0x7a, 0xa6, 0x1d, 0x84,
+	0x83, 0x94, 0x20, 0x7d, 0x6e, 0x36, 0xed, 0xba, 0xed, 0x5f, 0xf2, 0x10, 0x8c, 0xe9, 0xdd, 0x39,
+	0xb9, 0x03, 0xe3, 0xa8, 0x82, 0x5d, 0xe7, 0x8e, 0xce, 0xe8, 0xa9, 0x33, 0x7a, 0xf9, 0xaa, 0x4e,
+	0x7e, 0x1f, 0x54, 0xcb, 0xb3, 0x7d, 0xdb, 0x32, 0x9b, 0x86, 0xdb, 0xe6, 0x39, 0x5a, 0x1c, 0xe7,
+	0xbe, 0xdd, 0x40, 0x0d, 0xaf, 0xb2, 0x6a, 0x69, 0x4b, 0xfe, 0xf0, 0x8d, 0xf8, 0x5d, 0xc5, 0xf1,
+	0xbd, 0x4b, 0x3d, 0x6f, 0x45, 0xa1, 0xe4, 0x10, 0x80, 0x5e, 0xf8, 0xd4, 0x61, 0x9c, 0xf7, 0x04,
+	0xe7, 0xbd, 0x32, 0x92, 0x77, 0xa5, 0xfb, 0x13, 0xc1, 0x36, 0xc4, 0x03, 0xbd, 0xe0, 0x51, 0xbf,
+	0xe3, 0x39, 0x86, 0x5f, 0x63, 0xc5, 0x34, 0x8f, 0x48, 0x46, 0x40, 0x8e, 0x6a, 0x8c, 0x3c, 0x80,
+	0x74, 0xdb, 0xb3, 0x5d, 0x0f, 0xbd, 0x90, 0xe1, 0x41, 0xcf, 0xf2, 0xac, 0x95, 0x30, 0xbd, 0x8b,
+	0x2d, 0x6d, 0x42, 0x61, 0x90, 0x0d, 0x44, 0x85, 0x24, 0xfa, 0x57, 0x2c, 0x18, 0x1c, 0x92, 0x02,
+	0xa4, 0xce, 0xcd, 0x66, 0x87, 0xca, 0xfc, 0x10, 0x93, 0x17, 0x89, 0x67, 0x4a, 0xe9, 0xc7, 0x90,
+	0x8f, 0xe9, 0x7a, 0x93, 0x9f, 0x6b, 0xbf, 0x84, 0xf1, 0x6a, 0x75, 0x77, 0x8f, 0x0e, 0xfa, 0xd5,
+	0xe8, 0xe5, 0xa9, 0x42, 0x12, 0x5d, 0x80, 0x89, 0x90, 0xd5, 0x71, 0x48, 0x1e, 0xc3, 0x84, 0x47,
+	0x2d, 0x6a, 0xb7, 0x7d, 0x9e, 0x01, 0x93, 0x62, 0xc5, 0xbe, 0x62, 0xac, 0x63, 0x3a, 0x16, 0xd5,
+	0x05, 0x4a, 0x0f, 0x68, 0xb4, 0xaf, 0x20, 0x1f, 0xc3, 0x91, 0x22, 0x4c, 0xb4, 0xcd, 0xcb, 0xa6,
+	0x6b, 0xd6, 0xb9, 0x2e, 0x59, 0x3d, 0x98, 0x92, 0x79, 0xc8, 0x60, 0x95, 0x33, 0xfd, 0x8e, 0x17,
+	0x58, 0xd2, 0x03, 0x44, 0x72, 0x3c, 0x39, 0x3c, 0xc7, 0xb5, 0x7f, 0x4f, 0xc0, 0x7b, 0x3f, 0xdd,
+	0x58, 0x79, 0xfe, 0x3f, 0x5f, 0x2d, 0x2a, 0x24, 0x2d, 0xe6, 0x49, 0x4d, 0x70, 0x18, 0x59, 0x00,
+	0xc9, 0xd8, 0x02, 0xd0, 0x60, 0x8a, 0x5e, 0xf8, 0xb8, 0x70, 0x8c, 0x0e, 0x33, 0x4f, 0x69, 0x71,
+	0x6c, 0x21, 0xf9, 0x20, 0xa5, 0x4f, 0xd2, 0x0b, 0x7f, 0x8f, 0x5e, 0x1e, 0x23, 0x28, 0x96, 0x59,
+	0xa9, 0xab, 0x32, 0x6b, 0xfc, 0xaa, 0xcc, 0xc2, 0x82, 0x62, 0x33, 0xd6, 0xa1, 0x5e, 0x71, 0x42,
+	0x14, 0x14, 0x31, 0xe3, 0xce, 0xf5, 0xdc, 0x13, 0xbb, 0x49, 0x79, 0xde, 0x66, 0xf4, 0x60, 0x4a,
+	0x3e, 0x85, 0x6c, 0xcb, 0xbc, 0x30, 0xda, 0xa6, 0xdf, 0x30, 0x9a, 0xd4, 0xe1, 0x99, 0x3b, 0xb9,
+	0x36, 0xbf, 0x24, 0xca, 0xfd, 0x52, 0x50, 0xee, 0x97, 0x8e, 0x5f, 0x39, 0xfe, 0xfa, 0xda, 0x5b,
+	0x4c, 0x20, 0x1d, 0x5a, 0xe6, 0xc5, 0xa1, 0xe9, 0x37, 0xf6, 0xa9, 0xa3, 0xfd, 0xa3, 0x02, 0xf9,
+	0x98, 0x5b, 0x09, 0x81, 0x31, 0xac, 0xb0, 0x32, 0xa7, 0xf8, 0xf8, 0x1a, 0x49, 0xf5, 0x31, 0xe4,
+	0xfd, 0x1a, 0x8b, 0xd4, 0x66, 0x91, 0x60, 0x39, 0xbf, 0xc6, 0xc2, 0xec, 0x6f, 0x96, 0x6b, 0xe4,
+	0x7d, 0xc8, 0x0a, 0x2f, 0x18, 0x56, 0xc3, 0xb4, 0x9d, 0x62, 0x8a, 0x97, 0xb7, 0x49, 0x01, 0xdb,
+	0x42, 0x90, 0x56, 0x87, 0x7b, 0xdc, 0x86, 0x72, 0x48, 0xcc, 0xe1, 0xde, 0x56, 0x75, 0x75, 0xed,
+	0xa6, 0xb9, 0x51, 0x82, 0x74, 0xdb, 0x64, 0xec, 0x6b, 0xd7, 0xab, 0x4b, 0x1b, 0xbb, 0x73, 0x6d,
+	0x01, 0xc6, 0x05, 0x53, 0x0c, 0x53, 0xfb, 0xcc, 0x62, 0xab, 0x6b, 0x32, 0xd5, 0xe5, 0x4c, 0xfb,
+	0xd3, 0x31, 0x98, 0x8d, 0x39, 0xf3, 0xd0, 0xa3, 0xe7, 0x36, 0xfd, 0x1a, 0x23, 0xc8, 0x3a, 0xb5,
+	0x9f, 0x53, 0x2b, 0x70, 0x6b, 0x30, 0x0d, 0xc5, 0x3c, 0x11, 0x89, 0xf9, 0x7b, 0x00, 0x8e, 0xeb,
+	0x1b, 0x35, 0x7a, 0xe2, 0x7a, 0xc2, 0x95, 0x49, 0x3d, 0xe3, 0xb8, 0xfe, 0x26, 0x07, 0x90, 0xbb,
+	0x80, 0x13, 0xc3, 0x3c, 0xf1, 0xa9, 0xc7, 0xfd, 0x98, 0xd4, 0xd3, 0x8e, 0xeb, 0x97, 0x71, 0x4e,
+	0x56, 0xa0, 0xd0, 0x2b, 0xf8, 0x86, 0xd9, 0x3c, 0xc5, 0xf4, 0x6a, 0xb4, 0x64, 0x0d, 0x27, 0xdd,
+	0xd2, 0x5f, 0x0e, 0x30, 0xc8, 0xae, 0xee, 0x30, 0xc3, 0x31, 0x5b, 0x54, 0x54, 0xf2, 0x8c, 0x9e,
+	0xae, 0x3b, 0xec, 0x35, 0xce, 0x79, 0x08, 0xda, 0x86, 0x59, 0xaf, 0x7b, 0x94, 0x31, 0x2a, 0xaa,
+	0x31, 0x86, 0xa0, 0x5d, 0x0e, 0x40, 0x18, 0x7d, 0xda, 0x32, 0xed, 0x66, 0x88, 0x2a, 0xcd, 0xa9,
+	0x72, 0x1c, 0xdc, 0x23, 0x24, 0x30, 0xd6, 0xf1, 0x6c, 0x56, 0xcc, 0x70, 0x2c, 0x1f, 0xa3, 0xf0,
+	0xde, 0xfa, 0x02, 0x21, 0xfc, 0x2c, 0x58, 0x5c, 0x7d, 0x0b, 0x70, 0xb2, 0x7f, 0x01, 0x3e, 0x85,
+	0x39, 0xcb, 0x6b, 0x1a, 0x75, 0x9b, 0xf9, 0x9e, 0x5d, 0xeb, 0x60, 0x4d, 0x36, 0xda, 0xae, 0xed,
+	0xf8, 0xac, 0x98, 0xe5, 0xec, 0xee, 0x58, 0x5e, 0x73, 0x3b, 0x84, 0x3d, 0xe4, 0x48, 0x34, 0xcc,
+	0xb5, 0x58, 0xdb, 0x60, 0xd4, 0x3b, 0xa7, 0x1e, 0x2b, 0x4e, 0x09, 0xc3, 0x10, 0x56, 0x15, 0x20,
+	0xf2, 0x0c, 0x8a, 0x18, 0x10, 0xdb, 0x39, 0x0d, 0xa7, 0xb6, 0xd1, 0xf1, 0x9a, 0xac, 0x98, 0xe3,
+	0xe4, 0xb3, 0x12, 0x1f, 0x8a, 0xfa, 0xb1, 0xd7, 0x64, 0xda, 0x11, 0xa8, 0x47, 0x76, 0x8b, 0x32,
+	0xdf, 0x6c, 0xb5, 0x6f, 0x9a, 0x87, 0x45, 0x5c, 0x23, 0xfc, 0x27, 0x3c, 0x2b, 0xb2, 0x7a, 0x30,
+	0xd5, 0x96, 0x61, 0x3a, 0xc4, 0x95, 0xb5, 0x5d, 0x87, 0x51, 0x4c, 0x5b, 0x4f, 0x8e, 0x65, 0x4a,
+	0x76, 0xe7, 0xda, 0x31, 0x4c, 0xef, 0xd8, 0xfe, 0x2d, 0x6b, 0x65, 0xa8, 0xaa, 0x27, 0x22, 0x55,
+	0x5d, 0x7b, 0x04, 0x59, 0xc9, 0x56, 0xd4, 0xf1, 0x48, 0x95, 0x57, 0x62, 0x55, 0x5e, 0xfb, 0x4e,
+	0x81, 0xc2, 0xf6, 0xeb, 0x6a, 0xb5, 0xb2, 0x75, 0x4b, 0x45, 0xde, 0x87, 0x2c, 0x13, 0xbf, 0x34,
+	0xea, 0xa6, 0x6f, 0x4a, 0x6d, 0x26, 0x25, 0x6c, 0xdb, 0xf4, 0x4d, 0xb2, 0x0e, 0xb9, 0x86, 0xc9,
+	0x1a, 0xa1, 0x74, 0x4f, 0xf6, 0x8a, 0xed, 0xae, 0xc9, 0x1a, 0x98, 0xed, 0xfa, 0x54, 0x43, 0x8e,
+	0x38, 0x89, 0x76, 0x00, 0xf9, 0x9e, 0x5e, 0x43, 0x2c, 0xc9, 0x86, 0xf7, 0xab, 0x79, 0xc8, 0xf4,
+	0x04, 0xa0, 0x16, 0x53, 0x7a, 0x0f, 0xa0, 0xfd, 0x56, 0x81, 0x77, 0xcb, 0xbe, 0x8f, 0xe1, 0xc1,
+	0x34, 0xbb, 0xa5, 0xb1, 0x8f, 0x81, 0x98, 0x1d, 0xbf, 0x41, 0x1d, 0x3c, 0x63, 0xf8, 0xae, 0x17,
+	0x36, 0x79, 0x3a, 0x82, 0xe1, 0x86, 0x3f, 0x00, 0xd5, 0x6a, 0xda, 0xd4, 0xf1, 0x39, 0x9d, 0x81,
+	0x06, 0x06, 0xa5, 0x57, 0xc0, 0x91, 0x0a, 0x1d, 0xa0, 0xed, 0x43, 0x21, 0xac, 0x9d, 0x6f, 0xfa,
+	0xb4, 0x45, 0xc5, 0x81, 0xc0, 0x6c, 0x9e, 0x72, 0x9d, 0x92, 0x3a, 0x0e, 0x11, 0xc2, 0xec, 0x53,
+	0x29, 0x13, 0x87, 0x08, 0xb9, 0xd8, 0xb0, 0x8a, 0xc9, 0x85, 0x24, 0x42, 0x2e, 0x36, 0x2c, 0xed,
+	0x6f, 0x14, 0x98, 0xdf, 0x72, 0x1d, 0xdf, 0xb4, 0x1d, 0xea, 0xbd, 0x6a, 0x99, 0xa7, 0xf4, 0xfb,
+	0xce, 0x32, 0xf2, 0x09, 0xa8, 0x75, 0xd7, 0x3a, 0xa3, 0x9e, 0xe1, 0xd1, 0x13, 0xea, 0x51, 0xc7,
+	0xa2, 0xf2, 0xfc, 0x9a, 0x17, 0x70, 0x3d, 0x00, 0x63, 0x05, 0x6a, 0x99, 0x8e, 0x7d, 0x42, 0x99,
+	0x6f, 0xd4, 0xed, 0x53, 0x5c, 0x3a, 0x63, 0x9c, 0x32, 0x17, 0x80, 0xb7, 0x39, 0x54, 0x6b, 0xc3,
+	0x5c, 0xbf, 0xd6, 0x22, 0xb8, 0xb7, 0x3d, 0xc4, 0x5c, 0x7d, 0xc0, 0xd6, 0x3e, 0x83, 0x4c, 0xf7,
+	0xf2, 0x33, 0xf8, 0xc0, 0x16, 0xde, 0x35, 0xe5, 0xde, 0x1a, 0x02, 0x69, 0xff, 0x9a, 0x04, 0xb2,
+	0xd9, 0x74, 0x6b, 0xb7, 0xf4, 0xef, 0x2c, 0x8c, 0x4b, 0x8f, 0xc8, 0x2d, 0x46, 0xcc, 0x6e, 0xb5,
+	0x62, 0xc8, 0xa7, 0xa0, 0x76, 0x0d, 0x37, 0x98, 0xd5, 0xa0, 0x2d, 0x2a, 0x2f, 0x6e, 0x7c, 0x1f,
+	0xef, 0x3a, 0xb3, 0xca, 0x51, 0x7a, 0x9e, 0x45, 0x01, 0xe8, 0x63, 0xcb, 0x75, 0x7c, 0x7a, 0xe1,
+	0xcb, 0xed, 0x28, 0x98, 0xde, 0xe0, 0x9c, 0xf4, 0x02, 0x66, 0x2c, 0xd7, 0x40, 0xce, 0xd4, 0x33,
+	0x02, 0x17, 0x04, 0xb7, 0x84, 0x88, 0x0f, 0x54, 0xcb, 0xad, 0x72, 0xb2, 0xee, 0x55, 0xf0, 0x27,
+	0x50, 0x68, 0x9b, 0x9e, 0x6f, 0x9b, 0x4d, 0xc3, 0x3c, 0x37, 0xed, 0xa6, 0x59, 0xb3, 0x9b, 0x28,
+	0x31, 0xcd, 0x25, 0xce, 0x71, 0x89, 0x02, 0x5f, 0x0e, 0xa1, 0xf5, 0x99, 0x76, 0x3f, 0x90, 0xfc,
+	0x08, 0xf2, 0xc2, 0x95, 0x06, 0x75, 0x2c, 0xb7, 0x6e, 0x3b, 0xa7, 0xf2, 0xea, 0x40, 0x90, 0x8d,
+	0xc8, 0xb7, 0x8a, 0xc4, 0xe8, 0xb9, 0x7a, 0x64, 0xae, 0xfd, 0x0c, 0x72, 0x18, 0xd3, 0x4d, 0xd3,
+	0xb7, 0x1a, 0xe2, 0x06, 0xd0, 0x8b, 0x93, 0x32, 0x22, 0x4e, 0x89, 0xd1, 0x95, 0xed, 0xd7, 0x09,
+	0x98, 0xeb, 0xf2, 0xbf, 0x65, 0xe2, 0x3c, 0x82, 0x09, 0xea, 0xf8, 0x9e, 0x4d, 0xc5, 0xad, 0x72,
+	0x52, 0xd8, 0x15, 0xd5, 0x5a, 0x0f, 0x48, 0xfe, 0x6f, 0xd2, 0x29, 0x9c, 0x34, 0xa9, 0xab, 0x92,
+	0x46, 0xdb, 0x80, 0x99, 0x88, 0x3f, 0x38, 0x17, 0x86, 0x97, 0xe7, 0x2e, 0x4f, 0xd1, 0x20, 0xc8,
+	0xe8, 0x21, 0x88, 0xf6, 0x0b, 0x98, 0x8b, 0x1a, 0xdc, 0xfd, 0x2d, 0xde, 0xcf, 0x6c, 0xa7, 0x4e,
+	0x2f, 0xb8, 0x0f, 0xa7, 0x74, 0x31, 0x19, 0x51, 0x2a, 0xf0, 0x70, 0xed, 0xd6, 0x45, 0x15, 0x4b,
+	0xe9, 0x7c, 0x8c, 0x4b, 0xa2, 0x45, 0x99, 0xbc, 0x5d, 0xf0, 0x25, 0x21, 0xa7, 0x5a, 0x0b, 0xde,
+	0x8f, 0xde, 0x77, 0xdf, 0x52, 0x4f, 0x8c, 0x6c, 0xd7, 0xb9, 0x69, 0x34, 0x47, 0xd7, 0x99, 0x7f,
+	0x56, 0xa0, 0x34, 0x5c, 0x1e, 0x96, 0xd8, 0x5e, 0xac, 0xf8, 0x0d, 0x89, 0xcb, 0x4b, 0xeb, 0xb9,
+	0x2e, 0xf8, 0x2d, 0x42, 0x91, 0xf0, 0x6b, 0xdb, 0x6f, 0xd8, 0x8e, 0xd1, 0xbd, 0x57, 0x25, 0x04,
+	0xa1, 0x00, 0xbf, 0x95, 0x50, 0x72, 0x1f, 0x26, 0x39, 0x85, 0x3c, 0xc7, 0x8a, 0xcb, 0x17, 0x70,
+	0x90, 0x38, 0xc9, 0xbe, 0x0f, 0x59, 0x41, 0x20, 0xcf, 0xc1, 0xa2, 0x3f, 0x21, 0x7e, 0x24, 0x4f,
+	0xc2, 0xb3, 0x30, 0xee, 0x51, 0x93, 0xb9, 0x8e, 0xac, 0x27, 0x72, 0xa6, 0xfd, 0x4a, 0x81, 0xe9,
+	0xad, 0x83, 0xea, 0xef, 0x40, 0xcd, 0xd4, 0x16, 0x20, 0x2b, 0x35, 0x11, 0x49, 0x80, 0x57, 0xd0,
+	0x16, 0x0b, 0xf6, 0x00, 0xab, 0xc5, 0xb4, 0x5f, 0x2b, 0x30, 0x57, 0x69, 0x63, 0x46, 0x7b, 0x66,
+	0xf3, 0x77, 0x41, 0xe5, 0xff, 0x0f, 0x24, 0xa2, 0xcf, 0x35, 0x4e, 0x79, 0xb1, 0x6d, 0x30, 0x11,
+	0xdf, 0x06, 0xff, 0x4a, 0x81, 0xc2, 0x11, 0xef, 0x90, 0xdd, 0xde, 0xc0, 0x81, 0xfd, 0xb6, 0x9e,
+	0xe1, 0xc9, 0x11, 0x86, 0x8f, 0x8d, 0x36, 0xfc, 0x35, 0xe4, 0x7b, 0x4a, 0x7e, 0x0f, 0x56, 0xff,
+	0x93, 0x02, 0xd3, 0x7c, 0xef, 0xf6, 0x3d, 0x6a, 0xb6, 0x6e, 0x6a, 0xf2, 0x6d, 0x4a, 0xff, 0xc0,
+	0x9a, 0x9a, 0xbc, 0x41, 0x4d, 0x2d, 0x40, 0xca, 0x6a, 0x74, 0x9c, 0x33, 0xee, 0xae, 0xac, 0x2e,
+	0x26, 0xda, 0x1f, 0x2a, 0x30, 0xd3, 0x33, 0xe4, 0xba, 0xde, 0xf9, 0x5e, 0x93, 0xf2, 0x4b, 0xc8,
+	0x5c, 0x57, 0xee, 0x4a, 0xa4, 0xac, 0x8b, 0xdd, 0x4b, 0x95, 0x2e, 0xee, 0xf2, 0x88, 0x14, 0xfa,
+	0x1a, 0x64, 0xc3, 0xb8, 0x91, 0x6d, 0xf0, 0xab, 0xeb, 0x7c, 0x01, 0x52, 0xd4, 0xf3, 0x5c, 0x4f,
+	0xa6, 0xa4, 0x98, 0x68, 0x2f, 0x21, 0x57, 0x71, 0xea, 0xfc, 0x6a, 0x8a, 0xa7, 0xef, 0x0e, 0xc3,
+	0xab, 0x1b, 0x95, 0x10, 0x29, 0xa3, 0x3b, 0xc7, 0x7d, 0x81, 0x3a, 0x66, 0xad, 0x49, 0xeb, 0xb2,
+	0x7c, 0x06, 0x53, 0xed, 0x0f, 0xa0, 0xb0, 0x65, 0x7b, 0x56, 0xc7, 0xf6, 0x37, 0x3d, 0x6a, 0x9e,
+	0x51, 0x4f, 0x72, 0x1b, 0xa5, 0x73, 0x01, 0x52, 0x78, 0xf8, 0xef, 0x76, 0x14, 0xf9, 0x84, 0xac,
+	0x42, 0xc1, 0xc2, 0xbb, 0xa2, 0xd5, 0xf1, 0xed, 0x73, 0x6a, 0x9c, 0x98, 0x76, 0x93, 0x7b, 0x2d,
+	0xc9, 0xb7, 0xb5, 0x99, 0x10, 0xee, 0xa5, 0x44, 0x69, 0xdf, 0x2a, 0x00, 0xe2, 0x8a, 0xfc, 0xca,
+	0x39, 0x71, 0xc9, 0x0a, 0x64, 0x02, 0xad, 0x83, 0x26, 0x3b, 0x3f, 0x2a, 0x44, 0x8d, 0xd5, 0x7b,
+	0x44, 0x64, 0x0b, 0x54, 0x4b, 0x58, 0x60, 0xd4, 0x84, 0x09, 0x41, 0x94, 0x8a, 0xf8, 0xc3, 0x41,
+	0xd6, 0xe9, 0x79, 0x2b, 0x02, 0x65, 0xda, 0x5f, 0x27, 0x20, 0x17, 0x6a, 0x1c, 0xb9, 0x5e, 0x1d,
+	0xf7, 0xd7, 0x6e, 0xdf, 0x3e, 0xa3, 0xf3, 0x71, 0xcc, 0x2b, 0x89, 0x3e, 0xaf, 0xcc, 0xc2, 0x38,
+	0xa3, 0x9e, 0x6d, 0x36, 0x83, 0xfa, 0x21, 0x66, 0xe1, 0xa6, 0xcd, 0x58, 0xb4, 0x69, 0x33, 0xa4,
+	0x2d, 0x1e, 0x6d, 0xc4, 0x8f, 0xf7, 0x35, 0xe2, 0xef, 0x42, 0x86, 0x77, 0x77, 0xea, 0x86, 0xe9,
+	0xf3, 0x16, 0x5f, 0x52, 0x4f, 0x0b, 0x40, 0xd9, 0x8f, 0x35, 0x7c, 0xd2, 0x57, 0x36, 0x7c, 0x32,
+	0xb1, 0x86, 0x4f, 0xac, 0x3d, 0x07, 0x7d, 0xed, 0x39, 0xad, 0x01, 0x73, 0x81, 0xa7, 0x0e, 0xe4,
+	0x7d, 0x28, 0xa8, 0x45, 0x68, 0x26, 0x65, 0xd8, 0x89, 0xee, 0xf6, 0xa6, 0xc4, 0x94, 0xac, 0xc7,
+	0xcf, 0x7f, 0xef, 0x86, 0x5b, 0x75, 0x01, 0x9f, 0xe8, 0x31, 0x50, 0xfb, 0x0a, 0xee, 0x0c, 0xa4,
+	0x18, 0x99, 0x9c, 0xbd, 0x30, 0x24, 0x22, 0x61, 0x88, 0x19, 0x97, 0xec, 0x37, 0xee, 0xb3, 0x48,
+	0x3f, 0xda, 0xf5, 0xea, 0x0c, 0x8f, 0xae, 0x9e, 0x18, 0x86, 0xf3, 0x31, 0x4a, 0xa5, 0x07, 0x24,
+	0xda, 0xdf, 0x2a, 0x30, 0x15, 0x74, 0x93, 0x30, 0xd9, 0xae, 0xb7, 0x92, 0xec, 0x53, 0x87, 0x71,
+	0x5d, 0xc7, 0x74, 0x31, 0x41, 0x13, 0xf8, 0x42, 0x67, 0xf2, 0x28, 0x23, 0x67, 0x18, 0xbc, 0xa6,
+	0xc9, 0x7c, 0xa3, 0xc3, 0x68, 0x3d, 0xe8, 0xd6, 0x21, 0xe0, 0x98, 0x51, 0xcc, 0x9a, 0xc9, 0xb6,
+	0xeb, 0x36, 0x0d, 0xdb, 0x41, 0x3c, 0xcf, 0xa8, 0x94, 0x9e, 0x41, 0xd0, 0x2b, 0xe7, 0x98, 0xf1,
+	0xc8, 0x73, 0x3c, 0xb3, 0xbf, 0xa1, 0xfc, 0x62, 0x94, 0xd2, 0xd3, 0x08, 0xa8, 0xda, 0xdf, 0x50,
+	0xed, 0x05, 0x4c, 0x47, 0x14, 0xdf, 0xb7, 0x19, 0x3e, 0x40, 0x85, 0x9f, 0xbb, 0xa6, 0x65, 0xd9,
+	0xeb, 0x11, 0xc9, 0x47, 0xaf, 0xff, 0x50, 0xa0, 0xb0, 0x47, 0x2f, 0x77, 0xa8, 0x43, 0xbd, 0x5b,
+	0x9d, 0x28, 0xef, 0xc3, 0x24, 0x6b, 0xba, 0xbe, 0xe1, 0x74, 0x5a, 0x35, 0xb9, 0xb2, 0xa6, 0x74,
+	0x40, 0xd0, 0x6b, 0x0e, 0x09, 0x3a, 0x7b, 0x4d, 0xb3, 0x46, 0x83, 0xc5, 0x85, 0x9c, 0xf7, 0x71,
+	0x1e, 0x79, 0x66, 0x1b, 0xbb, 0xe2, 0x99, 0xed, 0x5d, 0x41, 0xc7, 0xcd, 0x4f, 0x71, 0x11, 0x88,
+	0x42, 0xeb, 0xd1, 0xdf, 0x2d, 0xb7, 0xde, 0x69, 0x0a, 0xbf, 0x64, 0x74, 0x39, 0xd3, 0x8e, 0x21,
+	0x2b, 0xad, 0xa2, 0x75, 0xbc, 0x74, 0x5f, 0xd7, 0xa0, 0x11, 0x7b, 0xf9, 0x5b, 0x50, 0x75, 0x8a,
+	0xfd, 0x00, 0x5a, 0xaf, 0x8a, 0x25, 0xc2, 0x6e, 0xd2, 0x5a, 0x96, 0xcb, 0x8a, 0x49, 0x47, 0x75,
+	0xe7, 0xda, 0x5f, 0x2a, 0x90, 0xdd, 0xad, 0x1e, 0x1c, 0x50, 0xab, 0x61, 0x3a, 0x36, 0x6b, 0x61,
+	0x15, 0xc3, 0x56, 0x6c, 0x50, 0xc5, 0x70, 0x1c, 0x7d, 0x0d, 0x9a, 0x92, 0xaf, 0x41, 0x64, 0x01,
+	0xb2, 0x2d, 0xdb, 0x31, 0xba, 0x0e, 0x12, 0x35, 0x1b, 0x5a, 0xb6, 0xb3, 0x27, 0x7d, 0xb4, 0x20,
+	0x9e, 0x08, 0xba, 0x14, 0x63, 0x92, 0xc2, 0xbc, 0x08, 0x28, 0xe6, 0x21, 0x73, 0xd2, 0x71, 0x2c,
+	0xf1, 0x8c, 0x27, 0xfa, 0xeb, 0x3d, 0x80, 0xf6, 0xe7, 0x0a, 0xe4, 0xaa, 0x4d, 0xd7, 0xef, 0x6a,
+	0xc7, 0x42, 0x6e, 0x57, 0xc2, 0x6e, 0x1f, 0x9d, 0x0f, 0x2b, 0x00, 0xad, 0x2e, 0x9b, 0x62, 0xb2,
+	0xb7, 0x2b, 0x87, 0xad, 0xd7, 0x43, 0x34, 0xbd, 0x7d, 0x74, 0x2c, 0xbc, 0x8f, 0x7e, 0x0a, 0x24,
+	0xaa, 0x12, 0x4f, 0xfb, 0x07, 0x90, 0x42, 0x59, 0x91, 0x15, 0x1f, 0x25, 0xd3, 0x05, 0x81, 0xb6,
+	0x09, 0xf9, 0xca, 0xc9, 0x09, 0xb5, 0x70, 0x4f, 0xdb, 0x72, 0x9d, 0x13, 0xfb, 0x94, 0x2c, 0xc3,
+	0xb8, 0xc5, 0x47, 0x32, 0x8a, 0x73, 0x7d, 0x6f, 0x28, 0x55, 0xfe, 0xa0, 0xae, 0x4b, 0x32, 0xed,
+	0x1f, 0x92, 0x90, 0xdf, 0xa3, 0x97, 0x5b, 0x66, 0x5b, 0xb4, 0x03, 0x6c, 0x7a, 0xfd, 0x64, 0x08,
+	0xa7, 0x7e, 0xe2, 0x9a, 0xa9, 0x2f, 0x6e, 0x8c, 0xdd, 0xd4, 0xdf, 0x80, 0x7c, 0xf4, 0x00, 0xc5,
+	0xf8, 0xd3, 0x54, 0xfc, 0x04, 0x95, 0x8b, 0x9c, 0xa0, 0x18, 0xf9, 0x7f, 0x30, 0x1d, 0x3f, 0x1b,
+	0x8a, 0x98, 0x0f, 0x39, 0x1c, 0xaa, 0xb1, 0xc3, 0x21, 0xc3, 0x9e, 0x9c, 0xdb, 0xf1, 0xdb, 0x9d,
+	0x5e, 0xd3, 0x23, 0xd8, 0xea, 0xf2, 0x02, 0x1e, 0x74, 0x38, 0xb0, 0x88, 0x4e, 0x32, 0xd6, 0xc0,
+	0xaa, 0xe6, 0x19, 0x96, 0xc9, 0x77, 0xbc, 0xb4, 0x9e, 0x61, 0xac, 0x71, 0xcc, 0xa8, 0xb7, 0x65,
+	0x06, 0xf8, 0x86, 0xcb, 0x7c, 0xc4, 0xa7, 0xbb, 0xf8, 0x5d, 0x97, 0xf9, 0x5b, 0x26, 0x99, 0x83,
+	0x89, 0x8b, 0x8d, 0x95, 0xe7, 0x88, 0xcb, 0x70, 0xdc, 0x38, 0x4e, 0xb7, 0x78, 0x3b, 0xb8, 0xd6,
+	0x74, 0x6b, 0x86, 0xec, 0xff, 0xf2, 0x0d, 0x2f, 0xad, 0x4f, 0xd6, 0x7a, 0x3d, 0x32, 0xf2, 0x01,
+	0x4c, 0xb5, 0x4d, 0xcf, 0x6c, 0x51, 0x9f, 0x7a, 0x06, 0xa3, 0x7e, 0x71, 0x92, 0x27, 0x50, 0xb6,
+	0x0b, 0xac, 0x52, 0x7f, 0x51, 0xe7, 0xcf, 0xfe, 0xe2, 0x3d, 0x9e, 0xbc, 0x0b, 0x77, 0x8e, 0x1d,
+	0xd6, 0xa6, 0x16, 0x56, 0xf8, 0xba, 0xd1, 0x45, 0xa8, 0xef, 0x90, 0x49, 0x98, 0xd8, 0xad, 0x94,
+	0xf7, 0x8f, 0x76, 0x7f, 0x4f, 0x55, 0x48, 0x16, 0xd2, 0xdb, 0x95, 0x1d, 0xbd, 0xbc, 0x5d, 0xd9,
+	0x56, 0x13, 0x24, 0x0f, 0x93, 0xc7, 0xaf, 0xcb, 0x6f, 0xcb, 0xaf, 0xf6, 0xcb, 0x9b, 0xfb, 0x15,
+	0x35, 0xb9, 0xf8, 0x08, 0xf2, 0xb1, 0x4f, 0x1d, 0xc8, 0x04, 0x24, 0x0f, 0x2b, 0x07, 0xea, 0x3b,
+	0x38, 0xf8, 0xc9, 0xe7, 0x7b, 0xaa, 0x82, 0x83, 0xed, 0x8a, 0xae, 0x26, 0x16, 0x3f, 0x81, 0x74,
+	0xd0, 0xab, 0x20, 0x00, 0xe3, 0xaf, 0xdf, 0xe8, 0x07, 0xe5, 0x7d, 0xf5, 0x1d, 0x92, 0x86, 0xb1,
+	0xdd, 0x57, 0x3b, 0xbb, 0x82, 0x74, 0xff, 0xcd, 0xe7, 0x6a, 0x62, 0xf1, 0x57, 0x0a, 0xa4, 0x83,
+	0xb8, 0x92, 0x02, 0xa8, 0x61, 0x65, 0x11, 0xae, 0xbe, 0x83, 0x1c, 0xaa, 0xbb, 0xe5, 0xb5, 0xb5,
+	0x27, 0xaa, 0x12, 0x8c, 0x37, 0x9e, 0xaa, 0x09, 0x39, 0x5e, 0x7f, 0xf6, 0x44, 0x4d, 0xca, 0xf1,
+	0xc6, 0xea, 0x9a, 0x3a, 0x86, 0xa6, 0x20, 0xdc, 0xc0, 0x5f, 0xa4, 0x7a, 0xb3, 0x8d, 0xa7, 0xea,
+	0x78, 0x77, 0x86, 0xbf, 0x9a, 0xe8, 0xce, 0xf0, 0x77, 0xe9, 0xc5, 0xdf, 0x28, 0x90, 0x8f, 0x65,
+	0x0a, 0xb9, 0x0f, 0x77, 0xc3, 0x1a, 0xc5, 0xd0, 0xea, 0x3b, 0xc8, 0x82, 0x3f, 0xa0, 0x9d, 0xaf,
+	0x6e, 0x08, 0xb3, 0x0e, 0xab, 0x55, 0x35, 0x41, 0x72, 0x00, 0x95, 0xad, 0xed, 0x6a, 0xd9, 0x28,
+	0x57, 0x5f, 0xaf, 0xaa, 0x49, 0x32, 0x05, 0x99, 0x4a, 0x7d, 0x6d, 0x63, 0x63, 0xf5, 0x79, 0xbb,
+	0xa1, 0x8e, 0xa1, 0x7f, 0x05, 0xfa, 0x70, 0x75, 0xfd, 0xe9, 0xba, 0x9a, 0x42, 0x3c, 0x5a, 0x7b,
+	0xb0, 0xbf, 0x5d, 0x2d, 0xab, 0xe3, 0xf8, 0x73, 0x9c, 0x56, 0xf7, 0x77, 0x71, 0x3e, 0xb1, 0x58,
+	0x81, 0x5c, 0xb4, 0xf1, 0x46, 0xee, 0x41, 0x29, 0xac, 0x58, 0x14, 0x2b, 0x9c, 0xb6, 0x59, 0xae,
+	0x56, 0x9e, 0x3e, 0x11, 0x5a, 0xed, 0x56, 0x7e, 0xaa, 0x26, 0x16, 0x3f, 0x87, 0x99, 0x01, 0x6d,
+	0x40, 0xf2, 0x01, 0xdc, 0x0f, 0xf3, 0x1a, 0x40, 0x22, 0xa3, 0x70, 0xa4, 0xbf, 0xda, 0x3a, 0x52,
+	0x15, 0x54, 0x7f, 0xb3, 0x52, 0x3d, 0x32, 0x2a, 0x2f, 0x5f, 0xbe, 0xd1, 0x8f, 0xd4, 0xc4, 0xe2,
+	0x17, 0xfc, 0x43, 0x1b, 0xbe, 0xba, 0xe7, 0x60, 0x26, 0x96, 0x70, 0x08, 0x16, 0x69, 0xa2, 0x57,
+	0xcb, 0xaa, 0x42, 0x32, 0x90, 0xe2, 0xc6, 0xab, 0x09, 0x4c, 0x41, 0xe9, 0x16, 0x35, 0x89, 0x70,
+	0x61, 0xff, 0x18, 0x17, 0x26, 0x6c, 0x4f, 0xad, 0xfd, 0x7d, 0x09, 0x26, 0x82, 0xfc, 0xa7, 0xf0,
+	0xd1, 0x0e, 0xf5, 0x63, 0xcf, 0x91, 0x52, 0xd1, 0x66, 0xd0, 0xa9, 0xdf, 0xa3, 0x97, 0x8c, 0x04,
+	0x1f, 0xdc, 0x88, 0xaf, 0x5f, 0x4a, 0xd9, 0x50, 0xc5, 0x62, 0xda, 0xbd, 0x3f, 0xfa, 0xb7, 0xff,
+	0xfc, 0x8b, 0x44, 0x91, 0xcc, 0x2e, 0x9f, 0xaf, 0x2f, 0x33, 0xfb, 0x74, 0x19, 0x57, 0xe0, 0x63,
+	0x6c, 0x1a, 0x2d, 0xe3, 0x19, 0x82, 0x50, 0x28, 0x04, 0x62, 0xc2, 0xcf, 0xaf, 0x24, 0x5c, 0xf7,
+	0x4a, 0xbc, 0xb2, 0xc4, 0x54, 0xd1, 0x1e, 0x72, 0xce, 0x1f, 0x92, 0x0f, 0x06, 0x73, 0x5e, 0xfe,
+	0x45, 0xef, 0xb4, 0xf5, 0x4b, 0xf2, 0x67, 0x0a, 0xbc, 0x57, 0xb9, 0x68, 0xbb, 0x9e, 0x3f, 0xe4,
+	0xa5, 0x97, 0x68, 0x5d, 0x19, 0x43, 0x9f, 0x81, 0x4b, 0xc0, 0x5b, 0x83, 0x1c, 0xa4, 0x7d, 0xca,
+	0xc5, 0x3f, 0xd3, 0xd6, 0x87, 0x89, 0x0f, 0x0a, 0xf9, 0x52, 0x48, 0x8f, 0x65, 0xf1, 0xd2, 0xfb,
+	0x42, 0x59, 0x24, 0x7f, 0xac, 0xc0, 0xcc, 0xa1, 0xcb, 0xe2, 0x1e, 0x26, 0xef, 0x0f, 0xb0, 0x35,
+	0xda, 0xef, 0x18, 0xec, 0x8e, 0x1f, 0x72, 0x7d, 0x56, 0xb5, 0x47, 0x37, 0xd1, 0x07, 0x15, 0xf9,
+	0x8d, 0x02, 0xb3, 0xf2, 0x99, 0xf9, 0x16, 0xba, 0x94, 0x06, 0x90, 0x48, 0x6e, 0xda, 0x67, 0x5c,
+	0xa5, 0xe7, 0xda, 0x93, 0x9b, 0xb9, 0x48, 0xfc, 0x1a, 0x55, 0x6b, 0xc2, 0x27, 0x3b, 0x14, 0x0f,
+	0xb9, 0x5e, 0xb4, 0xab, 0x78, 0xf3, 0x34, 0xd4, 0xb8, 0x2a, 0xf3, 0xa4, 0x14, 0xa8, 0xc2, 0x58,
+	0xe3, 0x31, 0xee, 0x2b, 0xa1, 0x54, 0x3c, 0x83, 0xfb, 0x03, 0xa5, 0xf5, 0x84, 0x44, 0xb3, 0x12,
+	0xe4, 0xe7, 0x44, 0x78, 0xb2, 0x5b, 0xe6, 0xfc, 0x3f, 0x21, 0x1f, 0x0f, 0xe7, 0x1f, 0x4d, 0xc8,
+	0x6f, 0xd1, 0xeb, 0x2e, 0x1b, 0x20, 0x8e, 0x2c, 0x8c, 0xfa, 0x4c, 0x29, 0x22, 0xf9, 0x47, 0x5c,
+	0xf2, 0x86, 0xb6, 0x72, 0x95, 0xe4, 0x61, 0xb1, 0x17, 0x0e, 0xc6, 0xdd, 0xf2, 0x7f, 0xc5, 0xc1,
+	0xb8, 0x31, 0xf7, 0x39, 0xb8, 0x5f, 0xda, 0xad, 0x1d, 0x1c, 0xe5, 0x3f, 0xd8, 0xc1, 0xfd, 0xe2,
+	0xbe, 0x0f, 0x07, 0xc7, 0x25, 0x0f, 0x73, 0xf0, 0x6f, 0x15, 0x28, 0xf0, 0x1e, 0xf8, 0x65, 0x4c,
+	0x87, 0x0f, 0xfb, 0x75, 0x18, 0xd0, 0x9b, 0x2f, 0xdd, 0xbb, 0x9a, 0x4c, 0xfb, 0x31, 0x57, 0xee,
+	0x87, 0xda, 0x5a, 0x58, 0xb9, 0x51, 0x2b, 0xec, 0x9c, 0x2b, 0x84, 0xea, 0x1d, 0xc3, 0xdd, 0x1d,
+	0xea, 0x63, 0x57, 0xee, 0xe6, 0x11, 0x7f, 0x97, 0x8b, 0x9e, 0x21, 0xd3, 0x81, 0x68, 0x3c, 0x3d,
+	0x89, 0x40, 0x7f, 0x0e, 0xd3, 0x92, 0xed, 0xb0, 0xd0, 0x4e, 0x45, 0xbe, 0xe8, 0xd4, 0x3e, 0xe2,
+	0xbc, 0x16, 0xc8, 0xbd, 0x3e, 0x5e, 0xd1, 0xa0, 0xda, 0x90, 0xc5, 0x98, 0x22, 0x57, 0xe4, 0x4e,
+	0x66, 0x83, 0xf7, 0xa4, 0x58, 0xfc, 0xa6, 0x22, 0x47, 0x51, 0x6d, 0x8d, 0xb3, 0x7f, 0xa4, 0x7d,
+	0x3c, 0x80, 0xfd, 0xb0, 0xc8, 0x7d, 0xab, 0xc0, 0x74, 0x58, 0x16, 0x7f, 0xc0, 0x21, 0x77, 0x23,
+	0x0f, 0x58, 0x31, 0xa9, 0x73, 0x7d, 0x48, 0xd9, 0x1a, 0x7c, 0xc6, 0xe5, 0xaf, 0x69, 0x8f, 0xaf,
+	0x29, 0x7f, 0xb9, 0x86, 0x0c, 0x50, 0x8b, 0xef, 0x14, 0x98, 0xeb, 0xd3, 0x42, 0x74, 0x50, 0xaf,
+	0xd6, 0xe5, 0x6e, 0xff, 0x4b, 0x5b, 0xcf, 0x1f, 0x7d, 0x85, 0xf9, 0x5a, 0xfa, 0x3c, 0x66, 0x5c,
+	0xee, 0x0b, 0x65, 0x71, 0x45, 0x21, 0x1e, 0xe4, 0xc3, 0x7a, 0x6d, 0x1d, 0x54, 0xc9, 0x1d, 0xde,
+	0x78, 0x8b, 0xbf, 0x98, 0x94, 0xd4, 0x10, 0x58, 0x88, 0x7f, 0xca, 0xc5, 0xaf, 0x68, 0x0f, 0xaf,
+	0x2b, 0xde, 0x6a, 0x31, 0xb9, 0x65, 0xf2, 0x25, 0x3d, 0xe0, 0x61, 0x81, 0x9b, 0x3b, 0xe4, 0x01,
+	0xa4, 0x34, 0xdb, 0x87, 0x14, 0x7a, 0xf4, 0x6d, 0x99, 0x34, 0xa0, 0x19, 0x91, 0x1b, 0xdf, 0x88,
+	0xad, 0x3b, 0xde, 0xe7, 0xe7, 0x9d, 0xc7, 0x41, 0x2f, 0x14, 0xa5, 0x99, 0x28, 0x46, 0x88, 0x7f,
+	0xc2, 0xc5, 0x2f, 0x69, 0x9f, 0x04, 0xe2, 0xc5, 0x73, 0xc4, 0x08, 0xd9, 0x2f, 0x81, 0x84, 0x1d,
+	0x2f, 0x73, 0xe1, 0x4e, 0x77, 0x21, 0x84, 0x9f, 0x09, 0x4a, 0x73, 0x51, 0x70, 0x57, 0xf6, 0x03,
+	0x85, 0x74, 0x60, 0x8a, 0xdb, 0x10, 0x7c, 0x0c, 0x44, 0x0a, 0x5c, 0xc7, 0xd8, 0x17, 0x47, 0xa5,
+	0x3b, 0x31, 0xa8, 0xfc, 0x2a, 0xa8, 0xcf, 0x75, 0x7e, 0x40, 0x32, 0x42, 0x7d, 0xb7, 0xb7, 0xaa,
+	0x76, 0x6c, 0xff, 0x8d, 0x6c, 0x87, 0xa2, 0x90, 0xbe, 0xaf, 0x8c, 0x4a, 0x6a, 0x08, 0x2c, 0x5c,
+	0xb6, 0xca, 0xc5, 0x3e, 0xd4, 0x3e, 0x0a, 0xc4, 0x9e, 0xda, 0xa3, 0xfc, 0xd5, 0x81, 0x5c, 0x20,
+	0x50, 0x7c, 0xa9, 0x23, 0xc2, 0x34, 0xe8, 0x6b, 0xa2, 0xd2, 0x4c, 0x14, 0x33, 0x24, 0x4c, 0x75,
+	0x87, 0x31, 0x6a, 0x8d, 0x10, 0xfb, 0x27, 0xf2, 0x78, 0x87, 0x7c, 0x42, 0xdf, 0xcc, 0x90, 0xf7,
+	0x50, 0xc4, 0xd0, 0x4f, 0x7c, 0x4a, 0xc5, 0x38, 0x3a, 0xf8, 0xc6, 0x46, 0x7b, 0xce, 0xd5, 0x58,
+	0xd7, 0x96, 0x02, 0x35, 0xcc, 0x1e, 0xd5, 0x08, 0x5d, 0xbe, 0x93, 0xeb, 0x06, 0x65, 0x45, 0x3f,
+	0x5d, 0x11, 0x5b, 0xe1, 0x55, 0x1f, 0xe1, 0x94, 0xee, 0x0e, 0xa6, 0x10, 0xbe, 0xe9, 0xdb, 0x7e,
+	0xac, 0x80, 0xf0, 0xb1, 0x8d, 0x94, 0x23, 0x14, 0xfb, 0x39, 0x14, 0x50, 0xaf, 0x78, 0xbb, 0x57,
+	0xac, 0xe6, 0x21, 0xed, 0xe6, 0xd2, 0xa0, 0xcf, 0x3d, 0xb5, 0x05, 0xae, 0x48, 0x49, 0xbb, 0x83,
+	0x8a, 0xd8, 0x12, 0xb9, 0x1c, 0x7c, 0xb9, 0x83, 0xb2, 0x6a, 0x40, 0x76, 0xa8, 0x1f, 0x6f, 0xb8,
+	0xf4, 0x5f, 0x33, 0x62, 0x14, 0xda, 0x22, 0xe7, 0xfc, 0x03, 0xa2, 0x21, 0xe7, 0xbe, 0x1d, 0x69,
+	0xd9, 0x0a, 0xd1, 0xae, 0xfd, 0x57, 0x0a, 0x52, 0xe5, 0x7a, 0xcb, 0x76, 0xc8, 0x1b, 0x98, 0xda,
+	0xa1, 0x7e, 0xe8, 0x85, 0x63, 0xb6, 0xaf, 0x1d, 0x54, 0xc1, 0x7f, 0xbe, 0x28, 0xe5, 0xf8, 0x4e,
+	0xd5, 0xa5, 0xd3, 0x66, 0xb9, 0x38, 0x95, 0xe4, 0x50, 0x9c, 0x89, 0xbc, 0x96, 0x6d, 0xfc, 0xfd,
+	0x97, 0x30, 0x5d, 0xa5, 0x7e, 0xec, 0xf1, 0x67, 0xc0, 0x1b, 0x49, 0x69, 0x00, 0x2c, 0xb8, 0x84,
+	0x95, 0x66, 0x7a, 0x4c, 0xbb, 0x2f, 0x29, 0xe8, 0x9b, 0x23, 0x98, 0x0c, 0xda, 0x9d, 0xb8, 0x53,
+	0x17, 0xa5, 0x1f, 0xfa, 0x1a, 0xbb, 0x72, 0x45, 0x86, 0x3a, 0xa3, 0xc1, 0x29, 0x40, 0x0b, 0xe9,
+	0x8b, 0x4e, 0x42, 0xae, 0x75, 0x98, 0x16, 0xdd, 0x4e, 0xec, 0x13, 0x06, 0xed, 0xce, 0x88, 0xc3,
+	0x79, 0xc9, 0x89, 0x77, 0x44, 0xb5, 0x47, 0x9c, 0xe5, 0x47, 0xda, 0x0f, 0xa2, 0x2c, 0xa3, 0x7e,
+	0x0f, 0x7a, 0x9f, 0xe4, 0x67, 0x40, 0xb0, 0x79, 0x87, 0x89, 0xe0, 0x74, 0x33, 0x69, 0xa8, 0xbb,
+	0x67, 0xfa, 0xbb, 0xf8, 0x4c, 0x2b, 0x71, 0x81, 0x05, 0x42, 0x42, 0x3e, 0x0f, 0x18, 0x7d, 0x01,
+	0xaa, 0x48, 0x9b, 0x50, 0x6f, 0x7f, 0x18, 0xf3, 0x3b, 0x7d, 0x8d, 0x72, 0xd4, 0x4c, 0x9b, 0xe3,
+	0xec, 0xa7, 0x49, 0xbe, 0xc7, 0x9e, 0x71, 0x3e, 0x26, 0x4c, 0x23, 0x41, 0xb8, 0x77, 0x39, 0x9c,
+	0xf9, 0x6c, 0x7f, 0x37, 0x92, 0x73, 0x9f, 0xe7, 0xdc, 0x67, 0x49, 0xa1, 0xc7, 0x3d, 0xd4, 0xfe,
+	0xfc, 0x92, 0x67, 0x7d, 0xbc, 0x57, 0x79, 0xa5, 0x77, 0x62, 0xc4, 0x5a, 0x91, 0x0b, 0x20, 0x44,
+	0xed, 0x09, 0x10, 0x1d, 0xcc, 0xcd, 0x89, 0x2f, 0x52, 0x82, 0xc1, 0x38, 0xff, 0xb3, 0xfe, 0xdf,
+	0x03, 0x00, 0x36, 0xea, 0xd9, 0x3a, 0x8f, 0x34, 0x00, 0x00,
 }
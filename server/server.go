@@ -4,12 +4,17 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
@@ -17,6 +22,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -25,8 +31,10 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/yahoo/crypki/api"
 	"github.com/yahoo/crypki/config"
+	"github.com/yahoo/crypki/metrics"
 	"github.com/yahoo/crypki/pkcs11"
 	"github.com/yahoo/crypki/proto"
+	"github.com/yahoo/crypki/x509cert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -48,16 +56,21 @@ func grpcHandlerFunc(ctx context.Context, grpcServer *grpc.Server, otherHandler
 }
 
 // initHTTPServer initializes HTTP server with TLS credentials and returns http.Server.
-func initHTTPServer(ctx context.Context, tlsConfig *tls.Config, grpcServer *grpc.Server, gwmux *runtime.ServeMux, addr string) *http.Server {
+// /ruok reports the service as not ready while pendingKeys returns a non-zero number of keys not loaded yet.
+func initHTTPServer(ctx context.Context, tlsConfig *tls.Config, grpcServer *grpc.Server, gwmux *runtime.ServeMux, m *metrics.Metrics, addr string, pendingKeys func() int32) *http.Server {
 	mux := http.NewServeMux()
 	// handler to check if service is up
 	mux.HandleFunc("/ruok", func(w http.ResponseWriter, req *http.Request) {
+		if n := pendingKeys(); n != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "not ready: %d keys pending HSM login\n", n)
+			return
+		}
 		fmt.Fprintln(w, "imok")
 	})
+	mux.Handle("/metrics", m.Handler())
 	mux.Handle("/", gwmux)
 
-	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
-
 	srv := &http.Server{
 		Addr: addr,
 		// discard noisy messages until we find a better way to filter them out,
@@ -69,7 +82,6 @@ func initHTTPServer(ctx context.Context, tlsConfig *tls.Config, grpcServer *grpc
 		// need further investigation - https://jira.ouroath.com/browse/SSHCA-1289
 		ErrorLog:     log.New(ioutil.Discard, "", 0),
 		Handler:      grpcHandlerFunc(ctx, grpcServer, mux),
-		IdleTimeout:  30 * time.Second,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		TLSConfig:    tlsConfig,
@@ -128,6 +140,22 @@ func Main(keyP crypki.KeyIDProcessor) {
 
 	keyUsages := make(map[string]map[string]bool)
 	maxValidity := make(map[string]uint64)
+	minValidity := make(map[string]uint64)
+	rejectDuplicateNames := make(map[string]bool)
+	allowEmptyPrincipals := make(map[string]bool)
+	deriveKeyID := make(map[string]bool)
+	var disabledEndpoints []string
+	timeouts := &api.Timeouts{
+		Default:   time.Duration(cfg.RequestTimeoutMs) * time.Millisecond,
+		Endpoints: make(map[string]time.Duration),
+	}
+	slowRequests := &api.SlowRequestLogger{
+		Default:   time.Duration(cfg.SlowRequestThresholdMs) * time.Millisecond,
+		Endpoints: make(map[string]time.Duration),
+	}
+	requestSizes := &api.RequestSizeLimits{
+		Endpoints: make(map[string]int),
+	}
 
 	for _, usage := range cfg.KeyUsages {
 		keyUsages[usage.Endpoint] = make(map[string]bool)
@@ -135,6 +163,73 @@ func Main(keyP crypki.KeyIDProcessor) {
 			keyUsages[usage.Endpoint][id] = true
 		}
 		maxValidity[usage.Endpoint] = usage.MaxValidity
+		minValidity[usage.Endpoint] = usage.MinValidity
+		rejectDuplicateNames[usage.Endpoint] = usage.RejectDuplicateNames
+		allowEmptyPrincipals[usage.Endpoint] = usage.AllowEmptyPrincipals
+		deriveKeyID[usage.Endpoint] = usage.DeriveKeyIDFromCaller
+		if usage.Disabled {
+			disabledEndpoints = append(disabledEndpoints, usage.Endpoint)
+		}
+		if usage.RequestTimeoutMs != 0 {
+			timeouts.Endpoints[usage.Endpoint] = time.Duration(usage.RequestTimeoutMs) * time.Millisecond
+		}
+		if usage.MaxRequestSize != 0 {
+			requestSizes.Endpoints[usage.Endpoint] = usage.MaxRequestSize
+		}
+		if usage.SlowRequestThresholdMs != 0 {
+			slowRequests.Endpoints[usage.Endpoint] = time.Duration(usage.SlowRequestThresholdMs) * time.Millisecond
+		}
+	}
+
+	var certCache *x509cert.CertFileCache
+	if cfg.CacheCertFiles {
+		certCache = x509cert.NewCertFileCache()
+	}
+	keys := make(map[string]config.KeyConfig)
+	ctLogs := make(map[string][]*x509cert.CTLog)
+	crossSignedCAs := make(map[string][][]*x509.Certificate)
+	var identifiers []string
+	for _, key := range cfg.Keys {
+		keys[key.Identifier] = key
+		identifiers = append(identifiers, key.Identifier)
+		for _, lc := range key.X509CTLogs {
+			der, err := base64.StdEncoding.DecodeString(lc.PublicKey)
+			if err != nil {
+				log.Fatalf("crypki: invalid public key of CT log %q: %v", lc.URL, err)
+			}
+			ctLog, err := x509cert.NewCTLog(lc.URL, der)
+			if err != nil {
+				log.Fatalf("crypki: %v", err)
+			}
+			ctLogs[key.Identifier] = append(ctLogs[key.Identifier], ctLog)
+		}
+		for _, location := range key.X509CrossSignedCACertLocations {
+			chain, err := api.LoadX509IssuerChain(certCache, location)
+			if err != nil {
+				log.Fatalf("crypki: failed to load cross-signed CA certificate of key %q: %v", key.Identifier, err)
+			}
+			crossSignedCAs[key.Identifier] = append(crossSignedCAs[key.Identifier], chain)
+		}
+	}
+
+	adminIdentities := make(map[string]bool)
+	for _, id := range cfg.AdminIdentities {
+		adminIdentities[id] = true
+	}
+	requiredFields, err := api.NewRequiredFields(cfg.RequiredFields)
+	if err != nil {
+		log.Fatalf("crypki: %v", err)
+	}
+	callerSchemes := make(map[string]map[proto.SignatureScheme]bool)
+	for caller, schemes := range cfg.CallerSignatureSchemes {
+		callerSchemes[caller] = make(map[proto.SignatureScheme]bool)
+		for _, scheme := range schemes {
+			callerSchemes[caller][proto.SignatureScheme(proto.SignatureScheme_value[scheme])] = true
+		}
+	}
+	keyGenIdentities := make(map[string]bool)
+	for _, id := range cfg.KeyGenerationIdentities {
+		keyGenIdentities[id] = true
 	}
 
 	hostname, err := os.Hostname()
@@ -147,24 +242,73 @@ func Main(keyP crypki.KeyIDProcessor) {
 		log.Fatal(err)
 	}
 
-	signer, err := pkcs11.NewCertSign(cfg.ModulePath, cfg.Keys, keyUsages[config.X509CertEndpoint], hostname, ips)
+	// Keys without a module are held by the module at the default path.
+	modulePaths := map[string]string{"": cfg.ModulePath}
+	for name, path := range cfg.Modules {
+		modulePaths[name] = path
+	}
+	// The X509 CA certificates of TSA and attestation keys are their TSA and attestation certificates.
+	requireX509CACert := make(map[string]bool)
+	for _, endpoint := range []string{config.X509CertEndpoint, config.TimestampEndpoint, config.AttestationEndpoint} {
+		for id := range keyUsages[endpoint] {
+			requireX509CACert[id] = true
+		}
+	}
+	// If configured, keys whose login to the HSM fails are loaded in the background, and are reported as
+	// being reloaded until then.
+	transitions := api.NewKeyTransitions(time.Duration(cfg.KeyReloadRetryDelayMs) * time.Millisecond)
+	var pendingKeys int32
+	var loginRetry *pkcs11.LoginRetry
+	if cfg.HSMLoginRetryIntervalMs != 0 {
+		loginRetry = &pkcs11.LoginRetry{
+			Interval: time.Duration(cfg.HSMLoginRetryIntervalMs) * time.Millisecond,
+			Pending: func(id string) {
+				atomic.AddInt32(&pendingKeys, 1)
+				transitions.Begin(id)
+			},
+			Loaded: func(id string) {
+				transitions.End(id)
+				atomic.AddInt32(&pendingKeys, -1)
+			},
+		}
+	}
+	signer, err := pkcs11.NewCertSign(modulePaths, cfg.Keys, requireX509CACert, hostname, ips, loginRetry, certCache)
 	if err != nil {
 		log.Fatalf("unable to initialize cert signer: %v", err)
 	}
 
+	// If configured, the TLS server key is an HSM-backed key instead of a key file on disk.
+	var tlsServerKey crypto.Signer
+	if cfg.TLSServerKeyIdentifier != "" {
+		tlsServerKey, err = pkcs11.NewTLSSigner(signer, cfg.TLSServerKeyIdentifier, cfg.TLSServerKeySessions)
+		if err != nil {
+			log.Fatalf("crypki: failed to load TLS server key %q: %v", cfg.TLSServerKeyIdentifier, err)
+		}
+	}
+
 	// Following TLS config will be used to initialize grpc server and
 	// grpc gateway server.
 	tlsConfig, err := tlsConfiguration(
 		cfg.TLSCACertPath,
 		cfg.TLSServerCertPath,
 		cfg.TLSServerKeyPath,
+		tlsServerKey,
 		cfg.TLSClientAuthMode)
 	if err != nil {
 		log.Fatalf("crypki: failed to setup TLS config: %v", err)
 	}
+	cfg.ApplyTLS(tlsConfig)
 
 	// Setup gRPC gateway
-	gwmux := runtime.NewServeMux()
+	// The identities of the REST clients are passed to the gRPC server, which trusts them from the gateway only.
+	gwmuxOpts := []runtime.ServeMuxOption{
+		runtime.WithMetadata(api.GatewayMetadata),
+		runtime.WithIncomingHeaderMatcher(api.GatewayHeaderMatcher),
+	}
+	if cfg.ProblemJSONErrors {
+		gwmuxOpts = append(gwmuxOpts, runtime.WithProtoErrorHandler(api.ProblemJSONErrorHandler))
+	}
+	gwmux := runtime.NewServeMux(gwmuxOpts...)
 
 	grpcAddr := net.JoinHostPort("localhost", cfg.TLSPort)
 
@@ -181,30 +325,312 @@ func Main(keyP crypki.KeyIDProcessor) {
 	if err := proto.RegisterSigningHandlerFromEndpoint(ctx, gwmux, grpcAddr, opts); err != nil {
 		log.Fatalf("crypki: failed to register signing service handler endpoint, err: %v", err)
 	}
+	if err := proto.RegisterAdminHandlerFromEndpoint(ctx, gwmux, grpcAddr, opts); err != nil {
+		log.Fatalf("crypki: failed to register admin service handler endpoint, err: %v", err)
+	}
+
+	// Index the public key fingerprints of the keys loaded at startup. The keys whose public key cannot be
+	// read are still served, but are not indexed nor checked against the TLS server key.
+	publicKeys := make(map[string]crypto.PublicKey)
+	if pkg, ok := signer.(crypki.PublicKeyGetter); ok {
+		for _, id := range identifiers {
+			pub, err := pkg.PublicKey(id)
+			if err != nil {
+				log.Printf("unable to get public key of %q, it cannot be referred to by fingerprint nor checked against the TLS server key: %v", id, err)
+				continue
+			}
+			publicKeys[id] = pub
+		}
+	}
+	checkTLSKeyReuse(cfg, tlsConfig, publicKeys)
+	fingerprints, err := api.NewFingerprintIndex(publicKeys)
+	if err != nil {
+		log.Fatalf("crypki: failed to index key fingerprints, err: %v", err)
+	}
+	// The signing certificates of the keys not loaded yet cannot be checked against their public keys.
+	blobSigningCerts := make(map[string]string)
+	for _, key := range cfg.Keys {
+		if key.BlobSigningCertLocation == "" {
+			continue
+		}
+		if blobSigningCerts[key.Identifier], err = api.LoadBlobSigningCert(certCache, key.BlobSigningCertLocation, publicKeys[key.Identifier]); err != nil {
+			log.Fatalf("crypki: failed to load signing certificate of key %q: %v", key.Identifier, err)
+		}
+	}
+
+	versions := &api.APIVersions{Min: cfg.MinAPIVersion, Max: cfg.MaxAPIVersion, WarnOnly: cfg.WarnUnsupportedAPIVersions}
+
+	// Limit the rate of the signing requests of the keys, across the replicas if the backend is shared.
+	rateLimits := make(map[string]api.RateLimit)
+	for _, key := range cfg.Keys {
+		if key.RateLimit > 0 {
+			rateLimits[key.Identifier] = api.RateLimit{Rate: key.RateLimit, Burst: key.RateLimitBurst}
+		}
+	}
+	var rateLimitBackend api.RateLimitBackend = &api.MemoryRateLimitBackend{}
+	if cfg.RateLimitBackend == config.RateLimitBackendRedis {
+		rateLimitBackend = &api.RedisRateLimitBackend{Addr: cfg.RateLimitRedisAddress, Timeout: time.Duration(cfg.RateLimitRedisTimeoutMs) * time.Millisecond}
+	}
 
 	// Setup gRPC server and http server
+	m := metrics.New(identifiers)
+	timeouts.Rejected = func(method string, req interface{}) {
+		m.ObserveRejection(method, req, metrics.ReasonSaturated)
+	}
+	var named []api.NamedInterceptor
+	if cfg.VerboseLogSampleRate > 0 {
+		sampler := &api.LogSampler{Rate: cfg.VerboseLogSampleRate}
+		named = append(named, api.NamedInterceptor{Name: config.LogSamplingInterceptor, Interceptor: sampler.UnaryServerInterceptor()})
+	}
+	if cfg.RequireCallerIdentity {
+		identity := &api.IdentityRequirement{
+			ExemptReadOnly: cfg.ExemptReadOnlyFromCallerIdentity,
+			Rejected: func(method string, req interface{}) {
+				m.ObserveRejection(method, req, metrics.ReasonUnauthenticated)
+			},
+		}
+		named = append(named, api.NamedInterceptor{Name: config.IdentityInterceptor, Interceptor: identity.UnaryServerInterceptor()})
+	}
+	if len(requestSizes.Endpoints) != 0 {
+		requestSizes.Rejected = func(method string, req interface{}) {
+			m.ObserveRejection(method, req, metrics.ReasonTooLarge)
+		}
+		named = append(named, api.NamedInterceptor{Name: config.RequestSizeInterceptor, Interceptor: requestSizes.UnaryServerInterceptor()})
+	}
+	if cfg.RejectSigningUntilReady {
+		readiness := &api.ReadinessGate{
+			Ready:      func() bool { return atomic.LoadInt32(&pendingKeys) == 0 },
+			RetryDelay: time.Duration(cfg.KeyReloadRetryDelayMs) * time.Millisecond,
+			Rejected: func(method string, req interface{}) {
+				m.ObserveRejection(method, req, metrics.ReasonNotReady)
+			},
+		}
+		named = append(named, api.NamedInterceptor{Name: config.ReadinessInterceptor, Interceptor: readiness.UnaryServerInterceptor()})
+	}
+	named = append(named,
+		api.NamedInterceptor{Name: config.APIVersionInterceptor, Interceptor: versions.UnaryServerInterceptor()},
+		api.NamedInterceptor{Name: config.KeyAliasInterceptor, Interceptor: api.KeyAliases(cfg.KeyAliases).UnaryServerInterceptor()},
+		api.NamedInterceptor{Name: config.FingerprintInterceptor, Interceptor: fingerprints.UnaryServerInterceptor()},
+		api.NamedInterceptor{Name: config.TimeoutInterceptor, Interceptor: timeouts.UnaryServerInterceptor()},
+	)
+	if len(rateLimits) != 0 {
+		limiter := &api.RateLimiter{
+			Backend:    rateLimitBackend,
+			Limits:     rateLimits,
+			FailClosed: cfg.RateLimitFailClosed,
+			Rejected: func(method string, req interface{}) {
+				m.ObserveRejection(method, req, metrics.ReasonRateLimited)
+			},
+		}
+		named = append(named, api.NamedInterceptor{Name: config.RateLimitInterceptor, Interceptor: limiter.UnaryServerInterceptor()})
+	}
+	if cfg.MaxConcurrentRequestsPerCaller > 0 || len(cfg.CallerConcurrencyLimits) != 0 {
+		concurrency := &api.CallerConcurrencyLimiter{
+			Max:    cfg.MaxConcurrentRequestsPerCaller,
+			Limits: cfg.CallerConcurrencyLimits,
+			Rejected: func(method string, req interface{}) {
+				m.ObserveRejection(method, req, metrics.ReasonConcurrencyLimited)
+			},
+		}
+		named = append(named, api.NamedInterceptor{Name: config.ConcurrencyInterceptor, Interceptor: concurrency.UnaryServerInterceptor()})
+	}
+	if cfg.PreSignHookPath != "" {
+		hook := &api.PreSignHook{Path: cfg.PreSignHookPath, Timeout: time.Duration(cfg.PreSignHookTimeoutMs) * time.Millisecond}
+		named = append(named, api.NamedInterceptor{Name: config.PreSignHookInterceptor, Interceptor: hook.UnaryServerInterceptor()})
+	}
+	if len(cfg.SlotSignRates) != 0 {
+		pacer := &api.SignPacer{
+			Slots: make(map[string]string),
+			Rates: make(map[string]float64),
+			Rejected: func(method string, req interface{}) {
+				m.ObserveRejection(method, req, metrics.ReasonPacingTimeout)
+			},
+		}
+		for _, rate := range cfg.SlotSignRates {
+			pacer.Rates[fmt.Sprintf("%s/%d", rate.Module, rate.SlotNumber)] = rate.Rate
+		}
+		for _, key := range cfg.Keys {
+			pacer.Slots[key.Identifier] = fmt.Sprintf("%s/%d", key.Module, key.SlotNumber)
+		}
+		named = append(named, api.NamedInterceptor{Name: config.SignPacingInterceptor, Interceptor: pacer.UnaryServerInterceptor()})
+	}
+	for _, name := range cfg.DisabledInterceptors {
+		log.Printf("crypki: interceptor %q is disabled", name)
+	}
+	gateway := &api.GatewayIdentity{}
+	if len(tlsConfig.Certificates) != 0 && len(tlsConfig.Certificates[0].Certificate) != 0 {
+		gateway.Certificate = tlsConfig.Certificates[0].Certificate[0]
+	}
+	interceptors := []grpc.UnaryServerInterceptor{gateway.UnaryServerInterceptor(), m.UnaryServerInterceptor(), slowRequests.UnaryServerInterceptor()}
+	interceptors = append(interceptors, api.OrderInterceptors(named, cfg.InterceptorOrder, cfg.DisabledInterceptors)...)
+	interceptors = append(interceptors, slowRequests.HandlerInterceptor(), m.HandlerInterceptor())
+	unaryInterceptor := api.ChainUnaryInterceptors(interceptors...)
 	grpcServer := grpc.NewServer([]grpc.ServerOption{
 		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.UnaryInterceptor(unaryInterceptor),
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
 	}...)
 
-	proto.RegisterSigningServer(grpcServer, &api.SigningService{CertSign: signer, KeyUsages: keyUsages, MaxValidity: maxValidity, KeyIDProcessor: keyP})
+	ss := &api.SigningService{
+		CertSign:                signer,
+		KeyUsages:               keyUsages,
+		MaxValidity:             maxValidity,
+		MinValidity:             minValidity,
+		RejectDuplicateNames:    rejectDuplicateNames,
+		AllowEmptyPrincipals:    allowEmptyPrincipals,
+		DeriveKeyID:             deriveKeyID,
+		MaxSSHCertOptions:       cfg.MaxSSHCertOptions,
+		MaxSSHCertOptionsSize:   cfg.MaxSSHCertOptionsSize,
+		MaxBlobBatchSize:        cfg.MaxBlobBatchSize,
+		MaxBlobBatchStreamSize:  cfg.MaxBlobBatchStreamSize,
+		Keys:                    keys,
+		KeyIDProcessor:          keyP,
+		Endpoints:               api.NewEndpointState(disabledEndpoints...),
+		AdminIdentities:         adminIdentities,
+		RetryInvalidSignatures:  cfg.RetryInvalidSignatures,
+		AllowZeroDigests:        cfg.AllowZeroDigests,
+		RejectLowEntropyDigests: cfg.RejectLowEntropyDigests,
+		StrictBlobDigests:       cfg.StrictBlobDigests,
+		PublicKeyCache:          api.NewPublicKeyCache(),
+		Fingerprints:            fingerprints,
+		Transitions:             transitions,
+		IssuanceLog:             api.NewIssuanceLog(cfg.IssuanceLogSize),
+		Usage:                   api.NewUsageCounters(),
+		VerboseErrors:           cfg.VerboseErrors,
+		RedactLogs:              cfg.RedactLogs,
+		LogSignerOpts:           cfg.LogSignerOpts,
+		CTLogs:                  ctLogs,
+		X509CrossSignedCACerts:  crossSignedCAs,
+		UnaryInterceptor:        unaryInterceptor,
+		ReceiptKey:              cfg.ReceiptKeyIdentifier,
+		CallerPermittedDomains:  cfg.CallerPermittedDomains,
+		CallerSignatureSchemes:  callerSchemes,
+		CallerSSHValidities:     cfg.CallerSSHValidities,
+		RequiredFields:          requiredFields,
+		BlobSigningCerts:        blobSigningCerts,
+		Config:                  cfg,
+	}
+	if cfg.CircuitBreakerThreshold > 0 {
+		ss.Breakers = api.NewCircuitBreakers(cfg.CircuitBreakerThreshold, time.Duration(cfg.CircuitBreakerOpenTimeoutMs)*time.Millisecond)
+		ss.Breakers.OnStateChange = func(identifier string, state api.BreakerState) {
+			log.Printf("circuit breaker of key %q is %s", identifier, state)
+			m.SetCircuitBreakerState(identifier, int(state))
+		}
+	}
+	if cfg.IssuanceStoreDir != "" {
+		if ss.IssuanceStore, err = api.NewFileIssuanceStore(cfg.IssuanceStoreDir); err != nil {
+			log.Fatalf("crypki: failed to open issuance store: %v", err)
+		}
+	}
+	if cfg.X509SerialStoreDir != "" {
+		if ss.SerialStore, err = x509cert.NewFileSerialStore(cfg.X509SerialStoreDir); err != nil {
+			log.Fatalf("crypki: failed to open x509 serial store: %v", err)
+		}
+	}
+	for _, key := range cfg.Keys {
+		if key.ReplayWindowMs != 0 {
+			ss.ReplayStore = &api.MemoryReplayStore{}
+			break
+		}
+	}
+	if cfg.NotBeforeWatermarkDir != "" {
+		if ss.NotBeforeWatermarks, err = api.NewNotBeforeWatermarks(cfg.NotBeforeWatermarkDir); err != nil {
+			log.Fatalf("crypki: failed to load notBefore watermarks: %v", err)
+		}
+	}
+	if cfg.MaxInFlightStreamBytes > 0 {
+		ss.StreamBytes = &api.StreamBytesLimiter{Max: cfg.MaxInFlightStreamBytes, MessageSize: int64(cfg.MaxRecvMsgSize)}
+	}
+	if cfg.SSHSerialCounterDir != "" {
+		if ss.SSHSerialCounters, err = api.NewSSHSerialCounters(cfg.SSHSerialCounterDir, cfg.SSHSerialCheckpointInterval); err != nil {
+			log.Fatalf("crypki: failed to load SSH serial counters: %v", err)
+		}
+	}
+	if cfg.SubjectKeyDenyListPath != "" {
+		if ss.SubjectKeyDenyList, err = api.LoadSubjectKeyDenyList(cfg.SubjectKeyDenyListPath); err != nil {
+			log.Fatalf("crypki: failed to load subject key deny list: %v", err)
+		}
+		log.Printf("crypki: loaded %d denied subject keys", ss.SubjectKeyDenyList.Len())
+		go reloadOnHangup(ss.SubjectKeyDenyList)
+	}
+	if cfg.MutatingWebhookURL != "" {
+		ss.MutatingWebhook = &api.MutatingWebhook{URL: cfg.MutatingWebhookURL, Timeout: time.Duration(cfg.MutatingWebhookTimeoutMs) * time.Millisecond}
+	}
+	if cfg.TrustedTimeURL != "" {
+		ss.ClockDrift = &api.ClockDriftMonitor{
+			TrustedTime: api.HTTPTrustedTime(cfg.TrustedTimeURL),
+			MaxDrift:    time.Duration(cfg.MaxClockDriftMs) * time.Millisecond,
+			Timeout:     5 * time.Second,
+		}
+		go ss.ClockDrift.Run(time.Duration(cfg.ClockDriftCheckIntervalMs) * time.Millisecond)
+	}
+	if cfg.SelfTestIntervalMs > 0 {
+		ss.SelfTest = &api.SelfTest{Probe: ss.ProbeKey, Identifiers: identifiers, Concurrency: cfg.SelfTestConcurrency}
+		go ss.SelfTest.Run(time.Duration(cfg.SelfTestIntervalMs) * time.Millisecond)
+	}
+	if kg, ok := signer.(crypki.KeyGenerator); ok {
+		ss.KeyGenerator = kg
+		ss.KeyGenerationIdentities = keyGenIdentities
+	}
+	if es, ok := signer.(crypki.EphemeralSigner); ok && len(cfg.EphemeralKeys) != 0 {
+		ss.EphemeralSigner = es
+		ss.EphemeralKeys = make(map[string]config.EphemeralKeyConfig)
+		for _, key := range cfg.EphemeralKeys {
+			ss.EphemeralKeys[key.Identifier] = key
+		}
+	}
+	proto.RegisterSigningServer(grpcServer, ss)
+	proto.RegisterAdminServer(grpcServer, ss)
 
-	server := initHTTPServer(ctx, tlsConfig, grpcServer, gwmux, net.JoinHostPort("", cfg.TLSPort))
+	server := initHTTPServer(ctx, tlsConfig, grpcServer, gwmux, m, net.JoinHostPort("", cfg.TLSPort), func() int32 {
+		return atomic.LoadInt32(&pendingKeys)
+	})
 
 	listener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
+	keepalive := api.Keepalive{
+		MaxConnectionIdle: time.Duration(cfg.KeepaliveMaxConnectionIdleMs) * time.Millisecond,
+		Time:              time.Duration(cfg.KeepaliveTimeMs) * time.Millisecond,
+	}
+	keepalive.ConfigureServer(server)
+	listener = keepalive.Listener(listener)
+	if cfg.ProxyProtocol {
+		listener = api.ProxyProtocolListener(listener, cfg.MaxPendingProxyHandshakes)
+	}
+	listener = api.LimitListener(listener, cfg.MaxConnections, cfg.MaxConnectionsPerIP)
+	// Shut the server down on SIGINT or SIGTERM, and finalize the PKCS#11 modules once
+	// the in-flight requests are done.
+	shutdown := make(chan struct{})
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-c
+		log.Printf("crypki: received %v signal, shutting down", sig)
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Printf("crypki: failed to shut down server gracefully: %v", err)
+		}
+		close(shutdown)
+	}()
+
 	log.Printf("starting server on %s", server.Addr)
-	if err := server.Serve(tls.NewListener(listener, server.TLSConfig)); err != nil {
+	if err := server.Serve(tls.NewListener(listener, server.TLSConfig)); err != http.ErrServerClosed {
 		log.Fatalf("failed to serve: %s", err)
 	}
-
+	<-shutdown
+	if closer, ok := signer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("crypki: %v", err)
+		}
+	}
+	log.Println("crypki: server stopped")
 }
 
 // tlsConfiguration returns tls configuration.
+// If key is not nil, it is used as the private key of the server certificate instead of the key in keyPath.
 // TODO: https://jira.ouroath.com/browse/SSHCA-1312
-func tlsConfiguration(caCertPath string, certPath, keyPath string, clientAuthMode tls.ClientAuthType) (*tls.Config, error) {
+func tlsConfiguration(caCertPath string, certPath, keyPath string, key crypto.Signer, clientAuthMode tls.ClientAuthType) (*tls.Config, error) {
 	cfg := &tls.Config{}
 	certPool := x509.NewCertPool()
 	caCert, err := ioutil.ReadFile(caCertPath)
@@ -213,6 +639,18 @@ func tlsConfiguration(caCertPath string, certPath, keyPath string, clientAuthMod
 	}
 	certPool.AppendCertsFromPEM(caCert)
 
+	if key != nil {
+		mycert, err := signerKeyPair(certPath, key)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{mycert}
+		cfg.ClientCAs = certPool
+		cfg.ClientAuth = clientAuthMode
+		setTLSDefaults(cfg)
+		return cfg, nil
+	}
+
 	keypem, err := ioutil.ReadFile(keyPath)
 	if err != nil {
 		return nil, err
@@ -233,7 +671,81 @@ func tlsConfiguration(caCertPath string, certPath, keyPath string, clientAuthMod
 
 		cfg.ClientAuth = clientAuthMode
 	}
+	setTLSDefaults(cfg)
+	return cfg, nil
+}
+
+// signerKeyPair returns a tls.Certificate with the certificate chain in certPath
+// and key as the private key.
+func signerKeyPair(certPath string, key crypto.Signer) (tls.Certificate, error) {
+	var cert tls.Certificate
+	certpem, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return cert, err
+	}
+	for {
+		var block *pem.Block
+		block, certpem = pem.Decode(certpem)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			cert.Certificate = append(cert.Certificate, block.Bytes)
+		}
+	}
+	if len(cert.Certificate) == 0 {
+		return cert, fmt.Errorf("no certificate found in %s", certPath)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return cert, err
+	}
+	if !publicKeyEqual(leaf.PublicKey, key.Public()) {
+		return cert, fmt.Errorf("public key of certificate %s does not match the TLS server key", certPath)
+	}
+	cert.PrivateKey = key
+	cert.Leaf = leaf
+	return cert, nil
+}
+
+// checkTLSKeyReuse exits if the public key of the TLS server certificate is the one of a signing key, or
+// only logs a warning if cfg.WarnTLSKeyReuse is set.
+func checkTLSKeyReuse(cfg *config.Config, tlsConfig *tls.Config, publicKeys map[string]crypto.PublicKey) {
+	if len(tlsConfig.Certificates) == 0 || len(tlsConfig.Certificates[0].Certificate) == 0 {
+		return
+	}
+	leaf, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	if err != nil {
+		log.Fatalf("crypki: failed to parse TLS server certificate: %v", err)
+	}
+	for _, usage := range cfg.KeyUsages {
+		for _, id := range usage.Identifiers {
+			if pub, ok := publicKeys[id]; !ok || !publicKeyEqual(leaf.PublicKey, pub) {
+				continue
+			}
+			if !cfg.WarnTLSKeyReuse {
+				log.Fatalf("crypki: TLS server key is also the signing key %q of %s", id, usage.Endpoint)
+			}
+			log.Printf("warning: TLS server key is also the signing key %q of %s", id, usage.Endpoint)
+		}
+	}
+}
+
+// publicKeyEqual returns true if the two public keys are the same.
+func publicKeyEqual(a, b crypto.PublicKey) bool {
+	aBytes, err := x509.MarshalPKIXPublicKey(a)
+	if err != nil {
+		return false
+	}
+	bBytes, err := x509.MarshalPKIXPublicKey(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
 
+// setTLSDefaults sets the protocol parameters shared by all TLS configurations.
+func setTLSDefaults(cfg *tls.Config) {
 	// Use only modern ciphers.
 	cfg.CipherSuites = []uint16{
 		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
@@ -259,7 +771,19 @@ func tlsConfiguration(caCertPath string, certPath, keyPath string, clientAuthMod
 
 	// Don't allow session resumption.
 	cfg.SessionTicketsDisabled = true
-	return cfg, nil
+}
+
+// reloadOnHangup reloads the subject key deny list on SIGHUP. The list is left unchanged if it fails to reload.
+func reloadOnHangup(denyList *api.SubjectKeyDenyList) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	for range c {
+		if err := denyList.Reload(); err != nil {
+			log.Printf("crypki: failed to reload subject key deny list: %v", err)
+			continue
+		}
+		log.Printf("crypki: reloaded %d denied subject keys", denyList.Len())
+	}
 }
 
 // logRotate handles log rotation without process restart.
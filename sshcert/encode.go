@@ -4,7 +4,10 @@
 package sshcert
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/yahoo/crypki"
@@ -22,6 +25,9 @@ func DecodeRequest(req *proto.SSHCertificateSigningRequest, sshCertType uint32,
 	// Backdate start time by one hour as the current system clock may be ahead of other running systems.
 	start := uint64(time.Now().Unix())
 	end := start + req.GetValidity()
+	if req.GetValidity() == ssh.CertTimeInfinity {
+		end = ssh.CertTimeInfinity
+	}
 	start -= 3600
 
 	keyID, err := keyP.Process(req.GetKeyId())
@@ -42,3 +48,85 @@ func DecodeRequest(req *proto.SSHCertificateSigningRequest, sshCertType uint32,
 		},
 	}, nil
 }
+
+// DedupPrincipals removes duplicate principals from cert and returns the removed duplicates.
+// Principals are compared exactly, as sshd does when matching them.
+func DedupPrincipals(cert *ssh.Certificate) []string {
+	var dups []string
+	seen := make(map[string]bool)
+	principals := cert.ValidPrincipals[:0]
+	for _, p := range cert.ValidPrincipals {
+		if seen[p] {
+			dups = append(dups, p)
+			continue
+		}
+		seen[p] = true
+		principals = append(principals, p)
+	}
+	cert.ValidPrincipals = principals
+	return dups
+}
+
+// StandardExtensions are the SSH certificate extensions defined by the OpenSSH certificate protocol,
+// which all SSH servers supporting certificates understand.
+var StandardExtensions = map[string]bool{
+	"permit-X11-forwarding":   true,
+	"permit-agent-forwarding": true,
+	"permit-port-forwarding":  true,
+	"permit-pty":              true,
+	"permit-user-rc":          true,
+}
+
+// StripNonStandardExtensions removes the extensions not in StandardExtensions from cert and returns
+// the sorted names of the removed extensions.
+func StripNonStandardExtensions(cert *ssh.Certificate) []string {
+	var stripped []string
+	extensions := make(map[string]string, len(cert.Extensions))
+	for name, value := range cert.Extensions {
+		if !StandardExtensions[name] {
+			stripped = append(stripped, name)
+			continue
+		}
+		extensions[name] = value
+	}
+	if stripped == nil {
+		return nil
+	}
+	sort.Strings(stripped)
+	cert.Extensions = extensions
+	return stripped
+}
+
+// TBS returns the bytes covered by the signature of the authorized_keys encoded certificate,
+// i.e. the wire encoding of the certificate without its signature field.
+func TBS(certAuthorizedKey []byte) ([]byte, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(certAuthorizedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate: %v", err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("unexpected key type %s, want a certificate", pub.Type())
+	}
+	return tbs(cert), nil
+}
+
+// tbs returns the wire encoding of the certificate without its signature field.
+func tbs(cert *ssh.Certificate) []byte {
+	c := *cert
+	c.Signature = nil
+	out := c.Marshal()
+	// Marshal encodes a nil signature as an empty string, i.e. a trailing 4-byte length.
+	return out[:len(out)-4]
+}
+
+// VerifySignature returns an error unless the certificate is signed by the CA key.
+func VerifySignature(cert *ssh.Certificate, ca ssh.PublicKey) error {
+	if cert.SignatureKey == nil || !bytes.Equal(cert.SignatureKey.Marshal(), ca.Marshal()) {
+		return errors.New("certificate is not signed by the CA key")
+	}
+	if cert.Signature == nil {
+		return errors.New("certificate has no signature")
+	}
+	return ca.Verify(tbs(cert), cert.Signature)
+}
@@ -4,6 +4,9 @@
 package sshcert
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"io/ioutil"
 	"reflect"
 	"testing"
@@ -180,3 +183,105 @@ func TestDecodeRequest(t *testing.T) {
 	}
 
 }
+
+func TestDedupPrincipals(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		principals         []string
+		expectedPrincipals []string
+		expectedDups       []string
+	}{
+		"no-principals": {
+			principals:         []string{},
+			expectedPrincipals: []string{},
+		},
+		"no-duplicates": {
+			principals:         []string{"alice", "bob"},
+			expectedPrincipals: []string{"alice", "bob"},
+		},
+		"duplicates": {
+			principals:         []string{"alice", "bob", "alice", "Bob", "bob"},
+			expectedPrincipals: []string{"alice", "bob", "Bob"},
+			expectedDups:       []string{"alice", "bob"},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			cert := &ssh.Certificate{ValidPrincipals: tt.principals}
+			dups := DedupPrincipals(cert)
+			if !reflect.DeepEqual(dups, tt.expectedDups) {
+				t.Errorf("got duplicates %q, want %q", dups, tt.expectedDups)
+			}
+			if !reflect.DeepEqual(cert.ValidPrincipals, tt.expectedPrincipals) {
+				t.Errorf("got principals %q, want %q", cert.ValidPrincipals, tt.expectedPrincipals)
+			}
+		})
+	}
+}
+
+func TestStripNonStandardExtensions(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		extensions         map[string]string
+		expectedExtensions map[string]string
+		expectedStripped   []string
+	}{
+		"no-extensions": {},
+		"standard": {
+			extensions:         map[string]string{"permit-pty": "", "permit-user-rc": ""},
+			expectedExtensions: map[string]string{"permit-pty": "", "permit-user-rc": ""},
+		},
+		"non-standard": {
+			extensions:         map[string]string{"permit-pty": "", "no-touch-required": "", "login@example.com": "alice"},
+			expectedExtensions: map[string]string{"permit-pty": ""},
+			expectedStripped:   []string{"login@example.com", "no-touch-required"},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			cert := &ssh.Certificate{Permissions: ssh.Permissions{Extensions: tt.extensions}}
+			stripped := StripNonStandardExtensions(cert)
+			if !reflect.DeepEqual(stripped, tt.expectedStripped) {
+				t.Errorf("got stripped extensions %q, want %q", stripped, tt.expectedStripped)
+			}
+			if !reflect.DeepEqual(cert.Extensions, tt.expectedExtensions) {
+				t.Errorf("got extensions %q, want %q", cert.Extensions, tt.expectedExtensions)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Parallel()
+	newSigner := func() ssh.Signer {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signer
+	}
+	ca, other := newSigner(), newSigner()
+	cert := &ssh.Certificate{Key: other.PublicKey(), CertType: ssh.UserCert, ValidPrincipals: []string{"alice"}, ValidBefore: ssh.CertTimeInfinity}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignature(cert, ca.PublicKey()); err != nil {
+		t.Errorf("unexpected error verifying against the CA key: %v", err)
+	}
+	if err := VerifySignature(cert, other.PublicKey()); err == nil {
+		t.Error("expected an error verifying against another key")
+	}
+	tampered := *cert
+	tampered.ValidPrincipals = []string{"root"}
+	if err := VerifySignature(&tampered, ca.PublicKey()); err == nil {
+		t.Error("expected an error verifying a tampered certificate")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package sshcert
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const defaultMinRSAKeySize = 2048
+
+// SubjectKeyPolicy restricts the subject public keys of the SSH certificates to be signed.
+type SubjectKeyPolicy struct {
+	// KeyTypes is the list of allowed SSH public key types, such as "ssh-ed25519".
+	// If empty, all types but "ssh-dss" are allowed.
+	KeyTypes []string
+	// MinRSAKeySize is the minimum size in bits of an RSA key. If zero, defaults to 2048.
+	MinRSAKeySize int
+	// MaxRSAKeySize is the maximum size in bits of an RSA key. If zero, there is no maximum.
+	MaxRSAKeySize int
+}
+
+// Check returns an error if the public key is not allowed by the policy.
+func (p *SubjectKeyPolicy) Check(pub ssh.PublicKey) error {
+	if _, ok := pub.(*ssh.Certificate); ok {
+		return errors.New("certificate public keys are not allowed")
+	}
+	if !p.allows(pub.Type()) {
+		return fmt.Errorf("%s public key is not allowed", pub.Type())
+	}
+	if pub.Type() != ssh.KeyAlgoRSA {
+		return nil
+	}
+	cpk, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	key, ok := cpk.CryptoPublicKey().(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported public key type %T", cpk.CryptoPublicKey())
+	}
+	minSize := p.MinRSAKeySize
+	if minSize == 0 {
+		minSize = defaultMinRSAKeySize
+	}
+	size := key.N.BitLen()
+	if size < minSize {
+		return fmt.Errorf("RSA public key size %d is less than the minimum allowed size %d", size, minSize)
+	}
+	if p.MaxRSAKeySize != 0 && size > p.MaxRSAKeySize {
+		return fmt.Errorf("RSA public key size %d is greater than the maximum allowed size %d", size, p.MaxRSAKeySize)
+	}
+	return nil
+}
+
+// allows returns true if the SSH public key type is allowed by the policy.
+func (p *SubjectKeyPolicy) allows(keyType string) bool {
+	if len(p.KeyTypes) == 0 {
+		return keyType != ssh.KeyAlgoDSA
+	}
+	for _, t := range p.KeyTypes {
+		if t == keyType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package sshcert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSubjectKeyPolicyCheck(t *testing.T) {
+	t.Parallel()
+	newPublicKey := func(key interface{}) ssh.PublicKey {
+		pub, err := ssh.NewPublicKey(key)
+		if err != nil {
+			t.Fatalf("unable to create SSH public key: %v", err)
+		}
+		return pub
+	}
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	rsa2048, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate Ed25519 key: %v", err)
+	}
+	dsaPub, _, _, _, err := ssh.ParseAuthorizedKey([]byte("ssh-dss AAAAB3NzaC1kc3MAAACBAJO2OS5J02GNCTRdHkkCKnrAM6ZJkyHsvlixWN+16ahzqZD7ijdQwiIofchTpqAsKgXPLH3OhCMvItDrvsJ56SNbP1RlW9qcPix94Ar4xaiW5kqngf0AallzVO1yjyVA0vtjzGBiM0ShzMGYogj1+jOsjgu2/B/FvGb2gIAc/l1lAAAAFQCZdAPNrWBZ92WeSmgL42iQZqKiwwAAAIAyMcUFJYzB+CDZ5aifPYzWPyrHfi/DhHmiY4pDAjFnZUWB6N+Heo1ovITVPLL7coFwLcv1PCvAJ7H+2BPtx7OMzicfAB2OustgzMfznOeUXVtFvA4jaaBP1x/BTrH4THz3gTg/lr6kBpsb/nHzBCLRXjGxsXV/GLQfVvBqVGQruAAAAIAhD56FQ9iNOMHiK+Lin1tF5f/kHFdUMIO1DRodv2ueBTTgXjcZ28i5KVCEuifQ8e9QFy7Za1NePAc1R0MwDoytyirK4IWFZCn0X1nHd1DRuw+0yxUOwwk/HyjC5myo7wf3ZNcjzBu5Hd56POc6XtIHY88PX8dsGyzKGv5J3ops1A=="))
+	if err != nil {
+		t.Fatalf("unable to parse DSA key: %v", err)
+	}
+	cert := &ssh.Certificate{Key: newPublicKey(edPub)}
+	testcases := map[string]struct {
+		policy      *SubjectKeyPolicy
+		pub         ssh.PublicKey
+		expectError bool
+	}{
+		"default-rsa-1024":      {&SubjectKeyPolicy{}, newPublicKey(&rsa1024.PublicKey), true},
+		"default-rsa-2048":      {&SubjectKeyPolicy{}, newPublicKey(&rsa2048.PublicKey), false},
+		"default-dsa":           {&SubjectKeyPolicy{}, dsaPub, true},
+		"default-ecdsa":         {&SubjectKeyPolicy{}, newPublicKey(&p256.PublicKey), false},
+		"default-ed25519":       {&SubjectKeyPolicy{}, newPublicKey(edPub), false},
+		"default-certificate":   {&SubjectKeyPolicy{}, cert, true},
+		"min-rsa-1024":          {&SubjectKeyPolicy{MinRSAKeySize: 1024}, newPublicKey(&rsa1024.PublicKey), false},
+		"min-rsa-3072":          {&SubjectKeyPolicy{MinRSAKeySize: 3072}, newPublicKey(&rsa2048.PublicKey), true},
+		"max-rsa-1024":          {&SubjectKeyPolicy{MinRSAKeySize: 512, MaxRSAKeySize: 1024}, newPublicKey(&rsa2048.PublicKey), true},
+		"explicit-dsa":          {&SubjectKeyPolicy{KeyTypes: []string{ssh.KeyAlgoDSA}}, dsaPub, false},
+		"ed25519-only-accept":   {&SubjectKeyPolicy{KeyTypes: []string{ssh.KeyAlgoED25519}}, newPublicKey(edPub), false},
+		"ed25519-only-reject":   {&SubjectKeyPolicy{KeyTypes: []string{ssh.KeyAlgoED25519}}, newPublicKey(&rsa2048.PublicKey), true},
+		"rsa-only-small-reject": {&SubjectKeyPolicy{KeyTypes: []string{ssh.KeyAlgoRSA}}, newPublicKey(&rsa1024.PublicKey), true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			err := tt.policy.Check(tt.pub)
+			if err != nil != tt.expectError {
+				t.Errorf("in test %v: got err: %v, expect err: %v", label, err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package sshcert
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// GitNamespace is the SSHSIG namespace of the signatures of git commit and tag objects.
+const GitNamespace = "git"
+
+const (
+	sshsigMagic    = "SSHSIG"
+	sshsigVersion  = 1
+	sshsigHash     = "sha512"
+	sshsigLineSize = 70
+)
+
+// SignSSHSIG returns the armored SSHSIG signature of the message in the namespace, in the format
+// produced by "ssh-keygen -Y sign" and verified by "ssh-keygen -Y verify". RSA keys sign with rsa-sha2-512.
+func SignSSHSIG(signer ssh.Signer, namespace string, message []byte) ([]byte, error) {
+	if namespace == "" {
+		return nil, errors.New("SSHSIG namespace cannot be empty")
+	}
+	data := sshsigSignedData(namespace, message)
+	var sig *ssh.Signature
+	var err error
+	if as, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, data, ssh.SigAlgoRSASHA2512)
+	} else {
+		sig, err = signer.Sign(rand.Reader, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blob := []byte(sshsigMagic)
+	blob = appendUint32(blob, sshsigVersion)
+	blob = appendString(blob, signer.PublicKey().Marshal())
+	blob = appendString(blob, []byte(namespace))
+	blob = appendString(blob, nil)
+	blob = appendString(blob, []byte(sshsigHash))
+	blob = appendString(blob, ssh.Marshal(sig))
+	return armorSSHSIG(blob), nil
+}
+
+// sshsigSignedData returns the data whose signature is carried by the SSHSIG signature of the message in the namespace.
+func sshsigSignedData(namespace string, message []byte) []byte {
+	h := sha512.Sum512(message)
+	data := []byte(sshsigMagic)
+	data = appendString(data, []byte(namespace))
+	data = appendString(data, nil)
+	data = appendString(data, []byte(sshsigHash))
+	return appendString(data, h[:])
+}
+
+// armorSSHSIG returns the PEM-like armor of the SSHSIG signature blob.
+func armorSSHSIG(blob []byte) []byte {
+	enc := base64.StdEncoding.EncodeToString(blob)
+	var b bytes.Buffer
+	b.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(enc) > sshsigLineSize {
+		b.WriteString(enc[:sshsigLineSize])
+		b.WriteByte('\n')
+		enc = enc[sshsigLineSize:]
+	}
+	b.WriteString(enc)
+	b.WriteString("\n-----END SSH SIGNATURE-----\n")
+	return b.Bytes()
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// appendString appends the SSH wire encoding of the string s.
+func appendString(b []byte, s []byte) []byte {
+	return append(appendUint32(b, uint32(len(s))), s...)
+}
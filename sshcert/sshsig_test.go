@@ -0,0 +1,137 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package sshcert
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+// parseSSHSIG parses the armored SSHSIG signature and verifies it against the public key, as
+// "ssh-keygen -Y verify" does.
+func parseSSHSIG(t *testing.T, armored []byte, pub ssh.PublicKey, namespace string, message []byte) *ssh.Signature {
+	t.Helper()
+	s := strings.TrimSpace(string(armored))
+	if !strings.HasPrefix(s, "-----BEGIN SSH SIGNATURE-----\n") || !strings.HasSuffix(s, "\n-----END SSH SIGNATURE-----") {
+		t.Fatalf("bad armor: %q", armored)
+	}
+	s = strings.TrimPrefix(strings.TrimSuffix(s, "-----END SSH SIGNATURE-----"), "-----BEGIN SSH SIGNATURE-----")
+	blob, err := base64.StdEncoding.DecodeString(strings.Replace(s, "\n", "", -1))
+	if err != nil {
+		t.Fatalf("bad base64: %v", err)
+	}
+	if !bytes.HasPrefix(blob, []byte("SSHSIG")) {
+		t.Fatalf("bad magic: %q", blob)
+	}
+	var fields struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      []byte
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(blob[len("SSHSIG"):], &fields); err != nil {
+		t.Fatalf("bad signature blob: %v", err)
+	}
+	if fields.Version != 1 || fields.Namespace != namespace || fields.HashAlgorithm != "sha512" || !bytes.Equal(fields.PublicKey, pub.Marshal()) {
+		t.Fatalf("unexpected signature fields: %+v", fields)
+	}
+	sig := &ssh.Signature{}
+	if err := ssh.Unmarshal(fields.Signature, sig); err != nil {
+		t.Fatalf("bad signature: %v", err)
+	}
+	if err := pub.Verify(sshsigSignedData(namespace, message), sig); err != nil {
+		t.Fatalf("unable to verify signature: %v", err)
+	}
+	return sig
+}
+
+// sshKeygenVerify verifies the armored SSHSIG signature with ssh-keygen if it is installed.
+func sshKeygenVerify(t *testing.T, armored []byte, pub ssh.PublicKey, namespace string, message []byte) {
+	t.Helper()
+	path, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		return
+	}
+	dir, err := ioutil.TempDir("", "sshsig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	signers := filepath.Join(dir, "allowed_signers")
+	if err := ioutil.WriteFile(signers, append([]byte("signer@example.com "), ssh.MarshalAuthorizedKey(pub)...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sigFile := filepath.Join(dir, "message.sig")
+	if err := ioutil.WriteFile(sigFile, armored, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(path, "-Y", "verify", "-f", signers, "-I", "signer@example.com", "-n", namespace, "-s", sigFile)
+	cmd.Stdin = bytes.NewReader(message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen -Y verify failed: %v: %s", err, out)
+	}
+}
+
+func TestSignSSHSIG(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor A U Thor <author@example.com> 1112911993 -0700\n\ninitial commit\n")
+	testcases := map[string]struct {
+		key         interface{}
+		expectedAlg string
+	}{
+		"rsa":     {rsaKey, ssh.SigAlgoRSASHA2512},
+		"ecdsa":   {ecKey, ssh.KeyAlgoECDSA256},
+		"ed25519": {edKey, ssh.KeyAlgoED25519},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			signer, err := ssh.NewSignerFromKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			armored, err := SignSSHSIG(signer, GitNamespace, message)
+			if err != nil {
+				t.Fatalf("in test %v: unable to sign: %v", label, err)
+			}
+			sig := parseSSHSIG(t, armored, signer.PublicKey(), GitNamespace, message)
+			if sig.Format != tt.expectedAlg {
+				t.Errorf("in test %v: got signature algorithm %q, want %q", label, sig.Format, tt.expectedAlg)
+			}
+			if err := signer.PublicKey().Verify(sshsigSignedData("file", message), sig); err == nil {
+				t.Errorf("in test %v: signature verified in another namespace", label)
+			}
+			sshKeygenVerify(t, armored, signer.PublicKey(), GitNamespace, message)
+		})
+	}
+}
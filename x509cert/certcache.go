@@ -0,0 +1,120 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// CertFileCache caches the certificates parsed from PEM files by path, so that a file loaded again, e.g.
+// a CA certificate shared by several keys or reloaded with a key after a login retry, is only parsed
+// again if it changed. A cached parse is only returned if the SHA-256 digest of the current content of
+// the file matches the one of the parsed content, and the cached certificates are the DER encoded
+// certificates of the current content. A nil CertFileCache parses the files on every load.
+type CertFileCache struct {
+	mu      sync.Mutex
+	entries map[string]certFileEntry
+	// parse parses the content of the files. It is replaced by the tests to count the parses.
+	parse func([]byte) ([]*x509.Certificate, error)
+}
+
+type certFileEntry struct {
+	sum   [sha256.Size]byte
+	certs []*x509.Certificate
+}
+
+// NewCertFileCache returns an empty CertFileCache.
+func NewCertFileCache() *CertFileCache {
+	return &CertFileCache{entries: make(map[string]certFileEntry), parse: ParsePEMCertificates}
+}
+
+// Load returns the certificates of the PEM file at path. Errors are not cached.
+func (c *CertFileCache) Load(path string) ([]*x509.Certificate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return parseCertFile(path, data, ParsePEMCertificates)
+	}
+	sum := sha256.Sum256(data)
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.sum == sum && bytes.Equal(pemCertsDER(data), certsDER(entry.certs)) {
+		return append([]*x509.Certificate(nil), entry.certs...), nil
+	}
+	certs, err := parseCertFile(path, data, c.parse)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[path] = certFileEntry{sum: sum, certs: certs}
+	c.mu.Unlock()
+	return append([]*x509.Certificate(nil), certs...), nil
+}
+
+// parseCertFile parses the content of the PEM file at path with parse.
+func parseCertFile(path string, data []byte, parse func([]byte) ([]*x509.Certificate, error)) ([]*x509.Certificate, error) {
+	certs, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return certs, nil
+}
+
+// certsDER returns the concatenation of the DER encodings of the certificates.
+func certsDER(certs []*x509.Certificate) []byte {
+	var der []byte
+	for _, cert := range certs {
+		der = append(der, cert.Raw...)
+	}
+	return der
+}
+
+// pemCertsDER returns the concatenation of the contents of the CERTIFICATE blocks of the PEM data.
+func pemCertsDER(data []byte) []byte {
+	var der []byte
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return der
+		}
+		if block.Type == "CERTIFICATE" {
+			der = append(der, block.Bytes...)
+		}
+	}
+}
+
+// ParsePEMCertificates returns the certificates of the CERTIFICATE blocks of the PEM data, in order.
+// The other blocks are skipped.
+func ParsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	return certs, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCertFileCache(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	newCertPEM := func(cn string) []byte {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		if err != nil {
+			t.Fatalf("unable to create certificate: %v", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	dir, err := ioutil.TempDir("", "certcache")
+	if err != nil {
+		t.Fatalf("unable to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ca.crt")
+	write := func(data []byte) {
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("unable to write %s: %v", path, err)
+		}
+	}
+
+	parses := 0
+	cache := NewCertFileCache()
+	cache.parse = func(data []byte) ([]*x509.Certificate, error) {
+		parses++
+		return ParsePEMCertificates(data)
+	}
+	load := func(wantCN string, wantParses int) {
+		t.Helper()
+		certs, err := cache.Load(path)
+		if err != nil {
+			t.Fatalf("unable to load %s: %v", path, err)
+		}
+		if got := certs[0].Subject.CommonName; got != wantCN {
+			t.Errorf("got certificate of %q, want %q", got, wantCN)
+		}
+		if parses != wantParses {
+			t.Errorf("got %d parses, want %d", parses, wantParses)
+		}
+	}
+
+	write(newCertPEM("ca1"))
+	load("ca1", 1)
+	// An unchanged file is not parsed again, even if it is rewritten.
+	load("ca1", 1)
+	data, _ := ioutil.ReadFile(path)
+	write(data)
+	load("ca1", 1)
+	// A changed file is parsed again.
+	write(newCertPEM("ca2"))
+	load("ca2", 2)
+	load("ca2", 2)
+
+	// The failed parses are not cached.
+	write([]byte("not a certificate"))
+	if _, err := cache.Load(path); err == nil {
+		t.Error("got no error for an invalid certificate file")
+	}
+	if _, err := cache.Load(path); err == nil || parses != 4 {
+		t.Errorf("got err %v after %d parses, want an error after 4 parses", err, parses)
+	}
+	// A nil cache parses the file on every load.
+	write(newCertPEM("ca3"))
+	var nilCache *CertFileCache
+	if certs, err := nilCache.Load(path); err != nil || certs[0].Subject.CommonName != "ca3" {
+		t.Errorf("got %v, %v from a nil cache, want the certificate of ca3", certs, err)
+	}
+}
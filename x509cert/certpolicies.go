@@ -0,0 +1,59 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+)
+
+var (
+	oidCertificatePolicies = asn1.ObjectIdentifier{2, 5, 29, 32}
+	// oidCPSQualifier is id-qt-cps, the qualifier of the URI of a certification practice statement.
+	oidCPSQualifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 1}
+)
+
+// CertificatePolicy is a policy of the CertificatePolicies extension, qualified by the URIs of its
+// certification practice statements if any.
+type CertificatePolicy struct {
+	OID     asn1.ObjectIdentifier
+	CPSURIs []string
+}
+
+// policyInformation is the PolicyInformation of RFC 5280, section 4.2.1.4.
+type policyInformation struct {
+	Policy     asn1.ObjectIdentifier
+	Qualifiers []policyQualifierInfo `asn1:"optional,omitempty"`
+}
+
+type policyQualifierInfo struct {
+	QualifierID asn1.ObjectIdentifier
+	Qualifier   string `asn1:"ia5"`
+}
+
+// AddCertificatePolicies adds the non-critical CertificatePolicies extension with the policies to the
+// (unsigned) certificate. It is a no-op if there is no policy.
+func AddCertificatePolicies(cert *x509.Certificate, policies []CertificatePolicy) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	var infos []policyInformation
+	cert.PolicyIdentifiers = nil
+	for _, p := range policies {
+		info := policyInformation{Policy: p.OID}
+		for _, uri := range p.CPSURIs {
+			info.Qualifiers = append(info.Qualifiers, policyQualifierInfo{QualifierID: oidCPSQualifier, Qualifier: uri})
+		}
+		infos = append(infos, info)
+		cert.PolicyIdentifiers = append(cert.PolicyIdentifiers, p.OID)
+	}
+	value, err := asn1.Marshal(infos)
+	if err != nil {
+		return err
+	}
+	// The extension overrides the one that crypto/x509 would encode from PolicyIdentifiers, without qualifiers.
+	cert.ExtraExtensions = append(cert.ExtraExtensions, pkix.Extension{Id: oidCertificatePolicies, Value: value})
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	oidAttrSigningTime  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSHA256WithRSA    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECDSAWithSHA256  = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384  = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512  = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	cmsDigestAlgorithms = map[crypto.Hash]asn1.ObjectIdentifier{crypto.SHA256: oidSHA256, crypto.SHA384: oidSHA384, crypto.SHA512: oidSHA512}
+	rsaSignatureOIDs    = map[crypto.Hash]asn1.ObjectIdentifier{crypto.SHA256: oidSHA256WithRSA, crypto.SHA384: oidSHA384WithRSA, crypto.SHA512: oidSHA512WithRSA}
+	ecdsaSignatureOIDs  = map[crypto.Hash]asn1.ObjectIdentifier{crypto.SHA256: oidECDSAWithSHA256, crypto.SHA384: oidECDSAWithSHA384, crypto.SHA512: oidECDSAWithSHA512}
+)
+
+// SignDetachedCMS returns the DER encoded ContentInfo of a detached CMS SignedData (RFC 5652) of the content
+// whose digest was computed with hash. The signed attributes are the content-type of id-data, the message-digest,
+// which is the digest, and the signing-time. The signer is identified by cert, which is included in the
+// SignedData, and signs the attributes with PKCS #1 v1.5 for RSA keys and with ECDSA for ECDSA keys.
+func SignDetachedCMS(signer crypto.Signer, cert *x509.Certificate, digest []byte, hash crypto.Hash, signingTime time.Time) ([]byte, error) {
+	digestAlg, ok := cmsDigestAlgorithms[hash]
+	if !ok {
+		return nil, fmt.Errorf("unsupported CMS hash algorithm %v", hash)
+	}
+	if len(digest) != hash.Size() {
+		return nil, fmt.Errorf("invalid digest length: got %d bytes, want %d", len(digest), hash.Size())
+	}
+	sigAlg, err := signatureAlgorithm(signer.Public(), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := asn1.Marshal(oidData)
+	if err != nil {
+		return nil, err
+	}
+	messageDigest, err := asn1.Marshal(digest)
+	if err != nil {
+		return nil, err
+	}
+	// The time is encoded as UTCTime until 2049 and as GeneralizedTime afterwards, as RFC 5652 section 11.3 requires.
+	signedTime, err := asn1.Marshal(signingTime.UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	signedAttrs, err := marshalAttributes([]attribute{
+		{Type: oidAttrContentType, Values: []asn1.RawValue{{FullBytes: contentType}}},
+		{Type: oidAttrMessageDigest, Values: []asn1.RawValue{{FullBytes: messageDigest}}},
+		{Type: oidAttrSigningTime, Values: []asn1.RawValue{{FullBytes: signedTime}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(signedAttrs)
+	signature, err := signer.Sign(rand.Reader, h.Sum(nil), hash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign CMS signed attributes: %v", err)
+	}
+
+	sd := signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: digestAlg}},
+		EncapContentInfo: encapsulatedContentInfo{EContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: cert.Raw},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                issuerAndSerialNumber{Issuer: asn1.RawValue{FullBytes: cert.RawIssuer}, SerialNumber: cert.SerialNumber},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: digestAlg},
+			SignedAttrs:        asn1.RawValue{FullBytes: append([]byte{0xa0}, signedAttrs[1:]...)},
+			SignatureAlgorithm: sigAlg,
+			Signature:          signature,
+		}},
+	}
+	content, err := asn1.Marshal(sd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode SignedData: %v", err)
+	}
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	})
+}
+
+// marshalAttributes returns the DER encoded SET OF the attributes, over which the signature of a SignerInfo is
+// computed (RFC 5652 section 5.4). The SignerInfo holds them [0] IMPLICIT instead.
+func marshalAttributes(attributes []attribute) ([]byte, error) {
+	var attrs [][]byte
+	for _, attr := range attributes {
+		der, err := asn1.Marshal(attr)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, der)
+	}
+	// DER requires the elements of a SET OF to be sorted by their encodings.
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+	return asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: bytes.Join(attrs, nil)})
+}
+
+// signatureAlgorithm returns the signature algorithm of the signatures of the key over digests of hash.
+func signatureAlgorithm(pub crypto.PublicKey, hash crypto.Hash) (pkix.AlgorithmIdentifier, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		if oid, ok := rsaSignatureOIDs[hash]; ok {
+			return pkix.AlgorithmIdentifier{Algorithm: oid, Parameters: asn1.NullRawValue}, nil
+		}
+	case *ecdsa.PublicKey:
+		if oid, ok := ecdsaSignatureOIDs[hash]; ok {
+			return pkix.AlgorithmIdentifier{Algorithm: oid}, nil
+		}
+	default:
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported signing key type %T", pub)
+	}
+	return pkix.AlgorithmIdentifier{}, fmt.Errorf("unsupported hash algorithm %v for %T", hash, pub)
+}
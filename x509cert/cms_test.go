@@ -0,0 +1,123 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+	"time"
+)
+
+func TestSignDetachedCMS(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha256Digest := sha256.Sum256([]byte("message"))
+	sha384Digest := sha512.Sum384([]byte("message"))
+	signingTime := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testcases := map[string]struct {
+		key         crypto.Signer
+		digest      []byte
+		hash        crypto.Hash
+		algo        x509.SignatureAlgorithm
+		expectError bool
+	}{
+		"good-rsa-sha256":     {key: rsaKey, digest: sha256Digest[:], hash: crypto.SHA256, algo: x509.SHA256WithRSA},
+		"good-ecdsa-sha384":   {key: ecKey, digest: sha384Digest[:], hash: crypto.SHA384, algo: x509.ECDSAWithSHA384},
+		"bad-digest-length":   {key: rsaKey, digest: sha256Digest[:], hash: crypto.SHA384, expectError: true},
+		"bad-hash-algorithm":  {key: rsaKey, digest: sha256Digest[:20], hash: crypto.SHA1, expectError: true},
+		"bad-unsupported-key": {key: edKey, digest: sha256Digest[:], hash: crypto.SHA256, expectError: true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			// The test TSA is reused for its self-signed certificate of the key.
+			cert := newTestTSA(t, tt.key).Cert
+			der, err := SignDetachedCMS(tt.key, cert, tt.digest, tt.hash, signingTime)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+
+			var ci contentInfo
+			if rest, err := asn1.Unmarshal(der, &ci); err != nil || len(rest) != 0 || !ci.ContentType.Equal(oidSignedData) {
+				t.Fatalf("unable to parse ContentInfo of signedData: %v", err)
+			}
+			var sd signedData
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+				t.Fatalf("unable to parse SignedData: %v", err)
+			}
+			if !sd.EncapContentInfo.EContentType.Equal(oidData) || len(sd.EncapContentInfo.EContent) != 0 {
+				t.Errorf("got encapsulated content %+v, want detached id-data", sd.EncapContentInfo)
+			}
+			if !bytes.Equal(sd.Certificates.Bytes, cert.Raw) {
+				t.Error("SignedData does not include the signer certificate")
+			}
+			if len(sd.SignerInfos) != 1 {
+				t.Fatalf("got %d SignerInfos, want 1", len(sd.SignerInfos))
+			}
+			si := sd.SignerInfos[0]
+			if si.SID.SerialNumber.Cmp(cert.SerialNumber) != 0 || !bytes.Equal(si.SID.Issuer.FullBytes, cert.RawIssuer) {
+				t.Error("signer identifier does not match the certificate")
+			}
+
+			// The signature covers the signed attributes encoded as a SET OF.
+			signed := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+			if err := cert.CheckSignature(tt.algo, signed, si.Signature); err != nil {
+				t.Fatalf("unable to verify the signature: %v", err)
+			}
+			var attrs []attribute
+			if _, err := asn1.UnmarshalWithParams(signed, &attrs, "set"); err != nil {
+				t.Fatalf("unable to parse signed attributes: %v", err)
+			}
+			found := map[string]bool{}
+			for _, attr := range attrs {
+				found[attr.Type.String()] = true
+				switch {
+				case attr.Type.Equal(oidAttrContentType):
+					var contentType asn1.ObjectIdentifier
+					if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &contentType); err != nil || !contentType.Equal(oidData) {
+						t.Errorf("got content type attribute %v, want id-data", contentType)
+					}
+				case attr.Type.Equal(oidAttrMessageDigest):
+					var md []byte
+					if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &md); err != nil || !bytes.Equal(md, tt.digest) {
+						t.Errorf("message digest attribute does not match the digest")
+					}
+				case attr.Type.Equal(oidAttrSigningTime):
+					var st time.Time
+					if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &st); err != nil || !st.Equal(signingTime) {
+						t.Errorf("got signing time %v, want %v", st, signingTime)
+					}
+				}
+			}
+			if len(found) != 3 {
+				t.Errorf("got signed attributes %v, want content type, message digest and signing time", found)
+			}
+		})
+	}
+}
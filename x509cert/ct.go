@@ -0,0 +1,364 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cbasn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+var (
+	// oidCTPoison is the critical extension that makes a precertificate unusable as a certificate (RFC 6962 section 3.1).
+	oidCTPoison = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
+	// oidSCTList is the extension holding the SCTs embedded in a certificate (RFC 6962 section 3.3).
+	oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+)
+
+const (
+	// ctSubmissionTimeout is the timeout of a precertificate submission to a CT log.
+	ctSubmissionTimeout = 10 * time.Second
+	// Values of the TLS encoded structures of RFC 6962.
+	sctVersionV1             = 0
+	signatureTypeTimestamp   = 0
+	entryTypePrecert         = 1
+	tlsHashSHA256            = 4
+	tlsSignatureRSA          = 1
+	tlsSignatureECDSA        = 3
+	maxSCTResponseBodyLength = 1 << 16
+)
+
+// SCT is a signed certificate timestamp (RFC 6962 section 3.2) returned by a CT log for a precertificate.
+type SCT struct {
+	LogID      [sha256.Size]byte
+	Timestamp  uint64
+	Extensions []byte
+	// HashAlgorithm and SignatureAlgorithm are the TLS algorithm identifiers of the signature of the log.
+	HashAlgorithm, SignatureAlgorithm uint8
+	Signature                         []byte
+}
+
+// marshal adds the TLS encoding of the SCT to b.
+func (s *SCT) marshal(b *cryptobyte.Builder) {
+	b.AddUint8(sctVersionV1)
+	b.AddBytes(s.LogID[:])
+	addUint64(b, s.Timestamp)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(s.Extensions) })
+	b.AddUint8(s.HashAlgorithm)
+	b.AddUint8(s.SignatureAlgorithm)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(s.Signature) })
+}
+
+func addUint64(b *cryptobyte.Builder, v uint64) {
+	b.AddUint32(uint32(v >> 32))
+	b.AddUint32(uint32(v))
+}
+
+// Precertificate returns a copy of the certificate template with the CT poison extension.
+func Precertificate(cert *x509.Certificate) *x509.Certificate {
+	precert := *cert
+	precert.ExtraExtensions = append(append([]pkix.Extension{}, cert.ExtraExtensions...), pkix.Extension{
+		Id:       oidCTPoison,
+		Critical: true,
+		Value:    asn1.NullBytes,
+	})
+	return &precert
+}
+
+// EmbedSCTs adds the SCT list extension holding the SCTs to the certificate template.
+func EmbedSCTs(cert *x509.Certificate, scts []*SCT) error {
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, sct := range scts {
+			b.AddUint16LengthPrefixed(sct.marshal)
+		}
+	})
+	list, err := b.Bytes()
+	if err != nil {
+		return fmt.Errorf("unable to encode SCT list: %v", err)
+	}
+	value, err := asn1.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("unable to encode SCT list extension: %v", err)
+	}
+	cert.ExtraExtensions = append(cert.ExtraExtensions, pkix.Extension{Id: oidSCTList, Value: value})
+	return nil
+}
+
+// ParseSCTs returns the SCTs embedded in the certificate.
+func ParseSCTs(cert *x509.Certificate) ([]*SCT, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSCTList) {
+			continue
+		}
+		var list []byte
+		if rest, err := asn1.Unmarshal(ext.Value, &list); err != nil || len(rest) != 0 {
+			return nil, errors.New("invalid SCT list extension")
+		}
+		input := cryptobyte.String(list)
+		var entries cryptobyte.String
+		if !input.ReadUint16LengthPrefixed(&entries) || !input.Empty() {
+			return nil, errors.New("invalid SCT list")
+		}
+		var scts []*SCT
+		for !entries.Empty() {
+			var entry cryptobyte.String
+			if !entries.ReadUint16LengthPrefixed(&entry) {
+				return nil, errors.New("invalid SCT list")
+			}
+			sct, err := parseSCT(entry)
+			if err != nil {
+				return nil, err
+			}
+			scts = append(scts, sct)
+		}
+		return scts, nil
+	}
+	return nil, nil
+}
+
+func parseSCT(s cryptobyte.String) (*SCT, error) {
+	sct := &SCT{}
+	var version uint8
+	var hi, lo uint32
+	var extensions, signature cryptobyte.String
+	if !s.ReadUint8(&version) || !s.CopyBytes(sct.LogID[:]) || !s.ReadUint32(&hi) || !s.ReadUint32(&lo) ||
+		!s.ReadUint16LengthPrefixed(&extensions) || !s.ReadUint8(&sct.HashAlgorithm) ||
+		!s.ReadUint8(&sct.SignatureAlgorithm) || !s.ReadUint16LengthPrefixed(&signature) || !s.Empty() {
+		return nil, errors.New("invalid SCT")
+	}
+	if version != sctVersionV1 {
+		return nil, fmt.Errorf("unsupported SCT version %d", version)
+	}
+	sct.Timestamp = uint64(hi)<<32 | uint64(lo)
+	sct.Extensions = []byte(extensions)
+	sct.Signature = []byte(signature)
+	return sct, nil
+}
+
+// CTLog is a certificate transparency log that precertificates are submitted to.
+type CTLog struct {
+	url       string
+	publicKey crypto.PublicKey
+	id        [sha256.Size]byte
+	client    *http.Client
+}
+
+// NewCTLog returns the CTLog of the RFC 6962 API at url whose key is the DER encoded public key.
+func NewCTLog(url string, publicKey []byte) (*CTLog, error) {
+	pub, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse public key of CT log %s: %v", url, err)
+	}
+	switch pub.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T of CT log %s", pub, url)
+	}
+	return &CTLog{
+		url:       strings.TrimSuffix(url, "/"),
+		publicKey: pub,
+		id:        sha256.Sum256(publicKey),
+		client:    &http.Client{Timeout: ctSubmissionTimeout},
+	}, nil
+}
+
+// URL returns the URL of the log.
+func (l *CTLog) URL() string {
+	return l.url
+}
+
+// addChainResponse is the response of the add-pre-chain method (RFC 6962 section 4.1).
+type addChainResponse struct {
+	SCTVersion uint8  `json:"sct_version"`
+	ID         []byte `json:"id"`
+	Timestamp  uint64 `json:"timestamp"`
+	Extensions string `json:"extensions"`
+	Signature  []byte `json:"signature"`
+}
+
+// AddPreChain submits the DER encoded precertificate and the chain of its issuer, starting with the
+// issuer certificate, to the log and returns the SCT of the log after verifying its signature.
+func (l *CTLog) AddPreChain(ctx context.Context, precert []byte, chain [][]byte) (*SCT, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("missing issuer certificate")
+	}
+	body, err := json.Marshal(struct {
+		Chain [][]byte `json:"chain"`
+	}{append([][]byte{precert}, chain...)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, l.url+"/ct/v1/add-pre-chain", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := l.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("unable to submit precertificate to CT log %s: %v", l.url, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSCTResponseBodyLength))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response of CT log %s: %v", l.url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CT log %s rejected precertificate: %s", l.url, resp.Status)
+	}
+	var r addChainResponse
+	if err := json.Unmarshal(respBody, &r); err != nil {
+		return nil, fmt.Errorf("unable to parse response of CT log %s: %v", l.url, err)
+	}
+	sct, err := l.parseResponse(&r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCT from CT log %s: %v", l.url, err)
+	}
+	if err := l.verify(sct, precert, chain[0]); err != nil {
+		return nil, fmt.Errorf("invalid SCT from CT log %s: %v", l.url, err)
+	}
+	return sct, nil
+}
+
+func (l *CTLog) parseResponse(r *addChainResponse) (*SCT, error) {
+	if r.SCTVersion != sctVersionV1 {
+		return nil, fmt.Errorf("unsupported SCT version %d", r.SCTVersion)
+	}
+	if !bytes.Equal(r.ID, l.id[:]) {
+		return nil, errors.New("log ID does not match the log key")
+	}
+	if r.Extensions != "" {
+		return nil, errors.New("SCT extensions are not supported")
+	}
+	sct := &SCT{LogID: l.id, Timestamp: r.Timestamp}
+	signature := cryptobyte.String(r.Signature)
+	var sig cryptobyte.String
+	if !signature.ReadUint8(&sct.HashAlgorithm) || !signature.ReadUint8(&sct.SignatureAlgorithm) ||
+		!signature.ReadUint16LengthPrefixed(&sig) || !signature.Empty() {
+		return nil, errors.New("invalid signature encoding")
+	}
+	sct.Signature = []byte(sig)
+	return sct, nil
+}
+
+// verify checks the signature of the log over the SCT of the precertificate issued by the issuer.
+func (l *CTLog) verify(sct *SCT, precert, issuer []byte) error {
+	data, err := precertSignedData(sct, precert, issuer)
+	if err != nil {
+		return err
+	}
+	if sct.HashAlgorithm != tlsHashSHA256 {
+		return fmt.Errorf("unsupported hash algorithm %d", sct.HashAlgorithm)
+	}
+	digest := sha256.Sum256(data)
+	switch pub := l.publicKey.(type) {
+	case *rsa.PublicKey:
+		if sct.SignatureAlgorithm != tlsSignatureRSA {
+			return fmt.Errorf("signature algorithm %d does not match RSA log key", sct.SignatureAlgorithm)
+		}
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sct.Signature)
+	case *ecdsa.PublicKey:
+		if sct.SignatureAlgorithm != tlsSignatureECDSA {
+			return fmt.Errorf("signature algorithm %d does not match ECDSA log key", sct.SignatureAlgorithm)
+		}
+		if !ecdsa.VerifyASN1(pub, digest[:], sct.Signature) {
+			return errors.New("signature verification failed")
+		}
+	}
+	return nil
+}
+
+// precertSignedData returns the data signed by a CT log in the SCT of the precertificate (RFC 6962 section 3.2).
+func precertSignedData(sct *SCT, precert, issuer []byte) ([]byte, error) {
+	issuerCert, err := x509.ParseCertificate(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse issuer certificate: %v", err)
+	}
+	precertCert, err := x509.ParseCertificate(precert)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse precertificate: %v", err)
+	}
+	tbs, err := removeExtension(precertCert.RawTBSCertificate, oidCTPoison)
+	if err != nil {
+		return nil, err
+	}
+	issuerKeyHash := sha256.Sum256(issuerCert.RawSubjectPublicKeyInfo)
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint8(sctVersionV1)
+	b.AddUint8(signatureTypeTimestamp)
+	addUint64(b, sct.Timestamp)
+	b.AddUint16(entryTypePrecert)
+	b.AddBytes(issuerKeyHash[:])
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(tbs) })
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sct.Extensions) })
+	return b.Bytes()
+}
+
+// removeExtension returns the DER encoded TBSCertificate without the extension.
+func removeExtension(rawTBS []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	errInvalid := errors.New("invalid TBSCertificate")
+	input := cryptobyte.String(rawTBS)
+	var tbs cryptobyte.String
+	if !input.ReadASN1(&tbs, cbasn1.SEQUENCE) || !input.Empty() {
+		return nil, errInvalid
+	}
+	extensionsTag := cbasn1.Tag(3).Constructed().ContextSpecific()
+	b := cryptobyte.NewBuilder(nil)
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		for !tbs.Empty() {
+			var field cryptobyte.String
+			var tag cbasn1.Tag
+			if !tbs.ReadAnyASN1Element(&field, &tag) {
+				b.SetError(errInvalid)
+				return
+			}
+			if tag != extensionsTag {
+				b.AddBytes(field)
+				continue
+			}
+			var explicit, extensions cryptobyte.String
+			if !field.ReadASN1(&explicit, extensionsTag) || !explicit.ReadASN1(&extensions, cbasn1.SEQUENCE) {
+				b.SetError(errInvalid)
+				return
+			}
+			b.AddASN1(extensionsTag, func(b *cryptobyte.Builder) {
+				b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					for !extensions.Empty() {
+						var ext, body cryptobyte.String
+						var id asn1.ObjectIdentifier
+						if !extensions.ReadASN1Element(&ext, cbasn1.SEQUENCE) {
+							b.SetError(errInvalid)
+							return
+						}
+						if elem := ext; !elem.ReadASN1(&body, cbasn1.SEQUENCE) || !body.ReadASN1ObjectIdentifier(&id) {
+							b.SetError(errInvalid)
+							return
+						}
+						if !id.Equal(oid) {
+							b.AddBytes(ext)
+						}
+					}
+				})
+			})
+		}
+	})
+	return b.Bytes()
+}
@@ -0,0 +1,234 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// mockCTLog is an RFC 6962 log that returns an SCT signed by key for every submitted precertificate.
+type mockCTLog struct {
+	key *ecdsa.PrivateKey
+	// id overrides the log ID of the SCTs if set.
+	id []byte
+	// corrupt specifies whether the signatures of the SCTs are corrupted.
+	corrupt bool
+	// status overrides the HTTP status of the responses if set.
+	status int
+}
+
+func (m *mockCTLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ct/v1/add-pre-chain" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	if m.status != 0 {
+		w.WriteHeader(m.status)
+		return
+	}
+	var req struct {
+		Chain [][]byte `json:"chain"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Chain) < 2 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&m.key.PublicKey)
+	id := sha256.Sum256(der)
+	sct := &SCT{LogID: id, Timestamp: uint64(time.Now().UnixNano() / int64(time.Millisecond))}
+	data, err := precertSignedData(sct, req.Chain[0], req.Chain[1])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	digest := sha256.Sum256(data)
+	sig, _ := ecdsa.SignASN1(rand.Reader, m.key, digest[:])
+	if m.corrupt {
+		sig[len(sig)-1] ^= 0xff
+	}
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint8(tlsHashSHA256)
+	b.AddUint8(tlsSignatureECDSA)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sig) })
+	respID := id[:]
+	if m.id != nil {
+		respID = m.id
+	}
+	_ = json.NewEncoder(w).Encode(&addChainResponse{ID: respID, Timestamp: sct.Timestamp, Signature: b.BytesOrPanic()})
+}
+
+// newTestCA returns a self-signed CA certificate and its key.
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, key
+}
+
+func newTestLeafTemplate() *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "leaf.example.com"},
+		DNSNames:     []string{"leaf.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+}
+
+func TestPrecertificate(t *testing.T) {
+	t.Parallel()
+	tmpl := newTestLeafTemplate()
+	precert := Precertificate(tmpl)
+	if len(tmpl.ExtraExtensions) != 0 {
+		t.Fatalf("Precertificate modified the template: %v", tmpl.ExtraExtensions)
+	}
+	ca, caKey := newTestCA(t)
+	der, err := x509.CreateCertificate(rand.Reader, precert, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("unable to create precertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unable to parse precertificate: %v", err)
+	}
+	found := false
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidCTPoison) {
+			found = true
+			if !ext.Critical || !bytes.Equal(ext.Value, []byte{0x05, 0x00}) {
+				t.Errorf("bad poison extension: %+v", ext)
+			}
+		}
+	}
+	if !found {
+		t.Error("poison extension not found in precertificate")
+	}
+}
+
+func TestCTLogAddPreChain(t *testing.T) {
+	t.Parallel()
+	logKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logKeyDER, _ := x509.MarshalPKIXPublicKey(&logKey.PublicKey)
+	ca, caKey := newTestCA(t)
+
+	testcases := map[string]struct {
+		log         *mockCTLog
+		expectError bool
+	}{
+		"good":          {log: &mockCTLog{key: logKey}},
+		"wrong-log-key": {log: &mockCTLog{key: otherKey, id: func() []byte { id := sha256.Sum256(logKeyDER); return id[:] }()}, expectError: true},
+		"wrong-log-id":  {log: &mockCTLog{key: logKey, id: make([]byte, sha256.Size)}, expectError: true},
+		"bad-signature": {log: &mockCTLog{key: logKey, corrupt: true}, expectError: true},
+		"log-error":     {log: &mockCTLog{key: logKey, status: http.StatusServiceUnavailable}, expectError: true},
+	}
+	for label, tt := range testcases {
+		label, tt := label, tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(tt.log)
+			defer srv.Close()
+			ctLog, err := NewCTLog(srv.URL+"/", logKeyDER)
+			if err != nil {
+				t.Fatalf("unable to create CT log: %v", err)
+			}
+
+			tmpl := newTestLeafTemplate()
+			precert, err := x509.CreateCertificate(rand.Reader, Precertificate(tmpl), ca, &caKey.PublicKey, caKey)
+			if err != nil {
+				t.Fatalf("unable to create precertificate: %v", err)
+			}
+			sct, err := ctLog.AddPreChain(context.Background(), precert, [][]byte{ca.Raw})
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+
+			if err := EmbedSCTs(tmpl, []*SCT{sct}); err != nil {
+				t.Fatalf("unable to embed SCTs: %v", err)
+			}
+			der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &caKey.PublicKey, caKey)
+			if err != nil {
+				t.Fatalf("unable to create certificate: %v", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatalf("unable to parse certificate: %v", err)
+			}
+			scts, err := ParseSCTs(cert)
+			if err != nil {
+				t.Fatalf("unable to parse SCTs: %v", err)
+			}
+			if len(scts) != 1 {
+				t.Fatalf("got %d SCTs, want 1", len(scts))
+			}
+			// The embedded SCT must still verify, and the certificate must match the precertificate
+			// the SCT was issued for once the poison and SCT list extensions are removed.
+			if err := ctLog.verify(scts[0], precert, ca.Raw); err != nil {
+				t.Errorf("unable to verify embedded SCT: %v", err)
+			}
+			precertCert, _ := x509.ParseCertificate(precert)
+			precertTBS, err := removeExtension(precertCert.RawTBSCertificate, oidCTPoison)
+			if err != nil {
+				t.Fatal(err)
+			}
+			certTBS, err := removeExtension(cert.RawTBSCertificate, oidSCTList)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(precertTBS, certTBS) {
+				t.Error("certificate does not match its precertificate")
+			}
+		})
+	}
+}
+
+func TestNewCTLog(t *testing.T) {
+	t.Parallel()
+	if _, err := NewCTLog("https://ct.example.com", []byte("bad key")); err == nil {
+		t.Error("expected error for bad log key")
+	}
+}
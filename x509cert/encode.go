@@ -8,6 +8,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/yahoo/crypki/proto"
@@ -45,6 +46,8 @@ func DecodeRequest(req *proto.X509CertificateSigningRequest) (*x509.Certificate,
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:           x509ExtKeyUsage,
 		BasicConstraintsValid: true,
+		// The extensions of the CSR are not signed unless an ExtensionPolicy copies them to ExtraExtensions.
+		Extensions: csr.Extensions,
 	}, nil
 }
 
@@ -82,3 +85,58 @@ func decodeCSR(csr string) (*x509.CertificateRequest, error) {
 	}
 	return req, nil
 }
+
+// DedupSANs removes duplicate subject alternative names from cert and returns the removed duplicates.
+// DNS names are compared case-insensitively and normalized to lower case.
+func DedupSANs(cert *x509.Certificate) []string {
+	var dups []string
+	seen := make(map[string]bool)
+	dnsNames := cert.DNSNames[:0]
+	for _, name := range cert.DNSNames {
+		name = strings.ToLower(name)
+		if seen[name] {
+			dups = append(dups, name)
+			continue
+		}
+		seen[name] = true
+		dnsNames = append(dnsNames, name)
+	}
+	cert.DNSNames = dnsNames
+
+	seen = make(map[string]bool)
+	emails := cert.EmailAddresses[:0]
+	for _, email := range cert.EmailAddresses {
+		if seen[email] {
+			dups = append(dups, email)
+			continue
+		}
+		seen[email] = true
+		emails = append(emails, email)
+	}
+	cert.EmailAddresses = emails
+
+	seen = make(map[string]bool)
+	ips := cert.IPAddresses[:0]
+	for _, ip := range cert.IPAddresses {
+		if seen[ip.String()] {
+			dups = append(dups, ip.String())
+			continue
+		}
+		seen[ip.String()] = true
+		ips = append(ips, ip)
+	}
+	cert.IPAddresses = ips
+
+	seen = make(map[string]bool)
+	uris := cert.URIs[:0]
+	for _, uri := range cert.URIs {
+		if seen[uri.String()] {
+			dups = append(dups, uri.String())
+			continue
+		}
+		seen[uri.String()] = true
+		uris = append(uris, uri)
+	}
+	cert.URIs = uris
+	return dups
+}
@@ -6,6 +6,8 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"io/ioutil"
+	"net"
+	"net/url"
 	"reflect"
 	"testing"
 
@@ -113,6 +115,7 @@ func TestDecodeRequest(t *testing.T) {
 				KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 				ExtKeyUsage:           x509ExtKeyUsages,
 				BasicConstraintsValid: true,
+				Extensions:            csr.Extensions,
 			}
 
 			// cannot validate ValidBefore, ValidAfter and SerialNumber fields because
@@ -134,3 +137,54 @@ func TestDecodeRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestDedupSANs(t *testing.T) {
+	t.Parallel()
+	uri, err := url.Parse("spiffe://foo.bar.com/service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testcases := map[string]struct {
+		cert         *x509.Certificate
+		expectedCert *x509.Certificate
+		expectedDups []string
+	}{
+		"no-duplicates": {
+			cert:         &x509.Certificate{DNSNames: []string{"foo.bar.com", "bar.foo.com"}},
+			expectedCert: &x509.Certificate{DNSNames: []string{"foo.bar.com", "bar.foo.com"}},
+		},
+		"dns-names-differing-in-case": {
+			cert:         &x509.Certificate{DNSNames: []string{"Foo.Bar.com", "foo.bar.com", "bar.foo.com", "FOO.BAR.COM"}},
+			expectedCert: &x509.Certificate{DNSNames: []string{"foo.bar.com", "bar.foo.com"}},
+			expectedDups: []string{"foo.bar.com", "foo.bar.com"},
+		},
+		"all-san-types": {
+			cert: &x509.Certificate{
+				DNSNames:       []string{"foo.bar.com", "foo.bar.com"},
+				EmailAddresses: []string{"foo@bar.com", "foo@bar.com", "Foo@bar.com"},
+				IPAddresses:    []net.IP{net.ParseIP("10.1.2.3"), net.ParseIP("10.1.2.3").To4()},
+				URIs:           []*url.URL{uri, uri},
+			},
+			expectedCert: &x509.Certificate{
+				DNSNames:       []string{"foo.bar.com"},
+				EmailAddresses: []string{"foo@bar.com", "Foo@bar.com"},
+				IPAddresses:    []net.IP{net.ParseIP("10.1.2.3")},
+				URIs:           []*url.URL{uri},
+			},
+			expectedDups: []string{"foo.bar.com", "foo@bar.com", "10.1.2.3", uri.String()},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			dups := DedupSANs(tt.cert)
+			if !reflect.DeepEqual(dups, tt.expectedDups) {
+				t.Errorf("got duplicates %q, want %q", dups, tt.expectedDups)
+			}
+			if !reflect.DeepEqual(tt.cert, tt.expectedCert) {
+				t.Errorf("got cert SANs %+v, want %+v", tt.cert, tt.expectedCert)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"crypto/x509"
+	"net"
+)
+
+// NameConstraints are the names for which an intermediate CA certificate may issue certificates.
+type NameConstraints struct {
+	PermittedDNSDomains []string
+	ExcludedDNSDomains  []string
+	PermittedIPRanges   []*net.IPNet
+	ExcludedIPRanges    []*net.IPNet
+}
+
+// MakeIntermediate turns the (unsigned) certificate into an intermediate CA certificate, which may issue
+// intermediate CA certificates down to maxPathLen levels below it, or only leaf certificates if maxPathLen
+// is zero, within the name constraints if any. The NameConstraints extension is marked critical, as required
+// by RFC 5280.
+func MakeIntermediate(cert *x509.Certificate, constraints *NameConstraints, maxPathLen int) {
+	cert.BasicConstraintsValid = true
+	cert.IsCA = true
+	cert.MaxPathLen = maxPathLen
+	cert.MaxPathLenZero = maxPathLen == 0
+	cert.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	if constraints == nil {
+		return
+	}
+	cert.PermittedDNSDomains = constraints.PermittedDNSDomains
+	cert.ExcludedDNSDomains = constraints.ExcludedDNSDomains
+	cert.PermittedIPRanges = constraints.PermittedIPRanges
+	cert.ExcludedIPRanges = constraints.ExcludedIPRanges
+	cert.PermittedDNSDomainsCritical = len(cert.PermittedDNSDomains)+len(cert.ExcludedDNSDomains)+len(cert.PermittedIPRanges)+len(cert.ExcludedIPRanges) != 0
+}
@@ -0,0 +1,355 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"unicode/utf16"
+)
+
+var (
+	oidData                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Certificate     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidPBEWithSHA13DESCBC  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidJavaTrustedKeyUsage = asn1.ObjectIdentifier{2, 16, 840, 1, 113894, 746875, 1, 1}
+	oidAnyExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37, 0}
+)
+
+// pkcs12Iterations is the iteration count of the key derivations of the PKCS#12 files.
+const pkcs12Iterations = 2048
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  pkcs12MacData
+}
+
+type pkcs12MacData struct {
+	Mac        pkcs12DigestInfo
+	MacSalt    []byte
+	Iterations int
+}
+
+type pkcs12DigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type pkcs12EncryptedData struct {
+	Version              int
+	EncryptedContentInfo pkcs12EncryptedContentInfo
+}
+
+type pkcs12EncryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type pkcs12PBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type pkcs12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue
+	Attributes []attribute `asn1:"set,optional"`
+}
+
+type pkcs12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+// EncodeCertsPKCS12 returns a password protected PKCS#12 file holding the certificates and no private key,
+// as imported into the trust stores of legacy clients. The certificates are encrypted with
+// pbeWithSHAAnd3-KeyTripleDES-CBC and the file is authenticated with an HMAC-SHA1 MAC, which all PKCS#12
+// implementations support. Each certificate is marked as trusted for any purpose so that Java loads it
+// as a trusted certificate entry.
+func EncodeCertsPKCS12(certs []*x509.Certificate, password string) ([]byte, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate to encode")
+	}
+	// The passwords are null terminated BMPStrings.
+	pass := append(bmpString(password), 0, 0)
+	var bags []pkcs12SafeBag
+	for _, cert := range certs {
+		bag, err := asn1.Marshal(pkcs12CertBag{ID: oidX509Certificate, Data: cert.Raw})
+		if err != nil {
+			return nil, err
+		}
+		name, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: bmpString(cert.Subject.String())})
+		if err != nil {
+			return nil, err
+		}
+		trusted, err := asn1.Marshal(oidAnyExtendedKeyUsage)
+		if err != nil {
+			return nil, err
+		}
+		bags = append(bags, pkcs12SafeBag{
+			ID:    oidCertBag,
+			Value: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: bag},
+			Attributes: []attribute{
+				{Type: oidFriendlyName, Values: []asn1.RawValue{{FullBytes: name}}},
+				{Type: oidJavaTrustedKeyUsage, Values: []asn1.RawValue{{FullBytes: trusted}}},
+			},
+		})
+	}
+	safeContents, err := asn1.Marshal(bags)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := pkcs12Encrypt(safeContents, pass)
+	if err != nil {
+		return nil, err
+	}
+	encryptedData, err := asn1.Marshal(*encrypted)
+	if err != nil {
+		return nil, err
+	}
+	// The empty safe contents where the private key would be keeps the two items of the authenticated
+	// safe that some readers expect.
+	emptySafeContents, err := asn1.Marshal([]pkcs12SafeBag{})
+	if err != nil {
+		return nil, err
+	}
+	emptyData, err := asn1.Marshal(emptySafeContents)
+	if err != nil {
+		return nil, err
+	}
+	authSafe, err := asn1.Marshal([]contentInfo{
+		{ContentType: oidEncryptedData, Content: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: encryptedData}},
+		{ContentType: oidData, Content: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: emptyData}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	authSafeData, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	macSalt := make([]byte, 8)
+	if _, err := rand.Read(macSalt); err != nil {
+		return nil, fmt.Errorf("unable to generate MAC salt: %v", err)
+	}
+	mac := hmac.New(sha1.New, pkcs12KDF(macSalt, pass, pkcs12Iterations, 3, sha1.Size))
+	mac.Write(authSafe)
+	return asn1.Marshal(pfxPDU{
+		Version:  3,
+		AuthSafe: contentInfo{ContentType: oidData, Content: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: authSafeData}},
+		MacData: pkcs12MacData{
+			Mac: pkcs12DigestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA1, Parameters: asn1.NullRawValue},
+				Digest:    mac.Sum(nil),
+			},
+			MacSalt:    macSalt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+// pkcs12Encrypt encrypts the data with pbeWithSHAAnd3-KeyTripleDES-CBC and the BMPString encoded password.
+func pkcs12Encrypt(data, password []byte) (*pkcs12EncryptedData, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("unable to generate salt: %v", err)
+	}
+	params, err := asn1.Marshal(pkcs12PBEParams{Salt: salt, Iterations: pkcs12Iterations})
+	if err != nil {
+		return nil, err
+	}
+	block, err := des.NewTripleDESCipher(pkcs12KDF(salt, password, pkcs12Iterations, 1, 24))
+	if err != nil {
+		return nil, err
+	}
+	padding := block.BlockSize() - len(data)%block.BlockSize()
+	encrypted := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, pkcs12KDF(salt, password, pkcs12Iterations, 2, block.BlockSize())).CryptBlocks(encrypted, encrypted)
+	return &pkcs12EncryptedData{
+		EncryptedContentInfo: pkcs12EncryptedContentInfo{
+			ContentType:                oidData,
+			ContentEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidPBEWithSHA13DESCBC, Parameters: asn1.RawValue{FullBytes: params}},
+			EncryptedContent:           encrypted,
+		},
+	}, nil
+}
+
+// bmpString returns the BMPString encoding of s, i.e. UTF-16 big endian.
+func bmpString(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(c>>8), byte(c))
+	}
+	return b
+}
+
+// pkcs12KDF derives size bytes of key material of the purpose id (1 for keys, 2 for IVs and 3 for MAC keys)
+// from the salt and the BMPString encoded password, with SHA1 as per RFC 7292 appendix B.2.
+func pkcs12KDF(salt, password []byte, iterations int, id byte, size int) []byte {
+	const u, v = sha1.Size, 64
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+	d := bytes.Repeat([]byte{id}, v)
+	i := append(fill(salt), fill(password)...)
+	one := big.NewInt(1)
+	var out []byte
+	for len(out) < size {
+		a := sha1.Sum(append(append([]byte{}, d...), i...))
+		for n := 1; n < iterations; n++ {
+			a = sha1.Sum(a[:])
+		}
+		out = append(out, a[:]...)
+		// Ij = (Ij + B + 1) mod 2^(8v) for each v-byte block Ij of I, where B repeats A to v bytes.
+		b := new(big.Int).SetBytes(fill(a[:u])[:v])
+		b.Add(b, one)
+		for j := 0; j < len(i); j += v {
+			ij := new(big.Int).SetBytes(i[j : j+v])
+			ij.Add(ij, b)
+			sum := ij.Bytes()
+			if len(sum) > v {
+				sum = sum[len(sum)-v:]
+			}
+			block := i[j : j+v]
+			for k := range block {
+				block[k] = 0
+			}
+			copy(block[v-len(sum):], sum)
+		}
+	}
+	return out[:size]
+}
+
+// DecodeCertsPKCS12 returns the certificates of a PKCS#12 file encoded by EncodeCertsPKCS12, after verifying
+// its MAC with the password. It returns an error if the file holds anything but certificates.
+func DecodeCertsPKCS12(der []byte, password string) ([]*x509.Certificate, error) {
+	bags, err := decodePKCS12Bags(der, append(bmpString(password), 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for _, bag := range bags {
+		if !bag.ID.Equal(oidCertBag) {
+			return nil, fmt.Errorf("unexpected safe bag type %v", bag.ID)
+		}
+		var certBag pkcs12CertBag
+		if _, err := asn1.Unmarshal(bag.Value.Bytes, &certBag); err != nil {
+			return nil, fmt.Errorf("unable to parse certificate bag: %v", err)
+		}
+		if !certBag.ID.Equal(oidX509Certificate) {
+			return nil, fmt.Errorf("unexpected certificate type %v", certBag.ID)
+		}
+		cert, err := x509.ParseCertificate(certBag.Data)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// decodePKCS12Bags verifies the MAC of the PKCS#12 file with the BMPString encoded password and returns
+// the safe bags of the file, decrypting the ones encrypted with pbeWithSHAAnd3-KeyTripleDES-CBC.
+func decodePKCS12Bags(der, password []byte) ([]pkcs12SafeBag, error) {
+	var pfx pfxPDU
+	if rest, err := asn1.Unmarshal(der, &pfx); err != nil || len(rest) != 0 {
+		return nil, errors.New("unable to parse PKCS#12 file")
+	}
+	if pfx.Version != 3 || !pfx.AuthSafe.ContentType.Equal(oidData) {
+		return nil, fmt.Errorf("unsupported PKCS#12 version %d or content type %v", pfx.Version, pfx.AuthSafe.ContentType)
+	}
+	var authSafe []byte
+	if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, fmt.Errorf("unable to parse authenticated safe: %v", err)
+	}
+	if !pfx.MacData.Mac.Algorithm.Algorithm.Equal(oidSHA1) {
+		return nil, fmt.Errorf("unsupported MAC algorithm %v", pfx.MacData.Mac.Algorithm.Algorithm)
+	}
+	mac := hmac.New(sha1.New, pkcs12KDF(pfx.MacData.MacSalt, password, pfx.MacData.Iterations, 3, sha1.Size))
+	mac.Write(authSafe)
+	if !hmac.Equal(mac.Sum(nil), pfx.MacData.Mac.Digest) {
+		return nil, errors.New("MAC verification failed: incorrect password")
+	}
+
+	var contents []contentInfo
+	if _, err := asn1.Unmarshal(authSafe, &contents); err != nil {
+		return nil, fmt.Errorf("unable to parse content infos: %v", err)
+	}
+	var bags []pkcs12SafeBag
+	for _, ci := range contents {
+		var data []byte
+		switch {
+		case ci.ContentType.Equal(oidData):
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &data); err != nil {
+				return nil, fmt.Errorf("unable to parse data: %v", err)
+			}
+		case ci.ContentType.Equal(oidEncryptedData):
+			var ed pkcs12EncryptedData
+			if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+				return nil, fmt.Errorf("unable to parse encrypted data: %v", err)
+			}
+			var err error
+			if data, err = pkcs12Decrypt(&ed, password); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported content type %v", ci.ContentType)
+		}
+		var safeBags []pkcs12SafeBag
+		if _, err := asn1.Unmarshal(data, &safeBags); err != nil {
+			return nil, fmt.Errorf("unable to parse safe contents: %v", err)
+		}
+		bags = append(bags, safeBags...)
+	}
+	return bags, nil
+}
+
+// pkcs12Decrypt decrypts the data encrypted by pkcs12Encrypt.
+func pkcs12Decrypt(ed *pkcs12EncryptedData, password []byte) ([]byte, error) {
+	alg := ed.EncryptedContentInfo.ContentEncryptionAlgorithm
+	if !alg.Algorithm.Equal(oidPBEWithSHA13DESCBC) {
+		return nil, fmt.Errorf("unsupported encryption algorithm %v", alg.Algorithm)
+	}
+	var params pkcs12PBEParams
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("unable to parse PBE parameters: %v", err)
+	}
+	block, err := des.NewTripleDESCipher(pkcs12KDF(params.Salt, password, params.Iterations, 1, 24))
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte{}, ed.EncryptedContentInfo.EncryptedContent...)
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New("bad encrypted content length")
+	}
+	cipher.NewCBCDecrypter(block, pkcs12KDF(params.Salt, password, params.Iterations, 2, block.BlockSize())).CryptBlocks(data, data)
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > block.BlockSize() || !bytes.Equal(data[len(data)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("bad padding of encrypted content")
+	}
+	return data[:len(data)-padding], nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestCACert returns a self-signed CA certificate of a new key.
+func newTestCACert(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestEncodeCertsPKCS12(t *testing.T) {
+	t.Parallel()
+	certs := []*x509.Certificate{newTestCACert(t, "intermediate CA"), newTestCACert(t, "root CA")}
+	der, err := EncodeCertsPKCS12(certs, "changeit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := DecodeCertsPKCS12(der, "changeit")
+	if err != nil {
+		t.Fatalf("unable to decode PKCS#12 file: %v", err)
+	}
+	if len(got) != len(certs) {
+		t.Fatalf("got %d certificates, want %d", len(got), len(certs))
+	}
+	for i := range certs {
+		if !got[i].Equal(certs[i]) {
+			t.Errorf("certificate %d mismatch", i)
+		}
+	}
+	// Java only loads the certificates marked as trusted.
+	bags, err := decodePKCS12Bags(der, append(bmpString("changeit"), 0, 0))
+	if err != nil {
+		t.Fatalf("unable to decode safe bags: %v", err)
+	}
+	for i, bag := range bags {
+		trusted := false
+		for _, attr := range bag.Attributes {
+			var usage asn1.ObjectIdentifier
+			if attr.Type.Equal(oidJavaTrustedKeyUsage) && len(attr.Values) == 1 {
+				if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &usage); err == nil && usage.Equal(oidAnyExtendedKeyUsage) {
+					trusted = true
+				}
+			}
+		}
+		if !trusted {
+			t.Errorf("bag %d: certificate not marked as trusted", i)
+		}
+	}
+
+	// Each file is encrypted with its own salts.
+	other, err := EncodeCertsPKCS12(certs, "changeit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bytes.Equal(der, other) {
+		t.Error("got identical files for two encodings")
+	}
+	if _, err := EncodeCertsPKCS12(nil, "changeit"); err == nil {
+		t.Error("expected error for no certificate")
+	}
+}
+
+func TestDecodeCertsPKCS12(t *testing.T) {
+	t.Parallel()
+	der, err := EncodeCertsPKCS12([]*x509.Certificate{newTestCACert(t, "root CA")}, "changeit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testcases := map[string]struct {
+		der      []byte
+		password string
+	}{
+		"wrong-password": {der: der, password: "wrong"},
+		"truncated":      {der: der[:len(der)-1], password: "changeit"},
+		"garbage":        {der: []byte("not a pkcs12 file"), password: "changeit"},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			if _, err := DecodeCertsPKCS12(tt.der, tt.password); err == nil {
+				t.Errorf("in test %v: expected error", label)
+			}
+		})
+	}
+}
+
+func TestPKCS12KDF(t *testing.T) {
+	t.Parallel()
+	testcases := map[string]struct {
+		salt     []byte
+		password []byte
+		expected []byte
+	}{
+		"3des-key": {
+			salt:     []byte("\xff\xff\xff\xff\xff\xff\xff\xff"),
+			password: append(bmpString("sesame"), 0, 0),
+			expected: []byte("\x7c\xd9\xfd\x3e\x2b\x3b\xe7\x69\x1a\x44\xe3\xbe\xf0\xf9\xea\x0f\xb9\xb8\x97\xd4\xe3\x25\xd9\xd1"),
+		},
+		"leading-zeros": {
+			salt:     []byte("\xf3\x7e\x05\xb5\x18\x32\x4b\x4b"),
+			password: []byte("\x00\x00"),
+			expected: []byte("\x00\xf7\x59\xff\x47\xd1\x4d\xd0\x36\x65\xd5\x94\x3c\xb3\xc4\xa3\x9a\x25\x55\xc0\x2a\xed\x66\xe1"),
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			if got := pkcs12KDF(tt.salt, tt.password, 2048, 1, 24); !bytes.Equal(got, tt.expected) {
+				t.Errorf("in test %v: got key %x, want %x", label, got, tt.expected)
+			}
+		})
+	}
+}
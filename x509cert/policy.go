@@ -0,0 +1,179 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/yahoo/crypki"
+)
+
+const (
+	defaultMinRSAKeySize     = 2048
+	defaultMinECDSAKeySize   = 256
+	defaultMaxExtensionSize  = 1024
+	defaultMaxExtensionsSize = 4096
+)
+
+// SubjectKeyPolicy restricts the subject public keys of the x509 certificates to be signed.
+type SubjectKeyPolicy struct {
+	// KeyTypes is the list of allowed public key algorithms. If empty, RSA and ECDSA keys are allowed.
+	KeyTypes []crypki.PublicKeyAlgorithm
+	// MinRSAKeySize is the minimum size in bits of an RSA key. If zero, defaults to 2048.
+	MinRSAKeySize int
+	// MinECDSAKeySize is the minimum curve size in bits of an ECDSA key. If zero, defaults to 256.
+	MinECDSAKeySize int
+}
+
+// Check returns an error if the public key is not allowed by the policy.
+func (p *SubjectKeyPolicy) Check(pub crypto.PublicKey) error {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if !p.allows(crypki.RSA) {
+			return errors.New("RSA public key is not allowed")
+		}
+		minSize := p.MinRSAKeySize
+		if minSize == 0 {
+			minSize = defaultMinRSAKeySize
+		}
+		if size := key.N.BitLen(); size < minSize {
+			return fmt.Errorf("RSA public key size %d is less than the minimum allowed size %d", size, minSize)
+		}
+	case *ecdsa.PublicKey:
+		if !p.allows(crypki.ECDSA) {
+			return errors.New("ECDSA public key is not allowed")
+		}
+		minSize := p.MinECDSAKeySize
+		if minSize == 0 {
+			minSize = defaultMinECDSAKeySize
+		}
+		if size := key.Curve.Params().BitSize; size < minSize {
+			return fmt.Errorf("ECDSA public key size %d is less than the minimum allowed size %d", size, minSize)
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return nil
+}
+
+// allows returns true if the public key algorithm is allowed by the policy.
+func (p *SubjectKeyPolicy) allows(pka crypki.PublicKeyAlgorithm) bool {
+	if len(p.KeyTypes) == 0 {
+		return pka == crypki.RSA || pka == crypki.ECDSA
+	}
+	for _, t := range p.KeyTypes {
+		if t == pka {
+			return true
+		}
+	}
+	return false
+}
+
+// handledExtensions are the OIDs of the extensions of the CSRs that are either decoded into the
+// certificates, i.e. the subject alternative names, or replaced by the ones set by crypki.
+var handledExtensions = []asn1.ObjectIdentifier{
+	{2, 5, 29, 14}, // subject key identifier
+	{2, 5, 29, 15}, // key usage
+	{2, 5, 29, 17}, // subject alternative name
+	{2, 5, 29, 19}, // basic constraints
+	{2, 5, 29, 37}, // extended key usage
+}
+
+// ExtensionPolicy restricts the custom extensions of the CSRs copied to the x509 certificates to be signed.
+type ExtensionPolicy struct {
+	// AllowedOIDs is the list of OIDs of the custom extensions copied to the certificates.
+	AllowedOIDs []asn1.ObjectIdentifier
+	// MaxSize is the maximum size in bytes of the value of an extension. If zero, defaults to 1024.
+	MaxSize int
+	// MaxTotalSize is the maximum total size in bytes of the values of the extensions. If zero, defaults to 4096.
+	MaxTotalSize int
+}
+
+// Apply copies the allowed extensions of the CSR of the certificate template, as set by DecodeRequest,
+// to its ExtraExtensions. It returns an error if the CSR has an extension neither handled by crypki
+// nor allowed, or if the extensions exceed the size limits.
+func (p *ExtensionPolicy) Apply(cert *x509.Certificate) error {
+	maxSize, maxTotalSize := p.MaxSize, p.MaxTotalSize
+	if maxSize == 0 {
+		maxSize = defaultMaxExtensionSize
+	}
+	if maxTotalSize == 0 {
+		maxTotalSize = defaultMaxExtensionsSize
+	}
+	var extensions []pkix.Extension
+	total := 0
+	for _, ext := range cert.Extensions {
+		if containsOID(handledExtensions, ext.Id) {
+			continue
+		}
+		if !containsOID(p.AllowedOIDs, ext.Id) {
+			return fmt.Errorf("extension %v is not allowed", ext.Id)
+		}
+		for _, e := range extensions {
+			if e.Id.Equal(ext.Id) {
+				return fmt.Errorf("duplicate extension %v", ext.Id)
+			}
+		}
+		if len(ext.Value) > maxSize {
+			return fmt.Errorf("extension %v of %d bytes exceeds the maximum size %d", ext.Id, len(ext.Value), maxSize)
+		}
+		if total += len(ext.Value); total > maxTotalSize {
+			return fmt.Errorf("extensions exceed the maximum total size %d", maxTotalSize)
+		}
+		extensions = append(extensions, ext)
+	}
+	cert.ExtraExtensions = append(cert.ExtraExtensions, extensions...)
+	return nil
+}
+
+// containsOID returns true if the OID is in the list.
+func containsOID(oids []asn1.ObjectIdentifier, oid asn1.ObjectIdentifier) bool {
+	for _, o := range oids {
+		if o.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtKeyUsagePolicy restricts the extended key usages of the x509 certificates to be signed.
+type ExtKeyUsagePolicy struct {
+	// Required is the list of extended key usages added to the certificates that do not have them.
+	Required []x509.ExtKeyUsage
+	// Forbidden is the list of extended key usages of the rejected certificates.
+	Forbidden []x509.ExtKeyUsage
+}
+
+// Apply returns an error if the certificate template has a forbidden extended key usage, and adds
+// the required ones it does not have.
+func (p *ExtKeyUsagePolicy) Apply(cert *x509.Certificate) error {
+	for _, eku := range cert.ExtKeyUsage {
+		if containsExtKeyUsage(p.Forbidden, eku) {
+			return fmt.Errorf("extended key usage %d is not allowed", eku)
+		}
+	}
+	for _, eku := range p.Required {
+		if !containsExtKeyUsage(cert.ExtKeyUsage, eku) {
+			cert.ExtKeyUsage = append(cert.ExtKeyUsage, eku)
+		}
+	}
+	return nil
+}
+
+// containsExtKeyUsage returns true if the extended key usage is in the list.
+func containsExtKeyUsage(ekus []x509.ExtKeyUsage, eku x509.ExtKeyUsage) bool {
+	for _, e := range ekus {
+		if e == eku {
+			return true
+		}
+	}
+	return false
+}
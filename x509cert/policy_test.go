@@ -0,0 +1,139 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"reflect"
+	"testing"
+
+	"github.com/yahoo/crypki"
+)
+
+func TestSubjectKeyPolicyCheck(t *testing.T) {
+	t.Parallel()
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	rsa2048, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %v", err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	testcases := map[string]struct {
+		policy      *SubjectKeyPolicy
+		pub         crypto.PublicKey
+		expectError bool
+	}{
+		"default-rsa-1024":     {&SubjectKeyPolicy{}, &rsa1024.PublicKey, true},
+		"default-rsa-2048":     {&SubjectKeyPolicy{}, &rsa2048.PublicKey, false},
+		"default-p224":         {&SubjectKeyPolicy{}, &p224.PublicKey, true},
+		"default-p256":         {&SubjectKeyPolicy{}, &p256.PublicKey, false},
+		"min-rsa-1024":         {&SubjectKeyPolicy{MinRSAKeySize: 1024}, &rsa1024.PublicKey, false},
+		"min-rsa-3072":         {&SubjectKeyPolicy{MinRSAKeySize: 3072}, &rsa2048.PublicKey, true},
+		"min-ecdsa-384":        {&SubjectKeyPolicy{MinECDSAKeySize: 384}, &p256.PublicKey, true},
+		"ecdsa-only-reject":    {&SubjectKeyPolicy{KeyTypes: []crypki.PublicKeyAlgorithm{crypki.ECDSA}}, &rsa2048.PublicKey, true},
+		"ecdsa-only-accept":    {&SubjectKeyPolicy{KeyTypes: []crypki.PublicKeyAlgorithm{crypki.ECDSA}}, &p256.PublicKey, false},
+		"rsa-only-reject":      {&SubjectKeyPolicy{KeyTypes: []crypki.PublicKeyAlgorithm{crypki.RSA}}, &p256.PublicKey, true},
+		"unsupported-key-type": {&SubjectKeyPolicy{}, []byte("bad key"), true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			err := tt.policy.Check(tt.pub)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestExtensionPolicyApply(t *testing.T) {
+	t.Parallel()
+	custom := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}
+	other := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 2}
+	san := pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: []byte{0x30, 0}}
+	small := pkix.Extension{Id: custom, Value: []byte{0x04, 0x01, 0x01}}
+	large := pkix.Extension{Id: custom, Value: make([]byte, 2000)}
+	testcases := map[string]struct {
+		policy       *ExtensionPolicy
+		extensions   []pkix.Extension
+		expectError  bool
+		expectCopied int
+	}{
+		"no-extensions":     {&ExtensionPolicy{}, nil, false, 0},
+		"handled-extension": {&ExtensionPolicy{}, []pkix.Extension{san}, false, 0},
+		"allowed":           {&ExtensionPolicy{AllowedOIDs: []asn1.ObjectIdentifier{custom}}, []pkix.Extension{san, small}, false, 1},
+		"not-allowed":       {&ExtensionPolicy{AllowedOIDs: []asn1.ObjectIdentifier{custom}}, []pkix.Extension{{Id: other, Value: small.Value}}, true, 0},
+		"duplicate":         {&ExtensionPolicy{AllowedOIDs: []asn1.ObjectIdentifier{custom}}, []pkix.Extension{small, small}, true, 0},
+		"default-max-size":  {&ExtensionPolicy{AllowedOIDs: []asn1.ObjectIdentifier{custom}}, []pkix.Extension{large}, true, 0},
+		"max-size":          {&ExtensionPolicy{AllowedOIDs: []asn1.ObjectIdentifier{custom}, MaxSize: 4096}, []pkix.Extension{large}, false, 1},
+		"max-total-size": {
+			&ExtensionPolicy{AllowedOIDs: []asn1.ObjectIdentifier{custom, other}, MaxSize: 2000, MaxTotalSize: 3000},
+			[]pkix.Extension{large, {Id: other, Value: large.Value}},
+			true, 0,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			cert := &x509.Certificate{Extensions: tt.extensions}
+			err := tt.policy.Apply(cert)
+			if err != nil != tt.expectError {
+				t.Fatalf("in test %v: got err: %v, expect err: %v", label, err, tt.expectError)
+			}
+			if err == nil && len(cert.ExtraExtensions) != tt.expectCopied {
+				t.Errorf("in test %v: got %d extensions copied, want %d", label, len(cert.ExtraExtensions), tt.expectCopied)
+			}
+		})
+	}
+}
+
+func TestExtKeyUsagePolicyApply(t *testing.T) {
+	t.Parallel()
+	codeSigning := &ExtKeyUsagePolicy{Required: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}, Forbidden: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}
+	testcases := map[string]struct {
+		policy       *ExtKeyUsagePolicy
+		ekus         []x509.ExtKeyUsage
+		expectError  bool
+		expectedEKUs []x509.ExtKeyUsage
+	}{
+		"no-policy":        {&ExtKeyUsagePolicy{}, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, false, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}},
+		"required-added":   {codeSigning, []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping}, false, []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping, x509.ExtKeyUsageCodeSigning}},
+		"required-present": {codeSigning, []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}, false, []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}},
+		"forbidden":        {codeSigning, []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning, x509.ExtKeyUsageServerAuth}, true, nil},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			cert := &x509.Certificate{ExtKeyUsage: tt.ekus}
+			err := tt.policy.Apply(cert)
+			if err != nil != tt.expectError {
+				t.Fatalf("in test %v: got err: %v, expect err: %v", label, err, tt.expectError)
+			}
+			if err == nil && !reflect.DeepEqual(cert.ExtKeyUsage, tt.expectedEKUs) {
+				t.Errorf("in test %v: got extended key usages %v, want %v", label, cert.ExtKeyUsage, tt.expectedEKUs)
+			}
+		})
+	}
+}
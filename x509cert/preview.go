@@ -0,0 +1,56 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+
+	"github.com/yahoo/crypki/proto"
+)
+
+// keyUsageNames lists the names of the x509.KeyUsage bits in bit order.
+var keyUsageNames = []string{
+	"DigitalSignature",
+	"ContentCommitment",
+	"KeyEncipherment",
+	"DataEncipherment",
+	"KeyAgreement",
+	"CertSign",
+	"CRLSign",
+	"EncipherOnly",
+	"DecipherOnly",
+}
+
+// Preview returns the description of the certificate that the (unsigned) x509 certificate
+// becomes once signed by the issuer.
+func Preview(cert *x509.Certificate, issuer pkix.Name) *proto.X509CertificatePreview {
+	p := &proto.X509CertificatePreview{
+		Subject:                cert.Subject.String(),
+		Issuer:                 issuer.String(),
+		NotBefore:              cert.NotBefore.Unix(),
+		NotAfter:               cert.NotAfter.Unix(),
+		PublicKeyAlgorithm:     cert.PublicKeyAlgorithm.String(),
+		DnsNames:               cert.DNSNames,
+		EmailAddresses:         cert.EmailAddresses,
+		CrlDistributionPoints:  cert.CRLDistributionPoints,
+		OcspServers:            cert.OCSPServer,
+		IssuingCertificateUrls: cert.IssuingCertificateURL,
+	}
+	for _, ip := range cert.IPAddresses {
+		p.IpAddresses = append(p.IpAddresses, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		p.Uris = append(p.Uris, uri.String())
+	}
+	for i, name := range keyUsageNames {
+		if cert.KeyUsage&(1<<uint(i)) != 0 {
+			p.KeyUsage = append(p.KeyUsage, name)
+		}
+	}
+	for _, eku := range cert.ExtKeyUsage {
+		p.ExtKeyUsage = append(p.ExtKeyUsage, int32(eku))
+	}
+	return p
+}
@@ -0,0 +1,192 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yahoo/crypki/config"
+)
+
+const (
+	maxDNSNameLength  = 253
+	maxDNSLabelLength = 63
+)
+
+// Profile restricts the subject and subject alternative names of the x509 certificates to be signed.
+type Profile struct {
+	// SANTypes is the list of allowed SAN types, such as config.X509SANTypeDNS. If empty, all types are allowed.
+	SANTypes []string
+	// DNSSuffixes is the list of allowed suffixes of the DNS names and the subject common name,
+	// matched on label boundaries. If empty, all names are allowed.
+	DNSSuffixes []string
+	// ValidateDNSNames specifies whether the DNS names must be fully qualified domain names
+	// as per RFC 1035 and RFC 5280, without a trailing dot.
+	ValidateDNSNames bool
+	// AllowWildcards specifies whether a validated DNS name may have a wildcard as its leftmost label.
+	AllowWildcards bool
+	// AllowUnderscores specifies whether the labels of a validated DNS name may contain underscores.
+	AllowUnderscores bool
+	// RequireCommonNameSAN specifies whether the subject common name must also be a DNS SAN,
+	// as required by the CA/Browser Forum Baseline Requirements.
+	RequireCommonNameSAN bool
+	// AddCommonNameSAN specifies whether the subject common name is added to the DNS SANs if it is missing.
+	// It takes precedence over RequireCommonNameSAN.
+	AddCommonNameSAN bool
+}
+
+// ApplyCommonNameSAN adds the subject common name of the certificate to its DNS SANs if the profile adds it,
+// or returns an error if the profile requires it and it is missing. It must be called before Check,
+// so that the added name is checked.
+func (p *Profile) ApplyCommonNameSAN(cert *x509.Certificate) error {
+	cn := cert.Subject.CommonName
+	if cn == "" || (!p.RequireCommonNameSAN && !p.AddCommonNameSAN) {
+		return nil
+	}
+	for _, name := range cert.DNSNames {
+		if strings.EqualFold(name, cn) {
+			return nil
+		}
+	}
+	if !p.AddCommonNameSAN {
+		return fmt.Errorf("subject common name %q is not a DNS SAN", cn)
+	}
+	cert.DNSNames = append(cert.DNSNames, cn)
+	return nil
+}
+
+// Check returns an error if the subject or a SAN of the certificate is not allowed by the profile.
+func (p *Profile) Check(cert *x509.Certificate) error {
+	if len(cert.DNSNames) != 0 && !p.allowsSANType(config.X509SANTypeDNS) {
+		return fmt.Errorf("%s SANs are not allowed", config.X509SANTypeDNS)
+	}
+	if len(cert.IPAddresses) != 0 && !p.allowsSANType(config.X509SANTypeIP) {
+		return fmt.Errorf("%s SANs are not allowed", config.X509SANTypeIP)
+	}
+	if len(cert.EmailAddresses) != 0 && !p.allowsSANType(config.X509SANTypeEmail) {
+		return fmt.Errorf("%s SANs are not allowed", config.X509SANTypeEmail)
+	}
+	if len(cert.URIs) != 0 && !p.allowsSANType(config.X509SANTypeURI) {
+		return fmt.Errorf("%s SANs are not allowed", config.X509SANTypeURI)
+	}
+	for _, name := range cert.DNSNames {
+		if p.ValidateDNSNames {
+			if err := p.checkDNSName(name); err != nil {
+				return fmt.Errorf("invalid DNS name %q: %v", name, err)
+			}
+		}
+		if !p.allowsName(name) {
+			return fmt.Errorf("DNS name %q is not allowed", name)
+		}
+	}
+	if cn := cert.Subject.CommonName; cn != "" && !p.allowsName(cn) {
+		return fmt.Errorf("subject common name %q is not allowed", cn)
+	}
+	return nil
+}
+
+// allowsSANType returns true if the SAN type is allowed by the profile.
+func (p *Profile) allowsSANType(sanType string) bool {
+	if len(p.SANTypes) == 0 {
+		return true
+	}
+	for _, t := range p.SANTypes {
+		if t == sanType {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsName returns true if the name is one of the allowed DNS suffixes or a subdomain of one.
+// A suffix matches whole labels only, with or without its leading dot, so that neither
+// ".example.com" nor "example.com" allows "fooexample.com".
+func (p *Profile) allowsName(name string) bool {
+	if len(p.DNSSuffixes) == 0 {
+		return true
+	}
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	for _, suffix := range p.DNSSuffixes {
+		suffix = strings.Trim(strings.ToLower(suffix), ".")
+		if name == suffix || strings.HasSuffix(name, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDNSName returns an error if name is not a fully qualified domain name allowed by the profile.
+func (p *Profile) checkDNSName(name string) error {
+	if len(name) > maxDNSNameLength {
+		return fmt.Errorf("longer than %d characters", maxDNSNameLength)
+	}
+	if strings.HasSuffix(name, ".") {
+		return errors.New("trailing dot")
+	}
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return errors.New("not fully qualified")
+	}
+	for i, label := range labels {
+		if label == "*" {
+			if i != 0 {
+				return errors.New("wildcard not in the leftmost label")
+			}
+			if !p.AllowWildcards {
+				return errors.New("wildcards are not allowed")
+			}
+			if len(labels) < 3 {
+				return errors.New("wildcard of a top-level domain")
+			}
+			continue
+		}
+		if err := p.checkLabel(label); err != nil {
+			return err
+		}
+	}
+	if isNumeric(labels[len(labels)-1]) {
+		return errors.New("numeric top-level domain")
+	}
+	return nil
+}
+
+// checkLabel returns an error if label is not a valid DNS label.
+func (p *Profile) checkLabel(label string) error {
+	if len(label) == 0 {
+		return errors.New("empty label")
+	}
+	if len(label) > maxDNSLabelLength {
+		return fmt.Errorf("label %q longer than %d characters", label, maxDNSLabelLength)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	for _, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+		case c == '_':
+			if !p.AllowUnderscores {
+				return fmt.Errorf("label %q contains an underscore", label)
+			}
+		case c == '*':
+			return fmt.Errorf("label %q contains a partial wildcard", label)
+		default:
+			return fmt.Errorf("label %q contains invalid character %q", label, c)
+		}
+	}
+	return nil
+}
+
+// isNumeric returns true if s consists of digits only.
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
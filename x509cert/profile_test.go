@@ -0,0 +1,200 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/yahoo/crypki/config"
+)
+
+func TestProfileCheck(t *testing.T) {
+	t.Parallel()
+	dnsOnly := &Profile{SANTypes: []string{config.X509SANTypeDNS}, DNSSuffixes: []string{".example.com"}}
+	testcases := map[string]struct {
+		profile     *Profile
+		cert        *x509.Certificate
+		expectError bool
+	}{
+		"empty-profile": {
+			profile: &Profile{},
+			cert: &x509.Certificate{
+				Subject:        pkix.Name{CommonName: "foo.bar.com"},
+				DNSNames:       []string{"foo.bar.com"},
+				IPAddresses:    []net.IP{net.ParseIP("10.1.2.3")},
+				EmailAddresses: []string{"foo@bar.com"},
+			},
+			expectError: false,
+		},
+		"allowed-names": {
+			profile: dnsOnly,
+			cert: &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "Foo.Example.com"},
+				DNSNames: []string{"foo.example.com", "bar.example.com"},
+			},
+			expectError: false,
+		},
+		"no-common-name": {
+			profile:     dnsOnly,
+			cert:        &x509.Certificate{DNSNames: []string{"foo.example.com"}},
+			expectError: false,
+		},
+		"forbidden-san-type": {
+			profile: dnsOnly,
+			cert: &x509.Certificate{
+				DNSNames:    []string{"foo.example.com"},
+				IPAddresses: []net.IP{net.ParseIP("10.1.2.3")},
+			},
+			expectError: true,
+		},
+		"forbidden-dns-name": {
+			profile:     dnsOnly,
+			cert:        &x509.Certificate{DNSNames: []string{"foo.example.com", "foo.bar.com"}},
+			expectError: true,
+		},
+		"suffix-without-label-boundary": {
+			profile:     dnsOnly,
+			cert:        &x509.Certificate{DNSNames: []string{"fooexample.com"}},
+			expectError: true,
+		},
+		"suffix-without-leading-dot": {
+			profile:     &Profile{DNSSuffixes: []string{"example.com"}},
+			cert:        &x509.Certificate{DNSNames: []string{"foo.example.com", "example.com"}},
+			expectError: false,
+		},
+		"suffix-without-leading-dot-or-label-boundary": {
+			profile:     &Profile{DNSSuffixes: []string{"example.com"}},
+			cert:        &x509.Certificate{DNSNames: []string{"badexample.com"}},
+			expectError: true,
+		},
+		"forbidden-common-name": {
+			profile: dnsOnly,
+			cert: &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "foo.bar.com"},
+				DNSNames: []string{"foo.example.com"},
+			},
+			expectError: true,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			err := tt.profile.Check(tt.cert)
+			if err != nil != tt.expectError {
+				t.Errorf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestProfileApplyCommonNameSAN(t *testing.T) {
+	t.Parallel()
+	require := &Profile{RequireCommonNameSAN: true}
+	add := &Profile{AddCommonNameSAN: true}
+	testcases := map[string]struct {
+		profile          *Profile
+		cert             *x509.Certificate
+		expectError      bool
+		expectedDNSNames []string
+	}{
+		"disabled": {
+			profile:          &Profile{},
+			cert:             &x509.Certificate{Subject: pkix.Name{CommonName: "foo.example.com"}, DNSNames: []string{"bar.example.com"}},
+			expectedDNSNames: []string{"bar.example.com"},
+		},
+		"require-present": {
+			profile:          require,
+			cert:             &x509.Certificate{Subject: pkix.Name{CommonName: "Foo.Example.com"}, DNSNames: []string{"bar.example.com", "foo.example.com"}},
+			expectedDNSNames: []string{"bar.example.com", "foo.example.com"},
+		},
+		"require-missing": {
+			profile:     require,
+			cert:        &x509.Certificate{Subject: pkix.Name{CommonName: "foo.example.com"}, DNSNames: []string{"bar.example.com"}},
+			expectError: true,
+		},
+		"require-no-common-name": {
+			profile:          require,
+			cert:             &x509.Certificate{DNSNames: []string{"bar.example.com"}},
+			expectedDNSNames: []string{"bar.example.com"},
+		},
+		"add-missing": {
+			profile:          add,
+			cert:             &x509.Certificate{Subject: pkix.Name{CommonName: "foo.example.com"}, DNSNames: []string{"bar.example.com"}},
+			expectedDNSNames: []string{"bar.example.com", "foo.example.com"},
+		},
+		"add-present": {
+			profile:          add,
+			cert:             &x509.Certificate{Subject: pkix.Name{CommonName: "foo.example.com"}, DNSNames: []string{"foo.example.com"}},
+			expectedDNSNames: []string{"foo.example.com"},
+		},
+		"add-over-require": {
+			profile:          &Profile{RequireCommonNameSAN: true, AddCommonNameSAN: true},
+			cert:             &x509.Certificate{Subject: pkix.Name{CommonName: "foo.example.com"}},
+			expectedDNSNames: []string{"foo.example.com"},
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			err := tt.profile.ApplyCommonNameSAN(tt.cert)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if err == nil && strings.Join(tt.cert.DNSNames, ",") != strings.Join(tt.expectedDNSNames, ",") {
+				t.Errorf("got DNS names %q, want %q", tt.cert.DNSNames, tt.expectedDNSNames)
+			}
+		})
+	}
+}
+
+func TestProfileCheckDNSNames(t *testing.T) {
+	t.Parallel()
+	strict := &Profile{ValidateDNSNames: true}
+	lenient := &Profile{ValidateDNSNames: true, AllowWildcards: true, AllowUnderscores: true}
+	testcases := map[string]struct {
+		name          string
+		expectStrict  bool
+		expectLenient bool
+	}{
+		"valid":                 {"foo-1.bar.example.com", true, true},
+		"valid-uppercase":       {"Foo.Example.COM", true, true},
+		"wildcard":              {"*.example.com", false, true},
+		"wildcard-not-leftmost": {"foo.*.example.com", false, false},
+		"partial-wildcard":      {"f*o.example.com", false, false},
+		"wildcard-tld":          {"*.com", false, false},
+		"underscore":            {"_srv.example.com", false, true},
+		"trailing-dot":          {"foo.example.com.", false, false},
+		"not-fully-qualified":   {"localhost", false, false},
+		"empty-label":           {"foo..example.com", false, false},
+		"leading-hyphen":        {"-foo.example.com", false, false},
+		"trailing-hyphen":       {"foo-.example.com", false, false},
+		"invalid-character":     {"foo bar.example.com", false, false},
+		"numeric-tld":           {"10.1.2.3", false, false},
+		"long-label":            {strings.Repeat("a", 64) + ".example.com", false, false},
+		"long-name":             {strings.Repeat("a.", 127) + "com", false, false},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			cert := &x509.Certificate{DNSNames: []string{tt.name}}
+			if err := strict.Check(cert); (err == nil) != tt.expectStrict {
+				t.Errorf("strict profile: got err: %v, expect valid: %v", err, tt.expectStrict)
+			} else if err != nil && !strings.Contains(err.Error(), tt.name) {
+				t.Errorf("error %q does not name the DNS name %q", err, tt.name)
+			}
+			if err := lenient.Check(cert); (err == nil) != tt.expectLenient {
+				t.Errorf("lenient profile: got err: %v, expect valid: %v", err, tt.expectLenient)
+			}
+		})
+	}
+	if err := (&Profile{}).Check(&x509.Certificate{DNSNames: []string{"foo.*.example.com."}}); err != nil {
+		t.Errorf("DNS names must not be validated by default, got err: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/yahoo/crypki"
+)
+
+// maxSerialAttempts is the number of serial numbers ReserveSerial tries to reserve. Random 128 bit
+// serial numbers practically never collide, so running out of attempts indicates a broken store.
+const maxSerialAttempts = 3
+
+// ReserveSerial reserves the serial number of the certificate signed by the specified key in the store.
+// If replace is true, as for the random serial numbers assigned by crypki, a serial number already reserved
+// is replaced with a new random one. Otherwise, such as for a serial number chosen by the caller, it is
+// never replaced and crypki.ErrSerialReserved is returned.
+func ReserveSerial(store crypki.SerialStore, keyIdentifier string, cert *x509.Certificate, replace bool) error {
+	for i := 0; i < maxSerialAttempts; i++ {
+		err := store.Reserve(keyIdentifier, cert.SerialNumber)
+		if err != crypki.ErrSerialReserved {
+			return err
+		}
+		if !replace {
+			return err
+		}
+		cert.SerialNumber = newSerial()
+	}
+	return fmt.Errorf("unable to reserve a serial number in %d attempts", maxSerialAttempts)
+}
+
+// maxSerialOctets is the maximum length in octets of the serial number of a certificate, per RFC 5280.
+const maxSerialOctets = 20
+
+// CheckSerial returns an error if the serial number is not positive or its DER encoding exceeds
+// 20 octets, as RFC 5280 requires. The random serial numbers of DecodeRequest always pass.
+func CheckSerial(serial *big.Int) error {
+	if serial == nil || serial.Sign() <= 0 {
+		return fmt.Errorf("serial number %v is not positive", serial)
+	}
+	n := len(serial.Bytes())
+	if serial.Bytes()[0]&0x80 != 0 {
+		// The DER encoding of a positive integer whose high bit is set has a leading zero octet.
+		n++
+	}
+	if n > maxSerialOctets {
+		return fmt.Errorf("serial number is %d octets long, more than %d", n, maxSerialOctets)
+	}
+	return nil
+}
+
+// FileSerialStore is a crypki.SerialStore that reserves a serial number by exclusively creating a file
+// named after it. The directory must be on a file system shared by all crypki replicas which supports
+// exclusive file creation, such as NFSv3 or later.
+type FileSerialStore struct {
+	dir string
+}
+
+// NewFileSerialStore returns a FileSerialStore that reserves the serial numbers in dir.
+func NewFileSerialStore(dir string) (*FileSerialStore, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &FileSerialStore{dir: dir}, nil
+}
+
+// Reserve implements crypki.SerialStore.
+func (f *FileSerialStore) Reserve(keyIdentifier string, serial *big.Int) error {
+	if keyIdentifier == "" || keyIdentifier == "." || keyIdentifier == ".." || keyIdentifier != filepath.Base(keyIdentifier) {
+		return fmt.Errorf("invalid key identifier %q", keyIdentifier)
+	}
+	dir := filepath.Join(f.dir, keyIdentifier)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, serial.Text(16)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return crypki.ErrSerialReserved
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
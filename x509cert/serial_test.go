@@ -0,0 +1,177 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/yahoo/crypki"
+)
+
+// mockSerialStore is an in-memory crypki.SerialStore, standing in for a store shared by replicas.
+type mockSerialStore struct {
+	mu       sync.Mutex
+	reserved map[string]bool
+	err      error
+}
+
+func (m *mockSerialStore) Reserve(keyIdentifier string, serial *big.Int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	key := keyIdentifier + "/" + serial.String()
+	if m.reserved[key] {
+		return crypki.ErrSerialReserved
+	}
+	m.reserved[key] = true
+	return nil
+}
+
+// reserveConcurrently reserves the serial numbers of n certificates whose initial serial number is
+// serial in parallel, and returns the reserved serial numbers.
+func reserveConcurrently(t *testing.T, store crypki.SerialStore, n int, serial int64) []*big.Int {
+	t.Helper()
+	var wg sync.WaitGroup
+	certs := make([]*x509.Certificate, n)
+	errs := make([]error, n)
+	for i := range certs {
+		certs[i] = &x509.Certificate{SerialNumber: big.NewInt(serial)}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = ReserveSerial(store, "x509id", certs[i], true)
+		}(i)
+	}
+	wg.Wait()
+	serials := make([]*big.Int, n)
+	for i := range certs {
+		if errs[i] != nil {
+			t.Fatalf("unable to reserve serial number: %v", errs[i])
+		}
+		serials[i] = certs[i].SerialNumber
+	}
+	return serials
+}
+
+func TestReserveSerial(t *testing.T) {
+	t.Parallel()
+	store := &mockSerialStore{reserved: make(map[string]bool)}
+	// Every certificate starts with the same serial number, as if issued by replicas with colliding allocators.
+	serials := reserveConcurrently(t, store, 50, 42)
+	seen := make(map[string]bool)
+	kept := 0
+	for _, serial := range serials {
+		if seen[serial.String()] {
+			t.Fatalf("serial number %v issued twice", serial)
+		}
+		seen[serial.String()] = true
+		if serial.Int64() == 42 {
+			kept++
+		}
+	}
+	if kept != 1 {
+		t.Errorf("initial serial number kept by %d certificates, want 1", kept)
+	}
+	if len(store.reserved) != len(serials) {
+		t.Errorf("got %d reserved serial numbers, want %d", len(store.reserved), len(serials))
+	}
+
+	// A serial number reserved for another key can be reused.
+	if err := ReserveSerial(store, "x509id2", &x509.Certificate{SerialNumber: big.NewInt(42)}, false); err != nil {
+		t.Errorf("unable to reserve serial number for another key: %v", err)
+	}
+
+	// A serial number that may not be replaced is never swapped for another one.
+	cert := &x509.Certificate{SerialNumber: big.NewInt(42)}
+	if err := ReserveSerial(store, "x509id", cert, false); err != crypki.ErrSerialReserved {
+		t.Errorf("got err %v, want %v", err, crypki.ErrSerialReserved)
+	}
+	if cert.SerialNumber.Int64() != 42 {
+		t.Errorf("serial number replaced by %v", cert.SerialNumber)
+	}
+
+	failing := &mockSerialStore{err: errors.New("connection refused")}
+	if err := ReserveSerial(failing, "x509id", &x509.Certificate{SerialNumber: big.NewInt(1)}, true); err != failing.err {
+		t.Errorf("got err %v, want store error %v", err, failing.err)
+	}
+}
+
+func TestFileSerialStore(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "serials")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	// Two stores on the same directory stand in for two replicas.
+	replica1, err := NewFileSerialStore(dir)
+	if err != nil {
+		t.Fatalf("unable to open serial store: %v", err)
+	}
+	replica2, err := NewFileSerialStore(dir)
+	if err != nil {
+		t.Fatalf("unable to open serial store: %v", err)
+	}
+	if err := replica1.Reserve("x509id", big.NewInt(42)); err != nil {
+		t.Fatalf("unable to reserve serial number: %v", err)
+	}
+	if err := replica2.Reserve("x509id", big.NewInt(42)); err != crypki.ErrSerialReserved {
+		t.Errorf("got err %v reserving a reserved serial number, want %v", err, crypki.ErrSerialReserved)
+	}
+	if err := replica2.Reserve("x509id2", big.NewInt(42)); err != nil {
+		t.Errorf("unable to reserve serial number for another key: %v", err)
+	}
+	for _, id := range []string{"", "..", "../x509id"} {
+		if err := replica1.Reserve(id, big.NewInt(1)); err == nil {
+			t.Errorf("expected error reserving serial number for key %q", id)
+		}
+	}
+	reserveConcurrently(t, replica1, 20, 7)
+
+	if _, err := NewFileSerialStore(dir + "/nonexist"); err == nil {
+		t.Error("expected error opening a missing directory")
+	}
+}
+
+func TestCheckSerial(t *testing.T) {
+	t.Parallel()
+	octets := func(n int, first byte) *big.Int {
+		b := make([]byte, n)
+		b[0] = first
+		for i := 1; i < n; i++ {
+			b[i] = 0xff
+		}
+		return new(big.Int).SetBytes(b)
+	}
+	testcases := map[string]struct {
+		serial      *big.Int
+		expectError bool
+	}{
+		"random":                 {newSerial(), false},
+		"one":                    {big.NewInt(1), false},
+		"20-octets":              {octets(20, 0x7f), false},
+		"21-octets":              {octets(21, 0x01), true},
+		"20-octets-with-padding": {octets(20, 0x80), true},
+		"zero":                   {big.NewInt(0), true},
+		"negative":               {big.NewInt(-1), true},
+		"missing":                {nil, true},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			if err := CheckSerial(tt.serial); (err != nil) != tt.expectError {
+				t.Errorf("in test %v: got err %v, expectError %v", label, err, tt.expectError)
+			}
+		})
+	}
+}
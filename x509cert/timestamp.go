@@ -0,0 +1,276 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+
+package x509cert
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	oidSignedData             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidAttrContentType        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningCertV2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidSHA1                   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256                 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384                 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512                 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	timestampHashAlgorithms   = map[string]crypto.Hash{oidSHA1.String(): crypto.SHA1, oidSHA256.String(): crypto.SHA256, oidSHA384.String(): crypto.SHA384, oidSHA512.String(): crypto.SHA512}
+	errTimestampBadDataFormat = errors.New("malformed time-stamp request")
+)
+
+// PKIStatus values and PKIFailureInfo bits of a TimeStampResp (RFC 3161 section 2.4.2).
+const (
+	pkiStatusGranted        = 0
+	pkiStatusRejection      = 2
+	failureBadAlg           = 0
+	failureBadRequest       = 2
+	failureBadDataFormat    = 5
+	failureUnacceptedPolicy = 15
+)
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+// timeStampReq is the TimeStampReq of RFC 3161 section 2.4.1.
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional,default:false"`
+	Extensions     []pkix.Extension      `asn1:"optional,tag:0"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional,omitempty"`
+	Millis  int `asn1:"optional,omitempty,tag:0"`
+	Micros  int `asn1:"optional,omitempty,tag:1"`
+}
+
+// tstInfo is the TSTInfo of RFC 3161 section 2.4.2.
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional,omitempty"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []asn1.RawValue `asn1:"optional"`
+	FailInfo     asn1.BitString  `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// contentInfo is the ContentInfo of RFC 5652, whose content is the [0] EXPLICIT element.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"optional,explicit,tag:0"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type signerInfo struct {
+	Version            int
+	SID                issuerAndSerialNumber
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// essCertIDv2 is the ESSCertIDv2 of RFC 5035 with the default SHA256 hash algorithm.
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// TSA is a time-stamping authority issuing RFC 3161 time-stamp tokens.
+type TSA struct {
+	// Cert is the TSA certificate, which should have the critical timeStamping extended key usage.
+	Cert *x509.Certificate
+	// Signer signs the tokens with the key of Cert.
+	Signer crypto.Signer
+	// Policy is the TSA policy under which the tokens are issued.
+	Policy asn1.ObjectIdentifier
+	// Accuracy is the accuracy of the time of the tokens. If zero, the accuracy is not included.
+	Accuracy time.Duration
+}
+
+// Respond returns the DER encoded TimeStampResp to the DER encoded TimeStampReq. A request that cannot
+// be granted is rejected in the status of the response. An error is returned only if the time-stamp
+// token cannot be issued.
+func (t *TSA) Respond(request []byte) ([]byte, error) {
+	var req timeStampReq
+	if rest, err := asn1.Unmarshal(request, &req); err != nil || len(rest) != 0 || req.Version != 1 {
+		return rejectTimestamp(failureBadDataFormat, errTimestampBadDataFormat)
+	}
+	hash, ok := timestampHashAlgorithms[req.MessageImprint.HashAlgorithm.Algorithm.String()]
+	if !ok {
+		return rejectTimestamp(failureBadAlg, fmt.Errorf("unsupported hash algorithm %v", req.MessageImprint.HashAlgorithm.Algorithm))
+	}
+	if len(req.MessageImprint.HashedMessage) != hash.Size() {
+		return rejectTimestamp(failureBadDataFormat, fmt.Errorf("invalid message imprint length: got %d bytes, want %d", len(req.MessageImprint.HashedMessage), hash.Size()))
+	}
+	if len(req.ReqPolicy) != 0 && !req.ReqPolicy.Equal(t.Policy) {
+		return rejectTimestamp(failureUnacceptedPolicy, fmt.Errorf("unaccepted policy %v", req.ReqPolicy))
+	}
+	if len(req.Extensions) != 0 {
+		return rejectTimestamp(failureBadRequest, errors.New("extensions are not supported"))
+	}
+	token, err := t.token(&req)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(timeStampResp{
+		Status:         pkiStatusInfo{Status: pkiStatusGranted},
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	})
+}
+
+// rejectTimestamp returns the TimeStampResp rejecting a request for the reason.
+func rejectTimestamp(failure int, reason error) ([]byte, error) {
+	failInfo := asn1.BitString{Bytes: make([]byte, failure/8+1), BitLength: failure + 1}
+	failInfo.Bytes[failure/8] = 0x80 >> uint(failure%8)
+	statusString, err := asn1.MarshalWithParams(reason.Error(), "utf8")
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(timeStampResp{Status: pkiStatusInfo{
+		Status:       pkiStatusRejection,
+		StatusString: []asn1.RawValue{{FullBytes: statusString}},
+		FailInfo:     failInfo,
+	}})
+}
+
+// token returns the DER encoded TimeStampToken of the request.
+func (t *TSA) token(req *timeStampReq) ([]byte, error) {
+	info := tstInfo{
+		Version:        1,
+		Policy:         t.Policy,
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   newSerial(),
+		GenTime:        time.Now().UTC(),
+		Nonce:          req.Nonce,
+	}
+	if t.Accuracy > 0 {
+		info.Accuracy = accuracy{
+			Seconds: int(t.Accuracy / time.Second),
+			Millis:  int(t.Accuracy % time.Second / time.Millisecond),
+			Micros:  int(t.Accuracy % time.Millisecond / time.Microsecond),
+		}
+	}
+	eContent, err := asn1.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode TSTInfo: %v", err)
+	}
+
+	sigAlg, err := signatureAlgorithm(t.Signer.Public(), crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	signedAttrs, err := t.signedAttributes(eContent)
+	if err != nil {
+		return nil, err
+	}
+	// The signature is computed over the DER encoding of the signed attributes as a SET OF (RFC 5652 section 5.4).
+	digest := sha256.Sum256(signedAttrs)
+	signature, err := t.Signer.Sign(nil, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign time-stamp token: %v", err)
+	}
+	// The signed attributes are [0] IMPLICIT in the SignerInfo.
+	implicitAttrs := append([]byte{0xa0}, signedAttrs[1:]...)
+
+	sd := signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: encapsulatedContentInfo{EContentType: oidTSTInfo, EContent: eContent},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                issuerAndSerialNumber{Issuer: asn1.RawValue{FullBytes: t.Cert.RawIssuer}, SerialNumber: t.Cert.SerialNumber},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{FullBytes: implicitAttrs},
+			SignatureAlgorithm: sigAlg,
+			Signature:          signature,
+		}},
+	}
+	if req.CertReq {
+		sd.Certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: t.Cert.Raw}
+	}
+	content, err := asn1.Marshal(sd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode SignedData: %v", err)
+	}
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	})
+}
+
+// signedAttributes returns the DER encoded SET OF the signed attributes of the token of eContent.
+func (t *TSA) signedAttributes(eContent []byte) ([]byte, error) {
+	contentType, err := asn1.Marshal(oidTSTInfo)
+	if err != nil {
+		return nil, err
+	}
+	contentDigest := sha256.Sum256(eContent)
+	messageDigest, err := asn1.Marshal(contentDigest[:])
+	if err != nil {
+		return nil, err
+	}
+	certHash := sha256.Sum256(t.Cert.Raw)
+	signingCert, err := asn1.Marshal(signingCertificateV2{Certs: []essCertIDv2{{CertHash: certHash[:]}}})
+	if err != nil {
+		return nil, err
+	}
+	return marshalAttributes([]attribute{
+		{Type: oidAttrContentType, Values: []asn1.RawValue{{FullBytes: contentType}}},
+		{Type: oidAttrMessageDigest, Values: []asn1.RawValue{{FullBytes: messageDigest}}},
+		{Type: oidAttrSigningCertV2, Values: []asn1.RawValue{{FullBytes: signingCert}}},
+	})
+}
@@ -0,0 +1,209 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var testTSAPolicy = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}
+
+// newTestTSA returns a TSA whose self-signed certificate has the key.
+func newTestTSA(t *testing.T, key crypto.Signer) *TSA {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(7),
+		Subject:               pkix.Name{CommonName: "test TSA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &TSA{Cert: cert, Signer: key, Policy: testTSAPolicy, Accuracy: 1500 * time.Millisecond}
+}
+
+// verifyTimestampToken parses the granted response and verifies the signature of its token
+// against the TSA certificate, returning the TSTInfo and whether the certificate is included.
+func verifyTimestampToken(t *testing.T, tsa *TSA, der []byte) (*tstInfo, bool) {
+	t.Helper()
+	var resp timeStampResp
+	if rest, err := asn1.Unmarshal(der, &resp); err != nil || len(rest) != 0 {
+		t.Fatalf("unable to parse TimeStampResp: %v", err)
+	}
+	if resp.Status.Status != pkiStatusGranted {
+		t.Fatalf("got status %d, want granted", resp.Status.Status)
+	}
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(resp.TimeStampToken.FullBytes, &ci); err != nil {
+		t.Fatalf("unable to parse ContentInfo: %v", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		t.Fatalf("got content type %v, want signedData", ci.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		t.Fatalf("unable to parse SignedData: %v", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) || len(sd.SignerInfos) != 1 {
+		t.Fatalf("bad SignedData: %+v", sd)
+	}
+	si := sd.SignerInfos[0]
+	if si.SID.SerialNumber.Cmp(tsa.Cert.SerialNumber) != 0 || !bytes.Equal(si.SID.Issuer.FullBytes, tsa.Cert.RawIssuer) {
+		t.Errorf("signer identifier does not match the TSA certificate")
+	}
+
+	// The signature covers the signed attributes encoded as a SET OF.
+	signed := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+	algo := x509.SHA256WithRSA
+	if _, ok := tsa.Cert.PublicKey.(*ecdsa.PublicKey); ok {
+		algo = x509.ECDSAWithSHA256
+	}
+	if err := tsa.Cert.CheckSignature(algo, signed, si.Signature); err != nil {
+		t.Fatalf("unable to verify token signature: %v", err)
+	}
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(signed, &attrs, "set"); err != nil {
+		t.Fatalf("unable to parse signed attributes: %v", err)
+	}
+	digest := sha256.Sum256(sd.EncapContentInfo.EContent)
+	certHash := sha256.Sum256(tsa.Cert.Raw)
+	found := map[string]bool{}
+	for _, attr := range attrs {
+		found[attr.Type.String()] = true
+		switch {
+		case attr.Type.Equal(oidAttrMessageDigest):
+			var md []byte
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &md); err != nil || !bytes.Equal(md, digest[:]) {
+				t.Errorf("message digest attribute does not match the TSTInfo")
+			}
+		case attr.Type.Equal(oidAttrSigningCertV2):
+			var sc signingCertificateV2
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &sc); err != nil || len(sc.Certs) != 1 || !bytes.Equal(sc.Certs[0].CertHash, certHash[:]) {
+				t.Errorf("signing certificate attribute does not match the TSA certificate")
+			}
+		}
+	}
+	if len(found) != 3 {
+		t.Errorf("got signed attributes %v, want content type, message digest and signing certificate", found)
+	}
+
+	var info tstInfo
+	if rest, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil || len(rest) != 0 {
+		t.Fatalf("unable to parse TSTInfo: %v", err)
+	}
+	return &info, len(sd.Certificates.Bytes) != 0
+}
+
+func TestTSARespond(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("artifact"))
+	imprint := messageImprint{HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256}, HashedMessage: sum[:]}
+
+	testcases := map[string]struct {
+		key             crypto.Signer
+		req             timeStampReq
+		expectedFailure int
+	}{
+		"rsa":                {key: rsaKey, req: timeStampReq{Version: 1, MessageImprint: imprint, Nonce: big.NewInt(42), CertReq: true}, expectedFailure: -1},
+		"ecdsa":              {key: ecKey, req: timeStampReq{Version: 1, MessageImprint: imprint, ReqPolicy: testTSAPolicy}, expectedFailure: -1},
+		"unaccepted-policy":  {key: rsaKey, req: timeStampReq{Version: 1, MessageImprint: imprint, ReqPolicy: asn1.ObjectIdentifier{1, 2, 3}}, expectedFailure: failureUnacceptedPolicy},
+		"bad-hash-algorithm": {key: rsaKey, req: timeStampReq{Version: 1, MessageImprint: messageImprint{HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 3}}, HashedMessage: sum[:]}}, expectedFailure: failureBadAlg},
+		"bad-imprint-length": {key: rsaKey, req: timeStampReq{Version: 1, MessageImprint: messageImprint{HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA512}, HashedMessage: sum[:]}}, expectedFailure: failureBadDataFormat},
+		"bad-version":        {key: rsaKey, req: timeStampReq{Version: 2, MessageImprint: imprint}, expectedFailure: failureBadDataFormat},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		label := label
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			tsa := newTestTSA(t, tt.key)
+			req, err := asn1.Marshal(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now().Add(-time.Second)
+			resp, err := tsa.Respond(req)
+			if err != nil {
+				t.Fatalf("in test %v: unexpected error: %v", label, err)
+			}
+			if tt.expectedFailure >= 0 {
+				var r timeStampResp
+				if _, err := asn1.Unmarshal(resp, &r); err != nil {
+					t.Fatalf("in test %v: unable to parse TimeStampResp: %v", label, err)
+				}
+				if r.Status.Status != pkiStatusRejection || r.Status.FailInfo.At(tt.expectedFailure) != 1 || len(r.TimeStampToken.FullBytes) != 0 {
+					t.Errorf("in test %v: got status %+v, want rejection with failure bit %d", label, r.Status, tt.expectedFailure)
+				}
+				return
+			}
+
+			info, hasCert := verifyTimestampToken(t, tsa, resp)
+			if hasCert != tt.req.CertReq {
+				t.Errorf("in test %v: got certificate included %v, want %v", label, hasCert, tt.req.CertReq)
+			}
+			if !info.Policy.Equal(testTSAPolicy) {
+				t.Errorf("in test %v: got policy %v, want %v", label, info.Policy, testTSAPolicy)
+			}
+			if !bytes.Equal(info.MessageImprint.HashedMessage, sum[:]) {
+				t.Errorf("in test %v: message imprint does not match the request", label)
+			}
+			if (info.Nonce == nil) != (tt.req.Nonce == nil) || (info.Nonce != nil && info.Nonce.Cmp(tt.req.Nonce) != 0) {
+				t.Errorf("in test %v: got nonce %v, want %v", label, info.Nonce, tt.req.Nonce)
+			}
+			if info.Accuracy.Seconds != 1 || info.Accuracy.Millis != 500 || info.Accuracy.Micros != 0 {
+				t.Errorf("in test %v: got accuracy %+v, want 1.5s", label, info.Accuracy)
+			}
+			if info.GenTime.Before(start) || info.GenTime.After(time.Now().Add(time.Second)) {
+				t.Errorf("in test %v: got time %v, want around %v", label, info.GenTime, start)
+			}
+		})
+	}
+}
+
+func TestTSARespondMalformed(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := newTestTSA(t, key).Respond([]byte("not a request"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var r timeStampResp
+	if _, err := asn1.Unmarshal(resp, &r); err != nil {
+		t.Fatalf("unable to parse TimeStampResp: %v", err)
+	}
+	if r.Status.Status != pkiStatusRejection || r.Status.FailInfo.At(failureBadDataFormat) != 1 {
+		t.Errorf("got status %+v, want rejection with badDataFormat", r.Status)
+	}
+}
@@ -6,9 +6,12 @@ package x509cert
 import (
 	"crypto"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
@@ -68,6 +71,32 @@ func getSignatureAlgorithm(pka crypki.PublicKeyAlgorithm) x509.SignatureAlgorith
 		algo = x509.SHA256WithRSA
 	case crypki.ECDSA:
 		algo = x509.ECDSAWithSHA256
+	case crypki.Ed25519:
+		algo = x509.PureEd25519
 	}
 	return algo
 }
+
+// Fingerprint returns the hex encoded SHA256 fingerprint of the PEM encoded certificate.
+func Fingerprint(certPEM []byte) (string, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return "", errors.New("unable to decode certificate PEM")
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// TBSCertificate returns the DER encoded TBSCertificate of the PEM encoded certificate,
+// i.e. the bytes covered by the signature of the certificate.
+func TBSCertificate(certPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("unable to decode certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate: %v", err)
+	}
+	return cert.RawTBSCertificate, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2019, Oath Inc.
+// Licensed under the terms of the Apache License 2.0. Please see LICENSE file in project root for terms.
+package x509cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate EC key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "foo.bar.com"},
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+	sum := sha256.Sum256(der)
+
+	testcases := map[string]struct {
+		certPEM     []byte
+		expected    string
+		expectError bool
+	}{
+		"good-cert": {
+			certPEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			expected: hex.EncodeToString(sum[:]),
+		},
+		"bad-pem": {
+			certPEM:     []byte("bad cert"),
+			expectError: true,
+		},
+	}
+	for label, tt := range testcases {
+		tt := tt
+		t.Run(label, func(t *testing.T) {
+			t.Parallel()
+			got, err := Fingerprint(tt.certPEM)
+			if err != nil != tt.expectError {
+				t.Fatalf("got err: %v, expect err: %v", err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("fingerprint mismatch: got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}